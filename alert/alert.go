@@ -0,0 +1,230 @@
+// Package alert evaluates threshold rules against values sampled by the
+// monitor scanners, such as disk temperatures and pool capacity, and
+// publishes an event when a rule trips or recovers.
+package alert
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+
+	"go.aimuz.me/mynt/disk"
+	"go.aimuz.me/mynt/event"
+	"go.aimuz.me/mynt/logger"
+)
+
+// Metrics that rules can watch.
+const (
+	DiskTemp     = "disk_temp"     // disk temperature, °C, per disk
+	PoolCapacity = "pool_capacity" // space allocated, percent, per pool
+	PoolErrors   = "pool_errors"   // read, write and checksum errors of all members, per pool
+)
+
+// metrics maps each metric to the hysteresis used by rules that leave
+// theirs at zero.
+var metrics = map[string]float64{
+	DiskTemp:     3,
+	PoolCapacity: 2,
+	PoolErrors:   0,
+}
+
+// Comparison operators of a rule.
+const (
+	OpAbove        = ">"
+	OpAboveOrEqual = ">="
+	OpBelow        = "<"
+	OpBelowOrEqual = "<="
+)
+
+// Rule fires when a metric compares to Threshold with Op, for example a
+// disk above 55 °C. Once fired it clears only when the value is back on
+// the other side of the threshold by Hysteresis, so a value hovering at
+// the threshold does not trigger an alert on every scan.
+type Rule struct {
+	Metric     string         `json:"metric"`
+	Op         string         `json:"op"`
+	Threshold  float64        `json:"threshold"`
+	Hysteresis float64        `json:"hysteresis,omitempty"` // 0 uses the metric's default
+	Severity   event.Severity `json:"severity"`
+}
+
+// Capacity thresholds, in percent used, at which a pool or a dataset with
+// a quota is reported as filling up.
+const (
+	CapacityWarnPercent     = 85
+	CapacityCriticalPercent = 95
+)
+
+// DefaultRules are used until the admin saves their own. They use the
+// thresholds behind disk health reasons and the alerts endpoint, so all
+// three agree until the rules are changed.
+var DefaultRules = []Rule{
+	{Metric: DiskTemp, Op: OpAbove, Threshold: disk.TempWarning, Severity: event.SeverityWarning},
+	{Metric: DiskTemp, Op: OpAbove, Threshold: 65, Severity: event.SeverityCritical},
+	{Metric: PoolCapacity, Op: OpAboveOrEqual, Threshold: CapacityWarnPercent, Severity: event.SeverityWarning},
+	{Metric: PoolCapacity, Op: OpAboveOrEqual, Threshold: CapacityCriticalPercent, Severity: event.SeverityCritical},
+}
+
+// Validate checks a rule, defaulting an empty severity to warning.
+func (r *Rule) Validate() error {
+	if _, ok := metrics[r.Metric]; !ok {
+		return fmt.Errorf("invalid metric %q: must be %s, %s or %s", r.Metric, DiskTemp, PoolCapacity, PoolErrors)
+	}
+	switch r.Op {
+	case OpAbove, OpAboveOrEqual, OpBelow, OpBelowOrEqual:
+	default:
+		return fmt.Errorf("invalid op %q: must be >, >=, < or <=", r.Op)
+	}
+	if r.Hysteresis < 0 {
+		return fmt.Errorf("hysteresis must not be negative")
+	}
+	if r.Severity == "" {
+		r.Severity = event.SeverityWarning
+	}
+	if !r.Severity.Valid() {
+		return fmt.Errorf("invalid severity %q: must be info, warning or critical", r.Severity)
+	}
+	return nil
+}
+
+// tripped reports whether value meets the rule's condition.
+func (r Rule) tripped(value float64) bool {
+	switch r.Op {
+	case OpAbove:
+		return value > r.Threshold
+	case OpAboveOrEqual:
+		return value >= r.Threshold
+	case OpBelow:
+		return value < r.Threshold
+	case OpBelowOrEqual:
+		return value <= r.Threshold
+	}
+	return false
+}
+
+// recovered reports whether value is far enough from the threshold for a
+// fired rule to clear.
+func (r Rule) recovered(value float64) bool {
+	h := r.Hysteresis
+	if h == 0 {
+		h = metrics[r.Metric]
+	}
+	if h == 0 {
+		return !r.tripped(value)
+	}
+	switch r.Op {
+	case OpAbove, OpAboveOrEqual:
+		return value < r.Threshold-h
+	default:
+		return value > r.Threshold+h
+	}
+}
+
+// Alert is the data of an alert.triggered or alert.cleared event.
+type Alert struct {
+	Rule    Rule    `json:"rule"`
+	Subject string  `json:"subject"` // disk or pool name
+	Value   float64 `json:"value"`
+}
+
+// EventSeverity returns the severity of the rule that fired, so alert
+// events are filtered by the rule's severity rather than their type.
+func (a Alert) EventSeverity() event.Severity {
+	return a.Rule.Severity
+}
+
+// firingKey identifies a rule that fired for one subject.
+type firingKey struct {
+	rule    Rule
+	subject string
+}
+
+// Evaluator checks sampled values against the rules and remembers which
+// have fired. Its methods are safe for concurrent use, and do nothing on
+// a nil Evaluator, so scanners can run without alerting.
+type Evaluator struct {
+	bus *event.Bus
+
+	mu     sync.Mutex
+	rules  []Rule
+	firing map[firingKey]bool
+}
+
+// NewEvaluator creates an evaluator that publishes to bus.
+func NewEvaluator(bus *event.Bus, rules []Rule) *Evaluator {
+	return &Evaluator{
+		bus:    bus,
+		rules:  slices.Clone(rules),
+		firing: make(map[firingKey]bool),
+	}
+}
+
+// Rules returns the rules being evaluated.
+func (e *Evaluator) Rules() []Rule {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return slices.Clone(e.rules)
+}
+
+// SetRules replaces the rules. Alerts of rules that remain keep firing;
+// those of removed rules are forgotten without a cleared event.
+func (e *Evaluator) SetRules(rules []Rule) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.rules = slices.Clone(rules)
+	for k := range e.firing {
+		if !slices.Contains(e.rules, k.rule) {
+			delete(e.firing, k)
+		}
+	}
+}
+
+// Observe checks a sampled value of metric for subject against every
+// rule on that metric, publishing alert.triggered when a rule trips and
+// alert.cleared when a fired rule recovers.
+func (e *Evaluator) Observe(metric, subject string, value float64) {
+	if e == nil {
+		return
+	}
+
+	var events []event.Event
+	e.mu.Lock()
+	for _, r := range e.rules {
+		if r.Metric != metric {
+			continue
+		}
+		key := firingKey{rule: r, subject: subject}
+		a := Alert{Rule: r, Subject: subject, Value: value}
+		switch {
+		case !e.firing[key] && r.tripped(value):
+			e.firing[key] = true
+			events = append(events, event.Event{Type: event.AlertTriggered, Data: a})
+		case e.firing[key] && r.recovered(value):
+			delete(e.firing, key)
+			events = append(events, event.Event{Type: event.AlertCleared, Data: a})
+		}
+	}
+	e.mu.Unlock()
+
+	for _, evt := range events {
+		a := evt.Data.(Alert)
+		logger.Info("alert state changed", "event", evt.Type, "metric", metric, "subject", subject,
+			"value", value, "op", a.Rule.Op, "threshold", a.Rule.Threshold)
+		e.bus.Publish(evt)
+	}
+}
+
+// Retain forgets the alerts on metric of subjects not in subjects, so a
+// disk that is removed while hot does not stay firing.
+func (e *Evaluator) Retain(metric string, subjects []string) {
+	if e == nil {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for k := range e.firing {
+		if k.rule.Metric == metric && !slices.Contains(subjects, k.subject) {
+			delete(e.firing, k)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package alert
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.aimuz.me/mynt/event"
+)
+
+// drain returns the events published so far.
+func drain(ch <-chan event.Event) []event.Event {
+	var events []event.Event
+	for {
+		select {
+		case evt := <-ch:
+			events = append(events, evt)
+		case <-time.After(20 * time.Millisecond):
+			return events
+		}
+	}
+}
+
+func TestEvaluator_Hysteresis(t *testing.T) {
+	bus := event.NewBus()
+	ch := bus.Subscribe("alert.*")
+	defer bus.Unsubscribe("alert.*", ch)
+
+	rule := Rule{Metric: DiskTemp, Op: OpAbove, Threshold: 55, Severity: event.SeverityCritical}
+	e := NewEvaluator(bus, []Rule{rule})
+
+	// Hovering around the threshold triggers once and does not clear
+	// until the disk is 3 °C (the default) below it
+	for _, temp := range []float64{54, 56, 55, 57, 53, 56} {
+		e.Observe(DiskTemp, "sda", temp)
+	}
+	events := drain(ch)
+	require.Len(t, events, 1)
+	require.Equal(t, event.AlertTriggered, events[0].Type)
+	require.Equal(t, Alert{Rule: rule, Subject: "sda", Value: 56}, events[0].Data)
+	require.Equal(t, event.SeverityCritical, events[0].Severity())
+
+	e.Observe(DiskTemp, "sda", 51)
+	events = drain(ch)
+	require.Len(t, events, 1)
+	require.Equal(t, event.AlertCleared, events[0].Type)
+
+	// Other subjects and metrics are tracked separately
+	e.Observe(DiskTemp, "sdb", 60)
+	e.Observe(PoolCapacity, "sda", 99)
+	events = drain(ch)
+	require.Len(t, events, 1)
+	require.Equal(t, "sdb", events[0].Data.(Alert).Subject)
+}
+
+func TestEvaluator_NoHysteresis(t *testing.T) {
+	bus := event.NewBus()
+	ch := bus.Subscribe("alert.*")
+	defer bus.Unsubscribe("alert.*", ch)
+
+	e := NewEvaluator(bus, []Rule{{Metric: PoolErrors, Op: OpAbove, Threshold: 0, Severity: event.SeverityWarning}})
+	e.Observe(PoolErrors, "tank", 3)
+	e.Observe(PoolErrors, "tank", 5)
+	e.Observe(PoolErrors, "tank", 0)
+
+	events := drain(ch)
+	require.Len(t, events, 2)
+	require.Equal(t, event.AlertTriggered, events[0].Type)
+	require.Equal(t, event.AlertCleared, events[1].Type)
+}
+
+func TestEvaluator_SetRulesAndRetain(t *testing.T) {
+	bus := event.NewBus()
+	ch := bus.Subscribe("alert.*")
+	defer bus.Unsubscribe("alert.*", ch)
+
+	warn := Rule{Metric: DiskTemp, Op: OpAbove, Threshold: 50, Severity: event.SeverityWarning}
+	crit := Rule{Metric: DiskTemp, Op: OpAbove, Threshold: 60, Severity: event.SeverityCritical}
+	e := NewEvaluator(bus, []Rule{warn})
+	e.Observe(DiskTemp, "sda", 52)
+	e.Observe(DiskTemp, "sdb", 52)
+	require.Len(t, drain(ch), 2)
+
+	// A kept rule stays firing; a new one starts fresh
+	e.SetRules([]Rule{warn, crit})
+	e.Observe(DiskTemp, "sda", 52)
+	require.Empty(t, drain(ch))
+
+	// A disk that went away triggers again when it comes back hot
+	e.Retain(DiskTemp, []string{"sda"})
+	e.Observe(DiskTemp, "sda", 52)
+	e.Observe(DiskTemp, "sdb", 52)
+	events := drain(ch)
+	require.Len(t, events, 1)
+	require.Equal(t, "sdb", events[0].Data.(Alert).Subject)
+
+	// A nil evaluator ignores samples
+	var none *Evaluator
+	none.Observe(DiskTemp, "sda", 90)
+	none.Retain(DiskTemp, nil)
+}
+
+func TestRule_Validate(t *testing.T) {
+	r := Rule{Metric: DiskTemp, Op: OpAboveOrEqual, Threshold: 50}
+	require.NoError(t, r.Validate())
+	require.Equal(t, event.SeverityWarning, r.Severity)
+
+	for _, bad := range []Rule{
+		{Metric: "cpu", Op: OpAbove},
+		{Metric: DiskTemp, Op: "=="},
+		{Metric: DiskTemp, Op: OpAbove, Hysteresis: -1},
+		{Metric: DiskTemp, Op: OpAbove, Severity: "urgent"},
+	} {
+		require.Error(t, bad.Validate(), "%+v", bad)
+	}
+}
@@ -14,14 +14,22 @@ type Claims struct {
 	UserID   int64  `json:"user_id"`
 	Username string `json:"username"`
 	IsAdmin  bool   `json:"is_admin"`
+	AuthTime int64  `json:"auth_time,omitempty"` // Unix time of the login, kept across refreshes
 	jwt.RegisteredClaims
 }
 
 // Config holds authentication configuration.
 type Config struct {
-	Secret         []byte
-	TokenDuration  time.Duration
+	Secret        []byte
+	TokenDuration time.Duration
+
+	// RefreshEnabled turns on sliding expiration: a token used within
+	// RenewWindow of its expiry is replaced by a fresh one, so sessions
+	// end after TokenDuration of inactivity, but never later than
+	// MaxLifetime after login.
 	RefreshEnabled bool
+	RenewWindow    time.Duration // zero means half of TokenDuration
+	MaxLifetime    time.Duration // zero means no absolute cap
 }
 
 // DefaultConfig returns default authentication config.
@@ -30,6 +38,7 @@ func DefaultConfig(secret string) *Config {
 		Secret:         []byte(secret),
 		TokenDuration:  24 * time.Hour,
 		RefreshEnabled: false,
+		MaxLifetime:    7 * 24 * time.Hour,
 	}
 }
 
@@ -40,6 +49,7 @@ func GenerateToken(user *store.User, config *Config) (string, error) {
 		UserID:   user.ID,
 		Username: user.Username,
 		IsAdmin:  user.IsAdmin,
+		AuthTime: now.Unix(),
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(now.Add(config.TokenDuration)),
 			IssuedAt:  jwt.NewNumericDate(now),
@@ -52,6 +62,49 @@ func GenerateToken(user *store.User, config *Config) (string, error) {
 	return token.SignedString(config.Secret)
 }
 
+// RefreshToken returns a renewed token for claims if sliding expiration is
+// enabled and the token is within the renewal window. The renewed token
+// keeps the login time and expires no later than MaxLifetime after it. An
+// empty string means no refresh is due.
+func RefreshToken(claims *Claims, config *Config) (string, error) {
+	return refreshToken(claims, config, time.Now())
+}
+
+func refreshToken(claims *Claims, config *Config, now time.Time) (string, error) {
+	if !config.RefreshEnabled || claims.ExpiresAt == nil {
+		return "", nil
+	}
+
+	window := config.RenewWindow
+	if window <= 0 {
+		window = config.TokenDuration / 2
+	}
+	current := claims.ExpiresAt.Time
+	if current.Sub(now) > window {
+		return "", nil
+	}
+
+	authTime := time.Unix(claims.AuthTime, 0)
+	if claims.AuthTime == 0 && claims.IssuedAt != nil {
+		authTime = claims.IssuedAt.Time // token issued before auth_time existed
+	}
+	expires := now.Add(config.TokenDuration)
+	if limit := authTime.Add(config.MaxLifetime); config.MaxLifetime > 0 && expires.After(limit) {
+		expires = limit
+	}
+	if !expires.After(current) {
+		return "", nil // capped: the session ends at its current expiry
+	}
+
+	renewed := *claims
+	renewed.AuthTime = authTime.Unix()
+	renewed.ExpiresAt = jwt.NewNumericDate(expires)
+	renewed.IssuedAt = jwt.NewNumericDate(now)
+	renewed.NotBefore = jwt.NewNumericDate(now)
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, renewed)
+	return token.SignedString(config.Secret)
+}
+
 // ValidateToken validates a JWT token and returns the claims.
 func ValidateToken(tokenString string, config *Config) (*Claims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
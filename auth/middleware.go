@@ -4,6 +4,9 @@ import (
 	"context"
 	"net/http"
 	"strings"
+
+	"go.aimuz.me/mynt/logger"
+	"go.aimuz.me/mynt/store"
 )
 
 // contextKey is a custom type for context keys to avoid collisions.
@@ -14,14 +17,39 @@ const (
 	UserContextKey contextKey = "user"
 )
 
+// RefreshTokenHeader carries a renewed token when sliding expiration is
+// enabled and the request's token is close to expiring.
+const RefreshTokenHeader = "X-Refresh-Token"
+
+// UserLookup returns the user with the given name, or nil if there is
+// none.
+type UserLookup func(username string) (*store.User, error)
+
 // Middleware provides authentication middleware.
 type Middleware struct {
 	config *Config
+	users  UserLookup // nil renews tokens without checking the user
+}
+
+// MiddlewareOption configures a Middleware.
+type MiddlewareOption func(*Middleware)
+
+// WithUserLookup reloads the user before renewing a token, so a token
+// outlives neither the deletion or deactivation of its user nor a change
+// of their role.
+func WithUserLookup(users UserLookup) MiddlewareOption {
+	return func(m *Middleware) {
+		m.users = users
+	}
 }
 
 // NewMiddleware creates a new auth middleware.
-func NewMiddleware(config *Config) *Middleware {
-	return &Middleware{config: config}
+func NewMiddleware(config *Config, opts ...MiddlewareOption) *Middleware {
+	m := &Middleware{config: config}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
 }
 
 // RequireAuth is a middleware that requires valid JWT authentication.
@@ -50,6 +78,8 @@ func (m *Middleware) RequireAuth(next http.Handler) http.Handler {
 			return
 		}
 
+		m.refresh(w, claims)
+
 		// Add claims to context
 		ctx := context.WithValue(r.Context(), UserContextKey, claims)
 		next.ServeHTTP(w, r.WithContext(ctx))
@@ -91,6 +121,7 @@ func (m *Middleware) OptionalAuth(next http.Handler) http.Handler {
 			parts := strings.Split(authHeader, " ")
 			if len(parts) == 2 && parts[0] == "Bearer" {
 				if claims, err := ValidateToken(parts[1], m.config); err == nil {
+					m.refresh(w, claims)
 					ctx := context.WithValue(r.Context(), UserContextKey, claims)
 					r = r.WithContext(ctx)
 				}
@@ -99,3 +130,24 @@ func (m *Middleware) OptionalAuth(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r)
 	})
 }
+
+// refresh sets RefreshTokenHeader on the response if the token is due for
+// renewal and its user still matches it. Failing to renew is not an
+// error; the old token stays valid until it expires.
+func (m *Middleware) refresh(w http.ResponseWriter, claims *Claims) {
+	token, err := RefreshToken(claims, m.config)
+	if err != nil || token == "" {
+		return
+	}
+	if m.users != nil {
+		user, err := m.users(claims.Username)
+		if err != nil {
+			logger.Warn("failed to load user for token refresh", "username", claims.Username, "error", err)
+			return
+		}
+		if user == nil || user.ID != claims.UserID || !user.IsActive || user.IsAdmin != claims.IsAdmin {
+			return
+		}
+	}
+	w.Header().Set(RefreshTokenHeader, token)
+}
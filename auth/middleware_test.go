@@ -5,7 +5,9 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/require"
 	"go.aimuz.me/mynt/store"
 )
@@ -210,3 +212,128 @@ func TestGetUserClaims(t *testing.T) {
 	nilClaims := GetUserClaims(emptyCtx)
 	require.Nil(t, nilClaims)
 }
+
+func TestRequireAuth_SlidingRefresh(t *testing.T) {
+	user := &store.User{ID: 1, Username: "kiosk"}
+	config := &Config{
+		Secret:         []byte("test-secret"),
+		TokenDuration:  30 * time.Minute,
+		RefreshEnabled: true,
+		RenewWindow:    10 * time.Minute,
+		MaxLifetime:    8 * time.Hour,
+	}
+	middleware := NewMiddleware(config)
+	handler := middleware.RequireAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	// Tokens with 5 and 25 minutes left
+	nearExpiry, err := GenerateToken(user, &Config{Secret: config.Secret, TokenDuration: 5 * time.Minute})
+	require.NoError(t, err)
+	fresh, err := GenerateToken(user, &Config{Secret: config.Secret, TokenDuration: 25 * time.Minute})
+	require.NoError(t, err)
+
+	serve := func(token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/test", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code)
+		return rr
+	}
+
+	require.Empty(t, serve(fresh).Header().Get(RefreshTokenHeader))
+
+	renewed := serve(nearExpiry).Header().Get(RefreshTokenHeader)
+	require.NotEmpty(t, renewed)
+	claims, err := ValidateToken(renewed, config)
+	require.NoError(t, err)
+	require.WithinDuration(t, time.Now().Add(30*time.Minute), claims.ExpiresAt.Time, 5*time.Second)
+
+	old, err := ValidateToken(nearExpiry, config)
+	require.NoError(t, err)
+	require.Equal(t, old.AuthTime, claims.AuthTime, "refresh must keep the login time")
+
+	// Disabled by default
+	config.RefreshEnabled = false
+	require.Empty(t, serve(nearExpiry).Header().Get(RefreshTokenHeader))
+}
+
+func TestRequireAuth_RefreshChecksUser(t *testing.T) {
+	config := &Config{
+		Secret:         []byte("test-secret"),
+		TokenDuration:  30 * time.Minute,
+		RefreshEnabled: true,
+	}
+	token, err := GenerateToken(&store.User{ID: 1, Username: "alice", IsAdmin: true},
+		&Config{Secret: config.Secret, TokenDuration: 5 * time.Minute})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		user    *store.User
+		renewed bool
+	}{
+		{"unchanged", &store.User{ID: 1, Username: "alice", IsAdmin: true, IsActive: true}, true},
+		{"deleted", nil, false},
+		{"recreated", &store.User{ID: 2, Username: "alice", IsAdmin: true, IsActive: true}, false},
+		{"deactivated", &store.User{ID: 1, Username: "alice", IsAdmin: true}, false},
+		{"demoted", &store.User{ID: 1, Username: "alice", IsActive: true}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			middleware := NewMiddleware(config, WithUserLookup(func(username string) (*store.User, error) {
+				require.Equal(t, "alice", username)
+				return tt.user, nil
+			}))
+			handler := middleware.RequireAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/test", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, http.StatusOK, rr.Code)
+			require.Equal(t, tt.renewed, rr.Header().Get(RefreshTokenHeader) != "")
+		})
+	}
+}
+
+func TestRefreshToken_MaxLifetime(t *testing.T) {
+	config := &Config{
+		Secret:         []byte("test-secret"),
+		TokenDuration:  30 * time.Minute,
+		RefreshEnabled: true,
+		MaxLifetime:    8 * time.Hour,
+	}
+	now := time.Date(2025, 6, 1, 18, 0, 0, 0, time.UTC)
+	claimsAt := func(login time.Time, expires time.Time) *Claims {
+		return &Claims{
+			UserID:   1,
+			AuthTime: login.Unix(),
+			RegisteredClaims: jwt.RegisteredClaims{
+				ExpiresAt: jwt.NewNumericDate(expires),
+				IssuedAt:  jwt.NewNumericDate(expires.Add(-30 * time.Minute)),
+			},
+		}
+	}
+
+	// Ten minutes of lifetime left: the renewed token stops at the cap
+	login := now.Add(-8*time.Hour + 10*time.Minute)
+	token, err := refreshToken(claimsAt(login, now.Add(5*time.Minute)), config, now)
+	require.NoError(t, err)
+	require.NotEmpty(t, token)
+	parsed, err := jwt.ParseWithClaims(token, &Claims{}, func(*jwt.Token) (any, error) { return config.Secret, nil },
+		jwt.WithTimeFunc(func() time.Time { return now }))
+	require.NoError(t, err)
+	require.True(t, parsed.Claims.(*Claims).ExpiresAt.Time.Equal(login.Add(8*time.Hour)))
+
+	// Already at the cap: no further refresh, the session just ends
+	login = now.Add(-8*time.Hour + 5*time.Minute)
+	token, err = refreshToken(claimsAt(login, now.Add(5*time.Minute)), config, now)
+	require.NoError(t, err)
+	require.Empty(t, token)
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"go.aimuz.me/mynt/event"
+)
+
+// reconnectDelay is how long to wait before reconnecting to the event stream.
+var reconnectDelay = 2 * time.Second
+
+func handleEvents(args []string, addr, token string) {
+	fs := flag.NewFlagSet("events", flag.ExitOnError)
+	raw := fs.Bool("json", false, "Print raw JSON events")
+	fs.Parse(args)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := tailEvents(ctx, http.DefaultClient, addr+"/api/v1/events", token, os.Stdout, *raw); err != nil {
+		log.Fatalf("Event stream failed: %v", err)
+	}
+}
+
+// tailEvents streams events until ctx is cancelled, reconnecting whenever
+// the connection drops. Authentication failures are not retried.
+func tailEvents(ctx context.Context, client *http.Client, url, token string, out io.Writer, raw bool) error {
+	for {
+		err := streamEvents(ctx, client, url, token, out, raw)
+		if ctx.Err() != nil {
+			return nil
+		}
+		var se *statusError
+		if errors.As(err, &se) && se.code == http.StatusUnauthorized {
+			return err
+		}
+		if err != nil {
+			log.Printf("Event stream disconnected: %v, reconnecting in %s", err, reconnectDelay)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(reconnectDelay):
+		}
+	}
+}
+
+// statusError is returned when the server rejects the stream request.
+type statusError struct {
+	code int
+	body string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("server returned %d: %s", e.code, e.body)
+}
+
+// streamEvents reads a single SSE connection and prints each event to out.
+// It returns when the stream ends or ctx is cancelled.
+func streamEvents(ctx context.Context, client *http.Client, url, token string, out io.Writer, raw bool) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return &statusError{code: resp.StatusCode, body: strings.TrimSpace(string(body))}
+	}
+
+	var name string
+	var data []string
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			// A blank line dispatches the frame
+			if len(data) > 0 && name != "ping" {
+				printEvent(out, strings.Join(data, "\n"), raw)
+			}
+			name, data = "", nil
+		case strings.HasPrefix(line, ":"):
+			// Comment
+		case strings.HasPrefix(line, "event:"):
+			name = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return io.ErrUnexpectedEOF
+}
+
+// printEvent writes a single event line.
+func printEvent(out io.Writer, data string, raw bool) {
+	if raw {
+		fmt.Fprintln(out, data)
+		return
+	}
+
+	var evt event.Event
+	if err := json.Unmarshal([]byte(data), &evt); err != nil {
+		fmt.Fprintln(out, data)
+		return
+	}
+	payload, _ := json.Marshal(evt.Data)
+	fmt.Fprintf(out, "%s  %-20s %s\n", evt.Time.Format(time.RFC3339), evt.Type, payload)
+}
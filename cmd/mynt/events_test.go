@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// syncBuffer is a bytes.Buffer safe for concurrent use.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func sseServer(t *testing.T, frames ...string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprintf(w, "event: ping\ndata: %d\n\n", time.Now().Unix())
+		for _, f := range frames {
+			fmt.Fprintf(w, "event: message\ndata: %s\n\n", f)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+const (
+	diskAddedFrame = `{"Type":"disk.added","Time":"2025-01-02T03:04:05Z","Data":{"name":"sda"}}`
+	poolFrame      = `{"Type":"pool.degraded","Time":"2025-01-02T03:04:06Z","Data":"tank"}`
+)
+
+func TestStreamEvents(t *testing.T) {
+	srv := sseServer(t, diskAddedFrame, poolFrame)
+
+	var out bytes.Buffer
+	err := streamEvents(context.Background(), srv.Client(), srv.URL, "secret", &out, false)
+	if err == nil {
+		t.Fatal("expected error when stream ends")
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2:\n%s", len(lines), out.String())
+	}
+	for i, want := range []string{
+		`2025-01-02T03:04:05Z  disk.added           {"name":"sda"}`,
+		`2025-01-02T03:04:06Z  pool.degraded        "tank"`,
+	} {
+		if lines[i] != want {
+			t.Errorf("line %d = %q, want %q", i, lines[i], want)
+		}
+	}
+}
+
+func TestStreamEvents_JSON(t *testing.T) {
+	srv := sseServer(t, diskAddedFrame)
+
+	var out bytes.Buffer
+	_ = streamEvents(context.Background(), srv.Client(), srv.URL, "secret", &out, true)
+
+	if got := strings.TrimSpace(out.String()); got != diskAddedFrame {
+		t.Errorf("output = %q, want %q", got, diskAddedFrame)
+	}
+}
+
+func TestTailEvents_Unauthorized(t *testing.T) {
+	srv := sseServer(t)
+
+	var out bytes.Buffer
+	err := tailEvents(context.Background(), srv.Client(), srv.URL, "wrong", &out, false)
+	if err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("error = %v, want 401", err)
+	}
+}
+
+func TestTailEvents_Reconnect(t *testing.T) {
+	old := reconnectDelay
+	reconnectDelay = 10 * time.Millisecond
+	t.Cleanup(func() { reconnectDelay = old })
+
+	var conns atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conns.Add(1)
+		fmt.Fprintf(w, "event: message\ndata: %s\n\n", diskAddedFrame)
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var out syncBuffer
+	done := make(chan error, 1)
+	go func() {
+		done <- tailEvents(ctx, srv.Client(), srv.URL, "", &out, true)
+	}()
+
+	deadline := time.After(2 * time.Second)
+	for conns.Load() < 2 {
+		select {
+		case <-deadline:
+			t.Fatalf("expected reconnect, got %d connections", conns.Load())
+		case <-time.After(5 * time.Millisecond):
+		}
+	}
+	cancel()
+
+	if err := <-done; err != nil {
+		t.Errorf("tailEvents() error = %v", err)
+	}
+	if n := strings.Count(out.String(), "disk.added"); n < 2 {
+		t.Errorf("printed %d events, want at least 2", n)
+	}
+}
@@ -17,6 +17,7 @@ const defaultAddr = "http://localhost:8080"
 
 func main() {
 	addr := flag.String("addr", defaultAddr, "Address of myntd")
+	token := flag.String("token", os.Getenv("MYNT_TOKEN"), "API token (defaults to $MYNT_TOKEN)")
 	flag.Parse()
 
 	args := flag.Args()
@@ -30,6 +31,10 @@ func main() {
 		handlePool(args[1:], *addr)
 	case "dataset":
 		handleDataset(args[1:], *addr)
+	case "events":
+		handleEvents(args[1:], *addr, *token)
+	case "version":
+		handleVersion(*addr)
 	default:
 		usage()
 		os.Exit(1)
@@ -41,6 +46,8 @@ func usage() {
 	fmt.Println("Commands:")
 	fmt.Println("  pool list")
 	fmt.Println("  dataset list")
+	fmt.Println("  events [--json]")
+	fmt.Println("  version")
 }
 
 func handlePool(args []string, addr string) {
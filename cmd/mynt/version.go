@@ -0,0 +1,44 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"go.aimuz.me/mynt/internal/version"
+)
+
+func handleVersion(addr string) {
+	printVersions(http.DefaultClient, addr, os.Stdout)
+}
+
+// printVersions prints the client version and, if the daemon is
+// reachable, the server version.
+func printVersions(client *http.Client, addr string, out io.Writer) {
+	fmt.Fprintf(out, "Client: %s\n", version.Get())
+
+	server, err := serverVersion(client, addr)
+	if err != nil {
+		fmt.Fprintf(out, "Server: unavailable (%v)\n", err)
+		return
+	}
+	fmt.Fprintf(out, "Server: %s\n", server)
+}
+
+// serverVersion fetches the build information of the daemon at addr.
+func serverVersion(client *http.Client, addr string) (version.Info, error) {
+	var info version.Info
+	resp, err := client.Get(addr + "/api/v1/version")
+	if err != nil {
+		return info, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return info, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	err = json.NewDecoder(resp.Body).Decode(&info)
+	return info, err
+}
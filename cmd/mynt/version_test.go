@@ -0,0 +1,41 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPrintVersions(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/version" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `{"version":"v1.2.3","commit":"abc1234","build_date":"2025-01-02T03:04:05Z"}`)
+	}))
+	defer srv.Close()
+
+	var out bytes.Buffer
+	printVersions(srv.Client(), srv.URL, &out)
+
+	want := "Server: v1.2.3 (commit abc1234, built 2025-01-02T03:04:05Z)"
+	if !strings.Contains(out.String(), "Client: dev") || !strings.Contains(out.String(), want) {
+		t.Errorf("output = %q, want client and %q", out.String(), want)
+	}
+}
+
+func TestPrintVersions_ServerUnreachable(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+
+	var out bytes.Buffer
+	printVersions(http.DefaultClient, srv.URL, &out)
+
+	if !strings.Contains(out.String(), "Server: unavailable") {
+		t.Errorf("output = %q, want server unavailable", out.String())
+	}
+}
@@ -1,36 +1,72 @@
 package main
 
 import (
+	"cmp"
 	"context"
 	"flag"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
 	"time"
 
+	"go.aimuz.me/mynt/alert"
 	"go.aimuz.me/mynt/auth"
 	"go.aimuz.me/mynt/disk"
 	"go.aimuz.me/mynt/event"
 	"go.aimuz.me/mynt/internal/api"
+	"go.aimuz.me/mynt/internal/version"
 	"go.aimuz.me/mynt/logger"
 	"go.aimuz.me/mynt/monitor"
+	"go.aimuz.me/mynt/notify"
 	"go.aimuz.me/mynt/scheduler"
 	"go.aimuz.me/mynt/share"
 	"go.aimuz.me/mynt/store"
+	"go.aimuz.me/mynt/sysinfo"
 	"go.aimuz.me/mynt/task"
 	"go.aimuz.me/mynt/user"
 	"go.aimuz.me/mynt/zfs"
 )
 
+// Background scan intervals.
+const (
+	monitorInterval = 30 * time.Second // disk and ZFS scans run every tick of their monitor
+	smartInterval   = 5 * time.Minute
+)
+
 func main() {
 	// Flags
 	dbPath := flag.String("db", "mynt.db", "Path to SQLite database")
 	addr := flag.String("addr", ":8080", "HTTP API address")
 	smbConfig := flag.String("smb-config", "", "Path to smb.conf (empty for auto-detect)")
+	smbLogLevel := flag.Int("smb-log-level", 0, "Samba log level written to smb.conf (0 keeps Samba's default)")
 	logLevel := flag.String("log-level", "info", "Log level (debug, info, warn, error)")
 	logFormat := flag.String("log-format", "text", "Log format (text, json)")
 	enableLoopDevices := flag.Bool("enable-loop-devices", false, "Enable detection of loop devices (for testing)")
+	notificationRetention := flag.Duration("notification-retention", monitor.DefaultNotificationRetention, "How long to keep read notifications")
+	notificationMax := flag.Int("notification-max", 10000, "Maximum number of stored notifications (0 for unlimited)")
+	capacityRetention := flag.Duration("capacity-retention", monitor.DefaultCapacityRetention, "How long to keep pool capacity history")
+	metricsRetention := flag.Duration("metrics-retention", monitor.DefaultMetricsRetention, "How long to keep CPU, memory, network and disk I/O history")
+	strictSharePaths := flag.Bool("strict-share-paths", false, "Reject shares whose path is not inside a ZFS dataset")
+	zfsRetries := flag.Int("zfs-retries", zfs.DefaultRetryPolicy.Attempts, "Attempts for zfs mutations failing with a transient busy error (1 disables retries)")
+	zfsRetryBackoff := flag.Duration("zfs-retry-backoff", zfs.DefaultRetryPolicy.Backoff, "Initial delay between zfs retries, doubled after each attempt")
+	backupRoot := flag.String("backup-root", zfs.DefaultBackupRoot, "Directory snapshot send stream files are written to and restored from")
+	diffMaxEntries := flag.Int("snapshot-diff-max-entries", zfs.DefaultMaxDiffEntries, "Maximum number of entries returned by a snapshot diff")
+	disableZFS := flag.Bool("disable-zfs", false, "Disable pool, dataset and snapshot features (no ZFS installed)")
+	disableShares := flag.Bool("disable-shares", false, "Disable share features (no Samba/NFS installed)")
+	disableDisks := flag.Bool("disable-disks", false, "Disable disk discovery and SMART features")
+	anonymousRead := flag.Bool("anonymous-read", false, "Allow read-only API access without login (trusted networks only)")
+	confirmDestructive := flag.Bool("confirm-destructive", false, "Require a confirmation token for destructive API calls")
+	tokenDuration := flag.Duration("token-duration", 24*time.Hour, "How long a login token is valid (the idle timeout with -token-refresh)")
+	tokenRefresh := flag.Bool("token-refresh", false, "Renew tokens on activity so sessions expire only after -token-duration idle")
+	tokenMaxLifetime := flag.Duration("token-max-lifetime", 7*24*time.Hour, "Absolute session lifetime with -token-refresh (0 for no cap)")
+	smtpAddr := flag.String("smtp-addr", "", "SMTP server host:port for email notification channels (unless set through the API)")
+	smtpFrom := flag.String("smtp-from", "mynt@localhost", "Sender address for notification emails")
+	smtpUser := flag.String("smtp-user", "", "SMTP username (password from MYNT_SMTP_PASSWORD)")
+	emailDigestWindow := flag.Duration("email-digest-window", notify.DefaultDigestWindow, "How long events are collected into one notification email")
+	maxBodyBytes := flag.Int64("max-body-bytes", api.DefaultMaxBodyBytes, "Maximum size of JSON request bodies in bytes")
 	flag.Parse()
 
 	// Initialize logger
@@ -49,6 +85,9 @@ func main() {
 		Format: *logFormat,
 	})
 
+	build := version.Get()
+	logger.Info("starting myntd", "version", build.Version, "commit", build.Commit, "build_date", build.BuildDate)
+
 	// Database
 	db, err := store.Open(*dbPath)
 	if err != nil {
@@ -67,32 +106,58 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Event bus with persistence
+	bus := event.NewBus()
+	notificationRepo := store.NewNotificationRepo(db)
+	snapshotPolicyRepo := store.NewSnapshotPolicyRepo(db)
+	bus.SetPersister(notificationRepo)
+
 	// Task manager
-	mgr, err := task.New(store.NewTaskRepo(db))
+	mgr, err := task.New(store.NewTaskRepo(db), task.WithEventBus(bus))
 	if err != nil {
 		logger.Error("failed to initialize task manager", "error", err)
 		os.Exit(1)
 	}
 
 	// ZFS
-	pools := zfs.NewManager()
-
-	// Event bus with persistence
-	bus := event.NewBus()
-	notificationRepo := store.NewNotificationRepo(db)
-	snapshotPolicyRepo := store.NewSnapshotPolicyRepo(db)
-	bus.SetPersister(notificationRepo)
+	templateRepo := store.NewDatasetTemplateRepo(db)
+	pools := zfs.NewManager(
+		zfs.WithTemplateSource(templateRepo),
+		zfs.WithRetryPolicy(zfs.RetryPolicy{Attempts: *zfsRetries, Backoff: *zfsRetryBackoff}),
+		zfs.WithMaxDiffEntries(*diffMaxEntries),
+		zfs.WithBackupRoot(*backupRoot),
+	)
 
 	// Share manager
 	shareRepo := store.NewShareRepo(db)
-	shareMgr := share.NewManager(shareRepo, *smbConfig)
+	if *smbLogLevel < 0 || *smbLogLevel > share.MaxLogLevel {
+		logger.Error("invalid smb log level", "level", *smbLogLevel, "max", share.MaxLogLevel)
+		os.Exit(1)
+	}
+	shareOpts := []share.Option{share.WithLogLevel(*smbLogLevel)}
+	if !*disableZFS {
+		shareOpts = append(shareOpts, share.WithDatasets(pools, *strictSharePaths))
+	}
+	shareMgr := share.NewManager(shareRepo, *smbConfig, shareOpts...)
 
 	// User manager
 	userRepo := store.NewUserRepo(db)
 	userMgr := user.NewManager(userRepo)
 
+	if *maxBodyBytes <= 0 {
+		logger.Error("invalid max body bytes, must be positive", "bytes", *maxBodyBytes)
+		os.Exit(1)
+	}
+
 	// Auth config
+	if *tokenDuration <= 0 {
+		logger.Error("invalid token duration, must be positive", "duration", *tokenDuration)
+		os.Exit(1)
+	}
 	authConfig := auth.DefaultConfig(jwtSecret)
+	authConfig.TokenDuration = *tokenDuration
+	authConfig.RefreshEnabled = *tokenRefresh
+	authConfig.MaxLifetime = *tokenMaxLifetime
 
 	// Monitoring with disk repository
 	diskRepo := store.NewDiskRepo(db)
@@ -103,29 +168,91 @@ func main() {
 		diskOpts = append(diskOpts, disk.WithLoopDevices())
 	}
 	diskOpts = append(diskOpts, disk.WithSmartCache(diskRepo.NewSmartCache()))
+	diskOpts = append(diskOpts, disk.WithDeviceTypeSource(diskRepo))
 	diskMgr := disk.NewManager(diskOpts...)
 
+	// Intervals changed at runtime are persisted and override the defaults
+	diskEvery, smartEvery, zfsEvery := monitorInterval, smartInterval, monitorInterval
+	if d, sm, z, err := configRepo.ScanIntervals(); err != nil {
+		logger.Warn("failed to load scan intervals", "error", err)
+	} else {
+		diskEvery = cmp.Or(d, diskEvery)
+		smartEvery = cmp.Or(sm, smartEvery)
+		zfsEvery = cmp.Or(z, zfsEvery)
+	}
+
 	// Scanners with different intervals:
-	// - DiskScanner: fast disk detection (every 30s)
-	// - SmartScanner: SMART data collection (every 5 min, throttled internally)
-	// - ZFSScanner: pool status (every 30s)
+	// - DiskScanner: fast disk detection (every disk monitor tick)
+	// - SmartScanner: SMART data collection (throttled internally)
+	// - ZFSScanner: pool status (every ZFS monitor tick)
+	// - NetworkScanner: interface error counters (every disk monitor tick)
+	// - NotificationPruner: notification retention (every hour)
+	// - MetricsScanner: system metric history (every minute)
+	// Threshold alerts on disk temperature and pool capacity, evaluated
+	// by the SMART and ZFS scanners
+	alertRules, err := configRepo.AlertRules()
+	if err != nil {
+		logger.Warn("failed to load alert rules, using defaults", "error", err)
+		alertRules = alert.DefaultRules
+	}
+	alerts := alert.NewEvaluator(bus, alertRules)
+
 	diskScanner := monitor.NewDiskScanner(bus, diskRepo, diskMgr)
-	smartScanner := monitor.NewSmartScanner(bus, diskRepo, diskMgr, 5*time.Minute)
-	zfsScanner := monitor.NewZFSScanner(bus, pools)
-	scanners := []monitor.Scanner{diskScanner, smartScanner, zfsScanner}
-	mon := monitor.New(scanners, 30*time.Second)
+	smartScanner := monitor.NewSmartScanner(bus, diskRepo, diskMgr, smartEvery)
+	zfsScanner := monitor.NewZFSScanner(bus, pools, diskRepo, *capacityRetention)
+	smartScanner.SetAlerts(alerts)
+	zfsScanner.SetAlerts(alerts)
+	notificationPruner := monitor.NewNotificationPruner(notificationRepo, *notificationRetention, *notificationMax, time.Hour)
+	networkScanner := monitor.NewNetworkScanner(bus, sysinfo.NewCollector())
+	metricsRepo := store.NewMetricsRepo(db)
+	metricsScanner := monitor.NewMetricsScanner(metricsRepo, sysinfo.NewCollector(), *metricsRetention)
+	scanners := []monitor.Scanner{notificationPruner, networkScanner, metricsScanner}
+	if !*disableDisks {
+		scanners = append(scanners, diskScanner, smartScanner)
+	}
+	mon := monitor.New(scanners, diskEvery)
+	zfsMon := monitor.New([]monitor.Scanner{zfsScanner}, zfsEvery)
 
 	ctx := context.Background()
 	mon.Start(ctx)
 	defer mon.Stop()
+	if !*disableZFS {
+		zfsMon.Start(ctx)
+		defer zfsMon.Stop()
+	}
 
-	// Snapshot Policy Scheduler
-	snapshotScheduler := scheduler.New(snapshotPolicyRepo, pools)
-	if err := snapshotScheduler.Start(ctx); err != nil {
-		logger.Error("failed to start snapshot scheduler", "error", err)
-		os.Exit(1)
+	// Notification channels: route events to email, webhooks and the log
+	channelRepo := store.NewNotificationChannelRepo(db)
+	// SMTP settings saved through the API take precedence over the flags
+	smtpConfig, err := configRepo.SMTPConfig()
+	if err != nil {
+		logger.Warn("failed to load SMTP settings", "error", err)
+	}
+	if smtpConfig == nil {
+		smtpConfig = smtpFlagConfig(*smtpAddr, *smtpFrom, *smtpUser, os.Getenv("MYNT_SMTP_PASSWORD"))
+	}
+	emailSender := notify.NewEmailSender(*smtpConfig, *emailDigestWindow)
+	webhookRepo := store.NewWebhookRepo(db)
+	dispatcher := notify.NewDispatcher(bus, channelRepo,
+		notify.WithSender(store.ChannelEmail, emailSender),
+		notify.WithWebhooks(webhookRepo))
+	dispatcher.Start(ctx)
+	defer dispatcher.Stop()
+
+	// Snapshot Policy Scheduler, also running SMART self-test policies
+	smartPolicyRepo := store.NewSmartTestPolicyRepo(db)
+	var schedOpts []scheduler.Option
+	if !*disableDisks {
+		schedOpts = append(schedOpts, scheduler.WithSmartTests(smartPolicyRepo, diskRepo, diskMgr))
+	}
+	snapshotScheduler := scheduler.New(snapshotPolicyRepo, pools, schedOpts...)
+	if !*disableZFS {
+		if err := snapshotScheduler.Start(ctx); err != nil {
+			logger.Error("failed to start snapshot scheduler", "error", err)
+			os.Exit(1)
+		}
+		defer snapshotScheduler.Stop()
 	}
-	defer snapshotScheduler.Stop()
 
 	// Check initialization status
 	initialized, _ := configRepo.IsInitialized()
@@ -135,7 +262,54 @@ func main() {
 	}
 
 	// API Server with authentication
-	srv := api.NewServer(pools, diskMgr, bus, mgr, shareMgr, userMgr, configRepo, notificationRepo, snapshotPolicyRepo, diskRepo, authConfig, func() { _ = snapshotScheduler.Reload() })
+	caps := sysinfo.NewProber().Probe(ctx)
+	logger.Debug("host capabilities probed", "capabilities", caps)
+	srvOpts := []api.Option{
+		api.WithScanIntervals(scanIntervals(mon, zfsMon, smartScanner)),
+		api.WithIntervalUpdates(func(iv api.ScanIntervals) api.ScanIntervals {
+			mon.SetInterval(iv.Disk)
+			smartScanner.SetInterval(iv.Smart)
+			zfsMon.SetInterval(iv.ZFS)
+			logger.Info("scan intervals changed", "disk", iv.Disk, "smart", iv.Smart, "zfs", iv.ZFS)
+			return scanIntervals(mon, zfsMon, smartScanner)
+		}),
+		api.WithCapabilities(caps),
+		api.WithMaxBodyBytes(*maxBodyBytes),
+		api.WithDatasetTemplates(templateRepo),
+		api.WithNotificationChannels(channelRepo),
+		api.WithChannelChanges(dispatcher.InvalidateChannels),
+		api.WithSmartTestPolicies(smartPolicyRepo),
+		api.WithMetrics(metricsRepo),
+		api.WithAlertRules(alerts),
+		api.WithEmail(emailSender),
+		api.WithWebhooks(webhookRepo),
+		api.WithWebhookChanges(dispatcher.InvalidateWebhooks),
+	}
+	if *anonymousRead {
+		logger.Warn("anonymous read-only API access enabled")
+		srvOpts = append(srvOpts, api.WithAnonymousRead())
+	}
+	var disabled []api.Subsystem
+	if *disableZFS {
+		disabled = append(disabled, api.SubsystemZFS)
+	}
+	if *disableShares {
+		disabled = append(disabled, api.SubsystemShares)
+	}
+	if *disableDisks {
+		disabled = append(disabled, api.SubsystemDisks)
+	}
+	if len(disabled) > 0 {
+		logger.Info("subsystems disabled", "subsystems", disabled)
+		srvOpts = append(srvOpts, api.WithDisabled(disabled...))
+	}
+	if *confirmDestructive {
+		srvOpts = append(srvOpts, api.WithConfirmation(2*time.Minute))
+	}
+	if !*disableZFS {
+		srvOpts = append(srvOpts, api.WithPolicyReload(snapshotScheduler.Reload))
+	}
+	srv := api.NewServer(pools, diskMgr, bus, mgr, shareMgr, userMgr, configRepo, notificationRepo, snapshotPolicyRepo, diskRepo, authConfig, func() { _ = snapshotScheduler.Reload() }, srvOpts...)
 	httpSrv := &http.Server{
 		Addr:    *addr,
 		Handler: srv,
@@ -166,3 +340,30 @@ func main() {
 
 	logger.Info("server exited")
 }
+
+// scanIntervals reports the effective scan intervals of the disk and ZFS
+// monitors. A throttled scanner can never run more often than its monitor
+// ticks.
+func scanIntervals(disks, pools *monitor.Monitor, smart *monitor.SmartScanner) api.ScanIntervals {
+	return api.ScanIntervals{
+		Disk:  disks.Interval(),
+		Smart: max(disks.Interval(), smart.Interval()),
+		ZFS:   pools.Interval(),
+	}
+}
+
+// smtpFlagConfig builds SMTP settings from the -smtp-* flags. An empty
+// addr leaves email disabled; a missing port defaults to 587.
+func smtpFlagConfig(addr, from, user, password string) *store.SMTPConfig {
+	cfg := &store.SMTPConfig{From: from, Username: user, Password: password}
+	if addr == "" {
+		return cfg
+	}
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		host, port = addr, "587"
+	}
+	cfg.Host = host
+	cfg.Port, _ = strconv.Atoi(port)
+	return cfg
+}
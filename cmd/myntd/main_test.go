@@ -0,0 +1,27 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"go.aimuz.me/mynt/monitor"
+)
+
+func TestScanIntervals(t *testing.T) {
+	mon := monitor.New(nil, monitorInterval)
+	smart := monitor.NewSmartScanner(nil, nil, nil, smartInterval)
+
+	got := scanIntervals(mon, mon, smart)
+	if got.Disk != 30*time.Second || got.ZFS != 30*time.Second {
+		t.Errorf("disk/zfs interval = %s/%s, want 30s", got.Disk, got.ZFS)
+	}
+	if got.Smart != 5*time.Minute {
+		t.Errorf("smart interval = %s, want 5m", got.Smart)
+	}
+
+	// SMART cannot be collected more often than the monitor ticks
+	fast := monitor.NewSmartScanner(nil, nil, nil, time.Second)
+	if got := scanIntervals(mon, mon, fast).Smart; got != monitorInterval {
+		t.Errorf("smart interval = %s, want %s", got, monitorInterval)
+	}
+}
@@ -0,0 +1,26 @@
+package disk
+
+// Available returns the disks that can be used to build a pool: not ZFS
+// members, not the system disk and not write-protected. Formatted or
+// partitioned disks are included only if includeUsed is set, since
+// building a pool on them destroys their contents.
+func Available(disks []Info, includeUsed bool) []Info {
+	available := []Info{}
+	for _, d := range disks {
+		if d.ReadOnly || d.Pool != "" {
+			continue
+		}
+		if d.Usage != nil {
+			switch d.Usage.Type {
+			case UsageTypeZFSMember, UsageTypeSystem:
+				continue
+			case UsageTypeFormatted, UsageTypePartitions:
+				if !includeUsed {
+					continue
+				}
+			}
+		}
+		available = append(available, d)
+	}
+	return available
+}
@@ -0,0 +1,36 @@
+package disk
+
+import (
+	"context"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// batchConcurrency bounds how many disks a batch operation touches at once.
+const batchConcurrency = 4
+
+// BatchResult is the outcome of a batch operation on one disk.
+type BatchResult struct {
+	Disk  string `json:"disk"`
+	Error string `json:"error,omitempty"`
+}
+
+// Batch applies op to each disk concurrently and returns one result per
+// disk, in input order. A failure on one disk does not stop the others.
+func Batch(ctx context.Context, names []string, op func(ctx context.Context, name string) error) []BatchResult {
+	results := make([]BatchResult, len(names))
+
+	var g errgroup.Group
+	g.SetLimit(batchConcurrency)
+	for i, name := range names {
+		results[i].Disk = name
+		g.Go(func() error {
+			if err := op(ctx, name); err != nil {
+				results[i].Error = err.Error()
+			}
+			return nil
+		})
+	}
+	g.Wait()
+	return results
+}
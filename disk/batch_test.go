@@ -0,0 +1,50 @@
+package disk
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBatch_PartialFailure(t *testing.T) {
+	names := []string{"sda", "sdb", "sdc", "sdd", "sde", "sdf"}
+
+	var running, peak atomic.Int32
+	results := Batch(context.Background(), names, func(ctx context.Context, name string) error {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+
+		if name == "sdc" {
+			return errors.New("device open failed")
+		}
+		return nil
+	})
+
+	if len(results) != len(names) {
+		t.Fatalf("got %d results, want %d", len(results), len(names))
+	}
+	for i, r := range results {
+		if r.Disk != names[i] {
+			t.Errorf("results[%d].Disk = %q, want %q", i, r.Disk, names[i])
+		}
+		wantErr := ""
+		if r.Disk == "sdc" {
+			wantErr = "device open failed"
+		}
+		if r.Error != wantErr {
+			t.Errorf("%s: Error = %q, want %q", r.Disk, r.Error, wantErr)
+		}
+	}
+	if p := peak.Load(); p > batchConcurrency {
+		t.Errorf("peak concurrency = %d, want <= %d", p, batchConcurrency)
+	}
+}
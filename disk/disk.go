@@ -3,9 +3,13 @@ package disk
 
 import (
 	"context"
+	"fmt"
+	"sync"
+	"time"
 
 	"go.aimuz.me/mynt/logger"
 	"go.aimuz.me/mynt/sysexec"
+	"golang.org/x/sync/singleflight"
 )
 
 // Type represents the technology of a disk.
@@ -71,6 +75,10 @@ type Info struct {
 	Status      Status      `json:"status"`
 	SmartHealth SmartHealth `json:"smart_health"`
 	Temperature int         `json:"temperature"`
+	ReadOnly    bool        `json:"read_only"` // Write-protected, e.g. by the kernel after errors
+
+	// HealthReasons explains a non-healthy Status in plain words.
+	HealthReasons []string `json:"health_reasons,omitempty"`
 }
 
 // SmartCache provides cached SMART data.
@@ -88,13 +96,29 @@ type CachedSmart struct {
 	UncorrectableErrors int64
 }
 
+// DeviceTypeSource provides per-disk smartctl device type overrides
+// (e.g. "sat" or "usbjmicron" for USB-SATA bridges).
+type DeviceTypeSource interface {
+	SmartDeviceType(name string) (string, error)
+}
+
 // Manager handles disk operations.
 type Manager struct {
 	exec               sysexec.Executor
 	includeLoopDevices bool
 	cache              SmartCache
+	deviceTypes        DeviceTypeSource
+	smartFlight        singleflight.Group // dedupes concurrent smartctl reads
+
+	// SMART data read live on the first listing, used until the cache fills
+	initialSmartOnce sync.Once
+	initialSmart     map[string]*CachedSmart
 }
 
+// initialSmartTimeout bounds the live SMART collection done when the cache
+// is still empty, so a slow or hung disk cannot stall the first listing.
+const initialSmartTimeout = 10 * time.Second
+
 // ManagerOption configures a Manager.
 type ManagerOption func(*Manager)
 
@@ -108,6 +132,11 @@ func WithSmartCache(c SmartCache) ManagerOption {
 	return func(m *Manager) { m.cache = c }
 }
 
+// WithDeviceTypeSource sets the source of per-disk smartctl device type overrides.
+func WithDeviceTypeSource(src DeviceTypeSource) ManagerOption {
+	return func(m *Manager) { m.deviceTypes = src }
+}
+
 // NewManager creates a new disk manager.
 func NewManager(opts ...ManagerOption) *Manager {
 	m := &Manager{exec: sysexec.NewExecutor()}
@@ -130,6 +159,10 @@ func (m *Manager) List(ctx context.Context) ([]Info, error) {
 		if err != nil {
 			logger.Debug("failed to load SMART cache", "error", err)
 		}
+		if len(smartMap) == 0 {
+			// First boot: the SmartScanner has not filled the cache yet
+			smartMap = m.collectInitialSmart(ctx, disks)
+		}
 		for i := range disks {
 			if s, ok := smartMap[disks[i].Name]; ok {
 				enrichFromCache(&disks[i], s)
@@ -140,14 +173,48 @@ func (m *Manager) List(ctx context.Context) ([]Info, error) {
 	return disks, nil
 }
 
+// collectInitialSmart reads SMART data directly from the disks, once per
+// Manager. The result is kept and reused while the cache stays empty. The
+// read ignores the cancellation of ctx, so a first caller that goes away
+// does not leave every later caller with an empty result.
+func (m *Manager) collectInitialSmart(ctx context.Context, disks []Info) map[string]*CachedSmart {
+	m.initialSmartOnce.Do(func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), initialSmartTimeout)
+		defer cancel()
+
+		m.initialSmart = make(map[string]*CachedSmart, len(disks))
+		for _, d := range disks {
+			report, err := m.SmartDetails(ctx, d.Name)
+			if err != nil {
+				logger.Debug("initial SMART read failed", "disk", d.Name, "error", err)
+				continue
+			}
+			m.initialSmart[d.Name] = &CachedSmart{
+				Passed:              report.Passed,
+				Temperature:         report.Temperature,
+				ReallocatedSectors:  report.ReallocatedSectors,
+				PendingSectors:      report.PendingSectors,
+				UncorrectableErrors: report.UncorrectableErrors,
+			}
+		}
+	})
+	return m.initialSmart
+}
+
 // ListBasic returns disks without SMART data (fast).
 func (m *Manager) ListBasic(ctx context.Context) ([]Info, error) {
 	return m.listBasic(ctx)
 }
 
-// enrichFromCache populates Info from cached SMART data.
+// TempWarning is the drive temperature in °C above which a disk is
+// reported as a warning.
+const TempWarning = 55
+
+// enrichFromCache populates Info from cached SMART data and records the
+// reasons behind a non-healthy status.
 func enrichFromCache(info *Info, s *CachedSmart) {
 	info.Temperature = s.Temperature
+	info.HealthReasons = nil
 
 	if s.Passed {
 		info.SmartHealth = SmartHealthGood
@@ -156,6 +223,13 @@ func enrichFromCache(info *Info, s *CachedSmart) {
 		}
 	} else {
 		info.SmartHealth = SmartHealthFailed
+		info.HealthReasons = append(info.HealthReasons, "SMART overall FAILED")
+	}
+	if s.ReallocatedSectors > 0 {
+		info.HealthReasons = append(info.HealthReasons, fmt.Sprintf("%d reallocated sectors", s.ReallocatedSectors))
+	}
+	if s.PendingSectors > 0 {
+		info.HealthReasons = append(info.HealthReasons, fmt.Sprintf("%d pending sectors", s.PendingSectors))
 	}
 
 	switch info.SmartHealth {
@@ -166,4 +240,12 @@ func enrichFromCache(info *Info, s *CachedSmart) {
 	case SmartHealthFailed:
 		info.Status = StatusFailed
 	}
+
+	if s.Temperature > TempWarning {
+		info.HealthReasons = append(info.HealthReasons,
+			fmt.Sprintf("temperature %d°C exceeds warn threshold %d°C", s.Temperature, TempWarning))
+		if info.Status == StatusHealthy {
+			info.Status = StatusWarning
+		}
+	}
 }
@@ -4,26 +4,35 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
 	"strings"
+
+	"go.aimuz.me/mynt/logger"
 )
 
+// sysBlockDir is where the kernel exposes block device attributes.
+var sysBlockDir = "/sys/block"
+
 // lsblkDevice represents a block device from lsblk output.
 type lsblkDevice struct {
-	Name     string        `json:"name"`
-	Path     string        `json:"path"`
-	Model    string        `json:"model"`
-	Serial   string        `json:"serial"`
-	Size     uint64        `json:"size"`
-	Rota     bool          `json:"rota"`
-	Type     string        `json:"type"`
-	Fstype   string        `json:"fstype"`
-	Label    string        `json:"label"`
-	Children []lsblkDevice `json:"children,omitempty"`
+	Name       string        `json:"name"`
+	Path       string        `json:"path"`
+	Model      string        `json:"model"`
+	Serial     string        `json:"serial"`
+	Size       uint64        `json:"size"`
+	Rota       bool          `json:"rota"`
+	Type       string        `json:"type"`
+	Fstype     string        `json:"fstype"`
+	Label      string        `json:"label"`
+	Mountpoint string        `json:"mountpoint"`
+	Children   []lsblkDevice `json:"children,omitempty"`
 }
 
 // listBasic returns all physical disks without SMART data (fast).
 func (m *Manager) listBasic(ctx context.Context) ([]Info, error) {
-	out, err := m.exec.Output(ctx, "lsblk", "-J", "-b", "-o", "NAME,PATH,MODEL,SERIAL,SIZE,ROTA,TYPE,FSTYPE,LABEL")
+	out, err := m.exec.Output(ctx, "lsblk", "-J", "-b", "-o", "NAME,PATH,MODEL,SERIAL,SIZE,ROTA,TYPE,FSTYPE,LABEL,MOUNTPOINT")
 	if err != nil {
 		return nil, fmt.Errorf("lsblk: %w", err)
 	}
@@ -57,12 +66,35 @@ func (m *Manager) listBasic(ctx context.Context) ([]Info, error) {
 			SmartHealth: SmartHealthUnknown,
 		}
 
+		ro, err := readOnly(sysBlockDir, d.Name)
+		if err != nil {
+			logger.Debug("failed to read disk read-only flag", "disk", d.Name, "error", err)
+		}
+		info.ReadOnly = ro
+
 		setUsage(&info, &d)
 		disks = append(disks, info)
 	}
 	return disks, nil
 }
 
+// readOnly reports whether the kernel marks a block device read-only,
+// as exposed in <dir>/<name>/ro.
+func readOnly(dir, name string) (bool, error) {
+	b, err := os.ReadFile(filepath.Join(dir, name, "ro"))
+	if err != nil {
+		return false, err
+	}
+	switch v := strings.TrimSpace(string(b)); v {
+	case "0":
+		return false, nil
+	case "1":
+		return true, nil
+	default:
+		return false, fmt.Errorf("unexpected ro value %q", v)
+	}
+}
+
 // diskType infers disk technology from device name and rotation flag.
 func diskType(name string, rota bool) Type {
 	if strings.HasPrefix(name, "nvme") {
@@ -76,14 +108,16 @@ func diskType(name string, rota bool) Type {
 
 // setUsage determines if a disk is in use and why.
 func setUsage(info *Info, d *lsblkDevice) {
+	if hasSystemMount(d) {
+		info.InUse = true
+		info.Usage = &UsageInfo{Type: UsageTypeSystem}
+		return
+	}
+
 	if d.Fstype != "" {
 		info.InUse = true
 		if d.Fstype == "zfs_member" {
-			info.Usage = &UsageInfo{Type: UsageTypeZFSMember}
-			if d.Label != "" {
-				info.Usage.Params = map[string]string{"pool": d.Label}
-				info.Pool = d.Label
-			}
+			setZFSMember(info, d.Label)
 		} else {
 			info.Usage = &UsageInfo{
 				Type:   UsageTypeFormatted,
@@ -94,10 +128,41 @@ func setUsage(info *Info, d *lsblkDevice) {
 	}
 
 	for _, c := range d.Children {
-		if c.Type == "part" {
-			info.InUse = true
-			info.Usage = &UsageInfo{Type: UsageTypePartitions}
+		if c.Type != "part" {
+			continue
+		}
+		info.InUse = true
+		// ZFS partitions the whole disks it is given
+		if c.Fstype == "zfs_member" {
+			setZFSMember(info, c.Label)
 			return
 		}
+		info.Usage = &UsageInfo{Type: UsageTypePartitions}
+	}
+}
+
+// setZFSMember marks a disk as a member of the pool named label.
+func setZFSMember(info *Info, label string) {
+	info.Usage = &UsageInfo{Type: UsageTypeZFSMember}
+	if label != "" {
+		info.Usage.Params = map[string]string{"pool": label}
+		info.Pool = label
+	}
+}
+
+// systemMounts are mountpoints that make a disk the system disk.
+var systemMounts = []string{"/", "/boot", "/boot/efi", "/usr", "/var", "[SWAP]"}
+
+// hasSystemMount reports whether the device or anything stacked on it
+// (partitions, LVM, dm-crypt) holds a system mountpoint.
+func hasSystemMount(d *lsblkDevice) bool {
+	if slices.Contains(systemMounts, d.Mountpoint) {
+		return true
+	}
+	for i := range d.Children {
+		if hasSystemMount(&d.Children[i]) {
+			return true
+		}
 	}
+	return false
 }
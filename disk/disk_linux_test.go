@@ -0,0 +1,197 @@
+package disk
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"go.aimuz.me/mynt/sysexec"
+)
+
+func TestReadOnly(t *testing.T) {
+	dir := t.TempDir()
+	for name, val := range map[string]string{
+		"sda": "0\n",
+		"sdb": "1\n",
+		"sdc": "garbage\n",
+	} {
+		if err := os.MkdirAll(filepath.Join(dir, name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name, "ro"), []byte(val), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		want    bool
+		wantErr bool
+	}{
+		{"sda", false, false},
+		{"sdb", true, false},
+		{"sdc", false, true},
+		{"sdz", false, true}, // missing device
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readOnly(dir, tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("readOnly() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("readOnly() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListBasic_ReadOnly(t *testing.T) {
+	dir := t.TempDir()
+	for name, val := range map[string]string{"sda": "0", "sdb": "1"} {
+		if err := os.MkdirAll(filepath.Join(dir, name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name, "ro"), []byte(val), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	old := sysBlockDir
+	sysBlockDir = dir
+	t.Cleanup(func() { sysBlockDir = old })
+
+	exec := sysexec.NewMock()
+	exec.SetOutput("lsblk", []byte(`{"blockdevices":[
+		{"name":"sda","path":"/dev/sda","serial":"A","size":1,"rota":true,"type":"disk"},
+		{"name":"sdb","path":"/dev/sdb","serial":"B","size":1,"rota":true,"type":"disk"}]}`))
+	m := &Manager{exec: exec}
+
+	disks, err := m.listBasic(context.Background())
+	if err != nil {
+		t.Fatalf("listBasic() error = %v", err)
+	}
+	got := map[string]bool{}
+	for _, d := range disks {
+		got[d.Name] = d.ReadOnly
+	}
+	if got["sda"] || !got["sdb"] {
+		t.Errorf("read-only flags = %v, want sda=false sdb=true", got)
+	}
+}
+
+func TestListBasic_Available(t *testing.T) {
+	old := sysBlockDir
+	sysBlockDir = t.TempDir()
+	t.Cleanup(func() { sysBlockDir = old })
+
+	exec := sysexec.NewMock()
+	exec.SetOutput("lsblk", []byte(`{"blockdevices":[
+		{"name":"sda","path":"/dev/sda","serial":"A","size":1,"type":"disk","children":[
+			{"name":"sda1","type":"part","fstype":"vfat","mountpoint":"/boot/efi"},
+			{"name":"sda2","type":"part","fstype":"LVM2_member","children":[
+				{"name":"vg-root","type":"lvm","fstype":"ext4","mountpoint":"/"}]}]},
+		{"name":"sdb","path":"/dev/sdb","serial":"B","size":1,"type":"disk","children":[
+			{"name":"sdb1","type":"part","fstype":"zfs_member","label":"tank"},
+			{"name":"sdb9","type":"part"}]},
+		{"name":"sdc","path":"/dev/sdc","model":"WDC WD40EFRX","serial":"C","size":4000787030016,"rota":true,"type":"disk"},
+		{"name":"sdd","path":"/dev/sdd","serial":"D","size":1,"type":"disk","fstype":"ext4"},
+		{"name":"sde","path":"/dev/sde","serial":"E","size":1,"type":"disk","children":[
+			{"name":"sde1","type":"part"}]}]}`))
+	m := &Manager{exec: exec}
+
+	disks, err := m.listBasic(context.Background())
+	if err != nil {
+		t.Fatalf("listBasic() error = %v", err)
+	}
+	names := func(disks []Info) []string {
+		var n []string
+		for _, d := range disks {
+			n = append(n, d.Name)
+		}
+		return n
+	}
+
+	free := Available(disks, false)
+	if got := names(free); !slices.Equal(got, []string{"sdc"}) {
+		t.Fatalf("Available() = %v, want [sdc]", got)
+	}
+	if free[0].Size != 4000787030016 || free[0].Model != "WDC WD40EFRX" {
+		t.Errorf("sdc size/model = %d/%q", free[0].Size, free[0].Model)
+	}
+	if got := names(Available(disks, true)); !slices.Equal(got, []string{"sdc", "sdd", "sde"}) {
+		t.Errorf("Available(includeUsed) = %v, want [sdc sdd sde]", got)
+	}
+}
+
+// staticSmartCache is a SmartCache backed by a map.
+type staticSmartCache map[string]*CachedSmart
+
+func (c staticSmartCache) GetSmart(name string) (*CachedSmart, error) {
+	return c[name], nil
+}
+
+func (c staticSmartCache) ListSmart() (map[string]*CachedSmart, error) {
+	return c, nil
+}
+
+func TestList_EmptySmartCache(t *testing.T) {
+	old := sysBlockDir
+	sysBlockDir = t.TempDir()
+	t.Cleanup(func() { sysBlockDir = old })
+
+	exec := sysexec.NewMock()
+	exec.SetOutput("lsblk", []byte(`{"blockdevices":[
+		{"name":"sda","path":"/dev/sda","serial":"A","size":1,"rota":true,"type":"disk"}]}`))
+	exec.SetOutput("smartctl", []byte(`{"smart_status":{"passed":true},"temperature":{"current":35}}`))
+	m := &Manager{exec: exec, cache: staticSmartCache{}}
+
+	for range 2 {
+		disks, err := m.List(context.Background())
+		if err != nil {
+			t.Fatalf("List() error = %v", err)
+		}
+		if len(disks) != 1 {
+			t.Fatalf("got %d disks, want 1", len(disks))
+		}
+		if disks[0].SmartHealth != SmartHealthGood || disks[0].Temperature != 35 {
+			t.Errorf("health = %q, temperature = %d, want good, 35", disks[0].SmartHealth, disks[0].Temperature)
+		}
+	}
+
+	smartctl := 0
+	for _, c := range exec.Commands() {
+		if c.Name == "smartctl" {
+			smartctl++
+		}
+	}
+	if smartctl != 1 {
+		t.Errorf("smartctl ran %d times, want 1", smartctl)
+	}
+}
+
+func TestList_PopulatedSmartCache(t *testing.T) {
+	old := sysBlockDir
+	sysBlockDir = t.TempDir()
+	t.Cleanup(func() { sysBlockDir = old })
+
+	exec := sysexec.NewMock()
+	exec.SetOutput("lsblk", []byte(`{"blockdevices":[
+		{"name":"sda","path":"/dev/sda","serial":"A","size":1,"rota":true,"type":"disk"}]}`))
+	m := &Manager{exec: exec, cache: staticSmartCache{"sda": {Passed: false}}}
+
+	disks, err := m.List(context.Background())
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if disks[0].SmartHealth != SmartHealthFailed {
+		t.Errorf("health = %q, want %q", disks[0].SmartHealth, SmartHealthFailed)
+	}
+	for _, c := range exec.Commands() {
+		if c.Name == "smartctl" {
+			t.Error("smartctl ran with a populated cache")
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package disk
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestEnrichFromCache_HealthReasons(t *testing.T) {
+	tests := []struct {
+		name    string
+		smart   CachedSmart
+		status  Status
+		reasons []string
+	}{
+		{
+			name:   "pending sectors and high temperature",
+			smart:  CachedSmart{Passed: true, Temperature: 58, PendingSectors: 5},
+			status: StatusWarning,
+			reasons: []string{
+				"5 pending sectors",
+				"temperature 58°C exceeds warn threshold 55°C",
+			},
+		},
+		{
+			name:   "healthy",
+			smart:  CachedSmart{Passed: true, Temperature: 40},
+			status: StatusHealthy,
+		},
+		{
+			name:    "failed",
+			smart:   CachedSmart{Passed: false, ReallocatedSectors: 12},
+			status:  StatusFailed,
+			reasons: []string{"SMART overall FAILED", "12 reallocated sectors"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var info Info
+			enrichFromCache(&info, &tt.smart)
+			if info.Status != tt.status {
+				t.Errorf("status = %s, want %s", info.Status, tt.status)
+			}
+			if !slices.Equal(info.HealthReasons, tt.reasons) {
+				t.Errorf("reasons = %q, want %q", info.HealthReasons, tt.reasons)
+			}
+		})
+	}
+}
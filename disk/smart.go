@@ -3,12 +3,16 @@ package disk
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os/exec"
+	"regexp"
 	"runtime"
 	"strconv"
 	"strings"
 	"time"
+
+	"go.aimuz.me/mynt/logger"
 )
 
 // SMART attribute IDs of interest.
@@ -18,8 +22,15 @@ const (
 	attrTemperature        = 194
 	attrPendingSectors     = 197
 	attrUncorrectable      = 198
+	attrWearLeveling       = 177 // Samsung and others: normalized value is remaining life
+	attrMediaWearout       = 233 // Intel: normalized value is remaining life
+	attrTotalLBAsWritten   = 241
 )
 
+// WearThreshold is the share of rated SSD endurance, in percent, used up
+// beyond which a disk's wear is flagged.
+const WearThreshold = 80
+
 // Attribute represents a single S.M.A.R.T. attribute.
 type Attribute struct {
 	ID     int    `json:"id"`
@@ -29,6 +40,9 @@ type Attribute struct {
 	Thresh int    `json:"thresh"`
 	Raw    string `json:"raw"`
 	Status string `json:"status"` // "OK" or "FAILING"
+
+	Critical       bool   `json:"critical"`                 // Indicates failing or soon-failing media
+	Interpretation string `json:"interpretation,omitempty"` // Plain-language meaning
 }
 
 // Report represents a S.M.A.R.T. health report.
@@ -67,6 +81,82 @@ type DetailedReport struct {
 	PendingSectors      int64       `json:"pending_sectors"`
 	UncorrectableErrors int64       `json:"uncorrectable_errors"`
 	Temperature         int         `json:"temperature"`
+
+	// SSD endurance. PercentageUsed is reported by NVMe drives, which may
+	// exceed 100; WearLevelingCount is the normalized remaining life (100
+	// is new) from SATA attribute 177 or 233. DataUnitsWritten is in NVMe
+	// units of 512,000 bytes.
+	PercentageUsed    *int  `json:"percentage_used,omitempty"`
+	WearLevelingCount *int  `json:"wear_leveling_count,omitempty"`
+	DataUnitsWritten  int64 `json:"data_units_written,omitempty"`
+	TotalLBAsWritten  int64 `json:"total_lbas_written,omitempty"`
+	WearWarning       bool  `json:"wear_warning"` // wear is past WearThreshold
+
+	// NVMe health log. AvailableSpare is the remaining spare capacity in
+	// percent; the drive warns when it drops below AvailableSpareThreshold.
+	// CriticalWarning is the raw warning bitmask, zero when healthy.
+	AvailableSpare          *int  `json:"available_spare,omitempty"`
+	AvailableSpareThreshold int   `json:"available_spare_threshold,omitempty"`
+	MediaErrors             int64 `json:"media_errors,omitempty"`
+	CriticalWarning         int   `json:"critical_warning,omitempty"`
+}
+
+// SmartOption configures a single smartctl invocation.
+type SmartOption func(*smartConfig)
+
+type smartConfig struct {
+	deviceType string
+}
+
+// DeviceType overrides the smartctl device type (-d) for a single call.
+// It takes precedence over any stored per-disk override.
+func DeviceType(typ string) SmartOption {
+	return func(c *smartConfig) { c.deviceType = typ }
+}
+
+// deviceTypeRegex matches smartctl device types such as "sat", "sat,12",
+// "usbjmicron,0" or "megaraid,3".
+var deviceTypeRegex = regexp.MustCompile(`^[a-z][a-z0-9_+-]*(,[a-z0-9_+-]+)*$`)
+
+// ValidateDeviceType checks that typ is a well-formed smartctl device type.
+func ValidateDeviceType(typ string) error {
+	if !deviceTypeRegex.MatchString(typ) {
+		return fmt.Errorf("invalid device type %q", typ)
+	}
+	return nil
+}
+
+// smartctlAttribute is one row of the smartctl ATA attribute table.
+type smartctlAttribute struct {
+	ID         int    `json:"id"`
+	Name       string `json:"name"`
+	Value      int    `json:"value"`
+	Worst      int    `json:"worst"`
+	Thresh     int    `json:"thresh"`
+	WhenFailed string `json:"when_failed"`
+	Raw        struct {
+		Value  int64  `json:"value"`
+		String string `json:"string"`
+	} `json:"raw"`
+}
+
+// attribute converts a smartctl row to an Attribute and interprets it.
+func (a smartctlAttribute) attribute() Attribute {
+	status := "OK"
+	if a.WhenFailed != "" && a.WhenFailed != "-" {
+		status = "FAILING"
+	}
+	attr := Attribute{
+		ID:     a.ID,
+		Name:   a.Name,
+		Value:  a.Value,
+		Worst:  a.Worst,
+		Thresh: a.Thresh,
+		Raw:    a.Raw.String,
+		Status: status,
+	}
+	interpretAttribute(&attr, a.Raw.Value)
+	return attr
 }
 
 // smartctlOutput represents the JSON output from smartctl.
@@ -75,18 +165,7 @@ type smartctlOutput struct {
 		Passed bool `json:"passed"`
 	} `json:"smart_status"`
 	AtaSmartAttributes struct {
-		Table []struct {
-			ID         int    `json:"id"`
-			Name       string `json:"name"`
-			Value      int    `json:"value"`
-			Worst      int    `json:"worst"`
-			Thresh     int    `json:"thresh"`
-			WhenFailed string `json:"when_failed"`
-			Raw        struct {
-				Value  int64  `json:"value"`
-				String string `json:"string"`
-			} `json:"raw"`
-		} `json:"table"`
+		Table []smartctlAttribute `json:"table"`
 	} `json:"ata_smart_attributes"`
 	Temperature struct {
 		Current int `json:"current"`
@@ -94,7 +173,8 @@ type smartctlOutput struct {
 	PowerOnTime struct {
 		Hours int64 `json:"hours"`
 	} `json:"power_on_time"`
-	PowerCycleCount     int64 `json:"power_cycle_count"`
+	PowerCycleCount     int64          `json:"power_cycle_count"`
+	NvmeHealth          *nvmeHealthLog `json:"nvme_smart_health_information_log"`
 	AtaSmartSelfTestLog struct {
 		Standard struct {
 			Table []struct {
@@ -115,13 +195,38 @@ type smartctlOutput struct {
 	} `json:"ata_smart_data"`
 }
 
+// nvmeHealthLog is the NVMe SMART / Health Information log page. Its
+// presence in smartctl output marks the disk as NVMe.
+type nvmeHealthLog struct {
+	CriticalWarning         int   `json:"critical_warning"`
+	Temperature             int   `json:"temperature"`
+	AvailableSpare          int   `json:"available_spare"`
+	AvailableSpareThreshold int   `json:"available_spare_threshold"`
+	PercentageUsed          int   `json:"percentage_used"`
+	DataUnitsWritten        int64 `json:"data_units_written"`
+	PowerCycles             int64 `json:"power_cycles"`
+	PowerOnHours            int64 `json:"power_on_hours"`
+	MediaErrors             int64 `json:"media_errors"`
+}
+
+// passed reports the overall health verdict. NVMe drives have no
+// attribute thresholds; any critical warning bit (spare below threshold,
+// temperature, degraded reliability, read-only media, failed volatile
+// memory backup) fails them.
+func (o *smartctlOutput) passed() bool {
+	if o.NvmeHealth != nil {
+		return o.NvmeHealth.CriticalWarning == 0
+	}
+	return o.SmartStatus.Passed
+}
+
 // Smart retrieves S.M.A.R.T. data for a disk.
-func (m *Manager) Smart(ctx context.Context, name string) (*Report, error) {
+func (m *Manager) Smart(ctx context.Context, name string, opts ...SmartOption) (*Report, error) {
 	if runtime.GOOS == "darwin" {
 		return mockReport(name), nil
 	}
 
-	out, err := m.runSmartctl(ctx, name)
+	out, err := m.runSmartctl(ctx, name, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -133,34 +238,22 @@ func (m *Manager) Smart(ctx context.Context, name string) (*Report, error) {
 
 	r := &Report{
 		Disk:      name,
-		Passed:    data.SmartStatus.Passed,
+		Passed:    data.passed(),
 		CheckedAt: time.Now(),
 	}
 	for _, a := range data.AtaSmartAttributes.Table {
-		status := "OK"
-		if a.WhenFailed != "" && a.WhenFailed != "-" {
-			status = "FAILING"
-		}
-		r.Attributes = append(r.Attributes, Attribute{
-			ID:     a.ID,
-			Name:   a.Name,
-			Value:  a.Value,
-			Worst:  a.Worst,
-			Thresh: a.Thresh,
-			Raw:    a.Raw.String,
-			Status: status,
-		})
+		r.Attributes = append(r.Attributes, a.attribute())
 	}
 	return r, nil
 }
 
 // SmartDetails retrieves comprehensive SMART data.
-func (m *Manager) SmartDetails(ctx context.Context, name string) (*DetailedReport, error) {
+func (m *Manager) SmartDetails(ctx context.Context, name string, opts ...SmartOption) (*DetailedReport, error) {
 	if runtime.GOOS == "darwin" {
 		return mockDetailedReport(name), nil
 	}
 
-	out, err := m.runSmartctl(ctx, name)
+	out, err := m.runSmartctl(ctx, name, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -169,10 +262,14 @@ func (m *Manager) SmartDetails(ctx context.Context, name string) (*DetailedRepor
 	if err := json.Unmarshal(out, &data); err != nil {
 		return nil, fmt.Errorf("parse smartctl: %w", err)
 	}
+	return detailedReport(name, &data), nil
+}
 
+// detailedReport builds a DetailedReport from parsed smartctl output.
+func detailedReport(name string, data *smartctlOutput) *DetailedReport {
 	r := &DetailedReport{
 		Disk:            name,
-		Passed:          data.SmartStatus.Passed,
+		Passed:          data.passed(),
 		CheckedAt:       time.Now(),
 		PowerOnHours:    data.PowerOnTime.Hours,
 		PowerCycleCount: data.PowerCycleCount,
@@ -180,19 +277,7 @@ func (m *Manager) SmartDetails(ctx context.Context, name string) (*DetailedRepor
 	}
 
 	for _, a := range data.AtaSmartAttributes.Table {
-		status := "OK"
-		if a.WhenFailed != "" && a.WhenFailed != "-" {
-			status = "FAILING"
-		}
-		r.Attributes = append(r.Attributes, Attribute{
-			ID:     a.ID,
-			Name:   a.Name,
-			Value:  a.Value,
-			Worst:  a.Worst,
-			Thresh: a.Thresh,
-			Raw:    a.Raw.String,
-			Status: status,
-		})
+		r.Attributes = append(r.Attributes, a.attribute())
 
 		switch a.ID {
 		case attrReallocatedSectors:
@@ -209,37 +294,75 @@ func (m *Manager) SmartDetails(ctx context.Context, name string) (*DetailedRepor
 			r.PendingSectors = a.Raw.Value
 		case attrUncorrectable:
 			r.UncorrectableErrors = a.Raw.Value
+		case attrWearLeveling, attrMediaWearout:
+			if r.WearLevelingCount == nil {
+				r.WearLevelingCount = &a.Value
+			}
+		case attrTotalLBAsWritten:
+			r.TotalLBAsWritten = a.Raw.Value
 		}
 	}
-	return r, nil
+
+	if nvme := data.NvmeHealth; nvme != nil {
+		r.PercentageUsed = &nvme.PercentageUsed
+		r.DataUnitsWritten = nvme.DataUnitsWritten
+		r.AvailableSpare = &nvme.AvailableSpare
+		r.AvailableSpareThreshold = nvme.AvailableSpareThreshold
+		r.MediaErrors = nvme.MediaErrors
+		r.CriticalWarning = nvme.CriticalWarning
+		// Older smartctl releases fill only the log page
+		if r.Temperature == 0 {
+			r.Temperature = nvme.Temperature
+		}
+		if r.PowerOnHours == 0 {
+			r.PowerOnHours = nvme.PowerOnHours
+		}
+		if r.PowerCycleCount == 0 {
+			r.PowerCycleCount = nvme.PowerCycles
+		}
+	}
+	r.WearWarning = r.WearExceeded()
+	return r
+}
+
+// WearExceeded reports whether the drive has used more than WearThreshold
+// percent of its rated endurance. Drives that report no wear never do.
+func (r *DetailedReport) WearExceeded() bool {
+	switch {
+	case r.PercentageUsed != nil:
+		return *r.PercentageUsed > WearThreshold
+	case r.WearLevelingCount != nil:
+		return 100-*r.WearLevelingCount > WearThreshold
+	}
+	return false
 }
 
 // SmartTest starts a S.M.A.R.T. self-test.
-func (m *Manager) SmartTest(ctx context.Context, name string, typ TestType) error {
+func (m *Manager) SmartTest(ctx context.Context, name string, typ TestType, opts ...SmartOption) error {
 	if runtime.GOOS == "darwin" {
 		return nil
 	}
 
-	_, err := m.exec.CombinedOutput(ctx, "smartctl", "-t", string(typ), "/dev/"+name)
+	devArgs, err := m.deviceTypeArgs(name, opts)
 	if err != nil {
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			// Only treat bits 0-2 as fatal
-			if exitErr.ExitCode()&smartExitFatalMask == 0 {
-				return nil
-			}
-		}
+		return err
+	}
+
+	args := append([]string{"-t", string(typ)}, devArgs...)
+	_, err = m.exec.CombinedOutput(ctx, "smartctl", append(args, "/dev/"+name)...)
+	if smartctlFatal(err) {
 		return fmt.Errorf("start smart test: %w", err)
 	}
 	return nil
 }
 
 // SmartTestStatus gets the current self-test status.
-func (m *Manager) SmartTestStatus(ctx context.Context, name string) (*TestStatus, error) {
+func (m *Manager) SmartTestStatus(ctx context.Context, name string, opts ...SmartOption) (*TestStatus, error) {
 	if runtime.GOOS == "darwin" {
 		return &TestStatus{LastResult: "Completed without error"}, nil
 	}
 
-	out, err := m.runSmartctl(ctx, name)
+	out, err := m.runSmartctl(ctx, name, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -279,21 +402,89 @@ const (
 	smartExitFatalMask = smartExitCmdLine | smartExitDevOpen | smartExitCmdFailed
 )
 
+// smartctlExitCode returns the exit status of a failed smartctl run, or -1
+// if it did not run to completion.
+func smartctlExitCode(err error) int {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return -1
+}
+
+// smartctlFatal reports whether a smartctl error means no data was read.
+// Only bits 0-2 are fatal (command/device errors); bits 3-7 indicate disk
+// health issues but the output is still valid.
+func smartctlFatal(err error) bool {
+	if err == nil {
+		return false
+	}
+	code := smartctlExitCode(err)
+	return code < 0 || code&smartExitFatalMask != 0
+}
+
+// deviceTypeArgs returns the "-d <type>" arguments for a disk, if any.
+// A per-call DeviceType option wins over the stored override.
+func (m *Manager) deviceTypeArgs(name string, opts []SmartOption) ([]string, error) {
+	var cfg smartConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	typ := cfg.deviceType
+	if typ == "" && m.deviceTypes != nil {
+		stored, err := m.deviceTypes.SmartDeviceType(name)
+		if err != nil {
+			logger.Debug("failed to load smartctl device type", "disk", name, "error", err)
+		}
+		typ = stored
+	}
+	if typ == "" {
+		return nil, nil
+	}
+
+	if err := ValidateDeviceType(typ); err != nil {
+		return nil, err
+	}
+	return []string{"-d", typ}, nil
+}
+
+// smartctlTimeout bounds a smartctl read shared by concurrent callers,
+// which runs independently of any one caller's context.
+const smartctlTimeout = time.Minute
+
 // runSmartctl executes smartctl and handles exit codes using bitmask.
-func (m *Manager) runSmartctl(ctx context.Context, name string) ([]byte, error) {
-	out, err := m.exec.CombinedOutput(ctx, "smartctl", "-a", "-j", "/dev/"+name)
+func (m *Manager) runSmartctl(ctx context.Context, name string, opts []SmartOption) ([]byte, error) {
+	devArgs, err := m.deviceTypeArgs(name, opts)
 	if err != nil {
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			code := exitErr.ExitCode()
-			// Only treat bits 0-2 as fatal (command/device errors)
-			// Bits 3-7 indicate disk health issues but data is still valid
-			if code&smartExitFatalMask == 0 {
-				return out, nil
-			}
+		return nil, err
+	}
+
+	args := append([]string{"-a", "-j"}, devArgs...)
+	args = append(args, "/dev/"+name)
+
+	// Concurrent reads of the same disk share one smartctl invocation. It
+	// must not be cut short when the caller that started it goes away, so
+	// it runs on its own timeout while each caller waits on its own ctx.
+	key := strings.Join(args, " ")
+	ch := m.smartFlight.DoChan(key, func() (any, error) {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), smartctlTimeout)
+		defer cancel()
+		out, err := m.exec.CombinedOutput(ctx, "smartctl", args...)
+		if smartctlFatal(err) {
+			return nil, fmt.Errorf("smartctl: %w", err)
+		}
+		return out, nil
+	})
+	select {
+	case res := <-ch:
+		if res.Err != nil {
+			return nil, res.Err
 		}
-		return nil, fmt.Errorf("smartctl: %w", err)
+		return res.Val.([]byte), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
 	}
-	return out, nil
 }
 
 // parseTemperature extracts temperature from SMART raw string.
@@ -0,0 +1,67 @@
+package disk
+
+import "fmt"
+
+// thresholdMargin is how close a normalized value may come to its
+// failure threshold before the attribute is flagged critical.
+const thresholdMargin = 10
+
+// attributeMeanings describes common ATA attributes in plain language.
+var attributeMeanings = map[int]string{
+	1:   "Rate of read errors; the raw value is vendor-specific and often large",
+	3:   "Time taken for the platters to spin up",
+	4:   "Number of spindle start/stop cycles",
+	5:   "Sectors remapped to spare area after failing",
+	7:   "Rate of seek errors; the raw value is vendor-specific",
+	9:   "Hours the disk has been powered on",
+	10:  "Spin-up retries; non-zero suggests motor or power problems",
+	12:  "Number of power on/off cycles",
+	177: "SSD wear leveling count",
+	187: "Errors that error correction could not recover",
+	188: "Commands aborted because the drive timed out",
+	190: "Airflow temperature",
+	194: "Drive temperature",
+	196: "Sector remapping operations performed",
+	197: "Sectors waiting to be remapped after read errors",
+	198: "Sectors found unreadable during offline scans",
+	199: "Interface CRC errors; usually a bad cable rather than the disk",
+	231: "SSD remaining life",
+	233: "SSD media wearout indicator",
+	241: "Total logical blocks written",
+}
+
+// criticalCountAttributes are counters where any non-zero raw value means
+// the disk is losing data.
+var criticalCountAttributes = map[int]bool{
+	attrReallocatedSectors: true,
+	187:                    true,
+	attrPendingSectors:     true,
+	attrUncorrectable:      true,
+}
+
+// interpretAttribute sets Critical and Interpretation from the attribute
+// ID, its raw counter and how close its normalized value is to the
+// failure threshold.
+func interpretAttribute(a *Attribute, raw int64) {
+	meaning := attributeMeanings[a.ID]
+
+	var problem string
+	switch {
+	case a.Status == "FAILING":
+		problem = "below the manufacturer's failure threshold"
+	case a.Thresh > 0 && a.Value <= a.Thresh+thresholdMargin:
+		problem = fmt.Sprintf("value %d is approaching the failure threshold %d", a.Value, a.Thresh)
+	case criticalCountAttributes[a.ID] && raw > 0:
+		problem = fmt.Sprintf("%d so far", raw)
+	default:
+		a.Interpretation = meaning
+		return
+	}
+
+	a.Critical = true
+	if meaning == "" {
+		a.Interpretation = problem
+		return
+	}
+	a.Interpretation = meaning + ": " + problem
+}
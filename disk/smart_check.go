@@ -0,0 +1,89 @@
+package disk
+
+import (
+	"context"
+	"encoding/json"
+	"runtime"
+)
+
+// SmartCheck reports whether smartctl can read a disk.
+type SmartCheck struct {
+	Readable      bool   `json:"readable"`
+	DeviceType    string `json:"device_type,omitempty"` // -d override in use, else the type smartctl detected
+	NeedsOverride bool   `json:"needs_override"`        // a -d device type is likely required
+	Message       string `json:"message"`
+}
+
+// smartctlInfo is the part of "smartctl -i -j" output used by CheckSmart.
+type smartctlInfo struct {
+	Smartctl struct {
+		Messages []struct {
+			String   string `json:"string"`
+			Severity string `json:"severity"`
+		} `json:"messages"`
+	} `json:"smartctl"`
+	Device struct {
+		Type string `json:"type"`
+	} `json:"device"`
+	SmartSupport *struct {
+		Available bool `json:"available"`
+		Enabled   bool `json:"enabled"`
+	} `json:"smart_support"`
+}
+
+// CheckSmart runs a minimal "smartctl -i" against a disk to confirm SMART
+// monitoring can work, which USB bridges often prevent without a device
+// type override. Failing to read the disk is reported in the result, not
+// as an error.
+func (m *Manager) CheckSmart(ctx context.Context, name string, opts ...SmartOption) (*SmartCheck, error) {
+	if runtime.GOOS == "darwin" {
+		return &SmartCheck{Readable: true, Message: "SMART is readable"}, nil
+	}
+
+	devArgs, err := m.deviceTypeArgs(name, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	args := append([]string{"-i", "-j"}, devArgs...)
+	out, runErr := m.exec.CombinedOutput(ctx, "smartctl", append(args, "/dev/"+name)...)
+
+	var info smartctlInfo
+	_ = json.Unmarshal(out, &info) // output is empty or partial on some failures
+
+	check := &SmartCheck{DeviceType: info.Device.Type}
+	if len(devArgs) > 0 {
+		check.DeviceType = devArgs[1]
+	}
+
+	if smartctlFatal(runErr) {
+		code := smartctlExitCode(runErr)
+		check.Message = "smartctl cannot read the disk"
+		if code < 0 {
+			check.Message = runErr.Error() // smartctl did not run
+		}
+		for _, msg := range info.Smartctl.Messages {
+			if msg.Severity == "error" {
+				check.Message = msg.String
+				break
+			}
+		}
+		// Opening or identifying the device is what bridges break; with no
+		// override in use, one is the usual fix
+		check.NeedsOverride = len(devArgs) == 0 && code > 0 && code&(smartExitCmdLine|smartExitDevOpen) != 0
+		return check, nil
+	}
+
+	if info.SmartSupport != nil && !info.SmartSupport.Available {
+		check.Message = "device does not support SMART"
+		return check, nil
+	}
+	if info.SmartSupport != nil && !info.SmartSupport.Enabled {
+		check.Message = "SMART is supported but disabled"
+		return check, nil
+	}
+
+	check.Readable = true
+	check.Message = "SMART is readable"
+	return check, nil
+}
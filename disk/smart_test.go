@@ -0,0 +1,465 @@
+package disk
+
+import (
+	"context"
+	"errors"
+	"os"
+	osexec "os/exec"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.aimuz.me/mynt/sysexec"
+)
+
+// staticDeviceTypes is a DeviceTypeSource backed by a map.
+type staticDeviceTypes map[string]string
+
+func (s staticDeviceTypes) SmartDeviceType(name string) (string, error) {
+	return s[name], nil
+}
+
+func TestRunSmartctl_DeviceType(t *testing.T) {
+	tests := []struct {
+		name     string
+		stored   map[string]string
+		opts     []SmartOption
+		wantArgs []string
+	}{
+		{
+			name:     "default",
+			wantArgs: []string{"-a", "-j", "/dev/sda"},
+		},
+		{
+			name:     "stored_override",
+			stored:   map[string]string{"sda": "sat"},
+			wantArgs: []string{"-a", "-j", "-d", "sat", "/dev/sda"},
+		},
+		{
+			name:     "per_call_override_wins",
+			stored:   map[string]string{"sda": "sat"},
+			opts:     []SmartOption{DeviceType("usbjmicron,0")},
+			wantArgs: []string{"-a", "-j", "-d", "usbjmicron,0", "/dev/sda"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exec := sysexec.NewMock()
+			exec.SetOutput("smartctl", []byte(`{"smart_status":{"passed":true}}`))
+			m := &Manager{exec: exec, deviceTypes: staticDeviceTypes(tt.stored)}
+
+			if _, err := m.runSmartctl(context.Background(), "sda", tt.opts); err != nil {
+				t.Fatalf("runSmartctl: %v", err)
+			}
+
+			cmds := exec.Commands()
+			if len(cmds) != 1 {
+				t.Fatalf("got %d commands, want 1", len(cmds))
+			}
+			if !slices.Equal(cmds[0].Args, tt.wantArgs) {
+				t.Errorf("args = %v, want %v", cmds[0].Args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestSmartTest_DeviceType(t *testing.T) {
+	exec := sysexec.NewMock()
+	m := &Manager{exec: exec, deviceTypes: staticDeviceTypes{"sdb": "sat"}}
+
+	if err := m.SmartTest(context.Background(), "sdb", TestShort); err != nil {
+		t.Fatalf("SmartTest: %v", err)
+	}
+
+	want := []string{"-t", "short", "-d", "sat", "/dev/sdb"}
+	if got := exec.Commands()[0].Args; !slices.Equal(got, want) {
+		t.Errorf("args = %v, want %v", got, want)
+	}
+}
+
+func TestValidateDeviceType(t *testing.T) {
+	tests := []struct {
+		input   string
+		wantErr bool
+	}{
+		{"sat", false},
+		{"sat,12", false},
+		{"usbjmicron,0", false},
+		{"megaraid,3", false},
+		{"", true},
+		{"-d", true},
+		{"sat;rm", true},
+		{"SAT", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			err := ValidateDeviceType(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateDeviceType(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// blockingExecutor counts smartctl calls and holds each one until release
+// is closed or its context is done.
+type blockingExecutor struct {
+	sysexec.Executor
+	calls   atomic.Int32
+	started chan struct{}
+	release chan struct{}
+}
+
+func (e *blockingExecutor) CombinedOutput(ctx context.Context, name string, args ...string) ([]byte, error) {
+	if e.calls.Add(1) == 1 {
+		close(e.started)
+	}
+	select {
+	case <-e.release:
+		return []byte(`{"smart_status":{"passed":true}}`), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func TestRunSmartctl_Singleflight(t *testing.T) {
+	const n = 10
+	exec := &blockingExecutor{
+		Executor: sysexec.NewMock(),
+		started:  make(chan struct{}),
+		release:  make(chan struct{}),
+	}
+	m := &Manager{exec: exec}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, n)
+	for range n {
+		wg.Go(func() {
+			_, err := m.runSmartctl(context.Background(), "sda", nil)
+			errs <- err
+		})
+	}
+
+	// Give the other callers time to join the in-flight read
+	<-exec.started
+	time.Sleep(50 * time.Millisecond)
+	close(exec.release)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("runSmartctl() error = %v", err)
+		}
+	}
+	if got := exec.calls.Load(); got != 1 {
+		t.Errorf("smartctl calls = %d, want 1", got)
+	}
+}
+
+func TestRunSmartctl_FirstCallerCancelled(t *testing.T) {
+	exec := &blockingExecutor{
+		Executor: sysexec.NewMock(),
+		started:  make(chan struct{}),
+		release:  make(chan struct{}),
+	}
+	m := &Manager{exec: exec}
+
+	// The caller that starts the read gives up while another waits on it
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error, 1)
+	go func() {
+		_, err := m.runSmartctl(ctx, "sda", nil)
+		first <- err
+	}()
+	<-exec.started
+	second := make(chan error, 1)
+	go func() {
+		_, err := m.runSmartctl(context.Background(), "sda", nil)
+		second <- err
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	cancel()
+	if err := <-first; !errors.Is(err, context.Canceled) {
+		t.Errorf("cancelled caller error = %v, want context.Canceled", err)
+	}
+	close(exec.release)
+	if err := <-second; err != nil {
+		t.Errorf("waiting caller error = %v, want nil", err)
+	}
+	if got := exec.calls.Load(); got != 1 {
+		t.Errorf("smartctl calls = %d, want 1", got)
+	}
+}
+
+func TestInterpretAttribute(t *testing.T) {
+	tests := []struct {
+		name         string
+		attr         Attribute
+		raw          int64
+		wantCritical bool
+	}{
+		{"reallocated_nonzero", Attribute{ID: 5, Value: 100, Thresh: 10, Status: "OK"}, 8, true},
+		{"reallocated_zero", Attribute{ID: 5, Value: 100, Thresh: 10, Status: "OK"}, 0, false},
+		{"pending_nonzero", Attribute{ID: 197, Value: 100, Status: "OK"}, 1, true},
+		{"uncorrectable_nonzero", Attribute{ID: 198, Value: 100, Status: "OK"}, 2, true},
+		{"power_on_hours", Attribute{ID: 9, Value: 90, Status: "OK"}, 20000, false},
+		{"temperature", Attribute{ID: 194, Value: 64, Status: "OK"}, 36, false},
+		{"crc_errors", Attribute{ID: 199, Value: 200, Status: "OK"}, 12, false},
+		{"approaching_threshold", Attribute{ID: 1, Value: 55, Thresh: 51, Status: "OK"}, 0, true},
+		{"failing", Attribute{ID: 3, Value: 20, Thresh: 21, Status: "FAILING"}, 0, true},
+		{"unknown_benign", Attribute{ID: 240, Value: 100, Status: "OK"}, 5, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := tt.attr
+			interpretAttribute(&a, tt.raw)
+			if a.Critical != tt.wantCritical {
+				t.Errorf("Critical = %v, want %v (%q)", a.Critical, tt.wantCritical, a.Interpretation)
+			}
+			if _, known := attributeMeanings[a.ID]; (known || a.Critical) && a.Interpretation == "" {
+				t.Error("Interpretation is empty")
+			}
+		})
+	}
+}
+
+func TestSmartDetails_Interpretation(t *testing.T) {
+	exec := sysexec.NewMock()
+	exec.SetOutput("smartctl", []byte(`{"smart_status":{"passed":true},"ata_smart_attributes":{"table":[
+		{"id":5,"name":"Reallocated_Sector_Ct","value":100,"worst":100,"thresh":10,"when_failed":"","raw":{"value":16,"string":"16"}},
+		{"id":9,"name":"Power_On_Hours","value":90,"worst":90,"thresh":0,"when_failed":"","raw":{"value":20000,"string":"20000"}}]}}`))
+	m := &Manager{exec: exec}
+
+	r, err := m.SmartDetails(context.Background(), "sda")
+	if err != nil {
+		t.Fatalf("SmartDetails: %v", err)
+	}
+	if len(r.Attributes) != 2 {
+		t.Fatalf("got %d attributes, want 2", len(r.Attributes))
+	}
+	if !r.Attributes[0].Critical {
+		t.Errorf("attribute 5 with raw 16 not critical: %+v", r.Attributes[0])
+	}
+	if r.Attributes[1].Critical || r.Attributes[1].Interpretation == "" {
+		t.Errorf("attribute 9 = %+v, want benign with interpretation", r.Attributes[1])
+	}
+}
+
+func TestCheckSmart(t *testing.T) {
+	// A real *exec.ExitError carrying smartctl's "device open failed" bit
+	openFailed := osexec.Command("sh", "-c", "exit 2").Run()
+
+	tests := []struct {
+		name     string
+		output   string
+		err      error
+		opts     []SmartOption
+		readable bool
+		devType  string
+		override bool
+		message  string
+	}{
+		{
+			name:     "readable",
+			output:   `{"device":{"type":"sat","protocol":"ATA"},"smart_support":{"available":true,"enabled":true}}`,
+			readable: true,
+			devType:  "sat",
+			message:  "SMART is readable",
+		},
+		{
+			name:     "device_open_failed",
+			err:      openFailed,
+			override: true,
+			message:  "smartctl cannot read the disk",
+		},
+		{
+			name:    "device_open_failed_with_override",
+			err:     openFailed,
+			opts:    []SmartOption{DeviceType("sat")},
+			devType: "sat",
+			message: "smartctl cannot read the disk",
+		},
+		{
+			name:    "unsupported",
+			output:  `{"device":{"type":"scsi"},"smart_support":{"available":false,"enabled":false}}`,
+			devType: "scsi",
+			message: "device does not support SMART",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exec := sysexec.NewMock()
+			exec.SetOutput("smartctl", []byte(tt.output))
+			if tt.err != nil {
+				exec.SetError("smartctl", tt.err)
+			}
+			m := &Manager{exec: exec}
+
+			check, err := m.CheckSmart(context.Background(), "sdb", tt.opts...)
+			if err != nil {
+				t.Fatalf("CheckSmart: %v", err)
+			}
+			if check.Readable != tt.readable || check.DeviceType != tt.devType ||
+				check.NeedsOverride != tt.override || check.Message != tt.message {
+				t.Errorf("check = %+v", check)
+			}
+			if args := exec.Commands()[0].Args; args[0] != "-i" {
+				t.Errorf("args = %v, want an -i identify call", args)
+			}
+		})
+	}
+}
+
+func TestSmartDetails_Wear(t *testing.T) {
+	tests := []struct {
+		name        string
+		output      string
+		wantUsed    int // -1 when PercentageUsed is unset
+		wantLevel   int // -1 when WearLevelingCount is unset
+		wantWritten int64
+		wantWarning bool
+	}{
+		{
+			name: "sata_ssd",
+			output: `{"smart_status":{"passed":true},"ata_smart_attributes":{"table":[
+				{"id":177,"name":"Wear_Leveling_Count","value":15,"worst":15,"thresh":0,"when_failed":"","raw":{"value":2950,"string":"2950"}},
+				{"id":241,"name":"Total_LBAs_Written","value":99,"worst":99,"thresh":0,"when_failed":"","raw":{"value":123456789,"string":"123456789"}}]}}`,
+			wantUsed:    -1,
+			wantLevel:   15,
+			wantWritten: 123456789,
+			wantWarning: true,
+		},
+		{
+			name: "nvme",
+			output: `{"smart_status":{"passed":true},"nvme_smart_health_information_log":{
+				"percentage_used":7,"data_units_written":4200000}}`,
+			wantUsed:    7,
+			wantLevel:   -1,
+			wantWritten: 4200000,
+		},
+		{
+			name:      "hdd",
+			output:    `{"smart_status":{"passed":true},"ata_smart_attributes":{"table":[]}}`,
+			wantUsed:  -1,
+			wantLevel: -1,
+		},
+	}
+
+	deref := func(p *int) int {
+		if p == nil {
+			return -1
+		}
+		return *p
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exec := sysexec.NewMock()
+			exec.SetOutput("smartctl", []byte(tt.output))
+			m := &Manager{exec: exec}
+
+			r, err := m.SmartDetails(context.Background(), "sda")
+			if err != nil {
+				t.Fatalf("SmartDetails: %v", err)
+			}
+			if got := deref(r.PercentageUsed); got != tt.wantUsed {
+				t.Errorf("PercentageUsed = %d, want %d", got, tt.wantUsed)
+			}
+			if got := deref(r.WearLevelingCount); got != tt.wantLevel {
+				t.Errorf("WearLevelingCount = %d, want %d", got, tt.wantLevel)
+			}
+			if got := r.TotalLBAsWritten + r.DataUnitsWritten; got != tt.wantWritten {
+				t.Errorf("written = %d, want %d", got, tt.wantWritten)
+			}
+			if r.WearWarning != tt.wantWarning {
+				t.Errorf("WearWarning = %v, want %v", r.WearWarning, tt.wantWarning)
+			}
+		})
+	}
+}
+
+func TestSmartDetails_Fixtures(t *testing.T) {
+	tests := []struct {
+		file         string
+		passed       bool
+		temperature  int
+		powerOnHours int64
+		powerCycles  int64
+		attributes   int
+		spare        int // -1 when AvailableSpare is unset
+		mediaErrors  int64
+		pending      int64
+	}{
+		{file: "smartctl_ata.json", passed: true, temperature: 35, powerOnHours: 29511, powerCycles: 57, attributes: 8, spare: -1, pending: 2},
+		{file: "smartctl_nvme.json", passed: true, temperature: 41, powerOnHours: 5121, powerCycles: 214, spare: 100},
+		{file: "smartctl_nvme_failing.json", passed: false, temperature: 38, powerOnHours: 31877, powerCycles: 1022, spare: 100, mediaErrors: 12},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			out, err := os.ReadFile("testdata/" + tt.file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			exec := sysexec.NewMock()
+			exec.SetOutput("smartctl", out)
+			m := &Manager{exec: exec}
+
+			r, err := m.SmartDetails(context.Background(), "sda")
+			if err != nil {
+				t.Fatalf("SmartDetails: %v", err)
+			}
+			if r.Passed != tt.passed {
+				t.Errorf("Passed = %v, want %v", r.Passed, tt.passed)
+			}
+			if r.Temperature != tt.temperature || r.PowerOnHours != tt.powerOnHours || r.PowerCycleCount != tt.powerCycles {
+				t.Errorf("temperature, power on hours, cycles = %d, %d, %d, want %d, %d, %d",
+					r.Temperature, r.PowerOnHours, r.PowerCycleCount, tt.temperature, tt.powerOnHours, tt.powerCycles)
+			}
+			if len(r.Attributes) != tt.attributes {
+				t.Errorf("got %d attributes, want %d", len(r.Attributes), tt.attributes)
+			}
+			spare := -1
+			if r.AvailableSpare != nil {
+				spare = *r.AvailableSpare
+			}
+			if spare != tt.spare || r.MediaErrors != tt.mediaErrors || r.PendingSectors != tt.pending {
+				t.Errorf("spare, media errors, pending = %d, %d, %d, want %d, %d, %d",
+					spare, r.MediaErrors, r.PendingSectors, tt.spare, tt.mediaErrors, tt.pending)
+			}
+
+			report, err := m.Smart(context.Background(), "sda")
+			if err != nil {
+				t.Fatalf("Smart: %v", err)
+			}
+			if report.Passed != tt.passed {
+				t.Errorf("Smart().Passed = %v, want %v", report.Passed, tt.passed)
+			}
+		})
+	}
+}
+
+func TestSmartDetails_NVMeLogOnly(t *testing.T) {
+	// smartctl before 7.1 prints neither smart_status nor the top-level
+	// temperature and power-on time for NVMe drives
+	exec := sysexec.NewMock()
+	exec.SetOutput("smartctl", []byte(`{"nvme_smart_health_information_log":{
+		"critical_warning":0,"temperature":44,"available_spare":98,"available_spare_threshold":10,
+		"percentage_used":9,"power_cycles":31,"power_on_hours":812,"media_errors":0}}`))
+	m := &Manager{exec: exec}
+
+	r, err := m.SmartDetails(context.Background(), "nvme0")
+	if err != nil {
+		t.Fatalf("SmartDetails: %v", err)
+	}
+	if !r.Passed || r.Temperature != 44 || r.PowerOnHours != 812 || r.PowerCycleCount != 31 {
+		t.Errorf("report = %+v", r)
+	}
+}
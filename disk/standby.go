@@ -0,0 +1,51 @@
+package disk
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"strconv"
+	"time"
+)
+
+// MaxStandbyTimeout is the longest spin-down timeout hdparm can encode.
+const MaxStandbyTimeout = 330 * time.Minute
+
+// SetStandby sets the spin-down timeout of a disk. A zero timeout disables
+// standby. The setting does not survive a power cycle.
+func (m *Manager) SetStandby(ctx context.Context, name string, timeout time.Duration) error {
+	v, err := standbyValue(timeout)
+	if err != nil {
+		return err
+	}
+	if runtime.GOOS == "darwin" {
+		return nil
+	}
+
+	if _, err := m.exec.CombinedOutput(ctx, "hdparm", "-S", strconv.Itoa(v), "/dev/"+name); err != nil {
+		return fmt.Errorf("hdparm: %w", err)
+	}
+	return nil
+}
+
+// standbyValue encodes timeout for hdparm -S, rounding up to the next
+// representable step: 1-240 are multiples of 5 seconds, 241-251 are
+// multiples of 30 minutes.
+func standbyValue(timeout time.Duration) (int, error) {
+	const (
+		short = 5 * time.Second
+		long  = 30 * time.Minute
+	)
+	switch {
+	case timeout < 0:
+		return 0, fmt.Errorf("invalid standby timeout: %s", timeout)
+	case timeout == 0:
+		return 0, nil
+	case timeout <= 240*short:
+		return int((timeout + short - 1) / short), nil
+	case timeout <= MaxStandbyTimeout:
+		return 240 + int((timeout+long-1)/long), nil
+	default:
+		return 0, fmt.Errorf("standby timeout exceeds %s", MaxStandbyTimeout)
+	}
+}
@@ -0,0 +1,36 @@
+package disk
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStandbyValue(t *testing.T) {
+	tests := []struct {
+		timeout time.Duration
+		want    int
+		wantErr bool
+	}{
+		{0, 0, false},
+		{5 * time.Second, 1, false},
+		{7 * time.Second, 2, false},
+		{10 * time.Minute, 120, false},
+		{20 * time.Minute, 240, false},
+		{21 * time.Minute, 241, false},
+		{time.Hour, 242, false},
+		{MaxStandbyTimeout, 251, false},
+		{MaxStandbyTimeout + time.Minute, 0, true},
+		{-time.Second, 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := standbyValue(tt.timeout)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("standbyValue(%s) error = %v, wantErr %v", tt.timeout, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("standbyValue(%s) = %d, want %d", tt.timeout, got, tt.want)
+		}
+	}
+}
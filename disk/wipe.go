@@ -0,0 +1,174 @@
+package disk
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// WipeMode selects how a disk is cleared before reuse.
+type WipeMode string
+
+const (
+	// WipeLabel clears ZFS labels and filesystem and partition table
+	// signatures, leaving the rest of the disk untouched.
+	WipeLabel WipeMode = "label"
+	// WipeQuick zeroes the start and the end of the disk, where partition
+	// tables and ZFS labels live.
+	WipeQuick WipeMode = "quick"
+)
+
+// ErrInvalidWipeMode is returned for an unknown WipeMode.
+var ErrInvalidWipeMode = errors.New("invalid wipe mode")
+
+// quickWipeMiB is how much WipeQuick zeroes at each end of the disk. ZFS
+// keeps two labels at the end of its data partition, which is followed by
+// an 8 MiB reserved partition on disks ZFS partitioned itself.
+const quickWipeMiB = 16
+
+// WipeOption configures Wipe.
+type WipeOption func(*wipeOptions)
+
+type wipeOptions struct {
+	progress func(done, total int)
+}
+
+// WipeProgress sets a callback called after each step of the wipe.
+func WipeProgress(fn func(done, total int)) WipeOption {
+	return func(o *wipeOptions) { o.progress = fn }
+}
+
+// Wipe clears a disk so it can be reused, destroying what is on it. It
+// does not check whether the disk is in use; callers must.
+func (m *Manager) Wipe(ctx context.Context, name string, mode WipeMode, opts ...WipeOption) error {
+	if err := validateDiskName(name); err != nil {
+		return err
+	}
+	var o wipeOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.progress == nil {
+		o.progress = func(int, int) {}
+	}
+	if mode != WipeLabel && mode != WipeQuick {
+		return fmt.Errorf("%w: %q", ErrInvalidWipeMode, mode)
+	}
+	if runtime.GOOS == "darwin" {
+		return nil
+	}
+
+	dev := "/dev/" + name
+	if mode == WipeQuick {
+		return m.wipeQuick(ctx, dev, o.progress)
+	}
+	return m.wipeLabel(ctx, dev, o.progress)
+}
+
+// wipeLabel clears the ZFS labels of the disk and its partitions, then the
+// remaining signatures, partitions first so wipefs can still find them.
+func (m *Manager) wipeLabel(ctx context.Context, dev string, progress func(done, total int)) error {
+	devices, err := m.blockDevices(ctx, dev)
+	if err != nil {
+		return err
+	}
+
+	total := 2 * len(devices)
+	done := 0
+	for _, d := range devices {
+		if d.fstype == "zfs_member" {
+			if out, err := m.exec.CombinedOutput(ctx, "zpool", "labelclear", "-f", d.path); err != nil {
+				return fmt.Errorf("zpool labelclear %s: %s: %w", d.path, bytes.TrimSpace(out), err)
+			}
+		}
+		done++
+		progress(done, total)
+	}
+	for i := len(devices) - 1; i >= 0; i-- {
+		if out, err := m.exec.CombinedOutput(ctx, "wipefs", "-a", devices[i].path); err != nil {
+			return fmt.Errorf("wipefs %s: %s: %w", devices[i].path, bytes.TrimSpace(out), err)
+		}
+		done++
+		progress(done, total)
+	}
+	return nil
+}
+
+// wipeQuick zeroes the first and last quickWipeMiB of the disk.
+func (m *Manager) wipeQuick(ctx context.Context, dev string, progress func(done, total int)) error {
+	out, err := m.exec.Output(ctx, "blockdev", "--getsize64", dev)
+	if err != nil {
+		return fmt.Errorf("blockdev: %w", err)
+	}
+	size, err := strconv.ParseInt(strings.TrimSpace(string(out)), 10, 64)
+	if err != nil {
+		return fmt.Errorf("parse disk size: %w", err)
+	}
+
+	const chunk = quickWipeMiB << 20
+	offsets := []int64{0}
+	if size > chunk {
+		offsets = append(offsets, size-chunk)
+	}
+	for i, off := range offsets {
+		args := []string{"if=/dev/zero", "of=" + dev, "bs=1M",
+			"count=" + strconv.Itoa(quickWipeMiB), "oflag=seek_bytes,direct",
+			"seek=" + strconv.FormatInt(off, 10), "conv=fsync"}
+		if out, err := m.exec.CombinedOutput(ctx, "dd", args...); err != nil {
+			// dd fails with "No space left" when a short disk ends mid-block,
+			// after writing everything up to the end
+			if !bytes.Contains(out, []byte("No space left")) {
+				return fmt.Errorf("dd: %s: %w", bytes.TrimSpace(out), err)
+			}
+		}
+		progress(i+1, len(offsets))
+	}
+	return nil
+}
+
+// blockDevice is a disk or one of its partitions.
+type blockDevice struct {
+	path   string
+	fstype string
+}
+
+// blockDevices returns dev followed by its partitions.
+func (m *Manager) blockDevices(ctx context.Context, dev string) ([]blockDevice, error) {
+	out, err := m.exec.Output(ctx, "lsblk", "-ln", "-o", "PATH,FSTYPE", dev)
+	if err != nil {
+		return nil, fmt.Errorf("lsblk: %w", err)
+	}
+	return parseBlockDevices(out), nil
+}
+
+// parseBlockDevices parses `lsblk -ln -o PATH,FSTYPE` output.
+func parseBlockDevices(out []byte) []blockDevice {
+	var devices []blockDevice
+	sc := bufio.NewScanner(bytes.NewReader(out))
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		d := blockDevice{path: fields[0]}
+		if len(fields) > 1 {
+			d.fstype = fields[1]
+		}
+		devices = append(devices, d)
+	}
+	return devices
+}
+
+// validateDiskName rejects names that are not a plain device name under
+// /dev, so they cannot reach another path or be read as a flag.
+func validateDiskName(name string) error {
+	if name == "" || strings.ContainsAny(name, "/\x00") || strings.HasPrefix(name, "-") || strings.HasPrefix(name, ".") {
+		return fmt.Errorf("invalid disk name: %q", name)
+	}
+	return nil
+}
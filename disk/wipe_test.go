@@ -0,0 +1,81 @@
+//go:build linux
+
+package disk
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"go.aimuz.me/mynt/sysexec"
+)
+
+func commandLines(exec *sysexec.MockExecutor) []string {
+	var lines []string
+	for _, c := range exec.Commands() {
+		lines = append(lines, c.Name+" "+strings.Join(c.Args, " "))
+	}
+	return lines
+}
+
+func TestWipe_Label(t *testing.T) {
+	exec := sysexec.NewMock()
+	exec.SetOutput("lsblk", []byte("/dev/sdb \n/dev/sdb1 zfs_member\n/dev/sdb9 \n"))
+	m := &Manager{exec: exec}
+
+	var steps []int
+	err := m.Wipe(context.Background(), "sdb", WipeLabel, WipeProgress(func(done, total int) {
+		steps = append(steps, done*100/total)
+	}))
+	if err != nil {
+		t.Fatalf("Wipe() error = %v", err)
+	}
+
+	want := []string{
+		"lsblk -ln -o PATH,FSTYPE /dev/sdb",
+		"zpool labelclear -f /dev/sdb1",
+		"wipefs -a /dev/sdb9",
+		"wipefs -a /dev/sdb1",
+		"wipefs -a /dev/sdb",
+	}
+	if got := commandLines(exec); !slices.Equal(got, want) {
+		t.Errorf("commands = %q, want %q", got, want)
+	}
+	if len(steps) != 6 || steps[len(steps)-1] != 100 {
+		t.Errorf("progress = %v, want 6 steps ending at 100", steps)
+	}
+}
+
+func TestWipe_Quick(t *testing.T) {
+	exec := sysexec.NewMock()
+	exec.SetOutput("blockdev", []byte("1000204886016\n"))
+	m := &Manager{exec: exec}
+
+	if err := m.Wipe(context.Background(), "sdc", WipeQuick); err != nil {
+		t.Fatalf("Wipe() error = %v", err)
+	}
+
+	want := []string{
+		"blockdev --getsize64 /dev/sdc",
+		"dd if=/dev/zero of=/dev/sdc bs=1M count=16 oflag=seek_bytes,direct seek=0 conv=fsync",
+		"dd if=/dev/zero of=/dev/sdc bs=1M count=16 oflag=seek_bytes,direct seek=1000188108800 conv=fsync",
+	}
+	if got := commandLines(exec); !slices.Equal(got, want) {
+		t.Errorf("commands = %q, want %q", got, want)
+	}
+}
+
+func TestWipe_Invalid(t *testing.T) {
+	m := &Manager{exec: sysexec.NewMock()}
+
+	if err := m.Wipe(context.Background(), "sdb", "secure"); !errors.Is(err, ErrInvalidWipeMode) {
+		t.Errorf("Wipe(mode=secure) error = %v, want ErrInvalidWipeMode", err)
+	}
+	for _, name := range []string{"", "../sda", "-rf", "sda/1"} {
+		if err := m.Wipe(context.Background(), name, WipeLabel); err == nil {
+			t.Errorf("Wipe(%q) succeeded, want error", name)
+		}
+	}
+}
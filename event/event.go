@@ -5,6 +5,7 @@ package event
 import (
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -17,13 +18,26 @@ type Event struct {
 
 // Event type constants
 const (
-	DiskAdded        = "disk.added"
-	DiskRemoved      = "disk.removed"
-	SmartFailed      = "smart.failed"
-	PoolDegraded     = "pool.degraded"
-	PoolOnline       = "pool.online"
-	DatasetCreated   = "dataset.created"
-	DatasetDestroyed = "dataset.destroyed"
+	DiskAdded            = "disk.added"
+	DiskRemoved          = "disk.removed"
+	DiskReadOnly         = "disk.readonly"
+	DiskPredictedFailure = "disk.predicted_failure"
+	SmartFailed          = "smart.failed"
+	PoolDegraded         = "pool.degraded"
+	PoolOnline           = "pool.online"
+	PoolDiskErrors       = "pool.disk.errors"
+	PoolScanStarted      = "pool.scan.started"
+	PoolScanFinished     = "pool.scan.finished"
+	NetworkErrors        = "network.errors"
+	DatasetCreated       = "dataset.created"
+	DatasetDestroyed     = "dataset.destroyed"
+	TaskStarted          = "task.started"
+	TaskProgress         = "task.progress"
+	TaskCompleted        = "task.completed"
+	TaskFailed           = "task.failed"
+	TaskCancelled        = "task.cancelled"
+	AlertTriggered       = "alert.triggered"
+	AlertCleared         = "alert.cleared"
 )
 
 // Persist is an optional interface that can be implemented to persist events.
@@ -31,22 +45,41 @@ type Persister interface {
 	Save(evt Event) error
 }
 
+// subscriberBuffer is how many events a subscriber can fall behind before
+// further events are dropped for it.
+const subscriberBuffer = 64
+
+// subscriber is one subscription and the events it missed.
+type subscriber struct {
+	ch      chan Event
+	dropped atomic.Uint64
+}
+
 // Bus is the central event distribution hub.
 // It allows components to publish events and subscribe to patterns.
 type Bus struct {
 	mu          sync.RWMutex
-	subscribers map[string][]chan Event // pattern -> channels
-	persister   Persister               // optional persistence
+	subscribers map[string][]*subscriber // pattern -> subscriptions
+	persister   Persister                // optional persistence
+
+	published atomic.Uint64
+	dropped   atomic.Uint64 // over all subscriptions, including closed ones
 }
 
 // NewBus creates a new event bus.
 func NewBus() *Bus {
 	return &Bus{
-		subscribers: make(map[string][]chan Event),
+		subscribers: make(map[string][]*subscriber),
 	}
 }
 
-// SetPersister sets an optional persister for events.
+// unpersisted are the patterns of events that are not persisted. Task
+// events are frequent and already tracked by the task manager, so they
+// would only flood the notification inbox.
+var unpersisted = []string{"task.*"}
+
+// SetPersister sets an optional persister for events. Events matching
+// unpersisted are only delivered to subscribers.
 func (b *Bus) SetPersister(p Persister) {
 	b.mu.Lock()
 	defer b.mu.Unlock()
@@ -54,33 +87,48 @@ func (b *Bus) SetPersister(p Persister) {
 }
 
 // Publish sends an event to all matching subscribers.
-// Events are sent asynchronously and non-blocking.
+// Publishing never blocks: a subscriber whose buffer is full misses the
+// event, which is counted in Stats, so one stalled consumer cannot hold
+// up the publisher or the other subscribers.
 func (b *Bus) Publish(evt Event) {
 	if evt.Time.IsZero() {
 		evt.Time = time.Now()
 	}
+	b.published.Add(1)
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
 
 	// Persist event if persister is set
-	if b.persister != nil {
+	if b.persister != nil && persisted(evt.Type) {
 		go b.persister.Save(evt) // Non-blocking
 	}
 
-	b.mu.RLock()
-	defer b.mu.RUnlock()
-
-	for pattern, channels := range b.subscribers {
+	for pattern, subs := range b.subscribers {
 		if matchPattern(pattern, evt.Type) {
-			for _, ch := range channels {
+			for _, sub := range subs {
 				select {
-				case ch <- evt:
+				case sub.ch <- evt:
 				default:
-					// Drop event if subscriber is too slow
+					sub.dropped.Add(1)
+					b.dropped.Add(1)
 				}
 			}
 		}
 	}
 }
 
+// persisted reports whether events of eventType are passed to the
+// persister.
+func persisted(eventType string) bool {
+	for _, pattern := range unpersisted {
+		if matchPattern(pattern, eventType) {
+			return false
+		}
+	}
+	return true
+}
+
 // Subscribe creates a subscription for events matching the pattern.
 // Pattern can be:
 //   - Exact match: "disk.added"
@@ -90,13 +138,13 @@ func (b *Bus) Publish(evt Event) {
 // The returned channel receives matching events.
 // The caller must call Unsubscribe when done to prevent leaks.
 func (b *Bus) Subscribe(pattern string) <-chan Event {
-	ch := make(chan Event, 10) // Buffer to prevent blocking
+	sub := &subscriber{ch: make(chan Event, subscriberBuffer)}
 
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
-	b.subscribers[pattern] = append(b.subscribers[pattern], ch)
-	return ch
+	b.subscribers[pattern] = append(b.subscribers[pattern], sub)
+	return sub.ch
 }
 
 // Unsubscribe removes a subscription.
@@ -104,12 +152,12 @@ func (b *Bus) Unsubscribe(pattern string, ch <-chan Event) {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
-	channels := b.subscribers[pattern]
-	for i, subscriber := range channels {
-		if subscriber == ch {
+	subs := b.subscribers[pattern]
+	for i, sub := range subs {
+		if sub.ch == ch {
 			// Remove from slice
-			b.subscribers[pattern] = append(channels[:i], channels[i+1:]...)
-			close(subscriber)
+			b.subscribers[pattern] = append(subs[:i], subs[i+1:]...)
+			close(sub.ch)
 
 			// Clean up empty pattern
 			if len(b.subscribers[pattern]) == 0 {
@@ -120,6 +168,42 @@ func (b *Bus) Unsubscribe(pattern string, ch <-chan Event) {
 	}
 }
 
+// PatternStats describes the subscriptions to one pattern.
+type PatternStats struct {
+	Subscribers int    `json:"subscribers"`
+	Dropped     uint64 `json:"dropped"` // events missed by current subscribers
+}
+
+// Stats describes the load on a Bus.
+type Stats struct {
+	Subscribers int                     `json:"subscribers"`
+	Published   uint64                  `json:"published"`
+	Dropped     uint64                  `json:"dropped"` // deliveries missed by slow subscribers, including past ones
+	Patterns    map[string]PatternStats `json:"patterns"`
+}
+
+// Stats returns the current subscriber counts and how many events were
+// dropped for slow subscribers.
+func (b *Bus) Stats() Stats {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	stats := Stats{
+		Published: b.published.Load(),
+		Dropped:   b.dropped.Load(),
+		Patterns:  make(map[string]PatternStats, len(b.subscribers)),
+	}
+	for pattern, subs := range b.subscribers {
+		ps := PatternStats{Subscribers: len(subs)}
+		for _, sub := range subs {
+			ps.Dropped += sub.dropped.Load()
+		}
+		stats.Patterns[pattern] = ps
+		stats.Subscribers += len(subs)
+	}
+	return stats
+}
+
 // matchPattern checks if an event type matches a subscription pattern.
 func matchPattern(pattern, eventType string) bool {
 	if pattern == "*" {
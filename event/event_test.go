@@ -190,6 +190,7 @@ func TestBus_WithPersister(t *testing.T) {
 	persister := &mockPersister{}
 	bus.SetPersister(persister)
 
+	bus.Publish(Event{Type: TaskProgress, Data: 50})
 	bus.Publish(Event{
 		Type: "test.event",
 		Data: "test_data",
@@ -217,3 +218,41 @@ func TestEvent_AutoTimestamp(t *testing.T) {
 		t.Fatal("Event not received")
 	}
 }
+
+func TestBus_SlowSubscriber(t *testing.T) {
+	bus := NewBus()
+	slow := bus.Subscribe("test.*") // never read
+	defer bus.Unsubscribe("test.*", slow)
+	fast := bus.Subscribe("*")
+	defer bus.Unsubscribe("*", fast)
+
+	// The fast subscriber receives every one of far more events than the
+	// stalled subscriber can buffer
+	const n = subscriberBuffer * 10
+	for i := range n {
+		published := make(chan struct{})
+		go func() {
+			bus.Publish(Event{Type: "test.event", Data: i})
+			close(published)
+		}()
+
+		select {
+		case <-published:
+		case <-time.After(time.Second):
+			t.Fatalf("Publish of event %d blocked on the slow subscriber", i)
+		}
+		select {
+		case evt := <-fast:
+			require.Equal(t, i, evt.Data)
+		case <-time.After(time.Second):
+			t.Fatalf("fast subscriber did not receive event %d", i)
+		}
+	}
+
+	stats := bus.Stats()
+	require.Equal(t, 2, stats.Subscribers)
+	require.Equal(t, uint64(n), stats.Published)
+	require.Equal(t, uint64(n-subscriberBuffer), stats.Dropped)
+	require.Equal(t, PatternStats{Subscribers: 1, Dropped: n - subscriberBuffer}, stats.Patterns["test.*"])
+	require.Equal(t, PatternStats{Subscribers: 1}, stats.Patterns["*"])
+}
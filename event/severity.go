@@ -0,0 +1,72 @@
+package event
+
+// Severity ranks how urgently an event needs the admin's attention.
+type Severity string
+
+const (
+	SeverityInfo     Severity = "info"
+	SeverityWarning  Severity = "warning"
+	SeverityCritical Severity = "critical"
+)
+
+// severities maps event types above info to their severity.
+var severities = map[string]Severity{
+	SmartFailed:          SeverityCritical,
+	DiskPredictedFailure: SeverityCritical,
+	DiskReadOnly:         SeverityCritical,
+	PoolDegraded:         SeverityCritical,
+	PoolDiskErrors:       SeverityCritical,
+	DiskRemoved:          SeverityWarning,
+	NetworkErrors:        SeverityWarning,
+	TaskFailed:           SeverityWarning,
+	AlertTriggered:       SeverityWarning,
+}
+
+// SeverityOf returns the severity of an event type. Types not listed are info.
+func SeverityOf(eventType string) Severity {
+	if s, ok := severities[eventType]; ok {
+		return s
+	}
+	return SeverityInfo
+}
+
+// severer is implemented by event data whose severity depends on the
+// event rather than its type, such as a triggered alert.
+type severer interface {
+	EventSeverity() Severity
+}
+
+// Severity returns the severity of e: the one its data reports, if any,
+// otherwise that of its type.
+func (e Event) Severity() Severity {
+	if s, ok := e.Data.(severer); ok {
+		return s.EventSeverity()
+	}
+	return SeverityOf(e.Type)
+}
+
+// Valid reports whether s is a known severity.
+func (s Severity) Valid() bool {
+	return s == SeverityInfo || s == SeverityWarning || s == SeverityCritical
+}
+
+// AtLeast reports whether s is as severe as min or more.
+func (s Severity) AtLeast(min Severity) bool {
+	return s.rank() >= min.rank()
+}
+
+func (s Severity) rank() int {
+	switch s {
+	case SeverityWarning:
+		return 1
+	case SeverityCritical:
+		return 2
+	}
+	return 0
+}
+
+// Match reports whether an event type matches a subscription pattern, using
+// the same rules as Subscribe.
+func Match(pattern, eventType string) bool {
+	return matchPattern(pattern, eventType)
+}
@@ -0,0 +1,55 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"go.aimuz.me/mynt/alert"
+)
+
+// WithAlertRules lets admins view and edit the threshold rules evaluated
+// by alerts. Without it the endpoints answer 503.
+func WithAlertRules(alerts *alert.Evaluator) Option {
+	return func(s *Server) {
+		s.alertRules = alerts
+	}
+}
+
+// handleGetAlertRules returns the threshold alert rules.
+func (s *Server) handleGetAlertRules(w http.ResponseWriter, r *http.Request) {
+	if s.alertRules == nil {
+		http.Error(w, "alert rules are not available", http.StatusServiceUnavailable)
+		return
+	}
+	respondJSON(w, http.StatusOK, s.alertRules.Rules())
+}
+
+// handleSetAlertRules replaces the threshold alert rules. They are
+// persisted and take effect on the next scan.
+func (s *Server) handleSetAlertRules(w http.ResponseWriter, r *http.Request) {
+	if s.alertRules == nil {
+		http.Error(w, "alert rules are not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	var rules []alert.Rule
+	if !s.decodeJSON(w, r, &rules) {
+		return
+	}
+	if rules == nil {
+		rules = []alert.Rule{}
+	}
+	for i := range rules {
+		if err := rules[i].Validate(); err != nil {
+			http.Error(w, fmt.Sprintf("rule %d: %v", i+1, err), http.StatusBadRequest)
+			return
+		}
+	}
+
+	if err := s.config.SetAlertRules(rules); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	s.alertRules.SetRules(rules)
+	respondJSON(w, http.StatusOK, rules)
+}
@@ -0,0 +1,43 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.aimuz.me/mynt/alert"
+	"go.aimuz.me/mynt/event"
+	"go.aimuz.me/mynt/store"
+)
+
+func TestHandleSetAlertRules(t *testing.T) {
+	db, err := store.Open(":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	cfg := store.NewConfigRepo(db)
+
+	alerts := alert.NewEvaluator(event.NewBus(), alert.DefaultRules)
+	s := &Server{config: cfg, maxBodyBytes: DefaultMaxBodyBytes}
+	WithAlertRules(alerts)(s)
+
+	put := func(body string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		s.handleSetAlertRules(rr, httptest.NewRequest(http.MethodPut, "/api/v1/config/alert-rules", strings.NewReader(body)))
+		return rr
+	}
+
+	require.Equal(t, http.StatusBadRequest, put(`[{"metric": "disk_temp", "op": "=", "threshold": 50}]`).Code)
+	require.Equal(t, http.StatusBadRequest, put(`[{"metric": "fan_speed", "op": "<", "threshold": 500}]`).Code)
+	require.Equal(t, alert.DefaultRules, alerts.Rules())
+
+	rr := put(`[{"metric": "disk_temp", "op": ">", "threshold": 50, "hysteresis": 5}]`)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	want := []alert.Rule{{Metric: alert.DiskTemp, Op: alert.OpAbove, Threshold: 50, Hysteresis: 5, Severity: event.SeverityWarning}}
+	require.Equal(t, want, alerts.Rules())
+	saved, err := cfg.AlertRules()
+	require.NoError(t, err)
+	require.Equal(t, want, saved)
+}
@@ -0,0 +1,178 @@
+package api
+
+import (
+	"cmp"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"go.aimuz.me/mynt/alert"
+	"go.aimuz.me/mynt/disk"
+	"go.aimuz.me/mynt/event"
+	"go.aimuz.me/mynt/logger"
+	"go.aimuz.me/mynt/store"
+	"go.aimuz.me/mynt/zfs"
+)
+
+// Alert is an outstanding problem with a pool, disk or dataset. Unlike a
+// notification it is computed from current state, so it disappears once
+// the condition is resolved.
+type Alert struct {
+	Kind     string         `json:"kind"`     // "pool", "disk" or "dataset"
+	Resource string         `json:"resource"` // pool, disk or dataset name
+	Severity event.Severity `json:"severity"`
+	Message  string         `json:"message"`
+	Action   string         `json:"action"` // recommended fix
+}
+
+// alertSet keeps the most severe alert per resource.
+type alertSet map[string]Alert
+
+func (a alertSet) add(alert Alert) {
+	key := alert.Kind + ":" + alert.Resource
+	if prev, ok := a[key]; ok && prev.Severity.AtLeast(alert.Severity) {
+		return
+	}
+	a[key] = alert
+}
+
+// sorted returns the alerts most severe first, then by resource.
+func (a alertSet) sorted() []Alert {
+	alerts := make([]Alert, 0, len(a))
+	for _, alert := range a {
+		alerts = append(alerts, alert)
+	}
+	slices.SortFunc(alerts, func(x, y Alert) int {
+		if x.Severity != y.Severity {
+			if x.Severity.AtLeast(y.Severity) {
+				return -1
+			}
+			return 1
+		}
+		return cmp.Or(cmp.Compare(x.Kind, y.Kind), cmp.Compare(x.Resource, y.Resource))
+	})
+	return alerts
+}
+
+// evaluateAlerts derives the active alerts from pool status, cached SMART
+// data and dataset usage.
+func evaluateAlerts(pools []zfs.Pool, smart map[string]*store.SmartState, datasets []zfs.Dataset) []Alert {
+	alerts := make(alertSet)
+
+	for _, p := range pools {
+		if p.Health != zfs.PoolOnline {
+			h := zfs.AssessHealth(p)
+			sev := event.SeverityWarning
+			if h.RiskLevel == "critical" {
+				sev = event.SeverityCritical
+			}
+			alerts.add(Alert{
+				Kind:     "pool",
+				Resource: p.Name,
+				Severity: sev,
+				Message:  fmt.Sprintf("Pool is %s. %s", p.Health, h.RiskDescription),
+				Action:   h.Recommendation,
+			})
+		}
+		if p.Size > 0 {
+			if alert, ok := capacityAlert("pool", p.Name, p.Allocated, p.Size,
+				"Free space by deleting data or old snapshots, or add a vdev."); ok {
+				alerts.add(alert)
+			}
+		}
+	}
+
+	for name, st := range smart {
+		switch {
+		case !st.Passed:
+			alerts.add(Alert{
+				Kind:     "disk",
+				Resource: name,
+				Severity: event.SeverityCritical,
+				Message:  "SMART overall health check failed.",
+				Action:   "Back up its data and replace the disk.",
+			})
+		case st.ReallocatedSectors > 0 || st.PendingSectors > 0 || st.UncorrectableErrors > 0:
+			alerts.add(Alert{
+				Kind:     "disk",
+				Resource: name,
+				Severity: event.SeverityWarning,
+				Message: fmt.Sprintf("Disk reports %d reallocated, %d pending and %d uncorrectable sectors.",
+					st.ReallocatedSectors, st.PendingSectors, st.UncorrectableErrors),
+				Action: "Run a long SMART test and plan to replace the disk if the counts grow.",
+			})
+		case (&disk.DetailedReport{PercentageUsed: st.PercentageUsed, WearLevelingCount: st.WearLevelingCount}).WearExceeded():
+			alerts.add(Alert{
+				Kind:     "disk",
+				Resource: name,
+				Severity: event.SeverityWarning,
+				Message:  fmt.Sprintf("SSD has used more than %d%% of its rated endurance.", disk.WearThreshold),
+				Action:   "Plan to replace the disk.",
+			})
+		}
+	}
+
+	// Datasets without a quota share their pool's free space, which the
+	// pool alert already covers.
+	for _, d := range datasets {
+		if d.Type == zfs.DatasetSnapshot || d.Quota == 0 {
+			continue
+		}
+		if alert, ok := capacityAlert("dataset", d.Name, d.Used, d.Quota,
+			"Raise the quota or free space in the dataset."); ok {
+			alerts.add(alert)
+		}
+	}
+
+	return alerts.sorted()
+}
+
+// capacityAlert reports a resource using at least
+// alert.CapacityWarnPercent of limit.
+func capacityAlert(kind, name string, used, limit uint64, action string) (Alert, bool) {
+	pct := used * 100 / limit
+	if pct < alert.CapacityWarnPercent {
+		return Alert{}, false
+	}
+	sev := event.SeverityWarning
+	if pct >= alert.CapacityCriticalPercent {
+		sev = event.SeverityCritical
+	}
+	return Alert{
+		Kind:     kind,
+		Resource: name,
+		Severity: sev,
+		Message:  fmt.Sprintf("%d%% of capacity used.", pct),
+		Action:   action,
+	}, true
+}
+
+// handleListAlerts evaluates current pool, disk and dataset state and
+// returns the outstanding problems. Disabled subsystems are skipped.
+func (s *Server) handleListAlerts(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var (
+		pools    []zfs.Pool
+		datasets []zfs.Dataset
+		smart    map[string]*store.SmartState
+		err      error
+	)
+
+	if !s.disabled[SubsystemZFS] && s.zfs != nil {
+		if pools, err = s.zfs.ListPools(ctx); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if datasets, err = s.zfs.ListDatasets(ctx); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	if !s.disabled[SubsystemDisks] && s.diskRepo != nil {
+		if smart, err = s.diskRepo.ListSmart(); err != nil {
+			logger.Warn("failed to read SMART cache for alerts", "error", err)
+		}
+	}
+
+	respondJSON(w, http.StatusOK, evaluateAlerts(pools, smart, datasets))
+}
@@ -0,0 +1,57 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.aimuz.me/mynt/event"
+	"go.aimuz.me/mynt/store"
+	"go.aimuz.me/mynt/zfs"
+)
+
+func TestEvaluateAlerts_DegradedPool(t *testing.T) {
+	pools := []zfs.Pool{
+		{Name: "tank", Health: zfs.PoolDegraded, Redundancy: 0, Size: 1000, Allocated: 400},
+		{Name: "backup", Health: zfs.PoolOnline, Redundancy: 1, Size: 1000, Allocated: 400},
+	}
+
+	alerts := evaluateAlerts(pools, nil, nil)
+	require.Len(t, alerts, 1)
+	require.Equal(t, "pool", alerts[0].Kind)
+	require.Equal(t, "tank", alerts[0].Resource)
+	require.Equal(t, event.SeverityCritical, alerts[0].Severity)
+	require.NotEmpty(t, alerts[0].Action)
+}
+
+func TestEvaluateAlerts(t *testing.T) {
+	pct := func(n int) *int { return &n }
+	pools := []zfs.Pool{
+		// Degraded and nearly full: one alert, the more severe.
+		{Name: "tank", Health: zfs.PoolDegraded, Redundancy: 1, Size: 100, Allocated: 97},
+		{Name: "backup", Health: zfs.PoolOnline, Redundancy: 1, Size: 100, Allocated: 90},
+	}
+	smart := map[string]*store.SmartState{
+		"sda":     {DiskName: "sda", Passed: true},
+		"sdb":     {DiskName: "sdb", Passed: false},
+		"sdc":     {DiskName: "sdc", Passed: true, PendingSectors: 8},
+		"nvme0n1": {DiskName: "nvme0n1", Passed: true, PercentageUsed: pct(92)},
+	}
+	datasets := []zfs.Dataset{
+		{Name: "tank/media", Type: zfs.DatasetFilesystem, Used: 950, Quota: 1000},
+		{Name: "tank/home", Type: zfs.DatasetFilesystem, Used: 10, Quota: 1000},
+		{Name: "tank/vm", Type: zfs.DatasetFilesystem, Used: 1 << 40}, // no quota
+	}
+
+	got := make(map[string]event.Severity)
+	for _, a := range evaluateAlerts(pools, smart, datasets) {
+		got[a.Kind+":"+a.Resource] = a.Severity
+	}
+	require.Equal(t, map[string]event.Severity{
+		"pool:tank":          event.SeverityCritical,
+		"pool:backup":        event.SeverityWarning,
+		"disk:sdb":           event.SeverityCritical,
+		"disk:sdc":           event.SeverityWarning,
+		"disk:nvme0n1":       event.SeverityWarning,
+		"dataset:tank/media": event.SeverityCritical,
+	}, got)
+}
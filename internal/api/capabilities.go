@@ -0,0 +1,34 @@
+package api
+
+import (
+	"net/http"
+
+	"go.aimuz.me/mynt/sysinfo"
+)
+
+// WithCapabilities sets the host capabilities reported to clients,
+// usually probed once at startup.
+func WithCapabilities(caps sysinfo.Capabilities) Option {
+	return func(s *Server) {
+		s.capabilities = caps
+	}
+}
+
+// handleCapabilities reports what this host supports. Disabled subsystems
+// are reported as unavailable.
+func (s *Server) handleCapabilities(w http.ResponseWriter, r *http.Request) {
+	caps := s.capabilities
+	if s.disabled[SubsystemZFS] {
+		caps.ZFS = sysinfo.Capability{}
+	}
+	if s.disabled[SubsystemShares] {
+		caps.Samba = sysinfo.Capability{}
+		caps.NFS = sysinfo.Capability{}
+	}
+	if s.disabled[SubsystemDisks] {
+		caps.Smartctl = sysinfo.Capability{}
+		caps.Ledctl = sysinfo.Capability{}
+	}
+
+	respondJSON(w, http.StatusOK, caps)
+}
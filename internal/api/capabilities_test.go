@@ -0,0 +1,34 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.aimuz.me/mynt/sysinfo"
+)
+
+func TestHandleCapabilities_HidesDisabled(t *testing.T) {
+	available := sysinfo.Capability{Available: true, Version: "1.0"}
+	s := &Server{}
+	WithCapabilities(sysinfo.Capabilities{
+		ZFS:      available,
+		Samba:    available,
+		NFS:      available,
+		Smartctl: available,
+	})(s)
+	WithDisabled(SubsystemShares)(s)
+
+	rr := httptest.NewRecorder()
+	s.handleCapabilities(rr, httptest.NewRequest(http.MethodGet, "/api/v1/capabilities", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var got sysinfo.Capabilities
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&got))
+	require.Equal(t, available, got.ZFS)
+	require.Equal(t, available, got.Smartctl)
+	require.False(t, got.Samba.Available)
+	require.False(t, got.NFS.Available)
+}
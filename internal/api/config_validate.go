@@ -0,0 +1,172 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+
+	"go.aimuz.me/mynt/event"
+	"go.aimuz.me/mynt/store"
+	"go.aimuz.me/mynt/zfs"
+)
+
+// Finding is one problem found while validating the configuration.
+type Finding struct {
+	Check    string         `json:"check"` // "pools", "shares", "policies", "samba" or "users"
+	Severity event.Severity `json:"severity"`
+	Resource string         `json:"resource,omitempty"`
+	Message  string         `json:"message"`
+}
+
+// configState is what validateConfig checks. Nil slices are skipped, so
+// subsystems that are disabled or failed to load produce no findings.
+type configState struct {
+	pools        []zfs.Pool
+	datasets     []zfs.Dataset
+	shares       []store.Share
+	policies     []store.SnapshotPolicy
+	users        []store.User
+	missingUsers []string // system accounts absent from the host
+	sambaErr     error    // testparm result
+}
+
+// validateConfig checks the configuration for problems that would break
+// shares, snapshots or logins.
+func validateConfig(st configState) []Finding {
+	findings := []Finding{}
+
+	for _, p := range st.pools {
+		if p.Health != zfs.PoolOnline {
+			findings = append(findings, Finding{
+				Check:    "pools",
+				Severity: event.SeverityCritical,
+				Resource: p.Name,
+				Message:  fmt.Sprintf("pool is %s", p.Health),
+			})
+		}
+	}
+
+	for _, sh := range st.shares {
+		if _, err := os.Stat(sh.Path); err != nil {
+			findings = append(findings, Finding{
+				Check:    "shares",
+				Severity: event.SeverityCritical,
+				Resource: sh.Name,
+				Message:  fmt.Sprintf("share path %s is not accessible: %v", sh.Path, err),
+			})
+		}
+	}
+
+	if st.datasets != nil {
+		names := make(map[string]bool, len(st.datasets))
+		for _, d := range st.datasets {
+			names[d.Name] = true
+		}
+		for _, p := range st.policies {
+			for _, ds := range p.DatasetNames() {
+				if !names[ds] {
+					findings = append(findings, Finding{
+						Check:    "policies",
+						Severity: event.SeverityWarning,
+						Resource: p.Name,
+						Message:  fmt.Sprintf("dataset %s does not exist", ds),
+					})
+				}
+			}
+		}
+	}
+
+	if st.sambaErr != nil {
+		findings = append(findings, Finding{
+			Check:    "samba",
+			Severity: event.SeverityCritical,
+			Message:  st.sambaErr.Error(),
+		})
+	}
+
+	for _, name := range st.missingUsers {
+		findings = append(findings, Finding{
+			Check:    "users",
+			Severity: event.SeverityWarning,
+			Resource: name,
+			Message:  "system account does not exist on the host",
+		})
+	}
+	if st.users != nil {
+		known := make([]string, len(st.users))
+		for i, u := range st.users {
+			known[i] = u.Username
+		}
+		for _, sh := range st.shares {
+			for _, u := range strings.Split(sh.ValidUsers, ",") {
+				u = strings.TrimSpace(u)
+				// @group and +group entries name groups, not users
+				if u == "" || strings.HasPrefix(u, "@") || strings.HasPrefix(u, "+") || slices.Contains(known, u) {
+					continue
+				}
+				findings = append(findings, Finding{
+					Check:    "users",
+					Severity: event.SeverityWarning,
+					Resource: sh.Name,
+					Message:  fmt.Sprintf("valid user %s is not a known user", u),
+				})
+			}
+		}
+	}
+
+	return findings
+}
+
+// handleValidateConfig runs the configuration checks and returns their
+// findings; an empty list means nothing was found.
+func (s *Server) handleValidateConfig(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var (
+		st  configState
+		err error
+	)
+
+	if !s.disabled[SubsystemZFS] && s.zfs != nil {
+		if st.pools, err = s.zfs.ListPools(ctx); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if st.datasets, err = s.zfs.ListDatasets(ctx); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if st.datasets == nil {
+			st.datasets = []zfs.Dataset{} // no datasets: every policy dataset is missing
+		}
+	}
+	if s.snapshotPolicy != nil {
+		if st.policies, err = s.snapshotPolicy.List(); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	if !s.disabled[SubsystemShares] && s.share != nil {
+		if st.shares, err = s.share.ListShares(""); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		// smb.conf is only written once an SMB share exists
+		if slices.ContainsFunc(st.shares, func(sh store.Share) bool { return sh.Protocol == "smb" }) {
+			st.sambaErr = s.share.CheckConfig()
+		}
+	}
+	if s.user != nil {
+		if st.users, err = s.user.List(); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if st.missingUsers, err = s.user.MissingSystemUsers(ctx); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	respondJSON(w, http.StatusOK, validateConfig(st))
+}
@@ -0,0 +1,62 @@
+package api
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.aimuz.me/mynt/store"
+	"go.aimuz.me/mynt/zfs"
+)
+
+func TestValidateConfig(t *testing.T) {
+	dir := t.TempDir()
+	st := configState{
+		pools: []zfs.Pool{
+			{Name: "tank", Health: zfs.PoolOnline},
+		},
+		datasets: []zfs.Dataset{
+			{Name: "tank"},
+			{Name: "tank/home"},
+		},
+		shares: []store.Share{
+			{Name: "home", Path: dir, Protocol: "smb", ValidUsers: "alice, @staff"},
+			{Name: "media", Path: filepath.Join(dir, "missing"), Protocol: "smb"},
+		},
+		policies: []store.SnapshotPolicy{
+			{Name: "daily", Datasets: []store.PolicyTarget{{Dataset: "tank/home"}, {Dataset: "tank/old"}}},
+		},
+		users: []store.User{{Username: "alice"}},
+	}
+
+	findings := validateConfig(st)
+	require.Len(t, findings, 2, "%+v", findings)
+	require.Equal(t, "shares", findings[0].Check)
+	require.Equal(t, "media", findings[0].Resource)
+	require.Equal(t, "policies", findings[1].Check)
+	require.Equal(t, "daily", findings[1].Resource)
+	require.Contains(t, findings[1].Message, "tank/old")
+}
+
+func TestValidateConfig_Other(t *testing.T) {
+	st := configState{
+		pools:        []zfs.Pool{{Name: "tank", Health: zfs.PoolDegraded}},
+		shares:       []store.Share{{Name: "home", Path: t.TempDir(), ValidUsers: "bob"}},
+		users:        []store.User{{Username: "alice"}},
+		missingUsers: []string{"carol"},
+		sambaErr:     errors.New("config test failed: unknown parameter"),
+	}
+
+	var checks []string
+	for _, f := range validateConfig(st) {
+		checks = append(checks, f.Check+":"+f.Resource)
+	}
+	require.Equal(t, []string{"pools:tank", "samba:", "users:carol", "users:home"}, checks)
+}
+
+func TestValidateConfig_Clean(t *testing.T) {
+	findings := validateConfig(configState{})
+	require.NotNil(t, findings)
+	require.Empty(t, findings)
+}
@@ -0,0 +1,145 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// confirmHeader carries the confirmation token on the second call of a
+// destructive request.
+const confirmHeader = "X-Confirm-Token"
+
+// requiredConfirmTTL is how long a confirmation token stays valid for
+// actions that need one without WithConfirmation.
+const requiredConfirmTTL = 2 * time.Minute
+
+// Option configures a Server.
+type Option func(*Server)
+
+// WithConfirmation requires a two-step confirmation for destructive
+// endpoints. The first call responds 428 Precondition Required with a token
+// describing the impact; repeating the call with the token in the
+// X-Confirm-Token header performs the action. Tokens are single-use and
+// expire after ttl. Without this option destructive endpoints act
+// immediately, which suits API scripts; destroying a pool needs a token
+// either way.
+func WithConfirmation(ttl time.Duration) Option {
+	return func(s *Server) {
+		s.confirms = newConfirmStore(ttl)
+	}
+}
+
+// confirmationResponse is returned when a destructive action needs confirmation.
+type confirmationResponse struct {
+	Token     string    `json:"token"`
+	Action    string    `json:"action"`
+	Impact    string    `json:"impact"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// confirmStore holds pending confirmation tokens.
+type confirmStore struct {
+	mu     sync.Mutex
+	ttl    time.Duration
+	tokens map[string]pendingConfirm
+	now    func() time.Time
+}
+
+type pendingConfirm struct {
+	action  string
+	expires time.Time
+}
+
+func newConfirmStore(ttl time.Duration) *confirmStore {
+	return &confirmStore{
+		ttl:    ttl,
+		tokens: make(map[string]pendingConfirm),
+		now:    time.Now,
+	}
+}
+
+// issue creates a token bound to action.
+func (c *confirmStore) issue(action string) (string, time.Time) {
+	b := make([]byte, 16)
+	rand.Read(b)
+	token := hex.EncodeToString(b)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	for t, p := range c.tokens {
+		if now.After(p.expires) {
+			delete(c.tokens, t)
+		}
+	}
+
+	expires := now.Add(c.ttl)
+	c.tokens[token] = pendingConfirm{action: action, expires: expires}
+	return token, expires
+}
+
+// consume reports whether token is valid for action, invalidating it.
+func (c *confirmStore) consume(token, action string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	p, ok := c.tokens[token]
+	if !ok {
+		return false
+	}
+	delete(c.tokens, token)
+	return p.action == action && !c.now().After(p.expires)
+}
+
+// confirm gates a destructive action and reports whether the handler may
+// proceed. If confirmation is required and no token was sent, it replies
+// with a new token and the impact returned by impact.
+func (s *Server) confirm(w http.ResponseWriter, r *http.Request, action string, impact func(ctx context.Context) (string, error)) bool {
+	if s.confirms == nil {
+		return true
+	}
+	return confirmWith(s.confirms, w, r, action, impact)
+}
+
+// confirmRequired is confirm for actions too destructive to run on a
+// single call, such as destroying a pool: they need a token even without
+// WithConfirmation.
+func (s *Server) confirmRequired(w http.ResponseWriter, r *http.Request, action string, impact func(ctx context.Context) (string, error)) bool {
+	confirms := s.confirms
+	if confirms == nil {
+		s.requiredConfirmsOnce.Do(func() { s.requiredConfirms = newConfirmStore(requiredConfirmTTL) })
+		confirms = s.requiredConfirms
+	}
+	return confirmWith(confirms, w, r, action, impact)
+}
+
+// confirmWith implements confirm with the tokens of confirms.
+func confirmWith(confirms *confirmStore, w http.ResponseWriter, r *http.Request, action string, impact func(ctx context.Context) (string, error)) bool {
+	token := r.Header.Get(confirmHeader)
+	if token == "" {
+		desc, err := impact(r.Context())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return false
+		}
+		token, expires := confirms.issue(action)
+		respondJSON(w, http.StatusPreconditionRequired, confirmationResponse{
+			Token:     token,
+			Action:    action,
+			Impact:    desc,
+			ExpiresAt: expires,
+		})
+		return false
+	}
+
+	if !confirms.consume(token, action) {
+		http.Error(w, "invalid or expired confirmation token", http.StatusPreconditionFailed)
+		return false
+	}
+	return true
+}
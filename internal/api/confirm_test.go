@@ -0,0 +1,162 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// confirmServer returns a server whose only route destroys "things" behind
+// a confirmation, recording how often the action actually ran.
+func confirmServer(t *testing.T, destroyed *int) (*Server, http.Handler) {
+	t.Helper()
+	s := &Server{}
+	WithConfirmation(time.Minute)(s)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("DELETE /things/{name}", func(w http.ResponseWriter, r *http.Request) {
+		name := r.PathValue("name")
+		if !s.confirm(w, r, "destroy thing "+name, func(ctx context.Context) (string, error) {
+			return "will destroy 3 datasets, 12 snapshots", nil
+		}) {
+			return
+		}
+		*destroyed++
+		w.WriteHeader(http.StatusNoContent)
+	})
+	return s, mux
+}
+
+func doDelete(h http.Handler, path, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodDelete, path, nil)
+	if token != "" {
+		req.Header.Set(confirmHeader, token)
+	}
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	return rr
+}
+
+func requestToken(t *testing.T, h http.Handler, path string) confirmationResponse {
+	t.Helper()
+	rr := doDelete(h, path, "")
+	require.Equal(t, http.StatusPreconditionRequired, rr.Code)
+
+	var resp confirmationResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+	require.NotEmpty(t, resp.Token)
+	return resp
+}
+
+func TestConfirm_RequiresToken(t *testing.T) {
+	var destroyed int
+	_, h := confirmServer(t, &destroyed)
+
+	resp := requestToken(t, h, "/things/a")
+	require.Equal(t, "destroy thing a", resp.Action)
+	require.Equal(t, "will destroy 3 datasets, 12 snapshots", resp.Impact)
+	require.Zero(t, destroyed)
+
+	// Made-up token is rejected
+	rr := doDelete(h, "/things/a", "bogus")
+	require.Equal(t, http.StatusPreconditionFailed, rr.Code)
+	require.Zero(t, destroyed)
+}
+
+func TestConfirm_ValidTokenProceeds(t *testing.T) {
+	var destroyed int
+	_, h := confirmServer(t, &destroyed)
+
+	resp := requestToken(t, h, "/things/a")
+	rr := doDelete(h, "/things/a", resp.Token)
+	require.Equal(t, http.StatusNoContent, rr.Code)
+	require.Equal(t, 1, destroyed)
+
+	// Tokens are single-use
+	rr = doDelete(h, "/things/a", resp.Token)
+	require.Equal(t, http.StatusPreconditionFailed, rr.Code)
+	require.Equal(t, 1, destroyed)
+}
+
+func TestConfirm_TokenBoundToAction(t *testing.T) {
+	var destroyed int
+	_, h := confirmServer(t, &destroyed)
+
+	resp := requestToken(t, h, "/things/a")
+	rr := doDelete(h, "/things/b", resp.Token)
+	require.Equal(t, http.StatusPreconditionFailed, rr.Code)
+	require.Zero(t, destroyed)
+}
+
+func TestConfirm_TokenExpires(t *testing.T) {
+	var destroyed int
+	s, h := confirmServer(t, &destroyed)
+
+	resp := requestToken(t, h, "/things/a")
+	s.confirms.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+
+	rr := doDelete(h, "/things/a", resp.Token)
+	require.Equal(t, http.StatusPreconditionFailed, rr.Code)
+	require.Zero(t, destroyed)
+}
+
+func TestConfirm_Disabled(t *testing.T) {
+	s := &Server{}
+	req := httptest.NewRequest(http.MethodDelete, "/", nil)
+	rr := httptest.NewRecorder()
+
+	ok := s.confirm(rr, req, "destroy thing a", func(ctx context.Context) (string, error) {
+		t.Fatal("impact should not be computed when confirmation is disabled")
+		return "", nil
+	})
+	require.True(t, ok)
+}
+
+func TestHandleRollbackSnapshot_RequiresConfirmation(t *testing.T) {
+	s := &Server{}
+	WithConfirmation(time.Minute)(s)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/snapshots/rollback?name=tank/data@snap1", nil)
+	rr := httptest.NewRecorder()
+	s.handleRollbackSnapshot(rr, req)
+	require.Equal(t, http.StatusPreconditionRequired, rr.Code)
+
+	var resp confirmationResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+	require.Equal(t, "rollback tank/data@snap1", resp.Action)
+	require.Contains(t, resp.Impact, "tank/data")
+
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/snapshots/rollback?name=tank/data@snap1", nil)
+	req.Header.Set(confirmHeader, "bogus")
+	rr = httptest.NewRecorder()
+	s.handleRollbackSnapshot(rr, req)
+	require.Equal(t, http.StatusPreconditionFailed, rr.Code)
+}
+
+func TestConfirmRequired_WithoutConfirmation(t *testing.T) {
+	s := &Server{}
+	var destroyed int
+	mux := http.NewServeMux()
+	mux.HandleFunc("DELETE /pools/{name}", func(w http.ResponseWriter, r *http.Request) {
+		if !s.confirmRequired(w, r, "destroy pool "+r.PathValue("name"), func(ctx context.Context) (string, error) {
+			return "will destroy 2 datasets", nil
+		}) {
+			return
+		}
+		destroyed++
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	resp := requestToken(t, mux, "/pools/tank")
+	require.Equal(t, "destroy pool tank", resp.Action)
+	require.Equal(t, 0, destroyed)
+
+	rr := doDelete(mux, "/pools/tank", resp.Token)
+	require.Equal(t, http.StatusNoContent, rr.Code)
+	require.Equal(t, 1, destroyed)
+}
@@ -0,0 +1,26 @@
+package api
+
+import (
+	"go.aimuz.me/mynt/share"
+	"go.aimuz.me/mynt/store"
+	"go.aimuz.me/mynt/zfs"
+)
+
+// markShared sets Shared and Shares on each dataset that a share exports.
+// A share belongs to the dataset share.DatasetForPath picks, so a share
+// inside a child dataset marks the child only.
+func markShared(datasets []zfs.Dataset, shares []store.Share) {
+	index := make(map[string]int, len(datasets))
+	for i, ds := range datasets {
+		index[ds.Name] = i
+	}
+	for _, sh := range shares {
+		name, ok := share.DatasetForPath(sh.Path, datasets)
+		if !ok {
+			continue
+		}
+		i := index[name]
+		datasets[i].Shared = true
+		datasets[i].Shares = append(datasets[i].Shares, sh.ID)
+	}
+}
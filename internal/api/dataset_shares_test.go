@@ -0,0 +1,43 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.aimuz.me/mynt/store"
+	"go.aimuz.me/mynt/zfs"
+)
+
+func TestMarkShared(t *testing.T) {
+	datasets := []zfs.Dataset{
+		{Name: "tank", Mountpoint: "/mnt/tank"},
+		{Name: "tank/media", Mountpoint: "/mnt/tank/media"},
+		{Name: "tank/media/photos", Mountpoint: "/mnt/tank/media/photos"},
+		{Name: "tank/vm", Mountpoint: "none"},
+		{Name: "tank/mediaold", Mountpoint: "/mnt/tank/mediaold"},
+		{Name: "tank/docs", Mountpoint: "/mnt/tank/docs/"}, // not cleaned
+	}
+	shares := []store.Share{
+		{ID: 1, Name: "media", Path: "/mnt/tank/media"},
+		{ID: 2, Name: "music", Path: "/mnt/tank/media/music/"}, // a directory of tank/media
+		{ID: 3, Name: "photos", Path: "/mnt/tank/media/photos"},
+		{ID: 4, Name: "elsewhere", Path: "/srv/public"},
+		{ID: 5, Name: "docs", Path: "/mnt/tank/docs/../docs/team"},
+	}
+
+	markShared(datasets, shares)
+
+	byName := make(map[string]zfs.Dataset)
+	for _, ds := range datasets {
+		byName[ds.Name] = ds
+	}
+	require.True(t, byName["tank/media"].Shared)
+	require.Equal(t, []int64{1, 2}, byName["tank/media"].Shares)
+	require.True(t, byName["tank/media/photos"].Shared)
+	require.Equal(t, []int64{3}, byName["tank/media/photos"].Shares)
+	require.Equal(t, []int64{5}, byName["tank/docs"].Shares)
+	for _, name := range []string{"tank", "tank/vm", "tank/mediaold"} {
+		require.False(t, byName[name].Shared, name)
+		require.Empty(t, byName[name].Shares, name)
+	}
+}
@@ -0,0 +1,144 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"go.aimuz.me/mynt/store"
+	"go.aimuz.me/mynt/zfs"
+)
+
+// WithDatasetTemplates enables custom dataset templates stored in repo.
+// Without it only the built-in templates are listed and changes are
+// rejected.
+func WithDatasetTemplates(repo *store.DatasetTemplateRepo) Option {
+	return func(s *Server) {
+		s.templates = repo
+	}
+}
+
+// datasetTemplate is a built-in or custom template as listed to clients.
+type datasetTemplate struct {
+	Name       string            `json:"name"`
+	Properties map[string]string `json:"properties"`
+	Builtin    bool              `json:"builtin"`
+}
+
+// handleListDatasetTemplates lists the built-in templates followed by the
+// custom ones.
+func (s *Server) handleListDatasetTemplates(w http.ResponseWriter, r *http.Request) {
+	templates := make([]datasetTemplate, 0, len(zfs.BuiltinTemplates))
+	for _, name := range zfs.BuiltinTemplates {
+		templates = append(templates, datasetTemplate{
+			Name:       string(name),
+			Properties: zfs.GetTemplateProperties(name),
+			Builtin:    true,
+		})
+	}
+
+	if s.templates != nil {
+		custom, err := s.templates.List()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		for _, t := range custom {
+			templates = append(templates, datasetTemplate{Name: t.Name, Properties: t.Properties})
+		}
+	}
+
+	respondJSON(w, http.StatusOK, templates)
+}
+
+// handleCreateDatasetTemplate defines a new custom template.
+func (s *Server) handleCreateDatasetTemplate(w http.ResponseWriter, r *http.Request) {
+	if s.templates == nil {
+		http.Error(w, "custom templates are not enabled", http.StatusServiceUnavailable)
+		return
+	}
+
+	var req struct {
+		Name       string            `json:"name"`
+		Properties map[string]string `json:"properties"`
+	}
+	if !s.decodeJSON(w, r, &req) {
+		return
+	}
+	if err := zfs.ValidateTemplate(req.Name, req.Properties); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	existing, err := s.templates.Get(req.Name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if existing != nil {
+		http.Error(w, "template already exists", http.StatusConflict)
+		return
+	}
+
+	t := &store.DatasetTemplate{Name: req.Name, Properties: req.Properties}
+	if err := s.templates.Save(t); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, t)
+}
+
+// handleUpdateDatasetTemplate replaces the properties of a custom template.
+func (s *Server) handleUpdateDatasetTemplate(w http.ResponseWriter, r *http.Request) {
+	if s.templates == nil {
+		http.Error(w, "custom templates are not enabled", http.StatusServiceUnavailable)
+		return
+	}
+
+	name := r.PathValue("name")
+	var req struct {
+		Properties map[string]string `json:"properties"`
+	}
+	if !s.decodeJSON(w, r, &req) {
+		return
+	}
+	if err := zfs.ValidateTemplate(name, req.Properties); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	t := &store.DatasetTemplate{Name: name, Properties: req.Properties}
+	if err := s.templates.Update(t); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, "template not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, t)
+}
+
+// handleDeleteDatasetTemplate removes a custom template. Datasets created
+// from it keep their properties.
+func (s *Server) handleDeleteDatasetTemplate(w http.ResponseWriter, r *http.Request) {
+	if s.templates == nil {
+		http.Error(w, "custom templates are not enabled", http.StatusServiceUnavailable)
+		return
+	}
+
+	name := r.PathValue("name")
+	if zfs.IsBuiltinTemplate(zfs.UseCaseTemplate(name)) {
+		http.Error(w, "built-in templates cannot be deleted", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.templates.Delete(name); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,126 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/coder/websocket"
+	"go.aimuz.me/mynt/event"
+	"go.aimuz.me/mynt/logger"
+)
+
+const (
+	// wsPingInterval is how often an idle event WebSocket is pinged, so
+	// proxies keep it open and dead clients are noticed.
+	wsPingInterval = 30 * time.Second
+	// wsWriteTimeout bounds a single write or ping to a client.
+	wsWriteTimeout = 10 * time.Second
+)
+
+// wsMessage is a control message on an event WebSocket. Clients send
+// {"type": "subscribe", "patterns": [...]} to choose the events they
+// receive, using the patterns of event.Bus.Subscribe; no patterns means
+// all events. The server answers with the same message typed
+// "subscribed" once the patterns apply.
+type wsMessage struct {
+	Type     string   `json:"type"`
+	Patterns []string `json:"patterns"`
+}
+
+// handleEventsWS streams events over a WebSocket, for clients behind
+// proxies that buffer Server-Sent Events. Each event is sent as a text
+// message with the same JSON as handleEvents. A new subscribe message
+// replaces the previous patterns.
+func (s *Server) handleEventsWS(w http.ResponseWriter, r *http.Request) {
+	conn, err := websocket.Accept(w, r, nil)
+	if err != nil {
+		return // Accept has answered the request
+	}
+	defer conn.CloseNow()
+
+	ch := s.bus.Subscribe("*")
+	defer s.bus.Unsubscribe("*", ch)
+
+	// The reader ends the stream when the client goes away
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	subscriptions := make(chan []string, 1)
+	go func() {
+		defer cancel()
+		readSubscriptions(ctx, conn, subscriptions)
+	}()
+
+	ping := time.NewTicker(wsPingInterval)
+	defer ping.Stop()
+
+	patterns := []string{"*"}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case patterns = <-subscriptions:
+			if err := wsWrite(ctx, conn, wsMessage{Type: "subscribed", Patterns: patterns}); err != nil {
+				return
+			}
+		case <-ping.C:
+			pingCtx, pingCancel := context.WithTimeout(ctx, wsWriteTimeout)
+			err := conn.Ping(pingCtx)
+			pingCancel()
+			if err != nil {
+				return
+			}
+		case evt, ok := <-ch:
+			if !ok {
+				return
+			}
+			if !slices.ContainsFunc(patterns, func(p string) bool { return event.Match(p, evt.Type) }) {
+				continue
+			}
+			if err := wsWrite(ctx, conn, evt); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// readSubscriptions reads subscribe messages from conn and sends their
+// patterns to subscriptions, replacing any not yet applied. It returns
+// when the connection fails or closes, or after closing it for an
+// invalid message.
+func readSubscriptions(ctx context.Context, conn *websocket.Conn, subscriptions chan []string) {
+	for {
+		typ, data, err := conn.Read(ctx)
+		if err != nil {
+			return
+		}
+		var msg wsMessage
+		if typ != websocket.MessageText || json.Unmarshal(data, &msg) != nil || msg.Type != "subscribe" {
+			conn.Close(websocket.StatusUnsupportedData, "expected a subscribe message")
+			return
+		}
+		if len(msg.Patterns) == 0 {
+			msg.Patterns = []string{"*"}
+		}
+
+		select {
+		case <-subscriptions:
+		default:
+		}
+		subscriptions <- msg.Patterns
+	}
+}
+
+// wsWrite sends v as a JSON text message.
+func wsWrite(ctx context.Context, conn *websocket.Conn, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		logger.Warn("failed to encode WebSocket message", "error", err)
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, wsWriteTimeout)
+	defer cancel()
+	return conn.Write(ctx, websocket.MessageText, data)
+}
@@ -0,0 +1,51 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/stretchr/testify/require"
+	"go.aimuz.me/mynt/event"
+)
+
+func TestHandleEventsWS(t *testing.T) {
+	bus := event.NewBus()
+	s := &Server{bus: bus}
+	srv := httptest.NewServer(http.HandlerFunc(s.handleEventsWS))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, _, err := websocket.Dial(ctx, "ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	require.NoError(t, err)
+	defer conn.CloseNow()
+
+	read := func() map[string]any {
+		_, data, err := conn.Read(ctx)
+		require.NoError(t, err)
+		var msg map[string]any
+		require.NoError(t, json.Unmarshal(data, &msg))
+		return msg
+	}
+
+	// Narrow the stream to disk events and wait for the acknowledgement
+	require.NoError(t, conn.Write(ctx, websocket.MessageText, []byte(`{"type": "subscribe", "patterns": ["disk.*"]}`)))
+	require.Equal(t, map[string]any{"type": "subscribed", "patterns": []any{"disk.*"}}, read())
+
+	bus.Publish(event.Event{Type: event.PoolDegraded})
+	bus.Publish(event.Event{Type: event.DiskAdded, Data: "sdb"})
+	msg := read()
+	require.Equal(t, event.DiskAdded, msg["Type"])
+	require.Equal(t, "sdb", msg["Data"])
+
+	// An invalid message closes the connection
+	require.NoError(t, conn.Write(ctx, websocket.MessageText, []byte(`hello`)))
+	_, _, err = conn.Read(ctx)
+	require.Equal(t, websocket.StatusUnsupportedData, websocket.CloseStatus(err))
+}
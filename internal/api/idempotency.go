@@ -0,0 +1,71 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"go.aimuz.me/mynt/auth"
+	"go.aimuz.me/mynt/task"
+)
+
+// idempotencyKeyHeader lets clients retry task-creating requests, or
+// survive a double click, without starting the task twice. Scrub and disk
+// replacement do not take it: they answer synchronously, and ZFS already
+// refuses to start a scrub or replace a disk twice.
+const idempotencyKeyHeader = "Idempotency-Key"
+
+// idempotencyKey returns the request's Idempotency-Key scoped to the
+// signed-in user, so one user's key never replays another user's task.
+// It returns "" if the request has no key.
+func idempotencyKey(r *http.Request) string {
+	key := r.Header.Get(idempotencyKeyHeader)
+	if key == "" {
+		return ""
+	}
+	var userID int64
+	if claims := auth.GetUserClaims(r.Context()); claims != nil {
+		userID = claims.UserID
+	}
+	return strconv.FormatInt(userID, 10) + ":" + key
+}
+
+// replayTask answers a request whose Idempotency-Key already submitted
+// task name with that task. It reports whether a response was written;
+// handlers call it before validating the request.
+func (s *Server) replayTask(w http.ResponseWriter, r *http.Request, name string) bool {
+	op, err := s.tm.Replay(idempotencyKey(r), name)
+	if err != nil {
+		http.Error(w, err.Error(), idempotencyStatus(err))
+		return true
+	}
+	if op == nil {
+		return false
+	}
+	w.Header().Set("Idempotent-Replayed", "true")
+	respondJSON(w, http.StatusAccepted, op)
+	return true
+}
+
+// submitTask starts fn as a task and responds with the operation,
+// returning the existing one for a repeated Idempotency-Key.
+func (s *Server) submitTask(w http.ResponseWriter, r *http.Request, name string, fn func(ctx context.Context, update func(int)) (interface{}, error)) {
+	op, replayed, err := s.tm.SubmitIdempotent(idempotencyKey(r), name, fn)
+	if err != nil {
+		http.Error(w, err.Error(), idempotencyStatus(err))
+		return
+	}
+	if replayed {
+		w.Header().Set("Idempotent-Replayed", "true")
+	}
+	respondJSON(w, http.StatusAccepted, op)
+}
+
+// idempotencyStatus maps task submission errors to HTTP status codes.
+func idempotencyStatus(err error) int {
+	if errors.Is(err, task.ErrIdempotencyKeyReused) {
+		return http.StatusUnprocessableEntity
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,70 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.aimuz.me/mynt/auth"
+	"go.aimuz.me/mynt/task"
+)
+
+func TestSubmitTask_IdempotencyKey(t *testing.T) {
+	tm, err := task.New(nil)
+	require.NoError(t, err)
+	s := &Server{tm: tm}
+
+	var runs atomic.Int32
+	release := make(chan struct{})
+	fn := func(ctx context.Context, update func(int)) (interface{}, error) {
+		runs.Add(1)
+		<-release
+		return nil, nil
+	}
+
+	submit := func(key, name string) (*httptest.ResponseRecorder, task.Operation) {
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req.Header.Set(idempotencyKeyHeader, key)
+		rr := httptest.NewRecorder()
+		s.submitTask(rr, req, name, fn)
+		var op task.Operation
+		if rr.Code == http.StatusAccepted {
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &op))
+		}
+		return rr, op
+	}
+
+	rr, first := submit("abc", "send tank@a")
+	require.Equal(t, http.StatusAccepted, rr.Code)
+	require.Empty(t, rr.Header().Get("Idempotent-Replayed"))
+
+	rr, second := submit("abc", "send tank@a")
+	require.Equal(t, http.StatusAccepted, rr.Code)
+	require.Equal(t, "true", rr.Header().Get("Idempotent-Replayed"))
+	require.Equal(t, first.ID, second.ID)
+
+	// The replay check handlers run before validation
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	req.Header.Set(idempotencyKeyHeader, "abc")
+	rr = httptest.NewRecorder()
+	require.True(t, s.replayTask(rr, req, "send tank@a"))
+	require.Equal(t, http.StatusAccepted, rr.Code)
+
+	rr, _ = submit("abc", "receive tank/b")
+	require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+
+	// Keys are scoped to the user
+	req = httptest.NewRequest(http.MethodPost, "/", nil)
+	req.Header.Set(idempotencyKeyHeader, "abc")
+	req = req.WithContext(context.WithValue(req.Context(), auth.UserContextKey, &auth.Claims{UserID: 2}))
+	rr = httptest.NewRecorder()
+	require.False(t, s.replayTask(rr, req, "send tank@a"))
+
+	close(release)
+	tm.Close()
+	require.Equal(t, int32(1), runs.Load())
+}
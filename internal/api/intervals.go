@@ -0,0 +1,123 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// ScanIntervals are the effective background scan intervals of the server.
+type ScanIntervals struct {
+	Disk  time.Duration
+	Smart time.Duration
+	ZFS   time.Duration
+}
+
+// Bounds accepted when scan intervals are changed at runtime.
+const (
+	MinScanInterval = 5 * time.Second
+	MaxScanInterval = 24 * time.Hour
+)
+
+// WithScanIntervals advertises the server's scan intervals so clients can
+// align their polling with how fresh the data actually is.
+func WithScanIntervals(iv ScanIntervals) Option {
+	return func(s *Server) {
+		s.intervals = iv
+	}
+}
+
+// WithIntervalUpdates lets admins change the scan intervals at runtime.
+// apply is called with the requested intervals, which have already been
+// persisted, and returns the resulting effective intervals.
+func WithIntervalUpdates(apply func(ScanIntervals) ScanIntervals) Option {
+	return func(s *Server) {
+		s.applyIntervals = apply
+	}
+}
+
+// intervalsResponse reports intervals in seconds.
+type intervalsResponse struct {
+	Scan struct {
+		Disk  int `json:"disk"`
+		Smart int `json:"smart"`
+		ZFS   int `json:"zfs"`
+	} `json:"scan"`
+	// Poll holds recommended client poll intervals per resource. Polling
+	// faster than the matching scan only returns the same data again.
+	Poll struct {
+		Disks int `json:"disks"`
+		Smart int `json:"smart"`
+		Pools int `json:"pools"`
+	} `json:"poll"`
+}
+
+// handleGetIntervals reports scan intervals and recommended poll intervals.
+func (s *Server) handleGetIntervals(w http.ResponseWriter, r *http.Request) {
+	s.intervalsMu.RLock()
+	iv := s.intervals
+	s.intervalsMu.RUnlock()
+
+	respondJSON(w, http.StatusOK, newIntervalsResponse(iv))
+}
+
+// handleSetIntervals changes the disk, SMART and ZFS scan intervals, given
+// in seconds, without restarting the daemon. The values are persisted so
+// they also apply after a restart.
+func (s *Server) handleSetIntervals(w http.ResponseWriter, r *http.Request) {
+	if s.applyIntervals == nil {
+		http.Error(w, "scan intervals cannot be changed at runtime", http.StatusServiceUnavailable)
+		return
+	}
+
+	var req struct {
+		Disk  int `json:"disk"`
+		Smart int `json:"smart"`
+		ZFS   int `json:"zfs"`
+	}
+	if !s.decodeJSON(w, r, &req) {
+		return
+	}
+
+	iv := ScanIntervals{
+		Disk:  time.Duration(req.Disk) * time.Second,
+		Smart: time.Duration(req.Smart) * time.Second,
+		ZFS:   time.Duration(req.ZFS) * time.Second,
+	}
+	for _, d := range []time.Duration{iv.Disk, iv.Smart, iv.ZFS} {
+		if d < MinScanInterval || d > MaxScanInterval {
+			http.Error(w, fmt.Sprintf("intervals must be between %d and %d seconds", seconds(MinScanInterval), seconds(MaxScanInterval)), http.StatusBadRequest)
+			return
+		}
+	}
+
+	if err := s.config.SetScanIntervals(iv.Disk, iv.Smart, iv.ZFS); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.intervalsMu.Lock()
+	s.intervals = s.applyIntervals(iv)
+	effective := s.intervals
+	s.intervalsMu.Unlock()
+
+	respondJSON(w, http.StatusOK, newIntervalsResponse(effective))
+}
+
+// newIntervalsResponse converts effective scan intervals for clients.
+func newIntervalsResponse(iv ScanIntervals) intervalsResponse {
+	var resp intervalsResponse
+	resp.Scan.Disk = seconds(iv.Disk)
+	resp.Scan.Smart = seconds(iv.Smart)
+	resp.Scan.ZFS = seconds(iv.ZFS)
+
+	resp.Poll.Disks = resp.Scan.Disk
+	resp.Poll.Smart = resp.Scan.Smart
+	resp.Poll.Pools = resp.Scan.ZFS
+	return resp
+}
+
+// seconds rounds d up to whole seconds.
+func seconds(d time.Duration) int {
+	return int((d + time.Second - 1) / time.Second)
+}
@@ -0,0 +1,72 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.aimuz.me/mynt/store"
+)
+
+func TestHandleGetIntervals(t *testing.T) {
+	s := &Server{}
+	WithScanIntervals(ScanIntervals{
+		Disk:  30 * time.Second,
+		Smart: 5 * time.Minute,
+		ZFS:   30 * time.Second,
+	})(s)
+
+	rr := httptest.NewRecorder()
+	s.handleGetIntervals(rr, httptest.NewRequest(http.MethodGet, "/api/v1/config/intervals", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var resp intervalsResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+	require.Equal(t, 30, resp.Scan.Disk)
+	require.Equal(t, 300, resp.Scan.Smart)
+	require.Equal(t, 30, resp.Scan.ZFS)
+	require.Equal(t, 30, resp.Poll.Disks)
+	require.Equal(t, 300, resp.Poll.Smart)
+	require.Equal(t, 30, resp.Poll.Pools)
+}
+
+func TestHandleSetIntervals(t *testing.T) {
+	db, err := store.Open(":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	cfg := store.NewConfigRepo(db)
+
+	var applied ScanIntervals
+	s := &Server{config: cfg, maxBodyBytes: DefaultMaxBodyBytes}
+	WithIntervalUpdates(func(iv ScanIntervals) ScanIntervals {
+		applied = iv
+		return iv
+	})(s)
+
+	put := func(body string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		s.handleSetIntervals(rr, httptest.NewRequest(http.MethodPut, "/api/v1/config/intervals", strings.NewReader(body)))
+		return rr
+	}
+
+	rr := put(`{"disk": 60, "smart": 3600, "zfs": 10}`)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	require.Equal(t, ScanIntervals{Disk: time.Minute, Smart: time.Hour, ZFS: 10 * time.Second}, applied)
+
+	var resp intervalsResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+	require.Equal(t, 3600, resp.Scan.Smart)
+
+	disk, smart, zfs, err := cfg.ScanIntervals()
+	require.NoError(t, err)
+	require.Equal(t, []time.Duration{time.Minute, time.Hour, 10 * time.Second}, []time.Duration{disk, smart, zfs})
+
+	// Out-of-range values are rejected before anything changes
+	rr = put(`{"disk": 1, "smart": 3600, "zfs": 10}`)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	require.Equal(t, time.Minute, applied.Disk)
+}
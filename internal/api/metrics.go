@@ -0,0 +1,91 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.aimuz.me/mynt/store"
+)
+
+const (
+	// defaultMetricPoints is how many points a metric series has unless
+	// the request asks for another resolution.
+	defaultMetricPoints = 120
+	// maxMetricPoints caps the resolution of a metric series.
+	maxMetricPoints = 1000
+	// defaultMetricRange is the window returned when from is not given.
+	defaultMetricRange = time.Hour
+)
+
+// WithMetrics enables the metric history endpoint backed by repo. Without
+// it the endpoint answers 503.
+func WithMetrics(repo *store.MetricsRepo) Option {
+	return func(s *Server) {
+		s.metrics = repo
+	}
+}
+
+// handleMetrics returns the history of one metric between from and to
+// (RFC 3339, default the last hour), averaged server-side into at most
+// resolution points (default 120) whatever the length of the range.
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	if s.metrics == nil {
+		http.Error(w, "metrics history is not enabled", http.StatusServiceUnavailable)
+		return
+	}
+
+	q := r.URL.Query()
+	metric := q.Get("metric")
+	if !store.ValidMetric(metric) {
+		http.Error(w, "metric must be one of "+strings.Join(store.Metrics, ", "), http.StatusBadRequest)
+		return
+	}
+
+	to := time.Now()
+	if v := q.Get("to"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			http.Error(w, "invalid to: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		to = t
+	}
+	from := to.Add(-defaultMetricRange)
+	if v := q.Get("from"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			http.Error(w, "invalid from: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		from = t
+	}
+	if !from.Before(to) {
+		http.Error(w, "from must be before to", http.StatusBadRequest)
+		return
+	}
+
+	points := defaultMetricPoints
+	if v := q.Get("resolution"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxMetricPoints {
+			http.Error(w, fmt.Sprintf("resolution must be between 1 and %d", maxMetricPoints), http.StatusBadRequest)
+			return
+		}
+		points = n
+	}
+
+	series, err := s.metrics.Series(metric, from, to, points)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"metric": metric,
+		"from":   from,
+		"to":     to,
+		"points": series,
+	})
+}
@@ -0,0 +1,45 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.aimuz.me/mynt/store"
+)
+
+func TestHandleMetrics(t *testing.T) {
+	db, err := store.Open(":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	repo := store.NewMetricsRepo(db)
+	s := &Server{metrics: repo}
+
+	start := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	for i := range 24 * 60 {
+		require.NoError(t, repo.Add(start.Add(time.Duration(i)*time.Minute), map[string]float64{store.MetricCPU: 10}))
+	}
+
+	get := func(query string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		s.handleMetrics(rr, httptest.NewRequest(http.MethodGet, "/api/v1/metrics?"+query, nil))
+		return rr
+	}
+
+	require.Equal(t, http.StatusBadRequest, get("metric=gpu").Code)
+	require.Equal(t, http.StatusBadRequest, get("metric=cpu&resolution=0").Code)
+	require.Equal(t, http.StatusBadRequest, get("metric=cpu&from=2025-06-02T00:00:00Z&to=2025-06-01T00:00:00Z").Code)
+
+	// A day of minute samples comes back as the requested 48 points
+	rr := get("metric=cpu&from=2025-06-01T00:00:00Z&to=2025-06-02T00:00:00Z&resolution=48")
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var resp struct {
+		Points []store.MetricPoint `json:"points"`
+	}
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+	require.Len(t, resp.Points, 48)
+	require.InDelta(t, 10, resp.Points[0].Value, 1e-9)
+}
@@ -0,0 +1,158 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"go.aimuz.me/mynt/notify"
+	"go.aimuz.me/mynt/store"
+)
+
+// WithNotificationChannels enables managing notification channels stored
+// in repo. Without it the channel routes answer 503.
+func WithNotificationChannels(repo *store.NotificationChannelRepo) Option {
+	return func(s *Server) {
+		s.channels = repo
+	}
+}
+
+// WithChannelChanges sets a function called after a channel is created,
+// updated or deleted, such as notify.Dispatcher.InvalidateChannels.
+func WithChannelChanges(fn func()) Option {
+	return func(s *Server) {
+		s.onChannelChange = fn
+	}
+}
+
+// channelsChanged calls the onChannelChange callback if set.
+func (s *Server) channelsChanged() {
+	if s.onChannelChange != nil {
+		s.onChannelChange()
+	}
+}
+
+// channelsEnabled reports whether notification channels are configured,
+// answering 503 if not.
+func (s *Server) channelsEnabled(w http.ResponseWriter) bool {
+	if s.channels == nil {
+		http.Error(w, "notification channels are not enabled", http.StatusServiceUnavailable)
+		return false
+	}
+	return true
+}
+
+// channelID parses the {id} path value, answering 400 if it is invalid.
+func channelID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid channel ID", http.StatusBadRequest)
+		return 0, false
+	}
+	return id, true
+}
+
+func (s *Server) handleListNotificationChannels(w http.ResponseWriter, r *http.Request) {
+	if !s.channelsEnabled(w) {
+		return
+	}
+	channels, err := s.channels.List()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	respondJSON(w, http.StatusOK, channels)
+}
+
+func (s *Server) handleGetNotificationChannel(w http.ResponseWriter, r *http.Request) {
+	if !s.channelsEnabled(w) {
+		return
+	}
+	id, ok := channelID(w, r)
+	if !ok {
+		return
+	}
+
+	ch, err := s.channels.Get(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if ch == nil {
+		http.Error(w, "channel not found", http.StatusNotFound)
+		return
+	}
+	respondJSON(w, http.StatusOK, ch)
+}
+
+func (s *Server) handleCreateNotificationChannel(w http.ResponseWriter, r *http.Request) {
+	if !s.channelsEnabled(w) {
+		return
+	}
+
+	var ch store.NotificationChannel
+	if !s.decodeJSON(w, r, &ch) {
+		return
+	}
+	if err := notify.Validate(&ch); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := s.channels.Save(&ch); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	s.channelsChanged()
+	respondJSON(w, http.StatusCreated, ch)
+}
+
+// handleUpdateNotificationChannel replaces a channel with the request body.
+func (s *Server) handleUpdateNotificationChannel(w http.ResponseWriter, r *http.Request) {
+	if !s.channelsEnabled(w) {
+		return
+	}
+	id, ok := channelID(w, r)
+	if !ok {
+		return
+	}
+
+	var ch store.NotificationChannel
+	if !s.decodeJSON(w, r, &ch) {
+		return
+	}
+	ch.ID = id
+	if err := notify.Validate(&ch); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := s.channels.Update(&ch); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, "channel not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	s.channelsChanged()
+	respondJSON(w, http.StatusOK, ch)
+}
+
+func (s *Server) handleDeleteNotificationChannel(w http.ResponseWriter, r *http.Request) {
+	if !s.channelsEnabled(w) {
+		return
+	}
+	id, ok := channelID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := s.channels.Delete(id); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	s.channelsChanged()
+	w.WriteHeader(http.StatusNoContent)
+}
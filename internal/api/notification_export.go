@@ -0,0 +1,78 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"go.aimuz.me/mynt/logger"
+	"go.aimuz.me/mynt/store"
+)
+
+// exportFlushEvery is how many notifications are written between flushes
+// of an export stream.
+const exportFlushEvery = 100
+
+// handleExportNotifications streams notifications as newline-delimited
+// JSON, oldest first, for feeding external log pipelines. It accepts the
+// status filter of the list endpoint plus type, since and until (RFC 3339),
+// and has no limit.
+func (s *Server) handleExportNotifications(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseNotificationFilter(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Content-Disposition", `attachment; filename="notifications.jsonl"`)
+
+	flusher, _ := w.(http.Flusher)
+	enc := json.NewEncoder(w) // Encode terminates each object with a newline
+	written := 0
+	err = s.notification.Each(filter, func(n store.Notification) error {
+		if err := r.Context().Err(); err != nil {
+			return err
+		}
+		if err := enc.Encode(n); err != nil {
+			return err
+		}
+		written++
+		if flusher != nil && written%exportFlushEvery == 0 {
+			flusher.Flush()
+		}
+		return nil
+	})
+	if err != nil {
+		// The status line has already been sent; all we can do is stop.
+		logger.Warn("notification export aborted", "written", written, "error", err)
+	}
+}
+
+// parseNotificationFilter reads the export filters from query parameters.
+func parseNotificationFilter(q url.Values) (store.NotificationFilter, error) {
+	filter := store.NotificationFilter{
+		Status: store.NotificationStatus(q.Get("status")),
+		Type:   q.Get("type"),
+	}
+	for _, p := range []struct {
+		name string
+		dst  *time.Time
+	}{
+		{"since", &filter.Since},
+		{"until", &filter.Until},
+	} {
+		v := q.Get(p.name)
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return filter, fmt.Errorf("invalid %s: must be an RFC 3339 time", p.name)
+		}
+		*p.dst = t
+	}
+	return filter, nil
+}
@@ -0,0 +1,65 @@
+package api
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.aimuz.me/mynt/event"
+	"go.aimuz.me/mynt/store"
+)
+
+func TestHandleExportNotifications(t *testing.T) {
+	db, err := store.Open(":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	repo := store.NewNotificationRepo(db)
+
+	base := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	types := []string{"disk.added", "pool.degraded", "disk.added"}
+	for i, typ := range types {
+		require.NoError(t, repo.Save(event.Event{
+			Type: typ,
+			Time: base.Add(time.Duration(i) * time.Minute),
+			Data: map[string]int{"seq": i},
+		}))
+	}
+	s := &Server{notification: repo}
+
+	export := func(query string) []store.Notification {
+		rr := httptest.NewRecorder()
+		s.handleExportNotifications(rr, httptest.NewRequest(http.MethodGet, "/api/v1/notifications/export"+query, nil))
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		require.Equal(t, "application/x-ndjson", rr.Header().Get("Content-Type"))
+
+		var got []store.Notification
+		sc := bufio.NewScanner(rr.Body)
+		for sc.Scan() {
+			var n store.Notification
+			require.NoError(t, json.Unmarshal(sc.Bytes(), &n), "line %q", sc.Text())
+			got = append(got, n)
+		}
+		require.NoError(t, sc.Err())
+		return got
+	}
+
+	all := export("")
+	require.Len(t, all, len(types))
+	for i, n := range all {
+		require.Equal(t, types[i], n.Type)
+		require.JSONEq(t, fmt.Sprintf(`{"seq":%d}`, i), n.Data)
+	}
+
+	filtered := export("?type=disk.added&since=" + base.Add(time.Minute).Format(time.RFC3339))
+	require.Len(t, filtered, 1)
+	require.Equal(t, all[2].ID, filtered[0].ID)
+
+	rr := httptest.NewRecorder()
+	s.handleExportNotifications(rr, httptest.NewRequest(http.MethodGet, "/api/v1/notifications/export?since=yesterday", nil))
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+}
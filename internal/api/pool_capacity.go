@@ -0,0 +1,44 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// maxCapacityHistoryDays caps the window of the capacity history endpoint.
+const maxCapacityHistoryDays = 366
+
+// handlePoolCapacityHistory returns the capacity samples of a pool for the
+// last days days (default 30), oldest first, for projecting when the pool
+// fills up.
+func (s *Server) handlePoolCapacityHistory(w http.ResponseWriter, r *http.Request) {
+	if s.diskRepo == nil {
+		http.Error(w, "capacity history is not enabled", http.StatusServiceUnavailable)
+		return
+	}
+	poolName := r.PathValue("name")
+	if poolName == "" {
+		http.Error(w, "pool name required", http.StatusBadRequest)
+		return
+	}
+
+	days := 30
+	if v := r.URL.Query().Get("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxCapacityHistoryDays {
+			http.Error(w, fmt.Sprintf("days must be between 1 and %d", maxCapacityHistoryDays), http.StatusBadRequest)
+			return
+		}
+		days = n
+	}
+
+	since := time.Now().Add(-time.Duration(days) * 24 * time.Hour)
+	samples, err := s.diskRepo.PoolCapacityHistory(poolName, since)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	respondJSON(w, http.StatusOK, samples)
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"go.aimuz.me/mynt/logger"
+	"go.aimuz.me/mynt/store"
+	"go.aimuz.me/mynt/zfs"
+)
+
+// poolScanStatus is the scrub and resilver state of a pool.
+type poolScanStatus struct {
+	Pool       string              `json:"pool"`
+	Kind       string              `json:"kind,omitempty"` // "scrub" or "resilver" while one runs
+	InProgress bool                `json:"in_progress"`
+	StartedAt  *time.Time          `json:"started_at,omitempty"`
+	Scrub      *zfs.ScrubStatus    `json:"scrub,omitempty"`
+	Resilver   *zfs.ResilverStatus `json:"resilver,omitempty"`
+}
+
+// handlePoolScanStatus returns the live scrub and resilver status of a pool.
+// The start time comes from the scan tracked by the ZFS monitor, so it stays
+// put across pauses and daemon restarts. With format=raw it returns the
+// zpool status text instead.
+func (s *Server) handlePoolScanStatus(w http.ResponseWriter, r *http.Request) {
+	poolName := r.PathValue("name")
+	if poolName == "" {
+		http.Error(w, "pool name required", http.StatusBadRequest)
+		return
+	}
+
+	if r.URL.Query().Get("format") == "raw" {
+		text, err := s.zfs.PoolStatusText(r.Context(), poolName)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		io.WriteString(w, text)
+		return
+	}
+
+	scrub, resilver, err := s.zfs.ScanStatus(r.Context(), poolName)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	var tracked *store.PoolScan
+	if s.diskRepo != nil {
+		tracked, err = s.diskRepo.GetPoolScan(poolName)
+		if err != nil {
+			logger.Warn("failed to load pool scan", "pool", poolName, "error", err)
+		}
+	}
+	respondJSON(w, http.StatusOK, scanStatus(poolName, scrub, resilver, tracked))
+}
+
+// scanStatus combines the live scrub and resilver status of a pool with its
+// tracked scan. The tracked start time is only used while a scan of the
+// same kind runs.
+func scanStatus(pool string, scrub *zfs.ScrubStatus, resilver *zfs.ResilverStatus, tracked *store.PoolScan) poolScanStatus {
+	st := poolScanStatus{
+		Pool:     pool,
+		Scrub:    scrub,
+		Resilver: resilver,
+	}
+	var start int64
+	switch {
+	case resilver != nil && resilver.InProgress:
+		st.Kind, start = "resilver", resilver.StartTime
+	case scrub != nil && scrub.InProgress:
+		st.Kind, start = "scrub", scrub.StartTime
+	default:
+		return st
+	}
+	st.InProgress = true
+	if tracked != nil && tracked.Kind == st.Kind {
+		st.StartedAt = &tracked.StartedAt
+	} else if start > 0 {
+		t := time.Unix(start, 0)
+		st.StartedAt = &t
+	}
+	return st
+}
@@ -0,0 +1,85 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"go.aimuz.me/mynt/zfs"
+)
+
+// streamWriter defers the response header until the first write, so a
+// send that fails before producing output still gets an error status.
+type streamWriter struct {
+	w       http.ResponseWriter
+	written bool
+}
+
+func (sw *streamWriter) Write(p []byte) (int, error) {
+	if !sw.written {
+		sw.written = true
+		sw.w.Header().Set("Content-Type", "application/octet-stream")
+		sw.w.WriteHeader(http.StatusOK)
+	}
+	return sw.w.Write(p)
+}
+
+// handleSendStream streams a snapshot's send stream as the response body,
+// for replication to another host. The stream ends, and zfs send is
+// killed, when the client disconnects.
+func (s *Server) handleSendStream(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	name := q.Get("name")
+	if name == "" {
+		http.Error(w, "snapshot name required in query parameter", http.StatusBadRequest)
+		return
+	}
+	opts := zfs.SendOptions{From: q.Get("from")}
+	for key, dst := range map[string]*bool{"intermediate": &opts.Intermediate, "recursive": &opts.Recursive} {
+		if v := q.Get(key); v != "" {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				http.Error(w, "invalid "+key+" parameter", http.StatusBadRequest)
+				return
+			}
+			*dst = b
+		}
+	}
+
+	sw := &streamWriter{w: w}
+	if err := s.zfs.Send(r.Context(), name, sw, opts); err != nil {
+		if !sw.written {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		// Headers are out; abort so the client sees a truncated stream
+		// rather than a complete one.
+		panic(http.ErrAbortHandler)
+	}
+	if !sw.written {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// handleReceiveStream receives the send stream in the request body into
+// the target dataset. zfs receive is killed, discarding the partial
+// receive, if the client disconnects.
+func (s *Server) handleReceiveStream(w http.ResponseWriter, r *http.Request) {
+	target := r.URL.Query().Get("name")
+	if target == "" {
+		http.Error(w, "target dataset required in query parameter", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.zfs.Receive(r.Context(), target, r.Body); err != nil {
+		status := zfsMutationStatus(err)
+		if errors.Is(err, zfs.ErrReceiveTargetExists) {
+			status = http.StatusConflict
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
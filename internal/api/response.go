@@ -2,7 +2,10 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+
+	"go.aimuz.me/mynt/zfs"
 )
 
 // respondJSON sends a JSON response with the specified status code and data.
@@ -13,3 +16,33 @@ func respondJSON(w http.ResponseWriter, status int, data any) {
 		http.Error(w, "failed to encode response", http.StatusInternalServerError)
 	}
 }
+
+// decodeJSON decodes the request body into v, limited to the server's
+// maximum body size, or DefaultMaxBodyBytes if none is set. On failure it
+// writes a 400 or 413 response and returns false.
+func (s *Server) decodeJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	limit := s.maxBodyBytes
+	if limit <= 0 {
+		limit = DefaultMaxBodyBytes
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, limit)
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+			return false
+		}
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+// zfsMutationStatus returns the HTTP status for a failed pool, dataset or
+// snapshot change: 409 if the pool is imported read-only, 500 otherwise.
+func zfsMutationStatus(err error) int {
+	if errors.Is(err, zfs.ErrPoolReadOnly) {
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,47 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeJSON_BodyLimit(t *testing.T) {
+	tests := []struct {
+		name  string
+		limit int64
+		body  string
+		want  int
+	}{
+		{"within_limit", DefaultMaxBodyBytes, `{"schedule":"@daily","count":1}`, http.StatusOK},
+		{"oversized", DefaultMaxBodyBytes, `{"schedule":"` + strings.Repeat("a", 2<<20) + `"}`, http.StatusRequestEntityTooLarge},
+		{"custom_limit", 16, `{"schedule":"@daily","count":1}`, http.StatusRequestEntityTooLarge},
+		{"malformed", DefaultMaxBodyBytes, `{"schedule":`, http.StatusBadRequest},
+		{"zero_limit", 0, `{"schedule":"@daily","count":1}`, http.StatusOK},
+		{"negative_limit", -1, `{"schedule":"@daily","count":1}`, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Server{}
+			WithMaxBodyBytes(tt.limit)(s)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/snapshot-policies/preview", strings.NewReader(tt.body))
+			rr := httptest.NewRecorder()
+			s.handlePreviewSnapshotSchedule(rr, req)
+			require.Equal(t, tt.want, rr.Code, rr.Body.String())
+		})
+	}
+}
+
+func TestDecodeJSON_UnsetLimit(t *testing.T) {
+	s := &Server{}
+	body := `{"schedule":"` + strings.Repeat("a", 2<<20) + `"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/snapshot-policies/preview", strings.NewReader(body))
+	rr := httptest.NewRecorder()
+	s.handlePreviewSnapshotSchedule(rr, req)
+	require.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+}
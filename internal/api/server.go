@@ -1,19 +1,28 @@
 package api
 
 import (
+	"cmp"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"net/http"
 	"slices"
 	"strconv"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
+	"go.aimuz.me/mynt/alert"
 	"go.aimuz.me/mynt/auth"
 	"go.aimuz.me/mynt/disk"
 	"go.aimuz.me/mynt/event"
+	"go.aimuz.me/mynt/internal/version"
 	"go.aimuz.me/mynt/logger"
+	"go.aimuz.me/mynt/notify"
 	"go.aimuz.me/mynt/share"
 	"go.aimuz.me/mynt/store"
 	"go.aimuz.me/mynt/sysinfo"
@@ -39,11 +48,59 @@ type Server struct {
 	authMw         *auth.Middleware
 	mux            *http.ServeMux
 	onPolicyChange func()
+	reloadPolicies func() error // nil unless the snapshot scheduler is running
 	sysinfo        *sysinfo.Collector
+	confirms       *confirmStore // nil unless destructive actions need confirmation
+	intervals      ScanIntervals
+	anonymousRead  bool // serve protected GET routes without a token
+	disabled       map[Subsystem]bool
+	capabilities   sysinfo.Capabilities
+	maxBodyBytes   int64                          // limit on JSON request bodies
+	templates      *store.DatasetTemplateRepo     // nil unless custom dataset templates are enabled
+	channels       *store.NotificationChannelRepo // nil unless notification channels are enabled
+	smartPolicies  *store.SmartTestPolicyRepo     // nil unless SMART test scheduling is enabled
+	metrics        *store.MetricsRepo             // nil unless metric history is recorded
+	alertRules     *alert.Evaluator               // nil unless threshold alerts are evaluated
+	email          *notify.EmailSender            // nil unless SMTP settings can be changed
+	webhooks       *store.WebhookRepo             // nil unless signed webhooks are enabled
+
+	// intervalsMu guards intervals, which applyIntervals may change at
+	// runtime when set.
+	intervalsMu    sync.RWMutex
+	applyIntervals func(ScanIntervals) ScanIntervals
+
+	// onChannelChange is called after notification channels change, so
+	// cached channel lists are reloaded. nil if nothing caches them.
+	onChannelChange func()
+
+	// onWebhookChange is called after signed webhooks change, so cached
+	// webhook lists are reloaded. nil if nothing caches them.
+	onWebhookChange func()
+
+	// requiredConfirms holds the tokens of confirmRequired when confirms
+	// is nil, created on first use.
+	requiredConfirms     *confirmStore
+	requiredConfirmsOnce sync.Once
+}
+
+// DefaultMaxBodyBytes is the request body limit used unless overridden
+// with WithMaxBodyBytes.
+const DefaultMaxBodyBytes = 1 << 20
+
+// WithMaxBodyBytes sets the largest JSON request body handlers accept.
+// Larger bodies are rejected with 413 Request Entity Too Large. A limit
+// of zero or less keeps DefaultMaxBodyBytes.
+func WithMaxBodyBytes(n int64) Option {
+	return func(s *Server) {
+		if n <= 0 {
+			n = DefaultMaxBodyBytes
+		}
+		s.maxBodyBytes = n
+	}
 }
 
 // NewServer creates a new API server.
-func NewServer(zfs *zfs.Manager, diskMgr *disk.Manager, bus *event.Bus, tm *task.Manager, sm *share.Manager, um *user.Manager, cfg *store.ConfigRepo, notif *store.NotificationRepo, sp *store.SnapshotPolicyRepo, dr *store.DiskRepo, authCfg *auth.Config, onPolicyChange func()) *Server {
+func NewServer(zfs *zfs.Manager, diskMgr *disk.Manager, bus *event.Bus, tm *task.Manager, sm *share.Manager, um *user.Manager, cfg *store.ConfigRepo, notif *store.NotificationRepo, sp *store.SnapshotPolicyRepo, dr *store.DiskRepo, authCfg *auth.Config, onPolicyChange func(), opts ...Option) *Server {
 	s := &Server{
 		zfs:            zfs,
 		disk:           diskMgr,
@@ -56,10 +113,18 @@ func NewServer(zfs *zfs.Manager, diskMgr *disk.Manager, bus *event.Bus, tm *task
 		snapshotPolicy: sp,
 		diskRepo:       dr,
 		authConfig:     authCfg,
-		authMw:         auth.NewMiddleware(authCfg),
 		mux:            http.NewServeMux(),
 		onPolicyChange: onPolicyChange,
 		sysinfo:        sysinfo.NewCollector(),
+		maxBodyBytes:   DefaultMaxBodyBytes,
+	}
+	var authOpts []auth.MiddlewareOption
+	if um != nil {
+		authOpts = append(authOpts, auth.WithUserLookup(um.Get))
+	}
+	s.authMw = auth.NewMiddleware(authCfg, authOpts...)
+	for _, opt := range opts {
+		opt(s)
 	}
 	s.routes()
 	return s
@@ -75,45 +140,104 @@ func (s *Server) routes() {
 
 	// Public routes (no auth required)
 	s.mux.HandleFunc("POST /api/v1/auth/login", s.handleLogin)
+	s.mux.HandleFunc("GET /api/v1/version", s.handleVersion)
 
 	// Protected API routes - all require authentication
 	// Apply auth middleware to all /api/v1/ routes except auth
 	s.mux.HandleFunc("GET /api/v1/disks", s.protected(s.handleListDisks))
+	s.mux.HandleFunc("GET /api/v1/disks/available", s.protected(s.handleAvailableDisks))
 	s.mux.HandleFunc("GET /api/v1/disks/{name}/smart", s.protected(s.handleDiskSmartDetails))
 	s.mux.HandleFunc("POST /api/v1/disks/{name}/smart/refresh", s.protected(s.handleRefreshSmart))
 	s.mux.HandleFunc("POST /api/v1/disks/{name}/smart/test", s.protected(s.handleRunSmartTest))
 	s.mux.HandleFunc("GET /api/v1/disks/{name}/smart/test/status", s.protected(s.handleSmartTestStatus))
+	s.mux.HandleFunc("GET /api/v1/disks/{name}/smart/check", s.protected(s.handleSmartCheck))
+	s.mux.HandleFunc("PUT /api/v1/disks/{name}/smart/device-type", s.protected(s.handleSetSmartDeviceType))
 	s.mux.HandleFunc("POST /api/v1/disks/{name}/locate", s.protected(s.handleDiskLocate))
+	s.mux.HandleFunc("POST /api/v1/disks/{name}/wipe", s.adminOnly(s.handleWipeDisk))
+	s.mux.HandleFunc("POST /api/v1/disks/batch", s.protected(s.handleDiskBatch))
 
 	// Enhanced pool operations
 	s.mux.HandleFunc("GET /api/v1/pools", s.protected(s.handleListPools))
 	s.mux.HandleFunc("POST /api/v1/pools", s.protected(s.handleCreatePool))
+	s.mux.HandleFunc("GET /api/v1/pools/importable", s.protected(s.handleImportablePools))
+	s.mux.HandleFunc("POST /api/v1/pools/import", s.protected(s.handleImportPool))
 	s.mux.HandleFunc("GET /api/v1/pools/{name}", s.protected(s.handleGetPool))
+	s.mux.HandleFunc("DELETE /api/v1/pools/{name}", s.adminOnly(s.handleDestroyPool))
+	s.mux.HandleFunc("POST /api/v1/pools/{name}/export", s.adminOnly(s.handleExportPool))
+	s.mux.HandleFunc("GET /api/v1/pools/{name}/health", s.protected(s.handleGetPoolHealth))
+	s.mux.HandleFunc("GET /api/v1/pools/{name}/capacity/history", s.protected(s.handlePoolCapacityHistory))
 	s.mux.HandleFunc("POST /api/v1/pools/{name}/replace", s.protected(s.handleReplaceDisk))
+	s.mux.HandleFunc("POST /api/v1/pools/{name}/vdevs", s.adminOnly(s.handleAddVdev))
 	s.mux.HandleFunc("POST /api/v1/pools/{name}/scrub", s.protected(s.handlePoolScrub))
-
+	s.mux.HandleFunc("GET /api/v1/pools/{name}/scrub/status", s.protected(s.handlePoolScanStatus))
+	s.mux.HandleFunc("POST /api/v1/pools/{name}/trim", s.protected(s.handlePoolTrim))
+	s.mux.HandleFunc("GET /api/v1/pools/{name}/trim/status", s.protected(s.handlePoolTrimStatus))
+	s.mux.HandleFunc("PUT /api/v1/pools/{name}/autotrim", s.adminOnly(s.handleSetPoolAutotrim))
+	s.mux.HandleFunc("GET /api/v1/pools/{name}/properties", s.protected(s.handleGetPoolProperties))
+	s.mux.HandleFunc("PUT /api/v1/pools/{name}/properties", s.adminOnly(s.handleSetPoolProperty))
+
+	s.mux.HandleFunc("GET /api/v1/zfs/compression-options", s.protected(s.handleCompressionOptions))
+	s.mux.HandleFunc("POST /api/v1/zfs/exec", s.adminOnly(s.handleZFSExec))
+	s.mux.HandleFunc("GET /api/v1/zfs/params", s.protected(s.handleZFSParams))
+	s.mux.HandleFunc("GET /api/v1/zfs/arc", s.protected(s.handleARCStats))
+	s.mux.HandleFunc("PUT /api/v1/zfs/params", s.adminOnly(s.handleSetZFSParam))
+	s.mux.HandleFunc("GET /api/v1/zfs/templates", s.protected(s.handleListDatasetTemplates))
+	s.mux.HandleFunc("POST /api/v1/zfs/templates", s.adminOnly(s.handleCreateDatasetTemplate))
+	s.mux.HandleFunc("PUT /api/v1/zfs/templates/{name}", s.adminOnly(s.handleUpdateDatasetTemplate))
+	s.mux.HandleFunc("DELETE /api/v1/zfs/templates/{name}", s.adminOnly(s.handleDeleteDatasetTemplate))
 	s.mux.HandleFunc("GET /api/v1/datasets", s.protected(s.handleListDatasets))
 	s.mux.HandleFunc("POST /api/v1/datasets", s.protected(s.handleCreateDataset))
 	s.mux.HandleFunc("GET /api/v1/datasets/{name...}", s.protected(s.handleGetDataset))
 	s.mux.HandleFunc("DELETE /api/v1/datasets/{name...}", s.protected(s.handleDestroyDataset))
 	s.mux.HandleFunc("PUT /api/v1/datasets/quota", s.protected(s.handleSetDatasetQuota))
+	s.mux.HandleFunc("PUT /api/v1/datasets/refquota", s.protected(s.handleSetDatasetRefQuota))
+	s.mux.HandleFunc("PUT /api/v1/datasets/reservation", s.protected(s.handleSetDatasetReservation))
+	s.mux.HandleFunc("POST /api/v1/datasets/compression", s.protected(s.handleSetDatasetCompression))
+	s.mux.HandleFunc("PUT /api/v1/datasets/properties", s.protected(s.handleSetDatasetProperties))
+	s.mux.HandleFunc("PUT /api/v1/datasets/note", s.protected(s.handleSetDatasetNote))
+	s.mux.HandleFunc("POST /api/v1/datasets/receive", s.adminOnly(s.handleReceiveDataset))
+	s.mux.HandleFunc("POST /api/v1/datasets/send", s.adminOnly(s.handleSendStream))
+	s.mux.HandleFunc("POST /api/v1/datasets/receive-stream", s.adminOnly(s.handleReceiveStream))
+	s.mux.HandleFunc("PUT /api/v1/datasets/acl", s.adminOnly(s.handleSetDatasetACL))
+	// Reads naming the dataset in ?name= live under dataset-info, where
+	// GET /datasets/{name...} cannot shadow them or a pool of the same name.
+	s.mux.HandleFunc("GET /api/v1/dataset-info/properties", s.protected(s.handleGetDatasetProperties))
+	s.mux.HandleFunc("GET /api/v1/dataset-info/note", s.protected(s.handleGetDatasetNote))
+	s.mux.HandleFunc("GET /api/v1/dataset-info/changes", s.protected(s.handleDatasetChanges))
+	s.mux.HandleFunc("GET /api/v1/dataset-info/acl", s.protected(s.handleGetDatasetACL))
+	s.mux.HandleFunc("GET /api/v1/dataset-info/template-drift", s.protected(s.handleTemplateDrift))
+	s.mux.HandleFunc("GET /api/v1/dataset-info/policies", s.protected(s.handleDatasetPolicies))
 
 	// Snapshot endpoints
 	s.mux.HandleFunc("GET /api/v1/snapshots", s.protected(s.handleListSnapshots))
 	s.mux.HandleFunc("POST /api/v1/snapshots", s.protected(s.handleCreateSnapshot))
 	s.mux.HandleFunc("DELETE /api/v1/snapshots/{name...}", s.protected(s.handleDestroySnapshot))
+	s.mux.HandleFunc("GET /api/v1/snapshots/diff", s.protected(s.handleSnapshotDiff))
 	s.mux.HandleFunc("POST /api/v1/snapshots/rollback", s.protected(s.handleRollbackSnapshot))
+	s.mux.HandleFunc("POST /api/v1/snapshots/rename", s.protected(s.handleRenameSnapshot))
+	s.mux.HandleFunc("POST /api/v1/snapshots/send-to-file", s.adminOnly(s.handleSendSnapshotToFile))
 
 	// Snapshot Policy endpoints
 	s.mux.HandleFunc("GET /api/v1/snapshot-policies", s.protected(s.handleListSnapshotPolicies))
 	s.mux.HandleFunc("POST /api/v1/snapshot-policies", s.protected(s.handleCreateSnapshotPolicy))
+	s.mux.HandleFunc("POST /api/v1/snapshot-policies/preview", s.protected(s.handlePreviewSnapshotSchedule))
+	s.mux.HandleFunc("POST /api/v1/snapshot-policies/reload", s.adminOnly(s.handleReloadSnapshotPolicies))
 	s.mux.HandleFunc("PUT /api/v1/snapshot-policies/{id}", s.protected(s.handleUpdateSnapshotPolicy))
+	s.mux.HandleFunc("PATCH /api/v1/snapshot-policies/{id}", s.protected(s.handleUpdateSnapshotPolicy))
 	s.mux.HandleFunc("DELETE /api/v1/snapshot-policies/{id}", s.protected(s.handleDeleteSnapshotPolicy))
 
+	// SMART self-test policies
+	s.mux.HandleFunc("GET /api/v1/smart-policies", s.protected(s.handleListSmartTestPolicies))
+	s.mux.HandleFunc("POST /api/v1/smart-policies", s.adminOnly(s.handleCreateSmartTestPolicy))
+	s.mux.HandleFunc("PUT /api/v1/smart-policies/{id}", s.adminOnly(s.handleUpdateSmartTestPolicy))
+	s.mux.HandleFunc("DELETE /api/v1/smart-policies/{id}", s.adminOnly(s.handleDeleteSmartTestPolicy))
+
 	// Shares
 	s.mux.HandleFunc("GET /api/v1/shares", s.protected(s.handleListShares))
 	s.mux.HandleFunc("POST /api/v1/shares", s.protected(s.handleCreateShare))
 	s.mux.HandleFunc("DELETE /api/v1/shares/{id}", s.protected(s.handleDeleteShare))
+	s.mux.HandleFunc("GET /api/v1/shares/config/preview", s.adminOnly(s.handlePreviewShareConfig))
+	s.mux.HandleFunc("POST /api/v1/shares/reload", s.adminOnly(s.handleReloadShares))
 
 	// Users (admin only for create/delete)
 	s.mux.HandleFunc("GET /api/v1/users", s.protected(s.handleListUsers))
@@ -121,24 +245,65 @@ func (s *Server) routes() {
 	s.mux.HandleFunc("DELETE /api/v1/users/{username}", s.adminOnly(s.handleDeleteUser))
 
 	// Notifications
+	s.mux.HandleFunc("GET /api/v1/alerts", s.protected(s.handleListAlerts))
 	s.mux.HandleFunc("GET /api/v1/notifications", s.protected(s.handleListNotifications))
 	s.mux.HandleFunc("POST /api/v1/notifications/{id}/read", s.protected(s.handleMarkRead))
 	s.mux.HandleFunc("POST /api/v1/notifications/{id}/ack", s.protected(s.handleMarkAcknowledged))
+	s.mux.HandleFunc("POST /api/v1/notifications/ack", s.protected(s.handleBulkAcknowledge))
 	s.mux.HandleFunc("DELETE /api/v1/notifications/{id}", s.protected(s.handleDeleteNotification))
 	s.mux.HandleFunc("GET /api/v1/notifications/count", s.protected(s.handleCountNotifications))
-
-	// Real-time events - SSE
+	s.mux.HandleFunc("GET /api/v1/notifications/export", s.protected(s.handleExportNotifications))
+	s.mux.HandleFunc("GET /api/v1/notification-channels", s.adminOnly(s.handleListNotificationChannels))
+	s.mux.HandleFunc("POST /api/v1/notification-channels", s.adminOnly(s.handleCreateNotificationChannel))
+	s.mux.HandleFunc("GET /api/v1/notification-channels/{id}", s.adminOnly(s.handleGetNotificationChannel))
+	s.mux.HandleFunc("PUT /api/v1/notification-channels/{id}", s.adminOnly(s.handleUpdateNotificationChannel))
+	s.mux.HandleFunc("DELETE /api/v1/notification-channels/{id}", s.adminOnly(s.handleDeleteNotificationChannel))
+	s.mux.HandleFunc("GET /api/v1/webhooks", s.adminOnly(s.handleListWebhooks))
+	s.mux.HandleFunc("POST /api/v1/webhooks", s.adminOnly(s.handleCreateWebhook))
+	s.mux.HandleFunc("GET /api/v1/webhooks/{id}", s.adminOnly(s.handleGetWebhook))
+	s.mux.HandleFunc("PUT /api/v1/webhooks/{id}", s.adminOnly(s.handleUpdateWebhook))
+	s.mux.HandleFunc("DELETE /api/v1/webhooks/{id}", s.adminOnly(s.handleDeleteWebhook))
+	s.mux.HandleFunc("GET /api/v1/webhooks/{id}/deliveries", s.adminOnly(s.handleListWebhookDeliveries))
+
+	// Real-time events - SSE, or WebSocket for proxies that buffer SSE
 	s.mux.HandleFunc("GET /api/v1/events", s.protected(s.handleEvents))
+	s.mux.HandleFunc("GET /api/v1/events/ws", s.protected(s.handleEventsWS))
 
 	// System monitoring
 	s.mux.HandleFunc("GET /api/v1/system/stats", s.protected(s.handleSystemStats))
+	s.mux.HandleFunc("GET /api/v1/metrics", s.protected(s.handleMetrics))
+	s.mux.HandleFunc("GET /api/v1/config/intervals", s.protected(s.handleGetIntervals))
+	s.mux.HandleFunc("PUT /api/v1/config/intervals", s.adminOnly(s.handleSetIntervals))
+	s.mux.HandleFunc("GET /api/v1/config/alert-rules", s.protected(s.handleGetAlertRules))
+	s.mux.HandleFunc("PUT /api/v1/config/alert-rules", s.adminOnly(s.handleSetAlertRules))
+	s.mux.HandleFunc("GET /api/v1/config/smtp", s.adminOnly(s.handleGetSMTPConfig))
+	s.mux.HandleFunc("PUT /api/v1/config/smtp", s.adminOnly(s.handleSetSMTPConfig))
+	s.mux.HandleFunc("GET /api/v1/config/validate", s.adminOnly(s.handleValidateConfig))
+	s.mux.HandleFunc("GET /api/v1/capabilities", s.protected(s.handleCapabilities))
+	s.mux.HandleFunc("GET /api/v1/system/resources", s.protected(s.handleSystemResources))
+	s.mux.HandleFunc("GET /api/v1/system/swap", s.protected(s.handleSystemSwap))
 	s.mux.HandleFunc("GET /api/v1/system/processes", s.protected(s.handleListProcesses))
+	s.mux.HandleFunc("GET /api/v1/system/processes/{pid}", s.adminOnly(s.handleProcessDetail))
 	s.mux.HandleFunc("POST /api/v1/system/processes/{pid}/signal", s.adminOnly(s.handleSignalProcess))
 }
 
-// protected wraps a handler with authentication requirement.
+// WithAnonymousRead lets unauthenticated clients use the GET routes that
+// otherwise only require a login, for dashboards on a trusted network.
+// Mutations and admin-only routes still require a token.
+func WithAnonymousRead() Option {
+	return func(s *Server) {
+		s.anonymousRead = true
+	}
+}
+
+// protected wraps a handler with authentication requirement. With
+// anonymous read enabled, GET and HEAD requests pass without a token.
 func (s *Server) protected(handler http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		if s.anonymousRead && (r.Method == http.MethodGet || r.Method == http.MethodHead) {
+			s.authMw.OptionalAuth(handler).ServeHTTP(w, r)
+			return
+		}
 		s.authMw.RequireAuth(handler).ServeHTTP(w, r)
 	}
 }
@@ -152,11 +317,20 @@ func (s *Server) adminOnly(handler http.HandlerFunc) http.HandlerFunc {
 
 // ServeHTTP implements the http.Handler interface.
 func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if sub, ok := s.disabledSubsystem(r.URL.Path); ok {
+		notImplemented(w, sub)
+		return
+	}
 	s.mux.ServeHTTP(w, r)
 }
 
 // Setup handlers
 
+// handleVersion returns the build information of the daemon.
+func (s *Server) handleVersion(w http.ResponseWriter, r *http.Request) {
+	respondJSON(w, http.StatusOK, version.Get())
+}
+
 func (s *Server) handleSetupStatus(w http.ResponseWriter, r *http.Request) {
 	initialized, err := s.config.IsInitialized()
 	if err != nil {
@@ -184,8 +358,7 @@ func (s *Server) handleSetup(w http.ResponseWriter, r *http.Request) {
 
 	// Parse request
 	var req user.CreateRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid request body", http.StatusBadRequest)
+	if !s.decodeJSON(w, r, &req) {
 		return
 	}
 
@@ -229,8 +402,7 @@ func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
 		Password string `json:"password"`
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid request body", http.StatusBadRequest)
+	if !s.decodeJSON(w, r, &req) {
 		return
 	}
 
@@ -267,6 +439,20 @@ func (s *Server) handleListDisks(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, http.StatusOK, disks)
 }
 
+// handleAvailableDisks returns the disks that a new pool can be built on.
+// Formatted and partitioned disks are listed only with include_used=true.
+func (s *Server) handleAvailableDisks(w http.ResponseWriter, r *http.Request) {
+	includeUsed := r.URL.Query().Get("include_used") == "true"
+
+	disks, err := s.disk.ListBasic(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, disk.Available(disks, includeUsed))
+}
+
 // handleDiskSmartDetails returns cached SMART data for a disk.
 func (s *Server) handleDiskSmartDetails(w http.ResponseWriter, r *http.Request) {
 	name := r.PathValue("name")
@@ -275,8 +461,14 @@ func (s *Server) handleDiskSmartDetails(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// Try cache first
-	if s.diskRepo != nil {
+	opts, err := smartOptions(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Try cache first (a one-off device type always queries live)
+	if s.diskRepo != nil && len(opts) == 0 {
 		cached, err := s.diskRepo.GetSmart(name)
 		if err == nil && cached != nil {
 			// Return cached data as DetailedReport format
@@ -291,14 +483,19 @@ func (s *Server) handleDiskSmartDetails(w http.ResponseWriter, r *http.Request)
 				PendingSectors:      cached.PendingSectors,
 				UncorrectableErrors: cached.UncorrectableErrors,
 				Temperature:         cached.Temperature,
+				PercentageUsed:      cached.PercentageUsed,
+				WearLevelingCount:   cached.WearLevelingCount,
+				DataUnitsWritten:    cached.DataUnitsWritten,
+				TotalLBAsWritten:    cached.TotalLBAsWritten,
 			}
+			report.WearWarning = report.WearExceeded()
 			respondJSON(w, http.StatusOK, report)
 			return
 		}
 	}
 
 	// Cache miss - fall back to live query
-	report, err := s.disk.SmartDetails(r.Context(), name)
+	report, err := s.disk.SmartDetails(r.Context(), name, opts...)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -315,8 +512,14 @@ func (s *Server) handleRefreshSmart(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	opts, err := smartOptions(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	// Fetch fresh SMART data (bypasses cache)
-	report, err := s.disk.SmartDetails(r.Context(), name)
+	report, err := s.disk.SmartDetails(r.Context(), name, opts...)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -343,8 +546,13 @@ func (s *Server) handleRunSmartTest(w http.ResponseWriter, r *http.Request) {
 	var req struct {
 		Type string `json:"type"`
 	}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid request body", http.StatusBadRequest)
+	if !s.decodeJSON(w, r, &req) {
+		return
+	}
+
+	opts, err := smartOptions(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
@@ -353,7 +561,7 @@ func (s *Server) handleRunSmartTest(w http.ResponseWriter, r *http.Request) {
 		typ = disk.TestLong
 	}
 
-	if err := s.disk.SmartTest(r.Context(), name, typ); err != nil {
+	if err := s.disk.SmartTest(r.Context(), name, typ, opts...); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -369,7 +577,13 @@ func (s *Server) handleSmartTestStatus(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	status, err := s.disk.SmartTestStatus(r.Context(), name)
+	opts, err := smartOptions(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	status, err := s.disk.SmartTestStatus(r.Context(), name, opts...)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -378,6 +592,79 @@ func (s *Server) handleSmartTestStatus(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, http.StatusOK, status)
 }
 
+// handleSmartCheck reports whether smartctl can read a disk and whether a
+// device type override is likely needed. The device_type query parameter
+// tries a type before storing it.
+func (s *Server) handleSmartCheck(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if name == "" {
+		http.Error(w, "disk name required", http.StatusBadRequest)
+		return
+	}
+
+	opts, err := smartOptions(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	check, err := s.disk.CheckSmart(r.Context(), name, opts...)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, check)
+}
+
+// handleSetSmartDeviceType stores a persistent smartctl device type override
+// for a disk. An empty device_type clears the override.
+func (s *Server) handleSetSmartDeviceType(w http.ResponseWriter, r *http.Request) {
+	if s.diskRepo == nil {
+		http.Error(w, "SMART device type overrides are not enabled", http.StatusServiceUnavailable)
+		return
+	}
+	name := r.PathValue("name")
+	if name == "" {
+		http.Error(w, "disk name required", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		DeviceType string `json:"device_type"`
+	}
+	if !s.decodeJSON(w, r, &req) {
+		return
+	}
+
+	if req.DeviceType != "" {
+		if err := disk.ValidateDeviceType(req.DeviceType); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	if err := s.diskRepo.SetSmartDeviceType(name, req.DeviceType); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// smartOptions builds per-request SMART options from the query string.
+// The optional device_type parameter overrides the smartctl -d flag.
+func smartOptions(r *http.Request) ([]disk.SmartOption, error) {
+	typ := r.URL.Query().Get("device_type")
+	if typ == "" {
+		return nil, nil
+	}
+	if err := disk.ValidateDeviceType(typ); err != nil {
+		return nil, err
+	}
+	return []disk.SmartOption{disk.DeviceType(typ)}, nil
+}
+
 // handleDiskLocate toggles the locate LED on a disk.
 func (s *Server) handleDiskLocate(w http.ResponseWriter, r *http.Request) {
 	name := r.PathValue("name")
@@ -389,8 +676,7 @@ func (s *Server) handleDiskLocate(w http.ResponseWriter, r *http.Request) {
 	var req struct {
 		Action string `json:"action"` // "on" or "off"
 	}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid request body", http.StatusBadRequest)
+	if !s.decodeJSON(w, r, &req) {
 		return
 	}
 
@@ -409,6 +695,143 @@ func (s *Server) handleDiskLocate(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 }
 
+// handleWipeDisk starts a task that clears a disk for reuse. The system
+// disk is always refused, and a member of an imported pool unless force is
+// set.
+func (s *Server) handleWipeDisk(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if name == "" {
+		http.Error(w, "disk name required", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		Mode  disk.WipeMode `json:"mode"` // "label" (default) or "quick"
+		Force bool          `json:"force"`
+	}
+	if !s.decodeJSON(w, r, &req) {
+		return
+	}
+	if req.Mode == "" {
+		req.Mode = disk.WipeLabel
+	}
+	if req.Mode != disk.WipeLabel && req.Mode != disk.WipeQuick {
+		http.Error(w, "mode must be label or quick", http.StatusBadRequest)
+		return
+	}
+
+	if s.replayTask(w, r, "wipe "+name) {
+		return
+	}
+
+	disks, err := s.disk.ListBasic(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	i := slices.IndexFunc(disks, func(d disk.Info) bool { return d.Name == name })
+	if i < 0 {
+		http.Error(w, "disk not found", http.StatusNotFound)
+		return
+	}
+	d := disks[i]
+	if d.Usage != nil && d.Usage.Type == disk.UsageTypeSystem {
+		http.Error(w, "refusing to wipe the system disk", http.StatusConflict)
+		return
+	}
+	if d.Pool != "" && !req.Force {
+		pools, err := s.zfs.ListPools(r.Context())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if slices.ContainsFunc(pools, func(p zfs.Pool) bool { return p.Name == d.Pool }) {
+			http.Error(w, fmt.Sprintf("disk %s is in use by imported pool %s", name, d.Pool), http.StatusConflict)
+			return
+		}
+	}
+
+	impact := func(context.Context) (string, error) {
+		return fmt.Sprintf("everything on %s (%s, serial %s) will be lost", d.Path, d.Model, d.Serial), nil
+	}
+	if !s.confirm(w, r, "wipe disk "+name, impact) {
+		return
+	}
+
+	s.submitTask(w, r, "wipe "+name, func(ctx context.Context, update func(int)) (interface{}, error) {
+		progress := disk.WipeProgress(func(done, total int) {
+			update(min(99, done*100/total))
+		})
+		return nil, s.disk.Wipe(ctx, name, req.Mode, progress)
+	})
+}
+
+// handleDiskBatch applies one action to several disks concurrently and
+// reports the outcome per disk.
+func (s *Server) handleDiskBatch(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Disks  []string        `json:"disks"`
+		Action string          `json:"action"` // "smart_test", "standby" or "locate"
+		Params json.RawMessage `json:"params"`
+	}
+	if !s.decodeJSON(w, r, &req) {
+		return
+	}
+	if len(req.Disks) == 0 {
+		http.Error(w, "disks required", http.StatusBadRequest)
+		return
+	}
+
+	op, err := s.diskBatchOp(req.Action, req.Params)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, disk.Batch(r.Context(), req.Disks, op))
+}
+
+// diskBatchOp maps a batch action and its parameters onto the matching
+// single-disk operation.
+func (s *Server) diskBatchOp(action string, raw json.RawMessage) (func(ctx context.Context, name string) error, error) {
+	var params struct {
+		Type    string `json:"type"`    // smart_test: "short" or "long"
+		Timeout int    `json:"timeout"` // standby: minutes, 0 disables
+		Action  string `json:"action"`  // locate: "on" or "off"
+	}
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &params); err != nil {
+			return nil, errors.New("invalid params")
+		}
+	}
+
+	switch action {
+	case "smart_test":
+		typ := disk.TestShort
+		if params.Type == "long" {
+			typ = disk.TestLong
+		}
+		return func(ctx context.Context, name string) error {
+			return s.disk.SmartTest(ctx, name, typ)
+		}, nil
+	case "standby":
+		timeout := time.Duration(params.Timeout) * time.Minute
+		if params.Timeout < 0 || timeout > disk.MaxStandbyTimeout {
+			return nil, fmt.Errorf("standby timeout must be between 0 and %d minutes", int(disk.MaxStandbyTimeout.Minutes()))
+		}
+		return func(ctx context.Context, name string) error {
+			return s.disk.SetStandby(ctx, name, timeout)
+		}, nil
+	case "locate":
+		if params.Action == "off" {
+			return s.disk.LocateOff, nil
+		}
+		return s.disk.Locate, nil
+	default:
+		return nil, fmt.Errorf("unknown action: %s", action)
+	}
+}
+
 func (s *Server) handleListPools(w http.ResponseWriter, r *http.Request) {
 	pools, err := s.zfs.ListPools(r.Context())
 	if err != nil {
@@ -421,8 +844,7 @@ func (s *Server) handleListPools(w http.ResponseWriter, r *http.Request) {
 
 func (s *Server) handleCreatePool(w http.ResponseWriter, r *http.Request) {
 	var req zfs.CreatePoolRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid request body", http.StatusBadRequest)
+	if !s.decodeJSON(w, r, &req) {
 		return
 	}
 
@@ -432,13 +854,102 @@ func (s *Server) handleCreatePool(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := s.zfs.CreatePool(r.Context(), req); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		status := http.StatusInternalServerError
+		if errors.Is(err, zfs.ErrInvalidPool) {
+			status = http.StatusBadRequest
+		}
+		http.Error(w, err.Error(), status)
 		return
 	}
 
 	w.WriteHeader(http.StatusCreated)
 }
 
+// handleCompressionOptions lists the compression algorithms supported by
+// the running ZFS.
+func (s *Server) handleCompressionOptions(w http.ResponseWriter, r *http.Request) {
+	respondJSON(w, http.StatusOK, s.zfs.CompressionOptions(r.Context()))
+}
+
+// handleZFSParams returns the ZFS kernel module parameters and which of
+// them can be set.
+func (s *Server) handleZFSParams(w http.ResponseWriter, r *http.Request) {
+	params, err := s.zfs.ZFSParams(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	respondJSON(w, http.StatusOK, params)
+}
+
+// handleARCStats returns the ARC size and hit ratio.
+func (s *Server) handleARCStats(w http.ResponseWriter, r *http.Request) {
+	stats, err := s.zfs.ARCStats(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	respondJSON(w, http.StatusOK, stats)
+}
+
+// handleSetZFSParam changes an allowlisted ZFS module parameter until the
+// next reboot.
+func (s *Server) handleSetZFSParam(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	}
+	if !s.decodeJSON(w, r, &req) {
+		return
+	}
+	if req.Name == "" || req.Value == "" {
+		http.Error(w, "name and value are required", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.zfs.SetZFSParam(r.Context(), req.Name, req.Value); err != nil {
+		status := http.StatusBadRequest
+		switch {
+		case errors.Is(err, zfs.ErrParamNotWritable):
+			status = http.StatusForbidden
+		case errors.Is(err, fs.ErrNotExist):
+			status = http.StatusNotFound // not present in this module version
+		case errors.Is(err, fs.ErrPermission):
+			status = http.StatusInternalServerError
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleZFSExec runs an allowlisted zfs or zpool subcommand for operations
+// mynt does not wrap yet.
+func (s *Server) handleZFSExec(w http.ResponseWriter, r *http.Request) {
+	var req zfs.ExecRequest
+	if !s.decodeJSON(w, r, &req) {
+		return
+	}
+
+	user := ""
+	if claims := auth.GetUserClaims(r.Context()); claims != nil {
+		user = claims.Username
+	}
+	logger.Warn("zfs passthrough", "user", user, "command", req.Command, "args", req.Args)
+
+	res, err := s.zfs.Exec(r.Context(), req)
+	if err != nil {
+		if errors.Is(err, zfs.ErrExecNotAllowed) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, res)
+}
+
 func (s *Server) handleListDatasets(w http.ResponseWriter, r *http.Request) {
 	datasets, err := s.zfs.ListDatasets(r.Context())
 	if err != nil {
@@ -446,18 +957,43 @@ func (s *Server) handleListDatasets(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Shares are loaded once per listing; a failure only hides share icons.
+	if shares, err := s.share.ListShares(""); err != nil {
+		logger.Warn("failed to list shares for datasets", "error", err)
+	} else {
+		markShared(datasets, shares)
+	}
+
 	respondJSON(w, http.StatusOK, datasets)
 }
 
 func (s *Server) handleCreateDataset(w http.ResponseWriter, r *http.Request) {
 	var req zfs.CreateDatasetRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid request body", http.StatusBadRequest)
+	if !s.decodeJSON(w, r, &req) {
 		return
 	}
 
+	// Only admins may mount a dataset outside its pool
+	if claims := auth.GetUserClaims(r.Context()); claims == nil || !claims.IsAdmin {
+		if err := s.zfs.CheckMountpointInPool(req); err != nil {
+			status := http.StatusInternalServerError
+			switch {
+			case errors.Is(err, zfs.ErrMountpointOutsidePool):
+				status = http.StatusForbidden
+			case errors.Is(err, zfs.ErrUnknownTemplate):
+				status = http.StatusBadRequest
+			}
+			http.Error(w, err.Error(), status)
+			return
+		}
+	}
+
 	if err := s.zfs.CreateDataset(r.Context(), req); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		if errors.Is(err, zfs.ErrUnknownTemplate) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, err.Error(), zfsMutationStatus(err))
 		return
 	}
 
@@ -487,14 +1023,29 @@ func (s *Server) handleDestroyDataset(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !s.confirm(w, r, "destroy dataset "+name, s.destroyImpact(name)) {
+		return
+	}
+
 	if err := s.zfs.DestroyDataset(r.Context(), name); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), zfsMutationStatus(err))
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// destroyImpact describes what recursively destroying a dataset or pool removes.
+func (s *Server) destroyImpact(name string) func(ctx context.Context) (string, error) {
+	return func(ctx context.Context) (string, error) {
+		impact, err := s.zfs.DestroyImpact(ctx, name)
+		if err != nil {
+			return "", err
+		}
+		return impact.String(), nil
+	}
+}
+
 // Share handlers
 
 func (s *Server) handleListShares(w http.ResponseWriter, r *http.Request) {
@@ -509,10 +1060,39 @@ func (s *Server) handleListShares(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, http.StatusOK, shares)
 }
 
+// handlePreviewShareConfig returns the generated service config for a
+// protocol (default smb) as plain text, so it can be reviewed before a
+// share change rewrites and reloads it.
+func (s *Server) handlePreviewShareConfig(w http.ResponseWriter, r *http.Request) {
+	protocol := cmp.Or(r.URL.Query().Get("protocol"), "smb")
+
+	config, err := s.share.PreviewConfig(protocol)
+	if errors.Is(err, share.ErrUnsupportedProtocol) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	io.WriteString(w, config)
+}
+
+// handleReloadShares regenerates the Samba config from the database and
+// reloads Samba, undoing any out-of-band edits to smb.conf.
+func (s *Server) handleReloadShares(w http.ResponseWriter, r *http.Request) {
+	if err := s.share.Reload(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
 func (s *Server) handleCreateShare(w http.ResponseWriter, r *http.Request) {
 	var share store.Share
-	if err := json.NewDecoder(r.Body).Decode(&share); err != nil {
-		http.Error(w, "invalid request body", http.StatusBadRequest)
+	if !s.decodeJSON(w, r, &share) {
 		return
 	}
 
@@ -558,8 +1138,7 @@ func (s *Server) handleListUsers(w http.ResponseWriter, r *http.Request) {
 
 func (s *Server) handleCreateUser(w http.ResponseWriter, r *http.Request) {
 	var req user.CreateRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid request body", http.StatusBadRequest)
+	if !s.decodeJSON(w, r, &req) {
 		return
 	}
 
@@ -642,6 +1221,47 @@ func (s *Server) handleMarkAcknowledged(w http.ResponseWriter, r *http.Request)
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// handleBulkAcknowledge acknowledges the listed notifications, or with
+// all set, every notification with the given status (any unacknowledged
+// one if status is empty).
+func (s *Server) handleBulkAcknowledge(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		IDs    []int64                  `json:"ids"`
+		All    bool                     `json:"all"`
+		Status store.NotificationStatus `json:"status"`
+	}
+	if !s.decodeJSON(w, r, &req) {
+		return
+	}
+
+	var (
+		n   int64
+		err error
+	)
+	switch {
+	case req.All && len(req.IDs) > 0:
+		http.Error(w, "ids and all are mutually exclusive", http.StatusBadRequest)
+		return
+	case req.All:
+		if req.Status != "" && req.Status != store.NotificationUnread && req.Status != store.NotificationRead {
+			http.Error(w, "status must be unread or read", http.StatusBadRequest)
+			return
+		}
+		n, err = s.notification.MarkAllAcknowledged(req.Status)
+	case len(req.IDs) > 0:
+		n, err = s.notification.MarkAcknowledgedBatch(req.IDs)
+	default:
+		http.Error(w, "ids or all is required", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]int64{"acknowledged": n})
+}
+
 // handleDeleteNotification deletes a notification.
 func (s *Server) handleDeleteNotification(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
@@ -660,6 +1280,8 @@ func (s *Server) handleDeleteNotification(w http.ResponseWriter, r *http.Request
 
 // Pool scrub handlers
 
+// handlePoolScrub starts, stops or pauses a scrub. Starting a paused
+// scrub resumes it.
 func (s *Server) handlePoolScrub(w http.ResponseWriter, r *http.Request) {
 	poolName := r.PathValue("name")
 	if poolName == "" {
@@ -668,20 +1290,26 @@ func (s *Server) handlePoolScrub(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req struct {
-		Action string `json:"action"` // start, stop, pause
+		Action zfs.ScrubAction `json:"action"` // start, stop, pause
 	}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid request body", http.StatusBadRequest)
+	if !s.decodeJSON(w, r, &req) {
 		return
 	}
 
-	// Only "start" is supported for now via the Scrub method
-	if req.Action != "start" {
-		http.Error(w, "only 'start' action is supported", http.StatusBadRequest)
+	var scrub func(ctx context.Context, pool string) error
+	switch req.Action {
+	case zfs.ScrubStart:
+		scrub = s.zfs.Scrub
+	case zfs.ScrubStop:
+		scrub = s.zfs.ScrubStop
+	case zfs.ScrubPause:
+		scrub = s.zfs.ScrubPause
+	default:
+		http.Error(w, "action must be start, stop or pause", http.StatusBadRequest)
 		return
 	}
 
-	if err := s.zfs.Scrub(r.Context(), poolName); err != nil {
+	if err := scrub(r.Context(), poolName); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -689,16 +1317,122 @@ func (s *Server) handlePoolScrub(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusAccepted)
 }
 
-// handleGetPool returns detailed information about a single pool.
-func (s *Server) handleGetPool(w http.ResponseWriter, r *http.Request) {
+// handlePoolTrim starts a manual TRIM of a pool's free space. Pools on
+// devices without trim support get 409.
+func (s *Server) handlePoolTrim(w http.ResponseWriter, r *http.Request) {
 	poolName := r.PathValue("name")
 	if poolName == "" {
 		http.Error(w, "pool name required", http.StatusBadRequest)
 		return
 	}
 
-	pool, err := s.zfs.GetPool(r.Context(), poolName)
-	if err != nil {
+	if err := s.zfs.Trim(r.Context(), poolName); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, zfs.ErrTrimUnsupported) {
+			status = http.StatusConflict
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// handlePoolTrimStatus returns the per-device progress of a pool's trim.
+func (s *Server) handlePoolTrimStatus(w http.ResponseWriter, r *http.Request) {
+	poolName := r.PathValue("name")
+	if poolName == "" {
+		http.Error(w, "pool name required", http.StatusBadRequest)
+		return
+	}
+
+	status, err := s.zfs.TrimStatus(r.Context(), poolName)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	respondJSON(w, http.StatusOK, status)
+}
+
+// handleSetPoolAutotrim turns the pool's autotrim property on or off.
+func (s *Server) handleSetPoolAutotrim(w http.ResponseWriter, r *http.Request) {
+	poolName := r.PathValue("name")
+	if poolName == "" {
+		http.Error(w, "pool name required", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		Enabled *bool `json:"enabled"`
+	}
+	if !s.decodeJSON(w, r, &req) {
+		return
+	}
+	if req.Enabled == nil {
+		http.Error(w, "enabled is required", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.zfs.SetAutotrim(r.Context(), poolName, *req.Enabled); err != nil {
+		http.Error(w, err.Error(), zfsMutationStatus(err))
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]bool{"autotrim": *req.Enabled})
+}
+
+// handleGetPoolProperties returns a pool's properties and which of them
+// can be set.
+func (s *Server) handleGetPoolProperties(w http.ResponseWriter, r *http.Request) {
+	props, err := s.zfs.GetPoolProperties(r.Context(), r.PathValue("name"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	respondJSON(w, http.StatusOK, props)
+}
+
+// handleSetPoolProperty sets one allowlisted pool property.
+func (s *Server) handleSetPoolProperty(w http.ResponseWriter, r *http.Request) {
+	poolName := r.PathValue("name")
+	if poolName == "" {
+		http.Error(w, "pool name required", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		Key   string `json:"key"`
+		Value string `json:"value"`
+	}
+	if !s.decodeJSON(w, r, &req) {
+		return
+	}
+	if req.Key == "" {
+		http.Error(w, "key is required", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.zfs.SetPoolProperty(r.Context(), poolName, req.Key, req.Value); err != nil {
+		status := zfsMutationStatus(err)
+		if errors.Is(err, zfs.ErrPoolPropertyNotSettable) {
+			status = http.StatusBadRequest
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleGetPool returns detailed information about a single pool.
+func (s *Server) handleGetPool(w http.ResponseWriter, r *http.Request) {
+	poolName := r.PathValue("name")
+	if poolName == "" {
+		http.Error(w, "pool name required", http.StatusBadRequest)
+		return
+	}
+
+	pool, err := s.zfs.GetPool(r.Context(), poolName)
+	if err != nil {
 		http.Error(w, err.Error(), http.StatusNotFound)
 		return
 	}
@@ -706,6 +1440,104 @@ func (s *Server) handleGetPool(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, http.StatusOK, pool)
 }
 
+// handleGetPoolHealth returns a risk assessment for a pool, including
+// warnings about mixed vdev layouts.
+func (s *Server) handleGetPoolHealth(w http.ResponseWriter, r *http.Request) {
+	poolName := r.PathValue("name")
+	if poolName == "" {
+		http.Error(w, "pool name required", http.StatusBadRequest)
+		return
+	}
+
+	pool, err := s.zfs.GetPool(r.Context(), poolName)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, zfs.AssessHealth(*pool))
+}
+
+// handleImportablePools lists pools whose disks are attached but which are
+// not imported yet.
+func (s *Server) handleImportablePools(w http.ResponseWriter, r *http.Request) {
+	pools, err := s.zfs.ImportablePools(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if pools == nil {
+		pools = []zfs.ImportablePool{}
+	}
+	respondJSON(w, http.StatusOK, pools)
+}
+
+// handleImportPool imports a pool, optionally despite missing devices.
+// The response lists any devices the pool was imported without.
+func (s *Server) handleImportPool(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Name string `json:"name"` // pool name or numeric GUID
+		zfs.ImportOptions
+	}
+	if !s.decodeJSON(w, r, &req) {
+		return
+	}
+	if req.Name == "" {
+		http.Error(w, "pool name required", http.StatusBadRequest)
+		return
+	}
+
+	pool, err := s.zfs.ImportPool(r.Context(), req.Name, req.ImportOptions)
+	if err != nil {
+		if errors.Is(err, zfs.ErrImportRefused) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, pool)
+}
+
+// handleExportPool exports a pool so its disks can be moved to another
+// system. The data is untouched and the pool can be imported again.
+func (s *Server) handleExportPool(w http.ResponseWriter, r *http.Request) {
+	poolName := r.PathValue("name")
+	if poolName == "" {
+		http.Error(w, "pool name required", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.zfs.ExportPool(r.Context(), poolName); err != nil {
+		http.Error(w, err.Error(), zfsMutationStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleDestroyPool destroys a pool and all of its data. It always needs
+// a confirmation token, even without WithConfirmation.
+func (s *Server) handleDestroyPool(w http.ResponseWriter, r *http.Request) {
+	poolName := r.PathValue("name")
+	if poolName == "" {
+		http.Error(w, "pool name required", http.StatusBadRequest)
+		return
+	}
+
+	if !s.confirmRequired(w, r, "destroy pool "+poolName, s.destroyImpact(poolName)) {
+		return
+	}
+
+	if err := s.zfs.DestroyPool(r.Context(), poolName); err != nil {
+		http.Error(w, err.Error(), zfsMutationStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
 // handleReplaceDisk initiates a disk replacement in a pool.
 func (s *Server) handleReplaceDisk(w http.ResponseWriter, r *http.Request) {
 	poolName := r.PathValue("name")
@@ -718,8 +1550,7 @@ func (s *Server) handleReplaceDisk(w http.ResponseWriter, r *http.Request) {
 		OldDisk string `json:"old_disk"`
 		NewDisk string `json:"new_disk"`
 	}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid request body", http.StatusBadRequest)
+	if !s.decodeJSON(w, r, &req) {
 		return
 	}
 
@@ -729,13 +1560,80 @@ func (s *Server) handleReplaceDisk(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := s.zfs.ReplaceDisk(r.Context(), poolName, req.OldDisk, req.NewDisk); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), zfsMutationStatus(err))
 		return
 	}
 
 	w.WriteHeader(http.StatusAccepted)
 }
 
+// handleAddVdev grows a pool with a new data vdev. A vdev that would lower
+// the pool's redundancy or does not match its layout is refused with 409;
+// force allows a lower redundancy, but only on disks that are free, since
+// it also overrides zpool's checks for disks in use.
+func (s *Server) handleAddVdev(w http.ResponseWriter, r *http.Request) {
+	poolName := r.PathValue("name")
+	if poolName == "" {
+		http.Error(w, "pool name required", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		Type    string   `json:"type"`
+		Devices []string `json:"devices"`
+		Force   bool     `json:"force"`
+	}
+	if !s.decodeJSON(w, r, &req) {
+		return
+	}
+	if len(req.Devices) == 0 {
+		http.Error(w, "devices are required", http.StatusBadRequest)
+		return
+	}
+
+	var opts []zfs.AddVdevOption
+	if req.Force {
+		if s.disk == nil {
+			http.Error(w, "disk features are disabled", http.StatusServiceUnavailable)
+			return
+		}
+		disks, err := s.disk.ListBasic(r.Context())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if dev, ok := firstUnavailable(req.Devices, disk.Available(disks, false)); !ok {
+			http.Error(w, fmt.Sprintf("device %s is not an available disk", dev), http.StatusConflict)
+			return
+		}
+		opts = append(opts, zfs.AllowReducedRedundancy())
+	}
+	if err := s.zfs.AddVdev(r.Context(), poolName, req.Type, req.Devices, opts...); err != nil {
+		status := zfsMutationStatus(err)
+		switch {
+		case errors.Is(err, zfs.ErrInvalidVdev):
+			status = http.StatusBadRequest
+		case errors.Is(err, zfs.ErrReducesRedundancy), errors.Is(err, zfs.ErrMismatchedReplication):
+			status = http.StatusConflict
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// firstUnavailable returns the first of devices that is not the path of
+// one of available, and false if there is one.
+func firstUnavailable(devices []string, available []disk.Info) (string, bool) {
+	for _, dev := range devices {
+		if !slices.ContainsFunc(available, func(d disk.Info) bool { return d.Path == dev }) {
+			return dev, false
+		}
+	}
+	return "", true
+}
+
 // Dataset quota handler
 func (s *Server) handleSetDatasetQuota(w http.ResponseWriter, r *http.Request) {
 	name := r.URL.Query().Get("name")
@@ -747,16 +1645,363 @@ func (s *Server) handleSetDatasetQuota(w http.ResponseWriter, r *http.Request) {
 	var req struct {
 		Quota uint64 `json:"quota"`
 	}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid request body", http.StatusBadRequest)
+	if !s.decodeJSON(w, r, &req) {
 		return
 	}
 
 	if err := s.zfs.SetQuota(r.Context(), name, req.Quota); err != nil {
+		http.Error(w, err.Error(), zfsMutationStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleSetDatasetRefQuota limits the space a dataset itself can
+// reference. A refquota below what the dataset already uses is refused by
+// zfs and reported as an error.
+func (s *Server) handleSetDatasetRefQuota(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		http.Error(w, "dataset name required in query parameter", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		RefQuota uint64 `json:"refquota"`
+	}
+	if !s.decodeJSON(w, r, &req) {
+		return
+	}
+
+	if err := s.zfs.SetRefQuota(r.Context(), name, req.RefQuota); err != nil {
+		http.Error(w, err.Error(), zfsMutationStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// compressionRequest turns compression on, off, or to a given algorithm.
+type compressionRequest struct {
+	Algorithm string `json:"algorithm,omitempty"` // e.g. "zstd-3"
+	Enabled   *bool  `json:"enabled,omitempty"`   // false means compression=off
+}
+
+// value returns the compression property value the request asks for.
+func (req compressionRequest) value() (string, error) {
+	switch {
+	case req.Enabled != nil && !*req.Enabled:
+		if req.Algorithm != "" && req.Algorithm != "off" {
+			return "", fmt.Errorf("algorithm cannot be set when enabled is false")
+		}
+		return "off", nil
+	case req.Algorithm != "":
+		return req.Algorithm, nil
+	case req.Enabled != nil:
+		return "on", nil // the pool default algorithm
+	default:
+		return "", fmt.Errorf("algorithm or enabled is required")
+	}
+}
+
+// handleSetDatasetCompression sets a dataset's compression. Existing data
+// keeps its old compression; only new writes use the new setting.
+func (s *Server) handleSetDatasetCompression(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		http.Error(w, "dataset name required in query parameter", http.StatusBadRequest)
+		return
+	}
+
+	var req compressionRequest
+	if !s.decodeJSON(w, r, &req) {
+		return
+	}
+	algorithm, err := req.value()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := s.zfs.SetCompression(r.Context(), name, algorithm); err != nil {
+		status := zfsMutationStatus(err)
+		if errors.Is(err, zfs.ErrUnsupportedCompression) {
+			status = http.StatusBadRequest
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{
+		"compression": algorithm,
+		"note":        "existing data is not recompressed; only new writes use this setting",
+	})
+}
+
+// handleGetDatasetProperties returns dataset property values. The keys
+// query parameter is a comma-separated list; without it the settable
+// properties are returned.
+func (s *Server) handleGetDatasetProperties(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		http.Error(w, "dataset name required in query parameter", http.StatusBadRequest)
+		return
+	}
+
+	var keys []string
+	if k := r.URL.Query().Get("keys"); k != "" {
+		keys = strings.Split(k, ",")
+	}
+
+	props, err := s.zfs.GetProperties(r.Context(), name, keys)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]any{
+		"properties": props,
+		"settable":   zfs.SettableProperties(),
+	})
+}
+
+// handleSetDatasetProperties sets allowlisted dataset properties. Read-only
+// and other non-allowlisted properties are rejected before any is changed.
+func (s *Server) handleSetDatasetProperties(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		http.Error(w, "dataset name required in query parameter", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		Properties map[string]string `json:"properties"`
+	}
+	if !s.decodeJSON(w, r, &req) {
+		return
+	}
+	if len(req.Properties) == 0 {
+		http.Error(w, "properties are required", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.zfs.SetProperties(r.Context(), name, req.Properties); err != nil {
+		status := zfsMutationStatus(err)
+		if errors.Is(err, zfs.ErrPropertyNotSettable) || errors.Is(err, zfs.ErrUnsupportedCompression) {
+			status = http.StatusBadRequest
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleDatasetChanges lists what changed in a dataset since its newest
+// snapshot, to help decide whether a new snapshot is worth taking.
+func (s *Server) handleDatasetChanges(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		http.Error(w, "dataset name required in query parameter", http.StatusBadRequest)
+		return
+	}
+
+	entries, err := s.zfs.ChangesSinceLatest(r.Context(), name)
+	truncated := errors.Is(err, zfs.ErrDiffTruncated)
+	if err != nil && !truncated {
+		if errors.Is(err, zfs.ErrNoSnapshots) {
+			http.Error(w, name+" has no snapshots yet; take one to start tracking changes", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]any{
+		"entries":   entries,
+		"truncated": truncated,
+	})
+}
+
+// handleSnapshotDiff lists what changed between snapshot name and the later
+// snapshot to. Huge diffs are cut off at the manager's entry limit and
+// reported with truncated set.
+func (s *Server) handleSnapshotDiff(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("name")
+	to := r.URL.Query().Get("to")
+	if name == "" || to == "" {
+		http.Error(w, "snapshot name and to are required in query parameters", http.StatusBadRequest)
+		return
+	}
+
+	entries, err := s.zfs.SnapshotDiff(r.Context(), name, to)
+	truncated := errors.Is(err, zfs.ErrDiffTruncated)
+	if err != nil && !truncated {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]any{
+		"entries":   entries,
+		"truncated": truncated,
+	})
+}
+
+// Dataset note handlers
+func (s *Server) handleGetDatasetNote(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		http.Error(w, "dataset name required in query parameter", http.StatusBadRequest)
+		return
+	}
+
+	note, err := s.zfs.GetNote(r.Context(), name)
+	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
+	respondJSON(w, http.StatusOK, map[string]string{"note": note})
+}
+
+func (s *Server) handleSetDatasetNote(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		http.Error(w, "dataset name required in query parameter", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		Note string `json:"note"` // empty removes the note
+	}
+	if !s.decodeJSON(w, r, &req) {
+		return
+	}
+
+	if err := s.zfs.SetNote(r.Context(), name, req.Note); err != nil {
+		http.Error(w, err.Error(), zfsMutationStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleTemplateDrift reports dataset properties that differ from a
+// use-case template.
+func (s *Server) handleTemplateDrift(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		http.Error(w, "dataset name required in query parameter", http.StatusBadRequest)
+		return
+	}
+
+	useCase := zfs.UseCaseTemplate(r.URL.Query().Get("use_case"))
+	if useCase == "" {
+		http.Error(w, "invalid use_case", http.StatusBadRequest)
+		return
+	}
+
+	drift, err := s.zfs.TemplateDrift(r.Context(), name, useCase)
+	if err != nil {
+		if errors.Is(err, zfs.ErrUnknownTemplate) {
+			http.Error(w, "invalid use_case", http.StatusBadRequest)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, drift)
+}
+
+// Dataset ACL handlers
+func (s *Server) handleGetDatasetACL(w http.ResponseWriter, r *http.Request) {
+	mountpoint, ok := s.datasetMountpoint(w, r)
+	if !ok {
+		return
+	}
+
+	entries, err := s.zfs.GetACL(r.Context(), mountpoint)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, entries)
+}
+
+func (s *Server) handleSetDatasetACL(w http.ResponseWriter, r *http.Request) {
+	var entries []zfs.ACLEntry
+	if !s.decodeJSON(w, r, &entries) {
+		return
+	}
+
+	mountpoint, ok := s.datasetMountpoint(w, r)
+	if !ok {
+		return
+	}
+
+	if err := s.zfs.SetACL(r.Context(), mountpoint, entries); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// datasetMountpoint resolves the mountpoint of the dataset named in the
+// query string, replying with an error if it has none.
+func (s *Server) datasetMountpoint(w http.ResponseWriter, r *http.Request) (string, bool) {
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		http.Error(w, "dataset name required in query parameter", http.StatusBadRequest)
+		return "", false
+	}
+
+	ds, err := s.zfs.GetDataset(r.Context(), name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return "", false
+	}
+	if !strings.HasPrefix(ds.Mountpoint, "/") {
+		http.Error(w, "dataset is not mounted", http.StatusBadRequest)
+		return "", false
+	}
+	return ds.Mountpoint, true
+}
+
+// Dataset reservation handler
+func (s *Server) handleSetDatasetReservation(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		http.Error(w, "dataset name required in query parameter", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		Reservation uint64              `json:"reservation"`
+		Mode        zfs.ReservationMode `json:"mode"` // "reservation" (default) or "refreservation"
+	}
+	if !s.decodeJSON(w, r, &req) {
+		return
+	}
+	switch req.Mode {
+	case "", zfs.ReservationFull, zfs.ReservationRef:
+	default:
+		http.Error(w, "invalid reservation mode", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.zfs.SetReservation(r.Context(), name, req.Mode, req.Reservation); err != nil {
+		if errors.Is(err, zfs.ErrReservationTooLarge) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, err.Error(), zfsMutationStatus(err))
+		return
+	}
+
 	w.WriteHeader(http.StatusNoContent)
 }
 
@@ -780,14 +2025,17 @@ func (s *Server) handleListSnapshots(w http.ResponseWriter, r *http.Request) {
 
 func (s *Server) handleCreateSnapshot(w http.ResponseWriter, r *http.Request) {
 	var req zfs.CreateSnapshotRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid request body", http.StatusBadRequest)
+	if !s.decodeJSON(w, r, &req) {
 		return
 	}
 
 	snapshot, err := s.zfs.CreateSnapshot(r.Context(), req)
+	if errors.Is(err, zfs.ErrSnapshotUnchanged) {
+		respondJSON(w, http.StatusOK, map[string]bool{"skipped": true})
+		return
+	}
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), zfsMutationStatus(err))
 		return
 	}
 
@@ -802,7 +2050,7 @@ func (s *Server) handleDestroySnapshot(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := s.zfs.DestroySnapshot(r.Context(), name); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), zfsMutationStatus(err))
 		return
 	}
 
@@ -816,14 +2064,132 @@ func (s *Server) handleRollbackSnapshot(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	if !s.confirm(w, r, "rollback "+name, func(ctx context.Context) (string, error) {
+		dataset, _, _ := strings.Cut(name, "@")
+		return fmt.Sprintf("will discard all changes to %s since %s", dataset, name), nil
+	}) {
+		return
+	}
+
 	if err := s.zfs.RollbackSnapshot(r.Context(), name); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), zfsMutationStatus(err))
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
 
+func (s *Server) handleRenameSnapshot(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		http.Error(w, "snapshot name required in query parameter", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		NewName string `json:"new_name"` // without the dataset prefix
+	}
+	if !s.decodeJSON(w, r, &req) {
+		return
+	}
+	if req.NewName == "" || strings.Contains(req.NewName, "@") {
+		http.Error(w, "new_name is required and must not contain '@'", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.zfs.RenameSnapshot(r.Context(), name, req.NewName); err != nil {
+		http.Error(w, err.Error(), zfsMutationStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleSendSnapshotToFile starts a task that writes a snapshot's send
+// stream to a file on the server, for offline backups.
+func (s *Server) handleSendSnapshotToFile(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		http.Error(w, "snapshot name required in query parameter", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		Path      string `json:"path"`
+		Compress  bool   `json:"compress"`
+		Overwrite bool   `json:"overwrite"`
+	}
+	if !s.decodeJSON(w, r, &req) {
+		return
+	}
+
+	if s.replayTask(w, r, "send "+name) {
+		return
+	}
+	// Check the destination up front so mistakes surface before the task starts
+	if err := s.zfs.CheckSendDestination(req.Path, req.Overwrite); err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, zfs.ErrDestinationExists) {
+			status = http.StatusConflict
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	opts := []zfs.SendOption{}
+	if req.Overwrite {
+		opts = append(opts, zfs.Overwrite())
+	}
+	s.submitTask(w, r, "send "+name, func(ctx context.Context, update func(int)) (interface{}, error) {
+		progress := zfs.SendProgress(func(sent, total int64) {
+			if total > 0 {
+				update(int(min(99, sent*100/total)))
+			}
+		})
+		return s.zfs.SendToFile(ctx, name, req.Path, req.Compress, append(opts, progress)...)
+	})
+}
+
+// handleReceiveDataset starts a task that restores a send stream file
+// into a new dataset.
+func (s *Server) handleReceiveDataset(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Path   string `json:"path"`
+		Target string `json:"target"`
+	}
+	if !s.decodeJSON(w, r, &req) {
+		return
+	}
+	if req.Target == "" {
+		http.Error(w, "target dataset is required", http.StatusBadRequest)
+		return
+	}
+	if s.replayTask(w, r, "receive "+req.Target) {
+		return
+	}
+	if err := s.zfs.CheckReceiveSource(req.Path); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := s.zfs.CheckReceiveTarget(r.Context(), req.Target); err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, zfs.ErrPoolReadOnly) {
+			status = http.StatusConflict
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	s.submitTask(w, r, "receive "+req.Target, func(ctx context.Context, update func(int)) (interface{}, error) {
+		progress := zfs.ReceiveProgress(func(read, total int64) {
+			if total > 0 {
+				update(int(min(99, read*100/total)))
+			}
+		})
+		return s.zfs.ReceiveFromFile(ctx, req.Path, req.Target, progress)
+	})
+}
+
 // handleCountNotifications returns notification counts by status.
 func (s *Server) handleCountNotifications(w http.ResponseWriter, r *http.Request) {
 	unread, _ := s.notification.Count(store.NotificationUnread)
@@ -880,6 +2246,28 @@ func (s *Server) handleSystemStats(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, http.StatusOK, stats)
 }
 
+// handleSystemResources returns open file and connection counts.
+func (s *Server) handleSystemResources(w http.ResponseWriter, r *http.Request) {
+	res, err := s.sysinfo.Resources()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, res)
+}
+
+// handleSystemSwap returns the active swap devices, including zram.
+func (s *Server) handleSystemSwap(w http.ResponseWriter, r *http.Request) {
+	devices, err := sysinfo.SwapDevices()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, devices)
+}
+
 // handleListProcesses returns a list of running processes.
 func (s *Server) handleListProcesses(w http.ResponseWriter, r *http.Request) {
 	processes, err := s.sysinfo.ListProcesses()
@@ -911,8 +2299,7 @@ func (s *Server) handleSignalProcess(w http.ResponseWriter, r *http.Request) {
 	var req struct {
 		Signal string `json:"signal"` // "TERM" or "KILL"
 	}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid request body", http.StatusBadRequest)
+	if !s.decodeJSON(w, r, &req) {
 		return
 	}
 
@@ -929,6 +2316,28 @@ func (s *Server) handleSignalProcess(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// handleProcessDetail returns the environment, working directory, open
+// file count and parent PID of a process, for debugging.
+func (s *Server) handleProcessDetail(w http.ResponseWriter, r *http.Request) {
+	pid, err := strconv.Atoi(r.PathValue("pid"))
+	if err != nil || pid <= 0 {
+		http.Error(w, "invalid pid", http.StatusBadRequest)
+		return
+	}
+
+	detail, err := s.sysinfo.ProcessDetail(pid)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			http.Error(w, "process not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, detail)
+}
+
 // containsIgnoreCase checks if s contains substr (case-insensitive).
 func containsIgnoreCase(s, substr string) bool {
 	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
@@ -0,0 +1,120 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.aimuz.me/mynt/auth"
+	"go.aimuz.me/mynt/store"
+	"go.aimuz.me/mynt/zfs"
+)
+
+// authServer returns a server whose pool routes are stubs behind the real
+// auth wrappers.
+func authServer(opts ...Option) *Server {
+	s := &Server{
+		authMw: auth.NewMiddleware(auth.DefaultConfig("test-secret")),
+		mux:    http.NewServeMux(),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }
+	s.mux.HandleFunc("GET /api/v1/pools", s.protected(ok))
+	s.mux.HandleFunc("POST /api/v1/pools", s.protected(ok))
+	s.mux.HandleFunc("GET /api/v1/users/{username}", s.adminOnly(ok))
+	return s
+}
+
+func TestAnonymousRead(t *testing.T) {
+	tests := []struct {
+		name      string
+		anonymous bool
+		method    string
+		path      string
+		want      int
+	}{
+		{"default_get", false, http.MethodGet, "/api/v1/pools", http.StatusUnauthorized},
+		{"anonymous_get", true, http.MethodGet, "/api/v1/pools", http.StatusOK},
+		{"anonymous_post", true, http.MethodPost, "/api/v1/pools", http.StatusUnauthorized},
+		{"anonymous_admin_get", true, http.MethodGet, "/api/v1/users/alice", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var opts []Option
+			if tt.anonymous {
+				opts = append(opts, WithAnonymousRead())
+			}
+			s := authServer(opts...)
+
+			rr := httptest.NewRecorder()
+			s.ServeHTTP(rr, httptest.NewRequest(tt.method, tt.path, nil))
+			require.Equal(t, tt.want, rr.Code)
+		})
+	}
+}
+
+func TestCompressionRequestValue(t *testing.T) {
+	off, on := false, true
+	tests := []struct {
+		name    string
+		req     compressionRequest
+		want    string
+		wantErr bool
+	}{
+		{"zstd_with_level", compressionRequest{Algorithm: "zstd-3"}, "zstd-3", false},
+		{"disable", compressionRequest{Enabled: &off}, "off", false},
+		{"enable_default", compressionRequest{Enabled: &on}, "on", false},
+		{"algorithm_with_enabled", compressionRequest{Algorithm: "lz4", Enabled: &on}, "lz4", false},
+		{"conflicting", compressionRequest{Algorithm: "lz4", Enabled: &off}, "", true},
+		{"empty", compressionRequest{}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.req.value()
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestHandleSetSmartDeviceType_Disabled(t *testing.T) {
+	s := &Server{maxBodyBytes: DefaultMaxBodyBytes}
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/disks/sda/smart/device-type", strings.NewReader(`{"device_type": "sat"}`))
+	req.SetPathValue("name", "sda")
+	rr := httptest.NewRecorder()
+	s.handleSetSmartDeviceType(rr, req)
+	require.Equal(t, http.StatusServiceUnavailable, rr.Code)
+}
+
+func TestHandleCreateDataset_MountpointOutsidePool(t *testing.T) {
+	s := &Server{zfs: zfs.NewManager(), maxBodyBytes: DefaultMaxBodyBytes}
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/datasets", strings.NewReader(`{"name": "tank/data", "mountpoint": "/etc"}`))
+	req = req.WithContext(context.WithValue(req.Context(), auth.UserContextKey, &auth.Claims{UserID: 2}))
+	rr := httptest.NewRecorder()
+	s.handleCreateDataset(rr, req)
+	require.Equal(t, http.StatusForbidden, rr.Code)
+}
+
+func TestSetDatasetACL_AdminOnly(t *testing.T) {
+	cfg := auth.DefaultConfig("test-secret")
+	s := &Server{authMw: auth.NewMiddleware(cfg), mux: http.NewServeMux()}
+	s.routes()
+
+	token, err := auth.GenerateToken(&store.User{ID: 2, Username: "bob"}, cfg)
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/datasets/acl?name=tank/data", strings.NewReader(`[]`))
+	req.Header.Set("Authorization", "Bearer "+token)
+	rr := httptest.NewRecorder()
+	s.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusForbidden, rr.Code)
+}
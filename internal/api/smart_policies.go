@@ -0,0 +1,133 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"go.aimuz.me/mynt/disk"
+	"go.aimuz.me/mynt/scheduler"
+	"go.aimuz.me/mynt/store"
+)
+
+// WithSmartTestPolicies enables managing the SMART self-test policies
+// stored in repo. Without it the policy routes answer 503.
+func WithSmartTestPolicies(repo *store.SmartTestPolicyRepo) Option {
+	return func(s *Server) {
+		s.smartPolicies = repo
+	}
+}
+
+// smartPoliciesEnabled reports whether SMART test policies are configured,
+// answering 503 if not.
+func (s *Server) smartPoliciesEnabled(w http.ResponseWriter) bool {
+	if s.smartPolicies == nil {
+		http.Error(w, "SMART test policies are not enabled", http.StatusServiceUnavailable)
+		return false
+	}
+	return true
+}
+
+// validateSmartTestPolicy checks a policy and normalizes its schedule.
+func validateSmartTestPolicy(p *store.SmartTestPolicy) error {
+	if !policyNameRegex.MatchString(p.Name) {
+		return errors.New("policy name must start with a letter and contain only letters, numbers, underscores, and hyphens")
+	}
+	if p.TestType != disk.TestShort && p.TestType != disk.TestLong {
+		return fmt.Errorf("test_type must be %s or %s", disk.TestShort, disk.TestLong)
+	}
+	schedule, err := scheduler.NormalizeSchedule(p.Schedule)
+	if err != nil {
+		return err
+	}
+	p.Schedule = schedule
+	return nil
+}
+
+func (s *Server) handleListSmartTestPolicies(w http.ResponseWriter, r *http.Request) {
+	if !s.smartPoliciesEnabled(w) {
+		return
+	}
+	policies, err := s.smartPolicies.List()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	respondJSON(w, http.StatusOK, policies)
+}
+
+func (s *Server) handleCreateSmartTestPolicy(w http.ResponseWriter, r *http.Request) {
+	if !s.smartPoliciesEnabled(w) {
+		return
+	}
+
+	var p store.SmartTestPolicy
+	if !s.decodeJSON(w, r, &p) {
+		return
+	}
+	if err := validateSmartTestPolicy(&p); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := s.smartPolicies.Save(&p); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	s.notifyPolicyChange()
+	respondJSON(w, http.StatusCreated, p)
+}
+
+// handleUpdateSmartTestPolicy replaces a policy with the request body and
+// reschedules it.
+func (s *Server) handleUpdateSmartTestPolicy(w http.ResponseWriter, r *http.Request) {
+	if !s.smartPoliciesEnabled(w) {
+		return
+	}
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid policy ID", http.StatusBadRequest)
+		return
+	}
+
+	var p store.SmartTestPolicy
+	if !s.decodeJSON(w, r, &p) {
+		return
+	}
+	p.ID = id
+	if err := validateSmartTestPolicy(&p); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := s.smartPolicies.Update(&p); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, "policy not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	s.notifyPolicyChange()
+	respondJSON(w, http.StatusOK, p)
+}
+
+func (s *Server) handleDeleteSmartTestPolicy(w http.ResponseWriter, r *http.Request) {
+	if !s.smartPoliciesEnabled(w) {
+		return
+	}
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid policy ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.smartPolicies.Delete(id); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	s.notifyPolicyChange()
+	w.WriteHeader(http.StatusNoContent)
+}
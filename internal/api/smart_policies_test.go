@@ -0,0 +1,57 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.aimuz.me/mynt/store"
+)
+
+func TestHandleSmartTestPolicies(t *testing.T) {
+	db, err := store.Open(":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	repo := store.NewSmartTestPolicyRepo(db)
+	reloads := 0
+	s := &Server{smartPolicies: repo, maxBodyBytes: DefaultMaxBodyBytes, onPolicyChange: func() { reloads++ }}
+
+	create := func(body string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		s.handleCreateSmartTestPolicy(rr, httptest.NewRequest(http.MethodPost, "/api/v1/smart-policies", strings.NewReader(body)))
+		return rr
+	}
+
+	rr := create(`{"name": "nightly", "schedule": "@daily", "test_type": "conveyance"}`)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	rr = create(`{"name": "nightly", "schedule": "0 25 * * *", "test_type": "short"}`)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	require.Zero(t, reloads)
+
+	rr = create(`{"name": "nightly", "schedule": "@daily", "test_type": "short", "disks": ["sda"], "enabled": true}`)
+	require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+	var created store.SmartTestPolicy
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&created))
+	require.Equal(t, "0 0 0 * * *", created.Schedule)
+	require.Equal(t, 1, reloads)
+
+	rr = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/smart-policies/999", strings.NewReader(`{"name": "gone", "schedule": "@daily", "test_type": "long"}`))
+	req.SetPathValue("id", "999")
+	s.handleUpdateSmartTestPolicy(rr, req)
+	require.Equal(t, http.StatusNotFound, rr.Code)
+
+	policies, err := repo.List()
+	require.NoError(t, err)
+	require.Len(t, policies, 3) // with the two default policies
+}
+
+func TestHandleSmartTestPolicies_Disabled(t *testing.T) {
+	s := &Server{maxBodyBytes: DefaultMaxBodyBytes}
+	rr := httptest.NewRecorder()
+	s.handleListSmartTestPolicies(rr, httptest.NewRequest(http.MethodGet, "/api/v1/smart-policies", nil))
+	require.Equal(t, http.StatusServiceUnavailable, rr.Code)
+}
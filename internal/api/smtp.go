@@ -0,0 +1,91 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/mail"
+
+	"go.aimuz.me/mynt/notify"
+	"go.aimuz.me/mynt/store"
+)
+
+// WithEmail lets admins change the SMTP settings of email notification
+// channels at runtime. Without it the endpoints answer 503.
+func WithEmail(sender *notify.EmailSender) Option {
+	return func(s *Server) {
+		s.email = sender
+	}
+}
+
+// smtpResponse is the SMTP settings without the password.
+type smtpResponse struct {
+	store.SMTPConfig
+	PasswordSet bool `json:"password_set"`
+}
+
+func newSMTPResponse(cfg store.SMTPConfig) smtpResponse {
+	resp := smtpResponse{SMTPConfig: cfg, PasswordSet: cfg.Password != ""}
+	resp.Password = ""
+	return resp
+}
+
+// handleGetSMTPConfig returns the SMTP settings in use.
+func (s *Server) handleGetSMTPConfig(w http.ResponseWriter, r *http.Request) {
+	if s.email == nil {
+		http.Error(w, "email notifications are not available", http.StatusServiceUnavailable)
+		return
+	}
+	respondJSON(w, http.StatusOK, newSMTPResponse(s.email.Config()))
+}
+
+// handleSetSMTPConfig saves the SMTP settings and applies them to emails
+// not yet sent. An empty password keeps the current one; an empty host
+// disables email.
+func (s *Server) handleSetSMTPConfig(w http.ResponseWriter, r *http.Request) {
+	if s.email == nil {
+		http.Error(w, "email notifications are not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	var cfg store.SMTPConfig
+	if !s.decodeJSON(w, r, &cfg) {
+		return
+	}
+	if err := validateSMTPConfig(&cfg); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if cfg.Password == "" && cfg.Username != "" {
+		cfg.Password = s.email.Config().Password
+	}
+
+	if err := s.config.SetSMTPConfig(cfg); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	s.email.SetConfig(cfg)
+	respondJSON(w, http.StatusOK, newSMTPResponse(cfg))
+}
+
+// validateSMTPConfig checks SMTP settings, defaulting the port to 587 (465
+// with implicit TLS) and reducing the sender to its bare address.
+func validateSMTPConfig(cfg *store.SMTPConfig) error {
+	if cfg.Host == "" {
+		return nil
+	}
+	if cfg.Port == 0 {
+		cfg.Port = 587
+		if cfg.TLS {
+			cfg.Port = 465
+		}
+	}
+	if cfg.Port < 1 || cfg.Port > 65535 {
+		return fmt.Errorf("invalid port %d", cfg.Port)
+	}
+	addr, err := mail.ParseAddress(cfg.From)
+	if err != nil {
+		return fmt.Errorf("invalid from address %q", cfg.From)
+	}
+	cfg.From = addr.Address
+	return nil
+}
@@ -0,0 +1,51 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.aimuz.me/mynt/notify"
+	"go.aimuz.me/mynt/store"
+)
+
+func TestHandleSetSMTPConfig(t *testing.T) {
+	db, err := store.Open(":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	cfg := store.NewConfigRepo(db)
+
+	sender := notify.NewEmailSender(store.SMTPConfig{Host: "old.example.com", Port: 25, Username: "nas", Password: "secret"}, 0)
+	s := &Server{config: cfg, maxBodyBytes: DefaultMaxBodyBytes}
+	WithEmail(sender)(s)
+
+	put := func(body string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		s.handleSetSMTPConfig(rr, httptest.NewRequest(http.MethodPut, "/api/v1/config/smtp", strings.NewReader(body)))
+		return rr
+	}
+
+	require.Equal(t, http.StatusBadRequest, put(`{"host": "mail.example.com", "from": "not an address"}`).Code)
+	require.Equal(t, http.StatusBadRequest, put(`{"host": "mail.example.com", "port": 70000, "from": "nas@example.com"}`).Code)
+
+	// The password is kept when not given, and never returned
+	rr := put(`{"host": "mail.example.com", "tls": true, "username": "nas", "from": "NAS <nas@example.com>"}`)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var resp smtpResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+	require.True(t, resp.PasswordSet)
+	require.Empty(t, resp.Password)
+
+	want := store.SMTPConfig{Host: "mail.example.com", Port: 465, Username: "nas", Password: "secret", From: "nas@example.com", TLS: true}
+	require.Equal(t, want, sender.Config())
+	saved, err := cfg.SMTPConfig()
+	require.NoError(t, err)
+	require.Equal(t, &want, saved)
+
+	rr = httptest.NewRecorder()
+	s.handleGetSMTPConfig(rr, httptest.NewRequest(http.MethodGet, "/api/v1/config/smtp", nil))
+	require.NotContains(t, rr.Body.String(), "secret")
+}
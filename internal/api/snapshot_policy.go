@@ -1,14 +1,21 @@
 package api
 
 import (
-	"encoding/json"
+	"fmt"
 	"net/http"
+	"path"
 	"regexp"
 	"strconv"
+	"time"
 
+	"go.aimuz.me/mynt/scheduler"
 	"go.aimuz.me/mynt/store"
 )
 
+// defaultPreviewCount is how many fire times a schedule preview returns
+// when the request does not say.
+const defaultPreviewCount = 5
+
 // policyNameRegex validates policy names: letters, numbers, underscores, hyphens only
 var policyNameRegex = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_-]*$`)
 
@@ -24,13 +31,16 @@ func (s *Server) handleListSnapshotPolicies(w http.ResponseWriter, r *http.Reque
 
 func (s *Server) handleCreateSnapshotPolicy(w http.ResponseWriter, r *http.Request) {
 	var policy store.SnapshotPolicy
-	if err := json.NewDecoder(r.Body).Decode(&policy); err != nil {
-		http.Error(w, "invalid request body", http.StatusBadRequest)
+	if !s.decodeJSON(w, r, &policy) {
 		return
 	}
 
-	if policy.Name == "" || policy.Schedule == "" || policy.Retention == "" {
-		http.Error(w, "name, schedule, and retention are required", http.StatusBadRequest)
+	if policy.Name == "" || policy.Schedule == "" || (policy.Retention == "" && !policy.HasKeepCounts()) {
+		http.Error(w, "name, schedule, and retention or keep counts are required", http.StatusBadRequest)
+		return
+	}
+	if err := validateKeepCounts(policy); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
@@ -40,6 +50,18 @@ func (s *Server) handleCreateSnapshotPolicy(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	schedule, err := scheduler.NormalizeSchedule(policy.Schedule)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	policy.Schedule = schedule
+
+	if err := validateExcludePatterns(policy.Exclude); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	if err := s.snapshotPolicy.Save(&policy); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -49,6 +71,9 @@ func (s *Server) handleCreateSnapshotPolicy(w http.ResponseWriter, r *http.Reque
 	respondJSON(w, http.StatusCreated, policy)
 }
 
+// handleUpdateSnapshotPolicy serves both PUT and PATCH. Only the fields
+// present in the body change, so {"enabled": false} just disables the
+// policy; the scheduler is reloaded afterwards.
 func (s *Server) handleUpdateSnapshotPolicy(w http.ResponseWriter, r *http.Request) {
 	idStr := r.PathValue("id")
 	id, err := strconv.ParseInt(idStr, 10, 64)
@@ -66,14 +91,20 @@ func (s *Server) handleUpdateSnapshotPolicy(w http.ResponseWriter, r *http.Reque
 
 	// Decode partial update
 	var update struct {
-		Name      *string   `json:"name,omitempty"`
-		Schedule  *string   `json:"schedule,omitempty"`
-		Retention *string   `json:"retention,omitempty"`
-		Datasets  *[]string `json:"datasets,omitempty"`
-		Enabled   *bool     `json:"enabled,omitempty"`
+		Name      *string               `json:"name,omitempty"`
+		Schedule  *string               `json:"schedule,omitempty"`
+		Retention *string               `json:"retention,omitempty"`
+		Datasets  *[]store.PolicyTarget `json:"datasets,omitempty"`
+		Recursive *bool                 `json:"recursive,omitempty"`
+		Exclude   *[]string             `json:"exclude,omitempty"`
+		Enabled   *bool                 `json:"enabled,omitempty"`
+
+		KeepLast    *int `json:"keep_last,omitempty"`
+		KeepDaily   *int `json:"keep_daily,omitempty"`
+		KeepWeekly  *int `json:"keep_weekly,omitempty"`
+		KeepMonthly *int `json:"keep_monthly,omitempty"`
 	}
-	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
-		http.Error(w, "invalid request body", http.StatusBadRequest)
+	if !s.decodeJSON(w, r, &update) {
 		return
 	}
 
@@ -86,7 +117,12 @@ func (s *Server) handleUpdateSnapshotPolicy(w http.ResponseWriter, r *http.Reque
 		existing.Name = *update.Name
 	}
 	if update.Schedule != nil {
-		existing.Schedule = *update.Schedule
+		schedule, err := scheduler.NormalizeSchedule(*update.Schedule)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		existing.Schedule = schedule
 	}
 	if update.Retention != nil {
 		existing.Retention = *update.Retention
@@ -94,9 +130,35 @@ func (s *Server) handleUpdateSnapshotPolicy(w http.ResponseWriter, r *http.Reque
 	if update.Datasets != nil {
 		existing.Datasets = *update.Datasets
 	}
+	if update.Recursive != nil {
+		existing.Recursive = *update.Recursive
+	}
+	if update.Exclude != nil {
+		if err := validateExcludePatterns(*update.Exclude); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		existing.Exclude = *update.Exclude
+	}
 	if update.Enabled != nil {
 		existing.Enabled = *update.Enabled
 	}
+	if update.KeepLast != nil {
+		existing.KeepLast = *update.KeepLast
+	}
+	if update.KeepDaily != nil {
+		existing.KeepDaily = *update.KeepDaily
+	}
+	if update.KeepWeekly != nil {
+		existing.KeepWeekly = *update.KeepWeekly
+	}
+	if update.KeepMonthly != nil {
+		existing.KeepMonthly = *update.KeepMonthly
+	}
+	if err := validateKeepCounts(*existing); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
 	if err := s.snapshotPolicy.Update(existing); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -124,9 +186,99 @@ func (s *Server) handleDeleteSnapshotPolicy(w http.ResponseWriter, r *http.Reque
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// validateKeepCounts rejects negative retention counts, and per-dataset
+// overrides that would keep no snapshots at all.
+func validateKeepCounts(policy store.SnapshotPolicy) error {
+	if policy.KeepLast < 0 || policy.KeepDaily < 0 || policy.KeepWeekly < 0 || policy.KeepMonthly < 0 {
+		return fmt.Errorf("keep counts cannot be negative")
+	}
+	for _, t := range policy.Datasets {
+		if t.KeepLast != nil && *t.KeepLast < 1 {
+			return fmt.Errorf("keep_last for %s must be at least 1", t.Dataset)
+		}
+	}
+	return nil
+}
+
+// handleDatasetPolicies returns the snapshot policies that include a dataset.
+func (s *Server) handleDatasetPolicies(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		http.Error(w, "dataset name required in query parameter", http.StatusBadRequest)
+		return
+	}
+
+	policies, err := s.snapshotPolicy.ListForDataset(name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, policies)
+}
+
+// handlePreviewSnapshotSchedule returns the next times a schedule would fire.
+func (s *Server) handlePreviewSnapshotSchedule(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Schedule string `json:"schedule"`
+		Count    int    `json:"count"`
+	}
+	if !s.decodeJSON(w, r, &req) {
+		return
+	}
+	if req.Schedule == "" {
+		http.Error(w, "schedule is required", http.StatusBadRequest)
+		return
+	}
+	if req.Count == 0 {
+		req.Count = defaultPreviewCount
+	}
+
+	times, err := scheduler.PreviewSchedule(req.Schedule, req.Count)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string][]time.Time{"next_runs": times})
+}
+
+// WithPolicyReload sets the function that reschedules snapshot policies
+// from the database, served by POST /api/v1/snapshot-policies/reload.
+func WithPolicyReload(reload func() error) Option {
+	return func(s *Server) {
+		s.reloadPolicies = reload
+	}
+}
+
+// handleReloadSnapshotPolicies reschedules all policies from the database,
+// picking up changes made outside the API.
+func (s *Server) handleReloadSnapshotPolicies(w http.ResponseWriter, r *http.Request) {
+	if s.reloadPolicies == nil {
+		http.Error(w, "snapshot scheduler is not running", http.StatusServiceUnavailable)
+		return
+	}
+	if err := s.reloadPolicies(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
 // notifyPolicyChange calls the onPolicyChange callback if set.
 func (s *Server) notifyPolicyChange() {
 	if s.onPolicyChange != nil {
 		s.onPolicyChange()
 	}
 }
+
+// validateExcludePatterns rejects malformed glob patterns up front, since
+// path.Match only reports them when a dataset is matched.
+func validateExcludePatterns(patterns []string) error {
+	for _, p := range patterns {
+		if _, err := path.Match(p, ""); err != nil {
+			return fmt.Errorf("invalid exclude pattern %q: %w", p, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.aimuz.me/mynt/store"
+)
+
+func TestHandleCreateSnapshotPolicy_Schedule(t *testing.T) {
+	db, err := store.Open(":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	repo := store.NewSnapshotPolicyRepo(db)
+	s := &Server{snapshotPolicy: repo, maxBodyBytes: DefaultMaxBodyBytes}
+
+	create := func(body string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		s.handleCreateSnapshotPolicy(rr, httptest.NewRequest(http.MethodPost, "/api/v1/snapshot-policies", strings.NewReader(body)))
+		return rr
+	}
+
+	rr := create(`{"name": "broken", "schedule": "0 25 * * *", "retention": "7d", "datasets": ["tank/data"]}`)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	require.Contains(t, rr.Body.String(), "invalid schedule")
+	policies, err := repo.List()
+	require.NoError(t, err)
+	require.Empty(t, policies)
+
+	rr = create(`{"name": "nightly", "schedule": "@daily", "retention": "7d", "datasets": ["tank/data"]}`)
+	require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+	var created store.SnapshotPolicy
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&created))
+	require.Equal(t, "0 0 0 * * *", created.Schedule)
+
+	stored, err := repo.Get(created.ID)
+	require.NoError(t, err)
+	require.Equal(t, "0 0 0 * * *", stored.Schedule)
+}
+
+func TestHandleUpdateSnapshotPolicy_Patch(t *testing.T) {
+	db, err := store.Open(":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	repo := store.NewSnapshotPolicyRepo(db)
+	reloads := 0
+	s := &Server{snapshotPolicy: repo, maxBodyBytes: DefaultMaxBodyBytes, onPolicyChange: func() { reloads++ }}
+
+	policy := store.SnapshotPolicy{
+		Name:      "nightly",
+		Schedule:  "0 0 0 * * *",
+		Retention: "7d",
+		Datasets:  []store.PolicyTarget{{Dataset: "tank/data"}, {Dataset: "tank/home"}},
+		Enabled:   true,
+	}
+	require.NoError(t, repo.Save(&policy))
+
+	patch := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPatch, "/api/v1/snapshot-policies/"+strconv.FormatInt(policy.ID, 10), strings.NewReader(body))
+		req.SetPathValue("id", strconv.FormatInt(policy.ID, 10))
+		rr := httptest.NewRecorder()
+		s.handleUpdateSnapshotPolicy(rr, req)
+		return rr
+	}
+
+	rr := patch(`{"enabled": false}`)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	require.Equal(t, 1, reloads)
+
+	stored, err := repo.Get(policy.ID)
+	require.NoError(t, err)
+	require.False(t, stored.Enabled)
+	require.Equal(t, policy.Name, stored.Name)
+	require.Equal(t, policy.Schedule, stored.Schedule)
+	require.Equal(t, policy.Retention, stored.Retention)
+	require.Equal(t, policy.Datasets, stored.Datasets)
+
+	// A bad field rejects the whole patch without reloading
+	rr = patch(`{"enabled": true, "schedule": "0 25 * * *"}`)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	require.Equal(t, 1, reloads)
+	stored, err = repo.Get(policy.ID)
+	require.NoError(t, err)
+	require.False(t, stored.Enabled)
+}
+
+func TestHandleReloadSnapshotPolicies(t *testing.T) {
+	reload := func(s *Server) int {
+		rr := httptest.NewRecorder()
+		s.handleReloadSnapshotPolicies(rr, httptest.NewRequest(http.MethodPost, "/api/v1/snapshot-policies/reload", nil))
+		return rr.Code
+	}
+
+	require.Equal(t, http.StatusServiceUnavailable, reload(&Server{}))
+
+	calls := 0
+	s := &Server{}
+	WithPolicyReload(func() error { calls++; return nil })(s)
+	require.Equal(t, http.StatusNoContent, reload(s))
+	require.Equal(t, 1, calls)
+}
@@ -0,0 +1,59 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Subsystem names a group of routes backed by one external tool set.
+type Subsystem string
+
+const (
+	SubsystemZFS    Subsystem = "zfs"    // zpool and zfs
+	SubsystemShares Subsystem = "shares" // Samba and NFS
+	SubsystemDisks  Subsystem = "disks"  // lsblk, smartctl and ledctl
+)
+
+// subsystemRoutes maps API path prefixes to the subsystem serving them.
+var subsystemRoutes = map[string]Subsystem{
+	"/api/v1/pools":             SubsystemZFS,
+	"/api/v1/datasets":          SubsystemZFS,
+	"/api/v1/dataset-info":      SubsystemZFS,
+	"/api/v1/snapshots":         SubsystemZFS,
+	"/api/v1/snapshot-policies": SubsystemZFS,
+	"/api/v1/zfs":               SubsystemZFS,
+	"/api/v1/shares":            SubsystemShares,
+	"/api/v1/disks":             SubsystemDisks,
+}
+
+// WithDisabled switches off subsystems whose tools are not installed.
+// Their routes respond 501 Not Implemented.
+func WithDisabled(subsystems ...Subsystem) Option {
+	return func(s *Server) {
+		if s.disabled == nil {
+			s.disabled = make(map[Subsystem]bool)
+		}
+		for _, sub := range subsystems {
+			s.disabled[sub] = true
+		}
+	}
+}
+
+// disabledSubsystem returns the disabled subsystem serving path, if any.
+func (s *Server) disabledSubsystem(path string) (Subsystem, bool) {
+	if len(s.disabled) == 0 {
+		return "", false
+	}
+	for prefix, sub := range subsystemRoutes {
+		if path == prefix || strings.HasPrefix(path, prefix+"/") {
+			return sub, s.disabled[sub]
+		}
+	}
+	return "", false
+}
+
+// notImplemented replies 501 for a route of a disabled subsystem.
+func notImplemented(w http.ResponseWriter, sub Subsystem) {
+	http.Error(w, fmt.Sprintf("%s subsystem is disabled on this server", sub), http.StatusNotImplemented)
+}
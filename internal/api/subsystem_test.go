@@ -0,0 +1,40 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDisabledSubsystem(t *testing.T) {
+	s := authServer(WithDisabled(SubsystemZFS))
+	s.mux.HandleFunc("GET /api/v1/shares", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	tests := []struct {
+		method string
+		path   string
+		want   int
+	}{
+		{http.MethodGet, "/api/v1/pools", http.StatusNotImplemented},
+		{http.MethodPost, "/api/v1/pools", http.StatusNotImplemented},
+		{http.MethodGet, "/api/v1/datasets/tank/data", http.StatusNotImplemented},
+		{http.MethodGet, "/api/v1/dataset-info/note", http.StatusNotImplemented},
+		{http.MethodGet, "/api/v1/snapshot-policies", http.StatusNotImplemented},
+		{http.MethodGet, "/api/v1/shares", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			s.ServeHTTP(rr, httptest.NewRequest(tt.method, tt.path, nil))
+			require.Equal(t, tt.want, rr.Code)
+			if tt.want == http.StatusNotImplemented {
+				require.Contains(t, rr.Body.String(), "zfs subsystem is disabled")
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.aimuz.me/mynt/internal/version"
+)
+
+func TestHandleVersion(t *testing.T) {
+	old := version.Get()
+	version.Version, version.Commit, version.BuildDate = "v1.2.3", "abc1234", "2025-01-02T03:04:05Z"
+	t.Cleanup(func() {
+		version.Version, version.Commit, version.BuildDate = old.Version, old.Commit, old.BuildDate
+	})
+
+	// Unauthenticated requests are served
+	s := authServer()
+	s.mux.HandleFunc("GET /api/v1/version", s.handleVersion)
+
+	rr := httptest.NewRecorder()
+	s.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/version", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var got version.Info
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&got))
+	require.Equal(t, version.Info{Version: "v1.2.3", Commit: "abc1234", BuildDate: "2025-01-02T03:04:05Z"}, got)
+}
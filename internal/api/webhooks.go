@@ -0,0 +1,234 @@
+package api
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"go.aimuz.me/mynt/notify"
+	"go.aimuz.me/mynt/store"
+)
+
+// defaultWebhookDeliveries is how many log entries the deliveries endpoint
+// returns unless the request asks for a limit.
+const defaultWebhookDeliveries = 20
+
+// WithWebhooks enables managing the signed webhooks stored in repo.
+// Without it the webhook routes answer 503.
+func WithWebhooks(repo *store.WebhookRepo) Option {
+	return func(s *Server) {
+		s.webhooks = repo
+	}
+}
+
+// WithWebhookChanges sets a function called after a webhook is created,
+// updated or deleted, such as notify.Dispatcher.InvalidateWebhooks.
+func WithWebhookChanges(fn func()) Option {
+	return func(s *Server) {
+		s.onWebhookChange = fn
+	}
+}
+
+// webhooksChanged calls the onWebhookChange callback if set.
+func (s *Server) webhooksChanged() {
+	if s.onWebhookChange != nil {
+		s.onWebhookChange()
+	}
+}
+
+// webhooksEnabled reports whether webhooks are configured, answering 503
+// if not.
+func (s *Server) webhooksEnabled(w http.ResponseWriter) bool {
+	if s.webhooks == nil {
+		http.Error(w, "webhooks are not enabled", http.StatusServiceUnavailable)
+		return false
+	}
+	return true
+}
+
+// webhookID parses the {id} path value, answering 400 if it is invalid.
+func webhookID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid webhook ID", http.StatusBadRequest)
+		return 0, false
+	}
+	return id, true
+}
+
+// getWebhook loads the webhook named by the {id} path value, answering
+// 400, 404 or 500 if it cannot.
+func (s *Server) getWebhook(w http.ResponseWriter, r *http.Request) (*store.Webhook, bool) {
+	id, ok := webhookID(w, r)
+	if !ok {
+		return nil, false
+	}
+	hook, err := s.webhooks.Get(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil, false
+	}
+	if hook == nil {
+		http.Error(w, "webhook not found", http.StatusNotFound)
+		return nil, false
+	}
+	return hook, true
+}
+
+// generateWebhookSecret returns a random 256-bit key in hex.
+func generateWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// handleListWebhooks returns the webhooks with their last delivery
+// status. Secrets are not returned.
+func (s *Server) handleListWebhooks(w http.ResponseWriter, r *http.Request) {
+	if !s.webhooksEnabled(w) {
+		return
+	}
+	hooks, err := s.webhooks.List()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	for i := range hooks {
+		hooks[i].Secret = ""
+	}
+	respondJSON(w, http.StatusOK, hooks)
+}
+
+func (s *Server) handleGetWebhook(w http.ResponseWriter, r *http.Request) {
+	if !s.webhooksEnabled(w) {
+		return
+	}
+	hook, ok := s.getWebhook(w, r)
+	if !ok {
+		return
+	}
+	hook.Secret = ""
+	respondJSON(w, http.StatusOK, hook)
+}
+
+// handleCreateWebhook adds a webhook. Without a secret in the request one
+// is generated; either way the response is the only time it is returned.
+func (s *Server) handleCreateWebhook(w http.ResponseWriter, r *http.Request) {
+	if !s.webhooksEnabled(w) {
+		return
+	}
+
+	var hook store.Webhook
+	if !s.decodeJSON(w, r, &hook) {
+		return
+	}
+	if err := notify.ValidateWebhook(&hook); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if hook.Secret == "" {
+		secret, err := generateWebhookSecret()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		hook.Secret = secret
+	}
+
+	if err := s.webhooks.Save(&hook); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	s.webhooksChanged()
+	respondJSON(w, http.StatusCreated, hook)
+}
+
+// handleUpdateWebhook replaces a webhook's settings with the request body.
+// An empty secret keeps the current one.
+func (s *Server) handleUpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	if !s.webhooksEnabled(w) {
+		return
+	}
+	current, ok := s.getWebhook(w, r)
+	if !ok {
+		return
+	}
+
+	var hook store.Webhook
+	if !s.decodeJSON(w, r, &hook) {
+		return
+	}
+	hook.ID = current.ID
+	if err := notify.ValidateWebhook(&hook); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if hook.Secret == "" {
+		hook.Secret = current.Secret
+	}
+
+	if err := s.webhooks.Update(&hook); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, "webhook not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	s.webhooksChanged()
+	hook.Secret = ""
+	hook.LastStatus, hook.LastError, hook.LastDeliveryAt = current.LastStatus, current.LastError, current.LastDeliveryAt
+	hook.CreatedAt = current.CreatedAt
+	respondJSON(w, http.StatusOK, hook)
+}
+
+func (s *Server) handleDeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	if !s.webhooksEnabled(w) {
+		return
+	}
+	id, ok := webhookID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := s.webhooks.Delete(id); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	s.webhooksChanged()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleListWebhookDeliveries returns a webhook's latest deliveries,
+// newest first. The limit query parameter picks how many.
+func (s *Server) handleListWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	if !s.webhooksEnabled(w) {
+		return
+	}
+	hook, ok := s.getWebhook(w, r)
+	if !ok {
+		return
+	}
+
+	limit := defaultWebhookDeliveries
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			http.Error(w, "limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+
+	deliveries, err := s.webhooks.Deliveries(hook.ID, limit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	respondJSON(w, http.StatusOK, deliveries)
+}
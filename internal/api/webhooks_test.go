@@ -0,0 +1,77 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.aimuz.me/mynt/store"
+)
+
+func TestHandleWebhooks(t *testing.T) {
+	db, err := store.Open(":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	repo := store.NewWebhookRepo(db)
+	var changes int
+	s := &Server{webhooks: repo, maxBodyBytes: DefaultMaxBodyBytes, onWebhookChange: func() { changes++ }}
+
+	create := func(body string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		s.handleCreateWebhook(rr, httptest.NewRequest(http.MethodPost, "/api/v1/webhooks", strings.NewReader(body)))
+		return rr
+	}
+	require.Equal(t, http.StatusBadRequest, create(`{"name": "bad", "url": "ftp://example.com"}`).Code)
+
+	// The generated secret is returned once
+	rr := create(`{"name": "incidents", "url": "https://hooks.example.com/x", "enabled": true}`)
+	require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+	var created store.Webhook
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&created))
+	require.Len(t, created.Secret, 64)
+
+	rr = httptest.NewRecorder()
+	s.handleListWebhooks(rr, httptest.NewRequest(http.MethodGet, "/api/v1/webhooks", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.NotContains(t, rr.Body.String(), created.Secret)
+
+	// Updating without a secret keeps it
+	id := strconv.FormatInt(created.ID, 10)
+	rr = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/webhooks/"+id, strings.NewReader(`{"name": "incidents", "url": "https://hooks.example.com/y", "min_severity": "critical"}`))
+	req.SetPathValue("id", id)
+	s.handleUpdateWebhook(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	got, err := repo.Get(created.ID)
+	require.NoError(t, err)
+	require.Equal(t, created.Secret, got.Secret)
+	require.Equal(t, "https://hooks.example.com/y", got.URL)
+	require.Equal(t, 2, changes)
+
+	require.NoError(t, repo.RecordDelivery(&store.WebhookDelivery{WebhookID: created.ID, EventType: "pool.degraded", Status: 200, Attempts: 1}))
+	rr = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/webhooks/"+id+"/deliveries", nil)
+	req.SetPathValue("id", id)
+	s.handleListWebhookDeliveries(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+	var deliveries []store.WebhookDelivery
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&deliveries))
+	require.Len(t, deliveries, 1)
+
+	rr = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/webhooks/999/deliveries", nil)
+	req.SetPathValue("id", "999")
+	s.handleListWebhookDeliveries(rr, req)
+	require.Equal(t, http.StatusNotFound, rr.Code)
+
+	rr = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodDelete, "/api/v1/webhooks/"+id, nil)
+	req.SetPathValue("id", id)
+	s.handleDeleteWebhook(rr, req)
+	require.Equal(t, http.StatusNoContent, rr.Code)
+	require.Equal(t, 3, changes)
+}
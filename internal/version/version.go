@@ -0,0 +1,32 @@
+// Package version holds build information injected at link time, e.g.
+//
+//	go build -ldflags "-X go.aimuz.me/mynt/internal/version.Version=v0.1.0 \
+//		-X go.aimuz.me/mynt/internal/version.Commit=$(git rev-parse --short HEAD) \
+//		-X go.aimuz.me/mynt/internal/version.BuildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+package version
+
+import "fmt"
+
+// Build information, set via -ldflags.
+var (
+	Version   = "dev"
+	Commit    = "unknown"
+	BuildDate = "unknown"
+)
+
+// Info describes a build.
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildDate string `json:"build_date"`
+}
+
+// Get returns the build information of the running binary.
+func Get() Info {
+	return Info{Version: Version, Commit: Commit, BuildDate: BuildDate}
+}
+
+// String formats the build information for humans.
+func (i Info) String() string {
+	return fmt.Sprintf("%s (commit %s, built %s)", i.Version, i.Commit, i.BuildDate)
+}
@@ -3,8 +3,10 @@ package monitor
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
+	"go.aimuz.me/mynt/alert"
 	"go.aimuz.me/mynt/disk"
 	"go.aimuz.me/mynt/event"
 	"go.aimuz.me/mynt/logger"
@@ -13,9 +15,10 @@ import (
 
 // DiskScanner monitors disk changes (fast, runs frequently).
 type DiskScanner struct {
-	bus     *event.Bus
-	repo    *store.DiskRepo
-	diskMgr *disk.Manager
+	bus      *event.Bus
+	repo     *store.DiskRepo
+	diskMgr  *disk.Manager
+	readOnly readOnlyTracker
 }
 
 // NewDiskScanner creates a disk scanner that publishes to the event bus.
@@ -58,6 +61,11 @@ func (s *DiskScanner) Scan(ctx context.Context) error {
 		}
 	}
 
+	for _, d := range s.readOnly.update(current) {
+		logger.Warn("disk became read-only", "disk", d.Name, "serial", d.Serial)
+		s.bus.Publish(event.Event{Type: event.DiskReadOnly, Data: d})
+	}
+
 	for serial, d := range knownMap {
 		if _, exists := currentMap[serial]; !exists {
 			s.bus.Publish(event.Event{Type: event.DiskRemoved, Data: d.ToInfo()})
@@ -73,30 +81,76 @@ func (s *DiskScanner) Scan(ctx context.Context) error {
 	return nil
 }
 
+// readOnlyTracker remembers each disk's read-only flag between scans.
+type readOnlyTracker struct {
+	last map[string]bool // serial -> read-only
+}
+
+// update records the current flags and returns the disks that were
+// writable on the previous scan but are read-only now.
+func (t *readOnlyTracker) update(disks []disk.Info) []disk.Info {
+	next := make(map[string]bool, len(disks))
+	var changed []disk.Info
+	for _, d := range disks {
+		next[d.Serial] = d.ReadOnly
+		if wasRO, seen := t.last[d.Serial]; seen && !wasRO && d.ReadOnly {
+			changed = append(changed, d)
+		}
+	}
+	t.last = next
+	return changed
+}
+
 // SmartScanner collects SMART data (slow, runs less frequently).
 type SmartScanner struct {
 	bus        *event.Bus
 	repo       *store.DiskRepo
 	diskMgr    *disk.Manager
 	lastUpdate time.Time
-	interval   time.Duration
+	predictor  *failurePredictor
+	alerts     *alert.Evaluator
+
+	mu       sync.Mutex
+	interval time.Duration
 }
 
 // NewSmartScanner creates a SMART data collector.
 // interval specifies how often to actually collect SMART data.
 func NewSmartScanner(bus *event.Bus, repo *store.DiskRepo, diskMgr *disk.Manager, interval time.Duration) *SmartScanner {
 	return &SmartScanner{
-		bus:      bus,
-		repo:     repo,
-		diskMgr:  diskMgr,
-		interval: interval,
+		bus:       bus,
+		repo:      repo,
+		diskMgr:   diskMgr,
+		interval:  interval,
+		predictor: newFailurePredictor(),
 	}
 }
 
+// Interval returns how often SMART data is actually collected.
+func (s *SmartScanner) Interval() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.interval
+}
+
+// SetInterval changes how often SMART data is collected. It takes effect
+// on the next scan.
+func (s *SmartScanner) SetInterval(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.interval = d
+}
+
+// SetAlerts checks the temperature of every disk sampled against the
+// rules of alerts. It must be called before the scanner runs.
+func (s *SmartScanner) SetAlerts(alerts *alert.Evaluator) {
+	s.alerts = alerts
+}
+
 // Scan collects SMART data for all attached disks.
 func (s *SmartScanner) Scan(ctx context.Context) error {
 	// Check if enough time has passed since last update
-	if time.Since(s.lastUpdate) < s.interval {
+	if time.Since(s.lastUpdate) < s.Interval() {
 		return nil
 	}
 
@@ -105,9 +159,13 @@ func (s *SmartScanner) Scan(ctx context.Context) error {
 		return fmt.Errorf("smart scan: %w", err)
 	}
 
+	names := make([]string, 0, len(disks))
 	for _, d := range disks {
 		s.collectSmart(ctx, d.Name)
+		names = append(names, d.Name)
 	}
+	s.predictor.retain(names)
+	s.alerts.Retain(alert.DiskTemp, names)
 
 	// Only update timestamp after successful collection
 	// This allows quick retry on transient failures
@@ -135,4 +193,14 @@ func (s *SmartScanner) collectSmart(ctx context.Context, name string) {
 			Data: map[string]any{"disk": name, "report": report},
 		})
 	}
+
+	if report.Temperature > 0 {
+		s.alerts.Observe(alert.DiskTemp, name, float64(report.Temperature))
+	}
+
+	if p := s.predictor.observe(name, report.ReallocatedSectors, report.PendingSectors); p != nil {
+		logger.Warn("disk failure predicted", "disk", name, "confidence", p.Confidence,
+			"increases", p.Increases, "samples", p.Samples)
+		s.bus.Publish(event.Event{Type: event.DiskPredictedFailure, Data: p})
+	}
 }
@@ -0,0 +1,36 @@
+package monitor
+
+import (
+	"testing"
+
+	"go.aimuz.me/mynt/disk"
+)
+
+func TestReadOnlyTracker(t *testing.T) {
+	var tr readOnlyTracker
+
+	// First scan establishes a baseline; an already read-only disk is not a transition
+	first := []disk.Info{
+		{Name: "sda", Serial: "A"},
+		{Name: "sdb", Serial: "B", ReadOnly: true},
+	}
+	if got := tr.update(first); len(got) != 0 {
+		t.Fatalf("first scan transitions = %v, want none", got)
+	}
+
+	// sda goes read-only, sdb stays read-only, sdc is new and read-only
+	second := []disk.Info{
+		{Name: "sda", Serial: "A", ReadOnly: true},
+		{Name: "sdb", Serial: "B", ReadOnly: true},
+		{Name: "sdc", Serial: "C", ReadOnly: true},
+	}
+	got := tr.update(second)
+	if len(got) != 1 || got[0].Serial != "A" {
+		t.Fatalf("second scan transitions = %v, want only sda", got)
+	}
+
+	// No further change, no repeated event
+	if got := tr.update(second); len(got) != 0 {
+		t.Errorf("third scan transitions = %v, want none", got)
+	}
+}
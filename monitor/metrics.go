@@ -0,0 +1,134 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.aimuz.me/mynt/logger"
+	"go.aimuz.me/mynt/store"
+	"go.aimuz.me/mynt/sysinfo"
+)
+
+const (
+	// DefaultMetricsRetention is how long metric samples are kept.
+	DefaultMetricsRetention = 7 * 24 * time.Hour
+	// MetricsResolution is the interval between two metric samples.
+	MetricsResolution = time.Minute
+
+	// metricsPruneInterval is how often samples past retention are removed.
+	metricsPruneInterval = time.Hour
+)
+
+// StatsSource provides current system statistics.
+type StatsSource interface {
+	Collect() (*sysinfo.Stats, error)
+}
+
+// MetricsScanner records CPU, memory, network and disk I/O samples for
+// the history graphs (throttled to MetricsResolution internally). Rates
+// are averaged over the time since the previous sample, so the source
+// must not be shared with other callers of Collect.
+type MetricsScanner struct {
+	repo       *store.MetricsRepo
+	source     StatsSource
+	retention  time.Duration
+	lastSample time.Time
+	lastPrune  time.Time
+}
+
+// NewMetricsScanner creates a scanner that stores samples from source in
+// repo and keeps them for retention.
+func NewMetricsScanner(repo *store.MetricsRepo, source StatsSource, retention time.Duration) *MetricsScanner {
+	if retention <= 0 {
+		retention = DefaultMetricsRetention
+	}
+	return &MetricsScanner{
+		repo:      repo,
+		source:    source,
+		retention: retention,
+	}
+}
+
+// Scan records a sample if MetricsResolution has elapsed since the last.
+func (s *MetricsScanner) Scan(ctx context.Context) error {
+	now := time.Now()
+	if now.Sub(s.lastSample) < MetricsResolution {
+		return nil
+	}
+
+	stats, err := s.source.Collect()
+	if err != nil {
+		return fmt.Errorf("collect metrics: %w", err)
+	}
+	// The first collect has no previous counters to compute rates from
+	if !s.lastSample.IsZero() {
+		if err := s.repo.Add(now, metricValues(stats)); err != nil {
+			return fmt.Errorf("save metrics: %w", err)
+		}
+	}
+	s.lastSample = now
+
+	if now.Sub(s.lastPrune) >= metricsPruneInterval {
+		n, err := s.repo.Prune(now.Add(-s.retention))
+		if err != nil {
+			return fmt.Errorf("prune metrics: %w", err)
+		}
+		if n > 0 {
+			logger.Debug("pruned metric samples", "count", n)
+		}
+		s.lastPrune = now
+	}
+	return nil
+}
+
+// metricValues reduces stats to one value per metric, summing rates over
+// network interfaces and disks.
+func metricValues(stats *sysinfo.Stats) map[string]float64 {
+	values := map[string]float64{
+		store.MetricCPU:    stats.CPU.Total,
+		store.MetricMemory: stats.Memory.Percent,
+	}
+	var netIn, netOut, diskRead, diskWrite float64
+	for _, n := range stats.Network {
+		netIn += n.SpeedIn
+		netOut += n.SpeedOut
+	}
+	devices := make(map[string]bool, len(stats.DiskIO))
+	for _, d := range stats.DiskIO {
+		devices[d.Device] = true
+	}
+	for _, d := range stats.DiskIO {
+		if !physicalDisk(d.Device, devices) {
+			continue
+		}
+		diskRead += d.ReadSpeed
+		diskWrite += d.WriteSpeed
+	}
+	values[store.MetricNetIn] = netIn
+	values[store.MetricNetOut] = netOut
+	values[store.MetricDiskRead] = diskRead
+	values[store.MetricDiskWrite] = diskWrite
+	return values
+}
+
+// stackedDevicePrefixes name block devices whose I/O is also counted on
+// the disks beneath them: loop devices, zvols, device mapper and md RAID.
+var stackedDevicePrefixes = []string{"loop", "zd", "dm-", "md", "ram"}
+
+// physicalDisk reports whether name is a whole physical disk rather than
+// a partition of another device in devices (sda1, nvme0n1p1) or a
+// stacked device, so summing I/O over disks counts each byte once.
+func physicalDisk(name string, devices map[string]bool) bool {
+	for _, p := range stackedDevicePrefixes {
+		if strings.HasPrefix(name, p) {
+			return false
+		}
+	}
+	base := strings.TrimRight(name, "0123456789")
+	if base == name {
+		return true
+	}
+	return !devices[base] && !devices[strings.TrimSuffix(base, "p")]
+}
@@ -0,0 +1,74 @@
+package monitor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.aimuz.me/mynt/store"
+	"go.aimuz.me/mynt/sysinfo"
+)
+
+type staticStats struct{ stats sysinfo.Stats }
+
+func (s staticStats) Collect() (*sysinfo.Stats, error) { return &s.stats, nil }
+
+func TestMetricValues(t *testing.T) {
+	stats := &sysinfo.Stats{
+		CPU:     sysinfo.CPUStats{Total: 12.5},
+		Memory:  sysinfo.MemStats{Percent: 40},
+		Network: []sysinfo.NetStats{{Name: "eth0", SpeedIn: 100, SpeedOut: 10}, {Name: "eth1", SpeedIn: 50}},
+		DiskIO: []sysinfo.DiskIO{
+			{Device: "sda", ReadSpeed: 1000, WriteSpeed: 200},
+			{Device: "sda1", ReadSpeed: 1000, WriteSpeed: 200}, // partition of sda
+			{Device: "nvme0n1", ReadSpeed: 500},
+			{Device: "nvme0n1p2", ReadSpeed: 500},
+			{Device: "zd0", WriteSpeed: 300}, // zvol, counted on its pool's disks
+			{Device: "dm-0", ReadSpeed: 700},
+		},
+	}
+
+	got := metricValues(stats)
+	want := map[string]float64{
+		store.MetricCPU:       12.5,
+		store.MetricMemory:    40,
+		store.MetricNetIn:     150,
+		store.MetricNetOut:    10,
+		store.MetricDiskRead:  1500,
+		store.MetricDiskWrite: 200,
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("%s = %v, want %v", k, got[k], v)
+		}
+	}
+}
+
+func TestMetricsScanner_Throttle(t *testing.T) {
+	db, err := store.Open(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	repo := store.NewMetricsRepo(db)
+	s := NewMetricsScanner(repo, staticStats{sysinfo.Stats{CPU: sysinfo.CPUStats{Total: 5}}}, 0)
+
+	// The first scan only primes the rate counters, the second is too soon
+	for range 2 {
+		if err := s.Scan(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s.lastSample = s.lastSample.Add(-MetricsResolution)
+	if err := s.Scan(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	points, err := repo.Series(store.MetricCPU, time.Now().Add(-time.Hour), time.Now().Add(time.Minute), 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(points) != 1 || points[0].Value != 5 {
+		t.Errorf("points = %+v, want one sample of 5", points)
+	}
+}
@@ -18,9 +18,12 @@ type Scanner interface {
 // Monitor coordinates all system scanners.
 type Monitor struct {
 	scanners []Scanner
-	interval time.Duration
 	cancel   context.CancelFunc
 	wg       sync.WaitGroup
+
+	mu       sync.Mutex
+	interval time.Duration
+	reset    chan struct{} // signals the run loop to pick up a new interval
 }
 
 // New creates a new monitor with the given scanners and interval.
@@ -28,6 +31,27 @@ func New(scanners []Scanner, interval time.Duration) *Monitor {
 	return &Monitor{
 		scanners: scanners,
 		interval: interval,
+		reset:    make(chan struct{}, 1),
+	}
+}
+
+// Interval returns how often the scanners are run.
+func (m *Monitor) Interval() time.Duration {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.interval
+}
+
+// SetInterval changes how often the scanners are run. A running monitor
+// restarts its ticker, so the next scan is one new interval away.
+func (m *Monitor) SetInterval(d time.Duration) {
+	m.mu.Lock()
+	m.interval = d
+	m.mu.Unlock()
+
+	select {
+	case m.reset <- struct{}{}:
+	default: // a reset is already pending and will read the new interval
 	}
 }
 
@@ -35,7 +59,7 @@ func New(scanners []Scanner, interval time.Duration) *Monitor {
 func (m *Monitor) Start(ctx context.Context) {
 	ctx, m.cancel = context.WithCancel(ctx)
 
-	logger.Info("monitoring started", "scanners", len(m.scanners), "interval", m.interval)
+	logger.Info("monitoring started", "scanners", len(m.scanners), "interval", m.Interval())
 
 	m.wg.Go(func() {
 		m.run(ctx)
@@ -51,7 +75,7 @@ func (m *Monitor) Stop() {
 }
 
 func (m *Monitor) run(ctx context.Context) {
-	ticker := time.NewTicker(m.interval)
+	ticker := time.NewTicker(m.Interval())
 	defer ticker.Stop()
 
 	// Run immediately on start
@@ -63,6 +87,8 @@ func (m *Monitor) run(ctx context.Context) {
 			return
 		case <-ticker.C:
 			m.scan(ctx)
+		case <-m.reset:
+			ticker.Reset(m.Interval())
 		}
 	}
 }
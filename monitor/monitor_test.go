@@ -0,0 +1,51 @@
+package monitor
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingScanner counts how often it is scanned.
+type countingScanner struct {
+	n atomic.Int64
+}
+
+func (s *countingScanner) Scan(context.Context) error {
+	s.n.Add(1)
+	return nil
+}
+
+func TestMonitor_SetInterval(t *testing.T) {
+	sc := &countingScanner{}
+	m := New([]Scanner{sc}, time.Hour)
+	m.Start(context.Background())
+	defer m.Stop()
+
+	// Only the immediate scan runs at an hourly interval
+	waitForScans(t, sc, 1)
+	time.Sleep(50 * time.Millisecond)
+	if n := sc.n.Load(); n != 1 {
+		t.Fatalf("scans at 1h interval = %d, want 1", n)
+	}
+
+	m.SetInterval(5 * time.Millisecond)
+	if got := m.Interval(); got != 5*time.Millisecond {
+		t.Errorf("Interval() = %s, want 5ms", got)
+	}
+	waitForScans(t, sc, 5)
+}
+
+// waitForScans fails the test if sc has not been scanned n times within a
+// generous deadline.
+func waitForScans(t *testing.T, sc *countingScanner, n int64) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for sc.n.Load() < n {
+		if time.Now().After(deadline) {
+			t.Fatalf("scans = %d after 5s, want at least %d", sc.n.Load(), n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
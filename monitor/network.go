@@ -0,0 +1,104 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+
+	"go.aimuz.me/mynt/event"
+	"go.aimuz.me/mynt/logger"
+	"go.aimuz.me/mynt/sysinfo"
+)
+
+// networkErrorThreshold is how many new receive and transmit errors an
+// interface may accumulate between scans before an event is published.
+// A few errors are normal; a steady climb points at a bad cable or driver.
+const networkErrorThreshold = 10
+
+// InterfaceSource provides cumulative network interface counters.
+type InterfaceSource interface {
+	Interfaces() ([]sysinfo.NetStats, error)
+}
+
+// NetworkScanner watches network interfaces for climbing error counts.
+type NetworkScanner struct {
+	bus    *event.Bus
+	source InterfaceSource
+	errors netErrorTracker
+}
+
+// NewNetworkScanner creates a network scanner that publishes to the event bus.
+func NewNetworkScanner(bus *event.Bus, source InterfaceSource) *NetworkScanner {
+	return &NetworkScanner{
+		bus:    bus,
+		source: source,
+	}
+}
+
+// NetworkErrors describes new errors and drops on an interface since the
+// last scan.
+type NetworkErrors struct {
+	Interface string `json:"interface"`
+	RxErrors  uint64 `json:"rx_errors"`
+	TxErrors  uint64 `json:"tx_errors"`
+	RxDropped uint64 `json:"rx_dropped"`
+	TxDropped uint64 `json:"tx_dropped"`
+}
+
+// Scan checks interface counters and publishes events.
+func (s *NetworkScanner) Scan(ctx context.Context) error {
+	stats, err := s.source.Interfaces()
+	if err != nil {
+		return fmt.Errorf("network scan: %w", err)
+	}
+
+	for _, e := range s.errors.update(stats) {
+		logger.Warn("network interface errors increased", "interface", e.Interface,
+			"rx_errors", e.RxErrors, "tx_errors", e.TxErrors,
+			"rx_dropped", e.RxDropped, "tx_dropped", e.TxDropped)
+		s.bus.Publish(event.Event{Type: event.NetworkErrors, Data: e})
+	}
+	return nil
+}
+
+// netErrorTracker remembers each interface's counters between scans.
+type netErrorTracker struct {
+	last     map[string]sysinfo.NetStats
+	reported map[string]bool // interfaces whose errors are still climbing since reported
+}
+
+// update records the current counters and returns the interfaces whose
+// errors grew by at least networkErrorThreshold since the previous scan.
+// The first scan of an interface, and a scan after its counters were
+// reset, only establish a baseline. An interface is reported once while
+// its errors keep climbing, and again only after a quiet scan, so a bad
+// cable does not publish an event on every scan.
+func (t *netErrorTracker) update(stats []sysinfo.NetStats) []NetworkErrors {
+	next := make(map[string]sysinfo.NetStats, len(stats))
+	reported := make(map[string]bool)
+	var climbed []NetworkErrors
+	for _, s := range stats {
+		next[s.Name] = s
+		prev, seen := t.last[s.Name]
+		if !seen || s.RxErrors < prev.RxErrors || s.TxErrors < prev.TxErrors ||
+			s.RxDropped < prev.RxDropped || s.TxDropped < prev.TxDropped {
+			continue
+		}
+		e := NetworkErrors{
+			Interface: s.Name,
+			RxErrors:  s.RxErrors - prev.RxErrors,
+			TxErrors:  s.TxErrors - prev.TxErrors,
+			RxDropped: s.RxDropped - prev.RxDropped,
+			TxDropped: s.TxDropped - prev.TxDropped,
+		}
+		if e.RxErrors+e.TxErrors < networkErrorThreshold {
+			continue
+		}
+		if !t.reported[s.Name] {
+			climbed = append(climbed, e)
+		}
+		reported[s.Name] = true
+	}
+	t.last = next
+	t.reported = reported
+	return climbed
+}
@@ -0,0 +1,62 @@
+package monitor
+
+import (
+	"testing"
+
+	"go.aimuz.me/mynt/sysinfo"
+)
+
+func TestNetErrorTracker(t *testing.T) {
+	var tr netErrorTracker
+
+	// First scan establishes a baseline, however high the counters are
+	first := []sysinfo.NetStats{
+		{Name: "eth0", RxErrors: 500, TxErrors: 20},
+		{Name: "eth1"},
+	}
+	if got := tr.update(first); len(got) != 0 {
+		t.Fatalf("first scan = %v, want none", got)
+	}
+
+	// eth0 gains a couple of errors, eth1 climbs past the threshold
+	second := []sysinfo.NetStats{
+		{Name: "eth0", RxErrors: 502, TxErrors: 20, RxDropped: 50},
+		{Name: "eth1", RxErrors: 8, TxErrors: 4, RxDropped: 3, TxDropped: 1},
+	}
+	got := tr.update(second)
+	want := NetworkErrors{Interface: "eth1", RxErrors: 8, TxErrors: 4, RxDropped: 3, TxDropped: 1}
+	if len(got) != 1 || got[0] != want {
+		t.Fatalf("second scan = %+v, want [%+v]", got, want)
+	}
+
+	// eth1 keeps climbing but was already reported
+	third := []sysinfo.NetStats{
+		{Name: "eth0", RxErrors: 502, TxErrors: 20, RxDropped: 50},
+		{Name: "eth1", RxErrors: 30, TxErrors: 4, RxDropped: 3, TxDropped: 1},
+	}
+	if got := tr.update(third); len(got) != 0 {
+		t.Fatalf("third scan = %v, want none", got)
+	}
+
+	// After a quiet scan, a new climb is reported again
+	fourth := third
+	if got := tr.update(fourth); len(got) != 0 {
+		t.Fatalf("fourth scan = %v, want none", got)
+	}
+	fifth := []sysinfo.NetStats{
+		{Name: "eth0", RxErrors: 502, TxErrors: 20, RxDropped: 50},
+		{Name: "eth1", RxErrors: 45, TxErrors: 4, RxDropped: 3, TxDropped: 1},
+	}
+	if got := tr.update(fifth); len(got) != 1 || got[0].Interface != "eth1" || got[0].RxErrors != 15 {
+		t.Fatalf("fifth scan = %+v, want eth1 with 15 new rx errors", got)
+	}
+
+	// Counters reset (driver reload) only re-baseline
+	sixth := []sysinfo.NetStats{
+		{Name: "eth0", RxErrors: 502, TxErrors: 20, RxDropped: 50},
+		{Name: "eth1", RxErrors: 0},
+	}
+	if got := tr.update(sixth); len(got) != 0 {
+		t.Errorf("sixth scan = %v, want none", got)
+	}
+}
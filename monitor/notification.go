@@ -0,0 +1,55 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.aimuz.me/mynt/logger"
+	"go.aimuz.me/mynt/store"
+)
+
+// DefaultNotificationRetention is how long read notifications are kept.
+const DefaultNotificationRetention = 30 * 24 * time.Hour
+
+// NotificationPruner deletes old notifications (slow, throttled internally).
+type NotificationPruner struct {
+	repo      *store.NotificationRepo
+	retention time.Duration
+	keepMax   int
+	lastRun   time.Time
+	interval  time.Duration
+}
+
+// NewNotificationPruner creates a pruner that removes read notifications
+// older than retention and caps the table at keepMax rows.
+// interval specifies how often to actually prune.
+func NewNotificationPruner(repo *store.NotificationRepo, retention time.Duration, keepMax int, interval time.Duration) *NotificationPruner {
+	if retention <= 0 {
+		retention = DefaultNotificationRetention
+	}
+	return &NotificationPruner{
+		repo:      repo,
+		retention: retention,
+		keepMax:   keepMax,
+		interval:  interval,
+	}
+}
+
+// Scan prunes notifications if the interval has elapsed.
+func (p *NotificationPruner) Scan(ctx context.Context) error {
+	if time.Since(p.lastRun) < p.interval {
+		return nil
+	}
+
+	n, err := p.repo.Prune(time.Now().Add(-p.retention), p.keepMax)
+	if err != nil {
+		return fmt.Errorf("prune notifications: %w", err)
+	}
+	if n > 0 {
+		logger.Info("pruned notifications", "count", n)
+	}
+
+	p.lastRun = time.Now()
+	return nil
+}
@@ -0,0 +1,104 @@
+package monitor
+
+import "slices"
+
+// Failure prediction looks at the trend of a disk's bad sectors rather
+// than their absolute count: a disk that keeps remapping sectors is
+// wearing out even while it is below any threshold. The count used is
+// reallocated plus pending sectors, so a pending sector that is later
+// reallocated is not counted twice.
+//
+// The heuristic keeps the last predictWindow SMART samples per disk (M)
+// and counts the samples in which the bad sector count rose (N). At
+// predictMediumIncreases increases the prediction has medium confidence,
+// at predictHighIncreases high. Samples are kept in memory, so the window
+// refills after a restart.
+const (
+	predictWindow          = 8
+	predictMediumIncreases = 2
+	predictHighIncreases   = 4
+)
+
+// Confidence levels of a FailurePrediction.
+const (
+	ConfidenceMedium = "medium"
+	ConfidenceHigh   = "high"
+)
+
+// FailurePrediction is published when a disk's bad sector count keeps
+// rising.
+type FailurePrediction struct {
+	Disk               string `json:"disk"`
+	Confidence         string `json:"confidence"`
+	Increases          int    `json:"increases"` // samples in which the count rose
+	Samples            int    `json:"samples"`   // samples in the window
+	ReallocatedSectors int64  `json:"reallocated_sectors"`
+	PendingSectors     int64  `json:"pending_sectors"`
+}
+
+// failurePredictor keeps a window of bad sector counts per disk.
+type failurePredictor struct {
+	history  map[string][]int64 // disk -> reallocated+pending, oldest first
+	reported map[string]string  // disk -> confidence last published
+}
+
+func newFailurePredictor() *failurePredictor {
+	return &failurePredictor{
+		history:  make(map[string][]int64),
+		reported: make(map[string]string),
+	}
+}
+
+// observe records a SMART sample. It returns a prediction when the
+// confidence for the disk rises, and nil otherwise, so a disk is reported
+// once per level rather than on every scan. The reported level resets
+// once the trend falls below the threshold.
+func (p *failurePredictor) observe(disk string, reallocated, pending int64) *FailurePrediction {
+	samples := append(p.history[disk], reallocated+pending)
+	if len(samples) > predictWindow {
+		samples = samples[len(samples)-predictWindow:]
+	}
+	p.history[disk] = samples
+
+	increases := 0
+	for i := 1; i < len(samples); i++ {
+		if samples[i] > samples[i-1] {
+			increases++
+		}
+	}
+
+	var confidence string
+	switch {
+	case increases >= predictHighIncreases:
+		confidence = ConfidenceHigh
+	case increases >= predictMediumIncreases:
+		confidence = ConfidenceMedium
+	default:
+		delete(p.reported, disk)
+		return nil
+	}
+
+	if prev := p.reported[disk]; prev == confidence || prev == ConfidenceHigh {
+		return nil
+	}
+	p.reported[disk] = confidence
+	return &FailurePrediction{
+		Disk:               disk,
+		Confidence:         confidence,
+		Increases:          increases,
+		Samples:            len(samples),
+		ReallocatedSectors: reallocated,
+		PendingSectors:     pending,
+	}
+}
+
+// retain drops the history of disks not in names, so a device name
+// reused by a new disk starts from a clean window.
+func (p *failurePredictor) retain(names []string) {
+	for disk := range p.history {
+		if !slices.Contains(names, disk) {
+			delete(p.history, disk)
+			delete(p.reported, disk)
+		}
+	}
+}
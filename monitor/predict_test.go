@@ -0,0 +1,67 @@
+package monitor
+
+import "testing"
+
+func TestFailurePredictor_IncreasingSectors(t *testing.T) {
+	p := newFailurePredictor()
+
+	var got []*FailurePrediction
+	for _, n := range []int64{0, 0, 2, 2, 5, 8, 8, 12} {
+		if pred := p.observe("sda", n, 0); pred != nil {
+			got = append(got, pred)
+		}
+	}
+
+	if len(got) != 2 {
+		t.Fatalf("got %d predictions, want medium then high: %+v", len(got), got)
+	}
+	if got[0].Confidence != ConfidenceMedium || got[0].Increases != predictMediumIncreases {
+		t.Errorf("first prediction = %+v, want medium after %d increases", got[0], predictMediumIncreases)
+	}
+	if got[1].Confidence != ConfidenceHigh || got[1].ReallocatedSectors != 12 {
+		t.Errorf("second prediction = %+v, want high with 12 reallocated", got[1])
+	}
+}
+
+func TestFailurePredictor_FlatSectors(t *testing.T) {
+	p := newFailurePredictor()
+
+	// Many bad sectors, but the count is not changing
+	for i := range 20 {
+		if pred := p.observe("sda", 40, 3); pred != nil {
+			t.Fatalf("sample %d: unexpected prediction %+v", i, pred)
+		}
+	}
+}
+
+func TestFailurePredictor_PendingReallocated(t *testing.T) {
+	p := newFailurePredictor()
+
+	// A pending sector being reallocated keeps the total flat
+	samples := [][2]int64{{0, 1}, {1, 0}, {1, 1}, {2, 0}}
+	var preds int
+	for _, s := range samples {
+		if p.observe("sda", s[0], s[1]) != nil {
+			preds++
+		}
+	}
+	if preds != 0 {
+		t.Errorf("got %d predictions for one increase, want none", preds)
+	}
+}
+
+func TestFailurePredictor_Retain(t *testing.T) {
+	p := newFailurePredictor()
+	for _, n := range []int64{0, 1, 2} {
+		p.observe("sdb", n, 0)
+	}
+
+	// sdb is replaced by a new disk with the same name
+	p.retain([]string{"sda"})
+	if pred := p.observe("sdb", 0, 0); pred != nil {
+		t.Errorf("prediction after retain = %+v, want none", pred)
+	}
+	if len(p.history["sdb"]) != 1 {
+		t.Errorf("history = %v, want a fresh window", p.history["sdb"])
+	}
+}
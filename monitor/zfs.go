@@ -3,25 +3,75 @@ package monitor
 import (
 	"context"
 	"fmt"
+	"time"
 
+	"go.aimuz.me/mynt/alert"
 	"go.aimuz.me/mynt/event"
+	"go.aimuz.me/mynt/logger"
+	"go.aimuz.me/mynt/store"
 	"go.aimuz.me/mynt/zfs"
 )
 
+// DefaultCapacityRetention is how long pool capacity samples are kept.
+const DefaultCapacityRetention = 90 * 24 * time.Hour
+
+// capacitySampleInterval is the least time between capacity samples of a
+// pool. Capacity changes slowly, so sampling on every scan would only
+// grow the table.
+const capacitySampleInterval = time.Hour
+
 // ZFSScanner monitors ZFS pool health.
 type ZFSScanner struct {
-	bus *event.Bus
-	mgr *zfs.Manager
+	bus               *event.Bus
+	mgr               *zfs.Manager
+	repo              *store.DiskRepo
+	capacityRetention time.Duration
+	errors            *diskErrorTracker
+	scans             *scanTracker
+	alerts            *alert.Evaluator
+	lastCapacity      time.Time // when capacity was last recorded
 }
 
 // NewZFSScanner creates a ZFS scanner that publishes to the event bus.
-func NewZFSScanner(bus *event.Bus, mgr *zfs.Manager) *ZFSScanner {
+// Per-disk error counters and running scrubs and resilvers are persisted
+// in repo so increases and scan start times survive restarts. Each scan
+// also records the capacity of every pool, at most hourly, keeping samples
+// for capacityRetention (DefaultCapacityRetention if zero).
+func NewZFSScanner(bus *event.Bus, mgr *zfs.Manager, repo *store.DiskRepo, capacityRetention time.Duration) *ZFSScanner {
+	if capacityRetention <= 0 {
+		capacityRetention = DefaultCapacityRetention
+	}
 	return &ZFSScanner{
-		bus: bus,
-		mgr: mgr,
+		bus:               bus,
+		mgr:               mgr,
+		repo:              repo,
+		capacityRetention: capacityRetention,
 	}
 }
 
+// SetAlerts checks the capacity and error counts of every pool against
+// the rules of alerts. It must be called before the scanner runs.
+func (s *ZFSScanner) SetAlerts(alerts *alert.Evaluator) {
+	s.alerts = alerts
+}
+
+// DiskErrors describes new I/O errors on a pool member since the last scan.
+type DiskErrors struct {
+	Pool     string `json:"pool"`
+	Disk     string `json:"disk"`
+	Read     uint64 `json:"read"`     // new read errors
+	Write    uint64 `json:"write"`    // new write errors
+	Checksum uint64 `json:"checksum"` // new checksum errors
+}
+
+// ScanFinished describes a scrub or resilver that is no longer running.
+type ScanFinished struct {
+	Pool      string    `json:"pool"`
+	Kind      string    `json:"kind"` // "scrub" or "resilver"
+	StartedAt time.Time `json:"started_at"`
+	Duration  int64     `json:"duration"` // seconds, including time the daemon was down
+}
+
 // Scan checks ZFS pool health and publishes events.
 func (s *ZFSScanner) Scan(ctx context.Context) error {
 	pools, err := s.mgr.ListPools(ctx)
@@ -38,5 +88,225 @@ func (s *ZFSScanner) Scan(ctx context.Context) error {
 		}
 	}
 
+	if s.errors == nil {
+		known, err := s.repo.ListPoolErrors()
+		if err != nil {
+			return fmt.Errorf("list pool errors: %w", err)
+		}
+		s.errors = newDiskErrorTracker(known)
+	}
+
+	increased, changed := s.errors.update(pools)
+	for _, e := range increased {
+		logger.Warn("pool disk errors increased", "pool", e.Pool, "disk", e.Disk,
+			"read", e.Read, "write", e.Write, "checksum", e.Checksum)
+		s.bus.Publish(event.Event{Type: event.PoolDiskErrors, Data: e})
+	}
+	for _, c := range changed {
+		if err := s.repo.SavePoolErrors(c); err != nil {
+			logger.Warn("failed to save pool errors", "pool", c.Pool, "disk", c.Disk, "error", err)
+		}
+	}
+
+	s.checkAlerts(pools)
+
+	now := time.Now()
+	s.recordCapacity(pools, now)
+	return s.trackScans(pools, now)
+}
+
+// checkAlerts feeds the capacity and error count of each pool to the
+// alert rules.
+func (s *ZFSScanner) checkAlerts(pools []zfs.Pool) {
+	names := make([]string, 0, len(pools))
+	for _, pool := range pools {
+		names = append(names, pool.Name)
+		if pool.Size > 0 {
+			s.alerts.Observe(alert.PoolCapacity, pool.Name, float64(pool.Allocated)*100/float64(pool.Size))
+		}
+		var errors uint64
+		for _, vdev := range pool.VDevs {
+			for _, d := range vdev.Children {
+				errors += d.Read + d.Write + d.Checksum
+			}
+		}
+		s.alerts.Observe(alert.PoolErrors, pool.Name, float64(errors))
+	}
+	s.alerts.Retain(alert.PoolCapacity, names)
+	s.alerts.Retain(alert.PoolErrors, names)
+}
+
+// recordCapacity stores a capacity sample per pool and drops samples
+// older than the retention window, unless the last samples were taken
+// less than capacitySampleInterval ago.
+func (s *ZFSScanner) recordCapacity(pools []zfs.Pool, now time.Time) {
+	if now.Sub(s.lastCapacity) < capacitySampleInterval {
+		return
+	}
+	s.lastCapacity = now
+
+	for _, pool := range pools {
+		sample := store.PoolCapacitySample{
+			Pool:      pool.Name,
+			Allocated: pool.Allocated,
+			Free:      pool.Free,
+			Time:      now,
+		}
+		if err := s.repo.AddPoolCapacity(sample); err != nil {
+			logger.Warn("failed to save pool capacity", "pool", pool.Name, "error", err)
+		}
+	}
+	if _, err := s.repo.PrunePoolCapacity(now.Add(-s.capacityRetention)); err != nil {
+		logger.Warn("failed to prune pool capacity history", "error", err)
+	}
+}
+
+// trackScans reconciles the stored scrubs and resilvers with the live pool
+// status. On the first call after startup this clears scans that finished
+// while the daemon was down.
+func (s *ZFSScanner) trackScans(pools []zfs.Pool, now time.Time) error {
+	if s.scans == nil {
+		known, err := s.repo.ListPoolScans()
+		if err != nil {
+			return fmt.Errorf("list pool scans: %w", err)
+		}
+		s.scans = newScanTracker(known)
+	}
+
+	started, finished := s.scans.update(pools, now)
+	for _, f := range finished {
+		if err := s.repo.DeletePoolScan(f.Pool); err != nil {
+			logger.Warn("failed to delete pool scan", "pool", f.Pool, "error", err)
+		}
+		s.bus.Publish(event.Event{Type: event.PoolScanFinished, Data: f})
+	}
+	for _, st := range started {
+		if err := s.repo.SavePoolScan(st); err != nil {
+			logger.Warn("failed to save pool scan", "pool", st.Pool, "error", err)
+		}
+		s.bus.Publish(event.Event{Type: event.PoolScanStarted, Data: st})
+	}
 	return nil
 }
+
+// scanTracker remembers the scrub or resilver running on each pool.
+type scanTracker struct {
+	active map[string]store.PoolScan // pool -> scan
+}
+
+func newScanTracker(known []store.PoolScan) *scanTracker {
+	t := &scanTracker{active: make(map[string]store.PoolScan, len(known))}
+	for _, s := range known {
+		t.active[s.Pool] = s
+	}
+	return t
+}
+
+// update records the scans running in pools. It returns scans seen for the
+// first time and tracked scans that are no longer running; a resilver that
+// replaces a scrub is reported as both. Pools missing from the list keep
+// their scan, since an exported pool resumes it on import.
+func (t *scanTracker) update(pools []zfs.Pool, now time.Time) (started []store.PoolScan, finished []ScanFinished) {
+	for _, pool := range pools {
+		kind, startTime := activeScan(pool)
+		prev, tracked := t.active[pool.Name]
+		if tracked && prev.Kind == kind {
+			continue
+		}
+		if tracked {
+			delete(t.active, pool.Name)
+			finished = append(finished, ScanFinished{
+				Pool:      prev.Pool,
+				Kind:      prev.Kind,
+				StartedAt: prev.StartedAt,
+				Duration:  int64(now.Sub(prev.StartedAt).Seconds()),
+			})
+		}
+		if kind == "" {
+			continue
+		}
+		cur := store.PoolScan{Pool: pool.Name, Kind: kind, StartedAt: now}
+		if startTime > 0 {
+			cur.StartedAt = time.Unix(startTime, 0)
+		}
+		t.active[pool.Name] = cur
+		started = append(started, cur)
+	}
+	return started, finished
+}
+
+// activeScan returns the kind and start time of the scan running on pool,
+// or an empty kind if none is.
+func activeScan(pool zfs.Pool) (kind string, startTime int64) {
+	if r := pool.ResilverStatus; r != nil && r.InProgress {
+		return "resilver", r.StartTime
+	}
+	if s := pool.ScrubStatus; s != nil && s.InProgress {
+		return "scrub", s.StartTime
+	}
+	return "", 0
+}
+
+// diskErrorTracker remembers the error counters of each pool member
+// between scans.
+type diskErrorTracker struct {
+	last map[[2]string]store.PoolDiskErrors // (pool, disk) -> counters
+}
+
+func newDiskErrorTracker(known []store.PoolDiskErrors) *diskErrorTracker {
+	t := &diskErrorTracker{last: make(map[[2]string]store.PoolDiskErrors, len(known))}
+	for _, e := range known {
+		t.last[[2]string{e.Pool, e.Disk}] = e
+	}
+	return t
+}
+
+// update records the current counters. It returns the disks whose counters
+// grew, with the increase, and the counters that differ from the last scan
+// and need saving. A disk seen for the first time only sets a baseline, and
+// counters that went down (zpool clear) are not reported.
+func (t *diskErrorTracker) update(pools []zfs.Pool) (increased []DiskErrors, changed []store.PoolDiskErrors) {
+	for _, pool := range pools {
+		for _, vdev := range pool.VDevs {
+			for _, d := range vdev.Children {
+				cur := store.PoolDiskErrors{
+					Pool:     pool.Name,
+					Disk:     d.Name,
+					Read:     d.Read,
+					Write:    d.Write,
+					Checksum: d.Checksum,
+				}
+				key := [2]string{pool.Name, d.Name}
+				prev, seen := t.last[key]
+				if seen && cur == prev {
+					continue
+				}
+				t.last[key] = cur
+				changed = append(changed, cur)
+				if !seen {
+					continue
+				}
+
+				delta := DiskErrors{
+					Pool:     pool.Name,
+					Disk:     d.Name,
+					Read:     increase(prev.Read, cur.Read),
+					Write:    increase(prev.Write, cur.Write),
+					Checksum: increase(prev.Checksum, cur.Checksum),
+				}
+				if delta.Read > 0 || delta.Write > 0 || delta.Checksum > 0 {
+					increased = append(increased, delta)
+				}
+			}
+		}
+	}
+	return increased, changed
+}
+
+// increase returns how much a counter grew, or 0 if it was reset.
+func increase(prev, cur uint64) uint64 {
+	if cur > prev {
+		return cur - prev
+	}
+	return 0
+}
@@ -0,0 +1,200 @@
+package monitor
+
+import (
+	"testing"
+	"time"
+
+	"go.aimuz.me/mynt/alert"
+	"go.aimuz.me/mynt/event"
+	"go.aimuz.me/mynt/store"
+	"go.aimuz.me/mynt/zfs"
+)
+
+func poolWithErrors(read, write, checksum uint64) []zfs.Pool {
+	return []zfs.Pool{{
+		Name: "tank",
+		VDevs: []zfs.VDevDetail{{
+			Name: "mirror-0",
+			Children: []zfs.DiskDetail{
+				{Name: "sda", Read: read, Write: write, Checksum: checksum},
+				{Name: "sdb"},
+			},
+		}},
+	}}
+}
+
+func TestDiskErrorTracker(t *testing.T) {
+	tr := newDiskErrorTracker(nil)
+
+	// First scan sets the baseline, including existing errors
+	increased, changed := tr.update(poolWithErrors(0, 0, 2))
+	if len(increased) != 0 {
+		t.Fatalf("first scan increases = %v, want none", increased)
+	}
+	if len(changed) != 2 {
+		t.Fatalf("first scan changed = %d, want 2", len(changed))
+	}
+
+	// Checksum errors grow on sda only
+	increased, changed = tr.update(poolWithErrors(0, 0, 5))
+	want := DiskErrors{Pool: "tank", Disk: "sda", Checksum: 3}
+	if len(increased) != 1 || increased[0] != want {
+		t.Fatalf("second scan increases = %v, want [%v]", increased, want)
+	}
+	if len(changed) != 1 || changed[0].Checksum != 5 {
+		t.Errorf("second scan changed = %v, want sda with 5 checksum errors", changed)
+	}
+
+	// Unchanged counters fire nothing and need no save
+	if increased, changed := tr.update(poolWithErrors(0, 0, 5)); len(increased) != 0 || len(changed) != 0 {
+		t.Errorf("third scan = %v, %v, want nothing", increased, changed)
+	}
+
+	// zpool clear resets the counters without an event
+	if increased, _ := tr.update(poolWithErrors(0, 0, 0)); len(increased) != 0 {
+		t.Errorf("after clear increases = %v, want none", increased)
+	}
+}
+
+func TestDiskErrorTracker_Persisted(t *testing.T) {
+	tr := newDiskErrorTracker([]store.PoolDiskErrors{
+		{Pool: "tank", Disk: "sda", Read: 1},
+	})
+
+	// Counters loaded from the store are compared against on the first scan
+	increased, _ := tr.update(poolWithErrors(4, 0, 0))
+	want := DiskErrors{Pool: "tank", Disk: "sda", Read: 3}
+	if len(increased) != 1 || increased[0] != want {
+		t.Errorf("increases = %v, want [%v]", increased, want)
+	}
+}
+
+func TestScanTracker(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	scrubbing := []zfs.Pool{{Name: "tank", ScrubStatus: &zfs.ScrubStatus{InProgress: true, StartTime: now.Unix()}}}
+	resilvering := []zfs.Pool{{Name: "tank", ResilverStatus: &zfs.ResilverStatus{InProgress: true}}}
+	idle := []zfs.Pool{{Name: "tank", ScrubStatus: &zfs.ScrubStatus{}}}
+
+	tr := newScanTracker(nil)
+	started, finished := tr.update(scrubbing, now)
+	if len(started) != 1 || started[0].Kind != "scrub" || !started[0].StartedAt.Equal(now) || len(finished) != 0 {
+		t.Fatalf("scrub start: started = %v, finished = %v", started, finished)
+	}
+
+	// A running scan is only reported once
+	started, finished = tr.update(scrubbing, now.Add(time.Minute))
+	if len(started) != 0 || len(finished) != 0 {
+		t.Fatalf("repeat scan: started = %v, finished = %v", started, finished)
+	}
+
+	// A resilver replaces the scrub and starts now, having no start time
+	later := now.Add(time.Hour)
+	started, finished = tr.update(resilvering, later)
+	if len(finished) != 1 || finished[0].Kind != "scrub" || finished[0].Duration != 3600 {
+		t.Fatalf("resilver: finished = %v", finished)
+	}
+	if len(started) != 1 || started[0].Kind != "resilver" || !started[0].StartedAt.Equal(later) {
+		t.Fatalf("resilver: started = %v", started)
+	}
+
+	// Missing pools keep their scan
+	if started, finished = tr.update(nil, later); len(started) != 0 || len(finished) != 0 {
+		t.Fatalf("missing pool: started = %v, finished = %v", started, finished)
+	}
+
+	_, finished = tr.update(idle, later.Add(time.Minute))
+	if len(finished) != 1 || finished[0].Kind != "resilver" {
+		t.Fatalf("idle: finished = %v", finished)
+	}
+}
+
+func TestZFSScanner_ReconcileFinishedScrub(t *testing.T) {
+	db, err := store.Open(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	repo := store.NewDiskRepo(db)
+
+	// A scrub was running when the daemon stopped and finished meanwhile
+	started := time.Date(2025, 6, 1, 3, 0, 0, 0, time.UTC)
+	if err := repo.SavePoolScan(store.PoolScan{Pool: "tank", Kind: "scrub", StartedAt: started}); err != nil {
+		t.Fatal(err)
+	}
+
+	bus := event.NewBus()
+	ch := bus.Subscribe(event.PoolScanFinished)
+	s := &ZFSScanner{bus: bus, repo: repo}
+	end := "Sun Jun  1 05:00:00 2025"
+	pools := []zfs.Pool{{Name: "tank", ScrubStatus: &zfs.ScrubStatus{EndTime: &end}}}
+	if err := s.trackScans(pools, started.Add(3*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	scan, err := repo.GetPoolScan("tank")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if scan != nil {
+		t.Errorf("stored scan = %v, want cleared", scan)
+	}
+	evt := <-ch
+	f := evt.Data.(ScanFinished)
+	if f.Kind != "scrub" || !f.StartedAt.Equal(started) || f.Duration != 3*3600 {
+		t.Errorf("finished event = %+v", f)
+	}
+}
+
+func TestZFSScanner_RecordCapacityHourly(t *testing.T) {
+	db, err := store.Open(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	repo := store.NewDiskRepo(db)
+
+	s := &ZFSScanner{repo: repo, capacityRetention: DefaultCapacityRetention}
+	pools := []zfs.Pool{{Name: "tank", Allocated: 10, Free: 90}}
+	start := time.Now().Add(-3 * time.Hour)
+	for i := range 12 {
+		s.recordCapacity(pools, start.Add(time.Duration(i)*10*time.Minute))
+	}
+
+	samples, err := repo.PoolCapacityHistory("tank", start.Add(-time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(samples) != 2 {
+		t.Errorf("got %d samples over two hours of scans, want 2", len(samples))
+	}
+}
+
+func TestZFSScanner_CheckAlerts(t *testing.T) {
+	bus := event.NewBus()
+	ch := bus.Subscribe("alert.*")
+	defer bus.Unsubscribe("alert.*", ch)
+
+	s := NewZFSScanner(bus, nil, nil, 0)
+	s.SetAlerts(alert.NewEvaluator(bus, []alert.Rule{
+		{Metric: alert.PoolCapacity, Op: alert.OpAboveOrEqual, Threshold: 90, Severity: event.SeverityWarning},
+		{Metric: alert.PoolErrors, Op: alert.OpAbove, Threshold: 0, Severity: event.SeverityCritical},
+	}))
+
+	pools := poolWithErrors(0, 1, 2)
+	pools[0].Size, pools[0].Allocated = 1000, 950
+	s.checkAlerts(pools)
+
+	got := map[string]float64{}
+	for range 2 {
+		select {
+		case evt := <-ch:
+			a := evt.Data.(alert.Alert)
+			got[a.Rule.Metric] = a.Value
+		case <-time.After(100 * time.Millisecond):
+			t.Fatal("alert not published")
+		}
+	}
+	if got[alert.PoolCapacity] != 95 || got[alert.PoolErrors] != 3 {
+		t.Errorf("alerts = %v, want pool_capacity 95 and pool_errors 3", got)
+	}
+}
@@ -0,0 +1,44 @@
+package notify
+
+import "sync"
+
+// listCache holds a list loaded from the store between changes, so events
+// do not each query it. generation counts invalidate calls, so a list
+// loaded while the store changed is not cached.
+type listCache[T any] struct {
+	mu         sync.Mutex
+	list       []T
+	cached     bool
+	generation uint64
+}
+
+// invalidate drops the cached list, so the next get loads it again.
+func (c *listCache[T]) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.list, c.cached = nil, false
+	c.generation++
+}
+
+// get returns the cached list, calling load if there is none.
+func (c *listCache[T]) get(load func() ([]T, error)) ([]T, error) {
+	c.mu.Lock()
+	if c.cached {
+		defer c.mu.Unlock()
+		return c.list, nil
+	}
+	generation := c.generation
+	c.mu.Unlock()
+
+	list, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.generation == generation {
+		c.list, c.cached = list, true
+	}
+	return list, nil
+}
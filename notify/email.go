@@ -0,0 +1,295 @@
+package notify
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.aimuz.me/mynt/event"
+	"go.aimuz.me/mynt/logger"
+	"go.aimuz.me/mynt/store"
+)
+
+const (
+	// DefaultDigestWindow is how long events for one channel are collected
+	// into a single email, so a burst of events sends one digest rather
+	// than a flood of mails.
+	DefaultDigestWindow = time.Minute
+
+	// maxDigestEvents caps the events listed in one digest; the rest are
+	// only counted.
+	maxDigestEvents = 50
+
+	// emailAttempts is how often delivery of an email is tried before it
+	// is dropped.
+	emailAttempts = 4
+)
+
+// emailRetryBackoff is the delay before retrying a failed delivery,
+// doubled after each attempt.
+var emailRetryBackoff = 30 * time.Second
+
+// ErrEmailNotConfigured is returned when an email channel fires but no
+// SMTP server was configured.
+var ErrEmailNotConfigured = errors.New("email is not configured")
+
+// errEmailClosed is returned for events sent after Close.
+var errEmailClosed = errors.New("email sender is closed")
+
+// EmailSender mails events through an SMTP server. Events for a channel
+// are collected for the digest window and sent as one email, and
+// deliveries failing with a transient error are retried with backoff.
+type EmailSender struct {
+	window  time.Duration
+	deliver func(ctx context.Context, cfg store.SMTPConfig, to string, msg []byte) error
+
+	mu      sync.Mutex
+	cfg     store.SMTPConfig
+	pending map[int64]*digest // channel ID -> queued events
+	closed  chan struct{}
+	wg      sync.WaitGroup // digests being delivered
+}
+
+// digest is the events queued for one channel.
+type digest struct {
+	to      string
+	events  []event.Event
+	dropped int // events past maxDigestEvents
+	timer   *time.Timer
+}
+
+// NewEmailSender creates a sender for the SMTP server in cfg, collecting
+// events for window (DefaultDigestWindow if zero) before mailing them.
+// Email is disabled while cfg has no host.
+func NewEmailSender(cfg store.SMTPConfig, window time.Duration) *EmailSender {
+	if window <= 0 {
+		window = DefaultDigestWindow
+	}
+	return &EmailSender{
+		window:  window,
+		deliver: deliverSMTP,
+		cfg:     cfg,
+		pending: make(map[int64]*digest),
+		closed:  make(chan struct{}),
+	}
+}
+
+// Config returns the SMTP settings in use.
+func (s *EmailSender) Config() store.SMTPConfig {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cfg
+}
+
+// SetConfig changes the SMTP settings. Digests not yet sent use the new
+// settings.
+func (s *EmailSender) SetConfig(cfg store.SMTPConfig) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cfg = cfg
+}
+
+// Send queues evt for the channel's next digest. The digest is mailed
+// once the window that started with its first event has passed.
+func (s *EmailSender) Send(_ context.Context, ch store.NotificationChannel, evt event.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cfg.Host == "" {
+		return ErrEmailNotConfigured
+	}
+	select {
+	case <-s.closed:
+		return errEmailClosed
+	default:
+	}
+
+	d, ok := s.pending[ch.ID]
+	if !ok {
+		d = &digest{to: ch.Target}
+		d.timer = time.AfterFunc(s.window, func() { s.flush(ch.ID) })
+		s.pending[ch.ID] = d
+	}
+	if len(d.events) < maxDigestEvents {
+		d.events = append(d.events, evt)
+	} else {
+		d.dropped++
+	}
+	return nil
+}
+
+// Close mails the queued digests without waiting for their window and
+// waits for deliveries to finish. Deliveries waiting to be retried give
+// up instead.
+func (s *EmailSender) Close() {
+	s.mu.Lock()
+	select {
+	case <-s.closed:
+		s.mu.Unlock()
+		return
+	default:
+	}
+	close(s.closed)
+	ids := make([]int64, 0, len(s.pending))
+	for id, d := range s.pending {
+		d.timer.Stop()
+		ids = append(ids, id)
+	}
+	s.mu.Unlock()
+
+	for _, id := range ids {
+		s.flush(id)
+	}
+	s.wg.Wait()
+}
+
+// flush mails the digest queued for a channel, if any.
+func (s *EmailSender) flush(id int64) {
+	s.mu.Lock()
+	d, ok := s.pending[id]
+	delete(s.pending, id)
+	cfg := s.cfg
+	if ok {
+		s.wg.Add(1)
+	}
+	s.mu.Unlock()
+	if !ok {
+		return
+	}
+	defer s.wg.Done()
+
+	msg := composeEmail(cfg.From, d.to, d.events, d.dropped)
+	if err := s.sendWithRetry(cfg, d.to, msg); err != nil {
+		logger.Warn("failed to send notification email", "to", d.to,
+			"events", len(d.events)+d.dropped, "error", err)
+	}
+}
+
+// sendWithRetry delivers msg, retrying transient failures up to
+// emailAttempts times.
+func (s *EmailSender) sendWithRetry(cfg store.SMTPConfig, to string, msg []byte) error {
+	backoff := emailRetryBackoff
+	for attempt := 1; ; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+		err := s.deliver(ctx, cfg, to, msg)
+		cancel()
+		if err == nil || attempt == emailAttempts || !transient(err) {
+			return err
+		}
+
+		logger.Debug("retrying notification email", "to", to, "attempt", attempt, "backoff", backoff, "error", err)
+		select {
+		case <-time.After(backoff):
+		case <-s.closed:
+			return err
+		}
+		backoff *= 2
+	}
+}
+
+// transient reports whether a failed delivery is worth retrying: the
+// server answered with a 4xx code, or it could not be reached at all.
+// Permanent 5xx rejections are not retried.
+func transient(err error) bool {
+	var reply *textproto.Error
+	if errors.As(err, &reply) {
+		return reply.Code < 500
+	}
+	return true
+}
+
+// composeEmail formats events as a plain text email. A single event keeps
+// its type in the subject; several are sent as a digest.
+func composeEmail(from, to string, events []event.Event, dropped int) []byte {
+	severity := event.SeverityInfo
+	for _, evt := range events {
+		if sev := evt.Severity(); sev.AtLeast(severity) {
+			severity = sev
+		}
+	}
+
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", from)
+	fmt.Fprintf(&msg, "To: %s\r\n", to)
+	if len(events) == 1 && dropped == 0 {
+		fmt.Fprintf(&msg, "Subject: [mynt] %s: %s\r\n", severity, events[0].Type)
+	} else {
+		fmt.Fprintf(&msg, "Subject: [mynt] %d events, most severe %s\r\n", len(events)+dropped, severity)
+	}
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+
+	for i, evt := range events {
+		if i > 0 {
+			msg.WriteString("\r\n----\r\n\r\n")
+		}
+		data, _ := json.MarshalIndent(evt.Data, "", "  ")
+		fmt.Fprintf(&msg, "Event: %s\r\nSeverity: %s\r\nTime: %s\r\n\r\n%s\r\n",
+			evt.Type, evt.Severity(), evt.Time.Format(time.RFC1123), data)
+	}
+	if dropped > 0 {
+		fmt.Fprintf(&msg, "\r\n... and %d more events not shown.\r\n", dropped)
+	}
+	return []byte(msg.String())
+}
+
+// deliverSMTP sends msg to one recipient. The connection is bound to ctx,
+// so an unresponsive server cannot hang the delivery. Plain connections
+// are upgraded with STARTTLS when the server offers it.
+func deliverSMTP(ctx context.Context, cfg store.SMTPConfig, to string, msg []byte) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port)))
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	tlsConfig := &tls.Config{ServerName: cfg.Host}
+	if cfg.TLS {
+		conn = tls.Client(conn, tlsConfig)
+	}
+
+	c, err := smtp.NewClient(conn, cfg.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok && !cfg.TLS {
+		if err := c.StartTLS(tlsConfig); err != nil {
+			return err
+		}
+	}
+	if cfg.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(cfg.From); err != nil {
+		return err
+	}
+	if err := c.Rcpt(to); err != nil {
+		return err
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
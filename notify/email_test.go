@@ -0,0 +1,151 @@
+package notify
+
+import (
+	"context"
+	"net"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.aimuz.me/mynt/event"
+	"go.aimuz.me/mynt/store"
+)
+
+// mailbox records the emails an EmailSender delivers, failing with the
+// queued errors first.
+type mailbox struct {
+	mu       sync.Mutex
+	errs     []error
+	attempts int
+	sent     map[string][]string // recipient -> messages
+}
+
+func (m *mailbox) deliver(_ context.Context, _ store.SMTPConfig, to string, msg []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.attempts++
+	if len(m.errs) > 0 {
+		err := m.errs[0]
+		m.errs = m.errs[1:]
+		return err
+	}
+	m.sent[to] = append(m.sent[to], string(msg))
+	return nil
+}
+
+func newTestEmailSender(window time.Duration, errs ...error) (*EmailSender, *mailbox) {
+	box := &mailbox{errs: errs, sent: map[string][]string{}}
+	s := NewEmailSender(store.SMTPConfig{Host: "mail.example.com", Port: 25, From: "nas@example.com"}, window)
+	s.deliver = box.deliver
+	return s, box
+}
+
+func TestEmailSender_Digest(t *testing.T) {
+	s, box := newTestEmailSender(20 * time.Millisecond)
+	ops := store.NotificationChannel{ID: 1, Name: "ops", Type: store.ChannelEmail, Target: "ops@example.com"}
+	admin := store.NotificationChannel{ID: 2, Name: "admin", Type: store.ChannelEmail, Target: "admin@example.com"}
+
+	ctx := context.Background()
+	require.NoError(t, s.Send(ctx, ops, event.Event{Type: event.DiskAdded}))
+	require.NoError(t, s.Send(ctx, ops, event.Event{Type: event.PoolDegraded}))
+	require.NoError(t, s.Send(ctx, ops, event.Event{Type: event.DatasetCreated}))
+	require.NoError(t, s.Send(ctx, admin, event.Event{Type: event.PoolDegraded}))
+
+	require.Eventually(t, func() bool {
+		box.mu.Lock()
+		defer box.mu.Unlock()
+		return len(box.sent) == 2
+	}, time.Second, 5*time.Millisecond)
+
+	require.Len(t, box.sent["ops@example.com"], 1)
+	digest := box.sent["ops@example.com"][0]
+	require.Contains(t, digest, "Subject: [mynt] 3 events, most severe critical\r\n")
+	for _, typ := range []string{event.DiskAdded, event.PoolDegraded, event.DatasetCreated} {
+		require.Contains(t, digest, "Event: "+typ+"\r\n")
+	}
+	require.Contains(t, box.sent["admin@example.com"][0], "Subject: [mynt] critical: pool.degraded\r\n")
+}
+
+func TestEmailSender_Retry(t *testing.T) {
+	old := emailRetryBackoff
+	emailRetryBackoff = time.Millisecond
+	t.Cleanup(func() { emailRetryBackoff = old })
+
+	busy := &textproto.Error{Code: 421, Msg: "try again later"}
+	s, box := newTestEmailSender(time.Millisecond, busy, busy)
+	require.NoError(t, s.sendWithRetry(s.Config(), "ops@example.com", []byte("hi")))
+	require.Equal(t, 3, box.attempts)
+
+	// Permanent rejections are not retried
+	s, box = newTestEmailSender(time.Millisecond, &textproto.Error{Code: 550, Msg: "no such user"})
+	require.Error(t, s.sendWithRetry(s.Config(), "nobody@example.com", []byte("hi")))
+	require.Equal(t, 1, box.attempts)
+}
+
+func TestEmailSender_CloseFlushes(t *testing.T) {
+	s, box := newTestEmailSender(time.Hour)
+	ch := store.NotificationChannel{ID: 1, Name: "ops", Type: store.ChannelEmail, Target: "ops@example.com"}
+	require.NoError(t, s.Send(context.Background(), ch, event.Event{Type: event.DiskRemoved}))
+
+	s.Close()
+	require.Len(t, box.sent["ops@example.com"], 1)
+	require.Error(t, s.Send(context.Background(), ch, event.Event{Type: event.DiskRemoved}))
+
+	unconfigured := NewEmailSender(store.SMTPConfig{}, 0)
+	require.ErrorIs(t, unconfigured.Send(context.Background(), ch, event.Event{}), ErrEmailNotConfigured)
+}
+
+// serveSMTP accepts one connection on l and speaks just enough SMTP to
+// take a message, which it sends to data.
+func serveSMTP(t *testing.T, l net.Listener, data chan<- string) {
+	conn, err := l.Accept()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer conn.Close()
+	tc := textproto.NewConn(conn)
+	tc.PrintfLine("220 test ESMTP")
+	for {
+		line, err := tc.ReadLine()
+		if err != nil {
+			return
+		}
+		switch cmd, _, _ := strings.Cut(line, " "); strings.ToUpper(cmd) {
+		case "EHLO", "HELO", "MAIL", "RCPT":
+			tc.PrintfLine("250 ok")
+		case "DATA":
+			tc.PrintfLine("354 go ahead")
+			body, _ := tc.ReadDotBytes()
+			data <- string(body)
+			tc.PrintfLine("250 queued")
+		case "QUIT":
+			tc.PrintfLine("221 bye")
+			return
+		default:
+			tc.PrintfLine("502 unknown")
+		}
+	}
+}
+
+func TestDeliverSMTP(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	data := make(chan string, 1)
+	go serveSMTP(t, l, data)
+
+	host, port, _ := net.SplitHostPort(l.Addr().String())
+	cfg := store.SMTPConfig{Host: host, From: "nas@example.com"}
+	cfg.Port, _ = strconv.Atoi(port)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	msg := composeEmail(cfg.From, "ops@example.com", []event.Event{{Type: event.DiskAdded}}, 0)
+	require.NoError(t, deliverSMTP(ctx, cfg, "ops@example.com", msg))
+	require.Contains(t, <-data, "Subject: [mynt] info: disk.added")
+}
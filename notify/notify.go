@@ -0,0 +1,220 @@
+// Package notify delivers events from the bus to the admin's notification
+// channels: email, webhooks and the log.
+//
+// There are two kinds of webhook. A webhook notification channel
+// (store.ChannelWebhook) is a plain channel: it posts each matching event
+// once as unsigned JSON, for receivers that only need to hear that
+// something happened. Signed webhooks (store.Webhook, enabled with
+// WithWebhooks) are managed on their own and post the same JSON body with
+// an HMAC signature in SignatureHeader, retry failed deliveries and keep a
+// delivery log. Use a signed webhook when the receiver must trust or audit
+// what it gets.
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"sync"
+	"time"
+
+	"go.aimuz.me/mynt/event"
+	"go.aimuz.me/mynt/logger"
+	"go.aimuz.me/mynt/store"
+)
+
+// ChannelSource lists the configured notification channels.
+type ChannelSource interface {
+	List() ([]store.NotificationChannel, error)
+}
+
+// Sender delivers an event to one channel of its type.
+type Sender interface {
+	Send(ctx context.Context, ch store.NotificationChannel, evt event.Event) error
+}
+
+// sendTimeout bounds a single delivery so a dead webhook or mail server
+// cannot pile up goroutines.
+const sendTimeout = 15 * time.Second
+
+// Dispatcher routes every published event to the channels whose filters
+// match it.
+type Dispatcher struct {
+	bus      *event.Bus
+	channels ChannelSource
+	senders  map[string]Sender
+	webhooks *webhookSink // nil unless signed webhooks are enabled
+
+	cache listCache[store.NotificationChannel] // channels between changes
+
+	sub  <-chan event.Event
+	done chan struct{}
+}
+
+// Option configures a Dispatcher.
+type Option func(*Dispatcher)
+
+// WithSender sets the sender used for channels of the given type.
+func WithSender(channelType string, s Sender) Option {
+	return func(d *Dispatcher) { d.senders[channelType] = s }
+}
+
+// NewDispatcher creates a dispatcher for the channels in src. Log and
+// webhook channels work out of the box; email channels need an
+// EmailSender with an SMTP server, set with WithSender.
+func NewDispatcher(bus *event.Bus, src ChannelSource, opts ...Option) *Dispatcher {
+	d := &Dispatcher{
+		bus:      bus,
+		channels: src,
+		senders: map[string]Sender{
+			store.ChannelLog:     LogSender{},
+			store.ChannelWebhook: NewWebhookSender(),
+			store.ChannelEmail:   NewEmailSender(store.SMTPConfig{}, 0),
+		},
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
+}
+
+// Start subscribes to the bus and dispatches events until Stop.
+func (d *Dispatcher) Start(ctx context.Context) {
+	d.sub = d.bus.Subscribe("*")
+	d.done = make(chan struct{})
+	go func() {
+		defer close(d.done)
+		for evt := range d.sub {
+			go d.Dispatch(ctx, evt)
+		}
+	}()
+}
+
+// closer is implemented by senders that hold events back, such as email
+// digests, and must flush them on shutdown.
+type closer interface {
+	Close()
+}
+
+// Stop unsubscribes from the bus and flushes senders holding events back.
+// Other deliveries already started finish on their own.
+func (d *Dispatcher) Stop() {
+	if d.sub == nil {
+		return
+	}
+	d.bus.Unsubscribe("*", d.sub)
+	<-d.done
+	for _, s := range d.senders {
+		if c, ok := s.(closer); ok {
+			c.Close()
+		}
+	}
+}
+
+// Dispatch delivers evt to every matching channel and webhook and waits
+// for the deliveries to finish. Failures are logged rather than returned,
+// so one broken channel does not affect the others.
+func (d *Dispatcher) Dispatch(ctx context.Context, evt event.Event) {
+	var wg sync.WaitGroup
+	if d.webhooks != nil {
+		d.webhooks.dispatch(ctx, &wg, evt)
+	}
+
+	channels, err := d.listChannels()
+	if err != nil {
+		logger.Warn("failed to load notification channels", "error", err)
+	}
+	for _, ch := range channels {
+		if !ch.Matches(evt) {
+			continue
+		}
+		sender, ok := d.senders[ch.Type]
+		if !ok {
+			logger.Warn("no sender for notification channel", "channel", ch.Name, "type", ch.Type)
+			continue
+		}
+		wg.Go(func() {
+			ctx, cancel := context.WithTimeout(ctx, sendTimeout)
+			defer cancel()
+			if err := sender.Send(ctx, ch, evt); err != nil {
+				logger.Warn("failed to deliver notification", "channel", ch.Name, "event", evt.Type, "error", err)
+			}
+		})
+	}
+	wg.Wait()
+}
+
+// InvalidateChannels drops the cached channel list, so the next event
+// loads the channels again. It must be called after channels change.
+func (d *Dispatcher) InvalidateChannels() {
+	d.cache.invalidate()
+}
+
+// InvalidateWebhooks drops the cached list of signed webhooks, so the next
+// event loads them again. It must be called after webhooks change.
+func (d *Dispatcher) InvalidateWebhooks() {
+	if d.webhooks != nil {
+		d.webhooks.cache.invalidate()
+	}
+}
+
+// listChannels returns the cached channel list, loading it if needed.
+func (d *Dispatcher) listChannels() ([]store.NotificationChannel, error) {
+	return d.cache.get(d.channels.List)
+}
+
+// Validate checks a channel before it is saved, defaulting an empty
+// minimum severity to info. Email targets are reduced to the bare
+// address, since a display name is not a valid SMTP recipient.
+func Validate(c *store.NotificationChannel) error {
+	if c.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if c.MinSeverity == "" {
+		c.MinSeverity = event.SeverityInfo
+	}
+	if !c.MinSeverity.Valid() {
+		return fmt.Errorf("invalid min_severity %q: must be info, warning or critical", c.MinSeverity)
+	}
+
+	switch c.Type {
+	case store.ChannelEmail:
+		addr, err := mail.ParseAddress(c.Target)
+		if err != nil {
+			return fmt.Errorf("invalid email address %q", c.Target)
+		}
+		c.Target = addr.Address
+	case store.ChannelWebhook:
+		return validateWebhookURL(c.Target)
+	case store.ChannelLog:
+	default:
+		return fmt.Errorf("invalid type %q: must be email, webhook or log", c.Type)
+	}
+	return nil
+}
+
+// ValidateWebhook checks a signed webhook before it is saved, defaulting
+// an empty minimum severity to info. The secret is checked by the caller,
+// which generates one if none was given.
+func ValidateWebhook(w *store.Webhook) error {
+	if w.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if w.MinSeverity == "" {
+		w.MinSeverity = event.SeverityInfo
+	}
+	if !w.MinSeverity.Valid() {
+		return fmt.Errorf("invalid min_severity %q: must be info, warning or critical", w.MinSeverity)
+	}
+	return validateWebhookURL(w.URL)
+}
+
+// validateWebhookURL checks that target is an absolute http or https URL.
+func validateWebhookURL(target string) error {
+	u, err := url.Parse(target)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid webhook URL %q: must be http or https", target)
+	}
+	return nil
+}
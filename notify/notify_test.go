@@ -0,0 +1,109 @@
+package notify
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.aimuz.me/mynt/event"
+	"go.aimuz.me/mynt/store"
+)
+
+// recorder is a Sender that records which channel got which event.
+type recorder struct {
+	mu   sync.Mutex
+	sent map[string][]string // channel name -> event types
+}
+
+func (r *recorder) Send(_ context.Context, ch store.NotificationChannel, evt event.Event) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sent[ch.Name] = append(r.sent[ch.Name], evt.Type)
+	return nil
+}
+
+func TestDispatch_SeverityFilter(t *testing.T) {
+	db, err := store.Open(":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	repo := store.NewNotificationChannelRepo(db)
+
+	require.NoError(t, repo.Save(&store.NotificationChannel{
+		Name: "pager", Type: store.ChannelWebhook, Target: "https://pager.example.com",
+		MinSeverity: event.SeverityCritical, Enabled: true,
+	}))
+	require.NoError(t, repo.Save(&store.NotificationChannel{
+		Name: "everything", Type: store.ChannelWebhook, Target: "https://all.example.com",
+		MinSeverity: event.SeverityInfo, Enabled: true,
+	}))
+
+	rec := &recorder{sent: map[string][]string{}}
+	d := NewDispatcher(event.NewBus(), repo, WithSender(store.ChannelWebhook, rec))
+
+	ctx := context.Background()
+	d.Dispatch(ctx, event.Event{Type: event.PoolDegraded})
+	d.Dispatch(ctx, event.Event{Type: event.DatasetCreated})
+
+	require.Equal(t, []string{event.PoolDegraded}, rec.sent["pager"])
+	require.Equal(t, []string{event.PoolDegraded, event.DatasetCreated}, rec.sent["everything"])
+}
+
+func TestDispatch_ChannelCache(t *testing.T) {
+	db, err := store.Open(":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	repo := store.NewNotificationChannelRepo(db)
+
+	rec := &recorder{sent: map[string][]string{}}
+	d := NewDispatcher(event.NewBus(), repo, WithSender(store.ChannelWebhook, rec))
+	ctx := context.Background()
+	d.Dispatch(ctx, event.Event{Type: event.PoolDegraded})
+
+	// A channel added behind the dispatcher's back is not seen until the
+	// cache is invalidated
+	require.NoError(t, repo.Save(&store.NotificationChannel{
+		Name: "hook", Type: store.ChannelWebhook, Target: "https://example.com",
+		MinSeverity: event.SeverityInfo, Enabled: true,
+	}))
+	d.Dispatch(ctx, event.Event{Type: event.PoolDegraded})
+	require.Empty(t, rec.sent["hook"])
+
+	d.InvalidateChannels()
+	d.Dispatch(ctx, event.Event{Type: event.PoolDegraded})
+	require.Equal(t, []string{event.PoolDegraded}, rec.sent["hook"])
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		channel store.NotificationChannel
+		wantErr bool
+	}{
+		{"log", store.NotificationChannel{Name: "log", Type: store.ChannelLog}, false},
+		{"email", store.NotificationChannel{Name: "ops", Type: store.ChannelEmail, Target: "ops@example.com"}, false},
+		{"webhook", store.NotificationChannel{Name: "hook", Type: store.ChannelWebhook, Target: "https://example.com/hook"}, false},
+		{"bad_email", store.NotificationChannel{Name: "ops", Type: store.ChannelEmail, Target: "not-an-address"}, true},
+		{"bad_webhook_scheme", store.NotificationChannel{Name: "hook", Type: store.ChannelWebhook, Target: "file:///etc/passwd"}, true},
+		{"unknown_type", store.NotificationChannel{Name: "sms", Type: "sms"}, true},
+		{"bad_severity", store.NotificationChannel{Name: "log", Type: store.ChannelLog, MinSeverity: "urgent"}, true},
+		{"missing_name", store.NotificationChannel{Type: store.ChannelLog}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(&tt.channel)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, event.SeverityInfo, tt.channel.MinSeverity)
+		})
+	}
+}
+
+func TestValidate_EmailAddress(t *testing.T) {
+	ch := store.NotificationChannel{Name: "ops", Type: store.ChannelEmail, Target: "NAS Ops <ops@example.com>"}
+	require.NoError(t, Validate(&ch))
+	require.Equal(t, "ops@example.com", ch.Target)
+}
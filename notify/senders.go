@@ -0,0 +1,93 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"go.aimuz.me/mynt/event"
+	"go.aimuz.me/mynt/logger"
+	"go.aimuz.me/mynt/store"
+)
+
+// LogSender writes events to the daemon log.
+type LogSender struct{}
+
+// Send logs evt at a level matching its severity.
+func (LogSender) Send(_ context.Context, ch store.NotificationChannel, evt event.Event) error {
+	args := []any{"channel", ch.Name, "event", evt.Type, "data", evt.Data}
+	switch evt.Severity() {
+	case event.SeverityCritical:
+		logger.Error("notification", args...)
+	case event.SeverityWarning:
+		logger.Warn("notification", args...)
+	default:
+		logger.Info("notification", args...)
+	}
+	return nil
+}
+
+// webhookPayload is the JSON body posted to webhook channels.
+type webhookPayload struct {
+	Type     string         `json:"type"`
+	Severity event.Severity `json:"severity"`
+	Time     time.Time      `json:"time"`
+	Data     any            `json:"data,omitempty"`
+}
+
+// WebhookSender posts events as JSON to the channel's URL.
+type WebhookSender struct {
+	client *http.Client
+}
+
+// NewWebhookSender creates a webhook sender using its own HTTP client.
+func NewWebhookSender() *WebhookSender {
+	return &WebhookSender{client: &http.Client{}}
+}
+
+// Send posts evt to the channel's URL. Any non-2xx response is an error.
+func (s *WebhookSender) Send(ctx context.Context, ch store.NotificationChannel, evt event.Event) error {
+	body, err := encodeWebhookPayload(evt)
+	if err != nil {
+		return err
+	}
+	_, err = postJSON(ctx, s.client, ch.Target, body, nil)
+	return err
+}
+
+// encodeWebhookPayload returns the JSON body posted for evt.
+func encodeWebhookPayload(evt event.Event) ([]byte, error) {
+	return json.Marshal(webhookPayload{
+		Type:     evt.Type,
+		Severity: evt.Severity(),
+		Time:     evt.Time,
+		Data:     evt.Data,
+	})
+}
+
+// postJSON posts body to url with the extra headers and returns the
+// response status. Any non-2xx response is an error; the status is 0 if
+// the server could not be reached.
+func postJSON(ctx context.Context, client *http.Client, url string, body []byte, header http.Header) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
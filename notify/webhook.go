@@ -0,0 +1,131 @@
+package notify
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.aimuz.me/mynt/event"
+	"go.aimuz.me/mynt/logger"
+	"go.aimuz.me/mynt/store"
+)
+
+// Headers of signed webhook requests.
+const (
+	// SignatureHeader carries "sha256=" followed by the hex HMAC-SHA256 of
+	// the request body, keyed with the webhook's secret. Receivers verify
+	// it by computing the same HMAC over the raw body and comparing the
+	// two in constant time.
+	SignatureHeader = "X-Mynt-Signature"
+	// EventHeader carries the event type, so receivers can route requests
+	// without parsing the body.
+	EventHeader = "X-Mynt-Event"
+)
+
+// webhookAttempts is how often delivery of an event to a webhook is
+// tried before it is recorded as failed.
+const webhookAttempts = 5
+
+// webhookRetryBackoff is the delay before retrying a failed delivery,
+// doubled after each attempt.
+var webhookRetryBackoff = 2 * time.Second
+
+// WebhookStore lists the signed webhooks and records their deliveries.
+// *store.WebhookRepo implements it.
+type WebhookStore interface {
+	List() ([]store.Webhook, error)
+	RecordDelivery(d *store.WebhookDelivery) error
+}
+
+// WithWebhooks also delivers events to the signed webhooks in src,
+// recording each delivery in its log. The webhook list is cached until
+// InvalidateWebhooks.
+func WithWebhooks(src WebhookStore) Option {
+	return func(d *Dispatcher) {
+		d.webhooks = &webhookSink{store: src, client: &http.Client{}}
+	}
+}
+
+// Sign returns the SignatureHeader value of body for secret.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookSink posts events to the signed webhooks of a WebhookStore.
+type webhookSink struct {
+	store  WebhookStore
+	client *http.Client
+	cache  listCache[store.Webhook] // webhooks between changes
+}
+
+// dispatch starts a delivery to every webhook matching evt, adding them
+// to wg.
+func (s *webhookSink) dispatch(ctx context.Context, wg *sync.WaitGroup, evt event.Event) {
+	hooks, err := s.cache.get(s.store.List)
+	if err != nil {
+		logger.Warn("failed to load webhooks", "error", err)
+		return
+	}
+	for _, hook := range hooks {
+		if hook.Matches(evt) {
+			wg.Go(func() { s.deliver(ctx, hook, evt) })
+		}
+	}
+}
+
+// deliver posts evt to hook, retrying with exponential backoff when the
+// receiver is unreachable, overloaded or failing, and records the outcome
+// in the webhook's delivery log. Other 4xx responses are not retried.
+func (s *webhookSink) deliver(ctx context.Context, hook store.Webhook, evt event.Event) {
+	body, err := encodeWebhookPayload(evt)
+	if err != nil {
+		logger.Warn("failed to encode webhook payload", "webhook", hook.Name, "event", evt.Type, "error", err)
+		return
+	}
+	header := http.Header{}
+	header.Set(SignatureHeader, Sign(hook.Secret, body))
+	header.Set(EventHeader, evt.Type)
+
+	d := store.WebhookDelivery{WebhookID: hook.ID, EventType: evt.Type}
+	backoff := webhookRetryBackoff
+	for {
+		d.Attempts++
+		attemptCtx, cancel := context.WithTimeout(ctx, sendTimeout)
+		d.Status, err = postJSON(attemptCtx, s.client, hook.URL, body, header)
+		cancel()
+		if err == nil || d.Attempts == webhookAttempts || !retryableStatus(d.Status) {
+			break
+		}
+
+		logger.Debug("retrying webhook", "webhook", hook.Name, "attempt", d.Attempts, "backoff", backoff, "error", err)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		backoff *= 2
+	}
+
+	if err != nil {
+		d.Error = err.Error()
+		logger.Warn("failed to deliver webhook", "webhook", hook.Name, "event", evt.Type, "attempts", d.Attempts, "error", err)
+	}
+	if err := s.store.RecordDelivery(&d); err != nil {
+		logger.Warn("failed to record webhook delivery", "webhook", hook.Name, "error", err)
+	}
+}
+
+// retryableStatus reports whether a failed delivery with the given
+// response status may succeed later: the receiver was unreachable (0),
+// rate limiting or failing.
+func retryableStatus(status int) bool {
+	return status == 0 || status == http.StatusTooManyRequests || status >= 500
+}
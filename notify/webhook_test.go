@@ -0,0 +1,118 @@
+package notify
+
+import (
+	"context"
+	"crypto/hmac"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.aimuz.me/mynt/event"
+	"go.aimuz.me/mynt/store"
+)
+
+func TestDispatch_Webhooks(t *testing.T) {
+	old := webhookRetryBackoff
+	webhookRetryBackoff = time.Millisecond
+	t.Cleanup(func() { webhookRetryBackoff = old })
+
+	// The receiver fails twice, then accepts requests with a valid signature
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		if !hmac.Equal([]byte(r.Header.Get(SignatureHeader)), []byte(Sign("s3cret", body))) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var payload webhookPayload
+		if err := json.Unmarshal(body, &payload); err != nil || payload.Type != r.Header.Get(EventHeader) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	db, err := store.Open(":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	repo := store.NewWebhookRepo(db)
+	hook := &store.Webhook{Name: "incidents", URL: srv.URL, Secret: "s3cret", MinSeverity: event.SeverityCritical, Enabled: true}
+	require.NoError(t, repo.Save(hook))
+
+	d := NewDispatcher(event.NewBus(), store.NewNotificationChannelRepo(db), WithWebhooks(repo))
+	ctx := context.Background()
+	d.Dispatch(ctx, event.Event{Type: event.DatasetCreated}) // below the webhook's severity
+	d.Dispatch(ctx, event.Event{Type: event.PoolDegraded, Time: time.Now()})
+
+	deliveries, err := repo.Deliveries(hook.ID, 10)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	require.Equal(t, event.PoolDegraded, deliveries[0].EventType)
+	require.Equal(t, 3, deliveries[0].Attempts)
+	require.Equal(t, http.StatusNoContent, deliveries[0].Status)
+	require.Empty(t, deliveries[0].Error)
+}
+
+func TestDispatch_WebhookClientError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusGone)
+	}))
+	defer srv.Close()
+
+	db, err := store.Open(":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	repo := store.NewWebhookRepo(db)
+	hook := &store.Webhook{Name: "gone", URL: srv.URL, Secret: "s", MinSeverity: event.SeverityInfo, Enabled: true}
+	require.NoError(t, repo.Save(hook))
+
+	d := NewDispatcher(event.NewBus(), store.NewNotificationChannelRepo(db), WithWebhooks(repo))
+	d.Dispatch(context.Background(), event.Event{Type: event.DiskAdded})
+
+	// A 4xx answer is final
+	got, err := repo.Get(hook.ID)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusGone, got.LastStatus)
+	require.Equal(t, "webhook returned 410 Gone", got.LastError)
+	deliveries, err := repo.Deliveries(hook.ID, 10)
+	require.NoError(t, err)
+	require.Equal(t, 1, deliveries[0].Attempts)
+}
+
+func TestDispatch_WebhookCache(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	db, err := store.Open(":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	repo := store.NewWebhookRepo(db)
+
+	d := NewDispatcher(event.NewBus(), store.NewNotificationChannelRepo(db), WithWebhooks(repo))
+	ctx := context.Background()
+	d.Dispatch(ctx, event.Event{Type: event.PoolDegraded})
+
+	// A webhook added behind the dispatcher's back is not seen until the
+	// cache is invalidated
+	require.NoError(t, repo.Save(&store.Webhook{Name: "hook", URL: srv.URL, Secret: "s", MinSeverity: event.SeverityInfo, Enabled: true}))
+	d.Dispatch(ctx, event.Event{Type: event.PoolDegraded})
+	require.Zero(t, calls.Load())
+
+	d.InvalidateWebhooks()
+	d.Dispatch(ctx, event.Event{Type: event.PoolDegraded})
+	require.Equal(t, int32(1), calls.Load())
+}
@@ -0,0 +1,58 @@
+package scheduler
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// MaxPreviewCount caps how many fire times PreviewSchedule returns.
+const MaxPreviewCount = 100
+
+// scheduleParser matches the parser behind cron.WithSeconds, which the
+// scheduler uses to run policies.
+var scheduleParser = cron.NewParser(
+	cron.Second | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor,
+)
+
+// NormalizeSchedule validates a policy schedule and returns it in the
+// canonical six-field form the scheduler runs, so that "@daily" and
+// "0 0 * * *" are stored as "0 0 0 * * *". Descriptors without a fixed
+// cron form, such as "@every 6h", are kept as written.
+func NormalizeSchedule(schedule string) (string, error) {
+	canonical := convertSchedule(strings.Join(strings.Fields(schedule), " "))
+	if _, err := scheduleParser.Parse(canonical); err != nil {
+		return "", fmt.Errorf("invalid schedule %q: %w", schedule, err)
+	}
+	return canonical, nil
+}
+
+// PreviewSchedule returns the next count times a policy schedule would fire.
+func PreviewSchedule(schedule string, count int) ([]time.Time, error) {
+	return previewSchedule(schedule, count, time.Now())
+}
+
+func previewSchedule(schedule string, count int, from time.Time) ([]time.Time, error) {
+	if count < 1 || count > MaxPreviewCount {
+		return nil, fmt.Errorf("count must be between 1 and %d", MaxPreviewCount)
+	}
+
+	sched, err := scheduleParser.Parse(convertSchedule(schedule))
+	if err != nil {
+		return nil, fmt.Errorf("invalid schedule %q: %w", schedule, err)
+	}
+
+	times := make([]time.Time, 0, count)
+	next := from
+	for range count {
+		next = sched.Next(next)
+		if next.IsZero() {
+			// The expression can never fire (e.g. February 30th)
+			break
+		}
+		times = append(times, next)
+	}
+	return times, nil
+}
@@ -0,0 +1,120 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPreviewSchedule(t *testing.T) {
+	from := time.Date(2025, 3, 10, 14, 30, 0, 0, time.UTC) // Monday
+
+	tests := []struct {
+		name     string
+		schedule string
+		count    int
+		want     []time.Time
+	}{
+		{
+			name:     "daily",
+			schedule: "@daily",
+			count:    3,
+			want: []time.Time{
+				time.Date(2025, 3, 11, 0, 0, 0, 0, time.UTC),
+				time.Date(2025, 3, 12, 0, 0, 0, 0, time.UTC),
+				time.Date(2025, 3, 13, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:     "every_15_minutes_weekdays",
+			schedule: "*/15 9-17 * * 1-5",
+			count:    4,
+			want: []time.Time{
+				time.Date(2025, 3, 10, 14, 45, 0, 0, time.UTC),
+				time.Date(2025, 3, 10, 15, 0, 0, 0, time.UTC),
+				time.Date(2025, 3, 10, 15, 15, 0, 0, time.UTC),
+				time.Date(2025, 3, 10, 15, 30, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:     "friday_evening",
+			schedule: "0 18 * * 5",
+			count:    2,
+			want: []time.Time{
+				time.Date(2025, 3, 14, 18, 0, 0, 0, time.UTC),
+				time.Date(2025, 3, 21, 18, 0, 0, 0, time.UTC),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := previewSchedule(tt.schedule, tt.count, from)
+			if err != nil {
+				t.Fatalf("previewSchedule: %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d times, want %d", len(got), len(tt.want))
+			}
+			for i := range got {
+				if !got[i].Equal(tt.want[i]) {
+					t.Errorf("time[%d] = %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestPreviewSchedule_Invalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		schedule string
+		count    int
+	}{
+		{"bad_expression", "not a schedule", 5},
+		{"unknown_descriptor", "@fortnightly", 5},
+		{"zero_count", "@daily", 0},
+		{"count_too_large", "@daily", MaxPreviewCount + 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := PreviewSchedule(tt.schedule, tt.count); err == nil {
+				t.Error("PreviewSchedule() error = nil, want error")
+			}
+		})
+	}
+}
+
+func TestNormalizeSchedule(t *testing.T) {
+	tests := []struct {
+		schedule string
+		want     string
+	}{
+		{"@hourly", "0 0 * * * *"},
+		{"@daily", "0 0 0 * * *"},
+		{"@weekly", "0 0 0 * * 0"},
+		{"@monthly", "0 0 0 1 * *"},
+		{"30 2 * * *", "0 30 2 * * *"},
+		{" 30  2 * *   * ", "0 30 2 * * *"},
+		{"0 */15 * * * *", "0 */15 * * * *"},
+		{"@every 6h", "@every 6h"},
+	}
+	for _, tt := range tests {
+		got, err := NormalizeSchedule(tt.schedule)
+		if err != nil {
+			t.Errorf("NormalizeSchedule(%q) error = %v", tt.schedule, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("NormalizeSchedule(%q) = %q, want %q", tt.schedule, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeSchedule_Invalid(t *testing.T) {
+	for _, schedule := range []string{"", "not a schedule", "@fortnightly", "61 * * * *", "* * *"} {
+		if got, err := NormalizeSchedule(schedule); err == nil {
+			t.Errorf("NormalizeSchedule(%q) = %q, want error", schedule, got)
+		}
+	}
+}
@@ -4,9 +4,13 @@ import (
 	"context"
 	"fmt"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
+
+	"go.aimuz.me/mynt/store"
+	"go.aimuz.me/mynt/zfs"
 )
 
 // runRetentionCleanup checks all policies and removes expired snapshots.
@@ -20,74 +24,230 @@ func (s *Scheduler) runRetentionCleanup(ctx context.Context) {
 	}
 
 	for _, policy := range policies {
-		if !policy.Enabled || policy.Retention == "forever" {
+		if !policy.Enabled {
 			continue
 		}
 
-		retention, err := parseRetention(policy.Retention)
+		targets, err := s.resolveTargets(ctx, policy)
 		if err != nil {
-			s.logger.Error("invalid retention format",
+			s.logger.Error("failed to resolve policy datasets",
 				"policy", policy.Name,
-				"retention", policy.Retention,
 				"error", err)
 			continue
 		}
 
-		s.cleanupPolicySnapshots(ctx, policy.Name, policy.Datasets, retention)
+		for _, target := range targets {
+			s.applyRetention(ctx, policy, target)
+		}
 	}
 }
 
+// applyRetention removes the snapshots a policy took of one target that
+// its retention no longer covers: keep counts if the target or the policy
+// has them, otherwise the policy's Retention duration.
+func (s *Scheduler) applyRetention(ctx context.Context, policy store.SnapshotPolicy, target store.PolicyTarget) {
+	if keep, ok := retentionFor(policy, target); ok {
+		s.cleanupKeepCounts(ctx, policy.Name, target.Dataset, keep)
+		return
+	}
+	if policy.Retention == "forever" {
+		return
+	}
+
+	retention, err := parseRetention(policy.Retention)
+	if err != nil {
+		s.logger.Error("invalid retention format",
+			"policy", policy.Name,
+			"retention", policy.Retention,
+			"error", err)
+		return
+	}
+	s.cleanupPolicySnapshots(ctx, policy.Name, target.Dataset, retention)
+}
+
 // cleanupPolicySnapshots removes snapshots older than the retention period.
-func (s *Scheduler) cleanupPolicySnapshots(ctx context.Context, policyName string, datasets []string, retention time.Duration) {
+func (s *Scheduler) cleanupPolicySnapshots(ctx context.Context, policyName, dataset string, retention time.Duration) {
 	cutoff := time.Now().Add(-retention)
 	prefix := fmt.Sprintf("auto-%s-", policyName)
 
-	for _, dataset := range datasets {
-		snapshots, err := s.zfsMgr.ListSnapshots(ctx, dataset)
+	snapshots, err := s.zfsMgr.ListSnapshots(ctx, dataset)
+	if err != nil {
+		s.logger.Error("failed to list snapshots for cleanup",
+			"dataset", dataset,
+			"error", err)
+		return
+	}
+
+	for _, snap := range snapshots {
+		// Only clean up snapshots created by this policy
+		parts := strings.Split(snap.Name, "@")
+		if len(parts) != 2 {
+			continue
+		}
+		snapName := parts[1]
+
+		if !strings.HasPrefix(snapName, prefix) {
+			continue
+		}
+
+		// Parse timestamp from snapshot name
+		// Format: auto-{policyName}-{YYYYMMDD-HHMMSS}
+		timestampStr := strings.TrimPrefix(snapName, prefix)
+		snapTime, err := parseSnapshotTimestamp(timestampStr)
 		if err != nil {
-			s.logger.Error("failed to list snapshots for cleanup",
-				"dataset", dataset,
+			s.logger.Debug("could not parse snapshot timestamp",
+				"snapshot", snap.Name,
 				"error", err)
 			continue
 		}
 
-		for _, snap := range snapshots {
-			// Only clean up snapshots created by this policy
-			parts := strings.Split(snap.Name, "@")
-			if len(parts) != 2 {
-				continue
-			}
-			snapName := parts[1]
-
-			if !strings.HasPrefix(snapName, prefix) {
-				continue
-			}
+		if snapTime.Before(cutoff) {
+			s.logger.Info("deleting expired snapshot",
+				"snapshot", snap.Name,
+				"policy", policyName,
+				"age", time.Since(snapTime).Round(time.Hour))
 
-			// Parse timestamp from snapshot name
-			// Format: auto-{policyName}-{YYYYMMDD-HHMMSS}
-			timestampStr := strings.TrimPrefix(snapName, prefix)
-			snapTime, err := parseSnapshotTimestamp(timestampStr)
-			if err != nil {
-				s.logger.Debug("could not parse snapshot timestamp",
+			if err := s.zfsMgr.DestroySnapshot(ctx, snap.Name); err != nil {
+				s.logger.Error("failed to delete expired snapshot",
 					"snapshot", snap.Name,
 					"error", err)
-				continue
 			}
+		}
+	}
+}
 
-			if snapTime.Before(cutoff) {
-				s.logger.Info("deleting expired snapshot",
-					"snapshot", snap.Name,
-					"policy", policyName,
-					"age", time.Since(snapTime).Round(time.Hour))
-
-				if err := s.zfsMgr.DestroySnapshot(ctx, snap.Name); err != nil {
-					s.logger.Error("failed to delete expired snapshot",
-						"snapshot", snap.Name,
-						"error", err)
-				}
-			}
+// keepCounts is a grandfather-father-son retention: the newest last
+// snapshots, plus the newest snapshot of each of the most recent daily
+// days, weekly ISO weeks and monthly months that have one.
+type keepCounts struct {
+	last, daily, weekly, monthly int
+}
+
+// keepCountsOf returns the count-based retention of a policy.
+func keepCountsOf(policy store.SnapshotPolicy) keepCounts {
+	return keepCounts{
+		last:    policy.KeepLast,
+		daily:   policy.KeepDaily,
+		weekly:  policy.KeepWeekly,
+		monthly: policy.KeepMonthly,
+	}
+}
+
+// retentionFor returns the keep counts for one target of a policy, and
+// false if the target falls back to the policy's Retention duration. A
+// target's KeepLast replaces the policy's.
+func retentionFor(policy store.SnapshotPolicy, target store.PolicyTarget) (keepCounts, bool) {
+	keep := keepCountsOf(policy)
+	if target.KeepLast != nil {
+		keep.last = *target.KeepLast
+		return keep, true
+	}
+	return keep, policy.HasKeepCounts()
+}
+
+// cleanupKeepCounts destroys the snapshots a policy took of a dataset
+// that its keep counts no longer cover.
+func (s *Scheduler) cleanupKeepCounts(ctx context.Context, policyName, dataset string, keep keepCounts) {
+	snapshots, err := s.zfsMgr.ListSnapshots(ctx, dataset)
+	if err != nil {
+		s.logger.Error("failed to list snapshots for cleanup",
+			"dataset", dataset,
+			"error", err)
+		return
+	}
+
+	for _, name := range expiredSnapshots(policyName, snapshots, keep) {
+		s.logger.Info("deleting snapshot beyond keep counts",
+			"snapshot", name,
+			"policy", policyName)
+
+		if err := s.zfsMgr.DestroySnapshot(ctx, name); err != nil {
+			s.logger.Error("failed to delete expired snapshot",
+				"snapshot", name,
+				"error", err)
+		}
+	}
+}
+
+// datedSnapshot is a policy snapshot with its creation time.
+type datedSnapshot struct {
+	name    string
+	created time.Time
+}
+
+// expiredSnapshots returns the snapshots created by the named policy that
+// keep does not retain. Only snapshots whose source is the policy are
+// considered, so manual snapshots and those of other policies are never
+// returned.
+func expiredSnapshots(policyName string, snapshots []zfs.Snapshot, keep keepCounts) []string {
+	source := "policy:" + policyName
+	var dated []datedSnapshot
+	for _, snap := range snapshots {
+		if snap.Source != source {
+			continue
 		}
+		created, ok := snapshotTime(snap, policyName)
+		if !ok {
+			continue
+		}
+		dated = append(dated, datedSnapshot{name: snap.Name, created: created})
+	}
+
+	// Newest first, so the first snapshot seen in a period is the one kept
+	slices.SortFunc(dated, func(a, b datedSnapshot) int {
+		return b.created.Compare(a.created)
+	})
+
+	kept := make(map[string]bool)
+	for i := range min(keep.last, len(dated)) {
+		kept[dated[i].name] = true
+	}
+	keepPerPeriod(dated, keep.daily, kept, func(t time.Time) string {
+		return t.Format("2006-01-02")
+	})
+	keepPerPeriod(dated, keep.weekly, kept, func(t time.Time) string {
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	})
+	keepPerPeriod(dated, keep.monthly, kept, func(t time.Time) string {
+		return t.Format("2006-01")
+	})
+
+	var expired []string
+	for _, d := range dated {
+		if !kept[d.name] {
+			expired = append(expired, d.name)
+		}
+	}
+	return expired
+}
+
+// keepPerPeriod marks the newest snapshot of each of the n most recent
+// periods, as named by period, that have a snapshot. dated must be sorted
+// newest first.
+func keepPerPeriod(dated []datedSnapshot, n int, kept map[string]bool, period func(time.Time) string) {
+	last := ""
+	for _, d := range dated {
+		if n <= 0 {
+			return
+		}
+		if p := period(d.created); p != last {
+			kept[d.name] = true
+			last = p
+			n--
+		}
+	}
+}
+
+// snapshotTime returns when a snapshot was created, from its creation
+// property or else the timestamp in its auto-<policy>-<timestamp> name.
+func snapshotTime(snap zfs.Snapshot, policyName string) (time.Time, bool) {
+	if t, err := time.Parse(time.RFC3339, snap.CreatedAt); err == nil {
+		return t, true
 	}
+	_, short, _ := strings.Cut(snap.Name, "@")
+	t, err := parseSnapshotTimestamp(strings.TrimPrefix(short, "auto-"+policyName+"-"))
+	return t, err == nil
 }
 
 // parseRetention parses retention strings like "24h", "7d", "30d", "365d".
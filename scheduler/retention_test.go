@@ -0,0 +1,130 @@
+package scheduler
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"go.aimuz.me/mynt/store"
+	"go.aimuz.me/mynt/zfs"
+)
+
+// dailySnapshots returns one snapshot of tank/data per day by policy,
+// taken at 01:00 UTC from first to last inclusive.
+func dailySnapshots(policy string, first, last time.Time) []zfs.Snapshot {
+	var snaps []zfs.Snapshot
+	for t := first; !t.After(last); t = t.AddDate(0, 0, 1) {
+		snaps = append(snaps, zfs.Snapshot{
+			Name:      "tank/data@auto-" + policy + "-" + t.Format("20060102-150405"),
+			Dataset:   "tank/data",
+			CreatedAt: t.Format(time.RFC3339),
+			Source:    "policy:" + policy,
+		})
+	}
+	return snaps
+}
+
+func TestExpiredSnapshots_GFS(t *testing.T) {
+	day := func(month time.Month, d int) time.Time {
+		return time.Date(2025, month, d, 1, 0, 0, 0, time.UTC)
+	}
+	// 2025-03-30 is the Sunday ending ISO week 13
+	snaps := dailySnapshots("daily", day(time.January, 15), day(time.March, 30))
+	// Never touched: a manual snapshot and one of a policy sharing the prefix
+	snaps = append(snaps,
+		zfs.Snapshot{Name: "tank/data@before-upgrade", CreatedAt: day(time.January, 1).Format(time.RFC3339), Source: "manual"},
+		zfs.Snapshot{Name: "tank/data@auto-daily-x-20250101-010000", CreatedAt: day(time.January, 1).Format(time.RFC3339), Source: "policy:daily-x"},
+	)
+
+	expired := expiredSnapshots("daily", snaps, keepCounts{last: 2, daily: 5, weekly: 3, monthly: 3})
+
+	kept := []time.Time{
+		day(time.March, 30), day(time.March, 29), // last
+		day(time.March, 28), day(time.March, 27), day(time.March, 26), // daily
+		day(time.March, 23), day(time.March, 16), // weekly: Sundays of weeks 12 and 11
+		day(time.February, 28), day(time.January, 31), // monthly
+	}
+	total := int(day(time.March, 30).Sub(day(time.January, 15)).Hours()/24) + 1
+	if want := total - len(kept); len(expired) != want {
+		t.Errorf("expired %d snapshots, want %d", len(expired), want)
+	}
+	for _, k := range kept {
+		name := "tank/data@auto-daily-" + k.Format("20060102-150405")
+		if slices.Contains(expired, name) {
+			t.Errorf("%s expired, want kept", name)
+		}
+	}
+	for _, name := range []string{"tank/data@before-upgrade", "tank/data@auto-daily-x-20250101-010000"} {
+		if slices.Contains(expired, name) {
+			t.Errorf("%s expired, but it is not a snapshot of the policy", name)
+		}
+	}
+}
+
+func TestExpiredSnapshots_KeepLastOnly(t *testing.T) {
+	first := time.Date(2025, time.March, 1, 1, 0, 0, 0, time.UTC)
+	snaps := dailySnapshots("hourly", first, first.AddDate(0, 0, 4))
+	// ListSnapshots sorts by creation, but expiredSnapshots must not rely on it
+	slices.Reverse(snaps)
+
+	expired := expiredSnapshots("hourly", snaps, keepCounts{last: 3})
+
+	want := []string{
+		"tank/data@auto-hourly-20250302-010000",
+		"tank/data@auto-hourly-20250301-010000",
+	}
+	if !slices.Equal(expired, want) {
+		t.Errorf("expired = %v, want %v", expired, want)
+	}
+}
+
+func TestExpiredSnapshots_NameTimestampFallback(t *testing.T) {
+	snaps := []zfs.Snapshot{
+		{Name: "tank/data@auto-p-20250101-000000", Source: "policy:p"},
+		{Name: "tank/data@auto-p-20250102-000000", Source: "policy:p"},
+	}
+
+	expired := expiredSnapshots("p", snaps, keepCounts{last: 1})
+
+	if want := []string{"tank/data@auto-p-20250101-000000"}; !slices.Equal(expired, want) {
+		t.Errorf("expired = %v, want %v", expired, want)
+	}
+}
+
+func TestKeepCountsOf(t *testing.T) {
+	p := store.SnapshotPolicy{KeepLast: 1, KeepDaily: 2, KeepWeekly: 3, KeepMonthly: 4}
+	if got, want := keepCountsOf(p), (keepCounts{last: 1, daily: 2, weekly: 3, monthly: 4}); got != want {
+		t.Errorf("keepCountsOf() = %+v, want %+v", got, want)
+	}
+	if !p.HasKeepCounts() || (store.SnapshotPolicy{Retention: "7d"}).HasKeepCounts() {
+		t.Error("HasKeepCounts() is wrong")
+	}
+}
+
+func TestRetentionFor(t *testing.T) {
+	two := 2
+	timed := store.SnapshotPolicy{Retention: "30d"}
+	gfs := store.SnapshotPolicy{KeepLast: 24, KeepDaily: 7}
+
+	tests := []struct {
+		name   string
+		policy store.SnapshotPolicy
+		target store.PolicyTarget
+		want   keepCounts
+		wantOK bool
+	}{
+		{"time_based", timed, store.PolicyTarget{Dataset: "tank/docs"}, keepCounts{}, false},
+		{"time_based_override", timed, store.PolicyTarget{Dataset: "tank/media", KeepLast: &two}, keepCounts{last: 2}, true},
+		{"gfs", gfs, store.PolicyTarget{Dataset: "tank/docs"}, keepCounts{last: 24, daily: 7}, true},
+		{"gfs_override", gfs, store.PolicyTarget{Dataset: "tank/media", KeepLast: &two}, keepCounts{last: 2, daily: 7}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := retentionFor(tt.policy, tt.target)
+			if ok != tt.wantOK || (ok && got != tt.want) {
+				t.Errorf("retentionFor() = %+v, %v, want %+v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
@@ -1,4 +1,5 @@
-// Package scheduler provides cron-based snapshot policy execution.
+// Package scheduler provides cron-based snapshot policy execution and
+// periodic pool and disk maintenance.
 package scheduler
 
 import (
@@ -21,19 +22,37 @@ type Scheduler struct {
 	zfsMgr     *zfs.Manager
 	logger     *slog.Logger
 
-	mu       sync.RWMutex
-	entryIDs map[int64]cron.EntryID // policyID -> cronEntryID
+	// SMART self-test scheduling, unset unless WithSmartTests is given
+	smartPolicies *store.SmartTestPolicyRepo
+	diskRepo      *store.DiskRepo
+	smartDisks    smartTester
+
+	mu            sync.RWMutex
+	entryIDs      map[int64]cron.EntryID // policyID -> cronEntryID
+	smartEntryIDs map[int64]cron.EntryID // SMART test policyID -> cronEntryID
+
+	testLocksMu sync.Mutex
+	testLocks   map[string]*sync.Mutex // pool -> lock held while one of its disks self-tests
 }
 
+// Option configures a Scheduler.
+type Option func(*Scheduler)
+
 // New creates a new Scheduler.
-func New(policyRepo *store.SnapshotPolicyRepo, zfsMgr *zfs.Manager) *Scheduler {
-	return &Scheduler{
-		cron:       cron.New(cron.WithSeconds()),
-		policyRepo: policyRepo,
-		zfsMgr:     zfsMgr,
-		logger:     slog.Default(),
-		entryIDs:   make(map[int64]cron.EntryID),
+func New(policyRepo *store.SnapshotPolicyRepo, zfsMgr *zfs.Manager, opts ...Option) *Scheduler {
+	s := &Scheduler{
+		cron:          cron.New(cron.WithSeconds()),
+		policyRepo:    policyRepo,
+		zfsMgr:        zfsMgr,
+		logger:        slog.Default(),
+		entryIDs:      make(map[int64]cron.EntryID),
+		smartEntryIDs: make(map[int64]cron.EntryID),
+		testLocks:     make(map[string]*sync.Mutex),
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 // Start begins the scheduler and loads all policies.
@@ -53,6 +72,14 @@ func (s *Scheduler) Start(ctx context.Context) error {
 		return fmt.Errorf("failed to add retention cleanup job: %w", err)
 	}
 
+	// Add pool trim job (runs at 03:00 on the 1st of each month)
+	_, err = s.cron.AddFunc("0 0 3 1 * *", func() {
+		s.runPoolTrim(ctx)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add pool trim job: %w", err)
+	}
+
 	s.cron.Start()
 	s.logger.Info("snapshot policy scheduler started", "policies", len(s.entryIDs))
 
@@ -67,7 +94,7 @@ func (s *Scheduler) Stop() {
 	s.logger.Info("snapshot policy scheduler stopped")
 }
 
-// Reload reloads all policies from the database.
+// Reload reloads all snapshot and SMART test policies from the database.
 // Call this after creating, updating, or deleting a policy.
 func (s *Scheduler) Reload() error {
 	s.mu.Lock()
@@ -100,7 +127,13 @@ func (s *Scheduler) Reload() error {
 		}
 	}
 
-	s.logger.Info("policies reloaded", "scheduled", len(s.entryIDs))
+	if s.smartPolicies != nil {
+		if err := s.reloadSmartPolicies(); err != nil {
+			return err
+		}
+	}
+
+	s.logger.Info("policies reloaded", "scheduled", len(s.entryIDs), "smart_tests", len(s.smartEntryIDs))
 	return nil
 }
 
@@ -156,19 +189,29 @@ func convertSchedule(schedule string) string {
 	}
 }
 
-// executePolicy creates snapshots for all datasets in a policy.
+// executePolicy creates snapshots for all datasets in a policy. Datasets
+// with their own KeepLast are pruned right after, so they never hold more
+// snapshots than the override allows until the next hourly cleanup.
 func (s *Scheduler) executePolicy(policy store.SnapshotPolicy) {
 	ctx := context.Background()
 	timestamp := time.Now().Format("20060102-150405")
 	snapshotName := fmt.Sprintf("auto-%s-%s", policy.Name, timestamp)
 
+	targets, err := s.resolveTargets(ctx, policy)
+	if err != nil {
+		s.logger.Error("failed to resolve policy datasets",
+			"policy", policy.Name,
+			"error", err)
+		return
+	}
+
 	s.logger.Info("executing snapshot policy",
 		"policy", policy.Name,
-		"datasets", len(policy.Datasets))
+		"datasets", len(targets))
 
-	for _, dataset := range policy.Datasets {
+	for _, target := range targets {
 		req := zfs.CreateSnapshotRequest{
-			Dataset: dataset,
+			Dataset: target.Dataset,
 			Name:    snapshotName,
 		}
 
@@ -176,7 +219,7 @@ func (s *Scheduler) executePolicy(policy store.SnapshotPolicy) {
 		if err != nil {
 			s.logger.Error("failed to create snapshot",
 				"policy", policy.Name,
-				"dataset", dataset,
+				"dataset", target.Dataset,
 				"error", err)
 			continue
 		}
@@ -187,5 +230,9 @@ func (s *Scheduler) executePolicy(policy store.SnapshotPolicy) {
 		s.logger.Info("snapshot created by policy",
 			"policy", policy.Name,
 			"snapshot", snapshot.Name)
+
+		if target.KeepLast != nil {
+			s.applyRetention(ctx, policy, target)
+		}
 	}
 }
@@ -0,0 +1,48 @@
+package scheduler
+
+import (
+	"testing"
+
+	"go.aimuz.me/mynt/store"
+)
+
+func TestReload_ReschedulesFromDB(t *testing.T) {
+	db, err := store.Open(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	repo := store.NewSnapshotPolicyRepo(db)
+	s := New(repo, nil)
+
+	hourly := &store.SnapshotPolicy{Name: "hourly", Schedule: "@hourly", Retention: "24h", Datasets: []store.PolicyTarget{{Dataset: "tank/data"}}, Enabled: true}
+	if err := repo.Save(hourly); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Reload(); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	if len(s.entryIDs) != 1 {
+		t.Fatalf("scheduled %d policies, want 1", len(s.entryIDs))
+	}
+
+	// Out-of-band changes: one policy disabled, another added
+	hourly.Enabled = false
+	if err := repo.Update(hourly); err != nil {
+		t.Fatal(err)
+	}
+	daily := &store.SnapshotPolicy{Name: "daily", Schedule: "@daily", Retention: "7d", Datasets: []store.PolicyTarget{{Dataset: "tank/data"}}, Enabled: true}
+	if err := repo.Save(daily); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Reload(); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+
+	if _, ok := s.entryIDs[daily.ID]; !ok || len(s.entryIDs) != 1 {
+		t.Errorf("scheduled %v, want only the daily policy (id %d)", s.entryIDs, daily.ID)
+	}
+	if got := len(s.cron.Entries()); got != 1 {
+		t.Errorf("cron has %d entries, want 1", got)
+	}
+}
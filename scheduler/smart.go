@@ -0,0 +1,176 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"go.aimuz.me/mynt/disk"
+	"go.aimuz.me/mynt/store"
+)
+
+// smartTestPollInterval is how often a running self-test is checked for
+// completion.
+var smartTestPollInterval = time.Minute
+
+// smartTestTimeout bounds the wait for one self-test. Long tests of large
+// disks take the better part of a day.
+const smartTestTimeout = 24 * time.Hour
+
+// smartTester runs SMART self-tests. *disk.Manager implements it.
+type smartTester interface {
+	ListBasic(ctx context.Context) ([]disk.Info, error)
+	SmartTest(ctx context.Context, name string, typ disk.TestType, opts ...disk.SmartOption) error
+	SmartTestStatus(ctx context.Context, name string, opts ...disk.SmartOption) (*disk.TestStatus, error)
+}
+
+// WithSmartTests schedules the SMART self-test policies in policies,
+// testing the attached disks recorded in diskRepo with diskMgr.
+func WithSmartTests(policies *store.SmartTestPolicyRepo, diskRepo *store.DiskRepo, diskMgr *disk.Manager) Option {
+	return func(s *Scheduler) {
+		s.smartPolicies = policies
+		s.diskRepo = diskRepo
+		s.smartDisks = diskMgr
+	}
+}
+
+// reloadSmartPolicies replaces the scheduled SMART test policies with the
+// enabled ones in the database. The caller holds s.mu.
+func (s *Scheduler) reloadSmartPolicies() error {
+	for id, entryID := range s.smartEntryIDs {
+		s.cron.Remove(entryID)
+		delete(s.smartEntryIDs, id)
+	}
+
+	policies, err := s.smartPolicies.List()
+	if err != nil {
+		return fmt.Errorf("failed to list SMART test policies: %w", err)
+	}
+
+	for _, policy := range policies {
+		if !policy.Enabled {
+			continue
+		}
+		entryID, err := s.cron.AddFunc(convertSchedule(policy.Schedule), func() {
+			s.runSmartPolicy(context.Background(), policy)
+		})
+		if err != nil {
+			s.logger.Error("failed to schedule SMART test policy",
+				"policy", policy.Name,
+				"schedule", policy.Schedule,
+				"error", err)
+			continue
+		}
+		s.smartEntryIDs[policy.ID] = entryID
+	}
+	return nil
+}
+
+// runSmartPolicy self-tests the disks of a policy. Disks of the same pool
+// are tested one after another, so a pool never has two disks busy
+// testing and its controller stays free for regular I/O; pools are tested
+// in parallel.
+func (s *Scheduler) runSmartPolicy(ctx context.Context, policy store.SmartTestPolicy) {
+	attached, err := s.diskRepo.ListAttached()
+	if err != nil {
+		s.logger.Error("failed to list disks for SMART tests", "policy", policy.Name, "error", err)
+		return
+	}
+
+	// Disks outside any pool, or all disks if pool membership is unknown,
+	// share the "" group
+	poolOf := map[string]string{}
+	if infos, err := s.smartDisks.ListBasic(ctx); err != nil {
+		s.logger.Warn("failed to read disk pool membership", "error", err)
+	} else {
+		for _, d := range infos {
+			poolOf[d.Name] = d.Pool
+		}
+	}
+
+	groups := map[string][]string{}
+	for _, d := range attached {
+		if len(policy.Disks) > 0 && !slices.Contains(policy.Disks, d.Name) {
+			continue
+		}
+		pool := poolOf[d.Name]
+		groups[pool] = append(groups[pool], d.Name)
+	}
+
+	if err := s.smartPolicies.MarkRun(policy.ID, time.Now()); err != nil {
+		s.logger.Warn("failed to record SMART test policy run", "policy", policy.Name, "error", err)
+	}
+	s.logger.Info("running SMART test policy",
+		"policy", policy.Name,
+		"type", policy.TestType,
+		"pools", len(groups))
+
+	var wg sync.WaitGroup
+	for pool, names := range groups {
+		wg.Go(func() {
+			mu := s.poolTestLock(pool)
+			mu.Lock()
+			defer mu.Unlock()
+			for _, name := range names {
+				s.runSmartTest(ctx, name, policy.TestType)
+			}
+		})
+	}
+	wg.Wait()
+}
+
+// poolTestLock returns the lock serializing self-tests of a pool's disks,
+// shared by all policies.
+func (s *Scheduler) poolTestLock(pool string) *sync.Mutex {
+	s.testLocksMu.Lock()
+	defer s.testLocksMu.Unlock()
+	mu, ok := s.testLocks[pool]
+	if !ok {
+		mu = &sync.Mutex{}
+		s.testLocks[pool] = mu
+	}
+	return mu
+}
+
+// runSmartTest starts a self-test, waits for it to finish and records its
+// result.
+func (s *Scheduler) runSmartTest(ctx context.Context, name string, typ disk.TestType) {
+	if err := s.smartDisks.SmartTest(ctx, name, typ); err != nil {
+		s.logger.Error("failed to start SMART test", "disk", name, "type", typ, "error", err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, smartTestTimeout)
+	defer cancel()
+	ticker := time.NewTicker(smartTestPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.logger.Warn("gave up waiting for SMART test", "disk", name, "type", typ)
+			return
+		case <-ticker.C:
+		}
+
+		status, err := s.smartDisks.SmartTestStatus(ctx, name)
+		if err != nil {
+			s.logger.Warn("failed to read SMART test status", "disk", name, "error", err)
+			continue
+		}
+		if status.Running {
+			continue
+		}
+
+		s.logger.Info("SMART test finished", "disk", name, "type", typ, "result", status.LastResult)
+		if status.LastResult == "" {
+			return
+		}
+		if err := s.diskRepo.SaveSmartTestResult(name, status.LastResult, time.Now()); err != nil {
+			s.logger.Warn("failed to save SMART test result", "disk", name, "error", err)
+		}
+		return
+	}
+}
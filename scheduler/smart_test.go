@@ -0,0 +1,135 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"go.aimuz.me/mynt/disk"
+	"go.aimuz.me/mynt/store"
+)
+
+// fakeTester is a smartTester whose tests finish on the second status
+// check. It records the most disks of one pool ever tested at once.
+type fakeTester struct {
+	pools map[string]string // disk -> pool
+
+	mu       sync.Mutex
+	running  map[string]int // pool -> disks under test
+	maxBusy  map[string]int
+	checks   map[string]int
+	finished []string
+}
+
+func (f *fakeTester) ListBasic(context.Context) ([]disk.Info, error) {
+	var infos []disk.Info
+	for name, pool := range f.pools {
+		infos = append(infos, disk.Info{Name: name, Pool: pool})
+	}
+	return infos, nil
+}
+
+func (f *fakeTester) SmartTest(_ context.Context, name string, _ disk.TestType, _ ...disk.SmartOption) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	pool := f.pools[name]
+	f.running[pool]++
+	f.maxBusy[pool] = max(f.maxBusy[pool], f.running[pool])
+	return nil
+}
+
+func (f *fakeTester) SmartTestStatus(_ context.Context, name string, _ ...disk.SmartOption) (*disk.TestStatus, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.checks[name]++
+	if f.checks[name] < 2 {
+		return &disk.TestStatus{Running: true, Progress: 50}, nil
+	}
+	f.running[f.pools[name]]--
+	f.finished = append(f.finished, name)
+	return &disk.TestStatus{LastResult: "Completed without error"}, nil
+}
+
+func TestRunSmartPolicy_SerializesPerPool(t *testing.T) {
+	old := smartTestPollInterval
+	smartTestPollInterval = time.Millisecond
+	t.Cleanup(func() { smartTestPollInterval = old })
+
+	db, err := store.Open(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	diskRepo := store.NewDiskRepo(db)
+	policies := store.NewSmartTestPolicyRepo(db)
+
+	tester := &fakeTester{
+		pools:   map[string]string{"sda": "tank", "sdb": "tank", "sdc": "tank", "sdd": "backup", "sde": ""},
+		running: map[string]int{},
+		maxBusy: map[string]int{},
+		checks:  map[string]int{},
+	}
+	for name := range tester.pools {
+		if err := diskRepo.Save(disk.Info{Name: name, Serial: name}); err != nil {
+			t.Fatal(err)
+		}
+		if err := diskRepo.SaveSmart(&disk.DetailedReport{Disk: name, Passed: true}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s := New(nil, nil, WithSmartTests(policies, diskRepo, nil))
+	s.smartDisks = tester
+
+	policy := store.SmartTestPolicy{Name: "weekly", Schedule: "@weekly", TestType: disk.TestShort, Disks: []string{"sda", "sdb", "sdc", "sdd"}, Enabled: true}
+	if err := policies.Save(&policy); err != nil {
+		t.Fatal(err)
+	}
+	s.runSmartPolicy(context.Background(), policy)
+
+	if len(tester.finished) != 4 {
+		t.Errorf("tested %v, want sda-sdd", tester.finished)
+	}
+	if tester.maxBusy["tank"] != 1 {
+		t.Errorf("tank had %d disks testing at once, want 1", tester.maxBusy["tank"])
+	}
+	if _, ok := tester.checks["sde"]; ok {
+		t.Error("sde was tested but is not in the policy")
+	}
+
+	smart, err := diskRepo.GetSmart("sdb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if smart.LastTestResult != "Completed without error" || smart.LastTestAt == nil {
+		t.Errorf("sdb result = %q at %v", smart.LastTestResult, smart.LastTestAt)
+	}
+	got, err := policies.Get(policy.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.LastRunAt == nil {
+		t.Error("LastRunAt not recorded")
+	}
+}
+
+func TestReload_SmartPolicies(t *testing.T) {
+	db, err := store.Open(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	s := New(store.NewSnapshotPolicyRepo(db), nil,
+		WithSmartTests(store.NewSmartTestPolicyRepo(db), store.NewDiskRepo(db), nil))
+
+	// The two default policies
+	if err := s.Reload(); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	if len(s.smartEntryIDs) != 2 {
+		t.Errorf("scheduled %d SMART test policies, want 2", len(s.smartEntryIDs))
+	}
+	if got := len(s.cron.Entries()); got != 2 {
+		t.Errorf("cron has %d entries, want 2", got)
+	}
+}
@@ -0,0 +1,57 @@
+package scheduler
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"go.aimuz.me/mynt/store"
+)
+
+// resolveTargets returns the datasets a policy snapshots. Recursive
+// policies list the existing datasets so excluded children can be skipped.
+func (s *Scheduler) resolveTargets(ctx context.Context, policy store.SnapshotPolicy) ([]store.PolicyTarget, error) {
+	if !policy.Recursive {
+		return policy.Datasets, nil
+	}
+
+	datasets, err := s.zfsMgr.ListDatasets(ctx)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(datasets))
+	for i, ds := range datasets {
+		names[i] = ds.Name
+	}
+	return policyTargets(policy, names), nil
+}
+
+// policyTargets expands a policy's datasets against all known dataset
+// names. A recursive policy includes every descendant of its datasets
+// except those matching an exclude pattern, and descendants of excluded
+// datasets are skipped too. Each target is snapshotted individually, so
+// "zfs snapshot -r" is never used. Descendants share the retention
+// override of the dataset they were found under.
+func policyTargets(policy store.SnapshotPolicy, all []string) []store.PolicyTarget {
+	if !policy.Recursive {
+		return policy.Datasets
+	}
+
+	var targets []store.PolicyTarget
+	covered := func(name string) bool {
+		return slices.ContainsFunc(targets, func(t store.PolicyTarget) bool { return t.Dataset == name })
+	}
+	for _, root := range policy.Datasets {
+		if !covered(root.Dataset) {
+			targets = append(targets, root)
+		}
+		for _, name := range all {
+			rel, ok := strings.CutPrefix(name, root.Dataset+"/")
+			if !ok || covered(name) || policy.Excludes(root.Dataset, rel) {
+				continue
+			}
+			targets = append(targets, store.PolicyTarget{Dataset: name, KeepLast: root.KeepLast})
+		}
+	}
+	return targets
+}
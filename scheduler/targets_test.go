@@ -0,0 +1,121 @@
+package scheduler
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"go.aimuz.me/mynt/store"
+)
+
+func TestPolicyTargets_Exclude(t *testing.T) {
+	all := []string{
+		"tank",
+		"tank/data",
+		"tank/data/docs",
+		"tank/data/media",
+		"tank/data/media/movies",
+		"tank/data/scratch",
+		"tank/other",
+	}
+
+	tests := []struct {
+		name    string
+		exclude []string
+		want    []string
+	}{
+		{
+			name:    "relative_pattern",
+			exclude: []string{"scratch"},
+			want:    []string{"tank/data", "tank/data/docs", "tank/data/media", "tank/data/media/movies"},
+		},
+		{
+			name:    "full_name_glob",
+			exclude: []string{"tank/data/s*"},
+			want:    []string{"tank/data", "tank/data/docs", "tank/data/media", "tank/data/media/movies"},
+		},
+		{
+			name:    "excluded_parent_skips_descendants",
+			exclude: []string{"media"},
+			want:    []string{"tank/data", "tank/data/docs", "tank/data/scratch"},
+		},
+		{
+			name: "no_exclude",
+			want: []string{"tank/data", "tank/data/docs", "tank/data/media", "tank/data/media/movies", "tank/data/scratch"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := store.SnapshotPolicy{
+				Datasets:  []store.PolicyTarget{{Dataset: "tank/data"}},
+				Recursive: true,
+				Exclude:   tt.exclude,
+			}
+			got := targetNames(policyTargets(policy, all))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("policyTargets() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPolicyTargets_ThreeChildrenOneExcluded(t *testing.T) {
+	policy := store.SnapshotPolicy{
+		Datasets:  []store.PolicyTarget{{Dataset: "tank/parent"}},
+		Recursive: true,
+		Exclude:   []string{"b"},
+	}
+	all := []string{"tank", "tank/parent", "tank/parent/a", "tank/parent/b", "tank/parent/c"}
+
+	got := targetNames(policyTargets(policy, all))
+
+	var children []string
+	for _, name := range got {
+		if name != "tank/parent" {
+			children = append(children, name)
+		}
+	}
+	if want := []string{"tank/parent/a", "tank/parent/c"}; !slices.Equal(children, want) {
+		t.Errorf("snapshotted children = %v, want %v", children, want)
+	}
+}
+
+func TestPolicyTargets_NotRecursive(t *testing.T) {
+	policy := store.SnapshotPolicy{
+		Datasets: []store.PolicyTarget{{Dataset: "tank/data"}},
+		Exclude:  []string{"*"},
+	}
+	got := targetNames(policyTargets(policy, []string{"tank/data", "tank/data/docs"}))
+	if want := []string{"tank/data"}; !slices.Equal(got, want) {
+		t.Errorf("policyTargets() = %v, want %v", got, want)
+	}
+}
+
+func TestPolicyTargets_InheritOverride(t *testing.T) {
+	keep := 2
+	policy := store.SnapshotPolicy{
+		Datasets:  []store.PolicyTarget{{Dataset: "tank/media", KeepLast: &keep}, {Dataset: "tank/docs"}},
+		Recursive: true,
+	}
+	got := policyTargets(policy, []string{"tank/media", "tank/media/movies", "tank/docs", "tank/docs/old"})
+
+	for _, target := range got {
+		overridden := strings.HasPrefix(target.Dataset, "tank/media")
+		if overridden != (target.KeepLast != nil) || (overridden && *target.KeepLast != keep) {
+			t.Errorf("target %s has keep_last %v, want override only under tank/media", target.Dataset, target.KeepLast)
+		}
+	}
+	if len(got) != 4 {
+		t.Errorf("got %d targets, want 4", len(got))
+	}
+}
+
+// targetNames returns the dataset names of targets.
+func targetNames(targets []store.PolicyTarget) []string {
+	var names []string
+	for _, t := range targets {
+		names = append(names, t.Dataset)
+	}
+	return names
+}
@@ -0,0 +1,39 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+
+	"go.aimuz.me/mynt/zfs"
+)
+
+// runPoolTrim starts a TRIM of every writable pool. Even with autotrim on,
+// a periodic full trim catches ranges that were too small to trim as they
+// were freed. Pools on devices without trim support are skipped quietly.
+func (s *Scheduler) runPoolTrim(ctx context.Context) {
+	s.logger.Debug("running scheduled pool trim")
+
+	pools, err := s.zfsMgr.ListPools(ctx)
+	if err != nil {
+		s.logger.Error("failed to list pools for trim", "error", err)
+		return
+	}
+
+	for _, pool := range pools {
+		if pool.ReadOnly {
+			continue
+		}
+
+		err := s.zfsMgr.Trim(ctx, pool.Name)
+		switch {
+		case errors.Is(err, zfs.ErrTrimUnsupported):
+			s.logger.Debug("pool does not support trim", "pool", pool.Name)
+		case err != nil:
+			s.logger.Error("failed to start pool trim",
+				"pool", pool.Name,
+				"error", err)
+		default:
+			s.logger.Info("started scheduled pool trim", "pool", pool.Name)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package share
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"go.aimuz.me/mynt/logger"
+	"go.aimuz.me/mynt/zfs"
+)
+
+// DatasetLister lists ZFS datasets. It is implemented by *zfs.Manager.
+type DatasetLister interface {
+	ListDatasets(ctx context.Context) ([]zfs.Dataset, error)
+}
+
+// Option configures a Manager.
+type Option func(*Manager)
+
+// WithDatasets resolves share paths against the mountpoints of the
+// datasets returned by lister. If strict is true, CreateShare rejects
+// paths outside any dataset; otherwise it only logs a warning.
+func WithDatasets(lister DatasetLister, strict bool) Option {
+	return func(m *Manager) {
+		m.datasets = lister
+		m.strictPaths = strict
+	}
+}
+
+// resolveDataset returns the dataset owning path, enforcing the
+// configured strictness when none is found.
+func (m *Manager) resolveDataset(path string) (string, error) {
+	if m.datasets == nil {
+		return "", nil
+	}
+
+	datasets, err := m.datasets.ListDatasets(context.Background())
+	if err != nil {
+		if m.strictPaths {
+			return "", fmt.Errorf("failed to list datasets: %w", err)
+		}
+		logger.Warn("failed to list datasets for share path", "path", path, "error", err)
+		return "", nil
+	}
+
+	// Resolve symlinks so a link into a dataset is not mistaken for one
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+
+	name, ok := DatasetForPath(path, datasets)
+	if !ok {
+		if m.strictPaths {
+			return "", fmt.Errorf("path is not inside a ZFS dataset: %s", path)
+		}
+		logger.Warn("share path is not inside a ZFS dataset", "path", path)
+	}
+	return name, nil
+}
+
+// DatasetForPath returns the dataset whose mountpoint most closely contains
+// path. Datasets without a real mountpoint (volumes, "none", "legacy") are
+// ignored.
+func DatasetForPath(path string, datasets []zfs.Dataset) (string, bool) {
+	path = filepath.Clean(path)
+
+	var best string
+	bestLen := -1
+	for _, ds := range datasets {
+		mp := ds.Mountpoint
+		if !filepath.IsAbs(mp) {
+			continue
+		}
+		mp = filepath.Clean(mp)
+		if !withinDir(path, mp) {
+			continue
+		}
+		if len(mp) > bestLen {
+			best, bestLen = ds.Name, len(mp)
+		}
+	}
+	return best, bestLen >= 0
+}
+
+// withinDir reports whether path is dir or lies beneath it.
+func withinDir(path, dir string) bool {
+	if path == dir || dir == "/" {
+		return true
+	}
+	return strings.HasPrefix(path, dir+"/")
+}
@@ -0,0 +1,124 @@
+package share
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.aimuz.me/mynt/store"
+	"go.aimuz.me/mynt/zfs"
+)
+
+type staticDatasets []zfs.Dataset
+
+func (s staticDatasets) ListDatasets(ctx context.Context) ([]zfs.Dataset, error) {
+	return s, nil
+}
+
+type failingDatasets struct{}
+
+func (failingDatasets) ListDatasets(ctx context.Context) ([]zfs.Dataset, error) {
+	return nil, errors.New("zfs not available")
+}
+
+func TestDatasetForPath(t *testing.T) {
+	datasets := []zfs.Dataset{
+		{Name: "tank", Mountpoint: "/tank"},
+		{Name: "tank/media", Mountpoint: "/tank/media"},
+		{Name: "tank/legacy", Mountpoint: "legacy"},
+		{Name: "tank/vol", Type: zfs.DatasetVolume, Mountpoint: "-"},
+	}
+
+	tests := []struct {
+		name   string
+		path   string
+		want   string
+		wantOK bool
+	}{
+		{"mountpoint", "/tank", "tank", true},
+		{"inside", "/tank/projects", "tank", true},
+		{"nested_dataset", "/tank/media/movies", "tank/media", true},
+		{"trailing_slash", "/tank/media/", "tank/media", true},
+		{"unclean", "/tank/media/../projects", "tank", true},
+		{"outside", "/tmp/share", "", false},
+		{"sibling_prefix", "/tanker", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := DatasetForPath(tt.path, datasets)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCreateShare_Dataset(t *testing.T) {
+	root := t.TempDir()
+	mount := filepath.Join(root, "tank")
+	inside := filepath.Join(mount, "projects")
+	outside := filepath.Join(root, "scratch")
+	for _, dir := range []string{inside, outside} {
+		require.NoError(t, os.MkdirAll(dir, 0755))
+	}
+	// TempDir may sit behind a symlink (e.g. /var on macOS)
+	mount, err := filepath.EvalSymlinks(mount)
+	require.NoError(t, err)
+	datasets := staticDatasets{{Name: "tank", Mountpoint: mount}}
+
+	tests := []struct {
+		name        string
+		path        string
+		strict      bool
+		wantDataset string
+		wantErr     bool
+	}{
+		{"inside", inside, true, "tank", false},
+		{"outside_strict", outside, true, "", true},
+		{"outside_lenient", outside, false, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, err := store.Open(":memory:")
+			require.NoError(t, err)
+			defer db.Close()
+
+			mgr := NewManager(store.NewShareRepo(db), filepath.Join(t.TempDir(), "smb.conf"), WithDatasets(datasets, tt.strict))
+			sh := &store.Share{Name: tt.name, Path: tt.path, Protocol: "nfs"}
+			err = mgr.CreateShare(sh)
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), "not inside a ZFS dataset")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantDataset, sh.Dataset)
+		})
+	}
+}
+
+func TestCreateShare_ListDatasetsFails(t *testing.T) {
+	for _, strict := range []bool{true, false} {
+		t.Run(fmt.Sprintf("strict=%v", strict), func(t *testing.T) {
+			db, err := store.Open(":memory:")
+			require.NoError(t, err)
+			defer db.Close()
+
+			mgr := NewManager(store.NewShareRepo(db), filepath.Join(t.TempDir(), "smb.conf"), WithDatasets(failingDatasets{}, strict))
+			sh := &store.Share{Name: "data", Path: t.TempDir(), Protocol: "nfs"}
+			err = mgr.CreateShare(sh)
+			if strict {
+				require.ErrorContains(t, err, "failed to list datasets")
+				return
+			}
+			require.NoError(t, err)
+			assert.Empty(t, sh.Dataset)
+		})
+	}
+}
@@ -3,10 +3,13 @@ package share
 
 import (
 	"bytes"
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"runtime"
 
 	"go.aimuz.me/mynt/store"
@@ -19,10 +22,29 @@ type Manager struct {
 	exec       sysexec.Executor
 	configPath string
 	reloadCmd  string
+
+	datasets    DatasetLister // optional, resolves share paths to datasets
+	strictPaths bool
+	logLevel    int // Samba log level; 0 keeps Samba's default
+}
+
+// ErrUnsupportedProtocol is returned for a share protocol an operation
+// does not support.
+var ErrUnsupportedProtocol = errors.New("unsupported protocol")
+
+// MaxLogLevel is the most verbose Samba log level.
+const MaxLogLevel = 10
+
+// WithLogLevel sets the Samba "log level" written to the [global] section.
+// Levels above 0 are useful when debugging client connection problems.
+func WithLogLevel(level int) Option {
+	return func(m *Manager) {
+		m.logLevel = level
+	}
 }
 
 // NewManager creates a new share manager.
-func NewManager(repo *store.ShareRepo, configPath string) *Manager {
+func NewManager(repo *store.ShareRepo, configPath string, opts ...Option) *Manager {
 	// Default config path if not specified
 	if configPath == "" {
 		if runtime.GOOS == "darwin" {
@@ -32,21 +54,36 @@ func NewManager(repo *store.ShareRepo, configPath string) *Manager {
 		}
 	}
 
-	return &Manager{
+	m := &Manager{
 		repo:       repo,
 		exec:       sysexec.NewExecutor(),
 		configPath: configPath,
 		reloadCmd:  detectSambaReloadCmd(),
 	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
 }
 
 // CreateShare creates a new SMB share.
 func (m *Manager) CreateShare(share *store.Share) error {
+	if err := validateMasks(share); err != nil {
+		return err
+	}
+
 	// Validate path exists
 	if _, err := os.Stat(share.Path); os.IsNotExist(err) {
 		return fmt.Errorf("path does not exist: %s", share.Path)
 	}
 
+	// Record the owning dataset so shares on ephemeral paths stand out
+	dataset, err := m.resolveDataset(share.Path)
+	if err != nil {
+		return err
+	}
+	share.Dataset = dataset
+
 	// Save to database
 	if err := m.repo.Save(share); err != nil {
 		return fmt.Errorf("failed to save share: %w", err)
@@ -98,37 +135,77 @@ func (m *Manager) DeleteShare(id int64) error {
 	return nil
 }
 
+// Reload regenerates smb.conf from the database and reloads Samba,
+// discarding any out-of-band edits to the config file.
+func (m *Manager) Reload() error {
+	if err := m.generateSMBConfig(); err != nil {
+		return fmt.Errorf("failed to generate config: %w", err)
+	}
+	if err := m.reloadSamba(); err != nil {
+		return fmt.Errorf("failed to reload samba: %w", err)
+	}
+	return nil
+}
+
+// PreviewConfig returns the config that regenerating the given protocol
+// would write, without writing it or reloading the service.
+func (m *Manager) PreviewConfig(protocol string) (string, error) {
+	if protocol != "smb" {
+		return "", fmt.Errorf("%w: config preview not supported for %q", ErrUnsupportedProtocol, protocol)
+	}
+	config, err := m.renderSMBConfig()
+	if err != nil {
+		return "", err
+	}
+	return string(config), nil
+}
+
 // generateSMBConfig generates smb.conf from database.
 func (m *Manager) generateSMBConfig() error {
-	shares, err := m.repo.List("smb")
+	config, err := m.renderSMBConfig()
 	if err != nil {
 		return err
 	}
 
+	// Ensure directory exists
+	dir := filepath.Dir(m.configPath)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	// Write config file
+	return os.WriteFile(m.configPath, config, 0644)
+}
+
+// renderSMBConfig builds smb.conf from the shares in the database.
+func (m *Manager) renderSMBConfig() ([]byte, error) {
+	shares, err := m.repo.List("smb")
+	if err != nil {
+		return nil, err
+	}
+
 	var buf bytes.Buffer
+	m.generateGlobalSection(&buf)
+
+	// Share sections
+	for _, share := range shares {
+		m.generateShareSection(&buf, share)
+	}
+	return buf.Bytes(), nil
+}
 
-	// Global section
+// generateGlobalSection generates the Samba [global] section.
+func (m *Manager) generateGlobalSection(buf *bytes.Buffer) {
 	buf.WriteString("[global]\n")
 	buf.WriteString("  workgroup = WORKGROUP\n")
 	buf.WriteString("  server string = Mynt NAS\n")
 	buf.WriteString("  security = user\n")
 	buf.WriteString("  map to guest = Bad User\n")
 	buf.WriteString("  log file = /var/log/samba/%m.log\n")
-	buf.WriteString("  max log size = 50\n\n")
-
-	// Share sections
-	for _, share := range shares {
-		m.generateShareSection(&buf, share)
-	}
-
-	// Ensure directory exists
-	dir := filepath.Dir(m.configPath)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return err
+	if m.logLevel > 0 {
+		buf.WriteString(fmt.Sprintf("  log level = %d\n", m.logLevel))
 	}
-
-	// Write config file
-	return os.WriteFile(m.configPath, buf.Bytes(), 0644)
+	buf.WriteString("  max log size = 50\n\n")
 }
 
 // generateShareSection generates Samba config for a single share based on its type
@@ -143,8 +220,7 @@ func (m *Manager) generateShareSection(buf *bytes.Buffer, share store.Share) {
 		buf.WriteString("  browseable = yes\n")
 		buf.WriteString("  guest ok = yes\n")
 		buf.WriteString(fmt.Sprintf("  read only = %s\n", bStr(share.ReadOnly)))
-		buf.WriteString("  create mask = 0644\n")
-		buf.WriteString("  directory mask = 0755\n")
+		writeMasks(buf, share, "0644", "0755")
 
 	case store.ShareTypeRestricted:
 		// Restricted share - only specified users
@@ -154,8 +230,7 @@ func (m *Manager) generateShareSection(buf *bytes.Buffer, share store.Share) {
 		if share.ValidUsers != "" {
 			buf.WriteString(fmt.Sprintf("  valid users = %s\n", share.ValidUsers))
 		}
-		buf.WriteString("  create mask = 0664\n")
-		buf.WriteString("  directory mask = 0775\n")
+		writeMasks(buf, share, "0664", "0775")
 
 	default: // ShareTypeNormal
 		// Normal share - standard configuration
@@ -165,13 +240,47 @@ func (m *Manager) generateShareSection(buf *bytes.Buffer, share store.Share) {
 		if share.ValidUsers != "" {
 			buf.WriteString(fmt.Sprintf("  valid users = %s\n", share.ValidUsers))
 		}
-		buf.WriteString("  create mask = 0664\n")
-		buf.WriteString("  directory mask = 0775\n")
+		writeMasks(buf, share, "0664", "0775")
+	}
+
+	if share.AuditLog {
+		// Log who opened, changed or removed what to syslog
+		buf.WriteString("  vfs objects = full_audit\n")
+		buf.WriteString("  full_audit:prefix = %u|%I|%S\n")
+		buf.WriteString("  full_audit:success = connect disconnect openat renameat unlinkat mkdirat\n")
+		buf.WriteString("  full_audit:failure = connect openat\n")
+		buf.WriteString("  full_audit:facility = local5\n")
+		buf.WriteString("  full_audit:priority = notice\n")
 	}
 
 	buf.WriteString("\n")
 }
 
+// writeMasks writes the share's create and directory masks, falling back to
+// the given defaults for the share type when unset.
+func writeMasks(buf *bytes.Buffer, share store.Share, createDefault, dirDefault string) {
+	buf.WriteString(fmt.Sprintf("  create mask = %s\n", cmp.Or(share.CreateMask, createDefault)))
+	buf.WriteString(fmt.Sprintf("  directory mask = %s\n", cmp.Or(share.DirectoryMask, dirDefault)))
+}
+
+// maskRegex matches a three or four digit octal mode, so setgid masks such
+// as 2770 are allowed.
+var maskRegex = regexp.MustCompile(`^[0-7]{3,4}$`)
+
+// validateMasks checks that the share's create and directory masks, when
+// set, are octal permission masks such as "0664".
+func validateMasks(share *store.Share) error {
+	for _, m := range []struct{ name, value string }{
+		{"create_mask", share.CreateMask},
+		{"directory_mask", share.DirectoryMask},
+	} {
+		if m.value != "" && !maskRegex.MatchString(m.value) {
+			return fmt.Errorf("invalid %s %q: must be an octal mode such as 0664", m.name, m.value)
+		}
+	}
+	return nil
+}
+
 // toSambaBoolString converts a boolean to "yes" or "no" string for Samba configuration.
 func bStr(b bool) string {
 	if b {
@@ -191,6 +300,11 @@ func (m *Manager) reloadSamba() error {
 	return m.exec.Run(ctx, "sudo", m.reloadCmd, "smbd", "reload")
 }
 
+// CheckConfig runs testparm against the current smb.conf.
+func (m *Manager) CheckConfig() error {
+	return m.testConfig()
+}
+
 // testConfig tests the Samba configuration.
 func (m *Manager) testConfig() error {
 	ctx := context.Background()
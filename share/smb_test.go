@@ -2,12 +2,15 @@ package share
 
 import (
 	"bytes"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.aimuz.me/mynt/store"
+	"go.aimuz.me/mynt/sysexec"
 )
 
 func TestGenerateShareSection_Normal(t *testing.T) {
@@ -341,3 +344,120 @@ func TestGenerateShareSection_ConfigFormat(t *testing.T) {
 	// Should end with a blank line
 	assert.Equal(t, "", lines[len(lines)-1], "Last line should be empty")
 }
+
+func TestGenerateGlobalSection_LogLevel(t *testing.T) {
+	var buf bytes.Buffer
+	(&Manager{}).generateGlobalSection(&buf)
+	assert.NotContains(t, buf.String(), "log level", "default keeps Samba's log level")
+
+	buf.Reset()
+	NewManager(nil, "/tmp/smb.conf", WithLogLevel(3)).generateGlobalSection(&buf)
+	config := buf.String()
+	assert.True(t, strings.HasPrefix(config, "[global]\n"))
+	assert.Contains(t, config, "  log level = 3\n")
+}
+
+func TestGenerateShareSection_AuditLog(t *testing.T) {
+	mgr := &Manager{}
+
+	var audited, plain bytes.Buffer
+	mgr.generateShareSection(&audited, store.Share{Name: "finance", Path: "/tank/finance", AuditLog: true})
+	mgr.generateShareSection(&plain, store.Share{Name: "media", Path: "/tank/media"})
+
+	assert.Contains(t, audited.String(), "vfs objects = full_audit")
+	assert.Contains(t, audited.String(), "full_audit:success =")
+	assert.NotContains(t, plain.String(), "full_audit")
+}
+
+func TestPreviewConfig(t *testing.T) {
+	db, err := store.Open(":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := store.NewShareRepo(db)
+	require.NoError(t, repo.Save(&store.Share{
+		Name:      "media",
+		Path:      "/tank/media",
+		Protocol:  "smb",
+		ShareType: store.ShareTypePublic,
+	}))
+
+	configPath := filepath.Join(t.TempDir(), "smb.conf")
+	mgr := &Manager{repo: repo, configPath: configPath, logLevel: 3}
+
+	preview, err := mgr.PreviewConfig("smb")
+	require.NoError(t, err)
+	assert.Contains(t, preview, "[media]")
+	assert.Contains(t, preview, "log level = 3")
+	_, err = os.Stat(configPath)
+	require.True(t, os.IsNotExist(err), "preview must not write the config")
+
+	require.NoError(t, mgr.generateSMBConfig())
+	written, err := os.ReadFile(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, string(written), preview)
+
+	_, err = mgr.PreviewConfig("nfs")
+	require.ErrorIs(t, err, ErrUnsupportedProtocol)
+}
+
+func TestGenerateShareSection_Masks(t *testing.T) {
+	mgr := &Manager{}
+
+	tests := []struct {
+		name      string
+		share     store.Share
+		createDir [2]string
+	}{
+		{"public_defaults", store.Share{Name: "p", ShareType: store.ShareTypePublic}, [2]string{"0644", "0755"}},
+		{"normal_defaults", store.Share{Name: "n", ShareType: store.ShareTypeNormal}, [2]string{"0664", "0775"}},
+		{"restricted_defaults", store.Share{Name: "r", ShareType: store.ShareTypeRestricted}, [2]string{"0664", "0775"}},
+		{"custom", store.Share{Name: "c", CreateMask: "0660", DirectoryMask: "2770"}, [2]string{"0660", "2770"}},
+		{"custom_create_only", store.Share{Name: "c", ShareType: store.ShareTypePublic, CreateMask: "0666"}, [2]string{"0666", "0755"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			mgr.generateShareSection(&buf, tt.share)
+			config := buf.String()
+			assert.Contains(t, config, "create mask = "+tt.createDir[0]+"\n")
+			assert.Contains(t, config, "directory mask = "+tt.createDir[1]+"\n")
+			assert.Equal(t, 1, strings.Count(config, "create mask"))
+		})
+	}
+}
+
+func TestValidateMasks(t *testing.T) {
+	require.NoError(t, validateMasks(&store.Share{}))
+	require.NoError(t, validateMasks(&store.Share{CreateMask: "0660", DirectoryMask: "2770"}))
+	require.Error(t, validateMasks(&store.Share{CreateMask: "0999"}))
+	require.Error(t, validateMasks(&store.Share{DirectoryMask: "rwx"}))
+	require.Error(t, validateMasks(&store.Share{CreateMask: "07777"}))
+}
+
+func TestReload(t *testing.T) {
+	db, err := store.Open(":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := store.NewShareRepo(db)
+	require.NoError(t, repo.Save(&store.Share{Name: "media", Path: "/tank/media", Protocol: "smb"}))
+
+	// An out-of-band edit that the reload must undo
+	configPath := filepath.Join(t.TempDir(), "smb.conf")
+	require.NoError(t, os.WriteFile(configPath, []byte("[edited]\n  path = /tmp\n"), 0644))
+
+	exec := sysexec.NewMock()
+	mgr := &Manager{repo: repo, exec: exec, configPath: configPath, reloadCmd: "service"}
+	require.NoError(t, mgr.Reload())
+
+	written, err := os.ReadFile(configPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(written), "[media]")
+	assert.NotContains(t, string(written), "[edited]")
+
+	cmds := exec.Commands()
+	require.Len(t, cmds, 1)
+	assert.Equal(t, []string{"service", "smbd", "reload"}, cmds[0].Args)
+}
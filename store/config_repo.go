@@ -2,8 +2,14 @@ package store
 
 import (
 	"crypto/rand"
+	"database/sql"
 	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"time"
+
+	"go.aimuz.me/mynt/alert"
 )
 
 // ConfigRepo manages system configuration.
@@ -65,6 +71,116 @@ func (r *ConfigRepo) GetJWTSecret() (string, error) {
 	return secret, nil
 }
 
+// Config keys of the monitor scan intervals, stored as duration strings.
+const (
+	configScanIntervalDisk  = "scan_interval_disk"
+	configScanIntervalSmart = "scan_interval_smart"
+	configScanIntervalZFS   = "scan_interval_zfs"
+)
+
+// ScanIntervals returns the persisted disk, SMART and ZFS scan intervals.
+// An interval that was never saved is returned as zero.
+func (r *ConfigRepo) ScanIntervals() (disk, smart, zfs time.Duration, err error) {
+	durations := make([]time.Duration, 3)
+	for i, key := range []string{configScanIntervalDisk, configScanIntervalSmart, configScanIntervalZFS} {
+		value, err := r.Get(key)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		if durations[i], err = time.ParseDuration(value); err != nil {
+			return 0, 0, 0, fmt.Errorf("config %s: %w", key, err)
+		}
+	}
+	return durations[0], durations[1], durations[2], nil
+}
+
+// SetScanIntervals persists the disk, SMART and ZFS scan intervals.
+func (r *ConfigRepo) SetScanIntervals(disk, smart, zfs time.Duration) error {
+	for key, d := range map[string]time.Duration{
+		configScanIntervalDisk:  disk,
+		configScanIntervalSmart: smart,
+		configScanIntervalZFS:   zfs,
+	} {
+		if err := r.Set(key, d.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// configAlertRules is the config key of the alert rules, stored as JSON.
+const configAlertRules = "alert_rules"
+
+// AlertRules returns the saved alert rules, or alert.DefaultRules if none
+// were ever saved.
+func (r *ConfigRepo) AlertRules() ([]alert.Rule, error) {
+	value, err := r.Get(configAlertRules)
+	if errors.Is(err, sql.ErrNoRows) {
+		return alert.DefaultRules, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var rules []alert.Rule
+	if err := json.Unmarshal([]byte(value), &rules); err != nil {
+		return nil, fmt.Errorf("config %s: %w", configAlertRules, err)
+	}
+	return rules, nil
+}
+
+// SetAlertRules persists the alert rules.
+func (r *ConfigRepo) SetAlertRules(rules []alert.Rule) error {
+	if rules == nil {
+		rules = []alert.Rule{}
+	}
+	value, err := json.Marshal(rules)
+	if err != nil {
+		return err
+	}
+	return r.Set(configAlertRules, string(value))
+}
+
+// SMTPConfig is the mail server notification emails are sent through.
+type SMTPConfig struct {
+	Host     string `json:"host"` // empty disables email
+	Port     int    `json:"port"`
+	Username string `json:"username"` // empty sends without authentication
+	Password string `json:"password,omitempty"`
+	From     string `json:"from"`
+	TLS      bool   `json:"tls"` // implicit TLS (usually port 465) instead of STARTTLS
+}
+
+// configSMTP is the config key of the SMTP settings, stored as JSON.
+const configSMTP = "smtp"
+
+// SMTPConfig returns the saved SMTP settings, or nil if none were saved.
+func (r *ConfigRepo) SMTPConfig() (*SMTPConfig, error) {
+	value, err := r.Get(configSMTP)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var cfg SMTPConfig
+	if err := json.Unmarshal([]byte(value), &cfg); err != nil {
+		return nil, fmt.Errorf("config %s: %w", configSMTP, err)
+	}
+	return &cfg, nil
+}
+
+// SetSMTPConfig persists the SMTP settings.
+func (r *ConfigRepo) SetSMTPConfig(cfg SMTPConfig) error {
+	value, err := json.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+	return r.Set(configSMTP, string(value))
+}
+
 // generateRandomSecret generates a random base64 encoded secret.
 func generateRandomSecret(length int) (string, error) {
 	bytes := make([]byte, length)
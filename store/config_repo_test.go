@@ -2,8 +2,11 @@ package store
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
+	"go.aimuz.me/mynt/alert"
+	"go.aimuz.me/mynt/event"
 )
 
 func setupTestDB(t *testing.T) *DB {
@@ -99,3 +102,57 @@ func TestConfigRepo_JWTSecretFormat(t *testing.T) {
 	// Should not contain spaces
 	require.NotContains(t, secret, " ")
 }
+
+func TestConfigRepo_ScanIntervals(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewConfigRepo(db)
+
+	disk, smart, zfs, err := repo.ScanIntervals()
+	require.NoError(t, err)
+	require.Zero(t, disk)
+	require.Zero(t, smart)
+	require.Zero(t, zfs)
+
+	require.NoError(t, repo.SetScanIntervals(time.Minute, time.Hour, 10*time.Second))
+	disk, smart, zfs, err = repo.ScanIntervals()
+	require.NoError(t, err)
+	require.Equal(t, time.Minute, disk)
+	require.Equal(t, time.Hour, smart)
+	require.Equal(t, 10*time.Second, zfs)
+}
+
+func TestConfigRepo_AlertRules(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewConfigRepo(db)
+
+	rules, err := repo.AlertRules()
+	require.NoError(t, err)
+	require.Equal(t, alert.DefaultRules, rules)
+
+	want := []alert.Rule{{Metric: alert.DiskTemp, Op: ">", Threshold: 50, Hysteresis: 4, Severity: event.SeverityCritical}}
+	require.NoError(t, repo.SetAlertRules(want))
+	rules, err = repo.AlertRules()
+	require.NoError(t, err)
+	require.Equal(t, want, rules)
+
+	// Saving no rules disables alerting rather than restoring the defaults
+	require.NoError(t, repo.SetAlertRules(nil))
+	rules, err = repo.AlertRules()
+	require.NoError(t, err)
+	require.Empty(t, rules)
+}
+
+func TestConfigRepo_SMTPConfig(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewConfigRepo(db)
+
+	cfg, err := repo.SMTPConfig()
+	require.NoError(t, err)
+	require.Nil(t, cfg)
+
+	want := SMTPConfig{Host: "mail.example.com", Port: 465, Username: "nas", Password: "secret", From: "nas@example.com", TLS: true}
+	require.NoError(t, repo.SetSMTPConfig(want))
+	cfg, err = repo.SMTPConfig()
+	require.NoError(t, err)
+	require.Equal(t, &want, cfg)
+}
@@ -0,0 +1,126 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+)
+
+// DatasetTemplate is an admin-defined set of properties applied when a
+// dataset is created with its name as the use case.
+type DatasetTemplate struct {
+	Name       string            `json:"name"`
+	Properties map[string]string `json:"properties"`
+	CreatedAt  time.Time         `json:"created_at"`
+	UpdatedAt  time.Time         `json:"updated_at"`
+}
+
+// DatasetTemplateRepo manages custom dataset template persistence.
+type DatasetTemplateRepo struct {
+	db *DB
+}
+
+// NewDatasetTemplateRepo creates a new dataset template repository.
+func NewDatasetTemplateRepo(db *DB) *DatasetTemplateRepo {
+	return &DatasetTemplateRepo{db: db}
+}
+
+// Save creates a new template. It fails if the name is already taken.
+func (r *DatasetTemplateRepo) Save(t *DatasetTemplate) error {
+	t.CreatedAt = time.Now()
+	t.UpdatedAt = t.CreatedAt
+
+	propsJSON, err := json.Marshal(t.Properties)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.conn.Exec(`
+		INSERT INTO dataset_templates (name, properties, created_at, updated_at)
+		VALUES (?, ?, ?, ?)
+	`, t.Name, string(propsJSON), t.CreatedAt, t.UpdatedAt)
+	return err
+}
+
+// Update replaces the properties of an existing template. It returns
+// sql.ErrNoRows if the template does not exist.
+func (r *DatasetTemplateRepo) Update(t *DatasetTemplate) error {
+	t.UpdatedAt = time.Now()
+
+	propsJSON, err := json.Marshal(t.Properties)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.conn.Exec(`
+		UPDATE dataset_templates SET properties = ?, updated_at = ? WHERE name = ?
+	`, string(propsJSON), t.UpdatedAt, t.Name)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// Get returns a template by name, or nil if it does not exist.
+func (r *DatasetTemplateRepo) Get(name string) (*DatasetTemplate, error) {
+	var t DatasetTemplate
+	var propsJSON string
+
+	err := r.db.conn.QueryRow(`
+		SELECT name, properties, created_at, updated_at
+		FROM dataset_templates WHERE name = ?
+	`, name).Scan(&t.Name, &propsJSON, &t.CreatedAt, &t.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(propsJSON), &t.Properties); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// List returns all templates ordered by name.
+func (r *DatasetTemplateRepo) List() ([]DatasetTemplate, error) {
+	rows, err := r.db.conn.Query("SELECT name, properties, created_at, updated_at FROM dataset_templates ORDER BY name")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	templates := []DatasetTemplate{}
+	for rows.Next() {
+		var t DatasetTemplate
+		var propsJSON string
+		if err := rows.Scan(&t.Name, &propsJSON, &t.CreatedAt, &t.UpdatedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(propsJSON), &t.Properties); err != nil {
+			return nil, err
+		}
+		templates = append(templates, t)
+	}
+	return templates, rows.Err()
+}
+
+// Delete removes a template. Deleting a missing template is not an error.
+func (r *DatasetTemplateRepo) Delete(name string) error {
+	_, err := r.db.conn.Exec("DELETE FROM dataset_templates WHERE name = ?", name)
+	return err
+}
+
+// TemplateProperties returns the properties of the named template, or nil
+// if it does not exist. It implements zfs.TemplateSource.
+func (r *DatasetTemplateRepo) TemplateProperties(name string) (map[string]string, error) {
+	t, err := r.Get(name)
+	if err != nil || t == nil {
+		return nil, err
+	}
+	return t.Properties, nil
+}
@@ -0,0 +1,45 @@
+package store
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDatasetTemplateRepo_CRUD(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewDatasetTemplateRepo(db)
+
+	backup := &DatasetTemplate{Name: "backup", Properties: map[string]string{"compression": "zstd", "recordsize": "1M"}}
+	require.NoError(t, repo.Save(backup))
+	require.Error(t, repo.Save(&DatasetTemplate{Name: "backup", Properties: map[string]string{"atime": "off"}}), "duplicate name")
+	require.NoError(t, repo.Save(&DatasetTemplate{Name: "archive", Properties: map[string]string{"compression": "gzip-9"}}))
+
+	got, err := repo.Get("backup")
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	require.Equal(t, map[string]string{"compression": "zstd", "recordsize": "1M"}, got.Properties)
+
+	list, err := repo.List()
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	require.Equal(t, "archive", list[0].Name)
+	require.Equal(t, "backup", list[1].Name)
+
+	backup.Properties = map[string]string{"compression": "lz4"}
+	require.NoError(t, repo.Update(backup))
+	props, err := repo.TemplateProperties("backup")
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"compression": "lz4"}, props)
+
+	require.ErrorIs(t, repo.Update(&DatasetTemplate{Name: "missing", Properties: map[string]string{"atime": "off"}}), sql.ErrNoRows)
+
+	require.NoError(t, repo.Delete("backup"))
+	got, err = repo.Get("backup")
+	require.NoError(t, err)
+	require.Nil(t, got)
+	props, err = repo.TemplateProperties("backup")
+	require.NoError(t, err)
+	require.Nil(t, props)
+}
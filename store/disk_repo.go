@@ -1,6 +1,7 @@
 package store
 
 import (
+	"database/sql"
 	"encoding/json"
 	"fmt"
 	"time"
@@ -32,6 +33,12 @@ type SmartState struct {
 	PendingSectors      int64            `json:"pending_sectors"`
 	UncorrectableErrors int64            `json:"uncorrectable_errors"`
 	Attributes          []disk.Attribute `json:"attributes"`
+	PercentageUsed      *int             `json:"percentage_used,omitempty"`
+	WearLevelingCount   *int             `json:"wear_leveling_count,omitempty"`
+	DataUnitsWritten    int64            `json:"data_units_written,omitempty"`
+	TotalLBAsWritten    int64            `json:"total_lbas_written,omitempty"`
+	LastTestResult      string           `json:"last_test_result,omitempty"` // of the last scheduled self-test
+	LastTestAt          *time.Time       `json:"last_test_at,omitempty"`
 	UpdatedAt           time.Time        `json:"updated_at"`
 }
 
@@ -150,8 +157,9 @@ func (r *DiskRepo) SaveSmart(report *disk.DetailedReport) error {
 
 	_, err = r.db.conn.Exec(`
 		INSERT INTO disk_smart (disk_name, passed, temperature, power_on_hours, power_cycle_count,
-			reallocated_sectors, pending_sectors, uncorrectable_errors, attributes, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			reallocated_sectors, pending_sectors, uncorrectable_errors, attributes,
+			percentage_used, wear_leveling_count, data_units_written, total_lbas_written, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT(disk_name) DO UPDATE SET
 			passed = excluded.passed,
 			temperature = excluded.temperature,
@@ -161,9 +169,14 @@ func (r *DiskRepo) SaveSmart(report *disk.DetailedReport) error {
 			pending_sectors = excluded.pending_sectors,
 			uncorrectable_errors = excluded.uncorrectable_errors,
 			attributes = excluded.attributes,
+			percentage_used = excluded.percentage_used,
+			wear_leveling_count = excluded.wear_leveling_count,
+			data_units_written = excluded.data_units_written,
+			total_lbas_written = excluded.total_lbas_written,
 			updated_at = excluded.updated_at
 	`, report.Disk, report.Passed, report.Temperature, report.PowerOnHours, report.PowerCycleCount,
-		report.ReallocatedSectors, report.PendingSectors, report.UncorrectableErrors, attrs, time.Now())
+		report.ReallocatedSectors, report.PendingSectors, report.UncorrectableErrors, attrs,
+		report.PercentageUsed, report.WearLevelingCount, report.DataUnitsWritten, report.TotalLBAsWritten, time.Now())
 	return err
 }
 
@@ -171,18 +184,26 @@ func (r *DiskRepo) SaveSmart(report *disk.DetailedReport) error {
 func (r *DiskRepo) GetSmart(name string) (*SmartState, error) {
 	var s SmartState
 	var attrsJSON []byte
+	var lastTest sql.NullTime
 
 	err := r.db.conn.QueryRow(`
 		SELECT disk_name, passed, temperature, power_on_hours, power_cycle_count,
-			reallocated_sectors, pending_sectors, uncorrectable_errors, attributes, updated_at
+			reallocated_sectors, pending_sectors, uncorrectable_errors, attributes,
+			percentage_used, wear_leveling_count, data_units_written, total_lbas_written,
+			last_test_result, last_test_at, updated_at
 		FROM disk_smart WHERE disk_name = ?
 	`, name).Scan(
 		&s.DiskName, &s.Passed, &s.Temperature, &s.PowerOnHours, &s.PowerCycleCount,
-		&s.ReallocatedSectors, &s.PendingSectors, &s.UncorrectableErrors, &attrsJSON, &s.UpdatedAt,
+		&s.ReallocatedSectors, &s.PendingSectors, &s.UncorrectableErrors, &attrsJSON,
+		&s.PercentageUsed, &s.WearLevelingCount, &s.DataUnitsWritten, &s.TotalLBAsWritten,
+		&s.LastTestResult, &lastTest, &s.UpdatedAt,
 	)
 	if err != nil {
 		return nil, err
 	}
+	if lastTest.Valid {
+		s.LastTestAt = &lastTest.Time
+	}
 
 	if len(attrsJSON) > 0 {
 		if err := json.Unmarshal(attrsJSON, &s.Attributes); err != nil {
@@ -196,7 +217,9 @@ func (r *DiskRepo) GetSmart(name string) (*SmartState, error) {
 func (r *DiskRepo) ListSmart() (map[string]*SmartState, error) {
 	rows, err := r.db.conn.Query(`
 		SELECT disk_name, passed, temperature, power_on_hours, power_cycle_count,
-			reallocated_sectors, pending_sectors, uncorrectable_errors, attributes, updated_at
+			reallocated_sectors, pending_sectors, uncorrectable_errors, attributes,
+			percentage_used, wear_leveling_count, data_units_written, total_lbas_written,
+			last_test_result, last_test_at, updated_at
 		FROM disk_smart
 	`)
 	if err != nil {
@@ -208,12 +231,18 @@ func (r *DiskRepo) ListSmart() (map[string]*SmartState, error) {
 	for rows.Next() {
 		var s SmartState
 		var attrsJSON []byte
+		var lastTest sql.NullTime
 		if err := rows.Scan(
 			&s.DiskName, &s.Passed, &s.Temperature, &s.PowerOnHours, &s.PowerCycleCount,
-			&s.ReallocatedSectors, &s.PendingSectors, &s.UncorrectableErrors, &attrsJSON, &s.UpdatedAt,
+			&s.ReallocatedSectors, &s.PendingSectors, &s.UncorrectableErrors, &attrsJSON,
+			&s.PercentageUsed, &s.WearLevelingCount, &s.DataUnitsWritten, &s.TotalLBAsWritten,
+			&s.LastTestResult, &lastTest, &s.UpdatedAt,
 		); err != nil {
 			return nil, err
 		}
+		if lastTest.Valid {
+			s.LastTestAt = &lastTest.Time
+		}
 		if len(attrsJSON) > 0 {
 			if err := json.Unmarshal(attrsJSON, &s.Attributes); err != nil {
 				return nil, fmt.Errorf("unmarshal attributes for %s: %w", s.DiskName, err)
@@ -224,12 +253,211 @@ func (r *DiskRepo) ListSmart() (map[string]*SmartState, error) {
 	return result, nil
 }
 
+// SaveSmartTestResult records the outcome of a scheduled self-test. Disks
+// without cached SMART data are skipped; the next scan adds them.
+func (r *DiskRepo) SaveSmartTestResult(name, result string, at time.Time) error {
+	_, err := r.db.conn.Exec(
+		"UPDATE disk_smart SET last_test_result = ?, last_test_at = ? WHERE disk_name = ?",
+		result, at, name)
+	return err
+}
+
 // DeleteSmart removes SMART data for a disk.
 func (r *DiskRepo) DeleteSmart(name string) error {
 	_, err := r.db.conn.Exec("DELETE FROM disk_smart WHERE disk_name = ?", name)
 	return err
 }
 
+// SetSmartDeviceType saves the smartctl device type override for a disk.
+// An empty type clears the override.
+func (r *DiskRepo) SetSmartDeviceType(name, deviceType string) error {
+	if deviceType == "" {
+		_, err := r.db.conn.Exec("DELETE FROM disk_settings WHERE disk_name = ?", name)
+		return err
+	}
+
+	_, err := r.db.conn.Exec(`
+		INSERT INTO disk_settings (disk_name, smart_device_type, updated_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT(disk_name) DO UPDATE SET
+			smart_device_type = excluded.smart_device_type,
+			updated_at = excluded.updated_at
+	`, name, deviceType, time.Now())
+	return err
+}
+
+// SmartDeviceType returns the smartctl device type override for a disk,
+// or an empty string if none is set. It implements disk.DeviceTypeSource.
+func (r *DiskRepo) SmartDeviceType(name string) (string, error) {
+	var deviceType string
+	err := r.db.conn.QueryRow(
+		"SELECT smart_device_type FROM disk_settings WHERE disk_name = ?", name,
+	).Scan(&deviceType)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return deviceType, err
+}
+
+// PoolDiskErrors holds the last-seen ZFS error counters of a pool member.
+type PoolDiskErrors struct {
+	Pool     string
+	Disk     string
+	Read     uint64
+	Write    uint64
+	Checksum uint64
+}
+
+// SavePoolErrors stores the error counters of a pool member.
+func (r *DiskRepo) SavePoolErrors(e PoolDiskErrors) error {
+	_, err := r.db.conn.Exec(`
+		INSERT INTO pool_disk_errors (pool_name, disk_name, read_errors, write_errors, checksum_errors, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(pool_name, disk_name) DO UPDATE SET
+			read_errors = excluded.read_errors,
+			write_errors = excluded.write_errors,
+			checksum_errors = excluded.checksum_errors,
+			updated_at = excluded.updated_at
+	`, e.Pool, e.Disk, e.Read, e.Write, e.Checksum, time.Now())
+	return err
+}
+
+// ListPoolErrors returns the stored error counters of all pool members.
+func (r *DiskRepo) ListPoolErrors() ([]PoolDiskErrors, error) {
+	rows, err := r.db.conn.Query(`
+		SELECT pool_name, disk_name, read_errors, write_errors, checksum_errors
+		FROM pool_disk_errors
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []PoolDiskErrors
+	for rows.Next() {
+		var e PoolDiskErrors
+		if err := rows.Scan(&e.Pool, &e.Disk, &e.Read, &e.Write, &e.Checksum); err != nil {
+			return nil, err
+		}
+		result = append(result, e)
+	}
+	return result, rows.Err()
+}
+
+// PoolScan records a scrub or resilver that was seen running on a pool.
+// It outlives restarts so the original start time is kept while a scan
+// is paused, resumed, or the daemon is down.
+type PoolScan struct {
+	Pool      string    `json:"pool"`
+	Kind      string    `json:"kind"` // "scrub" or "resilver"
+	StartedAt time.Time `json:"started_at"`
+}
+
+// SavePoolScan stores the active scan of a pool, replacing any previous one.
+func (r *DiskRepo) SavePoolScan(s PoolScan) error {
+	_, err := r.db.conn.Exec(`
+		INSERT INTO pool_scans (pool_name, kind, started_at, updated_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(pool_name) DO UPDATE SET
+			kind = excluded.kind,
+			started_at = excluded.started_at,
+			updated_at = excluded.updated_at
+	`, s.Pool, s.Kind, s.StartedAt, time.Now())
+	return err
+}
+
+// GetPoolScan returns the active scan of a pool, or nil if none is tracked.
+func (r *DiskRepo) GetPoolScan(pool string) (*PoolScan, error) {
+	s := PoolScan{Pool: pool}
+	err := r.db.conn.QueryRow(
+		"SELECT kind, started_at FROM pool_scans WHERE pool_name = ?", pool,
+	).Scan(&s.Kind, &s.StartedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// ListPoolScans returns all tracked scans.
+func (r *DiskRepo) ListPoolScans() ([]PoolScan, error) {
+	rows, err := r.db.conn.Query("SELECT pool_name, kind, started_at FROM pool_scans")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []PoolScan
+	for rows.Next() {
+		var s PoolScan
+		if err := rows.Scan(&s.Pool, &s.Kind, &s.StartedAt); err != nil {
+			return nil, err
+		}
+		result = append(result, s)
+	}
+	return result, rows.Err()
+}
+
+// DeletePoolScan forgets the tracked scan of a pool.
+func (r *DiskRepo) DeletePoolScan(pool string) error {
+	_, err := r.db.conn.Exec("DELETE FROM pool_scans WHERE pool_name = ?", pool)
+	return err
+}
+
+// PoolCapacitySample is the space usage of a pool at one point in time.
+type PoolCapacitySample struct {
+	Pool      string    `json:"-"`
+	Allocated uint64    `json:"allocated"`
+	Free      uint64    `json:"free"`
+	Time      time.Time `json:"time"`
+}
+
+// AddPoolCapacity appends a capacity sample.
+func (r *DiskRepo) AddPoolCapacity(s PoolCapacitySample) error {
+	_, err := r.db.conn.Exec(`
+		INSERT INTO pool_capacity_history (pool_name, allocated, free, sampled_at)
+		VALUES (?, ?, ?, ?)
+	`, s.Pool, s.Allocated, s.Free, s.Time)
+	return err
+}
+
+// PoolCapacityHistory returns the samples of a pool taken at or after
+// since, oldest first.
+func (r *DiskRepo) PoolCapacityHistory(pool string, since time.Time) ([]PoolCapacitySample, error) {
+	rows, err := r.db.conn.Query(`
+		SELECT allocated, free, sampled_at
+		FROM pool_capacity_history
+		WHERE pool_name = ? AND sampled_at >= ?
+		ORDER BY sampled_at ASC
+	`, pool, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []PoolCapacitySample{}
+	for rows.Next() {
+		s := PoolCapacitySample{Pool: pool}
+		if err := rows.Scan(&s.Allocated, &s.Free, &s.Time); err != nil {
+			return nil, err
+		}
+		result = append(result, s)
+	}
+	return result, rows.Err()
+}
+
+// PrunePoolCapacity deletes samples taken before the given time and
+// returns how many were removed.
+func (r *DiskRepo) PrunePoolCapacity(before time.Time) (int64, error) {
+	res, err := r.db.conn.Exec("DELETE FROM pool_capacity_history WHERE sampled_at < ?", before)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
 // SmartCacheAdapter adapts DiskRepo to disk.SmartCache interface.
 type SmartCacheAdapter struct {
 	repo *DiskRepo
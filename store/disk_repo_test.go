@@ -0,0 +1,108 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.aimuz.me/mynt/disk"
+)
+
+func TestDiskRepo_PoolErrors(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewDiskRepo(db)
+
+	require.NoError(t, repo.SavePoolErrors(PoolDiskErrors{Pool: "tank", Disk: "sda", Checksum: 2}))
+	require.NoError(t, repo.SavePoolErrors(PoolDiskErrors{Pool: "tank", Disk: "sda", Read: 1, Checksum: 5}))
+
+	list, err := repo.ListPoolErrors()
+	require.NoError(t, err)
+	require.Equal(t, []PoolDiskErrors{{Pool: "tank", Disk: "sda", Read: 1, Checksum: 5}}, list)
+}
+
+func TestDiskRepo_PoolScans(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewDiskRepo(db)
+
+	got, err := repo.GetPoolScan("tank")
+	require.NoError(t, err)
+	require.Nil(t, got)
+
+	started := time.Date(2025, 6, 1, 3, 0, 0, 0, time.UTC)
+	require.NoError(t, repo.SavePoolScan(PoolScan{Pool: "tank", Kind: "scrub", StartedAt: started}))
+	require.NoError(t, repo.SavePoolScan(PoolScan{Pool: "tank", Kind: "resilver", StartedAt: started.Add(time.Hour)}))
+	require.NoError(t, repo.SavePoolScan(PoolScan{Pool: "backup", Kind: "scrub", StartedAt: started}))
+
+	got, err = repo.GetPoolScan("tank")
+	require.NoError(t, err)
+	require.Equal(t, "resilver", got.Kind)
+	require.True(t, got.StartedAt.Equal(started.Add(time.Hour)))
+
+	list, err := repo.ListPoolScans()
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+
+	require.NoError(t, repo.DeletePoolScan("tank"))
+	got, err = repo.GetPoolScan("tank")
+	require.NoError(t, err)
+	require.Nil(t, got)
+	list, err = repo.ListPoolScans()
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+}
+
+func TestDiskRepo_PoolCapacity(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewDiskRepo(db)
+
+	base := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	for day := range 3 {
+		at := base.Add(time.Duration(day) * 24 * time.Hour)
+		require.NoError(t, repo.AddPoolCapacity(PoolCapacitySample{Pool: "tank", Allocated: uint64(100 + day), Free: uint64(900 - day), Time: at}))
+		require.NoError(t, repo.AddPoolCapacity(PoolCapacitySample{Pool: "backup", Allocated: 1, Free: 1, Time: at}))
+	}
+
+	all, err := repo.PoolCapacityHistory("tank", time.Time{})
+	require.NoError(t, err)
+	require.Len(t, all, 3)
+	require.Equal(t, uint64(100), all[0].Allocated)
+	require.Equal(t, uint64(898), all[2].Free)
+
+	// Only samples inside the window
+	recent, err := repo.PoolCapacityHistory("tank", base.Add(24*time.Hour))
+	require.NoError(t, err)
+	require.Len(t, recent, 2)
+	require.True(t, recent[0].Time.Equal(base.Add(24*time.Hour)))
+
+	none, err := repo.PoolCapacityHistory("missing", time.Time{})
+	require.NoError(t, err)
+	require.Empty(t, none)
+
+	// Pruning drops the oldest day of both pools
+	n, err := repo.PrunePoolCapacity(base.Add(time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, int64(2), n)
+	all, err = repo.PoolCapacityHistory("tank", time.Time{})
+	require.NoError(t, err)
+	require.Len(t, all, 2)
+}
+
+func TestDiskRepo_SmartWear(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewDiskRepo(db)
+
+	used := 7
+	require.NoError(t, repo.SaveSmart(&disk.DetailedReport{Disk: "nvme0n1", Passed: true, PercentageUsed: &used, DataUnitsWritten: 4200000}))
+	require.NoError(t, repo.SaveSmart(&disk.DetailedReport{Disk: "sda", Passed: true}))
+
+	nvme, err := repo.GetSmart("nvme0n1")
+	require.NoError(t, err)
+	require.NotNil(t, nvme.PercentageUsed)
+	require.Equal(t, 7, *nvme.PercentageUsed)
+	require.Nil(t, nvme.WearLevelingCount)
+	require.Equal(t, int64(4200000), nvme.DataUnitsWritten)
+
+	all, err := repo.ListSmart()
+	require.NoError(t, err)
+	require.Nil(t, all["sda"].PercentageUsed, "HDD reports no wear")
+}
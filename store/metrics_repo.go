@@ -0,0 +1,105 @@
+package store
+
+import (
+	"slices"
+	"time"
+)
+
+// Metric names recorded by the metrics sampler.
+const (
+	MetricCPU       = "cpu"        // aggregate CPU usage, percent
+	MetricMemory    = "memory"     // RAM usage, percent
+	MetricNetIn     = "net_in"     // receive rate over all interfaces, bytes/s
+	MetricNetOut    = "net_out"    // transmit rate over all interfaces, bytes/s
+	MetricDiskRead  = "disk_read"  // read rate over all disks, bytes/s
+	MetricDiskWrite = "disk_write" // write rate over all disks, bytes/s
+)
+
+// Metrics lists the known metric names.
+var Metrics = []string{MetricCPU, MetricMemory, MetricNetIn, MetricNetOut, MetricDiskRead, MetricDiskWrite}
+
+// ValidMetric reports whether name is a known metric.
+func ValidMetric(name string) bool {
+	return slices.Contains(Metrics, name)
+}
+
+// MetricPoint is the average of a metric over the bucket starting at Time.
+type MetricPoint struct {
+	Time  time.Time `json:"time"`
+	Value float64   `json:"value"`
+}
+
+// MetricsRepo stores time series of system metrics.
+type MetricsRepo struct {
+	db *DB
+}
+
+// NewMetricsRepo creates a new metrics repository.
+func NewMetricsRepo(db *DB) *MetricsRepo {
+	return &MetricsRepo{db: db}
+}
+
+// Add records one sample of each metric in values, taken at at.
+func (r *MetricsRepo) Add(at time.Time, values map[string]float64) error {
+	tx, err := r.db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare("INSERT INTO metric_samples (metric, ts, value) VALUES (?, ?, ?)")
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for metric, v := range values {
+		if _, err := stmt.Exec(metric, at.Unix(), v); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Series returns the samples of metric in [from, to), averaged into at
+// most points buckets of equal width, oldest first. Buckets without
+// samples are left out, so gaps in collection stay visible.
+func (r *MetricsRepo) Series(metric string, from, to time.Time, points int) ([]MetricPoint, error) {
+	start, end := from.Unix(), to.Unix()
+	n := int64(max(points, 1))
+	width := max(1, (end-start+n-1)/n)
+
+	rows, err := r.db.conn.Query(`
+		SELECT (ts - ?) / ? AS bucket, AVG(value)
+		FROM metric_samples
+		WHERE metric = ? AND ts >= ? AND ts < ?
+		GROUP BY bucket
+		ORDER BY bucket ASC
+	`, start, width, metric, start, end)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []MetricPoint{}
+	for rows.Next() {
+		var bucket int64
+		var p MetricPoint
+		if err := rows.Scan(&bucket, &p.Value); err != nil {
+			return nil, err
+		}
+		p.Time = time.Unix(start+bucket*width, 0)
+		result = append(result, p)
+	}
+	return result, rows.Err()
+}
+
+// Prune deletes samples taken before before and returns how many were
+// removed.
+func (r *MetricsRepo) Prune(before time.Time) (int64, error) {
+	result, err := r.db.conn.Exec("DELETE FROM metric_samples WHERE ts < ?", before.Unix())
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
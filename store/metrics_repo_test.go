@@ -0,0 +1,46 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricsRepo_Series(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewMetricsRepo(db)
+
+	// One sample a minute for an hour: CPU climbs 0..59, memory stays 50
+	start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	for i := range 60 {
+		at := start.Add(time.Duration(i) * time.Minute)
+		require.NoError(t, repo.Add(at, map[string]float64{MetricCPU: float64(i), MetricMemory: 50}))
+	}
+
+	// Six 10-minute buckets averaging 4.5, 14.5, ...
+	points, err := repo.Series(MetricCPU, start, start.Add(time.Hour), 6)
+	require.NoError(t, err)
+	require.Len(t, points, 6)
+	require.True(t, points[0].Time.Equal(start))
+	require.InDelta(t, 4.5, points[0].Value, 1e-9)
+	require.True(t, points[5].Time.Equal(start.Add(50*time.Minute)))
+	require.InDelta(t, 54.5, points[5].Value, 1e-9)
+
+	// More points than samples returns each sample
+	points, err = repo.Series(MetricMemory, start, start.Add(time.Hour), 1000)
+	require.NoError(t, err)
+	require.Len(t, points, 60)
+
+	// A range partly without data leaves the empty buckets out
+	points, err = repo.Series(MetricCPU, start.Add(30*time.Minute), start.Add(90*time.Minute), 6)
+	require.NoError(t, err)
+	require.Len(t, points, 3)
+
+	n, err := repo.Prune(start.Add(30 * time.Minute))
+	require.NoError(t, err)
+	require.EqualValues(t, 60, n) // 30 minutes of two metrics
+	points, err = repo.Series(MetricCPU, start, start.Add(time.Hour), 60)
+	require.NoError(t, err)
+	require.Len(t, points, 30)
+}
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS disk_settings (
+    disk_name TEXT PRIMARY KEY,
+    smart_device_type TEXT NOT NULL DEFAULT '', -- smartctl -d override, e.g. "sat"
+    updated_at DATETIME NOT NULL
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS disk_settings;
+-- +goose StatementEnd
@@ -0,0 +1,9 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE shares ADD COLUMN dataset TEXT NOT NULL DEFAULT ''; -- owning ZFS dataset
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE shares DROP COLUMN dataset;
+-- +goose StatementEnd
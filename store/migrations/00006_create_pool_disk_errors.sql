@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS pool_disk_errors (
+    pool_name TEXT NOT NULL,
+    disk_name TEXT NOT NULL,
+    read_errors INTEGER NOT NULL DEFAULT 0,
+    write_errors INTEGER NOT NULL DEFAULT 0,
+    checksum_errors INTEGER NOT NULL DEFAULT 0,
+    updated_at DATETIME NOT NULL,
+    PRIMARY KEY (pool_name, disk_name)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS pool_disk_errors;
+-- +goose StatementEnd
@@ -0,0 +1,9 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE shares ADD COLUMN audit_log BOOLEAN NOT NULL DEFAULT 0; -- vfs_full_audit access logging
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE shares DROP COLUMN audit_log;
+-- +goose StatementEnd
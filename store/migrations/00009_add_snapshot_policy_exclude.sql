@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE snapshot_policies ADD COLUMN recursive BOOLEAN NOT NULL DEFAULT 0;
+ALTER TABLE snapshot_policies ADD COLUMN exclude TEXT NOT NULL DEFAULT '[]'; -- JSON array of glob patterns
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE snapshot_policies DROP COLUMN exclude;
+ALTER TABLE snapshot_policies DROP COLUMN recursive;
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS pool_scans (
+    pool_name TEXT PRIMARY KEY,
+    kind TEXT NOT NULL,
+    started_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS pool_scans;
+-- +goose StatementEnd
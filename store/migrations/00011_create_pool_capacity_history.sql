@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS pool_capacity_history (
+    pool_name TEXT NOT NULL,
+    allocated INTEGER NOT NULL,
+    free INTEGER NOT NULL,
+    sampled_at DATETIME NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_pool_capacity_history_pool ON pool_capacity_history(pool_name, sampled_at);
+CREATE INDEX IF NOT EXISTS idx_pool_capacity_history_time ON pool_capacity_history(sampled_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS pool_capacity_history;
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE shares ADD COLUMN create_mask TEXT NOT NULL DEFAULT ''; -- empty uses the share type default
+ALTER TABLE shares ADD COLUMN directory_mask TEXT NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE shares DROP COLUMN create_mask;
+ALTER TABLE shares DROP COLUMN directory_mask;
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE disk_smart ADD COLUMN percentage_used INTEGER; -- NVMe, NULL if not reported
+ALTER TABLE disk_smart ADD COLUMN wear_leveling_count INTEGER; -- SATA SSD, NULL if not reported
+ALTER TABLE disk_smart ADD COLUMN data_units_written INTEGER DEFAULT 0;
+ALTER TABLE disk_smart ADD COLUMN total_lbas_written INTEGER DEFAULT 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE disk_smart DROP COLUMN percentage_used;
+ALTER TABLE disk_smart DROP COLUMN wear_leveling_count;
+ALTER TABLE disk_smart DROP COLUMN data_units_written;
+ALTER TABLE disk_smart DROP COLUMN total_lbas_written;
+-- +goose StatementEnd
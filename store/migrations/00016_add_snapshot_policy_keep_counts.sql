@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE snapshot_policies ADD COLUMN keep_last INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE snapshot_policies ADD COLUMN keep_daily INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE snapshot_policies ADD COLUMN keep_weekly INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE snapshot_policies ADD COLUMN keep_monthly INTEGER NOT NULL DEFAULT 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE snapshot_policies DROP COLUMN keep_monthly;
+ALTER TABLE snapshot_policies DROP COLUMN keep_weekly;
+ALTER TABLE snapshot_policies DROP COLUMN keep_daily;
+ALTER TABLE snapshot_policies DROP COLUMN keep_last;
+-- +goose StatementEnd
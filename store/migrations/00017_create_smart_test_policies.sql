@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS smart_test_policies (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL UNIQUE,
+    schedule TEXT NOT NULL,
+    test_type TEXT NOT NULL, -- short or long
+    disks TEXT NOT NULL DEFAULT '[]', -- JSON array of disk names, empty for all attached disks
+    enabled BOOLEAN DEFAULT 1,
+    last_run_at DATETIME,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL
+);
+INSERT INTO smart_test_policies (name, schedule, test_type, created_at, updated_at)
+VALUES ('weekly-short', '0 2 * * 0', 'short', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP),
+       ('monthly-long', '0 3 1 * *', 'long', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP);
+ALTER TABLE disk_smart ADD COLUMN last_test_result TEXT NOT NULL DEFAULT '';
+ALTER TABLE disk_smart ADD COLUMN last_test_at DATETIME;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE disk_smart DROP COLUMN last_test_at;
+ALTER TABLE disk_smart DROP COLUMN last_test_result;
+DROP TABLE IF EXISTS smart_test_policies;
+-- +goose StatementEnd
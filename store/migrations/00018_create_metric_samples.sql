@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS metric_samples (
+    metric TEXT NOT NULL,
+    ts INTEGER NOT NULL, -- Unix seconds, so buckets are integer division
+    value REAL NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_metric_samples_metric ON metric_samples(metric, ts);
+CREATE INDEX IF NOT EXISTS idx_metric_samples_ts ON metric_samples(ts);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS metric_samples;
+-- +goose StatementEnd
@@ -0,0 +1,155 @@
+package store
+
+import (
+	"database/sql"
+	"time"
+
+	"go.aimuz.me/mynt/event"
+)
+
+// Notification channel types.
+const (
+	ChannelEmail   = "email"
+	ChannelWebhook = "webhook"
+	ChannelLog     = "log"
+)
+
+// NotificationChannel is a destination events are delivered to, such as a
+// mailbox or a webhook, with filters choosing which events it receives.
+type NotificationChannel struct {
+	ID          int64          `json:"id"`
+	Name        string         `json:"name"`
+	Type        string         `json:"type"`         // email, webhook or log
+	Target      string         `json:"target"`       // email address or webhook URL; unused for log
+	MinSeverity event.Severity `json:"min_severity"` // least severe event delivered
+	EventTypes  []string       `json:"event_types"`  // patterns such as "pool.*"; empty matches all
+	Enabled     bool           `json:"enabled"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+}
+
+// Matches reports whether the channel should receive evt.
+func (c *NotificationChannel) Matches(evt event.Event) bool {
+	return c.Enabled && matchesFilter(c.MinSeverity, c.EventTypes, evt)
+}
+
+// matchesFilter reports whether evt is at least as severe as min and, if
+// patterns are given, matches one of them.
+func matchesFilter(min event.Severity, patterns []string, evt event.Event) bool {
+	if !evt.Severity().AtLeast(min) {
+		return false
+	}
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, pattern := range patterns {
+		if event.Match(pattern, evt.Type) {
+			return true
+		}
+	}
+	return false
+}
+
+// NotificationChannelRepo manages notification channel persistence.
+type NotificationChannelRepo struct {
+	db *DB
+}
+
+// NewNotificationChannelRepo creates a new notification channel repository.
+func NewNotificationChannelRepo(db *DB) *NotificationChannelRepo {
+	return &NotificationChannelRepo{db: db}
+}
+
+// Save creates a new channel. It fails if the name is already taken.
+func (r *NotificationChannelRepo) Save(c *NotificationChannel) error {
+	c.CreatedAt = time.Now()
+	c.UpdatedAt = c.CreatedAt
+
+	typesJSON, err := encodeStringList(c.EventTypes)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.conn.Exec(`
+		INSERT INTO notification_channels (name, type, target, min_severity, event_types, enabled, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, c.Name, c.Type, c.Target, c.MinSeverity, string(typesJSON), c.Enabled, c.CreatedAt, c.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	c.ID, _ = result.LastInsertId()
+	return nil
+}
+
+// Update replaces an existing channel. It returns sql.ErrNoRows if the
+// channel does not exist.
+func (r *NotificationChannelRepo) Update(c *NotificationChannel) error {
+	c.UpdatedAt = time.Now()
+
+	typesJSON, err := encodeStringList(c.EventTypes)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.conn.Exec(`
+		UPDATE notification_channels
+		SET name = ?, type = ?, target = ?, min_severity = ?, event_types = ?, enabled = ?, updated_at = ?
+		WHERE id = ?
+	`, c.Name, c.Type, c.Target, c.MinSeverity, string(typesJSON), c.Enabled, c.UpdatedAt, c.ID)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+const notificationChannelColumns = `id, name, type, target, min_severity, event_types, enabled, created_at, updated_at`
+
+// Get returns a channel by ID, or nil if it does not exist.
+func (r *NotificationChannelRepo) Get(id int64) (*NotificationChannel, error) {
+	c, err := scanNotificationChannel(r.db.conn.QueryRow(
+		`SELECT `+notificationChannelColumns+` FROM notification_channels WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return c, err
+}
+
+// List returns all channels ordered by name.
+func (r *NotificationChannelRepo) List() ([]NotificationChannel, error) {
+	rows, err := r.db.conn.Query(`SELECT ` + notificationChannelColumns + ` FROM notification_channels ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	channels := []NotificationChannel{}
+	for rows.Next() {
+		c, err := scanNotificationChannel(rows)
+		if err != nil {
+			return nil, err
+		}
+		channels = append(channels, *c)
+	}
+	return channels, rows.Err()
+}
+
+// Delete removes a channel. Deleting a missing channel is not an error.
+func (r *NotificationChannelRepo) Delete(id int64) error {
+	_, err := r.db.conn.Exec("DELETE FROM notification_channels WHERE id = ?", id)
+	return err
+}
+
+// scanNotificationChannel reads a row selected with notificationChannelColumns.
+func scanNotificationChannel(row interface{ Scan(...any) error }) (*NotificationChannel, error) {
+	var c NotificationChannel
+	var typesJSON string
+	if err := row.Scan(&c.ID, &c.Name, &c.Type, &c.Target, &c.MinSeverity, &typesJSON, &c.Enabled, &c.CreatedAt, &c.UpdatedAt); err != nil {
+		return nil, err
+	}
+	c.EventTypes = decodeStringList(typesJSON)
+	return &c, nil
+}
@@ -0,0 +1,67 @@
+package store
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.aimuz.me/mynt/event"
+)
+
+func TestNotificationChannelRepo_CRUD(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewNotificationChannelRepo(db)
+
+	mail := &NotificationChannel{Name: "oncall", Type: ChannelEmail, Target: "ops@example.com", MinSeverity: event.SeverityCritical, Enabled: true}
+	require.NoError(t, repo.Save(mail))
+	require.NotZero(t, mail.ID)
+	require.Error(t, repo.Save(&NotificationChannel{Name: "oncall", Type: ChannelLog}), "duplicate name")
+
+	hook := &NotificationChannel{Name: "chat", Type: ChannelWebhook, Target: "https://hooks.example.com/x", MinSeverity: event.SeverityInfo, EventTypes: []string{"pool.*"}, Enabled: true}
+	require.NoError(t, repo.Save(hook))
+
+	got, err := repo.Get(hook.ID)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	require.Equal(t, []string{"pool.*"}, got.EventTypes)
+
+	list, err := repo.List()
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	require.Equal(t, "chat", list[0].Name)
+	require.Equal(t, []string{}, list[1].EventTypes)
+
+	mail.Enabled = false
+	require.NoError(t, repo.Update(mail))
+	got, err = repo.Get(mail.ID)
+	require.NoError(t, err)
+	require.False(t, got.Enabled)
+
+	require.ErrorIs(t, repo.Update(&NotificationChannel{ID: 999, Name: "missing", Type: ChannelLog}), sql.ErrNoRows)
+
+	require.NoError(t, repo.Delete(mail.ID))
+	got, err = repo.Get(mail.ID)
+	require.NoError(t, err)
+	require.Nil(t, got)
+}
+
+func TestNotificationChannel_Matches(t *testing.T) {
+	tests := []struct {
+		name    string
+		channel NotificationChannel
+		event   string
+		want    bool
+	}{
+		{"critical_only_gets_critical", NotificationChannel{MinSeverity: event.SeverityCritical, Enabled: true}, event.PoolDegraded, true},
+		{"critical_only_skips_info", NotificationChannel{MinSeverity: event.SeverityCritical, Enabled: true}, event.DatasetCreated, false},
+		{"warning_gets_critical", NotificationChannel{MinSeverity: event.SeverityWarning, Enabled: true}, event.SmartFailed, true},
+		{"type_filter_match", NotificationChannel{MinSeverity: event.SeverityInfo, EventTypes: []string{"pool.*"}, Enabled: true}, event.PoolOnline, true},
+		{"type_filter_miss", NotificationChannel{MinSeverity: event.SeverityInfo, EventTypes: []string{"pool.*"}, Enabled: true}, event.DiskAdded, false},
+		{"disabled", NotificationChannel{MinSeverity: event.SeverityInfo}, event.PoolDegraded, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, tt.channel.Matches(event.Event{Type: tt.event}))
+		})
+	}
+}
@@ -2,6 +2,8 @@ package store
 
 import (
 	"encoding/json"
+	"slices"
+	"strings"
 	"time"
 
 	"go.aimuz.me/mynt/event"
@@ -87,6 +89,91 @@ func (r *NotificationRepo) List(status NotificationStatus, limit, offset int) ([
 	return notifications, nil
 }
 
+// NotificationFilter selects notifications for Each. Zero fields match
+// every notification.
+type NotificationFilter struct {
+	Status NotificationStatus
+	Type   string
+	Since  time.Time // created at or after
+	Until  time.Time // created before
+}
+
+// eachBatchSize is how many rows Each reads per query. Reading in batches
+// keeps a slow consumer from holding a read lock that blocks new
+// notifications from being saved.
+const eachBatchSize = 500
+
+// Each calls fn for every notification matching filter, oldest first,
+// without loading them all into memory. It stops at the first error
+// returned by fn.
+func (r *NotificationRepo) Each(filter NotificationFilter, fn func(Notification) error) error {
+	var where []string
+	var args []any
+	if filter.Status != "" {
+		where = append(where, "status = ?")
+		args = append(args, filter.Status)
+	}
+	if filter.Type != "" {
+		where = append(where, "type = ?")
+		args = append(args, filter.Type)
+	}
+	if !filter.Since.IsZero() {
+		where = append(where, "created_at >= ?")
+		args = append(args, filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		where = append(where, "created_at < ?")
+		args = append(args, filter.Until)
+	}
+	where = append(where, "id > ?")
+
+	query := `
+		SELECT id, type, data, status, created_at, read_at, acked_at
+		FROM notifications
+		WHERE ` + strings.Join(where, " AND ") + `
+		ORDER BY id ASC LIMIT ?
+	`
+
+	var lastID int64
+	for {
+		batch, err := r.queryBatch(query, append(args, lastID, eachBatchSize)...)
+		if err != nil {
+			return err
+		}
+		for _, n := range batch {
+			if err := fn(n); err != nil {
+				return err
+			}
+		}
+		if len(batch) < eachBatchSize {
+			return nil
+		}
+		lastID = batch[len(batch)-1].ID
+	}
+}
+
+// queryBatch runs a notification query and returns all its rows.
+func (r *NotificationRepo) queryBatch(query string, args ...any) ([]Notification, error) {
+	rows, err := r.db.conn.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var batch []Notification
+	for rows.Next() {
+		var n Notification
+		if err := rows.Scan(
+			&n.ID, &n.Type, &n.Data, &n.Status,
+			&n.CreatedAt, &n.ReadAt, &n.AckedAt,
+		); err != nil {
+			return nil, err
+		}
+		batch = append(batch, n)
+	}
+	return batch, rows.Err()
+}
+
 // MarkRead marks a notification as read.
 func (r *NotificationRepo) MarkRead(id int64) error {
 	now := time.Now()
@@ -109,6 +196,64 @@ func (r *NotificationRepo) MarkAcknowledged(id int64) error {
 	return err
 }
 
+// ackBatchSize caps the IDs per UPDATE in MarkAcknowledgedBatch, keeping
+// well below SQLite's limit on query parameters.
+const ackBatchSize = 500
+
+// MarkAcknowledgedBatch acknowledges the given notifications in one
+// transaction. It returns how many were acknowledged; unknown IDs and
+// notifications that were already acknowledged are not counted.
+func (r *NotificationRepo) MarkAcknowledgedBatch(ids []int64) (int64, error) {
+	tx, err := r.db.conn.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	var total int64
+	for chunk := range slices.Chunk(ids, ackBatchSize) {
+		args := []any{NotificationAcked, now}
+		for _, id := range chunk {
+			args = append(args, id)
+		}
+		args = append(args, NotificationAcked)
+
+		res, err := tx.Exec(`
+			UPDATE notifications
+			SET status = ?, acked_at = ?
+			WHERE id IN (`+placeholders(len(chunk))+`) AND status != ?
+		`, args...)
+		if err != nil {
+			return 0, err
+		}
+		n, _ := res.RowsAffected()
+		total += n
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// MarkAllAcknowledged acknowledges every notification with the given
+// status, or every unacknowledged one if status is empty. It returns how
+// many were acknowledged.
+func (r *NotificationRepo) MarkAllAcknowledged(status NotificationStatus) (int64, error) {
+	query := `UPDATE notifications SET status = ?, acked_at = ? WHERE status != ?`
+	args := []any{NotificationAcked, time.Now(), NotificationAcked}
+	if status != "" {
+		query += ` AND status = ?`
+		args = append(args, status)
+	}
+
+	res, err := r.db.conn.Exec(query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
 // Delete removes a notification.
 func (r *NotificationRepo) Delete(id int64) error {
 	_, err := r.db.conn.Exec(`DELETE FROM notifications WHERE id = ?`, id)
@@ -129,3 +274,66 @@ func (r *NotificationRepo) Count(status NotificationStatus) (int, error) {
 	err := r.db.conn.QueryRow(query, args...).Scan(&count)
 	return count, err
 }
+
+// criticalNotificationTypes lists event types whose unread notifications
+// must survive pruning until the user has seen them.
+var criticalNotificationTypes = []string{
+	event.SmartFailed,
+	event.DiskPredictedFailure,
+	event.PoolDegraded,
+	event.PoolDiskErrors,
+}
+
+// Prune deletes read and acknowledged notifications created before the given
+// time, then removes the oldest remaining notifications until at most keepMax
+// rows are left. A keepMax of zero or less disables the row cap.
+// Unread critical notifications are never pruned.
+// It returns the number of deleted notifications.
+func (r *NotificationRepo) Prune(before time.Time, keepMax int) (int64, error) {
+	res, err := r.db.conn.Exec(`
+		DELETE FROM notifications
+		WHERE status IN (?, ?) AND created_at < ?
+	`, NotificationRead, NotificationAcked, before)
+	if err != nil {
+		return 0, err
+	}
+	deleted, _ := res.RowsAffected()
+
+	if keepMax <= 0 {
+		return deleted, nil
+	}
+
+	total, err := r.Count("")
+	if err != nil {
+		return deleted, err
+	}
+	excess := total - keepMax
+	if excess <= 0 {
+		return deleted, nil
+	}
+
+	args := []any{NotificationUnread}
+	for _, typ := range criticalNotificationTypes {
+		args = append(args, typ)
+	}
+	args = append(args, excess)
+
+	res, err = r.db.conn.Exec(`
+		DELETE FROM notifications WHERE id IN (
+			SELECT id FROM notifications
+			WHERE NOT (status = ? AND type IN (`+placeholders(len(criticalNotificationTypes))+`))
+			ORDER BY created_at ASC, id ASC
+			LIMIT ?
+		)
+	`, args...)
+	if err != nil {
+		return deleted, err
+	}
+	n, _ := res.RowsAffected()
+	return deleted + n, nil
+}
+
+// placeholders returns n comma-separated SQL placeholders.
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?,", n), ",")
+}
@@ -134,6 +134,65 @@ func TestNotificationRepo_MarkAcknowledged(t *testing.T) {
 	require.NotNil(t, ackedList[0].AckedAt)
 }
 
+func TestNotificationRepo_MarkAcknowledgedBatch(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewNotificationRepo(db)
+
+	for range 4 {
+		require.NoError(t, repo.Save(event.Event{Type: "test", Time: time.Now()}))
+	}
+	list, err := repo.List("", 10, 0)
+	require.NoError(t, err)
+	require.Len(t, list, 4)
+	require.NoError(t, repo.MarkAcknowledged(list[0].ID))
+
+	// One already acknowledged and one unknown ID are not counted
+	n, err := repo.MarkAcknowledgedBatch([]int64{list[0].ID, list[1].ID, list[2].ID, 9999})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), n)
+
+	acked, err := repo.List(NotificationAcked, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, acked, 3)
+	for _, a := range acked {
+		require.NotNil(t, a.AckedAt)
+	}
+	count, err := repo.Count(NotificationUnread)
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+
+	n, err = repo.MarkAcknowledgedBatch(nil)
+	require.NoError(t, err)
+	require.Zero(t, n)
+}
+
+func TestNotificationRepo_MarkAllAcknowledged(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewNotificationRepo(db)
+
+	for range 3 {
+		require.NoError(t, repo.Save(event.Event{Type: "test", Time: time.Now()}))
+	}
+	list, err := repo.List("", 10, 0)
+	require.NoError(t, err)
+	require.NoError(t, repo.MarkRead(list[0].ID))
+
+	n, err := repo.MarkAllAcknowledged(NotificationUnread)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), n)
+
+	read, err := repo.Count(NotificationRead)
+	require.NoError(t, err)
+	require.Equal(t, 1, read, "read notifications are left alone")
+
+	n, err = repo.MarkAllAcknowledged("")
+	require.NoError(t, err)
+	require.Equal(t, int64(1), n)
+	acked, err := repo.Count(NotificationAcked)
+	require.NoError(t, err)
+	require.Equal(t, 3, acked)
+}
+
 func TestNotificationRepo_Delete(t *testing.T) {
 	db := setupTestDB(t)
 	repo := NewNotificationRepo(db)
@@ -187,3 +246,92 @@ func TestNotificationRepo_Count(t *testing.T) {
 	totalCount, _ := repo.Count("")
 	require.Equal(t, 3, totalCount)
 }
+
+func TestNotificationRepo_Prune(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewNotificationRepo(db)
+
+	old := time.Now().Add(-60 * 24 * time.Hour)
+	require.NoError(t, repo.Save(event.Event{Type: "old.read", Time: old}))
+	require.NoError(t, repo.Save(event.Event{Type: "old.acked", Time: old}))
+	require.NoError(t, repo.Save(event.Event{Type: event.SmartFailed, Time: old}))
+	require.NoError(t, repo.Save(event.Event{Type: "recent.read", Time: time.Now()}))
+
+	list, err := repo.List("", 10, 0)
+	require.NoError(t, err)
+	for _, n := range list {
+		switch n.Type {
+		case "old.read", "recent.read":
+			require.NoError(t, repo.MarkRead(n.ID))
+		case "old.acked":
+			require.NoError(t, repo.MarkAcknowledged(n.ID))
+		}
+	}
+
+	deleted, err := repo.Prune(time.Now().Add(-30*24*time.Hour), 0)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, deleted)
+
+	list, err = repo.List("", 10, 0)
+	require.NoError(t, err)
+	var types []string
+	for _, n := range list {
+		types = append(types, n.Type)
+	}
+	require.ElementsMatch(t, []string{event.SmartFailed, "recent.read"}, types)
+}
+
+func TestNotificationRepo_Prune_KeepMax(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewNotificationRepo(db)
+
+	base := time.Now().Add(-time.Hour)
+	require.NoError(t, repo.Save(event.Event{Type: event.PoolDegraded, Time: base}))
+	for i := 1; i <= 4; i++ {
+		require.NoError(t, repo.Save(event.Event{Type: "test", Time: base.Add(time.Duration(i) * time.Minute)}))
+	}
+
+	deleted, err := repo.Prune(base.Add(-time.Hour), 3)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, deleted)
+
+	list, err := repo.List("", 10, 0)
+	require.NoError(t, err)
+	require.Len(t, list, 3)
+
+	// The two newest survive, along with the oldest one because it is unread critical
+	require.True(t, list[0].CreatedAt.Equal(base.Add(4*time.Minute)))
+	require.True(t, list[1].CreatedAt.Equal(base.Add(3*time.Minute)))
+	require.Equal(t, event.PoolDegraded, list[2].Type)
+}
+
+func TestNotificationRepo_Each(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewNotificationRepo(db)
+
+	base := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	types := []string{"disk.added", "pool.degraded", "disk.added", "disk.removed"}
+	for i, typ := range types {
+		require.NoError(t, repo.Save(event.Event{Type: typ, Time: base.Add(time.Duration(i) * time.Hour)}))
+	}
+	list, err := repo.List("", 10, 0)
+	require.NoError(t, err)
+	require.NoError(t, repo.MarkRead(list[0].ID)) // the newest, disk.removed
+
+	collect := func(f NotificationFilter) []string {
+		var got []string
+		require.NoError(t, repo.Each(f, func(n Notification) error {
+			got = append(got, n.Type)
+			return nil
+		}))
+		return got
+	}
+
+	require.Equal(t, types, collect(NotificationFilter{}))
+	require.Equal(t, []string{"disk.added", "disk.added"}, collect(NotificationFilter{Type: "disk.added"}))
+	require.Equal(t, []string{"disk.removed"}, collect(NotificationFilter{Status: NotificationRead}))
+	require.Equal(t, []string{"pool.degraded", "disk.added"}, collect(NotificationFilter{
+		Since: base.Add(time.Hour),
+		Until: base.Add(3 * time.Hour),
+	}))
+}
@@ -16,17 +16,21 @@ const (
 
 // Share represents a file share configuration.
 type Share struct {
-	ID         int64     `json:"id"`
-	Name       string    `json:"name"`
-	Path       string    `json:"path"`
-	Protocol   string    `json:"protocol"` // smb, nfs
-	ReadOnly   bool      `json:"read_only"`
-	Browseable bool      `json:"browseable"`
-	GuestOK    bool      `json:"guest_ok"`
-	ValidUsers string    `json:"valid_users"` // comma-separated
-	Comment    string    `json:"comment"`
-	ShareType  ShareType `json:"share_type"` // normal, public, restricted
-	CreatedAt  time.Time `json:"created_at"`
+	ID            int64     `json:"id"`
+	Name          string    `json:"name"`
+	Path          string    `json:"path"`
+	Protocol      string    `json:"protocol"` // smb, nfs
+	ReadOnly      bool      `json:"read_only"`
+	Browseable    bool      `json:"browseable"`
+	GuestOK       bool      `json:"guest_ok"`
+	ValidUsers    string    `json:"valid_users"` // comma-separated
+	Comment       string    `json:"comment"`
+	ShareType     ShareType `json:"share_type"`     // normal, public, restricted
+	Dataset       string    `json:"dataset"`        // owning ZFS dataset, empty if unknown
+	AuditLog      bool      `json:"audit_log"`      // log file access with vfs_full_audit
+	CreateMask    string    `json:"create_mask"`    // octal, e.g. "0664"; empty uses the share type default
+	DirectoryMask string    `json:"directory_mask"` // octal, e.g. "0775"; empty uses the share type default
+	CreatedAt     time.Time `json:"created_at"`
 }
 
 // ShareRepo manages share persistence.
@@ -49,10 +53,11 @@ func (r *ShareRepo) Save(share *Share) error {
 	share.CreatedAt = time.Now()
 
 	result, err := r.db.conn.Exec(`
-		INSERT INTO shares (name, path, protocol, read_only, browseable, guest_ok, valid_users, comment, share_type, created_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO shares (name, path, protocol, read_only, browseable, guest_ok, valid_users, comment, share_type, dataset, audit_log, create_mask, directory_mask, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`, share.Name, share.Path, share.Protocol, share.ReadOnly, share.Browseable,
-		share.GuestOK, share.ValidUsers, share.Comment, share.ShareType, share.CreatedAt)
+		share.GuestOK, share.ValidUsers, share.Comment, share.ShareType, share.Dataset, share.AuditLog,
+		share.CreateMask, share.DirectoryMask, share.CreatedAt)
 
 	if err != nil {
 		return err
@@ -65,7 +70,7 @@ func (r *ShareRepo) Save(share *Share) error {
 
 // List returns all shares, optionally filtered by protocol.
 func (r *ShareRepo) List(protocol string) ([]Share, error) {
-	query := "SELECT id, name, path, protocol, read_only, browseable, guest_ok, valid_users, comment, share_type, created_at FROM shares"
+	query := "SELECT id, name, path, protocol, read_only, browseable, guest_ok, valid_users, comment, share_type, dataset, audit_log, create_mask, directory_mask, created_at FROM shares"
 	args := []any{}
 
 	if protocol != "" {
@@ -85,7 +90,7 @@ func (r *ShareRepo) List(protocol string) ([]Share, error) {
 	for rows.Next() {
 		var s Share
 		err := rows.Scan(&s.ID, &s.Name, &s.Path, &s.Protocol, &s.ReadOnly,
-			&s.Browseable, &s.GuestOK, &s.ValidUsers, &s.Comment, &s.ShareType, &s.CreatedAt)
+			&s.Browseable, &s.GuestOK, &s.ValidUsers, &s.Comment, &s.ShareType, &s.Dataset, &s.AuditLog, &s.CreateMask, &s.DirectoryMask, &s.CreatedAt)
 		if err != nil {
 			return nil, err
 		}
@@ -99,10 +104,10 @@ func (r *ShareRepo) List(protocol string) ([]Share, error) {
 func (r *ShareRepo) Get(id int64) (*Share, error) {
 	var s Share
 	err := r.db.conn.QueryRow(`
-		SELECT id, name, path, protocol, read_only, browseable, guest_ok, valid_users, comment, share_type, created_at
+		SELECT id, name, path, protocol, read_only, browseable, guest_ok, valid_users, comment, share_type, dataset, audit_log, create_mask, directory_mask, created_at
 		FROM shares WHERE id = ?
 	`, id).Scan(&s.ID, &s.Name, &s.Path, &s.Protocol, &s.ReadOnly,
-		&s.Browseable, &s.GuestOK, &s.ValidUsers, &s.Comment, &s.ShareType, &s.CreatedAt)
+		&s.Browseable, &s.GuestOK, &s.ValidUsers, &s.Comment, &s.ShareType, &s.Dataset, &s.AuditLog, &s.CreateMask, &s.DirectoryMask, &s.CreatedAt)
 
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -34,6 +34,7 @@ func TestShareRepo_Get(t *testing.T) {
 		Name:     "gettest",
 		Path:     "/tank/gettest",
 		Protocol: "smb",
+		Dataset:  "tank",
 	}
 	repo.Save(share)
 
@@ -43,6 +44,7 @@ func TestShareRepo_Get(t *testing.T) {
 	require.NotNil(t, retrieved)
 	require.Equal(t, share.Name, retrieved.Name)
 	require.Equal(t, share.Path, retrieved.Path)
+	require.Equal(t, share.Dataset, retrieved.Dataset)
 }
 
 func TestShareRepo_Get_NotFound(t *testing.T) {
@@ -99,3 +101,15 @@ func TestShareRepo_Delete(t *testing.T) {
 	retrieved, _ := repo.Get(share.ID)
 	require.Nil(t, retrieved)
 }
+
+func TestShareRepo_AuditLog(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewShareRepo(db)
+
+	share := &Share{Name: "audited", Path: "/tank/audited", Protocol: "smb", AuditLog: true}
+	require.NoError(t, repo.Save(share))
+
+	retrieved, err := repo.Get(share.ID)
+	require.NoError(t, err)
+	require.True(t, retrieved.AuditLog)
+}
@@ -0,0 +1,140 @@
+package store
+
+import (
+	"database/sql"
+	"time"
+
+	"go.aimuz.me/mynt/disk"
+)
+
+// SmartTestPolicy schedules SMART self-tests of a set of disks.
+type SmartTestPolicy struct {
+	ID        int64         `json:"id"`
+	Name      string        `json:"name"`
+	Schedule  string        `json:"schedule"`  // e.g. "@weekly", "0 2 * * 0"
+	TestType  disk.TestType `json:"test_type"` // short or long
+	Disks     []string      `json:"disks"`     // empty for all attached disks
+	Enabled   bool          `json:"enabled"`
+	LastRunAt *time.Time    `json:"last_run_at,omitempty"`
+	CreatedAt time.Time     `json:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at"`
+}
+
+// smartTestPolicyColumns lists the columns scanSmartTestPolicy reads, in order.
+const smartTestPolicyColumns = "id, name, schedule, test_type, disks, enabled, last_run_at, created_at, updated_at"
+
+// scanSmartTestPolicy reads a row selected with smartTestPolicyColumns.
+func scanSmartTestPolicy(row interface{ Scan(...any) error }) (SmartTestPolicy, error) {
+	var p SmartTestPolicy
+	var disksJSON string
+	var lastRun sql.NullTime
+	err := row.Scan(&p.ID, &p.Name, &p.Schedule, &p.TestType, &disksJSON, &p.Enabled, &lastRun, &p.CreatedAt, &p.UpdatedAt)
+	if err != nil {
+		return p, err
+	}
+	p.Disks = decodeStringList(disksJSON)
+	if lastRun.Valid {
+		p.LastRunAt = &lastRun.Time
+	}
+	return p, nil
+}
+
+// SmartTestPolicyRepo manages SMART test policy persistence.
+type SmartTestPolicyRepo struct {
+	db *DB
+}
+
+// NewSmartTestPolicyRepo creates a new SMART test policy repository.
+func NewSmartTestPolicyRepo(db *DB) *SmartTestPolicyRepo {
+	return &SmartTestPolicyRepo{db: db}
+}
+
+// Save creates a new policy. It fails if the name is already taken.
+func (r *SmartTestPolicyRepo) Save(p *SmartTestPolicy) error {
+	p.CreatedAt = time.Now()
+	p.UpdatedAt = p.CreatedAt
+
+	disksJSON, err := encodeStringList(p.Disks)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.conn.Exec(`
+		INSERT INTO smart_test_policies (name, schedule, test_type, disks, enabled, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, p.Name, p.Schedule, p.TestType, string(disksJSON), p.Enabled, p.CreatedAt, p.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	p.ID, _ = result.LastInsertId()
+	return nil
+}
+
+// Update replaces an existing policy. It returns sql.ErrNoRows if the
+// policy does not exist.
+func (r *SmartTestPolicyRepo) Update(p *SmartTestPolicy) error {
+	p.UpdatedAt = time.Now()
+
+	disksJSON, err := encodeStringList(p.Disks)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.conn.Exec(`
+		UPDATE smart_test_policies
+		SET name = ?, schedule = ?, test_type = ?, disks = ?, enabled = ?, updated_at = ?
+		WHERE id = ?
+	`, p.Name, p.Schedule, p.TestType, string(disksJSON), p.Enabled, p.UpdatedAt, p.ID)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// Get returns a policy by ID, or nil if it does not exist.
+func (r *SmartTestPolicyRepo) Get(id int64) (*SmartTestPolicy, error) {
+	p, err := scanSmartTestPolicy(r.db.conn.QueryRow(
+		"SELECT "+smartTestPolicyColumns+" FROM smart_test_policies WHERE id = ?", id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// List returns all policies ordered by name.
+func (r *SmartTestPolicyRepo) List() ([]SmartTestPolicy, error) {
+	rows, err := r.db.conn.Query("SELECT " + smartTestPolicyColumns + " FROM smart_test_policies ORDER BY name")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	policies := []SmartTestPolicy{}
+	for rows.Next() {
+		p, err := scanSmartTestPolicy(rows)
+		if err != nil {
+			return nil, err
+		}
+		policies = append(policies, p)
+	}
+	return policies, rows.Err()
+}
+
+// Delete removes a policy. Deleting a missing policy is not an error.
+func (r *SmartTestPolicyRepo) Delete(id int64) error {
+	_, err := r.db.conn.Exec("DELETE FROM smart_test_policies WHERE id = ?", id)
+	return err
+}
+
+// MarkRun records when a policy last ran.
+func (r *SmartTestPolicyRepo) MarkRun(id int64, at time.Time) error {
+	_, err := r.db.conn.Exec("UPDATE smart_test_policies SET last_run_at = ? WHERE id = ?", at, id)
+	return err
+}
@@ -0,0 +1,63 @@
+package store
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.aimuz.me/mynt/disk"
+)
+
+func TestSmartTestPolicyRepo(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewSmartTestPolicyRepo(db)
+
+	// The migration adds weekly short and monthly long tests of every disk
+	list, err := repo.List()
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	require.Equal(t, "monthly-long", list[0].Name)
+	require.Equal(t, disk.TestLong, list[0].TestType)
+	require.Equal(t, disk.TestShort, list[1].TestType)
+	require.Empty(t, list[1].Disks)
+	require.True(t, list[1].Enabled)
+	require.Nil(t, list[1].LastRunAt)
+
+	p := &SmartTestPolicy{Name: "ssd", Schedule: "@daily", TestType: disk.TestShort, Disks: []string{"nvme0n1"}, Enabled: true}
+	require.NoError(t, repo.Save(p))
+
+	ran := time.Date(2025, 6, 1, 2, 0, 0, 0, time.UTC)
+	require.NoError(t, repo.MarkRun(p.ID, ran))
+	p.Enabled = false
+	require.NoError(t, repo.Update(p))
+
+	got, err := repo.Get(p.ID)
+	require.NoError(t, err)
+	require.Equal(t, []string{"nvme0n1"}, got.Disks)
+	require.False(t, got.Enabled)
+	require.True(t, got.LastRunAt.Equal(ran))
+
+	require.NoError(t, repo.Delete(p.ID))
+	got, err = repo.Get(p.ID)
+	require.NoError(t, err)
+	require.Nil(t, got)
+	require.ErrorIs(t, repo.Update(p), sql.ErrNoRows)
+}
+
+func TestDiskRepo_SmartTestResult(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewDiskRepo(db)
+
+	require.NoError(t, repo.SaveSmart(&disk.DetailedReport{Disk: "sda", Passed: true}))
+	at := time.Date(2025, 6, 1, 4, 0, 0, 0, time.UTC)
+	require.NoError(t, repo.SaveSmartTestResult("sda", "Completed without error", at))
+	// A SMART rescan keeps the test result
+	require.NoError(t, repo.SaveSmart(&disk.DetailedReport{Disk: "sda", Passed: true, Temperature: 30}))
+
+	got, err := repo.GetSmart("sda")
+	require.NoError(t, err)
+	require.Equal(t, "Completed without error", got.LastTestResult)
+	require.True(t, got.LastTestAt.Equal(at))
+	require.Equal(t, 30, got.Temperature)
+}
@@ -3,19 +3,141 @@ package store
 import (
 	"database/sql"
 	"encoding/json"
+	"path"
+	"strings"
 	"time"
 )
 
 // SnapshotPolicy represents a snapshot schedule policy.
 type SnapshotPolicy struct {
-	ID        int64     `json:"id"`
-	Name      string    `json:"name"`
-	Schedule  string    `json:"schedule"`  // e.g., "@daily", "0 * * * *"
-	Retention string    `json:"retention"` // e.g., "7d", "24h"
-	Datasets  []string  `json:"datasets"`  // List of dataset names
-	Enabled   bool      `json:"enabled"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID        int64          `json:"id"`
+	Name      string         `json:"name"`
+	Schedule  string         `json:"schedule"`  // e.g., "@daily", "0 * * * *"
+	Retention string         `json:"retention"` // e.g., "7d", "24h"
+	Datasets  []PolicyTarget `json:"datasets"`  // Datasets to snapshot
+	Recursive bool           `json:"recursive"` // Also snapshot descendants of each dataset
+	Exclude   []string       `json:"exclude"`   // Glob patterns of descendants to skip
+	Enabled   bool           `json:"enabled"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+
+	// Grandfather-father-son retention: keep the newest KeepLast snapshots
+	// plus the newest one of each of the last KeepDaily days, KeepWeekly
+	// weeks and KeepMonthly months. When any is set they replace the
+	// time-based Retention.
+	KeepLast    int `json:"keep_last"`
+	KeepDaily   int `json:"keep_daily"`
+	KeepWeekly  int `json:"keep_weekly"`
+	KeepMonthly int `json:"keep_monthly"`
+}
+
+// PolicyTarget is a dataset covered by a snapshot policy, optionally with
+// its own retention.
+type PolicyTarget struct {
+	Dataset string `json:"dataset"`
+	// KeepLast, if set, keeps only this many of the newest snapshots of the
+	// dataset (and, for recursive policies, its descendants) in place of
+	// the policy's KeepLast or, without keep counts, its Retention.
+	KeepLast *int `json:"keep_last,omitempty"`
+}
+
+// UnmarshalJSON accepts either a bare dataset name, the form older
+// versions stored and still sent by clients, or the object form.
+func (t *PolicyTarget) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*t = PolicyTarget{Dataset: name}
+		return nil
+	}
+	type plain PolicyTarget
+	var p plain
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	*t = PolicyTarget(p)
+	return nil
+}
+
+// MarshalJSON writes a target without an override as a bare dataset name,
+// so that policies without overrides keep their old form.
+func (t PolicyTarget) MarshalJSON() ([]byte, error) {
+	if t.KeepLast == nil {
+		return json.Marshal(t.Dataset)
+	}
+	type plain PolicyTarget
+	return json.Marshal(plain(t))
+}
+
+// DatasetNames returns the names of the datasets the policy covers.
+func (p SnapshotPolicy) DatasetNames() []string {
+	names := make([]string, len(p.Datasets))
+	for i, t := range p.Datasets {
+		names[i] = t.Dataset
+	}
+	return names
+}
+
+// Covers reports whether the policy snapshots the dataset name: it is one
+// of the policy's datasets or, for a recursive policy, a descendant of one
+// that Excludes does not skip.
+func (p SnapshotPolicy) Covers(name string) bool {
+	for _, root := range p.Datasets {
+		if name == root.Dataset {
+			return true
+		}
+		if rel, ok := strings.CutPrefix(name, root.Dataset+"/"); ok && p.Recursive && !p.Excludes(root.Dataset, rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// Excludes reports whether the descendant rel of root, or any dataset
+// between them, matches an exclude pattern. Patterns match either the full
+// dataset name or the name relative to root.
+func (p SnapshotPolicy) Excludes(root, rel string) bool {
+	parts := strings.Split(rel, "/")
+	for i := range parts {
+		sub := strings.Join(parts[:i+1], "/")
+		for _, pattern := range p.Exclude {
+			if ok, _ := path.Match(pattern, sub); ok {
+				return true
+			}
+			if ok, _ := path.Match(pattern, root+"/"+sub); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// HasKeepCounts reports whether the policy uses count-based (GFS)
+// retention instead of the Retention duration.
+func (p SnapshotPolicy) HasKeepCounts() bool {
+	return p.KeepLast > 0 || p.KeepDaily > 0 || p.KeepWeekly > 0 || p.KeepMonthly > 0
+}
+
+// snapshotPolicyColumns lists the columns scanSnapshotPolicy reads, in order.
+const snapshotPolicyColumns = "id, name, schedule, retention, datasets, recursive, exclude, enabled, keep_last, keep_daily, keep_weekly, keep_monthly, created_at, updated_at"
+
+// scanSnapshotPolicy reads a row selected with snapshotPolicyColumns.
+func scanSnapshotPolicy(row interface{ Scan(...any) error }) (SnapshotPolicy, error) {
+	var p SnapshotPolicy
+	var datasetsJSON, excludeJSON string
+	err := row.Scan(&p.ID, &p.Name, &p.Schedule, &p.Retention, &datasetsJSON, &p.Recursive, &excludeJSON, &p.Enabled,
+		&p.KeepLast, &p.KeepDaily, &p.KeepWeekly, &p.KeepMonthly, &p.CreatedAt, &p.UpdatedAt)
+	if err != nil {
+		return p, err
+	}
+
+	if datasetsJSON != "" {
+		_ = json.Unmarshal([]byte(datasetsJSON), &p.Datasets)
+	}
+	if p.Datasets == nil {
+		p.Datasets = []PolicyTarget{}
+	}
+	p.Exclude = decodeStringList(excludeJSON)
+	return p, nil
 }
 
 // SnapshotPolicyRepo manages snapshot policy persistence.
@@ -37,11 +159,16 @@ func (r *SnapshotPolicyRepo) Save(policy *SnapshotPolicy) error {
 	if err != nil {
 		return err
 	}
+	excludeJSON, err := encodeStringList(policy.Exclude)
+	if err != nil {
+		return err
+	}
 
 	result, err := r.db.conn.Exec(`
-		INSERT INTO snapshot_policies (name, schedule, retention, datasets, enabled, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?)
-	`, policy.Name, policy.Schedule, policy.Retention, string(datasetsJSON), policy.Enabled, policy.CreatedAt, policy.UpdatedAt)
+		INSERT INTO snapshot_policies (name, schedule, retention, datasets, recursive, exclude, enabled, keep_last, keep_daily, keep_weekly, keep_monthly, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, policy.Name, policy.Schedule, policy.Retention, string(datasetsJSON), policy.Recursive, string(excludeJSON), policy.Enabled,
+		policy.KeepLast, policy.KeepDaily, policy.KeepWeekly, policy.KeepMonthly, policy.CreatedAt, policy.UpdatedAt)
 
 	if err != nil {
 		return err
@@ -54,25 +181,11 @@ func (r *SnapshotPolicyRepo) Save(policy *SnapshotPolicy) error {
 
 // GetByID returns a snapshot policy by ID.
 func (r *SnapshotPolicyRepo) GetByID(id int64) (*SnapshotPolicy, error) {
-	var p SnapshotPolicy
-	var datasetsJSON string
-
-	err := r.db.conn.QueryRow(`
-		SELECT id, name, schedule, retention, datasets, enabled, created_at, updated_at
-		FROM snapshot_policies WHERE id = ?
-	`, id).Scan(&p.ID, &p.Name, &p.Schedule, &p.Retention, &datasetsJSON, &p.Enabled, &p.CreatedAt, &p.UpdatedAt)
-
+	p, err := scanSnapshotPolicy(r.db.conn.QueryRow(
+		"SELECT "+snapshotPolicyColumns+" FROM snapshot_policies WHERE id = ?", id))
 	if err != nil {
 		return nil, err
 	}
-
-	if datasetsJSON != "" {
-		_ = json.Unmarshal([]byte(datasetsJSON), &p.Datasets)
-	}
-	if p.Datasets == nil {
-		p.Datasets = []string{}
-	}
-
 	return &p, nil
 }
 
@@ -84,19 +197,25 @@ func (r *SnapshotPolicyRepo) Update(policy *SnapshotPolicy) error {
 	if err != nil {
 		return err
 	}
+	excludeJSON, err := encodeStringList(policy.Exclude)
+	if err != nil {
+		return err
+	}
 
 	_, err = r.db.conn.Exec(`
 		UPDATE snapshot_policies 
-		SET name = ?, schedule = ?, retention = ?, datasets = ?, enabled = ?, updated_at = ?
+		SET name = ?, schedule = ?, retention = ?, datasets = ?, recursive = ?, exclude = ?, enabled = ?,
+			keep_last = ?, keep_daily = ?, keep_weekly = ?, keep_monthly = ?, updated_at = ?
 		WHERE id = ?
-	`, policy.Name, policy.Schedule, policy.Retention, string(datasetsJSON), policy.Enabled, policy.UpdatedAt, policy.ID)
+	`, policy.Name, policy.Schedule, policy.Retention, string(datasetsJSON), policy.Recursive, string(excludeJSON), policy.Enabled,
+		policy.KeepLast, policy.KeepDaily, policy.KeepWeekly, policy.KeepMonthly, policy.UpdatedAt, policy.ID)
 
 	return err
 }
 
 // List returns all snapshot policies.
 func (r *SnapshotPolicyRepo) List() ([]SnapshotPolicy, error) {
-	query := "SELECT id, name, schedule, retention, datasets, enabled, created_at, updated_at FROM snapshot_policies ORDER BY name"
+	query := "SELECT " + snapshotPolicyColumns + " FROM snapshot_policies ORDER BY name"
 
 	rows, err := r.db.conn.Query(query)
 	if err != nil {
@@ -106,50 +225,43 @@ func (r *SnapshotPolicyRepo) List() ([]SnapshotPolicy, error) {
 
 	var policies []SnapshotPolicy
 	for rows.Next() {
-		var p SnapshotPolicy
-		var datasetsJSON string
-		err := rows.Scan(&p.ID, &p.Name, &p.Schedule, &p.Retention, &datasetsJSON, &p.Enabled, &p.CreatedAt, &p.UpdatedAt)
+		p, err := scanSnapshotPolicy(rows)
 		if err != nil {
 			return nil, err
 		}
-
-		if datasetsJSON != "" {
-			_ = json.Unmarshal([]byte(datasetsJSON), &p.Datasets)
-		}
-		if p.Datasets == nil {
-			p.Datasets = []string{}
-		}
-
 		policies = append(policies, p)
 	}
 
 	return policies, nil
 }
 
-// Get retrieves a snapshot policy by ID.
-func (r *SnapshotPolicyRepo) Get(id int64) (*SnapshotPolicy, error) {
-	var p SnapshotPolicy
-	var datasetsJSON string
+// ListForDataset returns the policies that snapshot name, including
+// recursive policies on one of its ancestors that do not exclude it.
+func (r *SnapshotPolicyRepo) ListForDataset(name string) ([]SnapshotPolicy, error) {
+	policies, err := r.List()
+	if err != nil {
+		return nil, err
+	}
 
-	err := r.db.conn.QueryRow(`
-		SELECT id, name, schedule, retention, datasets, enabled, created_at, updated_at
-		FROM snapshot_policies WHERE id = ?
-	`, id).Scan(&p.ID, &p.Name, &p.Schedule, &p.Retention, &datasetsJSON, &p.Enabled, &p.CreatedAt, &p.UpdatedAt)
+	matched := []SnapshotPolicy{}
+	for _, p := range policies {
+		if p.Covers(name) {
+			matched = append(matched, p)
+		}
+	}
+	return matched, nil
+}
 
+// Get retrieves a snapshot policy by ID.
+func (r *SnapshotPolicyRepo) Get(id int64) (*SnapshotPolicy, error) {
+	p, err := scanSnapshotPolicy(r.db.conn.QueryRow(
+		"SELECT "+snapshotPolicyColumns+" FROM snapshot_policies WHERE id = ?", id))
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
 	if err != nil {
 		return nil, err
 	}
-
-	if datasetsJSON != "" {
-		_ = json.Unmarshal([]byte(datasetsJSON), &p.Datasets)
-	}
-	if p.Datasets == nil {
-		p.Datasets = []string{}
-	}
-
 	return &p, nil
 }
 
@@ -158,3 +270,20 @@ func (r *SnapshotPolicyRepo) Delete(id int64) error {
 	_, err := r.db.conn.Exec("DELETE FROM snapshot_policies WHERE id = ?", id)
 	return err
 }
+
+// encodeStringList marshals a string list, storing nil as an empty array.
+func encodeStringList(list []string) ([]byte, error) {
+	if list == nil {
+		list = []string{}
+	}
+	return json.Marshal(list)
+}
+
+// decodeStringList unmarshals a JSON string list, never returning nil.
+func decodeStringList(data string) []string {
+	list := []string{}
+	if data != "" {
+		_ = json.Unmarshal([]byte(data), &list)
+	}
+	return list
+}
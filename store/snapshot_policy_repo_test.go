@@ -0,0 +1,127 @@
+package store
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshotPolicyRepo_ListForDataset(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewSnapshotPolicyRepo(db)
+
+	hourly := &SnapshotPolicy{Name: "hourly", Schedule: "@hourly", Retention: "24h", Datasets: []PolicyTarget{{Dataset: "tank/data"}, {Dataset: "tank/home"}}, Enabled: true}
+	require.NoError(t, repo.Save(hourly))
+	weekly := &SnapshotPolicy{Name: "weekly", Schedule: "@weekly", Retention: "30d", Datasets: []PolicyTarget{{Dataset: "tank/media"}}, Enabled: true}
+	require.NoError(t, repo.Save(weekly))
+
+	policies, err := repo.ListForDataset("tank/data")
+	require.NoError(t, err)
+	require.Len(t, policies, 1)
+	require.Equal(t, "hourly", policies[0].Name)
+	require.Equal(t, "@hourly", policies[0].Schedule)
+
+	// Children are not covered by their parent's membership
+	policies, err = repo.ListForDataset("tank/data/child")
+	require.NoError(t, err)
+	require.Empty(t, policies)
+
+	// unless the policy is recursive and does not exclude them
+	recursive := &SnapshotPolicy{Name: "recursive", Schedule: "@daily", Retention: "7d", Datasets: []PolicyTarget{{Dataset: "tank"}},
+		Recursive: true, Exclude: []string{"scratch", "tank/data/cache*"}, Enabled: true}
+	require.NoError(t, repo.Save(recursive))
+
+	for name, want := range map[string]bool{
+		"tank":             true,
+		"tank/data":        true,
+		"tank/data/child":  true,
+		"tank/scratch":     false,
+		"tank/scratch/tmp": false,
+		"tank/data/cache1": false,
+		"tankard":          false,
+		"other/data":       false,
+	} {
+		policies, err := repo.ListForDataset(name)
+		require.NoError(t, err)
+		got := slices.ContainsFunc(policies, func(p SnapshotPolicy) bool { return p.Name == "recursive" })
+		require.Equal(t, want, got, name)
+	}
+}
+
+func TestSnapshotPolicyRepo_RecursiveExclude(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewSnapshotPolicyRepo(db)
+
+	policy := &SnapshotPolicy{Name: "daily", Schedule: "@daily", Retention: "7d", Datasets: []PolicyTarget{{Dataset: "tank/data"}}, Enabled: true}
+	require.NoError(t, repo.Save(policy))
+
+	got, err := repo.Get(policy.ID)
+	require.NoError(t, err)
+	require.False(t, got.Recursive)
+	require.Equal(t, []string{}, got.Exclude)
+
+	got.Recursive = true
+	got.Exclude = []string{"scratch", "tank/data/tmp*"}
+	require.NoError(t, repo.Update(got))
+
+	policies, err := repo.List()
+	require.NoError(t, err)
+	require.Len(t, policies, 1)
+	require.True(t, policies[0].Recursive)
+	require.Equal(t, []string{"scratch", "tank/data/tmp*"}, policies[0].Exclude)
+}
+
+func TestSnapshotPolicyRepo_KeepCounts(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewSnapshotPolicyRepo(db)
+
+	policy := &SnapshotPolicy{Name: "gfs", Schedule: "@daily", Retention: "forever", Datasets: []PolicyTarget{{Dataset: "tank/data"}}, Enabled: true,
+		KeepLast: 3, KeepDaily: 7}
+	require.NoError(t, repo.Save(policy))
+
+	got, err := repo.GetByID(policy.ID)
+	require.NoError(t, err)
+	require.Equal(t, 3, got.KeepLast)
+	require.Equal(t, 7, got.KeepDaily)
+
+	got.KeepWeekly = 4
+	got.KeepMonthly = 12
+	require.NoError(t, repo.Update(got))
+
+	policies, err := repo.List()
+	require.NoError(t, err)
+	require.Len(t, policies, 1)
+	require.Equal(t, 4, policies[0].KeepWeekly)
+	require.Equal(t, 12, policies[0].KeepMonthly)
+}
+
+func TestPolicyTarget_JSON(t *testing.T) {
+	var targets []PolicyTarget
+	require.NoError(t, json.Unmarshal([]byte(`["tank/docs", {"dataset": "tank/media", "keep_last": 3}]`), &targets))
+	require.Len(t, targets, 2)
+	require.Equal(t, PolicyTarget{Dataset: "tank/docs"}, targets[0])
+	require.Equal(t, "tank/media", targets[1].Dataset)
+	require.NotNil(t, targets[1].KeepLast)
+	require.Equal(t, 3, *targets[1].KeepLast)
+
+	// Targets without an override keep the bare-name form
+	out, err := json.Marshal(targets)
+	require.NoError(t, err)
+	require.JSONEq(t, `["tank/docs", {"dataset": "tank/media", "keep_last": 3}]`, string(out))
+}
+
+func TestSnapshotPolicyRepo_LegacyDatasets(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewSnapshotPolicyRepo(db)
+
+	policy := &SnapshotPolicy{Name: "legacy", Schedule: "@daily", Retention: "7d", Enabled: true}
+	require.NoError(t, repo.Save(policy))
+	_, err := db.conn.Exec(`UPDATE snapshot_policies SET datasets = '["tank/a","tank/b"]' WHERE id = ?`, policy.ID)
+	require.NoError(t, err)
+
+	got, err := repo.Get(policy.ID)
+	require.NoError(t, err)
+	require.Equal(t, []string{"tank/a", "tank/b"}, got.DatasetNames())
+}
@@ -1,7 +1,10 @@
 package store
 
 import (
+	"database/sql"
 	"encoding/json"
+	"errors"
+	"time"
 
 	"go.aimuz.me/mynt/task"
 )
@@ -102,3 +105,34 @@ func (r *TaskRepo) Get(id string) (*task.Operation, error) {
 	json.Unmarshal([]byte(resultJSON), &op.Result)
 	return &op, nil
 }
+
+// SaveIdempotencyKey records that key submitted the task with the given
+// ID, replacing any older use of the key.
+func (r *TaskRepo) SaveIdempotencyKey(key, taskID string, at time.Time) error {
+	_, err := r.db.conn.Exec(`
+		INSERT INTO task_idempotency_keys (key, task_id, created_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT(key) DO UPDATE SET task_id = excluded.task_id, created_at = excluded.created_at
+	`, key, taskID, at)
+	return err
+}
+
+// IdempotencyKey returns the ID of the task submitted with key at or after
+// since, or "" if there is none.
+func (r *TaskRepo) IdempotencyKey(key string, since time.Time) (string, error) {
+	var id string
+	err := r.db.conn.QueryRow(`
+		SELECT task_id FROM task_idempotency_keys
+		WHERE key = ? AND created_at >= ?
+	`, key, since).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	return id, err
+}
+
+// PruneIdempotencyKeys deletes the keys recorded before the given time.
+func (r *TaskRepo) PruneIdempotencyKeys(before time.Time) error {
+	_, err := r.db.conn.Exec(`DELETE FROM task_idempotency_keys WHERE created_at < ?`, before)
+	return err
+}
@@ -0,0 +1,67 @@
+package store
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.aimuz.me/mynt/task"
+)
+
+func TestTaskRepo_IdempotencyKey(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewTaskRepo(db)
+
+	now := time.Now()
+	id, err := repo.IdempotencyKey("k", now.Add(-time.Minute))
+	require.NoError(t, err)
+	require.Empty(t, id)
+
+	require.NoError(t, repo.SaveIdempotencyKey("k", "task-1", now))
+	id, err = repo.IdempotencyKey("k", now.Add(-time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, "task-1", id)
+
+	// Outside the window
+	id, err = repo.IdempotencyKey("k", now.Add(time.Minute))
+	require.NoError(t, err)
+	require.Empty(t, id)
+
+	// Reusing the key replaces the mapping
+	require.NoError(t, repo.SaveIdempotencyKey("k", "task-2", now))
+	id, err = repo.IdempotencyKey("k", now.Add(-time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, "task-2", id)
+
+	// Pruning drops keys recorded before the cutoff
+	require.NoError(t, repo.PruneIdempotencyKeys(now.Add(time.Second)))
+	id, err = repo.IdempotencyKey("k", now.Add(-time.Minute))
+	require.NoError(t, err)
+	require.Empty(t, id)
+}
+
+func TestTaskRepo_SubmitIdempotent(t *testing.T) {
+	db := setupTestDB(t)
+	m, err := task.New(NewTaskRepo(db))
+	require.NoError(t, err)
+
+	var runs atomic.Int32
+	fn := func(ctx context.Context, update func(int)) (interface{}, error) {
+		runs.Add(1)
+		return nil, nil
+	}
+
+	first, _, err := m.SubmitIdempotent("double-click", "send tank@a", fn)
+	require.NoError(t, err)
+	m.Close()
+
+	// The task has finished and left memory; the key is found in the db
+	second, existing, err := m.SubmitIdempotent("double-click", "send tank@a", fn)
+	require.NoError(t, err)
+	require.True(t, existing)
+	require.Equal(t, first.ID, second.ID)
+	require.Equal(t, task.StateDone, second.State)
+	require.Equal(t, int32(1), runs.Load())
+}
@@ -0,0 +1,229 @@
+package store
+
+import (
+	"database/sql"
+	"time"
+
+	"go.aimuz.me/mynt/event"
+)
+
+// webhookDeliveryLogSize is how many deliveries are kept per webhook.
+const webhookDeliveryLogSize = 100
+
+// Webhook posts events matching its filters to a URL, signing each body
+// with Secret so the receiver can verify it came from this NAS.
+type Webhook struct {
+	ID             int64          `json:"id"`
+	Name           string         `json:"name"`
+	URL            string         `json:"url"`
+	Secret         string         `json:"secret,omitempty"` // HMAC-SHA256 key; only returned when created
+	MinSeverity    event.Severity `json:"min_severity"`     // least severe event delivered
+	EventTypes     []string       `json:"event_types"`      // patterns such as "pool.*"; empty matches all
+	Enabled        bool           `json:"enabled"`
+	LastStatus     int            `json:"last_status"` // HTTP status of the last delivery; 0 if none or unreachable
+	LastError      string         `json:"last_error"`  // empty if the last delivery succeeded
+	LastDeliveryAt *time.Time     `json:"last_delivery_at,omitempty"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+}
+
+// Matches reports whether the webhook should receive evt.
+func (w *Webhook) Matches(evt event.Event) bool {
+	return w.Enabled && matchesFilter(w.MinSeverity, w.EventTypes, evt)
+}
+
+// WebhookDelivery is the outcome of posting one event to a webhook,
+// including retries.
+type WebhookDelivery struct {
+	ID        int64     `json:"id"`
+	WebhookID int64     `json:"webhook_id"`
+	EventType string    `json:"event_type"`
+	Status    int       `json:"status"` // HTTP status of the last attempt; 0 if unreachable
+	Error     string    `json:"error"`  // empty if delivered
+	Attempts  int       `json:"attempts"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// WebhookRepo manages webhook persistence and their delivery log.
+type WebhookRepo struct {
+	db *DB
+}
+
+// NewWebhookRepo creates a new webhook repository.
+func NewWebhookRepo(db *DB) *WebhookRepo {
+	return &WebhookRepo{db: db}
+}
+
+// Save creates a new webhook. It fails if the name is already taken.
+func (r *WebhookRepo) Save(w *Webhook) error {
+	w.CreatedAt = time.Now()
+	w.UpdatedAt = w.CreatedAt
+
+	typesJSON, err := encodeStringList(w.EventTypes)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.conn.Exec(`
+		INSERT INTO webhooks (name, url, secret, min_severity, event_types, enabled, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, w.Name, w.URL, w.Secret, w.MinSeverity, string(typesJSON), w.Enabled, w.CreatedAt, w.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	w.ID, _ = result.LastInsertId()
+	return nil
+}
+
+// Update replaces the settings of an existing webhook, keeping its
+// delivery status. It returns sql.ErrNoRows if the webhook does not exist.
+func (r *WebhookRepo) Update(w *Webhook) error {
+	w.UpdatedAt = time.Now()
+
+	typesJSON, err := encodeStringList(w.EventTypes)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.conn.Exec(`
+		UPDATE webhooks
+		SET name = ?, url = ?, secret = ?, min_severity = ?, event_types = ?, enabled = ?, updated_at = ?
+		WHERE id = ?
+	`, w.Name, w.URL, w.Secret, w.MinSeverity, string(typesJSON), w.Enabled, w.UpdatedAt, w.ID)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+const webhookColumns = `id, name, url, secret, min_severity, event_types, enabled, last_status, last_error, last_delivery_at, created_at, updated_at`
+
+// Get returns a webhook by ID, or nil if it does not exist.
+func (r *WebhookRepo) Get(id int64) (*Webhook, error) {
+	w, err := scanWebhook(r.db.conn.QueryRow(
+		`SELECT `+webhookColumns+` FROM webhooks WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return w, err
+}
+
+// List returns all webhooks ordered by name.
+func (r *WebhookRepo) List() ([]Webhook, error) {
+	rows, err := r.db.conn.Query(`SELECT ` + webhookColumns + ` FROM webhooks ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	webhooks := []Webhook{}
+	for rows.Next() {
+		w, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, *w)
+	}
+	return webhooks, rows.Err()
+}
+
+// Delete removes a webhook and its delivery log. Deleting a missing
+// webhook is not an error.
+func (r *WebhookRepo) Delete(id int64) error {
+	tx, err := r.db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM webhook_deliveries WHERE webhook_id = ?", id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM webhooks WHERE id = ?", id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// RecordDelivery adds d to the webhook's delivery log, drops entries past
+// webhookDeliveryLogSize and updates the webhook's last delivery status.
+func (r *WebhookRepo) RecordDelivery(d *WebhookDelivery) error {
+	if d.CreatedAt.IsZero() {
+		d.CreatedAt = time.Now()
+	}
+
+	tx, err := r.db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		INSERT INTO webhook_deliveries (webhook_id, event_type, status, error, attempts, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, d.WebhookID, d.EventType, d.Status, d.Error, d.Attempts, d.CreatedAt)
+	if err != nil {
+		return err
+	}
+	d.ID, _ = result.LastInsertId()
+
+	if _, err := tx.Exec(`
+		DELETE FROM webhook_deliveries
+		WHERE webhook_id = ? AND id NOT IN (
+			SELECT id FROM webhook_deliveries WHERE webhook_id = ? ORDER BY id DESC LIMIT ?
+		)
+	`, d.WebhookID, d.WebhookID, webhookDeliveryLogSize); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`
+		UPDATE webhooks SET last_status = ?, last_error = ?, last_delivery_at = ? WHERE id = ?
+	`, d.Status, d.Error, d.CreatedAt, d.WebhookID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Deliveries returns the latest deliveries of a webhook, newest first.
+func (r *WebhookRepo) Deliveries(webhookID int64, limit int) ([]WebhookDelivery, error) {
+	rows, err := r.db.conn.Query(`
+		SELECT id, webhook_id, event_type, status, error, attempts, created_at
+		FROM webhook_deliveries
+		WHERE webhook_id = ?
+		ORDER BY id DESC
+		LIMIT ?
+	`, webhookID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []WebhookDelivery{}
+	for rows.Next() {
+		var d WebhookDelivery
+		if err := rows.Scan(&d.ID, &d.WebhookID, &d.EventType, &d.Status, &d.Error, &d.Attempts, &d.CreatedAt); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, rows.Err()
+}
+
+// scanWebhook reads a row selected with webhookColumns.
+func scanWebhook(row interface{ Scan(...any) error }) (*Webhook, error) {
+	var w Webhook
+	var typesJSON string
+	var lastDelivery sql.NullTime
+	if err := row.Scan(&w.ID, &w.Name, &w.URL, &w.Secret, &w.MinSeverity, &typesJSON, &w.Enabled,
+		&w.LastStatus, &w.LastError, &lastDelivery, &w.CreatedAt, &w.UpdatedAt); err != nil {
+		return nil, err
+	}
+	w.EventTypes = decodeStringList(typesJSON)
+	if lastDelivery.Valid {
+		w.LastDeliveryAt = &lastDelivery.Time
+	}
+	return &w, nil
+}
@@ -0,0 +1,77 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.aimuz.me/mynt/event"
+)
+
+func TestWebhookRepo_CRUD(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewWebhookRepo(db)
+
+	hook := &Webhook{Name: "incidents", URL: "https://hooks.example.com/x", Secret: "s3cret", MinSeverity: event.SeverityWarning, EventTypes: []string{"pool.*"}, Enabled: true}
+	require.NoError(t, repo.Save(hook))
+	require.NotZero(t, hook.ID)
+	require.Error(t, repo.Save(&Webhook{Name: "incidents", URL: "https://other.example.com"}), "duplicate name")
+
+	got, err := repo.Get(hook.ID)
+	require.NoError(t, err)
+	require.Equal(t, "s3cret", got.Secret)
+	require.Equal(t, []string{"pool.*"}, got.EventTypes)
+	require.Nil(t, got.LastDeliveryAt)
+	require.True(t, got.Matches(event.Event{Type: event.PoolDegraded}))
+	require.False(t, got.Matches(event.Event{Type: event.DiskRemoved}))
+
+	hook.URL = "https://hooks.example.com/y"
+	require.NoError(t, repo.Update(hook))
+	require.ErrorIs(t, repo.Update(&Webhook{ID: 999, Name: "missing"}), sql.ErrNoRows)
+
+	list, err := repo.List()
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	require.Equal(t, "https://hooks.example.com/y", list[0].URL)
+
+	require.NoError(t, repo.Delete(hook.ID))
+	got, err = repo.Get(hook.ID)
+	require.NoError(t, err)
+	require.Nil(t, got)
+}
+
+func TestWebhookRepo_Deliveries(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewWebhookRepo(db)
+
+	hook := &Webhook{Name: "incidents", URL: "https://hooks.example.com/x", Secret: "s", MinSeverity: event.SeverityInfo, Enabled: true}
+	require.NoError(t, repo.Save(hook))
+
+	for i := range webhookDeliveryLogSize + 5 {
+		require.NoError(t, repo.RecordDelivery(&WebhookDelivery{
+			WebhookID: hook.ID, EventType: fmt.Sprintf("test.%d", i), Status: 200, Attempts: 1,
+		}))
+	}
+	require.NoError(t, repo.RecordDelivery(&WebhookDelivery{
+		WebhookID: hook.ID, EventType: "test.last", Status: 503, Error: "webhook returned 503", Attempts: 5,
+	}))
+
+	all, err := repo.Deliveries(hook.ID, 1000)
+	require.NoError(t, err)
+	require.Len(t, all, webhookDeliveryLogSize)
+	require.Equal(t, "test.last", all[0].EventType)
+	require.Equal(t, 5, all[0].Attempts)
+
+	got, err := repo.Get(hook.ID)
+	require.NoError(t, err)
+	require.Equal(t, 503, got.LastStatus)
+	require.Equal(t, "webhook returned 503", got.LastError)
+	require.NotNil(t, got.LastDeliveryAt)
+
+	// Deleting the webhook drops its log
+	require.NoError(t, repo.Delete(hook.ID))
+	all, err = repo.Deliveries(hook.ID, 1000)
+	require.NoError(t, err)
+	require.Empty(t, all)
+}
@@ -1,7 +1,10 @@
 // Package sysexec provides abstractions for executing external commands.
 package sysexec
 
-import "context"
+import (
+	"context"
+	"io"
+)
 
 // Executor is an interface for running external commands.
 // This abstraction allows for easy mocking in tests and provides a
@@ -15,4 +18,12 @@ type Executor interface {
 
 	// CombinedOutput executes a command and returns its combined stdout and stderr.
 	CombinedOutput(ctx context.Context, name string, args ...string) ([]byte, error)
+
+	// Stream executes a command, writing its standard output to w as it is
+	// produced. Use it for output too large to buffer, such as zfs send.
+	Stream(ctx context.Context, w io.Writer, name string, args ...string) error
+
+	// Feed executes a command with r as its standard input. Use it for
+	// input too large to buffer, such as zfs receive.
+	Feed(ctx context.Context, r io.Reader, name string, args ...string) error
 }
@@ -3,6 +3,7 @@ package sysexec
 import (
 	"context"
 	"fmt"
+	"io"
 	"sync"
 )
 
@@ -16,8 +17,9 @@ type MockExecutor struct {
 
 // Command records a command execution.
 type Command struct {
-	Name string
-	Args []string
+	Name  string
+	Args  []string
+	Stdin []byte // input consumed by Feed
 }
 
 // NewMock creates a new mock command executor.
@@ -89,3 +91,27 @@ func (m *MockExecutor) Output(ctx context.Context, name string, args ...string)
 func (m *MockExecutor) CombinedOutput(ctx context.Context, name string, args ...string) ([]byte, error) {
 	return m.Output(ctx, name, args...)
 }
+
+// Stream writes the mock output for a command to w.
+func (m *MockExecutor) Stream(ctx context.Context, w io.Writer, name string, args ...string) error {
+	out, err := m.Output(ctx, name, args...)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(out)
+	return err
+}
+
+// Feed consumes r and records it as the command's standard input.
+func (m *MockExecutor) Feed(ctx context.Context, r io.Reader, name string, args ...string) error {
+	in, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	m.commands = append(m.commands, Command{Name: name, Args: args, Stdin: in})
+	err = m.errors[name]
+	m.mu.Unlock()
+	return err
+}
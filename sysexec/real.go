@@ -1,10 +1,27 @@
 package sysexec
 
 import (
+	"bytes"
 	"context"
+	"fmt"
+	"io"
 	"os/exec"
+	"strings"
+	"time"
 )
 
+// waitDelay bounds how long a cancelled command may take to exit and
+// release its output pipes, e.g. a zfs process stuck on a hung pool.
+const waitDelay = 5 * time.Second
+
+// command creates a command that is killed when ctx is done. Callers give
+// up waiting for it at most waitDelay later, even if it does not die.
+func command(ctx context.Context, name string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.WaitDelay = waitDelay
+	return cmd
+}
+
 // RealExecutor executes real system commands using os/exec.
 type RealExecutor struct{}
 
@@ -15,18 +32,50 @@ func NewExecutor() *RealExecutor {
 
 // Run executes a command and returns an error if it fails.
 func (e *RealExecutor) Run(ctx context.Context, name string, args ...string) error {
-	cmd := exec.CommandContext(ctx, name, args...)
+	cmd := command(ctx, name, args...)
 	return cmd.Run()
 }
 
 // Output executes a command and returns its standard output.
 func (e *RealExecutor) Output(ctx context.Context, name string, args ...string) ([]byte, error) {
-	cmd := exec.CommandContext(ctx, name, args...)
+	cmd := command(ctx, name, args...)
 	return cmd.Output()
 }
 
 // CombinedOutput executes a command and returns its combined stdout and stderr.
 func (e *RealExecutor) CombinedOutput(ctx context.Context, name string, args ...string) ([]byte, error) {
-	cmd := exec.CommandContext(ctx, name, args...)
+	cmd := command(ctx, name, args...)
 	return cmd.CombinedOutput()
 }
+
+// Stream executes a command, writing its standard output to w. Standard
+// error is included in the returned error if the command fails.
+func (e *RealExecutor) Stream(ctx context.Context, w io.Writer, name string, args ...string) error {
+	var stderr bytes.Buffer
+	cmd := command(ctx, name, args...)
+	cmd.Stdout = w
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%w: %s", err, msg)
+		}
+		return err
+	}
+	return nil
+}
+
+// Feed executes a command reading standard input from r. Standard error
+// is included in the returned error if the command fails.
+func (e *RealExecutor) Feed(ctx context.Context, r io.Reader, name string, args ...string) error {
+	var stderr bytes.Buffer
+	cmd := command(ctx, name, args...)
+	cmd.Stdin = r
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%w: %s", err, msg)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package sysinfo
+
+import (
+	"context"
+	"os/exec"
+	"regexp"
+
+	"go.aimuz.me/mynt/sysexec"
+)
+
+// Capability reports whether a host feature is available.
+type Capability struct {
+	Available bool   `json:"available"`
+	Version   string `json:"version,omitempty"`
+}
+
+// Capabilities reports which storage and hardware tools are installed.
+type Capabilities struct {
+	ZFS      Capability `json:"zfs"`
+	Samba    Capability `json:"samba"`
+	NFS      Capability `json:"nfs"`
+	Smartctl Capability `json:"smartctl"`
+	Ledctl   Capability `json:"ledctl"`
+	Sensors  Capability `json:"sensors"`
+}
+
+// Prober detects host capabilities by looking for the tools behind them.
+type Prober struct {
+	exec     sysexec.Executor
+	lookPath func(file string) (string, error)
+}
+
+// NewProber creates a prober that inspects the real host.
+func NewProber() *Prober {
+	return &Prober{exec: sysexec.NewExecutor(), lookPath: exec.LookPath}
+}
+
+// Probe detects the host capabilities. It runs external commands, so
+// callers should cache the result.
+func (p *Prober) Probe(ctx context.Context) Capabilities {
+	return Capabilities{
+		ZFS:      p.probe(ctx, "zfs", "version"),
+		Samba:    p.probe(ctx, "smbd", "--version"),
+		NFS:      p.probe(ctx, "exportfs"),
+		Smartctl: p.probe(ctx, "smartctl", "--version"),
+		Ledctl:   p.probe(ctx, "ledctl", "--version"),
+		Sensors:  p.probe(ctx, "sensors", "-v"),
+	}
+}
+
+// probe reports whether bin is installed and, if versionArgs are given,
+// its version.
+func (p *Prober) probe(ctx context.Context, bin string, versionArgs ...string) Capability {
+	if _, err := p.lookPath(bin); err != nil {
+		return Capability{}
+	}
+
+	c := Capability{Available: true}
+	if len(versionArgs) > 0 {
+		// Some tools exit non-zero after printing their version
+		out, _ := p.exec.CombinedOutput(ctx, bin, versionArgs...)
+		c.Version = versionPattern.FindString(string(out))
+	}
+	return c
+}
+
+// versionPattern matches the first dotted version number in tool output,
+// e.g. "zfs-2.1.5-1ubuntu6" or "smartctl 7.2 2020-12-30 r5155".
+var versionPattern = regexp.MustCompile(`\d+(\.\d+)+`)
@@ -0,0 +1,44 @@
+package sysinfo
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.aimuz.me/mynt/sysexec"
+)
+
+func TestProbe(t *testing.T) {
+	installed := map[string]bool{"zfs": true, "smartctl": true, "exportfs": true}
+
+	exec := sysexec.NewMock()
+	exec.SetOutput("zfs", []byte("zfs-2.1.5-1ubuntu6~22.04.1\nzfs-kmod-2.1.5-1ubuntu6~22.04.1\n"))
+	exec.SetOutput("smartctl", []byte("smartctl 7.2 2020-12-30 r5155 [x86_64-linux-5.15.0] (local build)\n"))
+
+	p := &Prober{
+		exec: exec,
+		lookPath: func(file string) (string, error) {
+			if installed[file] {
+				return "/usr/sbin/" + file, nil
+			}
+			return "", errors.New("executable file not found in $PATH")
+		},
+	}
+
+	got := p.Probe(context.Background())
+	want := Capabilities{
+		ZFS:      Capability{Available: true, Version: "2.1.5"},
+		NFS:      Capability{Available: true},
+		Smartctl: Capability{Available: true, Version: "7.2"},
+	}
+	if got != want {
+		t.Errorf("Probe() = %+v\nwant %+v", got, want)
+	}
+
+	// Missing tools are never executed
+	for _, c := range exec.Commands() {
+		if !installed[c.Name] {
+			t.Errorf("ran %s, which is not installed", c.Name)
+		}
+	}
+}
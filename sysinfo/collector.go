@@ -2,6 +2,7 @@
 package sysinfo
 
 import (
+	"slices"
 	"sync"
 	"syscall"
 	"time"
@@ -9,11 +10,16 @@ import (
 	"github.com/shirou/gopsutil/v4/cpu"
 	"github.com/shirou/gopsutil/v4/disk"
 	"github.com/shirou/gopsutil/v4/host"
+	"github.com/shirou/gopsutil/v4/load"
 	"github.com/shirou/gopsutil/v4/mem"
 	"github.com/shirou/gopsutil/v4/net"
 	"github.com/shirou/gopsutil/v4/process"
 )
 
+// processCacheTTL is how long a process listing is reused before the
+// process table is walked again.
+const processCacheTTL = 2 * time.Second
+
 // cpuSnapshot stores CPU time for rate calculation.
 type cpuSnapshot struct {
 	cpuTime float64
@@ -33,6 +39,13 @@ type Collector struct {
 	lastCPUTime time.Time
 	uidCache    map[int]string
 	readBuf     [4096]byte
+
+	// Cached process listing, refreshed after processCacheTTL
+	procCache   []Process
+	procCacheAt time.Time
+	now         func() time.Time
+	walk        func() ([]Process, error)
+	netCounters func() ([]net.IOCountersStat, error)
 }
 
 type netSnapshot struct {
@@ -47,12 +60,40 @@ type diskSnapshot struct {
 
 // NewCollector creates a new system info collector.
 func NewCollector() *Collector {
-	return &Collector{
+	c := &Collector{
 		lastNet:  make(map[string]netSnapshot),
 		lastDisk: make(map[string]diskSnapshot),
 		lastCPU:  make(map[int]cpuSnapshot),
 		uidCache: make(map[int]string),
+		now:      time.Now,
 	}
+	c.walk = c.listProcesses
+	c.netCounters = func() ([]net.IOCountersStat, error) { return net.IOCounters(true) }
+	return c
+}
+
+// ListProcesses returns a list of running processes.
+// Results are cached for processCacheTTL so that concurrent clients polling
+// the process list share a single walk. CPU percentages are computed only
+// when the process table is actually walked, so they stay relative to the
+// previous walk rather than to the previous call.
+func (c *Collector) ListProcesses() ([]Process, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	if c.procCache != nil && now.Sub(c.procCacheAt) < processCacheTTL {
+		return slices.Clone(c.procCache), nil
+	}
+
+	procs, err := c.walk()
+	if err != nil {
+		return nil, err
+	}
+	c.procCache = procs
+	c.procCacheAt = now
+	// Callers may filter or reorder the result in place.
+	return slices.Clone(procs), nil
 }
 
 // Collect gathers current system statistics.
@@ -60,7 +101,7 @@ func (c *Collector) Collect() (*Stats, error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	now := time.Now()
+	now := c.now()
 	elapsed := now.Sub(c.lastTime).Seconds()
 	skipSpeeds := c.lastTime.IsZero() || elapsed < 0.1
 	if skipSpeeds {
@@ -87,10 +128,17 @@ func (c *Collector) Collect() (*Stats, error) {
 		}
 	}
 
+	// Load average
+	if avg, err := load.Avg(); err == nil {
+		stats.LoadAvg = [3]float64{avg.Load1, avg.Load5, avg.Load15}
+	}
+
 	// CPU frequency
 	if infos, err := cpu.Info(); err == nil && len(infos) > 0 {
 		stats.CPU.Frequency = infos[0].Mhz
 	}
+	stats.CPU.PerCoreFreq = coreFrequencies()
+	stats.CPU.Temperature = cpuTemperature()
 
 	// Memory stats
 	if vmem, err := mem.VirtualMemory(); err == nil {
@@ -107,26 +155,28 @@ func (c *Collector) Collect() (*Stats, error) {
 		stats.Memory.SwapTotal = swap.Total
 		stats.Memory.SwapUsed = swap.Used
 	}
+	if devices, err := SwapDevices(); err == nil {
+		stats.Memory.SwapDevices = devices
+	}
 
-	// Uptime
-	if uptime, err := host.Uptime(); err == nil {
+	// Uptime is derived from the fixed boot time so that the two agree
+	// and BootTime does not jitter between collects
+	if boot := bootTimestamp(); boot > 0 {
+		stats.BootTime = boot
+		stats.Uptime = uint64(max(0, now.Unix()-boot))
+	} else if uptime, err := host.Uptime(); err == nil {
 		stats.Uptime = uptime
 	}
 
 	// Network stats
-	if counters, err := net.IOCounters(true); err == nil {
+	if counters, err := c.netCounters(); err == nil {
 		newNet := make(map[string]netSnapshot)
 		for _, ioc := range counters {
 			if ioc.Name == "lo" {
 				continue // Skip loopback
 			}
 
-			ns := NetStats{
-				Name:     ioc.Name,
-				BytesIn:  ioc.BytesRecv,
-				BytesOut: ioc.BytesSent,
-				IsUp:     ioc.BytesRecv > 0 || ioc.BytesSent > 0,
-			}
+			ns := newNetStats(ioc)
 
 			// Calculate speed if we have previous data
 			if prev, ok := c.lastNet[ioc.Name]; ok && elapsed > 0 {
@@ -174,6 +224,39 @@ func (c *Collector) Collect() (*Stats, error) {
 	return stats, nil
 }
 
+// Interfaces returns the cumulative counters of each network interface,
+// without rates. Unlike Collect it does not disturb the snapshots used for
+// rate calculation, so it can be polled independently.
+func (c *Collector) Interfaces() ([]NetStats, error) {
+	counters, err := c.netCounters()
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make([]NetStats, 0, len(counters))
+	for _, ioc := range counters {
+		if ioc.Name == "lo" {
+			continue
+		}
+		stats = append(stats, newNetStats(ioc))
+	}
+	return stats, nil
+}
+
+// newNetStats converts gopsutil counters to NetStats without rates.
+func newNetStats(ioc net.IOCountersStat) NetStats {
+	return NetStats{
+		Name:      ioc.Name,
+		BytesIn:   ioc.BytesRecv,
+		BytesOut:  ioc.BytesSent,
+		IsUp:      ioc.BytesRecv > 0 || ioc.BytesSent > 0,
+		RxErrors:  ioc.Errin,
+		TxErrors:  ioc.Errout,
+		RxDropped: ioc.Dropin,
+		TxDropped: ioc.Dropout,
+	}
+}
+
 // KillProcess sends a signal to a process.
 func (c *Collector) KillProcess(pid int, signal syscall.Signal) error {
 	p, err := process.NewProcess(int32(pid))
@@ -54,11 +54,18 @@ import (
 // Cached at init time for performance.
 var machTimebaseNsPerTick = float64(C.getMachTimebaseNsPerTick())
 
-// ListProcesses returns a list of running processes.
-func (c *Collector) ListProcesses() ([]Process, error) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
+// bootTimestamp returns the boot time from the kern.boottime sysctl, or 0
+// if it cannot be read.
+func bootTimestamp() int64 {
+	tv, err := unix.SysctlTimeval("kern.boottime")
+	if err != nil {
+		return 0
+	}
+	return tv.Sec
+}
 
+// listProcesses walks the running processes. The caller must hold c.mu.
+func (c *Collector) listProcesses() ([]Process, error) {
 	// Step 1: Get all processes using sysctl (ONE syscall for all PIDs)
 	kprocs, err := unix.SysctlKinfoProcSlice("kern.proc.all")
 	if err != nil {
@@ -52,6 +52,11 @@ func getBootTime() int64 {
 	panic("btime not found in /proc/stat")
 }
 
+// bootTimestamp returns the boot time read from /proc/stat at startup.
+func bootTimestamp() int64 {
+	return bootTime
+}
+
 // memTotal caches total system memory in bytes.
 var memTotal uint64
 
@@ -80,12 +85,9 @@ func init() {
 	}
 }
 
-// ListProcesses returns a list of running processes.
+// listProcesses walks the running processes. The caller must hold c.mu.
 // Uses direct /proc parsing for maximum performance on Linux.
-func (c *Collector) ListProcesses() ([]Process, error) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-
+func (c *Collector) listProcesses() ([]Process, error) {
 	now := time.Now()
 	elapsed := now.Sub(c.lastCPUTime).Seconds()
 
@@ -7,12 +7,23 @@ package sysinfo
 import (
 	"context"
 
+	"github.com/shirou/gopsutil/v4/host"
 	"github.com/shirou/gopsutil/v4/process"
 )
 
-// ListProcesses returns a list of running processes.
+// bootTimestamp returns the boot time reported by gopsutil, or 0 if it
+// cannot be determined.
+func bootTimestamp() int64 {
+	boot, err := host.BootTime()
+	if err != nil {
+		return 0
+	}
+	return int64(boot)
+}
+
+// listProcesses walks the running processes. The caller must hold c.mu.
 // Uses gopsutil for portability on non-Linux systems.
-func (c *Collector) ListProcesses() ([]Process, error) {
+func (c *Collector) listProcesses() ([]Process, error) {
 	ctx := context.Background()
 	procs, err := process.ProcessesWithContext(ctx)
 	if err != nil {
@@ -1,21 +1,147 @@
 package sysinfo
 
 import (
+	"reflect"
+	"runtime"
 	"testing"
 	"time"
+
+	"github.com/shirou/gopsutil/v4/net"
 )
 
+func TestCollector_Collect_LoadAvg(t *testing.T) {
+	if runtime.GOOS != "linux" && runtime.GOOS != "darwin" {
+		t.Skip("load average not available on", runtime.GOOS)
+	}
+
+	stats, err := NewCollector().Collect()
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	for i, v := range stats.LoadAvg {
+		if v < 0 {
+			t.Errorf("LoadAvg[%d] = %v, want >= 0", i, v)
+		}
+	}
+}
+
+func TestCollector_Collect_BootTime(t *testing.T) {
+	c := NewCollector()
+	now := time.Now()
+	c.now = func() time.Time { return now }
+
+	first, err := c.Collect()
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	if first.BootTime <= 0 {
+		t.Skip("boot time not available on", runtime.GOOS)
+	}
+
+	now = now.Add(5 * time.Second)
+	second, err := c.Collect()
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	if second.BootTime != first.BootTime {
+		t.Errorf("BootTime changed from %d to %d", first.BootTime, second.BootTime)
+	}
+	if second.Uptime != first.Uptime+5 {
+		t.Errorf("Uptime = %d, want %d", second.Uptime, first.Uptime+5)
+	}
+	if got := now.Unix() - int64(second.Uptime); got != second.BootTime {
+		t.Errorf("now - Uptime = %d, want BootTime %d", got, second.BootTime)
+	}
+}
+
+func TestCollector_ListProcesses_Cache(t *testing.T) {
+	c := NewCollector()
+	now := time.Unix(1000, 0)
+	c.now = func() time.Time { return now }
+	walks := 0
+	c.walk = func() ([]Process, error) {
+		walks++
+		return []Process{{PID: walks, Name: "init"}}, nil
+	}
+
+	first, err := c.ListProcesses()
+	if err != nil {
+		t.Fatalf("ListProcesses() error = %v", err)
+	}
+	now = now.Add(processCacheTTL - time.Millisecond)
+	second, err := c.ListProcesses()
+	if err != nil {
+		t.Fatalf("ListProcesses() error = %v", err)
+	}
+	if walks != 1 {
+		t.Fatalf("walks = %d within TTL, want 1", walks)
+	}
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("second call = %+v, want cached %+v", second, first)
+	}
+
+	// Mutating a returned slice must not corrupt the cache.
+	second[0].Name = "changed"
+	third, _ := c.ListProcesses()
+	if third[0].Name != "init" {
+		t.Errorf("cached name = %q, want %q", third[0].Name, "init")
+	}
+
+	now = now.Add(time.Millisecond)
+	refreshed, err := c.ListProcesses()
+	if err != nil {
+		t.Fatalf("ListProcesses() error = %v", err)
+	}
+	if walks != 2 {
+		t.Errorf("walks = %d after TTL, want 2", walks)
+	}
+	if refreshed[0].PID != 2 {
+		t.Errorf("PID = %d after refresh, want 2", refreshed[0].PID)
+	}
+}
+
+func TestCollector_Interfaces_Errors(t *testing.T) {
+	c := NewCollector()
+	c.netCounters = func() ([]net.IOCountersStat, error) {
+		return []net.IOCountersStat{
+			{Name: "lo", BytesRecv: 100, Errin: 1},
+			{Name: "eth0", BytesRecv: 2048, BytesSent: 1024, Errin: 3, Errout: 1, Dropin: 7, Dropout: 2},
+		}, nil
+	}
+
+	want := []NetStats{{
+		Name: "eth0", BytesIn: 2048, BytesOut: 1024, IsUp: true,
+		RxErrors: 3, TxErrors: 1, RxDropped: 7, TxDropped: 2,
+	}}
+
+	got, err := c.Interfaces()
+	if err != nil {
+		t.Fatalf("Interfaces() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Interfaces() = %+v, want %+v", got, want)
+	}
+
+	stats, err := c.Collect()
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	if !reflect.DeepEqual(stats.Network, want) {
+		t.Errorf("Collect().Network = %+v, want %+v", stats.Network, want)
+	}
+}
+
 // BenchmarkListProcesses benchmarks the optimized procfs-based implementation.
 func BenchmarkListProcesses(b *testing.B) {
 	c := NewCollector()
 	// Warmup: ensure lastCPU is initialized
-	if _, err := c.ListProcesses(); err != nil {
+	if _, err := c.listProcesses(); err != nil {
 		b.Fatalf("warmup failed: %v", err)
 	}
 	time.Sleep(100 * time.Millisecond)
 	b.ResetTimer()
 	b.ReportAllocs()
 	for b.Loop() {
-		_, _ = c.ListProcesses()
+		_, _ = c.listProcesses()
 	}
 }
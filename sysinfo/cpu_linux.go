@@ -0,0 +1,125 @@
+//go:build linux
+
+package sysinfo
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	thermalDir = "/sys/class/thermal"
+	cpuSysDir  = "/sys/devices/system/cpu"
+)
+
+// cpuZoneTypes lists thermal zone types that measure the CPU, best first.
+// acpitz is a board sensor and only used when nothing better exists.
+var cpuZoneTypes = []string{"x86_pkg_temp", "cpu", "soc", "acpitz"}
+
+// cpuTemperature returns the CPU temperature in Celsius, or 0 if no
+// thermal zone reports it.
+func cpuTemperature() float64 {
+	return readCPUTemperature(thermalDir)
+}
+
+// readCPUTemperature picks the thermal zone under dir whose type best
+// matches cpuZoneTypes and returns its temperature.
+func readCPUTemperature(dir string) float64 {
+	zones, _ := filepath.Glob(filepath.Join(dir, "thermal_zone*"))
+	best, bestRank := 0.0, len(cpuZoneTypes)
+	for _, zone := range zones {
+		typ, err := os.ReadFile(filepath.Join(zone, "type"))
+		if err != nil {
+			continue
+		}
+		rank := slices.IndexFunc(cpuZoneTypes, func(t string) bool {
+			return strings.Contains(strings.ToLower(string(typ)), t)
+		})
+		if rank < 0 || rank >= bestRank {
+			continue
+		}
+		temp, err := os.ReadFile(filepath.Join(zone, "temp"))
+		if err != nil {
+			continue
+		}
+		if c, ok := parseMilliCelsius(temp); ok {
+			best, bestRank = c, rank
+		}
+	}
+	return best
+}
+
+// parseMilliCelsius parses a sysfs temperature in millidegrees Celsius.
+func parseMilliCelsius(b []byte) (float64, bool) {
+	v, err := strconv.ParseInt(string(bytes.TrimSpace(b)), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return float64(v) / 1000, true
+}
+
+// coreFrequencies returns the current frequency of each logical CPU in
+// MHz, from cpufreq or, without it (e.g. in VMs), /proc/cpuinfo.
+func coreFrequencies() []float64 {
+	if freqs := readCPUFreqs(cpuSysDir); len(freqs) > 0 {
+		return freqs
+	}
+	data, err := os.ReadFile("/proc/cpuinfo")
+	if err != nil {
+		return nil
+	}
+	return parseCPUInfoMHz(data)
+}
+
+// readCPUFreqs reads cpu*/cpufreq/scaling_cur_freq (kHz) under dir,
+// ordered by CPU number. CPUs without a readable value are reported as 0
+// so indexes line up with per-core usage.
+func readCPUFreqs(dir string) []float64 {
+	paths, _ := filepath.Glob(filepath.Join(dir, "cpu[0-9]*", "cpufreq", "scaling_cur_freq"))
+	if len(paths) == 0 {
+		return nil
+	}
+
+	byCPU := make(map[int]float64, len(paths))
+	last := -1
+	for _, p := range paths {
+		n, err := strconv.Atoi(strings.TrimPrefix(filepath.Base(filepath.Dir(filepath.Dir(p))), "cpu"))
+		if err != nil {
+			continue
+		}
+		last = max(last, n)
+		data, err := os.ReadFile(p)
+		if err != nil {
+			continue
+		}
+		if khz, err := strconv.ParseFloat(string(bytes.TrimSpace(data)), 64); err == nil {
+			byCPU[n] = khz / 1000
+		}
+	}
+
+	freqs := make([]float64, last+1)
+	for n, mhz := range byCPU {
+		freqs[n] = mhz
+	}
+	return freqs
+}
+
+// parseCPUInfoMHz returns the "cpu MHz" value of each processor in
+// /proc/cpuinfo, in order.
+func parseCPUInfoMHz(data []byte) []float64 {
+	var freqs []float64
+	for line := range strings.Lines(string(data)) {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok || strings.TrimSpace(key) != "cpu MHz" {
+			continue
+		}
+		if mhz, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+			freqs = append(freqs, mhz)
+		}
+	}
+	return freqs
+}
@@ -0,0 +1,82 @@
+//go:build linux
+
+package sysinfo
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeFiles creates files under dir from a path -> content map.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestReadCPUTemperature(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"thermal_zone0/type": "acpitz\n",
+		"thermal_zone0/temp": "27800\n",
+		"thermal_zone1/type": "x86_pkg_temp\n",
+		"thermal_zone1/temp": "48500\n",
+		"thermal_zone2/type": "iwlwifi_1\n",
+		"thermal_zone2/temp": "39000\n",
+	})
+
+	if got := readCPUTemperature(dir); got != 48.5 {
+		t.Errorf("readCPUTemperature() = %v, want 48.5", got)
+	}
+}
+
+func TestReadCPUTemperature_FallbackAndMissing(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"thermal_zone0/type": "acpitz\n",
+		"thermal_zone0/temp": "27800\n",
+	})
+	if got := readCPUTemperature(dir); got != 27.8 {
+		t.Errorf("readCPUTemperature() = %v, want 27.8", got)
+	}
+	if got := readCPUTemperature(t.TempDir()); got != 0 {
+		t.Errorf("readCPUTemperature() without zones = %v, want 0", got)
+	}
+}
+
+func TestReadCPUFreqs(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"cpu0/cpufreq/scaling_cur_freq":    "3400000\n",
+		"cpu1/cpufreq/scaling_cur_freq":    "800000\n",
+		"cpu10/cpufreq/scaling_cur_freq":   "2200500\n",
+		"cpufreq/policy0/scaling_cur_freq": "3400000\n", // not a CPU
+	})
+
+	got := readCPUFreqs(dir)
+	if len(got) != 11 || got[0] != 3400 || got[1] != 800 || got[10] != 2200.5 || got[5] != 0 {
+		t.Errorf("readCPUFreqs() = %v", got)
+	}
+	if got := readCPUFreqs(t.TempDir()); got != nil {
+		t.Errorf("readCPUFreqs() without cpufreq = %v, want nil", got)
+	}
+}
+
+func TestParseCPUInfoMHz(t *testing.T) {
+	input := "processor\t: 0\nmodel name\t: Intel(R) Xeon(R)\ncpu MHz\t\t: 2399.998\n\n" +
+		"processor\t: 1\nmodel name\t: Intel(R) Xeon(R)\ncpu MHz\t\t: 2400.102\n"
+
+	want := []float64{2399.998, 2400.102}
+	if got := parseCPUInfoMHz([]byte(input)); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseCPUInfoMHz() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,32 @@
+//go:build !linux
+
+package sysinfo
+
+import (
+	"strings"
+
+	"github.com/shirou/gopsutil/v4/sensors"
+)
+
+// cpuTemperature returns the hottest CPU sensor reported by gopsutil in
+// Celsius, or 0 if there is none.
+func cpuTemperature() float64 {
+	temps, err := sensors.SensorsTemperatures()
+	if err != nil && len(temps) == 0 {
+		return 0
+	}
+	var hottest float64
+	for _, t := range temps {
+		key := strings.ToLower(t.SensorKey)
+		if strings.Contains(key, "cpu") || strings.Contains(key, "die") {
+			hottest = max(hottest, t.Temperature)
+		}
+	}
+	return hottest
+}
+
+// coreFrequencies returns nil; per-core frequency is only collected on
+// Linux.
+func coreFrequencies() []float64 {
+	return nil
+}
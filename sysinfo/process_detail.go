@@ -0,0 +1,44 @@
+package sysinfo
+
+import "strings"
+
+// redactedValue replaces the value of environment variables that look
+// like secrets.
+const redactedValue = "[REDACTED]"
+
+// secretEnvMarkers are substrings of variable names whose values are
+// redacted, matched case-insensitively.
+var secretEnvMarkers = []string{
+	"PASSWORD", "PASSWD", "SECRET", "TOKEN", "KEY", "CREDENTIAL", "AUTH", "PRIVATE", "COOKIE", "SESSION",
+}
+
+// ProcessDetail returns the environment, working directory, open file
+// count and parent PID of a process. Values of environment variables
+// whose names suggest secrets are redacted. Fields the platform cannot
+// provide are left empty.
+func (c *Collector) ProcessDetail(pid int) (*ProcessDetail, error) {
+	d, err := processDetail(pid)
+	if err != nil {
+		return nil, err
+	}
+	d.Environ = redactEnv(d.Environ)
+	return d, nil
+}
+
+// redactEnv replaces the values of secret-looking variables in env.
+func redactEnv(env []string) []string {
+	for i, kv := range env {
+		name, _, ok := strings.Cut(kv, "=")
+		if !ok {
+			continue
+		}
+		upper := strings.ToUpper(name)
+		for _, marker := range secretEnvMarkers {
+			if strings.Contains(upper, marker) {
+				env[i] = name + "=" + redactedValue
+				break
+			}
+		}
+	}
+	return env
+}
@@ -0,0 +1,100 @@
+//go:build darwin
+
+package sysinfo
+
+/*
+#include <stdlib.h>
+#include <string.h>
+#include <libproc.h>
+#include <sys/proc_info.h>
+
+// Copy the process's current working directory into buf.
+// Returns 0 on success, -1 if it cannot be read.
+static int getProcessCwd(int pid, char *buf, int len) {
+    struct proc_vnodepathinfo vpi;
+    int ret = proc_pidinfo(pid, PROC_PIDVNODEPATHINFO, 0, &vpi, sizeof(vpi));
+    if (ret != sizeof(vpi)) {
+        return -1;
+    }
+    strlcpy(buf, vpi.pvi_cdir.vip_path, len);
+    return 0;
+}
+
+// Count the process's open file descriptors, or -1 if unavailable.
+// A NULL buffer only yields an upper bound, so the list is fetched.
+static int getProcessFDCount(int pid) {
+    int size = proc_pidinfo(pid, PROC_PIDLISTFDS, 0, NULL, 0);
+    if (size <= 0) {
+        return -1;
+    }
+    struct proc_fdinfo *fds = malloc(size);
+    if (fds == NULL) {
+        return -1;
+    }
+    size = proc_pidinfo(pid, PROC_PIDLISTFDS, 0, fds, size);
+    free(fds);
+    if (size <= 0) {
+        return -1;
+    }
+    return size / PROC_PIDLISTFD_SIZE;
+}
+*/
+import "C"
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// processDetail reads a process's details via sysctl and libproc.
+func processDetail(pid int) (*ProcessDetail, error) {
+	kp, err := unix.SysctlKinfoProc("kern.proc.pid", pid)
+	if err != nil {
+		return nil, fmt.Errorf("process %d: %w", pid, err)
+	}
+	if int(kp.Proc.P_pid) != pid {
+		return nil, fmt.Errorf("process %d: not found", pid)
+	}
+
+	d := &ProcessDetail{PID: pid, PPID: int(kp.Eproc.Ppid)}
+
+	buf := make([]byte, 1024) // MAXPATHLEN
+	if C.getProcessCwd(C.int(pid), (*C.char)(unsafe.Pointer(&buf[0])), C.int(len(buf))) == 0 {
+		d.Cwd = cstring(buf)
+	}
+	if n := C.getProcessFDCount(C.int(pid)); n >= 0 {
+		d.OpenFiles = int(n)
+	}
+	if args, err := unix.SysctlRaw("kern.procargs2", pid); err == nil {
+		d.Environ = procArgsEnviron(args)
+	}
+	return d, nil
+}
+
+// procArgsEnviron extracts the environment from kern.procargs2 output:
+// argc, the executable path, NUL padding, argc arguments, then the
+// environment, each NUL-terminated.
+func procArgsEnviron(b []byte) []string {
+	if len(b) < 4 {
+		return nil
+	}
+	argc := int(binary.LittleEndian.Uint32(b))
+	fields := bytes.Split(b[4:], []byte{0})
+
+	// Skip the executable path and its padding, then the arguments.
+	i := 1
+	for i < len(fields) && len(fields[i]) == 0 {
+		i++
+	}
+	i += argc
+
+	var env []string
+	for ; i < len(fields) && len(fields[i]) > 0; i++ {
+		env = append(env, string(fields[i]))
+	}
+	return env
+}
@@ -0,0 +1,71 @@
+//go:build linux
+
+package sysinfo
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// processDetail reads a process's details from /proc.
+func processDetail(pid int) (*ProcessDetail, error) {
+	return readProcessDetail("/proc", pid)
+}
+
+// readProcessDetail reads a process's details from the proc filesystem
+// mounted at procRoot. The stat file must be readable; the environment,
+// cwd and fd directory are usually only readable for our own processes or
+// as root, and are left empty otherwise.
+func readProcessDetail(procRoot string, pid int) (*ProcessDetail, error) {
+	dir := filepath.Join(procRoot, strconv.Itoa(pid))
+
+	stat, err := os.ReadFile(filepath.Join(dir, "stat"))
+	if err != nil {
+		return nil, fmt.Errorf("process %d: %w", pid, err)
+	}
+	ppid, err := parseStatPPID(stat)
+	if err != nil {
+		return nil, fmt.Errorf("process %d: %w", pid, err)
+	}
+
+	d := &ProcessDetail{PID: pid, PPID: ppid}
+	if environ, err := os.ReadFile(filepath.Join(dir, "environ")); err == nil {
+		d.Environ = parseEnviron(environ)
+	}
+	if cwd, err := os.Readlink(filepath.Join(dir, "cwd")); err == nil {
+		d.Cwd = cwd
+	}
+	if fds, err := os.ReadDir(filepath.Join(dir, "fd")); err == nil {
+		d.OpenFiles = len(fds)
+	}
+	return d, nil
+}
+
+// parseStatPPID returns the parent PID from /proc/[pid]/stat. The command
+// name may contain spaces and parentheses, so fields are counted from the
+// last ')'.
+func parseStatPPID(stat []byte) (int, error) {
+	i := bytes.LastIndexByte(stat, ')')
+	if i < 0 {
+		return 0, fmt.Errorf("malformed stat")
+	}
+	fields := bytes.Fields(stat[i+1:])
+	if len(fields) < 2 {
+		return 0, fmt.Errorf("malformed stat")
+	}
+	return strconv.Atoi(string(fields[1]))
+}
+
+// parseEnviron splits the NUL-separated contents of /proc/[pid]/environ.
+func parseEnviron(b []byte) []string {
+	var env []string
+	for kv := range bytes.SplitSeq(b, []byte{0}) {
+		if len(kv) > 0 {
+			env = append(env, string(kv))
+		}
+	}
+	return env
+}
@@ -0,0 +1,79 @@
+//go:build linux
+
+package sysinfo
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestReadProcessDetail(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "4242")
+	if err := os.MkdirAll(filepath.Join(dir, "fd"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"stat":    "4242 (my (odd) proc) S 17 4242 4242 0 -1 4194560 100 0 0 0 5 3 0 0 20 0 1 0 1000 0 0\n",
+		"environ": "PATH=/usr/bin\x00DB_PASSWORD=hunter2\x00HOME=/root\x00",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, fd := range []string{"0", "1", "2"} {
+		if err := os.Symlink("/dev/null", filepath.Join(dir, "fd", fd)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink("/srv/app", filepath.Join(dir, "cwd")); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := readProcessDetail(root, 4242)
+	if err != nil {
+		t.Fatalf("readProcessDetail() error = %v", err)
+	}
+	got.Environ = redactEnv(got.Environ)
+
+	want := &ProcessDetail{
+		PID:       4242,
+		PPID:      17,
+		Cwd:       "/srv/app",
+		OpenFiles: 3,
+		Environ:   []string{"PATH=/usr/bin", "DB_PASSWORD=" + redactedValue, "HOME=/root"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("readProcessDetail() = %+v, want %+v", got, want)
+	}
+}
+
+func TestReadProcessDetail_Unreadable(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "7")
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "stat"), []byte("7 (kthread) S 2 0 0 0\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := readProcessDetail(root, 7)
+	if err != nil {
+		t.Fatalf("readProcessDetail() error = %v", err)
+	}
+	if got.PPID != 2 || got.Cwd != "" || got.OpenFiles != 0 || got.Environ != nil {
+		t.Errorf("readProcessDetail() = %+v, want only PID and PPID", got)
+	}
+}
+
+func TestReadProcessDetail_NotFound(t *testing.T) {
+	_, err := readProcessDetail(t.TempDir(), 99999)
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("readProcessDetail() error = %v, want ErrNotExist", err)
+	}
+}
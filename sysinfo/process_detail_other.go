@@ -0,0 +1,33 @@
+//go:build !darwin && !linux
+
+package sysinfo
+
+import (
+	"context"
+
+	"github.com/shirou/gopsutil/v4/process"
+)
+
+// processDetail reads a process's details through gopsutil.
+func processDetail(pid int) (*ProcessDetail, error) {
+	ctx := context.Background()
+	p, err := process.NewProcessWithContext(ctx, int32(pid))
+	if err != nil {
+		return nil, err
+	}
+
+	d := &ProcessDetail{PID: pid}
+	if ppid, err := p.PpidWithContext(ctx); err == nil {
+		d.PPID = int(ppid)
+	}
+	if cwd, err := p.CwdWithContext(ctx); err == nil {
+		d.Cwd = cwd
+	}
+	if fds, err := p.NumFDsWithContext(ctx); err == nil {
+		d.OpenFiles = int(fds)
+	}
+	if env, err := p.EnvironWithContext(ctx); err == nil {
+		d.Environ = env
+	}
+	return d, nil
+}
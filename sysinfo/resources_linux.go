@@ -0,0 +1,56 @@
+//go:build linux
+
+package sysinfo
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/shirou/gopsutil/v4/net"
+)
+
+// Resources returns system-wide open file and TCP connection counts.
+func (c *Collector) Resources() (*ResourceStats, error) {
+	b, err := os.ReadFile("/proc/sys/fs/file-nr")
+	if err != nil {
+		return nil, err
+	}
+	open, maxFiles, err := parseFileNr(b)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &ResourceStats{OpenFiles: open, MaxFiles: maxFiles}
+
+	conns, err := net.Connections("tcp")
+	if err != nil {
+		return nil, fmt.Errorf("list tcp connections: %w", err)
+	}
+	for _, conn := range conns {
+		if conn.Status == "ESTABLISHED" {
+			res.TCPEstablished++
+		}
+	}
+	return res, nil
+}
+
+// parseFileNr parses /proc/sys/fs/file-nr, which holds the number of
+// allocated file handles, allocated-but-unused handles and the maximum.
+func parseFileNr(b []byte) (open, maxFiles uint64, err error) {
+	fields := strings.Fields(string(b))
+	if len(fields) != 3 {
+		return 0, 0, fmt.Errorf("unexpected file-nr format: %q", b)
+	}
+
+	var vals [3]uint64
+	for i, f := range fields {
+		if vals[i], err = strconv.ParseUint(f, 10, 64); err != nil {
+			return 0, 0, fmt.Errorf("parse file-nr: %w", err)
+		}
+	}
+	// Kernels since 2.6 always report 0 unused handles, but older ones
+	// count freed handles as allocated.
+	return vals[0] - vals[1], vals[2], nil
+}
@@ -0,0 +1,32 @@
+//go:build linux
+
+package sysinfo
+
+import "testing"
+
+func TestParseFileNr(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		wantOpen uint64
+		wantMax  uint64
+		wantErr  bool
+	}{
+		{"modern", "3456\t0\t9223372036854775807\n", 3456, 9223372036854775807, false},
+		{"unused_handles", "2048\t512\t100000\n", 1536, 100000, false},
+		{"too_few_fields", "2048 0\n", 0, 0, true},
+		{"not_a_number", "a b c\n", 0, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			open, maxFiles, err := parseFileNr([]byte(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseFileNr() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if open != tt.wantOpen || maxFiles != tt.wantMax {
+				t.Errorf("parseFileNr() = %d, %d, want %d, %d", open, maxFiles, tt.wantOpen, tt.wantMax)
+			}
+		})
+	}
+}
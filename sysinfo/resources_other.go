@@ -0,0 +1,9 @@
+//go:build !linux
+
+package sysinfo
+
+// Resources returns zero values; file handle and connection counts are
+// only collected on Linux.
+func (c *Collector) Resources() (*ResourceStats, error) {
+	return &ResourceStats{}, nil
+}
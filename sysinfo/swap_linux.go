@@ -0,0 +1,57 @@
+//go:build linux
+
+package sysinfo
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// SwapDevices returns the active swap areas listed in /proc/swaps.
+func SwapDevices() ([]SwapDevice, error) {
+	b, err := os.ReadFile("/proc/swaps")
+	if err != nil {
+		return nil, err
+	}
+	return parseSwaps(b)
+}
+
+// parseSwaps parses /proc/swaps. After a header line, each line holds the
+// filename, type, size and used space in KiB, and the priority.
+func parseSwaps(b []byte) ([]SwapDevice, error) {
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	devices := make([]SwapDevice, 0, len(lines))
+	for _, line := range lines[1:] {
+		fields := strings.Fields(line)
+		if len(fields) != 5 {
+			return nil, fmt.Errorf("unexpected swaps line: %q", line)
+		}
+		size, err := strconv.ParseUint(fields[2], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parse swap size: %w", err)
+		}
+		used, err := strconv.ParseUint(fields[3], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parse swap used: %w", err)
+		}
+		prio, err := strconv.Atoi(fields[4])
+		if err != nil {
+			return nil, fmt.Errorf("parse swap priority: %w", err)
+		}
+
+		// The kernel escapes spaces in paths as \040.
+		device := strings.ReplaceAll(fields[0], `\040`, " ")
+		devices = append(devices, SwapDevice{
+			Device:   device,
+			Type:     fields[1],
+			Size:     size * 1024,
+			Used:     used * 1024,
+			Priority: prio,
+			Zram:     strings.HasPrefix(filepath.Base(device), "zram"),
+		})
+	}
+	return devices, nil
+}
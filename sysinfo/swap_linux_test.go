@@ -0,0 +1,42 @@
+//go:build linux
+
+package sysinfo
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseSwaps(t *testing.T) {
+	input := "Filename\t\t\t\tType\t\tSize\t\tUsed\t\tPriority\n" +
+		"/dev/sda2                               partition\t8388604\t\t524288\t\t-2\n" +
+		"/dev/zram0                              partition\t4194300\t\t1024\t\t100\n"
+
+	got, err := parseSwaps([]byte(input))
+	if err != nil {
+		t.Fatalf("parseSwaps() error = %v", err)
+	}
+	want := []SwapDevice{
+		{Device: "/dev/sda2", Type: "partition", Size: 8388604 * 1024, Used: 524288 * 1024, Priority: -2},
+		{Device: "/dev/zram0", Type: "partition", Size: 4194300 * 1024, Used: 1024 * 1024, Priority: 100, Zram: true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseSwaps() = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseSwaps_Empty(t *testing.T) {
+	got, err := parseSwaps([]byte("Filename\t\t\t\tType\t\tSize\t\tUsed\t\tPriority\n"))
+	if err != nil {
+		t.Fatalf("parseSwaps() error = %v", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("parseSwaps() = %+v, want empty", got)
+	}
+}
+
+func TestParseSwaps_Malformed(t *testing.T) {
+	if _, err := parseSwaps([]byte("Filename Type Size Used Priority\n/dev/sda2 partition x 0 -2\n")); err == nil {
+		t.Error("parseSwaps() error = nil, want error for non-numeric size")
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !linux
+
+package sysinfo
+
+// SwapDevices returns no devices; per-device swap detail is only
+// collected on Linux.
+func SwapDevices() ([]SwapDevice, error) {
+	return []SwapDevice{}, nil
+}
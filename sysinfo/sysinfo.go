@@ -3,20 +3,23 @@ package sysinfo
 
 // Stats represents real-time system statistics.
 type Stats struct {
-	CPU     CPUStats   `json:"cpu"`
-	Memory  MemStats   `json:"memory"`
-	Network []NetStats `json:"network"`
-	DiskIO  []DiskIO   `json:"disk_io"`
-	Uptime  uint64     `json:"uptime"` // System uptime in seconds
+	CPU      CPUStats   `json:"cpu"`
+	Memory   MemStats   `json:"memory"`
+	Network  []NetStats `json:"network"`
+	DiskIO   []DiskIO   `json:"disk_io"`
+	Uptime   uint64     `json:"uptime"`    // System uptime in seconds
+	BootTime int64      `json:"boot_time"` // Boot time as a Unix timestamp
+	LoadAvg  [3]float64 `json:"load_avg"`  // Load average over 1, 5 and 15 minutes
 }
 
 // CPUStats represents CPU usage statistics.
 type CPUStats struct {
-	Cores       []float64 `json:"cores"`       // Per-core usage percentage (0-100)
-	Total       float64   `json:"total"`       // Aggregate usage percentage (0-100)
-	Temperature float64   `json:"temperature"` // CPU temperature in Celsius (0 if unavailable)
-	Frequency   float64   `json:"frequency"`   // Current frequency in MHz
-	CoreCount   int       `json:"core_count"`  // Number of logical cores
+	Cores       []float64 `json:"cores"`         // Per-core usage percentage (0-100)
+	Total       float64   `json:"total"`         // Aggregate usage percentage (0-100)
+	Temperature float64   `json:"temperature"`   // CPU temperature in Celsius (0 if unavailable)
+	Frequency   float64   `json:"frequency"`     // Current frequency in MHz
+	PerCoreFreq []float64 `json:"per_core_freq"` // Per-core frequency in MHz (Linux only)
+	CoreCount   int       `json:"core_count"`    // Number of logical cores
 }
 
 // MemStats represents memory usage statistics.
@@ -29,6 +32,18 @@ type MemStats struct {
 	SwapTotal uint64  `json:"swap_total"` // Total swap in bytes
 	SwapUsed  uint64  `json:"swap_used"`  // Used swap in bytes
 	Percent   float64 `json:"percent"`    // Memory usage percentage (0-100)
+
+	SwapDevices []SwapDevice `json:"swap_devices"` // Active swap areas (Linux only)
+}
+
+// SwapDevice represents an active swap area from /proc/swaps.
+type SwapDevice struct {
+	Device   string `json:"device"`   // Device or file path (e.g., "/dev/zram0")
+	Type     string `json:"type"`     // "partition" or "file"
+	Size     uint64 `json:"size"`     // Size in bytes
+	Used     uint64 `json:"used"`     // Used space in bytes
+	Priority int    `json:"priority"` // Swap priority; higher is used first
+	Zram     bool   `json:"zram"`     // Whether the device is compressed RAM
 }
 
 // NetStats represents network interface statistics.
@@ -40,6 +55,10 @@ type NetStats struct {
 	SpeedOut  float64 `json:"speed_out"`  // Current transmit rate (bytes/sec)
 	LinkSpeed uint64  `json:"link_speed"` // Link speed in Mbps (0 if unavailable)
 	IsUp      bool    `json:"is_up"`      // Whether interface is up
+	RxErrors  uint64  `json:"rx_errors"`  // Total receive errors
+	TxErrors  uint64  `json:"tx_errors"`  // Total transmit errors
+	RxDropped uint64  `json:"rx_dropped"` // Total received packets dropped
+	TxDropped uint64  `json:"tx_dropped"` // Total transmitted packets dropped
 }
 
 // DiskIO represents disk I/O statistics.
@@ -64,3 +83,19 @@ type Process struct {
 	StartTime  int64   `json:"start_time"` // Unix timestamp
 	Threads    int     `json:"threads"`    // Number of threads
 }
+
+// ProcessDetail holds debugging information for a single process.
+type ProcessDetail struct {
+	PID       int      `json:"pid"`
+	PPID      int      `json:"ppid"`
+	Cwd       string   `json:"cwd"`
+	OpenFiles int      `json:"open_files"`
+	Environ   []string `json:"environ"` // KEY=value, with likely secrets redacted
+}
+
+// ResourceStats represents system-wide kernel resource usage.
+type ResourceStats struct {
+	OpenFiles      uint64 `json:"open_files"`      // Allocated file handles
+	MaxFiles       uint64 `json:"max_files"`       // System-wide file handle limit
+	TCPEstablished int    `json:"tcp_established"` // Established TCP connections
+}
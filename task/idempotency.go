@@ -0,0 +1,123 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.aimuz.me/mynt/logger"
+)
+
+// DefaultIdempotencyWindow is how long an idempotency key keeps returning
+// the task it first submitted.
+const DefaultIdempotencyWindow = 10 * time.Minute
+
+// ErrIdempotencyKeyReused is returned when an idempotency key is repeated
+// for a task with a different name.
+var ErrIdempotencyKeyReused = errors.New("idempotency key was used for a different task")
+
+// keyEntry is an in-memory idempotency key, used when there is no db.
+type keyEntry struct {
+	taskID string
+	at     time.Time
+}
+
+// WithIdempotencyWindow sets how long idempotency keys are honoured.
+func WithIdempotencyWindow(d time.Duration) Option {
+	return func(m *Manager) {
+		m.keyWindow = d
+	}
+}
+
+// SubmitIdempotent is like Submit, but if key was used within the
+// idempotency window it returns the task that key submitted instead of
+// starting a new one, reporting true. An empty key always submits.
+// Without persistence a key is only remembered while its task runs.
+func (m *Manager) SubmitIdempotent(key, name string, fn func(ctx context.Context, update func(progress int)) (interface{}, error)) (*Operation, bool, error) {
+	if key == "" {
+		op, err := m.Submit(name, fn)
+		return op, false, err
+	}
+
+	m.keyMu.Lock()
+	defer m.keyMu.Unlock()
+
+	now := time.Now()
+	if op, err := m.replay(key, name, now); op != nil || err != nil {
+		return op, op != nil, err
+	}
+
+	op, err := m.Submit(name, fn)
+	if err != nil {
+		return nil, false, err
+	}
+	// The task is already running, so a failure to record the key only
+	// loses deduplication for later repeats.
+	if err := m.saveKey(key, op.ID, now); err != nil {
+		logger.Warn("failed to save idempotency key", "task", op.ID, "error", err)
+	}
+	return op, false, nil
+}
+
+// Replay returns the task key submitted within the idempotency window, or
+// nil if there is none. Handlers use it to answer a repeated request before
+// validating it again, since the first task may already have changed what
+// validation sees.
+func (m *Manager) Replay(key, name string) (*Operation, error) {
+	if key == "" {
+		return nil, nil
+	}
+	m.keyMu.Lock()
+	defer m.keyMu.Unlock()
+	return m.replay(key, name, time.Now())
+}
+
+// replay looks up key as of now. The caller must hold m.keyMu.
+func (m *Manager) replay(key, name string, now time.Time) (*Operation, error) {
+	id := m.lookupKey(key, now.Add(-m.keyWindow))
+	if id == "" {
+		return nil, nil
+	}
+	op, ok := m.Get(id)
+	if !ok {
+		return nil, nil
+	}
+	if op.Name != name {
+		return nil, fmt.Errorf("%w: %s", ErrIdempotencyKeyReused, key)
+	}
+	return op, nil
+}
+
+// lookupKey returns the task submitted with key at or after since.
+func (m *Manager) lookupKey(key string, since time.Time) string {
+	if m.db != nil {
+		id, err := m.db.IdempotencyKey(key, since)
+		if err != nil {
+			logger.Warn("failed to look up idempotency key", "error", err)
+		}
+		return id
+	}
+	if e, ok := m.keys[key]; ok && !e.at.Before(since) {
+		return e.taskID
+	}
+	return ""
+}
+
+// saveKey records that key submitted taskID. Keys past the window are
+// dropped on the way.
+func (m *Manager) saveKey(key, taskID string, at time.Time) error {
+	if m.db != nil {
+		if err := m.db.PruneIdempotencyKeys(at.Add(-m.keyWindow)); err != nil {
+			logger.Warn("failed to prune idempotency keys", "error", err)
+		}
+		return m.db.SaveIdempotencyKey(key, taskID, at)
+	}
+	for k, e := range m.keys {
+		if at.Sub(e.at) > m.keyWindow {
+			delete(m.keys, k)
+		}
+	}
+	m.keys[key] = keyEntry{taskID: taskID, at: at}
+	return nil
+}
@@ -7,14 +7,25 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"go.aimuz.me/mynt/event"
 )
 
+// progressEventInterval is the minimum time between two task.progress events
+// for the same operation.
+const progressEventInterval = time.Second
+
 // Persistence defines how tasks are saved.
 type Persistence interface {
 	Save(op *Operation) error
 	Update(op *Operation) error
 	List(limit, offset int) ([]*Operation, error)
 	Get(id string) (*Operation, error)
+
+	// SaveIdempotencyKey, IdempotencyKey and PruneIdempotencyKeys map
+	// idempotency keys to the task they submitted; see SubmitIdempotent.
+	SaveIdempotencyKey(key, taskID string, at time.Time) error
+	IdempotencyKey(key string, since time.Time) (string, error)
+	PruneIdempotencyKeys(before time.Time) error
 }
 
 // State represents the current status of a long-running operation.
@@ -40,7 +51,8 @@ type Operation struct {
 	CreatedAt time.Time   `json:"created_at"`
 	UpdatedAt time.Time   `json:"updated_at"`
 
-	cancelFn context.CancelFunc
+	cancelFn   context.CancelFunc
+	progressAt time.Time // last time a progress event was published
 }
 
 // Manager handles the lifecycle of operations.
@@ -48,14 +60,36 @@ type Manager struct {
 	mu    sync.RWMutex
 	tasks map[string]*Operation
 	db    Persistence // Optional persistence layer
+	bus   *event.Bus  // Optional event bus for lifecycle events
 	wg    sync.WaitGroup
+
+	// keyMu serializes SubmitIdempotent so a repeated key cannot race its
+	// first use. keys holds the mapping when there is no db.
+	keyMu     sync.Mutex
+	keys      map[string]keyEntry
+	keyWindow time.Duration
+}
+
+// Option configures a Manager.
+type Option func(*Manager)
+
+// WithEventBus publishes task lifecycle events to bus.
+func WithEventBus(bus *event.Bus) Option {
+	return func(m *Manager) {
+		m.bus = bus
+	}
 }
 
 // NewManager creates a new task manager.
-func NewManager(db Persistence) (*Manager, error) {
+func NewManager(db Persistence, opts ...Option) (*Manager, error) {
 	m := &Manager{
-		tasks: make(map[string]*Operation),
-		db:    db,
+		tasks:     make(map[string]*Operation),
+		db:        db,
+		keys:      make(map[string]keyEntry),
+		keyWindow: DefaultIdempotencyWindow,
+	}
+	for _, opt := range opts {
+		opt(m)
 	}
 
 	if db != nil {
@@ -68,8 +102,8 @@ func NewManager(db Persistence) (*Manager, error) {
 }
 
 // New is an alias for NewManager for more idiomatic usage.
-func New(db Persistence) (*Manager, error) {
-	return NewManager(db)
+func New(db Persistence, opts ...Option) (*Manager, error) {
+	return NewManager(db, opts...)
 }
 
 // recover marks any previously RUNNING or PENDING tasks as FAILED,
@@ -249,7 +283,10 @@ func (m *Manager) updateState(id string, state State, progress int, result inter
 	if m.db != nil {
 		_ = m.db.Update(op)
 	}
+	clone := *op
 	m.mu.Unlock()
+
+	m.publish(stateEventType(state), &clone)
 }
 
 func (m *Manager) updateProgress(id string, progress int) {
@@ -265,7 +302,43 @@ func (m *Manager) updateProgress(id string, progress int) {
 	if m.db != nil {
 		_ = m.db.Update(op)
 	}
+
+	// Debounce progress events so chatty tasks don't flood subscribers.
+	var clone *Operation
+	if op.UpdatedAt.Sub(op.progressAt) >= progressEventInterval {
+		op.progressAt = op.UpdatedAt
+		c := *op
+		clone = &c
+	}
 	m.mu.Unlock()
+
+	if clone != nil {
+		m.publish(event.TaskProgress, clone)
+	}
+}
+
+// publish sends a task event to the bus, if one is configured.
+func (m *Manager) publish(typ string, op *Operation) {
+	if m.bus == nil || typ == "" {
+		return
+	}
+	m.bus.Publish(event.Event{Type: typ, Data: op})
+}
+
+// stateEventType maps a task state to its lifecycle event type.
+func stateEventType(state State) string {
+	switch state {
+	case StateRunning:
+		return event.TaskStarted
+	case StateDone:
+		return event.TaskCompleted
+	case StateFailed:
+		return event.TaskFailed
+	case StateCancelled:
+		return event.TaskCancelled
+	default:
+		return ""
+	}
 }
 
 func (m *Manager) Close() {
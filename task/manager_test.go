@@ -0,0 +1,121 @@
+package task
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.aimuz.me/mynt/event"
+)
+
+func TestManager_PublishesCompleted(t *testing.T) {
+	bus := event.NewBus()
+	ch := bus.Subscribe("task.*")
+	defer bus.Unsubscribe("task.*", ch)
+
+	m, err := New(nil, WithEventBus(bus))
+	require.NoError(t, err)
+
+	op, err := m.Submit("test", func(ctx context.Context, update func(int)) (interface{}, error) {
+		update(50)
+		return "ok", nil
+	})
+	require.NoError(t, err)
+	m.Close()
+
+	var types []string
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case evt := <-ch:
+			got, ok := evt.Data.(*Operation)
+			require.True(t, ok)
+			require.Equal(t, op.ID, got.ID)
+			types = append(types, evt.Type)
+			if evt.Type == event.TaskCompleted {
+				require.Equal(t, StateDone, got.State)
+				require.Equal(t, []string{event.TaskStarted, event.TaskProgress, event.TaskCompleted}, types)
+				return
+			}
+		case <-timeout:
+			t.Fatalf("task.completed not received, got %v", types)
+		}
+	}
+}
+
+func TestManager_DebouncesProgress(t *testing.T) {
+	bus := event.NewBus()
+	ch := bus.Subscribe(event.TaskProgress)
+	defer bus.Unsubscribe(event.TaskProgress, ch)
+
+	m, err := New(nil, WithEventBus(bus))
+	require.NoError(t, err)
+
+	_, err = m.Submit("test", func(ctx context.Context, update func(int)) (interface{}, error) {
+		for i := 1; i <= 5; i++ {
+			update(i * 10)
+		}
+		return nil, nil
+	})
+	require.NoError(t, err)
+	m.Close()
+
+	require.Len(t, ch, 1)
+}
+
+func TestManager_SubmitIdempotent(t *testing.T) {
+	m, err := New(nil)
+	require.NoError(t, err)
+
+	var runs atomic.Int32
+	release := make(chan struct{})
+	fn := func(ctx context.Context, update func(int)) (interface{}, error) {
+		runs.Add(1)
+		<-release
+		return nil, nil
+	}
+
+	first, existing, err := m.SubmitIdempotent("key-1", "scrub tank", fn)
+	require.NoError(t, err)
+	require.False(t, existing)
+
+	second, existing, err := m.SubmitIdempotent("key-1", "scrub tank", fn)
+	require.NoError(t, err)
+	require.True(t, existing)
+	require.Equal(t, first.ID, second.ID)
+
+	_, _, err = m.SubmitIdempotent("key-1", "scrub other", fn)
+	require.ErrorIs(t, err, ErrIdempotencyKeyReused)
+
+	other, existing, err := m.SubmitIdempotent("key-2", "scrub tank", fn)
+	require.NoError(t, err)
+	require.False(t, existing)
+	require.NotEqual(t, first.ID, other.ID)
+
+	close(release)
+	m.Close()
+	require.Equal(t, int32(2), runs.Load())
+}
+
+func TestManager_SubmitIdempotent_WindowExpired(t *testing.T) {
+	m, err := New(nil, WithIdempotencyWindow(time.Nanosecond))
+	require.NoError(t, err)
+
+	release := make(chan struct{})
+	fn := func(ctx context.Context, update func(int)) (interface{}, error) {
+		<-release
+		return nil, nil
+	}
+	first, _, err := m.SubmitIdempotent("key", "test", fn)
+	require.NoError(t, err)
+	time.Sleep(time.Millisecond)
+	second, existing, err := m.SubmitIdempotent("key", "test", fn)
+	require.NoError(t, err)
+	require.False(t, existing)
+	require.NotEqual(t, first.ID, second.ID)
+
+	close(release)
+	m.Close()
+}
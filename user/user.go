@@ -157,6 +157,29 @@ func (m *Manager) VerifyPassword(username, password string) (*store.User, error)
 	return user, nil
 }
 
+// MissingSystemUsers returns the system accounts in the database that do
+// not exist on the host, e.g. after an OS reinstall.
+func (m *Manager) MissingSystemUsers(ctx context.Context) ([]string, error) {
+	if runtime.GOOS == "darwin" {
+		return nil, nil
+	}
+
+	users, err := m.repo.List()
+	if err != nil {
+		return nil, err
+	}
+	var missing []string
+	for _, u := range users {
+		if u.AccountType != store.AccountSystem {
+			continue
+		}
+		if _, err := m.exec.Output(ctx, "id", "-u", u.Username); err != nil {
+			missing = append(missing, u.Username)
+		}
+	}
+	return missing, nil
+}
+
 func (m *Manager) createSystemUser(user *store.User, password string) error {
 	if runtime.GOOS == "darwin" {
 		return nil
@@ -1,6 +1,9 @@
 package user
 
 import (
+	"context"
+	"errors"
+	"runtime"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -271,3 +274,25 @@ func TestMockExecutorVerification(t *testing.T) {
 	// On macOS, commands may be empty because system user creation is skipped
 	t.Logf("Recorded %d commands", len(cmds))
 }
+
+func TestMissingSystemUsers(t *testing.T) {
+	if runtime.GOOS == "darwin" {
+		t.Skip("system accounts are not managed on darwin")
+	}
+	db, err := store.Open(":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := store.NewUserRepo(db)
+	require.NoError(t, repo.Save(&store.User{Username: "alice", AccountType: store.AccountSystem, IsActive: true}))
+	require.NoError(t, repo.Save(&store.User{Username: "guest", AccountType: store.AccountVirtual, IsActive: true}))
+
+	mock := sysexec.NewMock()
+	mock.SetError("id", errors.New("id: 'alice': no such user"))
+	mgr := NewManager(repo)
+	mgr.SetExecutor(mock)
+
+	missing, err := mgr.MissingSystemUsers(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []string{"alice"}, missing)
+}
@@ -30,10 +30,42 @@ interface Pool {
     vdevs?: VDevDetail[];
     scrub_status?: ScrubStatus;
     resilver_status?: ResilverStatus;
+    readonly?: boolean;
+    autotrim?: boolean;
+    spares?: DiskDetail[];
+}
+
+interface TrimStatus {
+    in_progress: boolean;
+    percent_done: number; // average over devices that support trim
+    devices: {
+        name: string;
+        state: 'trimming' | 'suspended' | 'completed' | 'untrimmed' | 'unsupported';
+        percent_done: number;
+        time?: number; // Unix time the trim started, or completed
+    }[];
+}
+
+interface ImportablePool {
+    name: string;
+    guid: string;
+    state: string;
+    status?: string;
+    action?: string;
+    missing_devices?: { name: string; state: string; class?: string }[];
+    needs_force?: boolean; // last used by another system
+}
+
+interface ImportOptions {
+    force?: boolean;
+    missing_log?: boolean;
+    degraded?: boolean;
+    readonly?: boolean;
 }
 
 interface ScrubStatus {
     in_progress: boolean;
+    start_time?: number;
     end_time?: string;
     errors: number;
     data_scanned: number;
@@ -41,6 +73,21 @@ interface ScrubStatus {
     scan_rate: number;
 }
 
+interface PoolCapacitySample {
+    allocated: number;
+    free: number;
+    time: string;
+}
+
+interface PoolScanStatus {
+    pool: string;
+    kind?: 'scrub' | 'resilver';
+    in_progress: boolean;
+    started_at?: string;
+    scrub?: ScrubStatus;
+    resilver?: ResilverStatus;
+}
+
 interface VDevDetail {
     name: string;
     type: string;
@@ -57,6 +104,8 @@ interface DiskDetail {
     write: number;
     checksum: number;
     replacing: boolean;
+    spare?: boolean;
+    in_use?: boolean;   // hot spare standing in for a failed disk
 }
 
 interface ResilverStatus {
@@ -85,9 +134,27 @@ interface StorageSpace {
     available: number;
     referenced: number;
     quota?: number;
+    refquota?: number;
     reservation?: number;
+    refreservation?: number;
+    compressratio?: number; // e.g. 1.45 means data takes 1/1.45 of its logical size
+    logicalused?: number;
+    written?: number; // bytes written since the latest snapshot
+    origin?: string; // snapshot this clone was created from
     mountpoint?: string;
     compression?: string;
+    note?: string;
+    volmode?: string; // volumes only
+    pool_autotrim?: boolean; // volumes only: whether discards reach the pool's SSDs
+    shared?: boolean;
+    shares?: number[]; // IDs of the shares exporting this dataset
+}
+
+interface DiffEntry {
+    change: 'added' | 'removed' | 'modified' | 'renamed';
+    file_type: string;
+    path: string;
+    new_path?: string; // renames only
 }
 
 interface Snapshot {
@@ -99,26 +166,104 @@ interface Snapshot {
     source: string; // "manual", "policy:daily", etc.
 }
 
+// A policy dataset; a bare name when it has no retention override.
+type PolicyTarget = string | { dataset: string; keep_last?: number };
+
 interface SnapshotPolicy {
     id: number;
     name: string;
     schedule: string;
     retention: string;
-    datasets: string[];
+    datasets: PolicyTarget[];
+    recursive?: boolean;
+    exclude?: string[];
     enabled: boolean;
+    // Count-based retention; when any is set it replaces retention
+    keep_last?: number;
+    keep_daily?: number;
+    keep_weekly?: number;
+    keep_monthly?: number;
     created_at: string;
     updated_at: string;
 }
 
+interface SmartTestPolicy {
+    id: number;
+    name: string;
+    schedule: string;
+    test_type: 'short' | 'long';
+    disks: string[]; // empty for all attached disks
+    enabled: boolean;
+    last_run_at?: string;
+    created_at: string;
+    updated_at: string;
+}
+
+type SmartTestPolicyInput = Omit<SmartTestPolicy, 'id' | 'last_run_at' | 'created_at' | 'updated_at'>;
+
+interface PropDiff {
+    current: string;
+    expected: string;
+}
+
+interface ACLEntry {
+    type: 'user' | 'group' | 'mask' | 'other';
+    qualifier?: string;  // user or group name; empty for the owner entries
+    permissions: string; // e.g. "rwx", "r-x"
+    default?: boolean;
+}
+
 interface CreateDatasetRequest {
     name: string;
     type?: string;
     use_case?: string;
     quota_mode?: string;
     quota?: number;  // size/quota in bytes (required for volumes, optional for filesystems)
+    sparse?: boolean; // volumes only: thin-provisioned, no refreservation
+    mountpoint?: string; // filesystems only: absolute path, "legacy" or "none"
+    canmount?: 'on' | 'off' | 'noauto'; // filesystems only
+    refquota?: number; // filesystems only: excludes snapshots and descendants
+    refreservation?: number; // filesystems only
     properties?: Record<string, string>;
 }
 
+interface DatasetTemplate {
+    name: string;
+    properties: Record<string, string>;
+    builtin: boolean;
+}
+
+interface ZFSExecResult {
+    stdout: string;
+    stderr: string;   // only captured when the command fails
+    exit_code: number;
+}
+
+interface CompressionOptions {
+    version?: string; // OpenZFS version, absent if unknown
+    algorithms: string[];
+}
+
+interface PoolProperties {
+    properties: Record<string, string>; // property name -> value ("-" when unset)
+    settable: string[];
+}
+
+interface ARCStats {
+    size: number;
+    target_size: number;
+    min_size: number;
+    max_size: number;
+    hits: number; // since the zfs module was loaded
+    misses: number;
+    hit_ratio: number; // percent
+}
+
+interface ZFSParams {
+    params: Record<string, string>; // module parameter name -> value
+    writable: string[];
+}
+
 interface UsageInfo {
     type: string;
     params?: Record<string, string>;
@@ -138,6 +283,8 @@ interface Disk {
     status: string;          // "healthy", "warning", "failed", "unknown"
     smart_health: string;    // "good", "warning", "failed", "unknown"
     temperature?: number;
+    read_only: boolean;      // write-protected, e.g. by the kernel after errors
+    health_reasons?: string[]; // why the status is not healthy
 }
 
 interface SmartAttribute {
@@ -148,6 +295,8 @@ interface SmartAttribute {
     thresh: number;
     raw: string;
     status: string;
+    critical: boolean;       // Failing or soon-failing media
+    interpretation?: string; // Plain-language meaning
 }
 
 interface DetailedSmartReport {
@@ -160,9 +309,25 @@ interface DetailedSmartReport {
     pending_sectors: number;
     uncorrectable_errors: number;
     temperature: number;
+    percentage_used?: number; // NVMe: share of rated endurance used, may exceed 100
+    wear_leveling_count?: number; // SATA SSD: normalized remaining life, 100 is new
+    data_units_written?: number; // NVMe, units of 512,000 bytes
+    total_lbas_written?: number; // SATA
+    wear_warning: boolean;
+    available_spare?: number; // NVMe, percent
+    available_spare_threshold?: number; // NVMe
+    media_errors?: number; // NVMe
+    critical_warning?: number; // NVMe warning bitmask, 0 when healthy
     checked_at: string;
 }
 
+interface SmartCheck {
+    readable: boolean;
+    device_type?: string;
+    needs_override: boolean;
+    message: string;
+}
+
 interface SmartTestStatus {
     running: boolean;
     type?: string;
@@ -171,6 +336,17 @@ interface SmartTestStatus {
     last_result?: string;
 }
 
+interface DiskBatchParams {
+    type?: 'short' | 'long';  // smart_test
+    timeout?: number;         // standby, minutes (0 disables)
+    action?: 'on' | 'off';    // locate
+}
+
+interface DiskBatchResult {
+    disk: string;
+    error?: string;
+}
+
 interface Share {
     id: number;
     name: string;
@@ -182,6 +358,21 @@ interface Share {
     valid_users: string;
     comment: string;
     share_type: 'normal' | 'public' | 'restricted';
+    dataset?: string; // owning ZFS dataset, empty if the path is outside any dataset
+    audit_log?: boolean; // log file access with Samba vfs_full_audit
+    create_mask?: string; // octal, e.g. "0664"; empty uses the share type default
+    directory_mask?: string; // octal, e.g. "0775"; empty uses the share type default
+}
+
+interface TaskOperation {
+    id: string;
+    name: string;
+    state: 'PENDING' | 'RUNNING' | 'DONE' | 'FAILED' | 'CANCELLED';
+    progress: number;
+    result?: unknown;
+    error?: string;
+    created_at: string;
+    updated_at: string;
 }
 
 interface Notification {
@@ -194,6 +385,57 @@ interface Notification {
     acked_at?: string;
 }
 
+interface NotificationChannel {
+    id: number;
+    name: string;
+    type: 'email' | 'webhook' | 'log';
+    target: string; // email address or webhook URL; unused for log
+    min_severity: 'info' | 'warning' | 'critical';
+    event_types: string[]; // patterns such as "pool.*"; empty matches all
+    enabled: boolean;
+    created_at: string;
+    updated_at: string;
+}
+
+type NotificationChannelInput = Omit<NotificationChannel, 'id' | 'created_at' | 'updated_at'>;
+
+interface Webhook {
+    id: number;
+    name: string;
+    url: string;
+    secret?: string; // HMAC-SHA256 key; only returned by createWebhook
+    min_severity: 'info' | 'warning' | 'critical';
+    event_types: string[]; // patterns such as "pool.*"; empty matches all
+    enabled: boolean;
+    last_status: number; // HTTP status of the last delivery; 0 if none or unreachable
+    last_error: string;
+    last_delivery_at?: string;
+    created_at: string;
+    updated_at: string;
+}
+
+// An empty secret generates one on create and keeps the current one on update
+type WebhookInput = Pick<Webhook, 'name' | 'url' | 'secret' | 'min_severity' | 'event_types' | 'enabled'>;
+
+interface WebhookDelivery {
+    id: number;
+    webhook_id: number;
+    event_type: string;
+    status: number; // HTTP status of the last attempt; 0 if unreachable
+    error: string;
+    attempts: number;
+    created_at: string;
+}
+
+// An outstanding problem computed from current state; it clears once resolved.
+interface Alert {
+    kind: 'pool' | 'disk' | 'dataset';
+    resource: string;
+    severity: 'info' | 'warning' | 'critical';
+    message: string;
+    action: string;
+}
+
 class ApiClient {
     private token: string | null = null;
 
@@ -221,6 +463,15 @@ class ApiClient {
             headers,
         });
 
+        // Sliding expiration: the server renews tokens that are about to expire
+        const refreshed = response.headers.get('X-Refresh-Token');
+        if (refreshed) {
+            this.token = refreshed;
+            if (typeof window !== 'undefined') {
+                localStorage.setItem('auth_token', refreshed);
+            }
+        }
+
         if (!response.ok) {
             const error = await response.text();
             throw new Error(error || response.statusText);
@@ -231,6 +482,9 @@ class ApiClient {
         if (contentType?.includes('application/json')) {
             return response.json();
         }
+        if (contentType?.includes('text/plain')) {
+            return response.text() as Promise<T>;
+        }
 
         return undefined as T;
     }
@@ -289,6 +543,10 @@ class ApiClient {
         return this.request('/disks');
     }
 
+    async listAvailableDisks(includeUsed = false): Promise<Disk[]> {
+        return this.request(`/disks/available${includeUsed ? '?include_used=true' : ''}`);
+    }
+
     async getDiskSmartDetails(name: string): Promise<DetailedSmartReport> {
         return this.request(`/disks/${encodeURIComponent(name)}/smart`);
     }
@@ -310,6 +568,11 @@ class ApiClient {
         return this.request(`/disks/${encodeURIComponent(name)}/smart/test/status`);
     }
 
+    async checkSmart(name: string, deviceType?: string): Promise<SmartCheck> {
+        const query = deviceType ? `?device_type=${encodeURIComponent(deviceType)}` : '';
+        return this.request(`/disks/${encodeURIComponent(name)}/smart/check${query}`);
+    }
+
     async locateDisk(name: string, action: 'on' | 'off'): Promise<void> {
         return this.request(`/disks/${encodeURIComponent(name)}/locate`, {
             method: 'POST',
@@ -317,19 +580,85 @@ class ApiClient {
         });
     }
 
+    // Clears a disk for reuse as a task. label clears ZFS labels and
+    // signatures; quick also zeroes both ends of the disk. A member of an
+    // imported pool fails with 409 unless force is set.
+    async wipeDisk(name: string, mode: 'label' | 'quick' = 'label', force = false, idempotencyKey?: string): Promise<TaskOperation> {
+        return this.request(`/disks/${encodeURIComponent(name)}/wipe`, {
+            method: 'POST',
+            body: JSON.stringify({ mode, force }),
+            headers: idempotencyKey ? { 'Idempotency-Key': idempotencyKey } : undefined,
+        });
+    }
+
+    async batchDisks(disks: string[], action: 'smart_test' | 'standby' | 'locate', params?: DiskBatchParams): Promise<DiskBatchResult[]> {
+        return this.request('/disks/batch', {
+            method: 'POST',
+            body: JSON.stringify({ disks, action, params }),
+        });
+    }
+
     // Pools
     async listPools(): Promise<Pool[]> {
         return this.request('/pools');
     }
 
-    async createPool(name: string, devices: string[], type: string) {
+    async createPool(name: string, devices: string[], type: string, ashift?: number) {
         return this.request('/pools', {
             method: 'POST',
-            body: JSON.stringify({ name, devices, type }),
+            body: JSON.stringify({ name, devices, type, ashift }),
         });
     }
 
     // Datasets
+    async getCompressionOptions(): Promise<CompressionOptions> {
+        return this.request('/zfs/compression-options');
+    }
+
+    async getZFSParams(): Promise<ZFSParams> {
+        return this.request('/zfs/params');
+    }
+
+    async getARCStats(): Promise<ARCStats> {
+        return this.request('/zfs/arc');
+    }
+
+    async setZFSParam(name: string, value: string): Promise<void> {
+        return this.request('/zfs/params', {
+            method: 'PUT',
+            body: JSON.stringify({ name, value }),
+        });
+    }
+
+    async listDatasetTemplates(): Promise<DatasetTemplate[]> {
+        return this.request('/zfs/templates');
+    }
+
+    async createDatasetTemplate(name: string, properties: Record<string, string>): Promise<DatasetTemplate> {
+        return this.request('/zfs/templates', {
+            method: 'POST',
+            body: JSON.stringify({ name, properties }),
+        });
+    }
+
+    async updateDatasetTemplate(name: string, properties: Record<string, string>): Promise<DatasetTemplate> {
+        return this.request(`/zfs/templates/${encodeURIComponent(name)}`, {
+            method: 'PUT',
+            body: JSON.stringify({ properties }),
+        });
+    }
+
+    async deleteDatasetTemplate(name: string): Promise<void> {
+        return this.request(`/zfs/templates/${encodeURIComponent(name)}`, { method: 'DELETE' });
+    }
+
+    async zfsExec(command: 'zfs' | 'zpool', args: string[]): Promise<ZFSExecResult> {
+        return this.request('/zfs/exec', {
+            method: 'POST',
+            body: JSON.stringify({ command, args }),
+        });
+    }
+
     async listDatasets(): Promise<StorageSpace[]> {
         return this.request('/datasets');
     }
@@ -365,7 +694,19 @@ class ApiClient {
         });
     }
 
+    async reloadShares(): Promise<void> {
+        return this.request('/shares/reload', { method: 'POST' });
+    }
+
+    async previewShareConfig(protocol = 'smb'): Promise<string> {
+        return this.request(`/shares/config/preview?protocol=${encodeURIComponent(protocol)}`);
+    }
+
     // Notifications
+    async listAlerts(): Promise<Alert[]> {
+        return this.request('/alerts');
+    }
+
     async listNotifications(status = '', limit = 20, offset = 0): Promise<Notification[]> {
         const params = new URLSearchParams({
             limit: limit.toString(),
@@ -386,6 +727,69 @@ class ApiClient {
         });
     }
 
+    // Acknowledges the given notifications, or with all, every one with
+    // status (any unacknowledged one if status is omitted).
+    async acknowledgeNotifications(
+        req: { ids: number[] } | { all: true; status?: 'unread' | 'read' },
+    ): Promise<{ acknowledged: number }> {
+        return this.request('/notifications/ack', {
+            method: 'POST',
+            body: JSON.stringify(req),
+        });
+    }
+
+    async listNotificationChannels(): Promise<NotificationChannel[]> {
+        return this.request('/notification-channels');
+    }
+
+    async createNotificationChannel(channel: NotificationChannelInput): Promise<NotificationChannel> {
+        return this.request('/notification-channels', {
+            method: 'POST',
+            body: JSON.stringify(channel),
+        });
+    }
+
+    async updateNotificationChannel(id: number, channel: NotificationChannelInput): Promise<NotificationChannel> {
+        return this.request(`/notification-channels/${id}`, {
+            method: 'PUT',
+            body: JSON.stringify(channel),
+        });
+    }
+
+    async deleteNotificationChannel(id: number): Promise<void> {
+        return this.request(`/notification-channels/${id}`, {
+            method: 'DELETE',
+        });
+    }
+
+    async listWebhooks(): Promise<Webhook[]> {
+        return this.request('/webhooks');
+    }
+
+    async createWebhook(webhook: WebhookInput): Promise<Webhook> {
+        return this.request('/webhooks', {
+            method: 'POST',
+            body: JSON.stringify(webhook),
+        });
+    }
+
+    async updateWebhook(id: number, webhook: WebhookInput): Promise<Webhook> {
+        return this.request(`/webhooks/${id}`, {
+            method: 'PUT',
+            body: JSON.stringify(webhook),
+        });
+    }
+
+    async deleteWebhook(id: number): Promise<void> {
+        return this.request(`/webhooks/${id}`, {
+            method: 'DELETE',
+        });
+    }
+
+    async listWebhookDeliveries(id: number, limit = 20): Promise<WebhookDelivery[]> {
+        return this.request(`/webhooks/${id}/deliveries?limit=${limit}`);
+    }
+
     // Users
     async listUsers(): Promise<User[]> {
         return this.request('/users');
@@ -411,15 +815,22 @@ class ApiClient {
         });
     }
 
+    // Changes in a dataset since its newest snapshot
+    async getDatasetChanges(name: string): Promise<{ entries: DiffEntry[]; truncated: boolean }> {
+        return this.request(`/dataset-info/changes?name=${encodeURIComponent(name)}`);
+    }
+
     // Snapshots
     async listSnapshots(dataset: string): Promise<Snapshot[]> {
         return this.request(`/snapshots?dataset=${encodeURIComponent(dataset)}`);
     }
 
-    async createSnapshot(dataset: string, name: string): Promise<Snapshot> {
+    // With skipIfUnchanged, resolves to { skipped: true } when nothing was
+    // written since the last snapshot.
+    async createSnapshot(dataset: string, name: string, skipIfUnchanged = false): Promise<Snapshot | { skipped: true }> {
         return this.request('/snapshots', {
             method: 'POST',
-            body: JSON.stringify({ dataset, name }),
+            body: JSON.stringify({ dataset, name, skip_if_unchanged: skipIfUnchanged }),
         });
     }
 
@@ -429,17 +840,73 @@ class ApiClient {
         });
     }
 
+    // Changes between two snapshots of the same dataset, oldest first
+    async getSnapshotDiff(
+        from: string,
+        to: string
+    ): Promise<{ entries: DiffEntry[]; truncated: boolean }> {
+        return this.request(
+            `/snapshots/diff?name=${encodeURIComponent(from)}&to=${encodeURIComponent(to)}`
+        );
+    }
+
     async rollbackSnapshot(snapshotName: string): Promise<void> {
         return this.request(`/snapshots/rollback?name=${encodeURIComponent(snapshotName)}`, {
             method: 'POST',
         });
     }
 
+    async renameSnapshot(snapshotName: string, newName: string): Promise<void> {
+        return this.request(`/snapshots/rename?name=${encodeURIComponent(snapshotName)}`, {
+            method: 'POST',
+            body: JSON.stringify({ new_name: newName }),
+        });
+    }
+
+    // Task-creating calls take an optional idempotency key: repeating a
+    // call with the same key returns the task it started instead of a new one.
+    async sendSnapshotToFile(snapshotName: string, path: string, compress = false, overwrite = false, idempotencyKey?: string): Promise<TaskOperation> {
+        return this.request(`/snapshots/send-to-file?name=${encodeURIComponent(snapshotName)}`, {
+            method: 'POST',
+            body: JSON.stringify({ path, compress, overwrite }),
+            headers: idempotencyKey ? { 'Idempotency-Key': idempotencyKey } : undefined,
+        });
+    }
+
+    async receiveDataset(path: string, target: string, idempotencyKey?: string): Promise<TaskOperation> {
+        return this.request('/datasets/receive', {
+            method: 'POST',
+            body: JSON.stringify({ path, target }),
+            headers: idempotencyKey ? { 'Idempotency-Key': idempotencyKey } : undefined,
+        });
+    }
+
+    // Receives a send stream uploaded from the browser, e.g. a file saved by
+    // POST /datasets/send on another host.
+    async receiveDatasetStream(target: string, stream: Blob): Promise<void> {
+        return this.request(`/datasets/receive-stream?name=${encodeURIComponent(target)}`, {
+            method: 'POST',
+            body: stream,
+            headers: { 'Content-Type': 'application/octet-stream' },
+        });
+    }
+
     // Snapshot Policies
     async listSnapshotPolicies(): Promise<SnapshotPolicy[]> {
         return this.request('/snapshot-policies');
     }
 
+    async listDatasetPolicies(dataset: string): Promise<SnapshotPolicy[]> {
+        return this.request(`/dataset-info/policies?name=${encodeURIComponent(dataset)}`);
+    }
+
+    async previewSnapshotSchedule(schedule: string, count?: number): Promise<{ next_runs: string[] }> {
+        return this.request('/snapshot-policies/preview', {
+            method: 'POST',
+            body: JSON.stringify({ schedule, count }),
+        });
+    }
+
     async createSnapshotPolicy(policy: Partial<SnapshotPolicy>): Promise<SnapshotPolicy> {
         return this.request('/snapshot-policies', {
             method: 'POST',
@@ -454,12 +921,57 @@ class ApiClient {
         });
     }
 
+    async setSnapshotPolicyEnabled(id: number, enabled: boolean): Promise<SnapshotPolicy> {
+        return this.request(`/snapshot-policies/${id}`, {
+            method: 'PATCH',
+            body: JSON.stringify({ enabled }),
+        });
+    }
+
+    async getPoolProperties(poolName: string): Promise<PoolProperties> {
+        return this.request(`/pools/${poolName}/properties`);
+    }
+
+    async setPoolProperty(poolName: string, key: string, value: string): Promise<void> {
+        return this.request(`/pools/${poolName}/properties`, {
+            method: 'PUT',
+            body: JSON.stringify({ key, value }),
+        });
+    }
+
     async deleteSnapshotPolicy(id: number): Promise<void> {
         return this.request(`/snapshot-policies/${id}`, {
             method: 'DELETE',
         });
     }
 
+    async reloadSnapshotPolicies(): Promise<void> {
+        return this.request('/snapshot-policies/reload', { method: 'POST' });
+    }
+
+    // SMART self-test policies. Disks of one pool are tested one at a time.
+    async listSmartTestPolicies(): Promise<SmartTestPolicy[]> {
+        return this.request('/smart-policies');
+    }
+
+    async createSmartTestPolicy(policy: SmartTestPolicyInput): Promise<SmartTestPolicy> {
+        return this.request('/smart-policies', {
+            method: 'POST',
+            body: JSON.stringify(policy),
+        });
+    }
+
+    async updateSmartTestPolicy(id: number, policy: SmartTestPolicyInput): Promise<SmartTestPolicy> {
+        return this.request(`/smart-policies/${id}`, {
+            method: 'PUT',
+            body: JSON.stringify(policy),
+        });
+    }
+
+    async deleteSmartTestPolicy(id: number): Promise<void> {
+        return this.request(`/smart-policies/${id}`, { method: 'DELETE' });
+    }
+
     // Dataset quota management
     async setDatasetQuota(datasetName: string, quota: number): Promise<void> {
         return this.request(`/datasets/quota?name=${encodeURIComponent(datasetName)}`, {
@@ -468,19 +980,130 @@ class ApiClient {
         });
     }
 
+    async setDatasetRefQuota(datasetName: string, refquota: number): Promise<void> {
+        return this.request(`/datasets/refquota?name=${encodeURIComponent(datasetName)}`, {
+            method: 'PUT',
+            body: JSON.stringify({ refquota }),
+        });
+    }
+
+    // Pass an algorithm such as 'zstd-3', or { enabled: false } to turn compression off.
+    // Existing data is not recompressed.
+    async setDatasetCompression(
+        datasetName: string,
+        setting: { algorithm?: string; enabled?: boolean }
+    ): Promise<{ compression: string; note: string }> {
+        return this.request(`/datasets/compression?name=${encodeURIComponent(datasetName)}`, {
+            method: 'POST',
+            body: JSON.stringify(setting),
+        });
+    }
+
+    async getDatasetProperties(
+        datasetName: string,
+        keys?: string[]
+    ): Promise<{ properties: Record<string, string>; settable: string[] }> {
+        let path = `/dataset-info/properties?name=${encodeURIComponent(datasetName)}`;
+        if (keys && keys.length > 0) {
+            path += `&keys=${encodeURIComponent(keys.join(','))}`;
+        }
+        return this.request(path);
+    }
+
+    async setDatasetProperties(
+        datasetName: string,
+        properties: Record<string, string>
+    ): Promise<void> {
+        return this.request(`/datasets/properties?name=${encodeURIComponent(datasetName)}`, {
+            method: 'PUT',
+            body: JSON.stringify({ properties }),
+        });
+    }
+
+    async setDatasetReservation(
+        datasetName: string,
+        reservation: number,
+        mode: 'reservation' | 'refreservation' = 'reservation',
+    ): Promise<void> {
+        return this.request(`/datasets/reservation?name=${encodeURIComponent(datasetName)}`, {
+            method: 'PUT',
+            body: JSON.stringify({ reservation, mode }),
+        });
+    }
+
+    async getDatasetNote(datasetName: string): Promise<{ note: string }> {
+        return this.request(`/dataset-info/note?name=${encodeURIComponent(datasetName)}`);
+    }
+
+    async getTemplateDrift(datasetName: string, useCase: string): Promise<Record<string, PropDiff>> {
+        return this.request(`/dataset-info/template-drift?name=${encodeURIComponent(datasetName)}&use_case=${encodeURIComponent(useCase)}`);
+    }
+
+    async getDatasetACL(datasetName: string): Promise<ACLEntry[]> {
+        return this.request(`/dataset-info/acl?name=${encodeURIComponent(datasetName)}`);
+    }
+
+    async setDatasetACL(datasetName: string, entries: ACLEntry[]): Promise<void> {
+        return this.request(`/datasets/acl?name=${encodeURIComponent(datasetName)}`, {
+            method: 'PUT',
+            body: JSON.stringify(entries),
+        });
+    }
+
+    async setDatasetNote(datasetName: string, note: string): Promise<void> {
+        return this.request(`/datasets/note?name=${encodeURIComponent(datasetName)}`, {
+            method: 'PUT',
+            body: JSON.stringify({ note }),
+        });
+    }
+
     // Pool management
-    async scrubPool(poolName: string): Promise<void> {
+    // Starting a paused scrub resumes it.
+    async scrubPool(poolName: string, action: 'start' | 'stop' | 'pause' = 'start'): Promise<void> {
         return this.request(`/pools/${poolName}/scrub`, {
             method: 'POST',
-            body: JSON.stringify({ action: 'start' }),
+            body: JSON.stringify({ action }),
+        });
+    }
+
+    // Fails with 409 if the pool's devices do not support trim.
+    async trimPool(poolName: string): Promise<void> {
+        return this.request(`/pools/${poolName}/trim`, { method: 'POST' });
+    }
+
+    async getPoolTrimStatus(poolName: string): Promise<TrimStatus> {
+        return this.request(`/pools/${poolName}/trim/status`);
+    }
+
+    async setPoolAutotrim(poolName: string, enabled: boolean): Promise<{ autotrim: boolean }> {
+        return this.request(`/pools/${poolName}/autotrim`, {
+            method: 'PUT',
+            body: JSON.stringify({ enabled }),
         });
     }
 
+    async getPoolScanStatus(poolName: string): Promise<PoolScanStatus> {
+        return this.request(`/pools/${poolName}/scrub/status`);
+    }
+
+    // The zpool status text of a pool, as printed by the CLI.
+    async getPoolStatusText(poolName: string): Promise<string> {
+        return this.request(`/pools/${poolName}/scrub/status?format=raw`);
+    }
+
+    async getPoolCapacityHistory(poolName: string, days = 30): Promise<PoolCapacitySample[]> {
+        return this.request(`/pools/${poolName}/capacity/history?days=${days}`);
+    }
+
     // Pool detail operations
     async getPool(poolName: string): Promise<Pool> {
         return this.request(`/pools/${poolName}`);
     }
 
+    async getPoolHealth(poolName: string): Promise<PoolHealth> {
+        return this.request(`/pools/${poolName}/health`);
+    }
+
     async replaceDisk(poolName: string, oldDisk: string, newDisk: string): Promise<void> {
         return this.request(`/pools/${poolName}/replace`, {
             method: 'POST',
@@ -488,16 +1111,108 @@ class ApiClient {
         });
     }
 
+    // type is mirror, raidz, raidz2, raidz3, or '' for a single disk. A vdev
+    // that would lower the pool's redundancy fails with 409 unless force is set.
+    async addVdev(poolName: string, type: string, devices: string[], force = false): Promise<void> {
+        return this.request(`/pools/${poolName}/vdevs`, {
+            method: 'POST',
+            body: JSON.stringify({ type, devices, force }),
+        });
+    }
+
+    async listImportablePools(): Promise<ImportablePool[]> {
+        return this.request('/pools/importable');
+    }
+
+    // name may be the pool name or its numeric GUID. Fails with 409 when the
+    // pool needs force or degraded and the option is not set.
+    async importPool(name: string, opts: ImportOptions = {}): Promise<ImportablePool> {
+        return this.request('/pools/import', {
+            method: 'POST',
+            body: JSON.stringify({ name, ...opts }),
+        });
+    }
+
+    async exportPool(poolName: string): Promise<void> {
+        return this.request(`/pools/${poolName}/export`, { method: 'POST' });
+    }
+
     // System monitoring
     async getSystemStats(): Promise<SystemStats> {
         return this.request('/system/stats');
     }
 
+    async getMetrics(metric: MetricName, from?: string, to?: string, resolution?: number): Promise<MetricSeries> {
+        const params = new URLSearchParams({ metric });
+        if (from) params.append('from', from);
+        if (to) params.append('to', to);
+        if (resolution) params.append('resolution', resolution.toString());
+
+        return this.request(`/metrics?${params}`);
+    }
+
+    async getVersion(): Promise<VersionInfo> {
+        return this.request('/version');
+    }
+
+    async getCapabilities(): Promise<Capabilities> {
+        return this.request('/capabilities');
+    }
+
+    async validateConfig(): Promise<ConfigFinding[]> {
+        return this.request('/config/validate');
+    }
+
+    async getIntervals(): Promise<ServerIntervals> {
+        return this.request('/config/intervals');
+    }
+
+    async setIntervals(scan: ServerIntervals['scan']): Promise<ServerIntervals> {
+        return this.request('/config/intervals', {
+            method: 'PUT',
+            body: JSON.stringify(scan),
+        });
+    }
+
+    async getAlertRules(): Promise<AlertRule[]> {
+        return this.request('/config/alert-rules');
+    }
+
+    async setAlertRules(rules: AlertRule[]): Promise<AlertRule[]> {
+        return this.request('/config/alert-rules', {
+            method: 'PUT',
+            body: JSON.stringify(rules),
+        });
+    }
+
+    async getSMTPConfig(): Promise<SMTPConfig> {
+        return this.request('/config/smtp');
+    }
+
+    async setSMTPConfig(config: SMTPConfigInput): Promise<SMTPConfig> {
+        return this.request('/config/smtp', {
+            method: 'PUT',
+            body: JSON.stringify(config),
+        });
+    }
+
+    async getSystemResources(): Promise<SystemResources> {
+        return this.request('/system/resources');
+    }
+
+    async getSwapDevices(): Promise<SwapDevice[]> {
+        return this.request('/system/swap');
+    }
+
     async listProcesses(filter?: string): Promise<SysProcess[]> {
         const params = filter ? `?filter=${encodeURIComponent(filter)}` : '';
         return this.request(`/system/processes${params}`);
     }
 
+    async getProcessDetail(pid: number): Promise<ProcessDetail> {
+        return this.request(`/system/processes/${pid}`);
+    }
+
     async signalProcess(pid: number, signal: 'TERM' | 'KILL' = 'TERM'): Promise<void> {
         return this.request(`/system/processes/${pid}/signal`, {
             method: 'POST',
@@ -506,13 +1221,94 @@ class ApiClient {
     }
 }
 
+interface VersionInfo {
+    version: string;
+    commit: string;
+    build_date: string;
+}
+
 // System monitoring types
+interface Capability {
+    available: boolean;
+    version?: string;
+}
+
+interface Capabilities {
+    zfs: Capability;
+    samba: Capability;
+    nfs: Capability;
+    smartctl: Capability;
+    ledctl: Capability;
+    sensors: Capability;
+}
+
+// A problem found by the configuration check; an empty list means none.
+interface ConfigFinding {
+    check: 'pools' | 'shares' | 'policies' | 'samba' | 'users';
+    severity: 'info' | 'warning' | 'critical';
+    resource?: string;
+    message: string;
+}
+
+interface ServerIntervals {
+    scan: { disk: number; smart: number; zfs: number };     // seconds
+    poll: { disks: number; smart: number; pools: number }; // recommended, seconds
+}
+
+interface AlertRule {
+    metric: 'disk_temp' | 'pool_capacity' | 'pool_errors'; // °C, percent used, error count
+    op: '>' | '>=' | '<' | '<=';
+    threshold: number;
+    hysteresis?: number; // distance past the threshold needed to clear; 0 uses the metric's default
+    severity: 'info' | 'warning' | 'critical';
+}
+
+interface SMTPConfig {
+    host: string;     // empty when email is disabled
+    port: number;
+    username: string;
+    from: string;
+    tls: boolean;     // implicit TLS instead of STARTTLS
+    password_set: boolean;
+}
+
+interface SMTPConfigInput {
+    host: string;
+    port?: number;     // defaults to 587, or 465 with tls
+    username?: string;
+    password?: string; // empty keeps the saved password
+    from: string;
+    tls?: boolean;
+}
+
+interface SystemResources {
+    open_files: number;      // Allocated file handles
+    max_files: number;       // System-wide file handle limit
+    tcp_established: number; // Established TCP connections
+}
+
+type MetricName = 'cpu' | 'memory' | 'net_in' | 'net_out' | 'disk_read' | 'disk_write';
+
+interface MetricPoint {
+    time: string;
+    value: number; // Percent for cpu and memory, bytes/s otherwise
+}
+
+interface MetricSeries {
+    metric: MetricName;
+    from: string;
+    to: string;
+    points: MetricPoint[];
+}
+
 interface SystemStats {
     cpu: CPUStats;
     memory: MemStats;
     network: NetStats[];
     disk_io: DiskIOStats[];
     uptime: number; // System uptime in seconds
+    boot_time: number; // Boot time as a Unix timestamp
+    load_avg: [number, number, number]; // Load average over 1, 5 and 15 minutes
 }
 
 interface CPUStats {
@@ -520,6 +1316,7 @@ interface CPUStats {
     total: number;
     temperature: number;
     frequency: number;
+    per_core_freq: number[] | null; // MHz per logical CPU, Linux only
     core_count: number;
 }
 
@@ -532,6 +1329,16 @@ interface MemStats {
     swap_total: number;
     swap_used: number;
     percent: number;
+    swap_devices: SwapDevice[];
+}
+
+interface SwapDevice {
+    device: string;
+    type: string;     // "partition" or "file"
+    size: number;     // Bytes
+    used: number;     // Bytes
+    priority: number;
+    zram: boolean;
 }
 
 interface NetStats {
@@ -542,6 +1349,10 @@ interface NetStats {
     speed_out: number;
     link_speed: number;
     is_up: boolean;
+    rx_errors: number;
+    tx_errors: number;
+    rx_dropped: number;
+    tx_dropped: number;
 }
 
 interface DiskIOStats {
@@ -565,6 +1376,14 @@ interface SysProcess {
     threads: number;
 }
 
+interface ProcessDetail {
+    pid: number;
+    ppid: number;
+    cwd: string;
+    open_files: number;
+    environ: string[] | null;
+}
+
 export const api = new ApiClient();
-export type { User, Pool, VDevDetail, DiskDetail, ResilverStatus, ScrubStatus, PoolHealth, Disk, Share, Notification, Snapshot, StorageSpace, CreateDatasetRequest, SnapshotPolicy, SmartAttribute, DetailedSmartReport, SmartTestStatus, SystemStats, CPUStats, MemStats, NetStats, DiskIOStats, SysProcess };
+export type { User, Pool, VDevDetail, DiskDetail, ResilverStatus, ScrubStatus, TrimStatus, ImportablePool, ImportOptions, PoolScanStatus, PoolCapacitySample, PoolHealth, PoolProperties, Disk, Share, TaskOperation, Alert, Notification, NotificationChannel, NotificationChannelInput, Webhook, WebhookInput, WebhookDelivery, Snapshot, DiffEntry, StorageSpace, CreateDatasetRequest, DatasetTemplate, ZFSExecResult, CompressionOptions, ZFSParams, ARCStats, ACLEntry, PropDiff, SnapshotPolicy, PolicyTarget, SmartTestPolicy, SmartTestPolicyInput, SmartAttribute, DetailedSmartReport, SmartTestStatus, SmartCheck, DiskBatchParams, DiskBatchResult, SystemStats, SystemResources, MetricName, MetricPoint, MetricSeries, ServerIntervals, AlertRule, SMTPConfig, SMTPConfigInput, ConfigFinding, Capability, Capabilities, VersionInfo, CPUStats, MemStats, SwapDevice, NetStats, DiskIOStats, SysProcess, ProcessDetail };
 
@@ -0,0 +1,127 @@
+package zfs
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+)
+
+// ACLEntry is one POSIX ACL entry, e.g. "user:alice:r-x".
+type ACLEntry struct {
+	Type        string `json:"type"`                // user, group, mask or other
+	Qualifier   string `json:"qualifier,omitempty"` // user or group name; empty for the owner entries
+	Permissions string `json:"permissions"`         // e.g. "rwx", "r-x"
+	Default     bool   `json:"default,omitempty"`   // inherited by new files in a directory
+}
+
+// String returns the entry in getfacl/setfacl text format.
+func (e ACLEntry) String() string {
+	s := e.Type + ":" + e.Qualifier + ":" + e.Permissions
+	if e.Default {
+		s = "default:" + s
+	}
+	return s
+}
+
+// validate checks that e can be passed to setfacl safely.
+func (e ACLEntry) validate() error {
+	switch e.Type {
+	case "user", "group":
+	case "mask", "other":
+		if e.Qualifier != "" {
+			return fmt.Errorf("%s entry cannot have a qualifier", e.Type)
+		}
+	default:
+		return fmt.Errorf("invalid acl entry type: %s", e.Type)
+	}
+	if strings.ContainsAny(e.Qualifier, ":,\n") {
+		return fmt.Errorf("invalid acl qualifier: %s", e.Qualifier)
+	}
+	if !validPermissions(e.Permissions) {
+		return fmt.Errorf("invalid acl permissions: %s", e.Permissions)
+	}
+	return nil
+}
+
+// validPermissions reports whether p is in rwx form, e.g. "r-x".
+func validPermissions(p string) bool {
+	if len(p) != 3 {
+		return false
+	}
+	for i, c := range "rwx" {
+		if p[i] != byte(c) && p[i] != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+// GetACL returns the POSIX ACL of path.
+func (m *Manager) GetACL(ctx context.Context, path string) ([]ACLEntry, error) {
+	out, err := m.exec.CombinedOutput(ctx, "getfacl", "-p", "--", path)
+	if err != nil {
+		return nil, fmt.Errorf("getfacl: %s: %w", bytes.TrimSpace(out), err)
+	}
+	return parseGetfacl(out)
+}
+
+// SetACL replaces the POSIX ACL of path. It must contain the user::,
+// group:: and other:: entries.
+func (m *Manager) SetACL(ctx context.Context, path string, entries []ACLEntry) error {
+	if len(entries) == 0 {
+		return fmt.Errorf("acl entries are required")
+	}
+
+	specs := make([]string, len(entries))
+	for i, e := range entries {
+		if err := e.validate(); err != nil {
+			return err
+		}
+		specs[i] = e.String()
+	}
+
+	out, err := m.exec.CombinedOutput(ctx, "setfacl", "--set", strings.Join(specs, ","), "--", path)
+	if err != nil {
+		return fmt.Errorf("setfacl: %s: %w", bytes.TrimSpace(out), err)
+	}
+	return nil
+}
+
+// parseGetfacl parses the text output of getfacl, e.g.
+//
+//	# file: /tank/data
+//	# owner: root
+//	# group: root
+//	user::rwx
+//	user:alice:rwx		#effective:r-x
+//	group::r-x
+//	mask::r-x
+//	other::---
+//	default:user::rwx
+func parseGetfacl(out []byte) ([]ACLEntry, error) {
+	var entries []ACLEntry
+	sc := bufio.NewScanner(bytes.NewReader(out))
+	for sc.Scan() {
+		line, _, _ := strings.Cut(sc.Text(), "#")
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		var e ACLEntry
+		if rest, ok := strings.CutPrefix(line, "default:"); ok {
+			e.Default = true
+			line = rest
+		}
+
+		parts := strings.Split(line, ":")
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("invalid acl entry: %s", line)
+		}
+		e.Type, e.Qualifier, e.Permissions = parts[0], parts[1], parts[2]
+		entries = append(entries, e)
+	}
+	return entries, sc.Err()
+}
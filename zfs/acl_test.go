@@ -0,0 +1,80 @@
+package zfs
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"go.aimuz.me/mynt/sysexec"
+)
+
+const getfaclOutput = `# file: /tank/data
+# owner: root
+# group: staff
+user::rwx
+user:alice:rwx			#effective:r-x
+group::r-x
+group:media:rw-			#effective:r--
+mask::r-x
+other::---
+default:user::rwx
+default:group::r-x
+default:other::---
+
+`
+
+func TestParseGetfacl(t *testing.T) {
+	entries, err := parseGetfacl([]byte(getfaclOutput))
+	if err != nil {
+		t.Fatalf("parseGetfacl: %v", err)
+	}
+
+	want := []ACLEntry{
+		{Type: "user", Permissions: "rwx"},
+		{Type: "user", Qualifier: "alice", Permissions: "rwx"},
+		{Type: "group", Permissions: "r-x"},
+		{Type: "group", Qualifier: "media", Permissions: "rw-"},
+		{Type: "mask", Permissions: "r-x"},
+		{Type: "other", Permissions: "---"},
+		{Type: "user", Permissions: "rwx", Default: true},
+		{Type: "group", Permissions: "r-x", Default: true},
+		{Type: "other", Permissions: "---", Default: true},
+	}
+	if !slices.Equal(entries, want) {
+		t.Errorf("entries = %+v\nwant %+v", entries, want)
+	}
+}
+
+func TestSetACL(t *testing.T) {
+	exec := sysexec.NewMock()
+	m := &Manager{exec: exec}
+
+	err := m.SetACL(context.Background(), "/tank/data", []ACLEntry{
+		{Type: "user", Permissions: "rwx"},
+		{Type: "user", Qualifier: "alice", Permissions: "r-x"},
+		{Type: "group", Permissions: "r-x"},
+		{Type: "mask", Permissions: "r-x"},
+		{Type: "other", Permissions: "---"},
+		{Type: "user", Qualifier: "alice", Permissions: "r-x", Default: true},
+	})
+	if err != nil {
+		t.Fatalf("SetACL: %v", err)
+	}
+
+	want := []string{"--set", "user::rwx,user:alice:r-x,group::r-x,mask::r-x,other::---,default:user:alice:r-x", "--", "/tank/data"}
+	if cmds := exec.Commands(); len(cmds) != 1 || !slices.Equal(cmds[0].Args, want) {
+		t.Errorf("commands = %v, want setfacl %v", cmds, want)
+	}
+
+	for _, bad := range []ACLEntry{
+		{Type: "owner", Permissions: "rwx"},
+		{Type: "other", Qualifier: "bob", Permissions: "r--"},
+		{Type: "user", Qualifier: "a,b", Permissions: "r--"},
+		{Type: "user", Permissions: "rwxs"},
+		{Type: "user", Permissions: "xwr"},
+	} {
+		if err := m.SetACL(context.Background(), "/tank/data", []ACLEntry{bad}); err == nil {
+			t.Errorf("SetACL(%+v) succeeded, want error", bad)
+		}
+	}
+}
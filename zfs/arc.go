@@ -0,0 +1,107 @@
+package zfs
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// defaultARCStatsPath is where the ZFS module exposes ARC counters.
+const defaultARCStatsPath = "/proc/spl/kstat/zfs/arcstats"
+
+// mockARCStats is returned on darwin, which has no ZFS module to read.
+var mockARCStats = &ARCStats{
+	Size:       6 << 30,
+	TargetSize: 8 << 30,
+	MinSize:    512 << 20,
+	MaxSize:    8 << 30,
+	Hits:       950000,
+	Misses:     50000,
+	HitRatio:   95,
+}
+
+// ARCStats summarises the ZFS adaptive replacement cache.
+type ARCStats struct {
+	Size       uint64  `json:"size"`        // current size in bytes
+	TargetSize uint64  `json:"target_size"` // size the ARC is adapting towards (c)
+	MinSize    uint64  `json:"min_size"`    // c_min
+	MaxSize    uint64  `json:"max_size"`    // c_max
+	Hits       uint64  `json:"hits"`
+	Misses     uint64  `json:"misses"`
+	HitRatio   float64 `json:"hit_ratio"` // percent of lookups served from the ARC
+}
+
+// ARCStats reads the ARC counters of the ZFS kernel module. Hits and
+// misses count since the module was loaded.
+func (m *Manager) ARCStats(ctx context.Context) (*ARCStats, error) {
+	if runtime.GOOS == "darwin" {
+		stats := *mockARCStats
+		return &stats, nil
+	}
+
+	data, err := os.ReadFile(m.arcStatsFile())
+	if err != nil {
+		return nil, fmt.Errorf("read arcstats: %w", err)
+	}
+	kstats, err := parseKstat(data)
+	if err != nil {
+		return nil, fmt.Errorf("parse arcstats: %w", err)
+	}
+	return buildARCStats(kstats), nil
+}
+
+// buildARCStats picks the typed fields out of parsed arcstats.
+func buildARCStats(kstats map[string]uint64) *ARCStats {
+	stats := &ARCStats{
+		Size:       kstats["size"],
+		TargetSize: kstats["c"],
+		MinSize:    kstats["c_min"],
+		MaxSize:    kstats["c_max"],
+		Hits:       kstats["hits"],
+		Misses:     kstats["misses"],
+	}
+	if total := stats.Hits + stats.Misses; total > 0 {
+		stats.HitRatio = float64(stats.Hits) / float64(total) * 100
+	}
+	return stats
+}
+
+// parseKstat parses a named kstat file: a header line, a "name type data"
+// column line, then one whitespace-separated counter per line. Counters
+// that are not unsigned integers are skipped.
+func parseKstat(data []byte) (map[string]uint64, error) {
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	// Skip the kstat header and the column names
+	for range 2 {
+		if !sc.Scan() {
+			return nil, fmt.Errorf("missing kstat header")
+		}
+	}
+
+	values := make(map[string]uint64)
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) != 3 {
+			continue
+		}
+		v, err := strconv.ParseUint(fields[2], 10, 64)
+		if err != nil {
+			continue
+		}
+		values[fields[0]] = v
+	}
+	return values, sc.Err()
+}
+
+// arcStatsFile returns the arcstats kstat path.
+func (m *Manager) arcStatsFile() string {
+	if m.arcStatsPath != "" {
+		return m.arcStatsPath
+	}
+	return defaultARCStatsPath
+}
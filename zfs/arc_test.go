@@ -0,0 +1,63 @@
+//go:build linux
+
+package zfs
+
+import (
+	"context"
+	"math"
+	"path/filepath"
+	"testing"
+)
+
+func TestARCStats(t *testing.T) {
+	m := &Manager{arcStatsPath: filepath.Join("testdata", "arcstats.txt")}
+
+	stats, err := m.ARCStats(context.Background())
+	if err != nil {
+		t.Fatalf("ARCStats: %v", err)
+	}
+
+	want := ARCStats{
+		Size:       8124559112,
+		TargetSize: 8226078720,
+		MinSize:    520347648,
+		MaxSize:    8326078720,
+		Hits:       9812733,
+		Misses:     412377,
+	}
+	ratio := stats.HitRatio
+	stats.HitRatio = 0
+	if *stats != want {
+		t.Errorf("stats = %+v, want %+v", *stats, want)
+	}
+	if math.Abs(ratio-95.967) > 0.001 {
+		t.Errorf("HitRatio = %v, want about 95.967", ratio)
+	}
+}
+
+func TestARCStats_MissingFile(t *testing.T) {
+	m := &Manager{arcStatsPath: filepath.Join(t.TempDir(), "arcstats")}
+	if _, err := m.ARCStats(context.Background()); err == nil {
+		t.Error("expected error without the zfs module")
+	}
+}
+
+func TestParseKstat(t *testing.T) {
+	if _, err := parseKstat([]byte("9 1 0x01 2 96 1 2\n")); err == nil {
+		t.Error("expected error for a truncated header")
+	}
+
+	got, err := parseKstat([]byte("9 1 0x01 2 96 1 2\nname type data\nhits 4 10\nbogus\nlabel 7 text\n"))
+	if err != nil {
+		t.Fatalf("parseKstat: %v", err)
+	}
+	if len(got) != 1 || got["hits"] != 10 {
+		t.Errorf("parseKstat() = %v, want only hits=10", got)
+	}
+}
+
+func TestBuildARCStats_NoLookups(t *testing.T) {
+	if got := buildARCStats(map[string]uint64{"size": 1}); got.HitRatio != 0 {
+		t.Errorf("HitRatio = %v, want 0 before any lookup", got.HitRatio)
+	}
+}
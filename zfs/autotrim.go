@@ -0,0 +1,43 @@
+package zfs
+
+import (
+	"context"
+	"slices"
+)
+
+// SetAutotrim turns the pool's autotrim property on or off. With autotrim
+// on, space freed in the pool, including discards passed through from VMs
+// on zvols, is trimmed on the underlying SSDs as it is freed.
+func (m *Manager) SetAutotrim(ctx context.Context, pool string, on bool) error {
+	value := "off"
+	if on {
+		value = "on"
+	}
+	return m.SetPoolProperty(ctx, pool, "autotrim", value)
+}
+
+// fillVolumeAutotrim sets PoolAutotrim on the volumes among datasets from
+// their pools' autotrim property. If the property cannot be read it is
+// left unset rather than failing the listing.
+func (m *Manager) fillVolumeAutotrim(ctx context.Context, datasets []Dataset) {
+	var pools []string
+	for _, ds := range datasets {
+		if ds.Type == DatasetVolume && !slices.Contains(pools, ds.Pool) {
+			pools = append(pools, ds.Pool)
+		}
+	}
+	if len(pools) == 0 {
+		return
+	}
+
+	autotrim, err := m.poolFlag(ctx, "autotrim", pools...)
+	if err != nil {
+		return
+	}
+	for i := range datasets {
+		if datasets[i].Type == DatasetVolume {
+			on := autotrim[datasets[i].Pool]
+			datasets[i].PoolAutotrim = &on
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package zfs
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"go.aimuz.me/mynt/sysexec"
+)
+
+func TestSetAutotrim(t *testing.T) {
+	tests := []struct {
+		name     string
+		on       bool
+		wantArgs []string
+	}{
+		{name: "on", on: true, wantArgs: []string{"set", "autotrim=on", "tank"}},
+		{name: "off", on: false, wantArgs: []string{"set", "autotrim=off", "tank"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exec := sysexec.NewMock()
+			m := &Manager{exec: exec}
+
+			if err := m.SetAutotrim(context.Background(), "tank", tt.on); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			cmds := mutationCommands(t, exec)
+			if len(cmds) != 1 || cmds[0].Name != "zpool" || !slices.Equal(cmds[0].Args, tt.wantArgs) {
+				t.Errorf("commands = %v, want zpool %v", cmds, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestSetAutotrim_InvalidPool(t *testing.T) {
+	exec := sysexec.NewMock()
+	m := &Manager{exec: exec}
+
+	if err := m.SetAutotrim(context.Background(), "tank;rm", true); err == nil {
+		t.Fatal("expected error for invalid pool name")
+	}
+	if cmds := exec.Commands(); len(cmds) != 0 {
+		t.Errorf("ran %v, want nothing", cmds)
+	}
+}
+
+const volumeListJSON = `{"output_version":{},"datasets":{
+"tank/data":{"name":"tank/data","type":"FILESYSTEM","pool":"tank","properties":{"volmode":{"value":"-"}}},
+"tank/vm":{"name":"tank/vm","type":"VOLUME","pool":"tank","properties":{"volmode":{"value":"dev"}}},
+"fast/vm":{"name":"fast/vm","type":"VOLUME","pool":"fast","properties":{"volmode":{"value":"default"}}}}}`
+
+func TestListDatasets_VolumeTrim(t *testing.T) {
+	exec := sysexec.NewMock()
+	exec.SetOutput("zfs", []byte(volumeListJSON))
+	exec.SetOutput("zpool", []byte("fast\ton\ntank\toff\n"))
+	m := &Manager{exec: exec}
+
+	datasets, err := m.ListDatasets(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// autotrim is "" when PoolAutotrim is unset
+	want := map[string]struct{ volMode, autotrim string }{
+		"tank/data": {},
+		"tank/vm":   {volMode: "dev", autotrim: "off"},
+		"fast/vm":   {volMode: "default", autotrim: "on"},
+	}
+	for _, ds := range datasets {
+		w := want[ds.Name]
+		if ds.VolMode != w.volMode {
+			t.Errorf("%s: VolMode = %q, want %q", ds.Name, ds.VolMode, w.volMode)
+		}
+		var autotrim string
+		if ds.PoolAutotrim != nil {
+			autotrim = map[bool]string{true: "on", false: "off"}[*ds.PoolAutotrim]
+		}
+		if autotrim != w.autotrim {
+			t.Errorf("%s: PoolAutotrim = %q, want %q", ds.Name, autotrim, w.autotrim)
+		}
+	}
+
+	var get []string
+	for _, cmd := range exec.Commands() {
+		if cmd.Name == "zpool" {
+			get = cmd.Args
+		}
+	}
+	if !slices.Equal(get, []string{"get", "-H", "-p", "-o", "name,value", "autotrim", "fast", "tank"}) {
+		t.Errorf("zpool args = %v, want autotrim lookup for fast and tank", get)
+	}
+}
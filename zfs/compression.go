@@ -0,0 +1,119 @@
+package zfs
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"go.aimuz.me/mynt/logger"
+)
+
+// CompressionOptions lists the compression algorithms the running ZFS accepts.
+type CompressionOptions struct {
+	Version    string   `json:"version,omitempty"` // OpenZFS version, empty if unknown
+	Algorithms []string `json:"algorithms"`
+}
+
+// CompressionOptions returns the compression algorithms supported by the
+// running ZFS. The kernel module version wins over the userland one since it
+// decides what a dataset accepts. If the version cannot be determined, only
+// algorithms every ZFS supports are returned.
+func (m *Manager) CompressionOptions(ctx context.Context) *CompressionOptions {
+	out, err := m.exec.Output(ctx, "zfs", "version")
+	if err != nil {
+		// zfs version does not exist before OpenZFS 0.8
+		logger.Debug("failed to get zfs version", "error", err)
+	}
+
+	version, major, ok := parseZFSVersion(out)
+	if !ok {
+		return &CompressionOptions{Algorithms: compressionAlgorithms(0)}
+	}
+	return &CompressionOptions{
+		Version:    version,
+		Algorithms: compressionAlgorithms(major),
+	}
+}
+
+// ErrUnsupportedCompression is returned for a compression algorithm the
+// running ZFS does not accept.
+var ErrUnsupportedCompression = errors.New("unsupported compression algorithm")
+
+// SetCompression sets the compression property of a dataset, e.g. "zstd-3"
+// or "off". Only blocks written afterwards use the new setting; existing
+// data is not recompressed.
+func (m *Manager) SetCompression(ctx context.Context, name, algorithm string) error {
+	if err := validateName(name); err != nil {
+		return err
+	}
+	if !slices.Contains(m.CompressionOptions(ctx).Algorithms, algorithm) {
+		return fmt.Errorf("%w: %q", ErrUnsupportedCompression, algorithm)
+	}
+
+	defer m.lockPool(name)()
+	if err := m.checkWritable(ctx, name); err != nil {
+		return err
+	}
+	if out, err := m.runMutation(ctx, "zfs", "set", "compression="+algorithm, name); err != nil {
+		return fmt.Errorf("failed to set compression: %s: %w", bytes.TrimSpace(out), err)
+	}
+	return nil
+}
+
+// parseZFSVersion parses the output of zfs version, e.g.
+//
+//	zfs-2.1.5-1ubuntu6~22.04.1
+//	zfs-kmod-2.1.5-1ubuntu6~22.04.1
+//
+// preferring the kernel module version when both are present.
+func parseZFSVersion(out []byte) (version string, major int, ok bool) {
+	var userland, kmod string
+	sc := bufio.NewScanner(bytes.NewReader(out))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if v, found := strings.CutPrefix(line, "zfs-kmod-"); found {
+			kmod = v
+		} else if v, found := strings.CutPrefix(line, "zfs-"); found {
+			userland = v
+		}
+	}
+
+	version = kmod
+	if version == "" {
+		version = userland
+	}
+	version, _, _ = strings.Cut(version, "-")
+	majorStr, _, _ := strings.Cut(version, ".")
+	major, err := strconv.Atoi(majorStr)
+	if err != nil {
+		return "", 0, false
+	}
+	return version, major, true
+}
+
+// compressionAlgorithms returns the compression values accepted by the
+// given OpenZFS major version. zstd arrived in OpenZFS 2.0.
+func compressionAlgorithms(major int) []string {
+	algs := []string{"off", "on", "lz4", "lzjb", "zle", "gzip"}
+	for i := 1; i <= 9; i++ {
+		algs = append(algs, fmt.Sprintf("gzip-%d", i))
+	}
+	if major < 2 {
+		return algs
+	}
+
+	algs = append(algs, "zstd")
+	for i := 1; i <= 19; i++ {
+		algs = append(algs, fmt.Sprintf("zstd-%d", i))
+	}
+	algs = append(algs, "zstd-fast")
+	for _, i := range []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 20, 30, 40, 50, 60, 70, 80, 90, 100, 500, 1000} {
+		algs = append(algs, fmt.Sprintf("zstd-fast-%d", i))
+	}
+	return algs
+}
@@ -0,0 +1,137 @@
+package zfs
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"go.aimuz.me/mynt/sysexec"
+)
+
+func TestCompressionOptions(t *testing.T) {
+	tests := []struct {
+		name        string
+		output      string
+		err         error
+		wantVersion string
+		wantZstd    bool
+	}{
+		{
+			name:        "openzfs_2",
+			output:      "zfs-2.1.5-1ubuntu6~22.04.1\nzfs-kmod-2.1.5-1ubuntu6~22.04.1\n",
+			wantVersion: "2.1.5",
+			wantZstd:    true,
+		},
+		{
+			name:        "old_kmod_new_userland",
+			output:      "zfs-2.0.0-1\nzfs-kmod-0.8.6-1\n",
+			wantVersion: "0.8.6",
+		},
+		{
+			name:        "zol_0_8",
+			output:      "zfs-0.8.3-1ubuntu12\nzfs-kmod-0.8.3-1ubuntu12\n",
+			wantVersion: "0.8.3",
+		},
+		{
+			name: "no_version_command",
+			err:  errors.New("exit status 2"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exec := sysexec.NewMock()
+			exec.SetOutput("zfs", []byte(tt.output))
+			if tt.err != nil {
+				exec.SetError("zfs", tt.err)
+			}
+			m := &Manager{exec: exec}
+
+			opts := m.CompressionOptions(context.Background())
+			if opts.Version != tt.wantVersion {
+				t.Errorf("Version = %q, want %q", opts.Version, tt.wantVersion)
+			}
+			for _, alg := range []string{"lz4", "gzip-9"} {
+				if !slices.Contains(opts.Algorithms, alg) {
+					t.Errorf("missing %s", alg)
+				}
+			}
+			for _, alg := range []string{"zstd", "zstd-19", "zstd-fast-1000"} {
+				if got := slices.Contains(opts.Algorithms, alg); got != tt.wantZstd {
+					t.Errorf("has %s = %v, want %v", alg, got, tt.wantZstd)
+				}
+			}
+		})
+	}
+}
+
+func TestSetCompression(t *testing.T) {
+	tests := []struct {
+		name      string
+		algorithm string
+		wantArgs  []string
+		wantErr   error
+	}{
+		{
+			name:      "zstd_with_level",
+			algorithm: "zstd-3",
+			wantArgs:  []string{"set", "compression=zstd-3", "tank/data"},
+		},
+		{
+			name:      "disable",
+			algorithm: "off",
+			wantArgs:  []string{"set", "compression=off", "tank/data"},
+		},
+		{
+			name:      "unknown_algorithm",
+			algorithm: "brotli",
+			wantErr:   ErrUnsupportedCompression,
+		},
+		{
+			name:      "zstd_level_out_of_range",
+			algorithm: "zstd-42",
+			wantErr:   ErrUnsupportedCompression,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exec := sysexec.NewMock()
+			exec.SetOutput("zfs", []byte("zfs-2.2.2-1\nzfs-kmod-2.2.2-1\n"))
+			m := &Manager{exec: exec}
+
+			err := m.SetCompression(context.Background(), "tank/data", tt.algorithm)
+			cmds := exec.Commands()
+			last := cmds[len(cmds)-1]
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("error = %v, want %v", err, tt.wantErr)
+				}
+				if last.Name == "zfs" && last.Args[0] == "set" {
+					t.Errorf("unexpected zfs set: %v", last.Args)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if last.Name != "zfs" || !slices.Equal(last.Args, tt.wantArgs) {
+				t.Errorf("command = %s %v, want zfs %v", last.Name, last.Args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestSetCompression_InvalidName(t *testing.T) {
+	for _, name := range []string{"", "-o", "tank/data;reboot"} {
+		exec := sysexec.NewMock()
+		m := &Manager{exec: exec}
+		if err := m.SetCompression(context.Background(), name, "lz4"); err == nil {
+			t.Errorf("SetCompression(%q) error = nil, want error", name)
+		}
+		if n := len(exec.Commands()); n != 0 {
+			t.Errorf("SetCompression(%q) ran %d commands, want 0", name, n)
+		}
+	}
+}
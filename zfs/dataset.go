@@ -1,8 +1,15 @@
 package zfs
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"maps"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
 
 	gozfs "github.com/mistifyio/go-zfs/v4"
 )
@@ -14,11 +21,23 @@ type CreateDatasetRequest struct {
 	UseCase    UseCaseTemplate   `json:"use_case"`   // template to apply
 	QuotaMode  string            `json:"quota_mode"` // "fixed", "flexible" (only for filesystem)
 	Quota      uint64            `json:"quota"`      // size/quota in bytes (required for volumes, optional for filesystems)
+	Sparse     bool              `json:"sparse"`     // volumes only: thin-provisioned, no refreservation
+	Mountpoint string            `json:"mountpoint"` // filesystems only: absolute path, "legacy" or "none"
+	CanMount   string            `json:"canmount"`   // filesystems only: "on", "off" or "noauto"
 	Properties map[string]string `json:"properties"` // optional ZFS properties (overrides template)
+
+	// Filesystems only: limit and guarantee for the dataset itself,
+	// excluding snapshots and descendants. Zero leaves them unset.
+	RefQuota       uint64 `json:"refquota,omitempty"`
+	RefReservation uint64 `json:"refreservation,omitempty"`
 }
 
 // CreateDataset creates a new ZFS dataset.
+//
+// Volumes are thick-provisioned by default: ZFS sets a refreservation
+// covering the full volume size. Set Sparse to create a thin volume.
 func (m *Manager) CreateDataset(ctx context.Context, req CreateDatasetRequest) error {
+	defer m.lockPool(req.Name)()
 	if req.Name == "" {
 		return fmt.Errorf("dataset name is required")
 	}
@@ -28,24 +47,30 @@ func (m *Manager) CreateDataset(ctx context.Context, req CreateDatasetRequest) e
 		req.Type = "filesystem"
 	}
 
-	// Apply use-case template properties
-	properties := GetTemplateProperties(req.UseCase)
+	if err := m.checkWritable(ctx, req.Name); err != nil {
+		return err
+	}
 
-	// Merge user-provided properties (overrides template)
-	for k, v := range req.Properties {
-		properties[k] = v
+	template, err := m.TemplateProperties(req.UseCase)
+	if err != nil {
+		return err
 	}
 
-	var err error
 	if req.Type == "volume" {
 		// For volumes, Quota is used as the volume size
 		if req.Quota == 0 {
 			return fmt.Errorf("quota (size) is required for volumes")
 		}
+		if req.Mountpoint != "" || req.CanMount != "" {
+			return fmt.Errorf("mountpoint and canmount apply only to filesystems")
+		}
+		if req.RefQuota != 0 || req.RefReservation != 0 {
+			return fmt.Errorf("refquota and refreservation apply only to filesystems; use sparse for thin volumes")
+		}
 
 		// Filter properties for volumes - some properties don't apply
 		volumeProps := make(map[string]string)
-		for k, v := range properties {
+		for k, v := range mergedProperties(template, req) {
 			switch k {
 			case "recordsize":
 				// Convert to volblocksize for volumes
@@ -57,19 +82,12 @@ func (m *Manager) CreateDataset(ctx context.Context, req CreateDatasetRequest) e
 			}
 		}
 
-		_, err = gozfs.CreateVolume(req.Name, req.Quota, volumeProps)
+		err = m.createVolume(ctx, req.Name, req.Quota, req.Sparse, volumeProps)
 	} else {
-		// For filesystems, apply quota if specified
-		if req.Quota > 0 {
-			if req.QuotaMode == "fixed" {
-				properties["reservation"] = fmt.Sprintf("%d", req.Quota)
-				properties["quota"] = fmt.Sprintf("%d", req.Quota)
-			} else {
-				// Flexible mode: only set quota, no reservation
-				properties["quota"] = fmt.Sprintf("%d", req.Quota)
-			}
+		properties, perr := filesystemProperties(template, req)
+		if perr != nil {
+			return perr
 		}
-
 		_, err = gozfs.CreateFilesystem(req.Name, properties)
 	}
 
@@ -80,6 +98,119 @@ func (m *Manager) CreateDataset(ctx context.Context, req CreateDatasetRequest) e
 	return nil
 }
 
+// mergedProperties returns the use-case template properties overridden by
+// the properties given in the request. The template map is modified.
+func mergedProperties(template map[string]string, req CreateDatasetRequest) map[string]string {
+	properties := template
+	for k, v := range req.Properties {
+		properties[k] = v
+	}
+	return properties
+}
+
+// filesystemProperties builds the creation properties for a filesystem:
+// template and user properties, quota and reservation, refquota and
+// refreservation, then mountpoint and canmount.
+func filesystemProperties(template map[string]string, req CreateDatasetRequest) (map[string]string, error) {
+	properties := mergedProperties(template, req)
+
+	if req.Quota > 0 {
+		if req.QuotaMode == "fixed" {
+			properties["reservation"] = fmt.Sprintf("%d", req.Quota)
+			properties["quota"] = fmt.Sprintf("%d", req.Quota)
+		} else {
+			// Flexible mode: only set quota, no reservation
+			properties["quota"] = fmt.Sprintf("%d", req.Quota)
+		}
+	}
+	if req.RefQuota > 0 {
+		properties["refquota"] = fmt.Sprintf("%d", req.RefQuota)
+	}
+	if req.RefReservation > 0 {
+		properties["refreservation"] = fmt.Sprintf("%d", req.RefReservation)
+	}
+
+	if req.Mountpoint != "" {
+		if err := validateMountpoint(req.Mountpoint); err != nil {
+			return nil, err
+		}
+		properties["mountpoint"] = req.Mountpoint
+	}
+	if req.CanMount != "" {
+		switch req.CanMount {
+		case "on", "off", "noauto":
+			properties["canmount"] = req.CanMount
+		default:
+			return nil, fmt.Errorf("invalid canmount %q: must be on, off or noauto", req.CanMount)
+		}
+	}
+	return properties, nil
+}
+
+// ErrMountpointOutsidePool is returned by CheckMountpointInPool for a
+// mountpoint outside the pool's mount root.
+var ErrMountpointOutsidePool = errors.New("mountpoint outside the pool")
+
+// PoolMountRoot returns the directory CreatePool mounts a pool at.
+func PoolMountRoot(pool string) string {
+	return path.Join("/mnt", pool)
+}
+
+// CheckMountpointInPool returns ErrMountpointOutsidePool unless every
+// mountpoint the request would set, through Mountpoint, Properties or its
+// use-case template, is legacy, none or a path under the PoolMountRoot of
+// the dataset's pool. The API applies it to non-admin users, so they
+// cannot mount a dataset over system directories.
+func (m *Manager) CheckMountpointInPool(req CreateDatasetRequest) error {
+	template, err := m.TemplateProperties(req.UseCase)
+	if err != nil {
+		return err
+	}
+	properties := mergedProperties(template, req)
+
+	root := PoolMountRoot(poolOf(req.Name))
+	for _, mp := range []string{req.Mountpoint, properties["mountpoint"]} {
+		if mp == "" || mp == "legacy" || mp == "none" {
+			continue
+		}
+		if clean := path.Clean(mp); clean != root && !strings.HasPrefix(clean, root+"/") {
+			return fmt.Errorf("%w: %s is not under %s", ErrMountpointOutsidePool, mp, root)
+		}
+	}
+	return nil
+}
+
+// validateMountpoint accepts an absolute path or the special values
+// "legacy" and "none".
+func validateMountpoint(mp string) error {
+	if mp == "legacy" || mp == "none" {
+		return nil
+	}
+	if !path.IsAbs(mp) {
+		return fmt.Errorf("invalid mountpoint %q: must be an absolute path, legacy or none", mp)
+	}
+	return nil
+}
+
+// createVolume runs zfs create -V. A sparse volume is created with -s and
+// has no refreservation; otherwise ZFS reserves the full size.
+func (m *Manager) createVolume(ctx context.Context, name string, size uint64, sparse bool, props map[string]string) error {
+	args := []string{"create", "-p"}
+	if sparse {
+		args = append(args, "-s")
+	}
+	args = append(args, "-V", strconv.FormatUint(size, 10))
+	for _, k := range slices.Sorted(maps.Keys(props)) {
+		args = append(args, "-o", k+"="+props[k])
+	}
+	args = append(args, name)
+
+	if out, err := m.runMutation(ctx, "zfs", args...); err != nil {
+		return fmt.Errorf("zfs create: %s: %w", bytes.TrimSpace(out), err)
+	}
+	return nil
+}
+
 // ListDatasets lists all datasets.
 func (m *Manager) ListDatasets(ctx context.Context) ([]Dataset, error) {
 	return m.listDatasets(ctx)
@@ -99,17 +230,22 @@ func (m *Manager) GetDataset(ctx context.Context, name string) (*Dataset, error)
 
 // DestroyDataset destroys a ZFS dataset.
 func (m *Manager) DestroyDataset(ctx context.Context, name string) error {
+	defer m.lockPool(name)()
 	if name == "" {
 		return fmt.Errorf("dataset name is required")
 	}
 
+	if err := m.checkWritable(ctx, name); err != nil {
+		return err
+	}
+
 	gozfsDataset, err := gozfs.GetDataset(name)
 	if err != nil {
 		return fmt.Errorf("dataset not found: %s: %w", name, err)
 	}
 
 	// Use DestroyRecursive flag to destroy recursively (including snapshots and children)
-	if err := gozfsDataset.Destroy(gozfs.DestroyRecursive); err != nil {
+	if err := m.retry(ctx, func() error { return gozfsDataset.Destroy(gozfs.DestroyRecursive) }); err != nil {
 		return fmt.Errorf("failed to destroy dataset: %w", err)
 	}
 
@@ -118,16 +254,21 @@ func (m *Manager) DestroyDataset(ctx context.Context, name string) error {
 
 // SetProperty sets a property on a dataset.
 func (m *Manager) SetProperty(ctx context.Context, name, key, value string) error {
+	defer m.lockPool(name)()
 	if name == "" || key == "" {
 		return fmt.Errorf("dataset name and property key are required")
 	}
 
+	if err := m.checkWritable(ctx, name); err != nil {
+		return err
+	}
+
 	gozfsDataset, err := gozfs.GetDataset(name)
 	if err != nil {
 		return fmt.Errorf("dataset not found: %s: %w", name, err)
 	}
 
-	if err := gozfsDataset.SetProperty(key, value); err != nil {
+	if err := m.retry(ctx, func() error { return gozfsDataset.SetProperty(key, value) }); err != nil {
 		return fmt.Errorf("failed to set property: %w", err)
 	}
 
@@ -139,12 +280,101 @@ func (m *Manager) SetQuota(ctx context.Context, name string, quota uint64) error
 	return m.SetProperty(ctx, name, "quota", fmt.Sprintf("%d", quota))
 }
 
+// SetRefQuota limits the space a dataset itself can reference, excluding
+// snapshots and descendants. ZFS refuses a refquota below the space the
+// dataset already references; that error is returned as is.
+func (m *Manager) SetRefQuota(ctx context.Context, name string, refquota uint64) error {
+	defer m.lockPool(name)()
+	if err := validateName(name); err != nil {
+		return err
+	}
+	if err := m.checkWritable(ctx, name); err != nil {
+		return err
+	}
+
+	if out, err := m.runMutation(ctx, "zfs", "set", fmt.Sprintf("refquota=%d", refquota), name); err != nil {
+		return fmt.Errorf("failed to set refquota: %s: %w", bytes.TrimSpace(out), err)
+	}
+	return nil
+}
+
+// ReservationMode selects which reservation property SetReservation sets.
+type ReservationMode string
+
+const (
+	// ReservationFull reserves space for the dataset and its descendants.
+	ReservationFull ReservationMode = "reservation"
+	// ReservationRef reserves space for the dataset itself, excluding
+	// snapshots and descendants.
+	ReservationRef ReservationMode = "refreservation"
+)
+
+// ErrReservationTooLarge is returned when a reservation exceeds the
+// pool's free space.
+var ErrReservationTooLarge = errors.New("reservation exceeds pool free space")
+
 // SetReservation sets a reservation on a dataset.
-func (m *Manager) SetReservation(ctx context.Context, name string, reservation uint64) error {
-	return m.SetProperty(ctx, name, "reservation", fmt.Sprintf("%d", reservation))
+// The increase over the current reservation must fit in the pool's free space.
+func (m *Manager) SetReservation(ctx context.Context, name string, mode ReservationMode, reservation uint64) error {
+	defer m.lockPool(name)()
+	if name == "" {
+		return fmt.Errorf("dataset name is required")
+	}
+	if mode == "" {
+		mode = ReservationFull
+	}
+	if mode != ReservationFull && mode != ReservationRef {
+		return fmt.Errorf("invalid reservation mode: %s", mode)
+	}
+
+	if err := m.checkWritable(ctx, name); err != nil {
+		return err
+	}
+
+	ds, err := m.GetDataset(ctx, name)
+	if err != nil {
+		return err
+	}
+	pool, err := m.GetPool(ctx, ds.Pool)
+	if err != nil {
+		return err
+	}
+
+	current := ds.Reservation
+	if mode == ReservationRef {
+		current = ds.RefReservation
+	}
+	if err := checkReservationFits(pool, current, reservation); err != nil {
+		return err
+	}
+
+	if out, err := m.runMutation(ctx, "zfs", "set", fmt.Sprintf("%s=%d", mode, reservation), name); err != nil {
+		return fmt.Errorf("failed to set %s: %s: %w", mode, bytes.TrimSpace(out), err)
+	}
+	return nil
+}
+
+// SetRefReservation sets the refreservation of a dataset; see
+// SetReservation.
+func (m *Manager) SetRefReservation(ctx context.Context, name string, refreservation uint64) error {
+	return m.SetReservation(ctx, name, ReservationRef, refreservation)
 }
 
-// GetTemplateProperties returns ZFS properties for a given use-case template.
+// checkReservationFits reports whether growing a reservation from current
+// to requested bytes fits in the pool's free space.
+func checkReservationFits(pool *Pool, current, requested uint64) error {
+	if requested <= current {
+		return nil
+	}
+	if need := requested - current; need > pool.Free {
+		return fmt.Errorf("%w: need %d bytes, pool %s has %d free", ErrReservationTooLarge, need, pool.Name, pool.Free)
+	}
+	return nil
+}
+
+// GetTemplateProperties returns ZFS properties for a built-in use-case
+// template, falling back to the general template for other names. Use
+// Manager.TemplateProperties to include custom templates.
 func GetTemplateProperties(useCase UseCaseTemplate) map[string]string {
 	switch useCase {
 	case UseCaseMedia:
@@ -182,3 +412,80 @@ func GetTemplateProperties(useCase UseCaseTemplate) map[string]string {
 		}
 	}
 }
+
+// PropDiff is a property whose value differs from its template.
+type PropDiff struct {
+	Current  string `json:"current"`
+	Expected string `json:"expected"`
+}
+
+// TemplateDrift compares a dataset's properties against a use-case
+// template and returns the properties that differ, keyed by name. For
+// volumes the template recordsize is compared against volblocksize, as in
+// CreateDataset.
+func (m *Manager) TemplateDrift(ctx context.Context, name string, useCase UseCaseTemplate) (map[string]PropDiff, error) {
+	if err := validateName(name); err != nil {
+		return nil, err
+	}
+
+	expected, err := m.TemplateProperties(useCase)
+	if err != nil {
+		return nil, err
+	}
+	keys := append(slices.Sorted(maps.Keys(expected)), "type", "volblocksize")
+	current, err := m.getProperties(ctx, name, false, keys...)
+	if err != nil {
+		return nil, fmt.Errorf("dataset not found: %s: %w", name, err)
+	}
+
+	drift := make(map[string]PropDiff)
+	for prop, want := range expected {
+		got := current[prop]
+		if prop == "recordsize" && current["type"] == string(DatasetVolume) {
+			got = current["volblocksize"]
+		}
+		if got != want {
+			drift[prop] = PropDiff{Current: got, Expected: want}
+		}
+	}
+	return drift, nil
+}
+
+// DestroyImpact counts the datasets and snapshots that destroying name
+// (a dataset or pool) would remove, including descendants.
+func (m *Manager) DestroyImpact(ctx context.Context, name string) (*DestroyImpact, error) {
+	if name == "" {
+		return nil, fmt.Errorf("dataset name is required")
+	}
+	if err := validateName(name); err != nil {
+		return nil, err
+	}
+
+	out, err := m.exec.Output(ctx, "zfs", "list", "-H", "-r", "-t", "filesystem,volume,snapshot", "-o", "type", name)
+	if err != nil {
+		return nil, fmt.Errorf("zfs list: %w", err)
+	}
+
+	var impact DestroyImpact
+	for line := range strings.Lines(string(out)) {
+		switch strings.TrimSpace(line) {
+		case "filesystem", "volume":
+			impact.Datasets++
+		case "snapshot":
+			impact.Snapshots++
+		}
+	}
+	return &impact, nil
+}
+
+// String describes the impact, e.g. "will destroy 3 datasets, 12 snapshots".
+func (i DestroyImpact) String() string {
+	return fmt.Sprintf("will destroy %s, %s", plural(i.Datasets, "dataset"), plural(i.Snapshots, "snapshot"))
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("1 %s", noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
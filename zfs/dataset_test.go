@@ -2,8 +2,13 @@ package zfs
 
 import (
 	"context"
+	"errors"
+	"maps"
+	"slices"
 	"strings"
 	"testing"
+
+	"go.aimuz.me/mynt/sysexec"
 )
 
 func TestCreateDataset_Validation(t *testing.T) {
@@ -22,6 +27,26 @@ func TestCreateDataset_Validation(t *testing.T) {
 			req:     CreateDatasetRequest{Name: "pool/volume2", Type: "volume"},
 			wantErr: "quota (size) is required for volumes",
 		},
+		{
+			name:    "relative_mountpoint",
+			req:     CreateDatasetRequest{Name: "pool/data", Mountpoint: "mnt/data"},
+			wantErr: "invalid mountpoint",
+		},
+		{
+			name:    "bare_word",
+			req:     CreateDatasetRequest{Name: "pool/data", Mountpoint: "off"},
+			wantErr: "invalid mountpoint",
+		},
+		{
+			name:    "bad_canmount",
+			req:     CreateDatasetRequest{Name: "pool/data", CanMount: "yes"},
+			wantErr: "invalid canmount",
+		},
+		{
+			name:    "volume_with_mountpoint",
+			req:     CreateDatasetRequest{Name: "pool/vol", Type: "volume", Quota: 1 << 20, Mountpoint: "/mnt/vol"},
+			wantErr: "apply only to filesystems",
+		},
 	}
 
 	m := NewManager()
@@ -88,3 +113,377 @@ func TestSetProperty_Validation(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckReservationFits(t *testing.T) {
+	pool := &Pool{Name: "tank", Free: 100}
+	tests := []struct {
+		name      string
+		current   uint64
+		requested uint64
+		wantErr   bool
+	}{
+		{"fits", 0, 100, false},
+		{"too_large", 0, 101, true},
+		{"growth_fits", 50, 150, false},
+		{"growth_too_large", 50, 151, true},
+		{"shrink", 500, 10, false},
+		{"clear", 500, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkReservationFits(pool, tt.current, tt.requested)
+			if tt.wantErr {
+				if !errors.Is(err, ErrReservationTooLarge) {
+					t.Errorf("error = %v, want ErrReservationTooLarge", err)
+				}
+			} else if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+const reservationDatasetJSON = `{"output_version":{},"datasets":{"tank/data":{"name":"tank/data","type":"FILESYSTEM","pool":"tank",
+"properties":{"reservation":{"value":"1000"},"refreservation":{"value":"0"}}}}}`
+
+const reservationPoolJSON = `{"output_version":{},"pools":{"tank":{"name":"tank","state":"ONLINE",
+"vdevs":{"tank":{"name":"tank","vdev_type":"root","total_space":"10000","alloc_space":"4000"}}}}}`
+
+func TestSetReservation_Args(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     ReservationMode
+		size     uint64
+		wantArgs []string
+		wantErr  error
+	}{
+		{
+			name:     "default_mode",
+			size:     5000,
+			wantArgs: []string{"set", "reservation=5000", "tank/data"},
+		},
+		{
+			name:     "refreservation",
+			mode:     ReservationRef,
+			size:     6000,
+			wantArgs: []string{"set", "refreservation=6000", "tank/data"},
+		},
+		{
+			// current reservation is 1000, so 7001 needs 6001 more than the 6000 free
+			name:    "reservation_too_large",
+			mode:    ReservationFull,
+			size:    7001,
+			wantErr: ErrReservationTooLarge,
+		},
+		{
+			name:    "refreservation_too_large",
+			mode:    ReservationRef,
+			size:    6001,
+			wantErr: ErrReservationTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exec := sysexec.NewMock()
+			exec.SetOutput("zfs", []byte(reservationDatasetJSON))
+			exec.SetOutput("zpool", []byte(reservationPoolJSON))
+			m := &Manager{exec: exec}
+
+			err := m.SetReservation(context.Background(), "tank/data", tt.mode, tt.size)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("error = %v, want %v", err, tt.wantErr)
+				}
+				for _, cmd := range exec.Commands() {
+					if cmd.Name == "zfs" && len(cmd.Args) > 0 && cmd.Args[0] == "set" {
+						t.Errorf("unexpected zfs set: %v", cmd.Args)
+					}
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			cmds := exec.Commands()
+			last := cmds[len(cmds)-1]
+			if last.Name != "zfs" || !slices.Equal(last.Args, tt.wantArgs) {
+				t.Errorf("command = %s %v, want zfs %v", last.Name, last.Args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestSetReservation_InvalidMode(t *testing.T) {
+	m := &Manager{exec: sysexec.NewMock()}
+	err := m.SetReservation(context.Background(), "tank/data", "bogus", 1)
+	if err == nil || !strings.Contains(err.Error(), "invalid reservation mode") {
+		t.Errorf("error = %v, want invalid reservation mode", err)
+	}
+}
+
+func TestSetRefQuota(t *testing.T) {
+	exec := sysexec.NewMock()
+	m := &Manager{exec: exec}
+
+	if err := m.SetRefQuota(context.Background(), "tank/data", 4096); err != nil {
+		t.Fatalf("SetRefQuota() error = %v", err)
+	}
+	want := []string{"set", "refquota=4096", "tank/data"}
+	if cmds := mutationCommands(t, exec); len(cmds) != 1 || !slices.Equal(cmds[0].Args, want) {
+		t.Errorf("commands = %v, want zfs %v", cmds, want)
+	}
+}
+
+func TestSetRefQuota_BelowUsed(t *testing.T) {
+	exec := sysexec.NewMock()
+	exec.SetError("zfs", errors.New("cannot set property for 'tank/data': size is less than current used or reserved space"))
+	m := &Manager{exec: exec}
+
+	err := m.SetRefQuota(context.Background(), "tank/data", 1)
+	if err == nil || !strings.Contains(err.Error(), "size is less than current used") {
+		t.Errorf("error = %v, want the zfs refusal", err)
+	}
+}
+
+func TestCreateDataset_VolumeRejectsRefQuota(t *testing.T) {
+	exec := sysexec.NewMock()
+	m := &Manager{exec: exec}
+
+	err := m.CreateDataset(context.Background(), CreateDatasetRequest{
+		Name: "tank/vol", Type: "volume", Quota: 1 << 20, RefQuota: 1 << 20,
+	})
+	if err == nil || !strings.Contains(err.Error(), "only to filesystems") {
+		t.Errorf("error = %v, want filesystems-only error", err)
+	}
+}
+
+func TestDestroyImpact(t *testing.T) {
+	exec := sysexec.NewMock()
+	exec.SetOutput("zfs", []byte("filesystem\nfilesystem\nvolume\nsnapshot\nsnapshot\n"))
+	m := &Manager{exec: exec}
+
+	impact, err := m.DestroyImpact(context.Background(), "tank/data")
+	if err != nil {
+		t.Fatalf("DestroyImpact() error = %v", err)
+	}
+	if impact.Datasets != 3 || impact.Snapshots != 2 {
+		t.Errorf("impact = %+v, want 3 datasets, 2 snapshots", impact)
+	}
+	if want := "will destroy 3 datasets, 2 snapshots"; impact.String() != want {
+		t.Errorf("String() = %q, want %q", impact.String(), want)
+	}
+
+	want := []string{"list", "-H", "-r", "-t", "filesystem,volume,snapshot", "-o", "type", "tank/data"}
+	if cmds := exec.Commands(); len(cmds) != 1 || !slices.Equal(cmds[0].Args, want) {
+		t.Errorf("commands = %v, want zfs %v", cmds, want)
+	}
+}
+
+func TestDestroyImpact_String(t *testing.T) {
+	if got, want := (DestroyImpact{Datasets: 1, Snapshots: 0}).String(), "will destroy 1 dataset, 0 snapshots"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestFilesystemProperties(t *testing.T) {
+	tests := []struct {
+		name string
+		req  CreateDatasetRequest
+		want map[string]string
+	}{
+		{
+			name: "inherit",
+			req:  CreateDatasetRequest{Name: "tank/data"},
+			want: map[string]string{},
+		},
+		{
+			name: "unmounted_container",
+			req:  CreateDatasetRequest{Name: "tank/parent", Mountpoint: "none", CanMount: "off"},
+			want: map[string]string{"mountpoint": "none", "canmount": "off"},
+		},
+		{
+			name: "explicit_path",
+			req:  CreateDatasetRequest{Name: "tank/media", Mountpoint: "/srv/media", CanMount: "noauto"},
+			want: map[string]string{"mountpoint": "/srv/media", "canmount": "noauto"},
+		},
+		{
+			name: "legacy_with_quota",
+			req:  CreateDatasetRequest{Name: "tank/data", Mountpoint: "legacy", Quota: 1024, QuotaMode: "fixed"},
+			want: map[string]string{"mountpoint": "legacy", "quota": "1024", "reservation": "1024"},
+		},
+		{
+			name: "refquota_and_refreservation",
+			req:  CreateDatasetRequest{Name: "tank/vm", RefQuota: 2048, RefReservation: 512},
+			want: map[string]string{"refquota": "2048", "refreservation": "512"},
+		},
+		{
+			name: "field_overrides_properties",
+			req: CreateDatasetRequest{
+				Name:       "tank/data",
+				Mountpoint: "/srv/data",
+				Properties: map[string]string{"mountpoint": "/mnt/other", "atime": "off"},
+			},
+			want: map[string]string{"mountpoint": "/srv/data", "atime": "off"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := filesystemProperties(GetTemplateProperties(tt.req.UseCase), tt.req)
+			if err != nil {
+				t.Fatalf("filesystemProperties: %v", err)
+			}
+			want := GetTemplateProperties(UseCaseGeneral)
+			maps.Copy(want, tt.want)
+			if !maps.Equal(got, want) {
+				t.Errorf("properties = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestCheckMountpointInPool(t *testing.T) {
+	tests := []struct {
+		name string
+		req  CreateDatasetRequest
+		ok   bool
+	}{
+		{"inherited", CreateDatasetRequest{Name: "tank/data"}, true},
+		{"under_pool", CreateDatasetRequest{Name: "tank/data", Mountpoint: "/mnt/tank/media"}, true},
+		{"pool_root", CreateDatasetRequest{Name: "tank/data", Mountpoint: "/mnt/tank"}, true},
+		{"legacy", CreateDatasetRequest{Name: "tank/data", Mountpoint: "legacy"}, true},
+		{"none", CreateDatasetRequest{Name: "tank/data", Mountpoint: "none"}, true},
+		{"system_dir", CreateDatasetRequest{Name: "tank/data", Mountpoint: "/etc"}, false},
+		{"other_pool", CreateDatasetRequest{Name: "tank/data", Mountpoint: "/mnt/backup/data"}, false},
+		{"sibling_prefix", CreateDatasetRequest{Name: "tank/data", Mountpoint: "/mnt/tanker"}, false},
+		{"dot_dot", CreateDatasetRequest{Name: "tank/data", Mountpoint: "/mnt/tank/../../etc"}, false},
+		{"property", CreateDatasetRequest{Name: "tank/data", Properties: map[string]string{"mountpoint": "/root"}}, false},
+		{"template", CreateDatasetRequest{Name: "tank/data", UseCase: "escape"}, false},
+		{"template_overridden", CreateDatasetRequest{Name: "tank/data", UseCase: "escape", Properties: map[string]string{"mountpoint": "/mnt/tank/data"}}, true},
+	}
+	m := &Manager{templates: staticTemplates{"escape": {"mountpoint": "/etc"}}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := m.CheckMountpointInPool(tt.req)
+			if tt.ok && err != nil {
+				t.Errorf("CheckMountpointInPool: %v", err)
+			}
+			if !tt.ok && !errors.Is(err, ErrMountpointOutsidePool) {
+				t.Errorf("error = %v, want ErrMountpointOutsidePool", err)
+			}
+		})
+	}
+}
+
+func TestCreateDataset_VolumeProvisioning(t *testing.T) {
+	tests := []struct {
+		name     string
+		sparse   bool
+		wantArgs []string
+	}{
+		{
+			name:     "thick",
+			wantArgs: []string{"create", "-p", "-V", "10485760", "-o", "compression=lz4", "-o", "volblocksize=16K", "tank/vol"},
+		},
+		{
+			name:     "sparse",
+			sparse:   true,
+			wantArgs: []string{"create", "-p", "-s", "-V", "10485760", "-o", "compression=lz4", "-o", "volblocksize=16K", "tank/vol"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exec := sysexec.NewMock()
+			m := &Manager{exec: exec}
+
+			err := m.CreateDataset(context.Background(), CreateDatasetRequest{
+				Name:       "tank/vol",
+				Type:       "volume",
+				Quota:      10 * 1024 * 1024,
+				Sparse:     tt.sparse,
+				Properties: map[string]string{"recordsize": "16K", "compression": "lz4", "quota": "1G"},
+			})
+			if err != nil {
+				t.Fatalf("CreateDataset: %v", err)
+			}
+
+			cmds := mutationCommands(t, exec)
+			if len(cmds) != 1 {
+				t.Fatalf("got %d commands, want 1", len(cmds))
+			}
+			if !slices.Equal(cmds[0].Args, tt.wantArgs) {
+				t.Errorf("args = %v, want %v", cmds[0].Args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestSetNote(t *testing.T) {
+	tests := []struct {
+		name     string
+		note     string
+		wantArgs []string
+	}{
+		{"set", "do not enable dedup", []string{"set", "mynt:note=do not enable dedup", "tank/db"}},
+		{"clear", "", []string{"inherit", "mynt:note", "tank/db"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exec := sysexec.NewMock()
+			m := &Manager{exec: exec}
+
+			if err := m.SetNote(context.Background(), "tank/db", tt.note); err != nil {
+				t.Fatalf("SetNote: %v", err)
+			}
+			cmds := mutationCommands(t, exec)
+			if len(cmds) != 1 || !slices.Equal(cmds[0].Args, tt.wantArgs) {
+				t.Errorf("commands = %v, want zfs %v", cmds, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestTemplateDrift(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   map[string]PropDiff
+	}{
+		{
+			name:   "changed_recordsize",
+			output: "compression\tlz4\nlogbias\tlatency\nrecordsize\t128K\nsync\talways\ntype\tfilesystem\nvolblocksize\t-\n",
+			want:   map[string]PropDiff{"recordsize": {Current: "128K", Expected: "16K"}},
+		},
+		{
+			name:   "volume_uses_volblocksize",
+			output: "compression\tlz4\nlogbias\tlatency\nrecordsize\t-\nsync\talways\ntype\tvolume\nvolblocksize\t16K\n",
+			want:   map[string]PropDiff{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exec := sysexec.NewMock()
+			exec.SetOutput("zfs", []byte(tt.output))
+			m := &Manager{exec: exec}
+
+			got, err := m.TemplateDrift(context.Background(), "tank/db", UseCaseDatabase)
+			if err != nil {
+				t.Fatalf("TemplateDrift: %v", err)
+			}
+			if !maps.Equal(got, tt.want) {
+				t.Errorf("drift = %v, want %v", got, tt.want)
+			}
+
+			wantArgs := []string{"get", "-H", "-o", "property,value", "compression,logbias,recordsize,sync,type,volblocksize", "tank/db"}
+			if cmds := exec.Commands(); len(cmds) != 1 || !slices.Equal(cmds[0].Args, wantArgs) {
+				t.Errorf("commands = %v, want zfs %v", cmds, wantArgs)
+			}
+		})
+	}
+}
@@ -0,0 +1,214 @@
+package zfs
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DiffEntry is one path changed between a snapshot and a later state of
+// the dataset, as reported by zfs diff.
+type DiffEntry struct {
+	Change   string `json:"change"`    // added, removed, modified or renamed
+	FileType string `json:"file_type"` // file, directory, symlink, ...
+	Path     string `json:"path"`
+	NewPath  string `json:"new_path,omitempty"` // renames only
+}
+
+// ErrNoSnapshots is returned when a dataset has no snapshot to compare
+// against.
+var ErrNoSnapshots = errors.New("dataset has no snapshots to compare against")
+
+// ChangesSinceLatest returns what changed in the live dataset since its
+// newest snapshot. Like SnapshotDiff it stops at the entry limit and
+// returns the entries so far along with ErrDiffTruncated.
+func (m *Manager) ChangesSinceLatest(ctx context.Context, dataset string) ([]DiffEntry, error) {
+	snapshots, err := m.ListSnapshots(ctx, dataset)
+	if err != nil {
+		return nil, err
+	}
+	if len(snapshots) == 0 {
+		return nil, fmt.Errorf("%s: %w", dataset, ErrNoSnapshots)
+	}
+	latest := snapshots[len(snapshots)-1].Name
+
+	return m.streamDiff(ctx, latest, dataset)
+}
+
+// DefaultMaxDiffEntries is how many entries a diff returns unless
+// WithMaxDiffEntries sets another limit.
+const DefaultMaxDiffEntries = 10000
+
+// WithMaxDiffEntries caps the number of entries SnapshotDiff and
+// ChangesSinceLatest collect, so that diffing a huge tree cannot exhaust
+// memory. Zero or
+// less means DefaultMaxDiffEntries.
+func WithMaxDiffEntries(n int) ManagerOption {
+	return func(m *Manager) {
+		m.maxDiffEntries = n
+	}
+}
+
+// ErrDiffTruncated is returned with the first entries of a diff that had
+// more than the configured maximum.
+var ErrDiffTruncated = errors.New("diff truncated")
+
+// SnapshotDiff returns what changed between snapshot from and the later
+// snapshot to of the same dataset, stopping at the entry limit with
+// ErrDiffTruncated.
+func (m *Manager) SnapshotDiff(ctx context.Context, from, to string) ([]DiffEntry, error) {
+	if err := validateNames(from, to); err != nil {
+		return nil, err
+	}
+	if !strings.Contains(from, "@") || !strings.Contains(to, "@") {
+		return nil, fmt.Errorf("both %q and %q must be snapshots", from, to)
+	}
+	return m.streamDiff(ctx, from, to)
+}
+
+// streamDiff runs zfs diff between from and to, parsing its output as it
+// streams in. Once the entry limit is reached zfs is stopped and the
+// entries so far are returned along with ErrDiffTruncated.
+func (m *Manager) streamDiff(ctx context.Context, from, to string) ([]DiffEntry, error) {
+	limit := m.maxDiffEntries
+	if limit <= 0 {
+		limit = DefaultMaxDiffEntries
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	c := &diffCollector{limit: limit, entries: []DiffEntry{}}
+
+	err := m.exec.Stream(ctx, c, "zfs", "diff", "-H", "-F", from, to)
+	if err == nil {
+		c.flush()
+	}
+	if c.truncated {
+		return c.entries, fmt.Errorf("%w: showing the first %d entries", ErrDiffTruncated, limit)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("zfs diff %s %s: %w", from, to, err)
+	}
+	return c.entries, nil
+}
+
+// errDiffLimit stops the zfs diff stream once diffCollector is full.
+var errDiffLimit = errors.New("diff entry limit reached")
+
+// diffCollector parses zfs diff output line by line as it is written,
+// keeping at most limit entries.
+type diffCollector struct {
+	limit     int
+	entries   []DiffEntry
+	partial   []byte // incomplete last line
+	truncated bool
+}
+
+func (c *diffCollector) Write(p []byte) (int, error) {
+	if c.truncated {
+		return 0, errDiffLimit
+	}
+	c.partial = append(c.partial, p...)
+	for {
+		i := bytes.IndexByte(c.partial, '\n')
+		if i < 0 {
+			break
+		}
+		line := string(c.partial[:i])
+		c.partial = c.partial[i+1:]
+		if !c.add(line) {
+			return 0, errDiffLimit
+		}
+	}
+	return len(p), nil
+}
+
+// flush parses a final line that had no trailing newline.
+func (c *diffCollector) flush() {
+	if len(c.partial) > 0 {
+		c.add(string(c.partial))
+		c.partial = nil
+	}
+}
+
+// add parses line and appends it, reporting false once the limit has been
+// exceeded.
+func (c *diffCollector) add(line string) bool {
+	e, ok := parseDiffLine(line)
+	if !ok {
+		return true
+	}
+	if len(c.entries) >= c.limit {
+		c.truncated = true
+		return false
+	}
+	c.entries = append(c.entries, e)
+	return true
+}
+
+var diffChanges = map[string]string{
+	"+": "added",
+	"-": "removed",
+	"M": "modified",
+	"R": "renamed",
+}
+
+var diffFileTypes = map[string]string{
+	"F": "file",
+	"/": "directory",
+	"@": "symlink",
+	"B": "block_device",
+	"C": "char_device",
+	"|": "fifo",
+	"=": "socket",
+	">": "door",
+	"P": "event_port",
+}
+
+// parseDiffLine parses one line of `zfs diff -H -F` output: change, file
+// type, path and, for renames, the new path, separated by tabs. Unknown
+// lines are skipped.
+func parseDiffLine(line string) (DiffEntry, bool) {
+	fields := strings.Split(strings.TrimRight(line, "\n"), "\t")
+	if len(fields) < 3 {
+		return DiffEntry{}, false
+	}
+	change, ok := diffChanges[fields[0]]
+	if !ok {
+		return DiffEntry{}, false
+	}
+	e := DiffEntry{
+		Change:   change,
+		FileType: diffFileTypes[fields[1]],
+		Path:     unescapeDiffPath(fields[2]),
+	}
+	if e.FileType == "" {
+		e.FileType = "unknown"
+	}
+	if len(fields) > 3 {
+		e.NewPath = unescapeDiffPath(fields[3])
+	}
+	return e, true
+}
+
+// unescapeDiffPath decodes the \0NNN octal escapes zfs diff uses for
+// spaces and non-printable bytes in paths.
+func unescapeDiffPath(p string) string {
+	if !strings.Contains(p, `\`) {
+		return p
+	}
+	var b strings.Builder
+	for i := 0; i < len(p); i++ {
+		if p[i] == '\\' && i+5 <= len(p) {
+			if n, err := strconv.ParseUint(p[i+1:i+5], 8, 8); err == nil {
+				b.WriteByte(byte(n))
+				i += 4
+				continue
+			}
+		}
+		b.WriteByte(p[i])
+	}
+	return b.String()
+}
@@ -0,0 +1,165 @@
+package zfs
+
+import (
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"slices"
+	"testing"
+
+	"go.aimuz.me/mynt/sysexec"
+)
+
+// diffExec answers zfs list and zfs diff with different canned output.
+type diffExec struct {
+	*sysexec.MockExecutor
+	list, diff string
+}
+
+func (e diffExec) Output(ctx context.Context, name string, args ...string) ([]byte, error) {
+	e.MockExecutor.Output(ctx, name, args...) // record the command
+	return []byte(e.list), nil
+}
+
+func (e diffExec) Stream(ctx context.Context, w io.Writer, name string, args ...string) error {
+	e.MockExecutor.Output(ctx, name, args...) // record the command
+	_, err := io.WriteString(w, e.diff)
+	return err
+}
+
+const twoSnapshotsJSON = `{"output_version":{},"datasets":{
+"tank/data@new":{"name":"tank/data@new","type":"SNAPSHOT","pool":"tank","properties":{"creation":{"value":"1700000100"}}},
+"tank/data@old":{"name":"tank/data@old","type":"SNAPSHOT","pool":"tank","properties":{"creation":{"value":"1700000000"}}}}}`
+
+func TestChangesSinceLatest(t *testing.T) {
+	exec := diffExec{
+		MockExecutor: sysexec.NewMock(),
+		list:         twoSnapshotsJSON,
+		diff: "M\t/\t/tank/data/docs\n" +
+			"+\tF\t/tank/data/docs/new\\0040file.txt\n" +
+			"-\tF\t/tank/data/old.log\n" +
+			"R\tF\t/tank/data/a.txt\t/tank/data/b.txt\n",
+	}
+	m := &Manager{exec: exec}
+
+	got, err := m.ChangesSinceLatest(context.Background(), "tank/data")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []DiffEntry{
+		{Change: "modified", FileType: "directory", Path: "/tank/data/docs"},
+		{Change: "added", FileType: "file", Path: "/tank/data/docs/new file.txt"},
+		{Change: "removed", FileType: "file", Path: "/tank/data/old.log"},
+		{Change: "renamed", FileType: "file", Path: "/tank/data/a.txt", NewPath: "/tank/data/b.txt"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("entries = %+v, want %+v", got, want)
+	}
+
+	cmds := exec.Commands()
+	last := cmds[len(cmds)-1]
+	if wantArgs := []string{"diff", "-H", "-F", "tank/data@new", "tank/data"}; !slices.Equal(last.Args, wantArgs) {
+		t.Errorf("diff args = %v, want %v", last.Args, wantArgs)
+	}
+}
+
+func TestChangesSinceLatest_Truncated(t *testing.T) {
+	exec := diffExec{MockExecutor: sysexec.NewMock(), list: twoSnapshotsJSON, diff: snapshotDiffOutput}
+	m := &Manager{exec: exec, maxDiffEntries: 3}
+
+	got, err := m.ChangesSinceLatest(context.Background(), "tank/data")
+	if !errors.Is(err, ErrDiffTruncated) {
+		t.Fatalf("error = %v, want ErrDiffTruncated", err)
+	}
+	if len(got) != 3 {
+		t.Errorf("entries = %+v, want the first 3", got)
+	}
+}
+
+func TestChangesSinceLatest_NoSnapshots(t *testing.T) {
+	exec := diffExec{MockExecutor: sysexec.NewMock(), list: `{"output_version":{},"datasets":{}}`}
+	m := &Manager{exec: exec}
+
+	if _, err := m.ChangesSinceLatest(context.Background(), "tank/data"); !errors.Is(err, ErrNoSnapshots) {
+		t.Fatalf("error = %v, want ErrNoSnapshots", err)
+	}
+	for _, cmd := range exec.Commands() {
+		if cmd.Args[0] == "diff" {
+			t.Errorf("ran zfs diff without a snapshot: %v", cmd.Args)
+		}
+	}
+}
+
+// chunkedExec streams its output a few bytes at a time, stopping at the
+// first write error like a real pipe would.
+type chunkedExec struct {
+	*sysexec.MockExecutor
+	out string
+}
+
+func (e chunkedExec) Stream(ctx context.Context, w io.Writer, name string, args ...string) error {
+	e.MockExecutor.Output(ctx, name, args...) // record the command
+	for b := []byte(e.out); len(b) > 0; {
+		n := min(5, len(b))
+		if _, err := w.Write(b[:n]); err != nil {
+			return err
+		}
+		b = b[n:]
+	}
+	return nil
+}
+
+const snapshotDiffOutput = "M\t/\t/tank/data/docs\n" +
+	"+\tF\t/tank/data/docs/new.txt\n" +
+	"R\tF\t/tank/data/a.txt\t/tank/data/b.txt\n" +
+	"-\tF\t/tank/data/old.log"
+
+func TestSnapshotDiff(t *testing.T) {
+	exec := chunkedExec{MockExecutor: sysexec.NewMock(), out: snapshotDiffOutput}
+	m := &Manager{exec: exec}
+
+	got, err := m.SnapshotDiff(context.Background(), "tank/data@old", "tank/data@new")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []DiffEntry{
+		{Change: "modified", FileType: "directory", Path: "/tank/data/docs"},
+		{Change: "added", FileType: "file", Path: "/tank/data/docs/new.txt"},
+		{Change: "renamed", FileType: "file", Path: "/tank/data/a.txt", NewPath: "/tank/data/b.txt"},
+		{Change: "removed", FileType: "file", Path: "/tank/data/old.log"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("entries = %+v, want %+v", got, want)
+	}
+
+	cmds := exec.Commands()
+	if wantArgs := []string{"diff", "-H", "-F", "tank/data@old", "tank/data@new"}; len(cmds) != 1 || !slices.Equal(cmds[0].Args, wantArgs) {
+		t.Errorf("commands = %v, want zfs %v", cmds, wantArgs)
+	}
+}
+
+func TestSnapshotDiff_Truncated(t *testing.T) {
+	exec := chunkedExec{MockExecutor: sysexec.NewMock(), out: snapshotDiffOutput}
+	m := &Manager{exec: exec, maxDiffEntries: 2}
+
+	got, err := m.SnapshotDiff(context.Background(), "tank/data@old", "tank/data@new")
+	if !errors.Is(err, ErrDiffTruncated) {
+		t.Fatalf("error = %v, want ErrDiffTruncated", err)
+	}
+	if len(got) != 2 || got[1].Path != "/tank/data/docs/new.txt" {
+		t.Errorf("entries = %+v, want the first 2", got)
+	}
+}
+
+func TestSnapshotDiff_RequiresSnapshots(t *testing.T) {
+	m := &Manager{exec: sysexec.NewMock()}
+	if _, err := m.SnapshotDiff(context.Background(), "tank/data@old", "tank/data"); err == nil {
+		t.Error("expected error when to is not a snapshot")
+	}
+	if _, err := m.SnapshotDiff(context.Background(), "tank/data@old", "tank/data@new;rm"); err == nil {
+		t.Error("expected error for an invalid name")
+	}
+}
@@ -0,0 +1,113 @@
+package zfs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// ErrExecNotAllowed is returned when a passthrough command is rejected
+// before it runs.
+var ErrExecNotAllowed = errors.New("command not allowed")
+
+// execTimeout bounds passthrough commands, some of which (iostat with an
+// interval, events -f) would otherwise run forever.
+const execTimeout = 30 * time.Second
+
+// execSubcommands lists the subcommands allowed per binary. Stream
+// commands (send, receive) and the operations mynt wraps with its own
+// safeguards (pool create/destroy/import/export and property changes,
+// dataset destroy, property changes, snapshot, rollback, clone and
+// rename) are deliberately absent: those take the pool lock and refuse a
+// read-only pool, which passthrough commands do not.
+var execSubcommands = map[string]map[string]bool{
+	"zfs": {
+		"list": true, "get": true, "promote": true, "bookmark": true,
+		"hold": true, "holds": true, "release": true, "diff": true,
+		"mount": true, "unmount": true, "userspace": true,
+		"groupspace": true, "projectspace": true, "version": true,
+	},
+	"zpool": {
+		"list": true, "status": true, "get": true, "iostat": true,
+		"history": true, "events": true, "scrub": true, "trim": true,
+		"clear": true, "online": true, "offline": true, "reopen": true,
+		"sync": true, "checkpoint": true, "version": true,
+	},
+}
+
+// ExecRequest is a raw zfs or zpool invocation. Arguments are passed to
+// the binary directly and never through a shell.
+type ExecRequest struct {
+	Command string   `json:"command"` // "zfs" or "zpool"
+	Args    []string `json:"args"`    // subcommand followed by its arguments
+}
+
+// ExecResult holds the outcome of a passthrough command.
+type ExecResult struct {
+	Stdout   string `json:"stdout"`
+	Stderr   string `json:"stderr"` // only captured when the command fails
+	ExitCode int    `json:"exit_code"`
+}
+
+// Exec runs an allowlisted zfs or zpool subcommand. A non-zero exit is
+// reported in the result rather than as an error.
+func (m *Manager) Exec(ctx context.Context, req ExecRequest) (*ExecResult, error) {
+	if err := validateExec(req); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, execTimeout)
+	defer cancel()
+
+	out, err := m.exec.Output(ctx, req.Command, req.Args...)
+	res := &ExecResult{Stdout: string(out)}
+	if err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			return nil, fmt.Errorf("%s: %w", req.Command, err)
+		}
+		res.Stderr = string(exitErr.Stderr)
+		res.ExitCode = exitErr.ExitCode()
+	}
+	return res, nil
+}
+
+// validateExec checks the binary and subcommand against the allowlist and
+// every argument against the characters allowed in ZFS names, plus '='
+// and ',' for property assignments and lists and leading dashes for
+// options.
+func validateExec(req ExecRequest) error {
+	subcommands, ok := execSubcommands[req.Command]
+	if !ok {
+		return fmt.Errorf("%w: binary %q", ErrExecNotAllowed, req.Command)
+	}
+	if len(req.Args) == 0 {
+		return fmt.Errorf("%w: subcommand required", ErrExecNotAllowed)
+	}
+	if !subcommands[req.Args[0]] {
+		return fmt.Errorf("%w: %s %s", ErrExecNotAllowed, req.Command, req.Args[0])
+	}
+	for _, arg := range req.Args[1:] {
+		if err := validateExecArg(arg); err != nil {
+			return fmt.Errorf("%w: %v", ErrExecNotAllowed, err)
+		}
+	}
+	return nil
+}
+
+// execArgSeparators strips the characters arguments may use beyond those
+// allowed in names.
+var execArgSeparators = strings.NewReplacer("=", "", ",", "")
+
+func validateExecArg(arg string) error {
+	if arg == "" {
+		return fmt.Errorf("empty argument")
+	}
+	if err := validateName(strings.TrimLeft(execArgSeparators.Replace(arg), "-")); err != nil {
+		return fmt.Errorf("argument %q: %v", arg, err)
+	}
+	return nil
+}
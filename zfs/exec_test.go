@@ -0,0 +1,76 @@
+package zfs
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"go.aimuz.me/mynt/sysexec"
+)
+
+func TestExec_Rejected(t *testing.T) {
+	tests := []struct {
+		name string
+		req  ExecRequest
+	}{
+		{"other_binary", ExecRequest{Command: "sh", Args: []string{"-c", "id"}}},
+		{"path_to_binary", ExecRequest{Command: "/sbin/zfs", Args: []string{"list"}}},
+		{"no_subcommand", ExecRequest{Command: "zfs"}},
+		{"stream_subcommand", ExecRequest{Command: "zfs", Args: []string{"send", "tank@snap"}}},
+		{"wrapped_subcommand", ExecRequest{Command: "zpool", Args: []string{"destroy", "tank"}}},
+		{"wrapped_rollback", ExecRequest{Command: "zfs", Args: []string{"rollback", "tank/data@snap"}}},
+		{"wrapped_rename", ExecRequest{Command: "zfs", Args: []string{"rename", "tank/a", "tank/b"}}},
+		{"wrapped_set", ExecRequest{Command: "zfs", Args: []string{"set", "readonly=off", "tank/data"}}},
+		{"wrapped_pool_set", ExecRequest{Command: "zpool", Args: []string{"set", "autotrim=on", "tank"}}},
+		{"subcommand_of_other_binary", ExecRequest{Command: "zfs", Args: []string{"scrub", "tank"}}},
+		{"shell_metacharacters", ExecRequest{Command: "zfs", Args: []string{"list", "tank;reboot"}}},
+		{"embedded_space", ExecRequest{Command: "zfs", Args: []string{"list", "tank data"}}},
+		{"empty_argument", ExecRequest{Command: "zpool", Args: []string{"status", ""}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exec := sysexec.NewMock()
+			m := &Manager{exec: exec}
+
+			_, err := m.Exec(context.Background(), tt.req)
+			if !errors.Is(err, ErrExecNotAllowed) {
+				t.Fatalf("Exec() error = %v, want ErrExecNotAllowed", err)
+			}
+			if n := len(exec.Commands()); n != 0 {
+				t.Errorf("ran %d commands, want 0", n)
+			}
+		})
+	}
+}
+
+func TestExec_Allowed(t *testing.T) {
+	exec := sysexec.NewMock()
+	exec.SetOutput("zfs", []byte("tank/data\tcompression\tlz4\tlocal\n"))
+	m := &Manager{exec: exec}
+
+	args := []string{"get", "-H", "-o", "name,property,value,source", "compression", "tank/data"}
+	res, err := m.Exec(context.Background(), ExecRequest{Command: "zfs", Args: args})
+	if err != nil {
+		t.Fatalf("Exec: %v", err)
+	}
+	if res.Stdout != "tank/data\tcompression\tlz4\tlocal\n" || res.ExitCode != 0 {
+		t.Errorf("result = %+v", res)
+	}
+
+	cmds := exec.Commands()
+	if len(cmds) != 1 || cmds[0].Name != "zfs" || !slices.Equal(cmds[0].Args, args) {
+		t.Errorf("commands = %+v, want zfs %v", cmds, args)
+	}
+}
+
+func TestExec_CommandError(t *testing.T) {
+	exec := sysexec.NewMock()
+	exec.SetError("zpool", errors.New("executable file not found"))
+	m := &Manager{exec: exec}
+
+	if _, err := m.Exec(context.Background(), ExecRequest{Command: "zpool", Args: []string{"status"}}); err == nil {
+		t.Fatal("Exec() error = nil, want error")
+	}
+}
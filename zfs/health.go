@@ -0,0 +1,113 @@
+package zfs
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// AssessHealth summarizes how close a pool is to losing data and what to
+// do about it, including warnings about risky vdev layouts.
+func AssessHealth(p Pool) PoolHealth {
+	h := PoolHealth{
+		Status:      p.Health,
+		CanLoseMore: p.Redundancy,
+	}
+
+	switch {
+	case p.Health == PoolFaulted || p.Health == PoolUnavail || p.Health == PoolOffline:
+		h.RiskLevel = "critical"
+		h.RiskDescription = "Pool is unavailable and its data cannot be accessed."
+		h.Recommendation = "Reconnect or replace the failed disks and check zpool status."
+	case p.Health == PoolDegraded && p.Redundancy == 0:
+		h.RiskLevel = "critical"
+		h.RiskDescription = "Pool is degraded with no redundancy left; one more disk failure loses data."
+		h.Recommendation = "Replace the failed disk immediately."
+	case p.Health == PoolDegraded:
+		h.RiskLevel = "high"
+		h.RiskDescription = fmt.Sprintf("Pool is degraded but can survive %d more disk failure(s).", p.Redundancy)
+		h.Recommendation = "Replace the failed disk soon."
+	case p.Redundancy == 0:
+		h.RiskLevel = "medium"
+		h.RiskDescription = "Pool has no redundancy; any disk failure loses data."
+		h.Recommendation = "Keep backups, or attach disks to turn each vdev into a mirror."
+	default:
+		h.RiskLevel = "low"
+		h.RiskDescription = fmt.Sprintf("Pool is healthy and can survive %d disk failure(s).", p.Redundancy)
+		h.Recommendation = "No action needed."
+	}
+
+	// Only for healthy pools, so that it does not dilute disk replacement advice
+	if !p.Autotrim && p.Health == PoolOnline {
+		note := "Autotrim is off, so SSDs only get freed space trimmed by the monthly trim or a manual one; turn it on for SSD pools."
+		if h.RiskLevel == "low" {
+			h.Recommendation = note
+		} else {
+			h.Recommendation += " " + note
+		}
+	}
+
+	if warning := vdevLayoutWarning(p.VDevs); warning != "" {
+		h.RiskDescription += " " + warning
+		if h.RiskLevel == "low" {
+			h.RiskLevel = "medium"
+		}
+	}
+	return h
+}
+
+// vdevLayoutWarning describes a pool whose data vdevs mix types (e.g. a
+// mirror and a raidz) or, for the same type, widths. Such pools are only
+// as reliable as their weakest vdev and cannot be fixed without
+// recreating the pool.
+func vdevLayoutWarning(vdevs []VDevDetail) string {
+	if len(vdevs) < 2 {
+		return ""
+	}
+
+	var types []string
+	widths := make(map[string][]int)
+	for _, v := range vdevs {
+		if !slices.Contains(types, v.Type) {
+			types = append(types, v.Type)
+		}
+		w := vdevWidth(v)
+		if !slices.Contains(widths[v.Type], w) {
+			widths[v.Type] = append(widths[v.Type], w)
+		}
+	}
+
+	if len(types) > 1 {
+		return fmt.Sprintf("Pool mixes vdev types (%s); its redundancy is limited by the weakest vdev.",
+			strings.Join(types, ", "))
+	}
+	if w := widths[types[0]]; len(w) > 1 {
+		slices.Sort(w)
+		parts := make([]string, len(w))
+		for i, n := range w {
+			parts[i] = strconv.Itoa(n)
+		}
+		return fmt.Sprintf("Pool has %s vdevs of different widths (%s disks), so space and performance are uneven.",
+			types[0], strings.Join(parts, ", "))
+	}
+	return ""
+}
+
+// vdevWidth counts the disk slots in a vdev. A disk being replaced
+// appears twice (old and new), so replacing pairs count once, and an
+// active hot spare shares the slot of the disk it stands in for.
+func vdevWidth(v VDevDetail) int {
+	width, replacing := 0, 0
+	for _, d := range v.Children {
+		if d.Spare {
+			continue
+		}
+		if d.Replacing {
+			replacing++
+		} else {
+			width++
+		}
+	}
+	return width + (replacing+1)/2
+}
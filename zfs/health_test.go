@@ -0,0 +1,118 @@
+package zfs
+
+import (
+	"strings"
+	"testing"
+)
+
+func vdev(typ string, disks int) VDevDetail {
+	v := VDevDetail{Type: typ}
+	for range disks {
+		v.Children = append(v.Children, DiskDetail{Status: "ONLINE"})
+	}
+	return v
+}
+
+func TestAssessHealth_MixedVDevTypes(t *testing.T) {
+	p := Pool{
+		Health:     PoolOnline,
+		Redundancy: 1,
+		VDevs:      []VDevDetail{vdev("mirror", 2), vdev("raidz", 3)},
+	}
+
+	h := AssessHealth(p)
+	if !strings.Contains(h.RiskDescription, "mixes vdev types (mirror, raidz)") {
+		t.Errorf("RiskDescription = %q, want mixed-type warning", h.RiskDescription)
+	}
+	if h.RiskLevel != "medium" {
+		t.Errorf("RiskLevel = %q, want medium", h.RiskLevel)
+	}
+}
+
+func TestAssessHealth(t *testing.T) {
+	tests := []struct {
+		name      string
+		pool      Pool
+		wantLevel string
+		wantWarn  string
+	}{
+		{
+			name:      "uniform mirrors",
+			pool:      Pool{Health: PoolOnline, Redundancy: 1, VDevs: []VDevDetail{vdev("mirror", 2), vdev("mirror", 2)}},
+			wantLevel: "low",
+		},
+		{
+			name:      "raidz widths differ",
+			pool:      Pool{Health: PoolOnline, Redundancy: 1, VDevs: []VDevDetail{vdev("raidz", 5), vdev("raidz", 3)}},
+			wantLevel: "medium",
+			wantWarn:  "different widths (3, 5 disks)",
+		},
+		{
+			name:      "raidz and raidz2",
+			pool:      Pool{Health: PoolOnline, Redundancy: 1, VDevs: []VDevDetail{vdev("raidz", 4), vdev("raidz2", 4)}},
+			wantLevel: "medium",
+			wantWarn:  "mixes vdev types",
+		},
+		{
+			name: "replacing disk keeps width",
+			pool: Pool{Health: PoolOnline, Redundancy: 1, VDevs: []VDevDetail{
+				vdev("mirror", 2),
+				{Type: "mirror", Children: []DiskDetail{{}, {Replacing: true}, {Replacing: true}}},
+			}},
+			wantLevel: "low",
+		},
+		{
+			name:      "degraded mixed stays critical",
+			pool:      Pool{Health: PoolDegraded, VDevs: []VDevDetail{vdev("mirror", 2), vdev("stripe", 1)}},
+			wantLevel: "critical",
+			wantWarn:  "mixes vdev types (mirror, stripe)",
+		},
+		{
+			name:      "faulted",
+			pool:      Pool{Health: PoolFaulted},
+			wantLevel: "critical",
+		},
+		{
+			name:      "no redundancy",
+			pool:      Pool{Health: PoolOnline, VDevs: []VDevDetail{vdev("stripe", 1)}},
+			wantLevel: "medium",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := AssessHealth(tt.pool)
+			if h.RiskLevel != tt.wantLevel {
+				t.Errorf("RiskLevel = %q, want %q", h.RiskLevel, tt.wantLevel)
+			}
+			if tt.wantWarn != "" && !strings.Contains(h.RiskDescription, tt.wantWarn) {
+				t.Errorf("RiskDescription = %q, want it to contain %q", h.RiskDescription, tt.wantWarn)
+			}
+			if tt.wantWarn == "" && strings.Contains(h.RiskDescription, "vdev") {
+				t.Errorf("RiskDescription = %q, want no layout warning", h.RiskDescription)
+			}
+		})
+	}
+}
+
+func TestAssessHealth_Autotrim(t *testing.T) {
+	tests := []struct {
+		name     string
+		pool     Pool
+		wantNote bool
+	}{
+		{"healthy_off", Pool{Health: PoolOnline, Redundancy: 1}, true},
+		{"healthy_on", Pool{Health: PoolOnline, Redundancy: 1, Autotrim: true}, false},
+		{"no_redundancy_off", Pool{Health: PoolOnline}, true},
+		{"degraded_off", Pool{Health: PoolDegraded, Redundancy: 1}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := AssessHealth(tt.pool)
+			if got := strings.Contains(h.Recommendation, "Autotrim is off"); got != tt.wantNote {
+				t.Errorf("Recommendation = %q, want autotrim note: %v", h.Recommendation, tt.wantNote)
+			}
+		})
+	}
+}
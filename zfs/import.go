@@ -0,0 +1,237 @@
+package zfs
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ErrImportRefused is returned when a pool has missing devices and the
+// import options don't allow them.
+var ErrImportRefused = errors.New("import refused")
+
+// ImportablePools lists pools that can be imported.
+func (m *Manager) ImportablePools(ctx context.Context) ([]ImportablePool, error) {
+	out, err := m.exec.CombinedOutput(ctx, "zpool", "import")
+	if err != nil {
+		// zpool exits non-zero when there is nothing to import
+		if bytes.Contains(out, []byte("no pools available")) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("zpool import: %s: %w", bytes.TrimSpace(out), err)
+	}
+	return parseImportablePools(out), nil
+}
+
+// ImportPool imports the pool identified by name or numeric GUID.
+// Pools that are not ONLINE are refused unless opts allow the missing
+// devices. The returned pool describes the state it was imported in,
+// including any missing devices.
+func (m *Manager) ImportPool(ctx context.Context, nameOrGUID string, opts ImportOptions) (*ImportablePool, error) {
+	if nameOrGUID == "" {
+		return nil, fmt.Errorf("pool name is required")
+	}
+	if err := validateName(nameOrGUID); err != nil {
+		return nil, err
+	}
+
+	pools, err := m.ImportablePools(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pool, err := findImportable(pools, nameOrGUID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkImportable(pool, opts); err != nil {
+		return nil, err
+	}
+
+	args := []string{"import"}
+	if opts.Force {
+		args = append(args, "-f")
+	}
+	if opts.MissingLog {
+		args = append(args, "-m")
+	}
+	if opts.ReadOnly {
+		args = append(args, "-o", "readonly=on")
+	}
+	args = append(args, nameOrGUID)
+
+	if out, err := m.exec.CombinedOutput(ctx, "zpool", args...); err != nil {
+		if !opts.Force && bytes.Contains(out, []byte("another system")) {
+			return nil, fmt.Errorf("%w: %s, import with force to continue", ErrImportRefused, bytes.TrimSpace(out))
+		}
+		return nil, fmt.Errorf("zpool import: %s: %w", bytes.TrimSpace(out), err)
+	}
+	return pool, nil
+}
+
+// ExportPool exports a pool so that its disks can be moved to another
+// system. Datasets are unmounted first; a pool with busy mounts is not
+// exported.
+func (m *Manager) ExportPool(ctx context.Context, name string) error {
+	defer m.lockPool(name)()
+	if err := validateName(name); err != nil {
+		return err
+	}
+	if strings.ContainsAny(name, "/@") {
+		return fmt.Errorf("invalid pool name %q", name)
+	}
+
+	if out, err := m.runMutation(ctx, "zpool", "export", name); err != nil {
+		return fmt.Errorf("zpool export %s: %s: %w", name, bytes.TrimSpace(out), err)
+	}
+	return nil
+}
+
+// findImportable finds a pool by GUID or unique name.
+func findImportable(pools []ImportablePool, nameOrGUID string) (*ImportablePool, error) {
+	var found *ImportablePool
+	for i := range pools {
+		p := &pools[i]
+		if p.GUID == nameOrGUID {
+			return p, nil
+		}
+		if p.Name == nameOrGUID {
+			if found != nil {
+				return nil, fmt.Errorf("multiple importable pools named %s, import by id", nameOrGUID)
+			}
+			found = p
+		}
+	}
+	if found == nil {
+		return nil, fmt.Errorf("no importable pool %s", nameOrGUID)
+	}
+	return found, nil
+}
+
+// checkImportable refuses to import a pool with missing devices unless
+// the caller has opted in.
+func checkImportable(p *ImportablePool, opts ImportOptions) error {
+	if p.NeedsForce && !opts.Force {
+		return fmt.Errorf("%w: pool %s was last used by another system, make sure it is not imported there and import with force to continue", ErrImportRefused, p.Name)
+	}
+	if p.State == PoolOnline {
+		return nil
+	}
+
+	var logMissing, dataMissing bool
+	for _, d := range p.MissingDevices {
+		if d.Class == "logs" {
+			logMissing = true
+		} else {
+			dataMissing = true
+		}
+	}
+
+	if logMissing && !opts.MissingLog {
+		return fmt.Errorf("%w: pool %s is missing a log device, import with missing log to continue", ErrImportRefused, p.Name)
+	}
+	if (dataMissing || !logMissing) && !opts.Degraded {
+		return fmt.Errorf("%w: pool %s is %s, allow a degraded import to continue", ErrImportRefused, p.Name, p.State)
+	}
+	return nil
+}
+
+// importVdevClasses are the headers zpool prints before auxiliary vdevs.
+var importVdevClasses = []string{"logs", "cache", "spares", "special", "dedup"}
+
+// importDeviceStates are the states zpool reports for pool devices.
+var importDeviceStates = []string{"ONLINE", "DEGRADED", "FAULTED", "OFFLINE", "UNAVAIL", "REMOVED", "AVAIL", "INUSE"}
+
+// parseImportablePools parses the human-readable output of `zpool import`.
+func parseImportablePools(out []byte) []ImportablePool {
+	var (
+		pools    []ImportablePool
+		cur      *ImportablePool
+		lastKey  string
+		inConfig bool
+		class    string
+	)
+
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+
+		if key, value, ok := strings.Cut(trimmed, ":"); ok && !strings.HasPrefix(line, "\t") && !strings.Contains(key, " ") {
+			value = strings.TrimSpace(value)
+			switch key {
+			case "pool":
+				pools = append(pools, ImportablePool{Name: value})
+				cur = &pools[len(pools)-1]
+				inConfig, class = false, ""
+			case "id":
+				cur.GUID = value
+			case "state":
+				cur.State = PoolStatus(value)
+			case "status":
+				cur.Status = value
+			case "action":
+				cur.Action = value
+			case "config":
+				inConfig = true
+			}
+			lastKey = key
+			continue
+		}
+		if cur == nil || trimmed == "" {
+			continue
+		}
+
+		if !inConfig {
+			// Continuation of a wrapped status/action message
+			switch lastKey {
+			case "status":
+				cur.Status += " " + trimmed
+			case "action":
+				cur.Action += " " + trimmed
+			}
+			continue
+		}
+
+		fields := strings.Fields(trimmed)
+		if len(fields) == 1 && slices.Contains(importVdevClasses, fields[0]) {
+			class = fields[0]
+			continue
+		}
+		if len(fields) < 2 || !slices.Contains(importDeviceStates, fields[1]) {
+			continue
+		}
+
+		name, state := fields[0], fields[1]
+		if name == cur.Name || isGroupVdev(name) {
+			continue
+		}
+		switch state {
+		case "UNAVAIL", "FAULTED", "REMOVED":
+			// "was /dev/sdb1" names a device zpool only knows by GUID
+			if len(fields) >= 4 && fields[2] == "was" {
+				name = fields[3]
+			}
+			cur.MissingDevices = append(cur.MissingDevices, MissingDevice{Name: name, State: state, Class: class})
+		}
+	}
+
+	for i := range pools {
+		pools[i].NeedsForce = strings.Contains(pools[i].Status, "another system") ||
+			strings.Contains(pools[i].Action, "'-f'")
+	}
+	return pools
+}
+
+// isGroupVdev reports whether name is a grouping vdev rather than a device.
+func isGroupVdev(name string) bool {
+	for _, prefix := range []string{"mirror-", "raidz", "draid", "spare-", "replacing-"} {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
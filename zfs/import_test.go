@@ -0,0 +1,192 @@
+package zfs
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"go.aimuz.me/mynt/sysexec"
+)
+
+func readTestdata(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("read testdata: %v", err)
+	}
+	return data
+}
+
+func TestParseImportablePools_Degraded(t *testing.T) {
+	pools := parseImportablePools(readTestdata(t, "import_degraded.txt"))
+	if len(pools) != 2 {
+		t.Fatalf("got %d pools, want 2", len(pools))
+	}
+
+	tank := pools[0]
+	if tank.Name != "tank" || tank.GUID != "15809428539486016212" || tank.State != PoolDegraded {
+		t.Errorf("tank = %+v", tank)
+	}
+	if !strings.HasSuffix(tank.Action, "may be compromised if imported.") {
+		t.Errorf("action not joined across lines: %q", tank.Action)
+	}
+	want := []MissingDevice{{Name: "/dev/sdb1", State: "UNAVAIL"}}
+	if !slices.Equal(tank.MissingDevices, want) {
+		t.Errorf("missing devices = %+v, want %+v", tank.MissingDevices, want)
+	}
+
+	backup := pools[1]
+	if backup.Name != "backup" || backup.State != PoolOnline || len(backup.MissingDevices) != 0 {
+		t.Errorf("backup = %+v", backup)
+	}
+}
+
+func TestParseImportablePools_MissingLog(t *testing.T) {
+	pools := parseImportablePools(readTestdata(t, "import_missing_log.txt"))
+	if len(pools) != 1 {
+		t.Fatalf("got %d pools, want 1", len(pools))
+	}
+	want := []MissingDevice{{Name: "sdf", State: "UNAVAIL", Class: "logs"}}
+	if !slices.Equal(pools[0].MissingDevices, want) {
+		t.Errorf("missing devices = %+v, want %+v", pools[0].MissingDevices, want)
+	}
+}
+
+func TestParseImportablePools_OtherSystem(t *testing.T) {
+	pools := parseImportablePools(readTestdata(t, "import_other_system.txt"))
+	if len(pools) != 1 || !pools[0].NeedsForce {
+		t.Fatalf("pools = %+v, want media needing force", pools)
+	}
+	for _, p := range parseImportablePools(readTestdata(t, "import_degraded.txt")) {
+		if p.NeedsForce {
+			t.Errorf("%s needs force, want not", p.Name)
+		}
+	}
+}
+
+func TestExportPool(t *testing.T) {
+	exec := sysexec.NewMock()
+	m := &Manager{exec: exec}
+
+	if err := m.ExportPool(context.Background(), "tank"); err != nil {
+		t.Fatalf("ExportPool: %v", err)
+	}
+	cmds := exec.Commands()
+	if want := []string{"export", "tank"}; len(cmds) != 1 || !slices.Equal(cmds[0].Args, want) {
+		t.Errorf("commands = %v, want zpool %v", cmds, want)
+	}
+
+	if err := m.ExportPool(context.Background(), "tank/data"); err == nil {
+		t.Error("expected error exporting a dataset")
+	}
+}
+
+func TestImportPool_Flags(t *testing.T) {
+	tests := []struct {
+		name     string
+		fixture  string
+		pool     string
+		opts     ImportOptions
+		wantArgs []string
+		wantErr  string
+	}{
+		{
+			name:     "online",
+			fixture:  "import_degraded.txt",
+			pool:     "backup",
+			wantArgs: []string{"import", "backup"},
+		},
+		{
+			name:     "force",
+			fixture:  "import_degraded.txt",
+			pool:     "backup",
+			opts:     ImportOptions{Force: true},
+			wantArgs: []string{"import", "-f", "backup"},
+		},
+		{
+			name:    "degraded_refused",
+			fixture: "import_degraded.txt",
+			pool:    "tank",
+			wantErr: "allow a degraded import",
+		},
+		{
+			name:     "degraded_by_guid",
+			fixture:  "import_degraded.txt",
+			pool:     "15809428539486016212",
+			opts:     ImportOptions{Degraded: true, Force: true},
+			wantArgs: []string{"import", "-f", "15809428539486016212"},
+		},
+		{
+			name:     "readonly",
+			fixture:  "import_degraded.txt",
+			pool:     "backup",
+			opts:     ImportOptions{ReadOnly: true, Force: true},
+			wantArgs: []string{"import", "-f", "-o", "readonly=on", "backup"},
+		},
+		{
+			name:    "missing_log_refused",
+			fixture: "import_missing_log.txt",
+			pool:    "fast",
+			opts:    ImportOptions{Degraded: true},
+			wantErr: "missing a log device",
+		},
+		{
+			name:     "missing_log",
+			fixture:  "import_missing_log.txt",
+			pool:     "fast",
+			opts:     ImportOptions{MissingLog: true},
+			wantArgs: []string{"import", "-m", "fast"},
+		},
+		{
+			name:    "other_system_refused",
+			fixture: "import_other_system.txt",
+			pool:    "media",
+			wantErr: "last used by another system",
+		},
+		{
+			name:     "other_system_force",
+			fixture:  "import_other_system.txt",
+			pool:     "media",
+			opts:     ImportOptions{Force: true},
+			wantArgs: []string{"import", "-f", "media"},
+		},
+		{
+			name:    "not_found",
+			fixture: "import_degraded.txt",
+			pool:    "nope",
+			wantErr: "no importable pool nope",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exec := sysexec.NewMock()
+			exec.SetOutput("zpool", readTestdata(t, tt.fixture))
+			m := &Manager{exec: exec}
+
+			pool, err := m.ImportPool(context.Background(), tt.pool, tt.opts)
+			cmds := exec.Commands()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want containing %q", err, tt.wantErr)
+				}
+				if len(cmds) != 1 {
+					t.Errorf("expected only the scan to run, got %v", cmds)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(cmds) != 2 || !slices.Equal(cmds[1].Args, tt.wantArgs) {
+				t.Fatalf("commands = %v, want zpool %v", cmds, tt.wantArgs)
+			}
+			if tt.opts.Degraded && len(pool.MissingDevices) == 0 {
+				t.Errorf("expected missing devices in result, got %+v", pool)
+			}
+		})
+	}
+}
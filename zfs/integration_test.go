@@ -245,6 +245,33 @@ func TestIntegration_Snapshot(t *testing.T) {
 	})
 }
 
+func TestIntegration_RenameSnapshot(t *testing.T) {
+	testutil.RequireIntegration(t)
+
+	m := setupTestPool(t)
+
+	ctx := context.Background()
+	datasetName := testPoolName + "/renametest"
+	if err := m.CreateDataset(ctx, CreateDatasetRequest{Name: datasetName}); err != nil {
+		t.Fatalf("CreateDataset: %v", err)
+	}
+	if _, err := m.CreateSnapshot(ctx, CreateSnapshotRequest{Dataset: datasetName, Name: "before"}); err != nil {
+		t.Fatalf("CreateSnapshot: %v", err)
+	}
+
+	if err := m.RenameSnapshot(ctx, datasetName+"@before", "after"); err != nil {
+		t.Fatalf("RenameSnapshot: %v", err)
+	}
+
+	snapshots, err := m.ListSnapshots(ctx, datasetName)
+	if err != nil {
+		t.Fatalf("ListSnapshots: %v", err)
+	}
+	if len(snapshots) != 1 || snapshots[0].Name != datasetName+"@after" {
+		t.Errorf("snapshots = %+v, want only %s@after", snapshots, datasetName)
+	}
+}
+
 func TestIntegration_Volume(t *testing.T) {
 	testutil.RequireIntegration(t)
 
@@ -281,6 +308,100 @@ func TestIntegration_Volume(t *testing.T) {
 	})
 }
 
+func TestIntegration_DatasetNote(t *testing.T) {
+	testutil.RequireIntegration(t)
+
+	m := setupTestPool(t)
+
+	ctx := context.Background()
+	parent := testPoolName + "/noted"
+	child := parent + "/child"
+	for _, name := range []string{parent, child} {
+		if err := m.CreateDataset(ctx, CreateDatasetRequest{Name: name}); err != nil {
+			t.Fatalf("CreateDataset(%s): %v", name, err)
+		}
+	}
+
+	const note = "DB volume, do not enable dedup"
+	if err := m.SetNote(ctx, parent, note); err != nil {
+		t.Fatalf("SetNote: %v", err)
+	}
+
+	got, err := m.GetNote(ctx, parent)
+	if err != nil {
+		t.Fatalf("GetNote: %v", err)
+	}
+	if got != note {
+		t.Errorf("GetNote = %q, want %q", got, note)
+	}
+
+	datasets, err := m.ListDatasets(ctx)
+	if err != nil {
+		t.Fatalf("ListDatasets: %v", err)
+	}
+	for _, ds := range datasets {
+		want := ""
+		if ds.Name == parent {
+			want = note
+		}
+		// The child must not show the parent's note
+		if ds.Note != want {
+			t.Errorf("%s: Note = %q, want %q", ds.Name, ds.Note, want)
+		}
+	}
+
+	if err := m.SetNote(ctx, parent, ""); err != nil {
+		t.Fatalf("SetNote (clear): %v", err)
+	}
+	if got, _ := m.GetNote(ctx, parent); got != "" {
+		t.Errorf("GetNote after clear = %q, want empty", got)
+	}
+}
+
+func TestIntegration_VolumeProvisioning(t *testing.T) {
+	testutil.RequireIntegration(t)
+
+	m := setupTestPool(t)
+
+	ctx := context.Background()
+	const size = 10 * 1024 * 1024 // 10MB
+
+	tests := []struct {
+		name   string
+		sparse bool
+	}{
+		{"thick", false},
+		{"sparse", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			volumeName := testPoolName + "/" + tt.name
+			if err := m.CreateDataset(ctx, CreateDatasetRequest{
+				Name:   volumeName,
+				Type:   "volume",
+				Quota:  size,
+				Sparse: tt.sparse,
+			}); err != nil {
+				t.Fatalf("CreateDataset (volume): %v", err)
+			}
+			defer m.DestroyDataset(ctx, volumeName)
+
+			ds, err := m.GetDataset(ctx, volumeName)
+			if err != nil {
+				t.Fatalf("GetDataset (volume): %v", err)
+			}
+			if tt.sparse && ds.RefReservation != 0 {
+				t.Errorf("RefReservation = %d, want 0", ds.RefReservation)
+			}
+			// Thick volumes reserve the full size plus metadata overhead
+			if !tt.sparse && ds.RefReservation < size {
+				t.Errorf("RefReservation = %d, want >= %d", ds.RefReservation, size)
+			}
+		})
+	}
+}
+
 // TestIntegration_ListSnapshots_MultipleSnapshots verifies that ListSnapshots
 // correctly parses multiple snapshots with different naming patterns.
 func TestIntegration_ListSnapshots_MultipleSnapshots(t *testing.T) {
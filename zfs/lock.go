@@ -0,0 +1,42 @@
+package zfs
+
+import (
+	"strings"
+	"sync"
+)
+
+// Mutations of the same pool are serialized with a per-pool lock, so that
+// for example a dataset create racing a destroy of its parent fails
+// cleanly instead of with a confusing zfs error. Reads never take the
+// lock, and mutations of different pools run in parallel.
+//
+// Methods that take the pool lock: CreatePool, DestroyPool, ExportPool,
+// ReplaceDisk, AddVdev, AddSpare, RemoveSpare, SetPoolProperty (and so
+// SetAutotrim), CreateDataset, DestroyDataset, SetProperty (and so
+// SetQuota and SetProperties), SetCompression, SetRefQuota,
+// SetReservation (and so SetRefReservation), SetNote, CreateSnapshot,
+// DestroySnapshot, RollbackSnapshot, RenameSnapshot and CloneSnapshot. All
+// but CreatePool and ExportPool also refuse to run on a pool imported
+// read-only, see checkWritable.
+//
+// Long-running streams (SendToFile, ReceiveFromFile, Receive), Scrub,
+// Trim and ImportPool do not, so they cannot hold up other changes for
+// hours. Receive and ReceiveFromFile still refuse a read-only pool. Exec does not take the
+// lock either; its allowlist leaves out every mutation listed above, so
+// those cannot bypass it.
+
+// lockPool locks the pool that name (a pool, dataset or snapshot name)
+// belongs to and returns the matching unlock function.
+func (m *Manager) lockPool(name string) (unlock func()) {
+	v, _ := m.poolLocks.LoadOrStore(poolOf(name), new(sync.Mutex))
+	mu := v.(*sync.Mutex)
+	mu.Lock()
+	return mu.Unlock
+}
+
+// poolOf returns the pool component of a pool, dataset or snapshot name.
+func poolOf(name string) string {
+	pool, _, _ := strings.Cut(name, "/")
+	pool, _, _ = strings.Cut(pool, "@")
+	return pool
+}
@@ -0,0 +1,99 @@
+package zfs
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"go.aimuz.me/mynt/sysexec"
+)
+
+// overlapExec records how many commands run at once, in total and per
+// pool. Each command holds for a while so overlapping calls are visible.
+type overlapExec struct {
+	*sysexec.MockExecutor
+	hold time.Duration
+
+	mu         sync.Mutex
+	active     map[string]int
+	maxPerPool map[string]int
+	total      int
+	maxTotal   int
+}
+
+func newOverlapExec(hold time.Duration) *overlapExec {
+	return &overlapExec{
+		MockExecutor: sysexec.NewMock(),
+		hold:         hold,
+		active:       make(map[string]int),
+		maxPerPool:   make(map[string]int),
+	}
+}
+
+func (e *overlapExec) CombinedOutput(ctx context.Context, name string, args ...string) ([]byte, error) {
+	pool := poolOf(args[len(args)-1])
+
+	e.mu.Lock()
+	e.active[pool]++
+	e.total++
+	e.maxPerPool[pool] = max(e.maxPerPool[pool], e.active[pool])
+	e.maxTotal = max(e.maxTotal, e.total)
+	e.mu.Unlock()
+
+	time.Sleep(e.hold)
+
+	e.mu.Lock()
+	e.active[pool]--
+	e.total--
+	e.mu.Unlock()
+	return nil, nil
+}
+
+func setNotes(t *testing.T, m *Manager, datasets ...string) {
+	t.Helper()
+	var wg sync.WaitGroup
+	for _, ds := range datasets {
+		wg.Go(func() {
+			if err := m.SetNote(context.Background(), ds, "note"); err != nil {
+				t.Errorf("SetNote(%s): %v", ds, err)
+			}
+		})
+	}
+	wg.Wait()
+}
+
+func TestPoolLock_SamePoolSerialized(t *testing.T) {
+	exec := newOverlapExec(20 * time.Millisecond)
+	m := &Manager{exec: exec}
+
+	setNotes(t, m, "tank/a", "tank/b", "tank/c")
+
+	if got := exec.maxPerPool["tank"]; got != 1 {
+		t.Errorf("max concurrent mutations on tank = %d, want 1", got)
+	}
+}
+
+func TestPoolLock_DifferentPoolsConcurrent(t *testing.T) {
+	exec := newOverlapExec(200 * time.Millisecond)
+	m := &Manager{exec: exec}
+
+	setNotes(t, m, "tank/a", "backup/a")
+
+	if exec.maxTotal != 2 {
+		t.Errorf("max concurrent mutations = %d, want 2 for different pools", exec.maxTotal)
+	}
+}
+
+func TestPoolOf(t *testing.T) {
+	for name, want := range map[string]string{
+		"tank":            "tank",
+		"tank/data/child": "tank",
+		"tank@snap":       "tank",
+		"tank/data@snap":  "tank",
+	} {
+		if got := poolOf(name); got != want {
+			t.Errorf("poolOf(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
@@ -1,12 +1,18 @@
 package zfs
 
 import (
+	"bytes"
+	"cmp"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"iter"
+	"path/filepath"
 	"slices"
 	"strconv"
+	"strings"
+	"sync"
 
 	gozfs "github.com/mistifyio/go-zfs/v4"
 	"go.aimuz.me/mynt/sysexec"
@@ -14,12 +20,36 @@ import (
 
 // Manager handles ZFS operations.
 type Manager struct {
-	exec sysexec.Executor
+	exec      sysexec.Executor
+	templates TemplateSource
+	poolLocks sync.Map // pool name -> *sync.Mutex, see lockPool
+	paramsDir string   // module parameters directory, defaultParamsDir if empty
+
+	backupRoot string // directory SendToFile writes under, DefaultBackupRoot if empty
+
+	arcStatsPath string // arcstats kstat file, defaultARCStatsPath if empty
+
+	retryPolicy    RetryPolicy // zero value means no retries
+	maxDiffEntries int         // SnapshotDiff limit, DefaultMaxDiffEntries if zero
+}
+
+// ManagerOption configures a Manager.
+type ManagerOption func(*Manager)
+
+// WithTemplateSource sets the source of custom dataset templates.
+func WithTemplateSource(src TemplateSource) ManagerOption {
+	return func(m *Manager) {
+		m.templates = src
+	}
 }
 
 // NewManager creates a new ZFS manager.
-func NewManager() *Manager {
-	return &Manager{exec: sysexec.NewExecutor()}
+func NewManager(opts ...ManagerOption) *Manager {
+	m := &Manager{exec: sysexec.NewExecutor(), retryPolicy: DefaultRetryPolicy}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
 }
 
 // ListPools lists all imported ZFS pools.
@@ -59,9 +89,17 @@ func (m *Manager) listPools(ctx context.Context, names ...string) ([]Pool, error
 		return nil, fmt.Errorf("parse zpool status: %w", err)
 	}
 
+	// Read-only state and autotrim are pool properties, not part of the
+	// status output. Failing to read them is not worth failing the listing for.
+	readOnly, _ := m.readOnlyPools(ctx, names...)
+	autotrim, _ := m.poolFlag(ctx, "autotrim", names...)
+
 	pools := make([]Pool, 0, len(status.Pools))
 	for name, pj := range sortMapIter(status.Pools) {
-		pools = append(pools, buildPool(name, pj))
+		pool := buildPool(name, pj)
+		pool.ReadOnly = readOnly[name]
+		pool.Autotrim = autotrim[name]
+		pools = append(pools, pool)
 	}
 	return pools, nil
 }
@@ -69,13 +107,15 @@ func (m *Manager) listPools(ctx context.Context, names ...string) ([]Pool, error
 // buildPool constructs a Pool from JSON data.
 func buildPool(name string, pj *PoolJSON) Pool {
 	vdevs := parseVDevsFromJSON(pj.VDevs)
+	spares := parseSparesFromJSON(pj.Spares, vdevs)
 	pool := poolFromJSON(name, pj, vdevs)
+	pool.Spares = spares
 	pool.ScrubStatus = parseScrubFromJSON(pj.ScanStats)
 	pool.ResilverStatus = parseResilverFromJSON(pj.ScanStats)
 	return pool
 }
 
-const zfsDatasetProperties = "name,type,used,available,referenced,mountpoint,compression,encryption,dedup,quota,reservation,volsize,usedbydataset"
+const zfsDatasetProperties = "name,type,used,available,referenced,mountpoint,compression,encryption,dedup,quota,refquota,reservation,refreservation,compressratio,logicalused,written,volsize,usedbydataset,origin,volmode,mynt:note"
 
 // listDatasets is the internal implementation for listing datasets.
 // If names are provided, only those datasets are queried.
@@ -101,6 +141,7 @@ func (m *Manager) listDatasets(ctx context.Context, names ...string) ([]Dataset,
 	for _, dj := range sortMapIter(listJSON.Datasets) {
 		datasets = append(datasets, buildDataset(dj))
 	}
+	m.fillVolumeAutotrim(ctx, datasets)
 
 	return datasets, nil
 }
@@ -114,40 +155,66 @@ func buildDataset(dj *DatasetListJSON) Dataset {
 
 	used := parseUint(dj.GetProp("used"))
 	quota := parseUint(dj.GetProp("quota"))
+	var volMode string
 	if dsType == DatasetVolume {
 		used = parseUint(dj.GetProp("usedbydataset"))
 		quota = parseUint(dj.GetProp("volsize"))
+		volMode = propOrEmpty(dj.GetProp("volmode"))
 	}
 
 	return Dataset{
-		Name:          dj.Name,
-		Pool:          dj.Pool,
-		Type:          dsType,
-		Used:          used,
-		Available:     parseUint(dj.GetProp("available")),
-		Referenced:    parseUint(dj.GetProp("referenced")),
-		Mountpoint:    dj.GetProp("mountpoint"),
-		Compression:   dj.GetProp("compression"),
-		Encryption:    dj.GetProp("encryption"),
-		Deduplication: dj.GetProp("dedup"),
-		Quota:         quota,
-		Reservation:   parseUint(dj.GetProp("reservation")),
+		Name:           dj.Name,
+		Pool:           dj.Pool,
+		Type:           dsType,
+		Used:           used,
+		Available:      parseUint(dj.GetProp("available")),
+		Referenced:     parseUint(dj.GetProp("referenced")),
+		Mountpoint:     dj.GetProp("mountpoint"),
+		Compression:    dj.GetProp("compression"),
+		Encryption:     dj.GetProp("encryption"),
+		Deduplication:  dj.GetProp("dedup"),
+		Quota:          quota,
+		RefQuota:       parseUint(dj.GetProp("refquota")),
+		Reservation:    parseUint(dj.GetProp("reservation")),
+		RefReservation: parseUint(dj.GetProp("refreservation")),
+		CompressRatio:  parseRatio(dj.GetProp("compressratio")),
+		LogicalUsed:    parseUint(dj.GetProp("logicalused")),
+		Written:        parseUint(dj.GetProp("written")),
+		Origin:         propOrEmpty(dj.GetProp("origin")),
+		Note:           localProp(dj, noteProperty),
+		VolMode:        volMode,
 	}
 }
 
+// propOrEmpty maps the "-" ZFS prints for unset properties to "".
+func propOrEmpty(v string) string {
+	if v == "-" {
+		return ""
+	}
+	return v
+}
+
+// localProp returns a property value only if it is set on the dataset
+// itself rather than inherited.
+func localProp(dj *DatasetListJSON, key string) string {
+	p := dj.Properties[key]
+	if p == nil || p.Source.Type != "LOCAL" {
+		return ""
+	}
+	return p.Value
+}
+
 // CreatePool creates a new ZFS pool.
 func (m *Manager) CreatePool(ctx context.Context, req CreatePoolRequest) error {
-	// Build vdev args
-	vdevArgs := make([]string, 0)
-	if req.Type != "" {
-		vdevArgs = append(vdevArgs, req.Type)
+	args, err := createPoolArgs(req)
+	if err != nil {
+		return err
 	}
-	vdevArgs = append(vdevArgs, req.Devices...)
+	defer m.lockPool(req.Name)()
 
 	// Create pool without dataset properties (mountpoint is a dataset property, not pool property)
-	_, err := gozfs.CreateZpool(req.Name, nil, vdevArgs...)
-	if err != nil {
-		return fmt.Errorf("failed to create pool: %w", err)
+	if out, err := m.exec.CombinedOutput(ctx, "zpool", args...); err != nil {
+		return fmt.Errorf("failed to create pool: %s: %w", bytes.TrimSpace(out), err)
 	}
 
 	// Set mountpoint on the root dataset
@@ -156,16 +223,67 @@ func (m *Manager) CreatePool(ctx context.Context, req CreatePoolRequest) error {
 		return fmt.Errorf("failed to get root dataset: %w", err)
 	}
 
-	mountpoint := fmt.Sprintf("/mnt/%s", req.Name)
-	if err := rootDataset.SetProperty("mountpoint", mountpoint); err != nil {
+	if err := rootDataset.SetProperty("mountpoint", PoolMountRoot(req.Name)); err != nil {
 		return fmt.Errorf("failed to set mountpoint: %w", err)
 	}
 
 	return nil
 }
 
+// DefaultAshift is the ashift used when a pool is created without one:
+// 4K sectors, which suits modern disks and costs little on 512-byte ones.
+const DefaultAshift = 12
+
+// ErrInvalidPool is returned by CreatePool for a request with an invalid
+// name, type or device list.
+var ErrInvalidPool = errors.New("invalid pool")
+
+// createPoolArgs builds the zpool create arguments for req. The ashift is
+// always set explicitly, since a wrong value cannot be changed later.
+func createPoolArgs(req CreatePoolRequest) ([]string, error) {
+	if err := validateName(req.Name); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidPool, err)
+	}
+	if strings.ContainsAny(req.Name, "/@") {
+		return nil, fmt.Errorf("%w: pool name %q must not contain / or @", ErrInvalidPool, req.Name)
+	}
+	minDevices, ok := vdevMinDevices[req.Type]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown type %q", ErrInvalidPool, req.Type)
+	}
+	if len(req.Devices) < minDevices {
+		return nil, fmt.Errorf("%w: %s needs at least %d devices, got %d", ErrInvalidPool, cmp.Or(req.Type, "stripe"), minDevices, len(req.Devices))
+	}
+	if err := validateDevices(req.Devices...); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidPool, err)
+	}
+	for i, d := range req.Devices {
+		if slices.Contains(req.Devices[:i], d) {
+			return nil, fmt.Errorf("%w: device %s listed twice", ErrInvalidPool, d)
+		}
+	}
+
+	ashift := req.Ashift
+	if ashift == 0 {
+		ashift = DefaultAshift
+	}
+	if ashift < 9 || ashift > 16 {
+		return nil, fmt.Errorf("%w: ashift must be between 9 and 16, got %d", ErrInvalidPool, ashift)
+	}
+
+	args := []string{"create", "-o", fmt.Sprintf("ashift=%d", ashift), req.Name}
+	if req.Type != "" {
+		args = append(args, req.Type)
+	}
+	return append(args, req.Devices...), nil
+}
+
 // DestroyPool destroys a ZFS pool.
 func (m *Manager) DestroyPool(ctx context.Context, name string) error {
+	defer m.lockPool(name)()
+	if err := m.checkWritable(ctx, name); err != nil {
+		return err
+	}
 	zpool, err := gozfs.GetZpool(name)
 	if err != nil {
 		return fmt.Errorf("failed to get pool: %w", err)
@@ -233,6 +351,9 @@ func parseScrubFromJSON(scan *ScanStatsJSON) *ScrubStatus {
 		ScanRate:    parseUint(scan.BytesPerScan),
 	}
 
+	if status.InProgress {
+		status.StartTime = int64(parseUint(scan.PassStart))
+	}
 	if scan.State == "FINISHED" && scan.EndTime != "" {
 		status.EndTime = &scan.EndTime
 	}
@@ -242,13 +363,149 @@ func parseScrubFromJSON(scan *ScanStatsJSON) *ScrubStatus {
 
 // ReplaceDisk replaces a disk in a pool.
 func (m *Manager) ReplaceDisk(ctx context.Context, poolName, oldDisk, newDisk string) error {
-	_, err := m.exec.Output(ctx, "zpool", "replace", "-f", poolName, oldDisk, newDisk)
+	defer m.lockPool(poolName)()
+	if err := m.checkWritable(ctx, poolName); err != nil {
+		return err
+	}
+	out, err := m.runMutation(ctx, "zpool", "replace", "-f", poolName, oldDisk, newDisk)
 	if err != nil {
-		return fmt.Errorf("replace disk %s with %s in pool %s: %w", oldDisk, newDisk, poolName, err)
+		return fmt.Errorf("replace disk %s with %s in pool %s: %s: %w", oldDisk, newDisk, poolName, bytes.TrimSpace(out), err)
 	}
 	return nil
 }
 
+// ErrInvalidVdev is returned by AddVdev for an unknown vdev type or a
+// device list that does not fit it.
+var ErrInvalidVdev = errors.New("invalid vdev")
+
+// ErrReducesRedundancy is returned by AddVdev when the new vdev tolerates
+// fewer disk failures than the pool's existing vdevs, which would lower
+// the redundancy of the whole pool.
+var ErrReducesRedundancy = errors.New("vdev would reduce pool redundancy")
+
+// ErrMismatchedReplication is returned by AddVdev when zpool refuses a
+// vdev whose layout differs from the pool's existing vdevs, such as a
+// raidz vdev added to a mirror pool.
+var ErrMismatchedReplication = errors.New("vdev replication level does not match the pool")
+
+// vdevMinDevices is the smallest device count each addable data vdev type
+// accepts; "" is a single-disk (stripe) vdev.
+var vdevMinDevices = map[string]int{
+	"":       1,
+	"mirror": 2,
+	"raidz":  2,
+	"raidz1": 2,
+	"raidz2": 3,
+	"raidz3": 4,
+}
+
+// AddVdevOption configures AddVdev.
+type AddVdevOption func(*addVdevOptions)
+
+type addVdevOptions struct {
+	force bool
+}
+
+// AllowReducedRedundancy adds the vdev even if it tolerates fewer disk
+// failures than the existing ones, passing -f to zpool add.
+func AllowReducedRedundancy() AddVdevOption {
+	return func(o *addVdevOptions) { o.force = true }
+}
+
+// AddVdev grows a pool by adding a data vdev of vdevType (mirror, raidz,
+// raidz2, raidz3, or empty for a single disk) built from devices.
+func (m *Manager) AddVdev(ctx context.Context, poolName, vdevType string, devices []string, opts ...AddVdevOption) error {
+	var o addVdevOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if err := validateName(poolName); err != nil {
+		return err
+	}
+	minDevices, ok := vdevMinDevices[vdevType]
+	if !ok {
+		return fmt.Errorf("%w: unknown type %q", ErrInvalidVdev, vdevType)
+	}
+	if vdevType == "" && len(devices) != 1 {
+		return fmt.Errorf("%w: a single-disk vdev takes exactly one device", ErrInvalidVdev)
+	}
+	if len(devices) < minDevices {
+		return fmt.Errorf("%w: %s needs at least %d devices, got %d", ErrInvalidVdev, vdevType, minDevices, len(devices))
+	}
+	if err := validateDevices(devices...); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidVdev, err)
+	}
+	for i, d := range devices {
+		if slices.Contains(devices[:i], d) {
+			return fmt.Errorf("%w: device %s listed twice", ErrInvalidVdev, d)
+		}
+	}
+
+	defer m.lockPool(poolName)()
+	if err := m.checkWritable(ctx, poolName); err != nil {
+		return err
+	}
+
+	pool, err := m.GetPool(ctx, poolName)
+	if err != nil {
+		return err
+	}
+	if !o.force {
+		have, want := vdevFaultTolerance(pool.VDevs), newVdevFaultTolerance(vdevType, len(devices))
+		if want < have {
+			return fmt.Errorf("%w: pool %s vdevs survive %d disk failures, a %s vdev of %d disks survives %d; set force to add it anyway",
+				ErrReducesRedundancy, poolName, have, cmp.Or(vdevType, "single-disk"), len(devices), want)
+		}
+	}
+
+	args := []string{"add"}
+	if o.force {
+		args = append(args, "-f")
+	}
+	args = append(args, poolName)
+	if vdevType != "" {
+		args = append(args, vdevType)
+	}
+	args = append(args, devices...)
+	if out, err := m.runMutation(ctx, "zpool", args...); err != nil {
+		if bytes.Contains(out, []byte("mismatched replication level")) {
+			err = ErrMismatchedReplication
+		}
+		return fmt.Errorf("add vdev to pool %s: %s: %w", poolName, bytes.TrimSpace(out), err)
+	}
+	return nil
+}
+
+// vdevFaultTolerance returns how many disk failures the weakest of vdevs
+// survives by design, whether or not any of their disks have failed.
+func vdevFaultTolerance(vdevs []VDevDetail) int {
+	tolerance := -1
+	for _, v := range vdevs {
+		t := newVdevFaultTolerance(v.Type, vdevWidth(v))
+		if tolerance < 0 || t < tolerance {
+			tolerance = t
+		}
+	}
+	return max(tolerance, 0)
+}
+
+// newVdevFaultTolerance returns how many disk failures a vdev of vdevType
+// with the given number of disks survives.
+func newVdevFaultTolerance(vdevType string, disks int) int {
+	switch vdevType {
+	case "mirror":
+		return max(disks-1, 0)
+	case "raidz", "raidz1":
+		return 1
+	case "raidz2":
+		return 2
+	case "raidz3":
+		return 3
+	default:
+		return 0
+	}
+}
+
 // calculateRedundancy determines how many more disks can fail.
 // Returns the minimum number of additional disks that can fail before data loss.
 // Returns 0 if already degraded with no more redundancy.
@@ -317,6 +574,34 @@ func parseVDevsFromJSON(jsonVDevs map[string]*Vdev) []VDevDetail {
 	return vdevs
 }
 
+// parseSparesFromJSON converts a pool's hot spares to DiskDetails, and
+// marks the ones standing in for a failed disk in vdevs.
+func parseSparesFromJSON(jsonSpares map[string]*Vdev, vdevs []VDevDetail) []DiskDetail {
+	if len(jsonSpares) == 0 {
+		return nil
+	}
+
+	active := make(map[string]bool)
+	for i := range vdevs {
+		for j := range vdevs[i].Children {
+			d := &vdevs[i].Children[j]
+			if _, ok := jsonSpares[d.Name]; ok {
+				d.Spare, d.InUse = true, true
+				active[d.Name] = true
+			}
+		}
+	}
+
+	spares := make([]DiskDetail, 0, len(jsonSpares))
+	for name, v := range sortMapIter(jsonSpares) {
+		d := diskDetailFromVdev(v, false)
+		d.Spare = true
+		d.InUse = active[name] || v.State == "INUSE"
+		spares = append(spares, d)
+	}
+	return spares
+}
+
 // vdevDetailFromVdev converts a single Vdev node to VDevDetail.
 func vdevDetailFromVdev(v *Vdev) VDevDetail {
 	vdev := VDevDetail{
@@ -338,12 +623,19 @@ func vdevDetailFromVdev(v *Vdev) VDevDetail {
 func collectChildDisks(children map[string]*Vdev) []DiskDetail {
 	var disks []DiskDetail
 	for _, child := range sortMapIter(children) {
-		if child.VDevType == "replacing" {
+		switch child.VDevType {
+		case "replacing":
 			// Replacing vdev contains old and new disk as children
 			for _, d := range child.VDevs {
 				disks = append(disks, diskDetailFromVdev(d, true))
 			}
-		} else {
+		case "spare":
+			// Spare vdev contains the failed disk and the hot spare
+			// standing in for it; parseSparesFromJSON marks the spare
+			for _, d := range sortMapIter(child.VDevs) {
+				disks = append(disks, diskDetailFromVdev(d, false))
+			}
+		default:
 			disks = append(disks, diskDetailFromVdev(child, false))
 		}
 	}
@@ -415,6 +707,16 @@ func parseUint(s string) uint64 {
 	return v
 }
 
+// parseRatio parses a ZFS ratio such as "1.45x", returning 0 on error.
+// The trailing "x" is optional, since parsable output may omit it.
+func parseRatio(s string) float64 {
+	v, err := strconv.ParseFloat(strings.TrimSuffix(s, "x"), 64)
+	if err != nil || v < 0 {
+		return 0
+	}
+	return v
+}
+
 // sortMapIter returns an iterator that yields map entries in sorted key order.
 // The iterator conforms to iter.Seq2 and can be used with range loops.
 func sortMapIter[K string, T any](m map[K]T) iter.Seq2[K, T] {
@@ -443,11 +745,28 @@ func validateNames(names ...string) error {
 	return nil
 }
 
+// validateDevices checks that each device is a clean absolute path under
+// /dev, so it cannot be mistaken for a zpool option or vdev keyword.
+func validateDevices(devices ...string) error {
+	for _, dev := range devices {
+		if !strings.HasPrefix(dev, "/dev/") || filepath.Clean(dev) != dev {
+			return fmt.Errorf("device %q must be an absolute path under /dev", dev)
+		}
+		if err := validateName(dev); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // validateName checks for potentially malicious characters in ZFS names (pools/datasets/snapshots).
 func validateName(name string) error {
 	if name == "" {
 		return fmt.Errorf("name cannot be empty")
 	}
+	if name[0] == '-' {
+		return fmt.Errorf("name %q must not start with '-'", name)
+	}
 
 	// Allowed characters: letters, numbers, -, _, :, ., /, @
 	for _, r := range name {
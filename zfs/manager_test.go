@@ -2,8 +2,12 @@ package zfs
 
 import (
 	"context"
+	"errors"
+	"maps"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	"go.aimuz.me/mynt/sysexec"
 )
@@ -69,6 +73,30 @@ func TestParseUint(t *testing.T) {
 	}
 }
 
+func TestParseRatio(t *testing.T) {
+	tests := []struct {
+		input string
+		want  float64
+	}{
+		{"1.00x", 1},
+		{"2.50x", 2.5},
+		{"1.45", 1.45},
+		{"", 0},
+		{"-", 0},
+		{"x", 0},
+		{"fast", 0},
+		{"-1.00x", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := parseRatio(tt.input); got != tt.want {
+				t.Errorf("parseRatio(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
 func TestCalculateRedundancy(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -397,3 +425,291 @@ func TestListPools_Validation(t *testing.T) {
 		})
 	}
 }
+
+func TestCreatePoolArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     CreatePoolRequest
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "default ashift",
+			req:  CreatePoolRequest{Name: "tank", Type: "mirror", Devices: []string{"/dev/sda", "/dev/sdb"}},
+			want: []string{"create", "-o", "ashift=12", "tank", "mirror", "/dev/sda", "/dev/sdb"},
+		},
+		{
+			name: "override",
+			req:  CreatePoolRequest{Name: "tank", Devices: []string{"/dev/sda"}, Ashift: 9},
+			want: []string{"create", "-o", "ashift=9", "tank", "/dev/sda"},
+		},
+		{
+			name:    "too small",
+			req:     CreatePoolRequest{Name: "tank", Devices: []string{"/dev/sda"}, Ashift: 8},
+			wantErr: true,
+		},
+		{
+			name:    "too large",
+			req:     CreatePoolRequest{Name: "tank", Devices: []string{"/dev/sda"}, Ashift: 17},
+			wantErr: true,
+		},
+		{
+			name:    "option as name",
+			req:     CreatePoolRequest{Name: "-f", Devices: []string{"/dev/sda"}},
+			wantErr: true,
+		},
+		{
+			name:    "dataset name",
+			req:     CreatePoolRequest{Name: "tank/data", Devices: []string{"/dev/sda"}},
+			wantErr: true,
+		},
+		{
+			name:    "unknown type",
+			req:     CreatePoolRequest{Name: "tank", Type: "-o", Devices: []string{"/dev/sda"}},
+			wantErr: true,
+		},
+		{
+			name:    "too few devices",
+			req:     CreatePoolRequest{Name: "tank", Type: "mirror", Devices: []string{"/dev/sda"}},
+			wantErr: true,
+		},
+		{
+			name:    "relative device",
+			req:     CreatePoolRequest{Name: "tank", Devices: []string{"sda"}},
+			wantErr: true,
+		},
+		{
+			name:    "option as device",
+			req:     CreatePoolRequest{Name: "tank", Devices: []string{"-f"}},
+			wantErr: true,
+		},
+		{
+			name:    "duplicate device",
+			req:     CreatePoolRequest{Name: "tank", Type: "mirror", Devices: []string{"/dev/sda", "/dev/sda"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := createPoolArgs(tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("createPoolArgs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("createPoolArgs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListDatasets_Origin(t *testing.T) {
+	exec := sysexec.NewMock()
+	exec.SetOutput("zfs", []byte(`{
+		"output_version": {"command": "zfs list", "vers_major": 0, "vers_minor": 1},
+		"datasets": {
+			"tank/base": {
+				"name": "tank/base", "type": "FILESYSTEM", "pool": "tank",
+				"properties": {"origin": {"value": "-", "source": {"type": "NONE", "data": "-"}}}
+			},
+			"tank/clone": {
+				"name": "tank/clone", "type": "FILESYSTEM", "pool": "tank",
+				"properties": {"origin": {"value": "tank/base@snap1", "source": {"type": "NONE", "data": "-"}}}
+			}
+		}
+	}`))
+	m := &Manager{exec: exec}
+
+	datasets, err := m.ListDatasets(context.Background())
+	if err != nil {
+		t.Fatalf("ListDatasets: %v", err)
+	}
+	origins := make(map[string]string)
+	for _, ds := range datasets {
+		origins[ds.Name] = ds.Origin
+	}
+	want := map[string]string{"tank/base": "", "tank/clone": "tank/base@snap1"}
+	if !maps.Equal(origins, want) {
+		t.Errorf("origins = %v, want %v", origins, want)
+	}
+}
+
+// blockingExec simulates a hung zfs: commands never finish on their own
+// and only return once their context is done.
+type blockingExec struct {
+	*sysexec.MockExecutor
+}
+
+func (e blockingExec) Output(ctx context.Context, name string, args ...string) ([]byte, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestList_CancelledContext(t *testing.T) {
+	m := &Manager{exec: blockingExec{sysexec.NewMock()}}
+	lists := map[string]func(ctx context.Context) error{
+		"datasets": func(ctx context.Context) error {
+			_, err := m.ListDatasets(ctx)
+			return err
+		},
+		"snapshots": func(ctx context.Context) error {
+			_, err := m.ListSnapshots(ctx, "tank/data")
+			return err
+		},
+		"pools": func(ctx context.Context) error {
+			_, err := m.ListPools(ctx)
+			return err
+		},
+	}
+
+	for name, list := range lists {
+		t.Run(name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+
+			done := make(chan error, 1)
+			go func() { done <- list(ctx) }()
+			select {
+			case err := <-done:
+				if !errors.Is(err, context.DeadlineExceeded) {
+					t.Errorf("error = %v, want context.DeadlineExceeded", err)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("list did not return after its context was cancelled")
+			}
+		})
+	}
+}
+
+const mirrorPoolJSON = `{"output_version":{},"pools":{"tank":{"name":"tank","state":"ONLINE",
+"vdevs":{"tank":{"name":"tank","vdev_type":"root","vdevs":{"mirror-0":{"name":"mirror-0","vdev_type":"mirror","state":"ONLINE",
+"vdevs":{"sda":{"name":"sda","vdev_type":"disk","state":"ONLINE"},"sdb":{"name":"sdb","vdev_type":"disk","state":"ONLINE"}}}}}}}}}`
+
+func TestAddVdev(t *testing.T) {
+	tests := []struct {
+		name      string
+		vdevType  string
+		devices   []string
+		opts      []AddVdevOption
+		want      []string
+		wantError error
+	}{
+		{
+			name:     "mirror",
+			vdevType: "mirror",
+			devices:  []string{"/dev/sdc", "/dev/sdd"},
+			want:     []string{"add", "tank", "mirror", "/dev/sdc", "/dev/sdd"},
+		},
+		{
+			name:     "raidz2",
+			vdevType: "raidz2",
+			devices:  []string{"/dev/sdc", "/dev/sdd", "/dev/sde"},
+			want:     []string{"add", "tank", "raidz2", "/dev/sdc", "/dev/sdd", "/dev/sde"},
+		},
+		{
+			name:      "single_disk_to_mirror",
+			devices:   []string{"/dev/sdc"},
+			wantError: ErrReducesRedundancy,
+		},
+		{
+			name:    "single_disk_forced",
+			devices: []string{"/dev/sdc"},
+			opts:    []AddVdevOption{AllowReducedRedundancy()},
+			want:    []string{"add", "-f", "tank", "/dev/sdc"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exec := sysexec.NewMock()
+			exec.SetOutput("zpool", []byte(mirrorPoolJSON))
+			m := &Manager{exec: exec}
+
+			err := m.AddVdev(context.Background(), "tank", tt.vdevType, tt.devices, tt.opts...)
+			if tt.wantError != nil {
+				if !errors.Is(err, tt.wantError) {
+					t.Fatalf("AddVdev() error = %v, want %v", err, tt.wantError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("AddVdev: %v", err)
+			}
+			cmds := exec.Commands()
+			last := cmds[len(cmds)-1]
+			if !slices.Equal(last.Args, tt.want) {
+				t.Errorf("args = %v, want %v", last.Args, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddVdev_Validation(t *testing.T) {
+	tests := []struct {
+		name     string
+		vdevType string
+		devices  []string
+	}{
+		{"unknown_type", "draid", []string{"/dev/sdc", "/dev/sdd"}},
+		{"no_devices", "", nil},
+		{"stripe_two_devices", "", []string{"/dev/sdc", "/dev/sdd"}},
+		{"mirror_one_device", "mirror", []string{"/dev/sdc"}},
+		{"raidz3_too_few", "raidz3", []string{"/dev/sdc", "/dev/sdd", "/dev/sde"}},
+		{"semicolon", "", []string{"/dev/sdc;rm"}},
+		{"pipe", "", []string{"/dev/sdc|sh"}},
+		{"backtick", "", []string{"/dev/`id`"}},
+		{"subshell", "", []string{"/dev/$(id)"}},
+		{"duplicate", "mirror", []string{"/dev/sdc", "/dev/sdc"}},
+		{"relative", "", []string{"sdc"}},
+		{"option", "", []string{"-f"}},
+		{"outside_dev", "", []string{"/tmp/sdc"}},
+		{"unclean", "", []string{"/dev/../tmp/sdc"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exec := sysexec.NewMock()
+			m := &Manager{exec: exec}
+			err := m.AddVdev(context.Background(), "tank", tt.vdevType, tt.devices)
+			if !errors.Is(err, ErrInvalidVdev) {
+				t.Fatalf("AddVdev() error = %v, want ErrInvalidVdev", err)
+			}
+			if n := len(exec.Commands()); n != 0 {
+				t.Errorf("ran %d commands, want 0", n)
+			}
+		})
+	}
+}
+
+func TestAddVdev_MismatchedReplication(t *testing.T) {
+	exec := trimExec{sysexec.NewMock(), "invalid vdev specification\nuse '-f' to override the following errors:\nmismatched replication level: pool uses mirror and new vdev is raidz\n"}
+	exec.SetOutput("zpool", []byte(mirrorPoolJSON))
+	m := &Manager{exec: exec}
+
+	err := m.AddVdev(context.Background(), "tank", "raidz", []string{"/dev/sdc", "/dev/sdd", "/dev/sde"})
+	if !errors.Is(err, ErrMismatchedReplication) {
+		t.Errorf("AddVdev() error = %v, want ErrMismatchedReplication", err)
+	}
+}
+
+func TestVdevFaultTolerance(t *testing.T) {
+	disks := func(n int) []DiskDetail { return make([]DiskDetail, n) }
+	tests := []struct {
+		name  string
+		vdevs []VDevDetail
+		want  int
+	}{
+		{"empty", nil, 0},
+		{"stripe", []VDevDetail{{Type: "stripe", Children: disks(1)}}, 0},
+		{"three_way_mirror", []VDevDetail{{Type: "mirror", Children: disks(3)}}, 2},
+		{"weakest_wins", []VDevDetail{{Type: "raidz2", Children: disks(6)}, {Type: "mirror", Children: disks(2)}}, 1},
+		{"replacing_counted_once", []VDevDetail{{Type: "mirror", Children: []DiskDetail{{}, {Replacing: true}, {Replacing: true}}}}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := vdevFaultTolerance(tt.vdevs); got != tt.want {
+				t.Errorf("vdevFaultTolerance() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
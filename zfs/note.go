@@ -0,0 +1,53 @@
+package zfs
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+)
+
+// noteProperty is the user property holding a free-form dataset note.
+const noteProperty = "mynt:note"
+
+// maxNoteLen is the ZFS limit on user property values.
+const maxNoteLen = 8191
+
+// SetNote attaches a free-form note to a dataset, e.g. tuning decisions.
+// An empty note removes it. Notes are not inherited by child datasets.
+func (m *Manager) SetNote(ctx context.Context, name, note string) error {
+	defer m.lockPool(name)()
+	if err := validateName(name); err != nil {
+		return err
+	}
+	if len(note) > maxNoteLen {
+		return fmt.Errorf("note exceeds %d bytes", maxNoteLen)
+	}
+
+	if err := m.checkWritable(ctx, name); err != nil {
+		return err
+	}
+
+	args := []string{"inherit", noteProperty, name}
+	if note != "" {
+		args = []string{"set", noteProperty + "=" + note, name}
+	}
+	if out, err := m.runMutation(ctx, "zfs", args...); err != nil {
+		return fmt.Errorf("zfs %s: %s: %w", args[0], bytes.TrimSpace(out), err)
+	}
+	return nil
+}
+
+// GetNote returns the note set on a dataset, or an empty string if none.
+func (m *Manager) GetNote(ctx context.Context, name string) (string, error) {
+	if err := validateName(name); err != nil {
+		return "", err
+	}
+
+	// -s local hides notes inherited from a parent
+	out, err := m.exec.Output(ctx, "zfs", "get", "-H", "-s", "local", "-o", "value", noteProperty, name)
+	if err != nil {
+		return "", fmt.Errorf("zfs get: %w", err)
+	}
+	return strings.TrimSuffix(string(out), "\n"), nil
+}
@@ -0,0 +1,112 @@
+package zfs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// defaultParamsDir is where the ZFS kernel module exposes its tunables.
+const defaultParamsDir = "/sys/module/zfs/parameters"
+
+// ErrParamNotWritable is returned when setting a module parameter that is
+// not on the writable allowlist.
+var ErrParamNotWritable = errors.New("zfs parameter is not writable")
+
+// writableParams lists the module parameters that may be changed at
+// runtime. All of them take a non-negative integer.
+var writableParams = []string{
+	"zfs_arc_max",
+	"zfs_arc_min",
+	"zfs_prefetch_disable",
+	"zfs_txg_timeout",
+	"zfs_resilver_min_time_ms",
+	"zfs_scrub_min_time_ms",
+	"zfs_scan_vdev_limit",
+	"zfs_vdev_scrub_max_active",
+	"zfs_dirty_data_max",
+}
+
+// mockParams is returned on darwin, which has no ZFS module to read.
+var mockParams = map[string]string{
+	"zfs_arc_max":          "0",
+	"zfs_arc_min":          "0",
+	"zfs_prefetch_disable": "0",
+	"zfs_txg_timeout":      "5",
+}
+
+// ZFSParams holds the ZFS module parameters and which of them can be set.
+type ZFSParams struct {
+	Params   map[string]string `json:"params"`
+	Writable []string          `json:"writable"`
+}
+
+// ZFSParams reads the ZFS kernel module parameters.
+func (m *Manager) ZFSParams(ctx context.Context) (*ZFSParams, error) {
+	params := &ZFSParams{Writable: writableParams}
+	if runtime.GOOS == "darwin" {
+		params.Params = mockParams
+		return params, nil
+	}
+
+	dir := m.paramsPath()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read zfs parameters: %w", err)
+	}
+
+	params.Params = make(map[string]string, len(entries))
+	for _, e := range entries {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if e.IsDir() {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			continue // some parameters are write-only or unreadable
+		}
+		params.Params[e.Name()] = strings.TrimSpace(string(data))
+	}
+	return params, nil
+}
+
+// SetZFSParam changes a ZFS kernel module parameter at runtime. Only
+// allowlisted parameters may be set; the change does not survive a reboot.
+func (m *Manager) SetZFSParam(ctx context.Context, name, value string) error {
+	if !slices.Contains(writableParams, name) {
+		return fmt.Errorf("%w: %s", ErrParamNotWritable, name)
+	}
+	if _, err := strconv.ParseUint(value, 10, 64); err != nil {
+		return fmt.Errorf("invalid value %q for %s: must be a non-negative integer", value, name)
+	}
+	if runtime.GOOS == "darwin" {
+		return fmt.Errorf("zfs parameters are not supported on %s", runtime.GOOS)
+	}
+
+	// Open without O_CREATE: a missing file means the module lacks the parameter
+	f, err := os.OpenFile(filepath.Join(m.paramsPath(), name), os.O_WRONLY|os.O_TRUNC, 0)
+	if err != nil {
+		return fmt.Errorf("set zfs parameter %s: %w", name, err)
+	}
+	if _, err := f.WriteString(value + "\n"); err != nil {
+		f.Close()
+		return fmt.Errorf("set zfs parameter %s: %w", name, err)
+	}
+	return f.Close()
+}
+
+// paramsPath returns the module parameters directory.
+func (m *Manager) paramsPath() string {
+	if m.paramsDir != "" {
+		return m.paramsDir
+	}
+	return defaultParamsDir
+}
@@ -0,0 +1,81 @@
+//go:build linux
+
+package zfs
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// fakeParamsDir creates a parameters directory holding the given files.
+func fakeParamsDir(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestZFSParams(t *testing.T) {
+	dir := fakeParamsDir(t, map[string]string{
+		"zfs_arc_max":     "4294967296\n",
+		"zfs_arc_min":     "0\n",
+		"zfs_txg_timeout": "5\n",
+	})
+	m := &Manager{paramsDir: dir}
+
+	params, err := m.ZFSParams(context.Background())
+	if err != nil {
+		t.Fatalf("ZFSParams: %v", err)
+	}
+	want := map[string]string{"zfs_arc_max": "4294967296", "zfs_arc_min": "0", "zfs_txg_timeout": "5"}
+	if len(params.Params) != len(want) {
+		t.Fatalf("params = %v, want %v", params.Params, want)
+	}
+	for k, v := range want {
+		if params.Params[k] != v {
+			t.Errorf("%s = %q, want %q", k, params.Params[k], v)
+		}
+	}
+}
+
+func TestSetZFSParam(t *testing.T) {
+	dir := fakeParamsDir(t, map[string]string{
+		"zfs_arc_max":     "0\n",
+		"zfs_vdev_max_ms": "10\n", // exists, but not on the allowlist
+	})
+	m := &Manager{paramsDir: dir}
+	ctx := context.Background()
+
+	if err := m.SetZFSParam(ctx, "zfs_arc_max", "8589934592"); err != nil {
+		t.Fatalf("SetZFSParam: %v", err)
+	}
+	got, _ := os.ReadFile(filepath.Join(dir, "zfs_arc_max"))
+	if string(got) != "8589934592\n" {
+		t.Errorf("zfs_arc_max = %q", got)
+	}
+
+	if err := m.SetZFSParam(ctx, "zfs_vdev_max_ms", "20"); !errors.Is(err, ErrParamNotWritable) {
+		t.Errorf("non-allowlisted param: err = %v, want ErrParamNotWritable", err)
+	}
+	if err := m.SetZFSParam(ctx, "../zfs_arc_max", "1"); !errors.Is(err, ErrParamNotWritable) {
+		t.Errorf("path traversal: err = %v, want ErrParamNotWritable", err)
+	}
+	if err := m.SetZFSParam(ctx, "zfs_arc_max", "1; reboot"); err == nil {
+		t.Error("non-numeric value: want error")
+	}
+
+	// Allowlisted but missing from this module version: never created
+	if err := m.SetZFSParam(ctx, "zfs_txg_timeout", "10"); err == nil {
+		t.Error("missing parameter file: want error")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "zfs_txg_timeout")); !os.IsNotExist(err) {
+		t.Error("SetZFSParam created a parameter file")
+	}
+}
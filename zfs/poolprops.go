@@ -0,0 +1,122 @@
+package zfs
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ErrPoolPropertyNotSettable is returned when setting a pool property that
+// is not on the settable allowlist, or to a value it does not allow.
+var ErrPoolPropertyNotSettable = errors.New("pool property is not settable")
+
+// onOff is the value set of boolean pool properties.
+var onOff = []string{"on", "off"}
+
+// settablePoolProperties lists the pool properties that may be changed and
+// the values each accepts; nil means free-form (checked separately).
+// Properties that change where or how the pool is imported, or whose
+// effects cannot be undone (feature flags, version), are left out, as is
+// failmode=panic.
+var settablePoolProperties = map[string][]string{
+	"comment":       nil,
+	"autoexpand":    onOff,
+	"autoreplace":   onOff,
+	"autotrim":      onOff,
+	"failmode":      {"wait", "continue"},
+	"listsnapshots": onOff,
+}
+
+// maxPoolComment is the longest comment zpool accepts.
+const maxPoolComment = 32
+
+// PoolProperties holds a pool's properties and which of them can be set.
+type PoolProperties struct {
+	Properties map[string]string `json:"properties"`
+	Settable   []string          `json:"settable"`
+}
+
+// GetPoolProperties returns all properties of a pool, with numeric values
+// in exact form.
+func (m *Manager) GetPoolProperties(ctx context.Context, pool string) (*PoolProperties, error) {
+	if err := validateName(pool); err != nil {
+		return nil, err
+	}
+
+	out, err := m.exec.Output(ctx, "zpool", "get", "-H", "-p", "-o", "property,value", "all", pool)
+	if err != nil {
+		return nil, fmt.Errorf("zpool get: %w", err)
+	}
+
+	settable := make([]string, 0, len(settablePoolProperties))
+	for name := range settablePoolProperties {
+		settable = append(settable, name)
+	}
+	slices.Sort(settable)
+
+	return &PoolProperties{
+		Properties: parsePoolProperties(string(out)),
+		Settable:   settable,
+	}, nil
+}
+
+// parsePoolProperties parses `zpool get -H -o property,value` output.
+// Unset values ("-") are kept, so callers can tell the property exists.
+func parsePoolProperties(out string) map[string]string {
+	props := make(map[string]string)
+	for line := range strings.Lines(out) {
+		key, value, ok := strings.Cut(strings.TrimRight(line, "\n"), "\t")
+		if ok && key != "" {
+			props[key] = value
+		}
+	}
+	return props
+}
+
+// SetPoolProperty sets an allowlisted pool property with zpool set.
+func (m *Manager) SetPoolProperty(ctx context.Context, pool, key, value string) error {
+	if err := validateName(pool); err != nil {
+		return err
+	}
+	if err := validatePoolProperty(key, value); err != nil {
+		return err
+	}
+
+	defer m.lockPool(pool)()
+	if err := m.checkWritable(ctx, pool); err != nil {
+		return err
+	}
+	if out, err := m.runMutation(ctx, "zpool", "set", key+"="+value, pool); err != nil {
+		return fmt.Errorf("failed to set %s: %s: %w", key, bytes.TrimSpace(out), err)
+	}
+	return nil
+}
+
+// validatePoolProperty checks key against the allowlist and value against
+// the values it accepts.
+func validatePoolProperty(key, value string) error {
+	allowed, ok := settablePoolProperties[key]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrPoolPropertyNotSettable, key)
+	}
+	if allowed != nil {
+		if !slices.Contains(allowed, value) {
+			return fmt.Errorf("%w: %s=%s (allowed: %s)", ErrPoolPropertyNotSettable, key, value, strings.Join(allowed, ", "))
+		}
+		return nil
+	}
+
+	// comment: printable ASCII only, as zpool requires
+	if len(value) > maxPoolComment {
+		return fmt.Errorf("%w: comment is longer than %d characters", ErrPoolPropertyNotSettable, maxPoolComment)
+	}
+	for _, c := range value {
+		if c < ' ' || c > '~' {
+			return fmt.Errorf("%w: comment must be printable ASCII", ErrPoolPropertyNotSettable)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package zfs
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"go.aimuz.me/mynt/sysexec"
+)
+
+func TestSetPoolProperty(t *testing.T) {
+	tests := []struct {
+		name     string
+		key      string
+		value    string
+		wantArgs []string
+		wantErr  error
+	}{
+		{name: "autotrim_on", key: "autotrim", value: "on", wantArgs: []string{"set", "autotrim=on", "tank"}},
+		{name: "failmode", key: "failmode", value: "continue", wantArgs: []string{"set", "failmode=continue", "tank"}},
+		{name: "comment", key: "comment", value: "rack 2, shelf 3", wantArgs: []string{"set", "comment=rack 2, shelf 3", "tank"}},
+		{name: "not_allowlisted", key: "altroot", value: "/mnt", wantErr: ErrPoolPropertyNotSettable},
+		{name: "feature_flag", key: "feature@encryption", value: "enabled", wantErr: ErrPoolPropertyNotSettable},
+		{name: "bad_value", key: "autotrim", value: "yes", wantErr: ErrPoolPropertyNotSettable},
+		{name: "failmode_panic", key: "failmode", value: "panic", wantErr: ErrPoolPropertyNotSettable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exec := sysexec.NewMock()
+			m := &Manager{exec: exec}
+
+			err := m.SetPoolProperty(context.Background(), "tank", tt.key, tt.value)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("error = %v, want %v", err, tt.wantErr)
+				}
+				if cmds := exec.Commands(); len(cmds) != 0 {
+					t.Errorf("ran %v, want nothing", cmds)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			cmds := mutationCommands(t, exec)
+			if len(cmds) != 1 || cmds[0].Name != "zpool" || !slices.Equal(cmds[0].Args, tt.wantArgs) {
+				t.Errorf("commands = %v, want zpool %v", cmds, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestSetPoolProperty_InvalidComment(t *testing.T) {
+	m := &Manager{exec: sysexec.NewMock()}
+	for _, comment := range []string{"tab\there", "this comment is far too long for zpool"} {
+		if err := m.SetPoolProperty(context.Background(), "tank", "comment", comment); !errors.Is(err, ErrPoolPropertyNotSettable) {
+			t.Errorf("comment %q: error = %v, want ErrPoolPropertyNotSettable", comment, err)
+		}
+	}
+}
+
+func TestGetPoolProperties(t *testing.T) {
+	exec := sysexec.NewMock()
+	exec.SetOutput("zpool", []byte("size\t1000204886016\nautotrim\ton\ncomment\t-\nfailmode\twait\n"))
+	m := &Manager{exec: exec}
+
+	props, err := m.GetPoolProperties(context.Background(), "tank")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := map[string]string{"size": "1000204886016", "autotrim": "on", "comment": "-", "failmode": "wait"}
+	for k, v := range want {
+		if props.Properties[k] != v {
+			t.Errorf("%s = %q, want %q", k, props.Properties[k], v)
+		}
+	}
+	if !slices.Contains(props.Settable, "autotrim") || slices.Contains(props.Settable, "size") {
+		t.Errorf("Settable = %v", props.Settable)
+	}
+	wantArgs := []string{"get", "-H", "-p", "-o", "property,value", "all", "tank"}
+	if cmds := exec.Commands(); len(cmds) != 1 || !slices.Equal(cmds[0].Args, wantArgs) {
+		t.Errorf("commands = %v, want zpool %v", cmds, wantArgs)
+	}
+}
@@ -0,0 +1,105 @@
+package zfs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// ErrPropertyNotSettable is returned when setting a dataset property that
+// is not on the settable allowlist, such as a read-only one like used.
+var ErrPropertyNotSettable = errors.New("dataset property is not settable")
+
+// settableDatasetProperties lists the dataset properties SetProperties
+// accepts. Properties with their own endpoints (quota, reservation,
+// mountpoint) or managed by mynt (sharesmb, sharenfs) are left out.
+var settableDatasetProperties = []string{
+	"atime",
+	"checksum",
+	"compression",
+	"copies",
+	"dedup",
+	"logbias",
+	"primarycache",
+	"readonly",
+	"recordsize",
+	"redundant_metadata",
+	"relatime",
+	"secondarycache",
+	"snapdir",
+	"sync",
+	"xattr",
+}
+
+// SettableProperties returns the dataset properties SetProperties accepts.
+func SettableProperties() []string {
+	return slices.Clone(settableDatasetProperties)
+}
+
+// GetProperties returns the values of the given dataset properties, with
+// numbers in exact form. With no keys, the settable properties are returned.
+func (m *Manager) GetProperties(ctx context.Context, name string, keys []string) (map[string]string, error) {
+	if err := validateName(name); err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		keys = settableDatasetProperties
+	}
+	for _, key := range keys {
+		if err := validatePropertyKey(key); err != nil {
+			return nil, err
+		}
+	}
+
+	props, err := m.getProperties(ctx, name, true, keys...)
+	if err != nil {
+		return nil, fmt.Errorf("zfs get: %w", err)
+	}
+	return props, nil
+}
+
+// SetProperties sets several allowlisted properties on a dataset, in key
+// order. All keys, and a compression algorithm against those the running
+// ZFS supports, are checked before anything is changed, but a failure part
+// way through leaves the earlier properties set.
+func (m *Manager) SetProperties(ctx context.Context, name string, props map[string]string) error {
+	if err := validateName(name); err != nil {
+		return err
+	}
+	for key := range props {
+		if !slices.Contains(settableDatasetProperties, key) {
+			return fmt.Errorf("%w: %s", ErrPropertyNotSettable, key)
+		}
+	}
+	if algorithm, ok := props["compression"]; ok && !slices.Contains(m.CompressionOptions(ctx).Algorithms, algorithm) {
+		return fmt.Errorf("%w: %q", ErrUnsupportedCompression, algorithm)
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(props)) {
+		if err := m.SetProperty(ctx, name, key, props[key]); err != nil {
+			return fmt.Errorf("set %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// validatePropertyKey checks that key looks like a native or user
+// property name, so it cannot be mistaken for a zfs flag.
+func validatePropertyKey(key string) error {
+	if key == "" || key[0] == '-' {
+		return fmt.Errorf("invalid property name %q", key)
+	}
+	for _, r := range key {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			continue
+		}
+		switch r {
+		case '_', ':', '.', '-':
+			continue
+		}
+		return fmt.Errorf("invalid property name %q", key)
+	}
+	return nil
+}
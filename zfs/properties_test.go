@@ -0,0 +1,73 @@
+package zfs
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"go.aimuz.me/mynt/sysexec"
+)
+
+func TestGetProperties(t *testing.T) {
+	exec := sysexec.NewMock()
+	exec.SetOutput("zfs", []byte("compression\tzstd\nrecordsize\t1048576\n"))
+	m := &Manager{exec: exec}
+
+	props, err := m.GetProperties(context.Background(), "tank/media", []string{"compression", "recordsize"})
+	if err != nil {
+		t.Fatalf("GetProperties: %v", err)
+	}
+	if props["compression"] != "zstd" || props["recordsize"] != "1048576" {
+		t.Errorf("props = %v", props)
+	}
+	want := []string{"get", "-Hp", "-o", "property,value", "compression,recordsize", "tank/media"}
+	if cmds := exec.Commands(); len(cmds) != 1 || !slices.Equal(cmds[0].Args, want) {
+		t.Errorf("commands = %v, want zfs %v", cmds, want)
+	}
+}
+
+func TestGetProperties_InvalidKey(t *testing.T) {
+	exec := sysexec.NewMock()
+	m := &Manager{exec: exec}
+
+	for _, key := range []string{"-r", "comp;rm", "Used"} {
+		if _, err := m.GetProperties(context.Background(), "tank", []string{key}); err == nil {
+			t.Errorf("key %q: expected error", key)
+		}
+	}
+	if cmds := exec.Commands(); len(cmds) != 0 {
+		t.Errorf("ran %v, want nothing", cmds)
+	}
+}
+
+func TestSetProperties_NotSettable(t *testing.T) {
+	exec := sysexec.NewMock()
+	m := &Manager{exec: exec}
+
+	for _, key := range []string{"used", "mountpoint", "sharesmb"} {
+		props := map[string]string{"atime": "off", key: "x"}
+		if err := m.SetProperties(context.Background(), "tank/data", props); !errors.Is(err, ErrPropertyNotSettable) {
+			t.Errorf("%s: error = %v, want ErrPropertyNotSettable", key, err)
+		}
+	}
+	if cmds := exec.Commands(); len(cmds) != 0 {
+		t.Errorf("ran %v, want nothing", cmds)
+	}
+}
+
+func TestSetProperties_UnsupportedCompression(t *testing.T) {
+	exec := sysexec.NewMock()
+	exec.SetOutput("zfs", []byte("zfs-0.8.6-1\nzfs-kmod-0.8.6-1\n"))
+	m := &Manager{exec: exec}
+
+	props := map[string]string{"atime": "off", "compression": "zstd"}
+	if err := m.SetProperties(context.Background(), "tank/data", props); !errors.Is(err, ErrUnsupportedCompression) {
+		t.Fatalf("error = %v, want ErrUnsupportedCompression", err)
+	}
+	for _, c := range exec.Commands() {
+		if c.Args[0] == "set" {
+			t.Errorf("ran %v, want no changes", c)
+		}
+	}
+}
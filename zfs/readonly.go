@@ -0,0 +1,56 @@
+package zfs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrPoolReadOnly is returned when a mutation targets a pool that was
+// imported read-only.
+var ErrPoolReadOnly = errors.New("pool is imported read-only")
+
+// checkWritable fails fast if the pool that name belongs to was imported
+// read-only, rather than letting zfs report a less obvious error halfway
+// through a change. Mutations call it under the pool lock, after
+// validating their arguments. If the property cannot be read the mutation
+// goes ahead and reports its own error.
+func (m *Manager) checkWritable(ctx context.Context, name string) error {
+	pool := poolOf(name)
+	readOnly, err := m.readOnlyPools(ctx, pool)
+	if err == nil && readOnly[pool] {
+		return fmt.Errorf("cannot modify %s: %w", name, ErrPoolReadOnly)
+	}
+	return nil
+}
+
+// readOnlyPools returns the imported pools (or the named ones) whose
+// readonly property is on.
+func (m *Manager) readOnlyPools(ctx context.Context, names ...string) (map[string]bool, error) {
+	return m.poolFlag(ctx, "readonly", names...)
+}
+
+// poolFlag returns the imported pools (or the named ones) whose on/off
+// pool property is on.
+func (m *Manager) poolFlag(ctx context.Context, property string, names ...string) (map[string]bool, error) {
+	args := append([]string{"get", "-H", "-p", "-o", "name,value", property}, names...)
+	out, err := m.exec.Output(ctx, "zpool", args...)
+	if err != nil {
+		return nil, fmt.Errorf("zpool get %s: %w", property, err)
+	}
+	return parsePoolFlag(string(out)), nil
+}
+
+// parsePoolFlag parses `zpool get -H -o name,value <property>` output for
+// an on/off property, returning the pools where it is on.
+func parsePoolFlag(out string) map[string]bool {
+	on := make(map[string]bool)
+	for line := range strings.Lines(out) {
+		name, value, ok := strings.Cut(strings.TrimSpace(line), "\t")
+		if ok && value == "on" {
+			on[name] = true
+		}
+	}
+	return on
+}
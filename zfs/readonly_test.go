@@ -0,0 +1,61 @@
+package zfs
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"go.aimuz.me/mynt/sysexec"
+)
+
+// mutationCommands returns the commands a mutation ran after its
+// read-only check, failing the test if the check did not come first.
+func mutationCommands(t *testing.T, exec *sysexec.MockExecutor) []sysexec.Command {
+	t.Helper()
+	cmds := exec.Commands()
+	if len(cmds) == 0 || cmds[0].Name != "zpool" || !slices.Contains(cmds[0].Args, "readonly") {
+		t.Fatalf("commands = %v, want read-only check first", cmds)
+	}
+	return cmds[1:]
+}
+
+func TestParsePoolFlag(t *testing.T) {
+	got := parsePoolFlag("tank\toff\nrescue\ton\n")
+	if !got["rescue"] || got["tank"] || len(got) != 1 {
+		t.Errorf("parsePoolFlag() = %v, want only rescue", got)
+	}
+}
+
+func TestReadOnlyPool_RefusesMutations(t *testing.T) {
+	ctx := context.Background()
+	mutations := map[string]func(m *Manager) error{
+		"set_note": func(m *Manager) error { return m.SetNote(ctx, "rescue/data", "note") },
+		"rename_snapshot": func(m *Manager) error {
+			return m.RenameSnapshot(ctx, "rescue/data@a", "b")
+		},
+		"create_snapshot": func(m *Manager) error {
+			_, err := m.CreateSnapshot(ctx, CreateSnapshotRequest{Dataset: "rescue/data", Name: "s"})
+			return err
+		},
+		"create_volume": func(m *Manager) error {
+			return m.CreateDataset(ctx, CreateDatasetRequest{Name: "rescue/vol", Type: "volume", Quota: 1 << 20})
+		},
+		"replace_disk": func(m *Manager) error { return m.ReplaceDisk(ctx, "rescue", "sda", "sdb") },
+	}
+
+	for name, mutate := range mutations {
+		t.Run(name, func(t *testing.T) {
+			exec := sysexec.NewMock()
+			exec.SetOutput("zpool", []byte("rescue\ton\n"))
+			m := &Manager{exec: exec}
+
+			if err := mutate(m); !errors.Is(err, ErrPoolReadOnly) {
+				t.Fatalf("error = %v, want ErrPoolReadOnly", err)
+			}
+			if cmds := mutationCommands(t, exec); len(cmds) != 0 {
+				t.Errorf("ran %v after the read-only check, want nothing", cmds)
+			}
+		})
+	}
+}
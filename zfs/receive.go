@@ -0,0 +1,152 @@
+package zfs
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrReceiveTargetExists is returned when zfs receive refuses to write
+// over an existing dataset.
+var ErrReceiveTargetExists = errors.New("receive target already exists")
+
+// ReceiveResult describes a completed receive from a file.
+type ReceiveResult struct {
+	Dataset    string `json:"dataset"`
+	FileBytes  int64  `json:"file_bytes"` // bytes read from the file
+	Compressed bool   `json:"compressed"` // whether the file was gzipped
+}
+
+// ReceiveOption configures ReceiveFromFile.
+type ReceiveOption func(*receiveOptions)
+
+type receiveOptions struct {
+	progress func(read, total int64)
+}
+
+// ReceiveProgress reports the bytes of the file read so far and its size.
+func ReceiveProgress(fn func(read, total int64)) ReceiveOption {
+	return func(o *receiveOptions) { o.progress = fn }
+}
+
+// gzipMagic is the header every gzip stream starts with.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// CheckReceiveSource verifies that srcPath is an absolute, clean path to a
+// regular file under the backup root. Symlinks are resolved before
+// comparing the file with the root.
+func (m *Manager) CheckReceiveSource(srcPath string) error {
+	if !filepath.IsAbs(srcPath) || filepath.Clean(srcPath) != srcPath {
+		return fmt.Errorf("source must be a clean absolute path")
+	}
+	realPath, err := filepath.EvalSymlinks(srcPath)
+	if err != nil {
+		return fmt.Errorf("source file: %w", err)
+	}
+	if err := m.checkBackupRoot(realPath); err != nil {
+		return fmt.Errorf("source %w", err)
+	}
+	fi, err := os.Stat(realPath)
+	if err != nil {
+		return fmt.Errorf("source file: %w", err)
+	}
+	if !fi.Mode().IsRegular() {
+		return fmt.Errorf("source %s is not a regular file", srcPath)
+	}
+	return nil
+}
+
+// CheckReceiveTarget verifies that target is a dataset name on a pool that
+// is not imported read-only.
+func (m *Manager) CheckReceiveTarget(ctx context.Context, target string) error {
+	if strings.Contains(target, "@") {
+		return fmt.Errorf("target must be a dataset name, not a snapshot")
+	}
+	if err := validateName(target); err != nil {
+		return err
+	}
+	return m.checkWritable(ctx, target)
+}
+
+// ReceiveFromFile restores a send stream file under the backup root, such
+// as one written by SendToFile, into targetDataset. Gzipped files are
+// detected and decompressed. The target must not exist, and its pool must
+// not be imported read-only.
+func (m *Manager) ReceiveFromFile(ctx context.Context, srcPath, targetDataset string, opts ...ReceiveOption) (*ReceiveResult, error) {
+	var o receiveOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if err := m.CheckReceiveSource(srcPath); err != nil {
+		return nil, err
+	}
+	if err := m.CheckReceiveTarget(ctx, targetDataset); err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(srcPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var total int64
+	if fi, err := f.Stat(); err == nil {
+		total = fi.Size()
+	}
+	counter := &countingReader{r: f, total: total, progress: o.progress}
+	br := bufio.NewReader(counter)
+
+	res := &ReceiveResult{Dataset: targetDataset}
+	var stream io.Reader = br
+	if magic, err := br.Peek(len(gzipMagic)); err == nil && bytes.Equal(magic, gzipMagic) {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("open gzip stream: %w", err)
+		}
+		defer gz.Close()
+		stream = gz
+		res.Compressed = true
+	}
+
+	if err := m.exec.Feed(ctx, stream, "zfs", "receive", targetDataset); err != nil {
+		return nil, receiveError(targetDataset, err)
+	}
+
+	res.FileBytes = counter.n
+	return res, nil
+}
+
+// receiveError wraps a failed zfs receive into target, mapping an existing
+// target to ErrReceiveTargetExists.
+func receiveError(target string, err error) error {
+	if strings.Contains(err.Error(), "destination") && strings.Contains(err.Error(), "exists") {
+		return fmt.Errorf("%w: %s", ErrReceiveTargetExists, target)
+	}
+	return fmt.Errorf("zfs receive %s: %w", target, err)
+}
+
+// countingReader counts bytes read through it and reports progress.
+type countingReader struct {
+	r        io.Reader
+	n        int64
+	total    int64
+	progress func(read, total int64)
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	if c.progress != nil && n > 0 {
+		c.progress(c.n, c.total)
+	}
+	return n, err
+}
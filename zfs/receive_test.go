@@ -0,0 +1,140 @@
+package zfs
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"go.aimuz.me/mynt/sysexec"
+)
+
+func TestReceiveFromFile(t *testing.T) {
+	stream := []byte("full send stream bytes")
+
+	var gzipped bytes.Buffer
+	gz := gzip.NewWriter(&gzipped)
+	gz.Write(stream)
+	gz.Close()
+
+	tests := []struct {
+		name           string
+		content        []byte
+		wantCompressed bool
+	}{
+		{"plain", stream, false},
+		{"gzip", gzipped.Bytes(), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			src := filepath.Join(root, "backup.zfs")
+			if err := os.WriteFile(src, tt.content, 0o600); err != nil {
+				t.Fatal(err)
+			}
+			exec := sysexec.NewMock()
+			m := &Manager{exec: exec, backupRoot: root}
+
+			var read int64
+			res, err := m.ReceiveFromFile(context.Background(), src, "tank/restored",
+				ReceiveProgress(func(n, _ int64) { read = n }))
+			if err != nil {
+				t.Fatalf("ReceiveFromFile: %v", err)
+			}
+			if res.Compressed != tt.wantCompressed {
+				t.Errorf("Compressed = %v, want %v", res.Compressed, tt.wantCompressed)
+			}
+			if res.FileBytes != int64(len(tt.content)) || read != res.FileBytes {
+				t.Errorf("file bytes = %d, progress = %d, want %d", res.FileBytes, read, len(tt.content))
+			}
+
+			cmds := mutationCommands(t, exec)
+			if len(cmds) != 1 {
+				t.Fatalf("got %d commands, want 1", len(cmds))
+			}
+			if want := []string{"receive", "tank/restored"}; !slices.Equal(cmds[0].Args, want) {
+				t.Errorf("args = %v, want %v", cmds[0].Args, want)
+			}
+			if !bytes.Equal(cmds[0].Stdin, stream) {
+				t.Errorf("stdin = %q, want %q", cmds[0].Stdin, stream)
+			}
+		})
+	}
+}
+
+func TestReceiveFromFile_TargetExists(t *testing.T) {
+	root := t.TempDir()
+	src := filepath.Join(root, "backup.zfs")
+	if err := os.WriteFile(src, []byte("stream"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	exec := sysexec.NewMock()
+	exec.SetError("zfs", errors.New("exit status 1: cannot receive new filesystem stream: destination 'tank/data' exists"))
+	m := &Manager{exec: exec, backupRoot: root}
+
+	_, err := m.ReceiveFromFile(context.Background(), src, "tank/data")
+	if !errors.Is(err, ErrReceiveTargetExists) {
+		t.Fatalf("ReceiveFromFile() error = %v, want ErrReceiveTargetExists", err)
+	}
+}
+
+func TestReceiveFromFile_Validation(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "backup.zfs")
+	outside := filepath.Join(t.TempDir(), "backup.zfs")
+	for _, path := range []string{src, outside} {
+		if err := os.WriteFile(path, []byte("stream"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		src    string
+		target string
+	}{
+		{"bad_characters", src, "tank/data;rm"},
+		{"snapshot_target", src, "tank/data@snap"},
+		{"empty_target", src, ""},
+		{"relative_source", "backup.zfs", "tank/data"},
+		{"missing_source", filepath.Join(dir, "missing.zfs"), "tank/data"},
+		{"directory_source", dir, "tank/data"},
+		{"outside_backup_root", outside, "tank/data"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exec := sysexec.NewMock()
+			m := &Manager{exec: exec, backupRoot: dir}
+			if _, err := m.ReceiveFromFile(context.Background(), tt.src, tt.target); err == nil {
+				t.Fatal("ReceiveFromFile() error = nil, want error")
+			}
+			if n := len(exec.Commands()); n != 0 {
+				t.Errorf("ran %d commands, want 0", n)
+			}
+		})
+	}
+}
+
+func TestReceiveFromFile_ReadOnlyPool(t *testing.T) {
+	root := t.TempDir()
+	src := filepath.Join(root, "backup.zfs")
+	if err := os.WriteFile(src, []byte("stream"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	exec := sysexec.NewMock()
+	exec.SetOutput("zpool", []byte("rescue\ton\n"))
+	m := &Manager{exec: exec, backupRoot: root}
+
+	if _, err := m.ReceiveFromFile(context.Background(), src, "rescue/restored"); !errors.Is(err, ErrPoolReadOnly) {
+		t.Fatalf("ReceiveFromFile() error = %v, want ErrPoolReadOnly", err)
+	}
+	if cmds := mutationCommands(t, exec); len(cmds) != 0 {
+		t.Errorf("ran %v after the read-only check, want nothing", cmds)
+	}
+}
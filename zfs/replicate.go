@@ -0,0 +1,91 @@
+package zfs
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// SendOptions configures Send.
+type SendOptions struct {
+	// From is the base snapshot of an incremental stream, either in full
+	// (tank/data@monday) or as @monday on the same dataset. Empty sends a
+	// full stream.
+	From string `json:"from,omitempty"`
+	// Intermediate includes every snapshot between From and the sent
+	// snapshot (zfs send -I) rather than only the difference (-i).
+	Intermediate bool `json:"intermediate,omitempty"`
+	// Recursive replicates descendant datasets with their properties and
+	// snapshots (zfs send -R).
+	Recursive bool `json:"recursive,omitempty"`
+}
+
+// args returns the zfs send arguments for snapshot.
+func (o SendOptions) args(snapshot string) []string {
+	args := []string{"send"}
+	if o.Recursive {
+		args = append(args, "-R")
+	}
+	if o.From != "" {
+		if o.Intermediate {
+			args = append(args, "-I", o.From)
+		} else {
+			args = append(args, "-i", o.From)
+		}
+	}
+	return append(args, snapshot)
+}
+
+// validate checks the base snapshot name.
+func (o SendOptions) validate() error {
+	if o.From == "" {
+		if o.Intermediate {
+			return fmt.Errorf("intermediate requires a base snapshot")
+		}
+		return nil
+	}
+	if !strings.Contains(o.From, "@") {
+		return fmt.Errorf("invalid base snapshot format (expected dataset@snapshot or @snapshot)")
+	}
+	return validateName(strings.TrimPrefix(o.From, "@"))
+}
+
+// Send writes a send stream of snapshot to w, for replication to another
+// host with Receive. Cancelling ctx kills zfs send.
+func (m *Manager) Send(ctx context.Context, snapshot string, w io.Writer, opts SendOptions) error {
+	if !strings.Contains(snapshot, "@") {
+		return fmt.Errorf("invalid snapshot name format (expected dataset@snapshot)")
+	}
+	if err := validateName(snapshot); err != nil {
+		return err
+	}
+	if err := opts.validate(); err != nil {
+		return err
+	}
+
+	if err := m.exec.Stream(ctx, w, "zfs", opts.args(snapshot)...); err != nil {
+		return fmt.Errorf("zfs send %s: %w", snapshot, err)
+	}
+	return nil
+}
+
+// Receive reads a send stream from r into target. A full stream creates
+// target, which must not exist; an incremental stream updates it.
+// Cancelling ctx kills zfs receive, which discards the partial receive.
+func (m *Manager) Receive(ctx context.Context, target string, r io.Reader) error {
+	if strings.Contains(target, "@") {
+		return fmt.Errorf("target must be a dataset name, not a snapshot")
+	}
+	if err := validateName(target); err != nil {
+		return err
+	}
+	if err := m.checkWritable(ctx, target); err != nil {
+		return err
+	}
+
+	if err := m.exec.Feed(ctx, r, "zfs", "receive", target); err != nil {
+		return receiveError(target, err)
+	}
+	return nil
+}
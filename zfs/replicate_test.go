@@ -0,0 +1,108 @@
+package zfs
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"go.aimuz.me/mynt/sysexec"
+)
+
+func TestSend(t *testing.T) {
+	tests := []struct {
+		name string
+		opts SendOptions
+		want []string
+	}{
+		{"full", SendOptions{}, []string{"send", "tank/data@b"}},
+		{"incremental", SendOptions{From: "@a"}, []string{"send", "-i", "@a", "tank/data@b"}},
+		{"intermediate", SendOptions{From: "tank/data@a", Intermediate: true}, []string{"send", "-I", "tank/data@a", "tank/data@b"}},
+		{"recursive", SendOptions{Recursive: true, From: "@a"}, []string{"send", "-R", "-i", "@a", "tank/data@b"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exec := sysexec.NewMock()
+			exec.SetOutput("zfs", []byte("stream"))
+			m := &Manager{exec: exec}
+
+			var buf bytes.Buffer
+			if err := m.Send(context.Background(), "tank/data@b", &buf, tt.opts); err != nil {
+				t.Fatalf("Send: %v", err)
+			}
+			if buf.String() != "stream" {
+				t.Errorf("stream = %q, want %q", buf.String(), "stream")
+			}
+			cmds := exec.Commands()
+			if len(cmds) != 1 || !slices.Equal(cmds[0].Args, tt.want) {
+				t.Errorf("commands = %v, want args %v", cmds, tt.want)
+			}
+		})
+	}
+}
+
+func TestSend_Validation(t *testing.T) {
+	tests := []struct {
+		name     string
+		snapshot string
+		opts     SendOptions
+	}{
+		{"not_snapshot", "tank/data", SendOptions{}},
+		{"bad_characters", "tank/data@b;rm", SendOptions{}},
+		{"base_not_snapshot", "tank/data@b", SendOptions{From: "tank/data"}},
+		{"bad_base", "tank/data@b", SendOptions{From: "@a;rm"}},
+		{"intermediate_without_base", "tank/data@b", SendOptions{Intermediate: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exec := sysexec.NewMock()
+			m := &Manager{exec: exec}
+			if err := m.Send(context.Background(), tt.snapshot, &bytes.Buffer{}, tt.opts); err == nil {
+				t.Fatal("Send() error = nil, want error")
+			}
+			if n := len(exec.Commands()); n != 0 {
+				t.Errorf("ran %d commands, want 0", n)
+			}
+		})
+	}
+}
+
+func TestReceive(t *testing.T) {
+	exec := sysexec.NewMock()
+	m := &Manager{exec: exec}
+
+	if err := m.Receive(context.Background(), "tank/restored", strings.NewReader("stream")); err != nil {
+		t.Fatalf("Receive: %v", err)
+	}
+
+	cmds := mutationCommands(t, exec)
+	if len(cmds) != 1 {
+		t.Fatalf("got %d commands, want 1", len(cmds))
+	}
+	if want := []string{"receive", "tank/restored"}; !slices.Equal(cmds[0].Args, want) {
+		t.Errorf("args = %v, want %v", cmds[0].Args, want)
+	}
+	if string(cmds[0].Stdin) != "stream" {
+		t.Errorf("stdin = %q, want %q", cmds[0].Stdin, "stream")
+	}
+}
+
+func TestReceive_Errors(t *testing.T) {
+	for _, target := range []string{"", "tank/data@snap", "tank/data;rm"} {
+		m := &Manager{exec: sysexec.NewMock()}
+		if err := m.Receive(context.Background(), target, strings.NewReader("stream")); err == nil {
+			t.Errorf("Receive(%q) error = nil, want error", target)
+		}
+	}
+
+	exec := sysexec.NewMock()
+	exec.SetError("zfs", errors.New("exit status 1: cannot receive new filesystem stream: destination 'tank/data' exists"))
+	m := &Manager{exec: exec}
+	if err := m.Receive(context.Background(), "tank/data", strings.NewReader("stream")); !errors.Is(err, ErrReceiveTargetExists) {
+		t.Fatalf("Receive() error = %v, want ErrReceiveTargetExists", err)
+	}
+}
@@ -0,0 +1,86 @@
+package zfs
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"go.aimuz.me/mynt/logger"
+)
+
+// RetryPolicy controls how mutations that fail with a transient error,
+// such as a briefly busy dataset, are retried.
+type RetryPolicy struct {
+	Attempts int           // total tries including the first; 1 or less disables retries
+	Backoff  time.Duration // wait before the first retry, doubled for each one after
+}
+
+// DefaultRetryPolicy retries twice, waiting 500ms and then 1s.
+var DefaultRetryPolicy = RetryPolicy{Attempts: 3, Backoff: 500 * time.Millisecond}
+
+// WithRetryPolicy sets how mutations retry transient errors.
+func WithRetryPolicy(p RetryPolicy) ManagerOption {
+	return func(m *Manager) {
+		m.retryPolicy = p
+	}
+}
+
+// transientErrors are zfs and zpool messages for conditions that usually
+// clear up on their own within a second or two.
+var transientErrors = []string{
+	"pool I/O is currently suspended",
+	"dataset is busy",
+	"pool is busy",
+	"resource busy",
+}
+
+// isTransient reports whether err, or the command output that came with
+// it, looks like a transient failure worth retrying.
+func isTransient(err error, out []byte) bool {
+	msg := err.Error() + " " + string(out)
+	for _, s := range transientErrors {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// withRetry calls op until it succeeds, fails with a non-transient error,
+// or the retry policy runs out. op returns the command output, if any, so
+// that messages zfs only printed can be recognised.
+func (m *Manager) withRetry(ctx context.Context, op func() ([]byte, error)) ([]byte, error) {
+	backoff := m.retryPolicy.Backoff
+	for attempt := 1; ; attempt++ {
+		out, err := op()
+		if err == nil || attempt >= m.retryPolicy.Attempts || !isTransient(err, out) {
+			return out, err
+		}
+
+		logger.Debug("retrying transient zfs error", "attempt", attempt, "error", err, "output", strings.TrimSpace(string(out)))
+		select {
+		case <-ctx.Done():
+			return out, errors.Join(err, ctx.Err())
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// runMutation runs a mutating zfs or zpool command, retrying transient
+// failures, and returns its combined output.
+func (m *Manager) runMutation(ctx context.Context, name string, args ...string) ([]byte, error) {
+	return m.withRetry(ctx, func() ([]byte, error) {
+		return m.exec.CombinedOutput(ctx, name, args...)
+	})
+}
+
+// retry runs a mutation done through the go-zfs library, whose errors
+// already include the command's stderr, retrying transient failures.
+func (m *Manager) retry(ctx context.Context, op func() error) error {
+	_, err := m.withRetry(ctx, func() ([]byte, error) {
+		return nil, op()
+	})
+	return err
+}
@@ -0,0 +1,84 @@
+package zfs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.aimuz.me/mynt/sysexec"
+)
+
+// flakyExec fails mutating commands with the given output a number of
+// times before letting them succeed.
+type flakyExec struct {
+	*sysexec.MockExecutor
+	failures int
+	output   string
+	calls    int
+}
+
+func (e *flakyExec) CombinedOutput(ctx context.Context, name string, args ...string) ([]byte, error) {
+	e.calls++
+	if e.calls <= e.failures {
+		return []byte(e.output), errors.New("exit status 1")
+	}
+	return nil, nil
+}
+
+func TestRunMutation_Retry(t *testing.T) {
+	policy := RetryPolicy{Attempts: 3, Backoff: time.Millisecond}
+	tests := []struct {
+		name      string
+		failures  int
+		output    string
+		wantErr   bool
+		wantCalls int
+	}{
+		{"busy_twice_then_ok", 2, "cannot set property for 'tank/data': dataset is busy", false, 3},
+		{"suspended_once", 1, "cannot open 'tank': pool I/O is currently suspended", false, 2},
+		{"busy_too_long", 5, "cannot set property for 'tank/data': dataset is busy", true, 3},
+		{"permanent_error", 5, "cannot open 'tank/data': dataset does not exist", true, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exec := &flakyExec{MockExecutor: sysexec.NewMock(), failures: tt.failures, output: tt.output}
+			m := &Manager{exec: exec, retryPolicy: policy}
+
+			err := m.SetNote(context.Background(), "tank/data", "note")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if exec.calls != tt.wantCalls {
+				t.Errorf("ran zfs %d times, want %d", exec.calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestRunMutation_NoRetryByDefault(t *testing.T) {
+	exec := &flakyExec{MockExecutor: sysexec.NewMock(), failures: 1, output: "dataset is busy"}
+	m := &Manager{exec: exec}
+
+	if err := m.SetNote(context.Background(), "tank/data", "note"); err == nil {
+		t.Fatal("expected error without a retry policy")
+	}
+	if exec.calls != 1 {
+		t.Errorf("ran zfs %d times, want 1", exec.calls)
+	}
+}
+
+func TestRunMutation_ContextCancelled(t *testing.T) {
+	exec := &flakyExec{MockExecutor: sysexec.NewMock(), failures: 5, output: "dataset is busy"}
+	m := &Manager{exec: exec, retryPolicy: RetryPolicy{Attempts: 5, Backoff: time.Hour}}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := m.runMutation(ctx, "zfs", "set", "atime=off", "tank/data"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("error = %v, want deadline exceeded", err)
+	}
+	if exec.calls != 1 {
+		t.Errorf("ran zfs %d times, want 1", exec.calls)
+	}
+}
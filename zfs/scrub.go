@@ -0,0 +1,177 @@
+package zfs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.aimuz.me/mynt/logger"
+)
+
+// ScrubStop cancels a running scrub on a pool. A later Scrub starts over
+// from the beginning.
+func (m *Manager) ScrubStop(ctx context.Context, poolName string) error {
+	if err := validateName(poolName); err != nil {
+		return err
+	}
+	if out, err := m.exec.CombinedOutput(ctx, "zpool", "scrub", "-s", poolName); err != nil {
+		return fmt.Errorf("stop scrub on pool %s: %s: %w", poolName, bytes.TrimSpace(out), err)
+	}
+	return nil
+}
+
+// ScrubPause pauses a running scrub on a pool; a later Scrub resumes it
+// where it left off. ZFS versions without scrub pause reject -p, in which
+// case the scrub is stopped instead.
+func (m *Manager) ScrubPause(ctx context.Context, poolName string) error {
+	if err := validateName(poolName); err != nil {
+		return err
+	}
+	out, err := m.exec.CombinedOutput(ctx, "zpool", "scrub", "-p", poolName)
+	if err == nil {
+		return nil
+	}
+	if isInvalidOption(err, out) {
+		logger.Warn("zpool scrub -p not supported, stopping scrub instead", "pool", poolName)
+		return m.ScrubStop(ctx, poolName)
+	}
+	return fmt.Errorf("pause scrub on pool %s: %s: %w", poolName, bytes.TrimSpace(out), err)
+}
+
+// isInvalidOption reports whether a zpool command failed because it does
+// not know one of the flags it was given.
+func isInvalidOption(err error, out []byte) bool {
+	return strings.Contains(err.Error()+" "+string(out), "invalid option")
+}
+
+// GetScrubStatus returns the state of a pool's current or last scrub, or
+// nil if the pool was never scrubbed.
+func (m *Manager) GetScrubStatus(ctx context.Context, poolName string) (*ScrubStatus, error) {
+	scrub, _, err := m.ScanStatus(ctx, poolName)
+	return scrub, err
+}
+
+// ScanStatus returns the scrub and resilver state of a pool. The scrub
+// status is nil if the pool was never scrubbed. It reads zpool status
+// -j, and falls back to parsing the text output on ZFS versions without
+// JSON support.
+func (m *Manager) ScanStatus(ctx context.Context, poolName string) (*ScrubStatus, *ResilverStatus, error) {
+	if err := validateName(poolName); err != nil {
+		return nil, nil, err
+	}
+
+	var out bytes.Buffer
+	err := m.exec.Stream(ctx, &out, "zpool", "status", "-p", "-j", poolName)
+	if err != nil && isInvalidOption(err, nil) {
+		text, err := m.exec.Output(ctx, "zpool", "status", "-p", poolName)
+		if err != nil {
+			return nil, nil, fmt.Errorf("zpool status: %w", err)
+		}
+		scan := parseScanText(string(text))
+		return parseScrubFromJSON(scan), parseResilverFromJSON(scan), nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("zpool status: %w", err)
+	}
+
+	var status ZpoolStatusJSON
+	if err := json.Unmarshal(out.Bytes(), &status); err != nil {
+		return nil, nil, fmt.Errorf("parse zpool status: %w", err)
+	}
+	pj := status.Pools[poolName]
+	if pj == nil {
+		return nil, nil, fmt.Errorf("pool %s not found", poolName)
+	}
+	return parseScrubFromJSON(pj.ScanStats), parseResilverFromJSON(pj.ScanStats), nil
+}
+
+// PoolStatusText returns the human-readable zpool status output of a pool.
+func (m *Manager) PoolStatusText(ctx context.Context, poolName string) (string, error) {
+	if err := validateName(poolName); err != nil {
+		return "", err
+	}
+	out, err := m.exec.Output(ctx, "zpool", "status", poolName)
+	if err != nil {
+		return "", fmt.Errorf("zpool status: %w", err)
+	}
+	return string(out), nil
+}
+
+// zpoolTimeLayout is how zpool status prints scan start and end times.
+const zpoolTimeLayout = "Mon Jan _2 15:04:05 2006"
+
+var (
+	scanErrorsRe   = regexp.MustCompile(`with (\d+) errors on (.+)$`)
+	scanProgressRe = regexp.MustCompile(`^(\S+)(?: / (\S+))? scanned at (\S+)/s`)
+	scanIssuedRe   = regexp.MustCompile(`(\S+)(?: / \S+)? issued`)
+	scanTotalRe    = regexp.MustCompile(`(\S+) total`)
+)
+
+// parseScanText extracts the scan statistics from `zpool status -p` text
+// output, in the form zpool status -j reports them, or returns nil if the
+// pool has no scan line. Only the fields parseScrubFromJSON and
+// parseResilverFromJSON use are filled in.
+func parseScanText(out string) *ScanStatsJSON {
+	lines := strings.Split(out, "\n")
+	for i, line := range lines {
+		rest, ok := strings.CutPrefix(strings.TrimSpace(line), "scan:")
+		if !ok {
+			continue
+		}
+		rest = strings.TrimSpace(rest)
+
+		scan := &ScanStatsJSON{Function: "SCRUB"}
+		switch {
+		case strings.HasPrefix(rest, "resilver"):
+			scan.Function = "RESILVER"
+		case !strings.HasPrefix(rest, "scrub"):
+			return nil
+		}
+
+		if start, ok := cutAfter(rest, " in progress since "); ok {
+			scan.State = "SCANNING"
+			scan.StartTime = start
+		} else if start, ok := cutAfter(rest, " paused since "); ok {
+			scan.State = "SCANNING"
+			scan.ScrubPause = start
+		} else if end, ok := cutAfter(rest, " canceled on "); ok {
+			scan.State = "CANCELED"
+			scan.EndTime = end
+		} else if m := scanErrorsRe.FindStringSubmatch(rest); m != nil {
+			scan.State = "FINISHED"
+			scan.Errors = m[1]
+			scan.EndTime = m[2]
+		} else {
+			return nil
+		}
+
+		if t, err := time.ParseInLocation(zpoolTimeLayout, scan.StartTime, time.Local); err == nil {
+			scan.PassStart = strconv.FormatInt(t.Unix(), 10)
+		}
+		if scan.State == "SCANNING" && i+1 < len(lines) {
+			progress := strings.TrimSpace(lines[i+1])
+			if m := scanProgressRe.FindStringSubmatch(progress); m != nil {
+				scan.Examined, scan.ToExamine, scan.BytesPerScan = m[1], m[2], m[3]
+			}
+			if m := scanIssuedRe.FindStringSubmatch(progress); m != nil {
+				scan.Issued = m[1]
+			}
+			if m := scanTotalRe.FindStringSubmatch(progress); m != nil && scan.ToExamine == "" {
+				scan.ToExamine = m[1]
+			}
+		}
+		return scan
+	}
+	return nil
+}
+
+// cutAfter returns what follows sep in s.
+func cutAfter(s, sep string) (string, bool) {
+	_, after, ok := strings.Cut(s, sep)
+	return strings.TrimSpace(after), ok
+}
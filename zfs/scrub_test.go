@@ -0,0 +1,198 @@
+package zfs
+
+import (
+	"context"
+	"errors"
+	"io"
+	"slices"
+	"testing"
+
+	"go.aimuz.me/mynt/sysexec"
+)
+
+func TestScrubActions(t *testing.T) {
+	tests := []struct {
+		name   string
+		action func(m *Manager, ctx context.Context, pool string) error
+		err    error // returned for every zpool call
+		want   [][]string
+	}{
+		{
+			name:   "start",
+			action: (*Manager).Scrub,
+			want:   [][]string{{"scrub", "tank"}},
+		},
+		{
+			name:   "stop",
+			action: (*Manager).ScrubStop,
+			want:   [][]string{{"scrub", "-s", "tank"}},
+		},
+		{
+			name:   "pause",
+			action: (*Manager).ScrubPause,
+			want:   [][]string{{"scrub", "-p", "tank"}},
+		},
+		{
+			name:   "pause_unsupported_stops",
+			action: (*Manager).ScrubPause,
+			err:    errors.New("invalid option 'p'"),
+			want:   [][]string{{"scrub", "-p", "tank"}, {"scrub", "-s", "tank"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exec := sysexec.NewMock()
+			if tt.err != nil {
+				exec.SetError("zpool", tt.err)
+			}
+			m := &Manager{exec: exec}
+
+			_ = tt.action(m, context.Background(), "tank")
+
+			var got [][]string
+			for _, c := range exec.Commands() {
+				got = append(got, c.Args)
+			}
+			if !slices.EqualFunc(got, tt.want, slices.Equal) {
+				t.Errorf("zpool calls = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScrubPause_OtherErrorNotRetried(t *testing.T) {
+	exec := sysexec.NewMock()
+	exec.SetError("zpool", errors.New("cannot pause scrubbing tank: there is no active scrub"))
+	m := &Manager{exec: exec}
+
+	if err := m.ScrubPause(context.Background(), "tank"); err == nil {
+		t.Fatal("expected error")
+	}
+	if n := len(exec.Commands()); n != 1 {
+		t.Errorf("ran %d commands, want 1", n)
+	}
+}
+
+func TestScrubStop_InvalidPool(t *testing.T) {
+	exec := sysexec.NewMock()
+	m := &Manager{exec: exec}
+
+	if err := m.ScrubStop(context.Background(), "tank;rm"); err == nil {
+		t.Fatal("expected error for invalid pool name")
+	}
+	if cmds := exec.Commands(); len(cmds) != 0 {
+		t.Errorf("ran %v, want nothing", cmds)
+	}
+}
+
+// noJSONExec behaves like a zpool that predates -j.
+type noJSONExec struct {
+	*sysexec.MockExecutor
+}
+
+func (e *noJSONExec) Stream(ctx context.Context, w io.Writer, name string, args ...string) error {
+	if slices.Contains(args, "-j") {
+		return errors.New("exit status 2: invalid option 'j'")
+	}
+	return e.MockExecutor.Stream(ctx, w, name, args...)
+}
+
+const scrubRunningText = `  pool: tank
+ state: ONLINE
+  scan: scrub in progress since Sun Oct 11 00:00:01 2026
+	1000 / 4000 scanned at 100/s, 800 / 4000 issued at 80/s
+	0 repaired, 20.00% done, 00:00:32 to go
+config:
+`
+
+func TestParseScanText(t *testing.T) {
+	tests := []struct {
+		name         string
+		out          string
+		wantFunction string
+		wantState    string
+		wantErrors   string
+		wantExamined string
+		wantTotal    string
+		wantRate     string
+	}{
+		{
+			name:         "scrub_running",
+			out:          scrubRunningText,
+			wantFunction: "SCRUB", wantState: "SCANNING",
+			wantExamined: "1000", wantTotal: "4000", wantRate: "100",
+		},
+		{
+			name:         "scrub_finished",
+			out:          "  scan: scrub repaired 0B in 00:00:01 with 3 errors on Sun Oct 11 00:24:02 2026\n",
+			wantFunction: "SCRUB", wantState: "FINISHED", wantErrors: "3",
+		},
+		{
+			name:         "scrub_canceled",
+			out:          "  scan: scrub canceled on Sun Oct 11 00:24:02 2026\n",
+			wantFunction: "SCRUB", wantState: "CANCELED",
+		},
+		{
+			name:         "resilver_running",
+			out:          "  scan: resilver in progress since Sun Oct 11 00:00:01 2026\n\t500 / 2000 scanned at 50/s, 400 / 2000 issued at 40/s\n",
+			wantFunction: "RESILVER", wantState: "SCANNING",
+			wantExamined: "500", wantTotal: "2000", wantRate: "50",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scan := parseScanText(tt.out)
+			if scan == nil {
+				t.Fatal("parseScanText() = nil")
+			}
+			got := []string{scan.Function, scan.State, scan.Errors, scan.Examined, scan.ToExamine, scan.BytesPerScan}
+			want := []string{tt.wantFunction, tt.wantState, tt.wantErrors, tt.wantExamined, tt.wantTotal, tt.wantRate}
+			if !slices.Equal(got, want) {
+				t.Errorf("parseScanText() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestParseScanText_NoScan(t *testing.T) {
+	if scan := parseScanText("  pool: tank\n state: ONLINE\n  scan: none requested\n"); scan != nil {
+		t.Errorf("parseScanText() = %+v, want nil", scan)
+	}
+}
+
+func TestGetScrubStatus(t *testing.T) {
+	const statusJSON = `{"output_version":{},"pools":{"tank":{"name":"tank","state":"ONLINE",
+"scan_stats":{"function":"SCRUB","state":"SCANNING","to_examine":"4000","examined":"1000","bytes_per_scan":"100","errors":"0","pass_start":"1760140801"}}}}`
+
+	exec := sysexec.NewMock()
+	exec.SetOutput("zpool", []byte(statusJSON))
+	m := &Manager{exec: exec}
+
+	got, err := m.GetScrubStatus(context.Background(), "tank")
+	if err != nil {
+		t.Fatalf("GetScrubStatus: %v", err)
+	}
+	if !got.InProgress || got.DataScanned != 1000 || got.DataToScan != 4000 || got.ScanRate != 100 {
+		t.Errorf("GetScrubStatus() = %+v", got)
+	}
+}
+
+func TestGetScrubStatus_TextFallback(t *testing.T) {
+	exec := &noJSONExec{MockExecutor: sysexec.NewMock()}
+	exec.SetOutput("zpool", []byte(scrubRunningText))
+	m := &Manager{exec: exec}
+
+	got, err := m.GetScrubStatus(context.Background(), "tank")
+	if err != nil {
+		t.Fatalf("GetScrubStatus: %v", err)
+	}
+	if got == nil || !got.InProgress || got.DataScanned != 1000 || got.DataToScan != 4000 || got.StartTime == 0 {
+		t.Errorf("GetScrubStatus() = %+v", got)
+	}
+	cmds := exec.Commands()
+	if last := cmds[len(cmds)-1]; slices.Contains(last.Args, "-j") {
+		t.Errorf("last command = %v, want the text fallback", last)
+	}
+}
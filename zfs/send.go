@@ -0,0 +1,257 @@
+package zfs
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// DefaultBackupRoot is the directory send stream files are written to and
+// restored from unless changed with WithBackupRoot.
+const DefaultBackupRoot = "/var/backups/mynt"
+
+// ErrDestinationExists is returned when a send target already exists and
+// overwriting was not requested.
+var ErrDestinationExists = errors.New("destination file already exists")
+
+// SendResult describes a completed send to a file.
+type SendResult struct {
+	Path        string `json:"path"`
+	StreamBytes int64  `json:"stream_bytes"` // size of the raw send stream
+	FileBytes   int64  `json:"file_bytes"`   // size of the file, after compression
+}
+
+// SendOption configures SendToFile.
+type SendOption func(*sendOptions)
+
+type sendOptions struct {
+	overwrite bool
+	progress  func(sent, total int64)
+}
+
+// Overwrite allows SendToFile to replace an existing file.
+func Overwrite() SendOption {
+	return func(o *sendOptions) { o.overwrite = true }
+}
+
+// SendProgress reports the bytes of the stream sent so far and the
+// estimated total, which is 0 when ZFS cannot estimate it.
+func SendProgress(fn func(sent, total int64)) SendOption {
+	return func(o *sendOptions) { o.progress = fn }
+}
+
+// WithBackupRoot sets the directory SendToFile may write under and
+// ReceiveFromFile may read from.
+func WithBackupRoot(dir string) ManagerOption {
+	return func(m *Manager) {
+		m.backupRoot = dir
+	}
+}
+
+func (m *Manager) backupRootDir() string {
+	if m.backupRoot != "" {
+		return m.backupRoot
+	}
+	return DefaultBackupRoot
+}
+
+// checkBackupRoot verifies that path, with its symlinks already resolved,
+// lies under the backup root.
+func (m *Manager) checkBackupRoot(path string) error {
+	root, err := filepath.EvalSymlinks(m.backupRootDir())
+	if err != nil {
+		return fmt.Errorf("backup root: %w", err)
+	}
+	if rel, err := filepath.Rel(root, path); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("must be under the backup root %s", m.backupRootDir())
+	}
+	return nil
+}
+
+// CheckSendDestination verifies that destPath is an absolute, clean path
+// in an existing directory under the backup root, and that it does not
+// exist unless overwrite is set. Symlinks in the directory are resolved
+// before comparing it with the root.
+func (m *Manager) CheckSendDestination(destPath string, overwrite bool) error {
+	if !filepath.IsAbs(destPath) || filepath.Clean(destPath) != destPath {
+		return fmt.Errorf("destination must be a clean absolute path")
+	}
+	dir, err := os.Stat(filepath.Dir(destPath))
+	if err != nil {
+		return fmt.Errorf("destination directory: %w", err)
+	}
+	if !dir.IsDir() {
+		return fmt.Errorf("destination directory %s is not a directory", filepath.Dir(destPath))
+	}
+	realDir, err := filepath.EvalSymlinks(filepath.Dir(destPath))
+	if err != nil {
+		return fmt.Errorf("destination directory: %w", err)
+	}
+	if err := m.checkBackupRoot(realDir); err != nil {
+		return fmt.Errorf("destination %w", err)
+	}
+
+	fi, err := os.Lstat(destPath)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return nil
+	case err != nil:
+		return err
+	case !fi.Mode().IsRegular():
+		return fmt.Errorf("destination %s is not a regular file", destPath)
+	case !overwrite:
+		return fmt.Errorf("%w: %s", ErrDestinationExists, destPath)
+	}
+	return nil
+}
+
+// SendToFile writes a full send stream of snapshot to destPath, gzipped
+// when compress is set. The stream goes to a temporary file in the same
+// directory that is moved into place once complete, so destPath never
+// holds a partial stream, and is removed on failure.
+func (m *Manager) SendToFile(ctx context.Context, snapshot, destPath string, compress bool, opts ...SendOption) (*SendResult, error) {
+	var o sendOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if !strings.Contains(snapshot, "@") {
+		return nil, fmt.Errorf("invalid snapshot name format (expected dataset@snapshot)")
+	}
+	if err := validateName(snapshot); err != nil {
+		return nil, err
+	}
+	if err := m.CheckSendDestination(destPath, o.overwrite); err != nil {
+		return nil, err
+	}
+
+	total := m.sendSize(ctx, snapshot)
+
+	f, err := createTemp(destPath)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := m.sendTo(ctx, f, snapshot, compress, total, o.progress)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = publishFile(f.Name(), destPath, o.overwrite)
+	}
+	if err != nil {
+		_ = os.Remove(f.Name())
+		return nil, err
+	}
+
+	res.Path = destPath
+	if fi, err := os.Stat(destPath); err == nil {
+		res.FileBytes = fi.Size()
+	}
+	return res, nil
+}
+
+// createTemp creates a new hidden file next to destPath. It does not
+// follow symlinks, so a link planted in the directory cannot redirect
+// the write.
+func createTemp(destPath string) (*os.File, error) {
+	dir, base := filepath.Split(destPath)
+	for {
+		name := filepath.Join(dir, "."+base+"."+strconv.FormatUint(rand.Uint64(), 36)+".tmp")
+		f, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL|syscall.O_NOFOLLOW, 0o600)
+		if !errors.Is(err, os.ErrExist) {
+			return f, err
+		}
+	}
+}
+
+// publishFile moves the completed file tmp to destPath. Without overwrite
+// it is linked rather than renamed, so a file created at destPath in the
+// meantime is not replaced.
+func publishFile(tmp, destPath string, overwrite bool) error {
+	if overwrite {
+		return os.Rename(tmp, destPath)
+	}
+	if err := os.Link(tmp, destPath); err != nil {
+		if errors.Is(err, os.ErrExist) {
+			return fmt.Errorf("%w: %s", ErrDestinationExists, destPath)
+		}
+		return err
+	}
+	return os.Remove(tmp)
+}
+
+// sendTo streams zfs send output into w through an optional gzip writer.
+func (m *Manager) sendTo(ctx context.Context, w io.Writer, snapshot string, compress bool, total int64, progress func(sent, total int64)) (*SendResult, error) {
+	bw := bufio.NewWriter(w)
+	var out io.Writer = bw
+	var gz *gzip.Writer
+	if compress {
+		gz = gzip.NewWriter(bw)
+		out = gz
+	}
+
+	counter := &countingWriter{w: out, total: total, progress: progress}
+	if err := m.exec.Stream(ctx, counter, "zfs", "send", snapshot); err != nil {
+		return nil, fmt.Errorf("zfs send %s: %w", snapshot, err)
+	}
+	if gz != nil {
+		if err := gz.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := bw.Flush(); err != nil {
+		return nil, err
+	}
+	return &SendResult{StreamBytes: counter.n}, nil
+}
+
+// sendSize estimates the size of a full send stream with a dry run. It
+// returns 0 if the estimate is unavailable.
+func (m *Manager) sendSize(ctx context.Context, snapshot string) int64 {
+	out, err := m.exec.Output(ctx, "zfs", "send", "-nP", snapshot)
+	if err != nil {
+		return 0
+	}
+	return parseSendSize(out)
+}
+
+// parseSendSize extracts the "size" line from zfs send -nP output.
+func parseSendSize(out []byte) int64 {
+	for line := range bytes.Lines(out) {
+		fields := strings.Fields(string(line))
+		if len(fields) == 2 && fields[0] == "size" {
+			if n, err := strconv.ParseInt(fields[1], 10, 64); err == nil {
+				return n
+			}
+		}
+	}
+	return 0
+}
+
+// countingWriter counts bytes written through it and reports progress.
+type countingWriter struct {
+	w        io.Writer
+	n        int64
+	total    int64
+	progress func(sent, total int64)
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	if c.progress != nil {
+		c.progress(c.n, c.total)
+	}
+	return n, err
+}
@@ -0,0 +1,172 @@
+package zfs
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"go.aimuz.me/mynt/sysexec"
+)
+
+func TestSendToFile(t *testing.T) {
+	stream := []byte("full send stream bytes")
+
+	tests := []struct {
+		name     string
+		compress bool
+	}{
+		{"plain", false},
+		{"gzip", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exec := sysexec.NewMock()
+			exec.SetOutput("zfs", stream)
+			root := t.TempDir()
+			m := &Manager{exec: exec, backupRoot: root}
+			dest := filepath.Join(root, "backup.zfs")
+
+			var sent int64
+			res, err := m.SendToFile(context.Background(), "tank/data@daily", dest, tt.compress,
+				SendProgress(func(n, _ int64) { sent = n }))
+			if err != nil {
+				t.Fatalf("SendToFile: %v", err)
+			}
+			if res.StreamBytes != int64(len(stream)) || sent != res.StreamBytes {
+				t.Errorf("stream bytes = %d, progress = %d, want %d", res.StreamBytes, sent, len(stream))
+			}
+
+			f, err := os.Open(dest)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			var r io.Reader = f
+			if tt.compress {
+				gz, err := gzip.NewReader(f)
+				if err != nil {
+					t.Fatalf("gzip.NewReader: %v", err)
+				}
+				r = gz
+			}
+			got, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != string(stream) {
+				t.Errorf("file content = %q, want %q", got, stream)
+			}
+
+			cmds := exec.Commands()
+			if len(cmds) != 2 {
+				t.Fatalf("got %d commands, want 2", len(cmds))
+			}
+			if want := []string{"send", "-nP", "tank/data@daily"}; !slices.Equal(cmds[0].Args, want) {
+				t.Errorf("estimate args = %v, want %v", cmds[0].Args, want)
+			}
+			if want := []string{"send", "tank/data@daily"}; !slices.Equal(cmds[1].Args, want) {
+				t.Errorf("send args = %v, want %v", cmds[1].Args, want)
+			}
+		})
+	}
+}
+
+func TestSendToFile_Overwrite(t *testing.T) {
+	root := t.TempDir()
+	dest := filepath.Join(root, "backup.zfs")
+	if err := os.WriteFile(dest, []byte("old"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	exec := sysexec.NewMock()
+	exec.SetOutput("zfs", []byte("new"))
+	m := &Manager{exec: exec, backupRoot: root}
+
+	_, err := m.SendToFile(context.Background(), "tank/data@daily", dest, false)
+	if !errors.Is(err, ErrDestinationExists) {
+		t.Fatalf("SendToFile() error = %v, want ErrDestinationExists", err)
+	}
+	if n := len(exec.Commands()); n != 0 {
+		t.Errorf("ran %d commands, want 0", n)
+	}
+
+	if _, err := m.SendToFile(context.Background(), "tank/data@daily", dest, false, Overwrite()); err != nil {
+		t.Fatalf("SendToFile(Overwrite): %v", err)
+	}
+	if got, _ := os.ReadFile(dest); string(got) != "new" {
+		t.Errorf("file content = %q, want %q", got, "new")
+	}
+}
+
+func TestSendToFile_FailureRemovesFile(t *testing.T) {
+	exec := sysexec.NewMock()
+	exec.SetError("zfs", errors.New("exit status 1"))
+	root := t.TempDir()
+	m := &Manager{exec: exec, backupRoot: root}
+	dest := filepath.Join(root, "backup.zfs")
+
+	if _, err := m.SendToFile(context.Background(), "tank/data@daily", dest, false); err == nil {
+		t.Fatal("SendToFile() error = nil, want error")
+	}
+	if entries, _ := os.ReadDir(root); len(entries) != 0 {
+		t.Errorf("partial file left behind: %v", entries)
+	}
+}
+
+func TestCheckSendDestination(t *testing.T) {
+	dir := t.TempDir()
+	outside := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(outside, filepath.Join(dir, "escape")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(outside, "target"), filepath.Join(dir, "link.zfs")); err != nil {
+		t.Fatal(err)
+	}
+	m := &Manager{backupRoot: dir}
+
+	for _, path := range []string{filepath.Join(dir, "backup.zfs"), filepath.Join(dir, "sub", "backup.zfs")} {
+		if err := m.CheckSendDestination(path, false); err != nil {
+			t.Errorf("CheckSendDestination(%q) error = %v", path, err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		path string
+	}{
+		{"relative", "backup.zfs"},
+		{"unclean", dir + "/../backup.zfs"},
+		{"missing_dir", filepath.Join(dir, "missing", "backup.zfs")},
+		{"directory", filepath.Join(dir, "sub")},
+		{"outside_root", filepath.Join(outside, "backup.zfs")},
+		{"symlinked_dir", filepath.Join(dir, "escape", "backup.zfs")},
+		{"symlink", filepath.Join(dir, "link.zfs")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := m.CheckSendDestination(tt.path, true); err == nil {
+				t.Errorf("CheckSendDestination(%q) error = nil, want error", tt.path)
+			}
+		})
+	}
+}
+
+func TestParseSendSize(t *testing.T) {
+	out := []byte("full\ttank/data@daily\t1048576\nsize\t1048576\n")
+	if got := parseSendSize(out); got != 1048576 {
+		t.Errorf("parseSendSize() = %d, want 1048576", got)
+	}
+	if got := parseSendSize([]byte("garbage")); got != 0 {
+		t.Errorf("parseSendSize(garbage) = %d, want 0", got)
+	}
+}
@@ -1,19 +1,27 @@
 package zfs
 
 import (
+	"bytes"
 	"cmp"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"slices"
 	"strings"
 	"time"
 
 	gozfs "github.com/mistifyio/go-zfs/v4"
+	"go.aimuz.me/mynt/logger"
 )
 
+// ErrSnapshotUnchanged is returned by CreateSnapshot when SkipIfUnchanged
+// is set and nothing was written since the last snapshot.
+var ErrSnapshotUnchanged = errors.New("dataset unchanged since last snapshot")
+
 // CreateSnapshot creates a new ZFS snapshot.
 func (m *Manager) CreateSnapshot(ctx context.Context, req CreateSnapshotRequest) (*Snapshot, error) {
+	defer m.lockPool(req.Dataset)()
 	if req.Dataset == "" {
 		return nil, fmt.Errorf("dataset name is required")
 	}
@@ -24,29 +32,71 @@ func (m *Manager) CreateSnapshot(ctx context.Context, req CreateSnapshotRequest)
 	// Ensure snapshot name doesn't contain '@'
 	snapshotName := strings.TrimPrefix(req.Name, "@")
 	fullName := fmt.Sprintf("%s@%s", req.Dataset, snapshotName)
+	if err := validateName(fullName); err != nil {
+		return nil, err
+	}
+
+	if err := m.checkWritable(ctx, req.Dataset); err != nil {
+		return nil, err
+	}
 
-	dataset, err := gozfs.GetDataset(req.Dataset)
+	props, err := m.getProperties(ctx, req.Dataset, true, "written", "referenced")
 	if err != nil {
 		return nil, fmt.Errorf("dataset not found: %s: %w", req.Dataset, err)
 	}
 
-	gozfsSnapshot, err := dataset.Snapshot(snapshotName, false)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create snapshot: %w", err)
+	// written is the data written since the latest snapshot, or since
+	// creation if there is none
+	if req.SkipIfUnchanged && props["written"] == "0" {
+		return nil, ErrSnapshotUnchanged
+	}
+
+	if out, err := m.runMutation(ctx, "zfs", "snapshot", fullName); err != nil {
+		return nil, fmt.Errorf("failed to create snapshot: %s: %w", bytes.TrimSpace(out), err)
 	}
 
 	snapshot := &Snapshot{
 		Name:       fullName,
 		Dataset:    req.Dataset,
 		CreatedAt:  time.Now().Format(time.RFC3339),
-		Used:       gozfsSnapshot.Used,
-		Referenced: gozfsSnapshot.Referenced,
+		Referenced: parseUint(props["referenced"]),
 		Source:     "manual",
 	}
 
+	// The snapshot exists, so failing to read its space only leaves the
+	// dataset's referenced size, which a new snapshot shares in full
+	if snapProps, err := m.getProperties(ctx, fullName, true, "used", "referenced"); err != nil {
+		logger.Warn("failed to read new snapshot properties", "snapshot", fullName, "error", err)
+	} else {
+		snapshot.Used = parseUint(snapProps["used"])
+		snapshot.Referenced = parseUint(snapProps["referenced"])
+	}
+
 	return snapshot, nil
 }
 
+// getProperties returns the values of the given properties, in exact
+// numbers if parsable is set or as displayed by zfs (e.g. "128K") otherwise.
+func (m *Manager) getProperties(ctx context.Context, name string, parsable bool, props ...string) (map[string]string, error) {
+	flags := "-H"
+	if parsable {
+		flags = "-Hp"
+	}
+	out, err := m.exec.Output(ctx, "zfs", "get", flags, "-o", "property,value", strings.Join(props, ","), name)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string, len(props))
+	for line := range strings.Lines(string(out)) {
+		prop, value, ok := strings.Cut(strings.TrimSpace(line), "\t")
+		if ok {
+			values[prop] = value
+		}
+	}
+	return values, nil
+}
+
 const zfsSnapshotProperties = "name,used,referenced,creation"
 
 // ListSnapshots returns all snapshots for a specific dataset.
@@ -129,6 +179,7 @@ func detectSnapshotSource(snapshotName string) string {
 
 // DestroySnapshot destroys a ZFS snapshot.
 func (m *Manager) DestroySnapshot(ctx context.Context, snapshotName string) error {
+	defer m.lockPool(snapshotName)()
 	if snapshotName == "" {
 		return fmt.Errorf("snapshot name is required")
 	}
@@ -137,12 +188,16 @@ func (m *Manager) DestroySnapshot(ctx context.Context, snapshotName string) erro
 		return fmt.Errorf("invalid snapshot name format (expected dataset@snapshot)")
 	}
 
+	if err := m.checkWritable(ctx, snapshotName); err != nil {
+		return err
+	}
+
 	snapshot, err := gozfs.GetDataset(snapshotName)
 	if err != nil {
 		return fmt.Errorf("snapshot not found: %s: %w", snapshotName, err)
 	}
 
-	if err := snapshot.Destroy(gozfs.DestroyDefault); err != nil {
+	if err := m.retry(ctx, func() error { return snapshot.Destroy(gozfs.DestroyDefault) }); err != nil {
 		return fmt.Errorf("failed to destroy snapshot: %w", err)
 	}
 
@@ -151,6 +206,7 @@ func (m *Manager) DestroySnapshot(ctx context.Context, snapshotName string) erro
 
 // RollbackSnapshot rolls back a dataset to a specific snapshot.
 func (m *Manager) RollbackSnapshot(ctx context.Context, snapshotName string) error {
+	defer m.lockPool(snapshotName)()
 	if snapshotName == "" {
 		return fmt.Errorf("snapshot name is required")
 	}
@@ -159,20 +215,56 @@ func (m *Manager) RollbackSnapshot(ctx context.Context, snapshotName string) err
 		return fmt.Errorf("invalid snapshot name format (expected dataset@snapshot)")
 	}
 
+	if err := m.checkWritable(ctx, snapshotName); err != nil {
+		return err
+	}
+
 	snapshot, err := gozfs.GetDataset(snapshotName)
 	if err != nil {
 		return fmt.Errorf("snapshot not found: %s: %w", snapshotName, err)
 	}
 
-	if err := snapshot.Rollback(false); err != nil {
+	if err := m.retry(ctx, func() error { return snapshot.Rollback(false) }); err != nil {
 		return fmt.Errorf("failed to rollback snapshot: %w", err)
 	}
 
 	return nil
 }
 
+// RenameSnapshot renames a snapshot within its dataset. newSnapName is the
+// new snapshot name without the dataset prefix.
+func (m *Manager) RenameSnapshot(ctx context.Context, oldName, newSnapName string) error {
+	defer m.lockPool(oldName)()
+	if oldName == "" || newSnapName == "" {
+		return fmt.Errorf("snapshot name and new name are required")
+	}
+
+	dataset, _, ok := strings.Cut(oldName, "@")
+	if !ok {
+		return fmt.Errorf("invalid snapshot name format (expected dataset@snapshot)")
+	}
+	if strings.Contains(newSnapName, "@") {
+		return fmt.Errorf("new snapshot name must not contain '@'")
+	}
+
+	newName := dataset + "@" + newSnapName
+	if err := validateNames(oldName, newName); err != nil {
+		return err
+	}
+
+	if err := m.checkWritable(ctx, oldName); err != nil {
+		return err
+	}
+
+	if out, err := m.runMutation(ctx, "zfs", "rename", oldName, newName); err != nil {
+		return fmt.Errorf("failed to rename snapshot: %s: %w", bytes.TrimSpace(out), err)
+	}
+	return nil
+}
+
 // CloneSnapshot creates a clone from a snapshot.
 func (m *Manager) CloneSnapshot(ctx context.Context, snapshotName, cloneName string) error {
+	defer m.lockPool(snapshotName)()
 	if snapshotName == "" || cloneName == "" {
 		return fmt.Errorf("snapshot name and clone name are required")
 	}
@@ -181,12 +273,19 @@ func (m *Manager) CloneSnapshot(ctx context.Context, snapshotName, cloneName str
 		return fmt.Errorf("invalid snapshot name format (expected dataset@snapshot)")
 	}
 
+	if err := m.checkWritable(ctx, snapshotName); err != nil {
+		return err
+	}
+
 	snapshot, err := gozfs.GetDataset(snapshotName)
 	if err != nil {
 		return fmt.Errorf("snapshot not found: %s: %w", snapshotName, err)
 	}
 
-	_, err = snapshot.Clone(cloneName, nil)
+	err = m.retry(ctx, func() error {
+		_, err := snapshot.Clone(cloneName, nil)
+		return err
+	})
 	if err != nil {
 		return fmt.Errorf("failed to clone snapshot: %w", err)
 	}
@@ -1,8 +1,13 @@
 package zfs
 
 import (
+	"context"
+	"errors"
+	"slices"
 	"strings"
 	"testing"
+
+	"go.aimuz.me/mynt/sysexec"
 )
 
 func TestCreateSnapshot_Validation(t *testing.T) {
@@ -37,6 +42,59 @@ func TestCreateSnapshot_Validation(t *testing.T) {
 	}
 }
 
+func TestCreateSnapshot_SkipIfUnchanged(t *testing.T) {
+	tests := []struct {
+		name       string
+		written    string
+		skip       bool
+		wantSkip   bool
+		wantCreate bool
+	}{
+		{"unchanged_skipped", "0", true, true, false},
+		{"changed_created", "4096", true, false, true},
+		{"unchanged_without_flag", "0", false, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exec := sysexec.NewMock()
+			exec.SetOutput("zfs", []byte("written\t"+tt.written+"\nreferenced\t1048576\nused\t8192\n"))
+			m := &Manager{exec: exec}
+
+			snap, err := m.CreateSnapshot(context.Background(), CreateSnapshotRequest{
+				Dataset:         "tank/data",
+				Name:            "snap1",
+				SkipIfUnchanged: tt.skip,
+			})
+			if tt.wantSkip {
+				if !errors.Is(err, ErrSnapshotUnchanged) {
+					t.Fatalf("error = %v, want ErrSnapshotUnchanged", err)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("CreateSnapshot: %v", err)
+				}
+				if snap.Name != "tank/data@snap1" || snap.Used != 8192 || snap.Referenced != 1048576 {
+					t.Errorf("snapshot = %+v", snap)
+				}
+				read := slices.ContainsFunc(exec.Commands(), func(c sysexec.Command) bool {
+					return slices.Equal(c.Args, []string{"get", "-Hp", "-o", "property,value", "used,referenced", "tank/data@snap1"})
+				})
+				if !read {
+					t.Errorf("snapshot properties not read: %+v", exec.Commands())
+				}
+			}
+
+			created := slices.ContainsFunc(exec.Commands(), func(c sysexec.Command) bool {
+				return slices.Equal(c.Args, []string{"snapshot", "tank/data@snap1"})
+			})
+			if created != tt.wantCreate {
+				t.Errorf("snapshot created = %v, want %v", created, tt.wantCreate)
+			}
+		})
+	}
+}
+
 func TestDestroySnapshot_Validation(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -161,3 +219,44 @@ func TestListSnapshots_EmptyDataset(t *testing.T) {
 		t.Error("expected error for empty dataset name")
 	}
 }
+
+func TestRenameSnapshot(t *testing.T) {
+	tests := []struct {
+		name    string
+		oldName string
+		newName string
+		wantErr string
+	}{
+		{"missing_new_name", "tank/data@snap1", "", "new name are required"},
+		{"no_at_sign", "tank/data", "snap2", "invalid snapshot name format"},
+		{"new_name_with_at", "tank/data@snap1", "other@snap2", "must not contain '@'"},
+		{"invalid_character", "tank/data@snap1", "snap 2", "invalid character"},
+		{"ok", "tank/data@snap1", "snap2", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exec := sysexec.NewMock()
+			m := &Manager{exec: exec}
+
+			err := m.RenameSnapshot(context.Background(), tt.oldName, tt.newName)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want containing %q", err, tt.wantErr)
+				}
+				if len(exec.Commands()) != 0 {
+					t.Errorf("ran %v, want no commands", exec.Commands())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RenameSnapshot: %v", err)
+			}
+
+			want := []string{"rename", "tank/data@snap1", "tank/data@snap2"}
+			if cmds := mutationCommands(t, exec); len(cmds) != 1 || !slices.Equal(cmds[0].Args, want) {
+				t.Errorf("commands = %v, want zfs %v", cmds, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package zfs
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// ErrNotSpare is returned by RemoveSpare for a device that is not one of
+// the pool's hot spares.
+var ErrNotSpare = errors.New("device is not a hot spare of the pool")
+
+// AddSpare adds device, a path under /dev, to a pool as a hot spare.
+func (m *Manager) AddSpare(ctx context.Context, pool, device string) error {
+	if err := validateName(pool); err != nil {
+		return err
+	}
+	if err := validateDevices(device); err != nil {
+		return err
+	}
+
+	defer m.lockPool(pool)()
+	if err := m.checkWritable(ctx, pool); err != nil {
+		return err
+	}
+	if out, err := m.runMutation(ctx, "zpool", "add", pool, "spare", device); err != nil {
+		return fmt.Errorf("add spare %s to pool %s: %s: %w", device, pool, bytes.TrimSpace(out), err)
+	}
+	return nil
+}
+
+// RemoveSpare removes a hot spare from a pool. The device must be one of
+// the pool's spares: zpool remove on a data disk would instead start
+// evacuating its vdev.
+func (m *Manager) RemoveSpare(ctx context.Context, pool, device string) error {
+	if err := validateNames(pool, device); err != nil {
+		return err
+	}
+
+	defer m.lockPool(pool)()
+	if err := m.checkWritable(ctx, pool); err != nil {
+		return err
+	}
+	p, err := m.GetPool(ctx, pool)
+	if err != nil {
+		return err
+	}
+	if !slices.ContainsFunc(p.Spares, func(d DiskDetail) bool { return d.Name == device || d.Path == device }) {
+		return fmt.Errorf("%w: %s", ErrNotSpare, device)
+	}
+
+	if out, err := m.runMutation(ctx, "zpool", "remove", pool, device); err != nil {
+		return fmt.Errorf("remove spare %s from pool %s: %s: %w", device, pool, bytes.TrimSpace(out), err)
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package zfs
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"go.aimuz.me/mynt/sysexec"
+)
+
+// sparePoolJSON is a mirror whose sdb has failed and been taken over by
+// the hot spare sdc, with sdd still available.
+const sparePoolJSON = `{"output_version":{},"pools":{"tank":{"name":"tank","state":"DEGRADED",
+"vdevs":{"tank":{"name":"tank","vdev_type":"root","vdevs":{"mirror-0":{"name":"mirror-0","vdev_type":"mirror","state":"DEGRADED",
+"vdevs":{"sda":{"name":"sda","vdev_type":"disk","state":"ONLINE"},
+"spare-1":{"name":"spare-1","vdev_type":"spare","state":"DEGRADED","vdevs":{
+"sdb":{"name":"sdb","vdev_type":"disk","state":"FAULTED"},"sdc":{"name":"sdc","vdev_type":"disk","state":"ONLINE"}}}}}}}},
+"spares":{"sdc":{"name":"sdc","vdev_type":"disk","state":"INUSE"},"sdd":{"name":"sdd","vdev_type":"disk","state":"AVAIL"}}}}}`
+
+func TestGetPool_Spares(t *testing.T) {
+	exec := sysexec.NewMock()
+	exec.SetOutput("zpool", []byte(sparePoolJSON))
+	m := &Manager{exec: exec}
+
+	pool, err := m.GetPool(context.Background(), "tank")
+	if err != nil {
+		t.Fatalf("GetPool: %v", err)
+	}
+
+	var spares []string
+	for _, s := range pool.Spares {
+		if !s.Spare {
+			t.Errorf("%s: Spare = false, want true", s.Name)
+		}
+		spares = append(spares, s.Name)
+		if want := s.Name == "sdc"; s.InUse != want {
+			t.Errorf("%s: InUse = %v, want %v", s.Name, s.InUse, want)
+		}
+	}
+	if !slices.Equal(spares, []string{"sdc", "sdd"}) {
+		t.Errorf("spares = %v, want [sdc sdd]", spares)
+	}
+
+	if len(pool.VDevs) != 1 {
+		t.Fatalf("len(VDevs) = %d, want 1", len(pool.VDevs))
+	}
+	var active []string
+	for _, d := range pool.VDevs[0].Children {
+		if d.Spare && d.InUse {
+			active = append(active, d.Name)
+		}
+	}
+	if !slices.Equal(active, []string{"sdc"}) {
+		t.Errorf("active spares in vdev = %v, want [sdc]", active)
+	}
+	if got := vdevFaultTolerance(pool.VDevs); got != 1 {
+		t.Errorf("vdevFaultTolerance() = %d, want 1", got)
+	}
+}
+
+func TestAddSpare(t *testing.T) {
+	exec := sysexec.NewMock()
+	m := &Manager{exec: exec}
+
+	if err := m.AddSpare(context.Background(), "tank", "/dev/sde"); err != nil {
+		t.Fatalf("AddSpare: %v", err)
+	}
+	cmds := mutationCommands(t, exec)
+	want := []string{"add", "tank", "spare", "/dev/sde"}
+	if len(cmds) != 1 || cmds[0].Name != "zpool" || !slices.Equal(cmds[0].Args, want) {
+		t.Errorf("commands = %v, want zpool %v", cmds, want)
+	}
+}
+
+func TestRemoveSpare(t *testing.T) {
+	tests := []struct {
+		name    string
+		device  string
+		wantErr error
+	}{
+		{name: "available_spare", device: "sdd"},
+		{name: "data_disk", device: "sda", wantErr: ErrNotSpare},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exec := sysexec.NewMock()
+			exec.SetOutput("zpool", []byte(sparePoolJSON))
+			m := &Manager{exec: exec}
+
+			err := m.RemoveSpare(context.Background(), "tank", tt.device)
+			cmds := exec.Commands()
+			last := cmds[len(cmds)-1]
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("RemoveSpare() error = %v, want %v", err, tt.wantErr)
+				}
+				if slices.Contains(last.Args, "remove") {
+					t.Errorf("ran %v, want no zpool remove", last)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RemoveSpare: %v", err)
+			}
+			if want := []string{"remove", "tank", tt.device}; !slices.Equal(last.Args, want) {
+				t.Errorf("args = %v, want %v", last.Args, want)
+			}
+		})
+	}
+}
+
+func TestSpare_InvalidDevice(t *testing.T) {
+	exec := sysexec.NewMock()
+	m := &Manager{exec: exec}
+	ctx := context.Background()
+
+	for _, device := range []string{"/dev/sde;rm", "sde", "/etc/passwd", "/dev/../etc/passwd"} {
+		if err := m.AddSpare(ctx, "tank", device); err == nil {
+			t.Errorf("AddSpare(%q): expected error for invalid device", device)
+		}
+	}
+	if err := m.RemoveSpare(ctx, "tank", "sdd|sh"); err == nil {
+		t.Error("RemoveSpare: expected error for invalid device")
+	}
+	if cmds := exec.Commands(); len(cmds) != 0 {
+		t.Errorf("ran %v, want nothing", cmds)
+	}
+}
@@ -0,0 +1,81 @@
+package zfs
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// ErrUnknownTemplate is returned when a use case names neither a
+// built-in nor a custom template.
+var ErrUnknownTemplate = errors.New("unknown dataset template")
+
+// BuiltinTemplates lists the use-case templates defined in code. Custom
+// templates may not reuse these names.
+var BuiltinTemplates = []UseCaseTemplate{
+	UseCaseGeneral, UseCaseMedia, UseCaseSurveillance, UseCaseVM, UseCaseDatabase,
+}
+
+// TemplateSource provides custom dataset templates defined by admins.
+type TemplateSource interface {
+	// TemplateProperties returns the properties of the named template,
+	// or nil if no such template exists.
+	TemplateProperties(name string) (map[string]string, error)
+}
+
+// IsBuiltinTemplate reports whether name is one of BuiltinTemplates.
+func IsBuiltinTemplate(name UseCaseTemplate) bool {
+	return slices.Contains(BuiltinTemplates, name)
+}
+
+var (
+	templateNameRe = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+	propertyNameRe = regexp.MustCompile(`^[a-z][a-z0-9_.:]*$`)
+)
+
+// ValidateTemplate checks a custom template before it is stored: the name
+// must be a short lowercase identifier not used by a built-in template,
+// and each property a ZFS-style name with a non-empty value free of
+// whitespace.
+func ValidateTemplate(name string, properties map[string]string) error {
+	if !templateNameRe.MatchString(name) {
+		return fmt.Errorf("invalid template name %q: use up to 64 lowercase letters, digits, '-' or '_'", name)
+	}
+	if IsBuiltinTemplate(UseCaseTemplate(name)) {
+		return fmt.Errorf("template name %q is reserved for a built-in template", name)
+	}
+	if len(properties) == 0 {
+		return fmt.Errorf("template must set at least one property")
+	}
+	for _, k := range slices.Sorted(maps.Keys(properties)) {
+		if !propertyNameRe.MatchString(k) {
+			return fmt.Errorf("invalid property name %q", k)
+		}
+		v := properties[k]
+		if v == "" || strings.ContainsFunc(v, func(r rune) bool { return r <= ' ' || r == 0x7f }) {
+			return fmt.Errorf("invalid value %q for property %s", v, k)
+		}
+	}
+	return nil
+}
+
+// TemplateProperties returns the properties of a use-case template. Built-in
+// templates and an empty use case resolve through GetTemplateProperties;
+// other names are looked up in the custom template source, if any.
+func (m *Manager) TemplateProperties(useCase UseCaseTemplate) (map[string]string, error) {
+	if useCase == "" || IsBuiltinTemplate(useCase) || m.templates == nil {
+		return GetTemplateProperties(useCase), nil
+	}
+
+	props, err := m.templates.TemplateProperties(string(useCase))
+	if err != nil {
+		return nil, fmt.Errorf("load template %s: %w", useCase, err)
+	}
+	if props == nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownTemplate, useCase)
+	}
+	return maps.Clone(props), nil
+}
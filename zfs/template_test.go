@@ -0,0 +1,89 @@
+package zfs
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"go.aimuz.me/mynt/sysexec"
+)
+
+// staticTemplates is a TemplateSource backed by a map.
+type staticTemplates map[string]map[string]string
+
+func (s staticTemplates) TemplateProperties(name string) (map[string]string, error) {
+	return s[name], nil
+}
+
+func TestCreateDataset_CustomTemplate(t *testing.T) {
+	exec := sysexec.NewMock()
+	m := &Manager{exec: exec, templates: staticTemplates{
+		"backup": {"compression": "zstd", "recordsize": "1M", "atime": "off"},
+	}}
+
+	err := m.CreateDataset(context.Background(), CreateDatasetRequest{
+		Name:       "tank/vol",
+		Type:       "volume",
+		UseCase:    "backup",
+		Quota:      10 * 1024 * 1024,
+		Properties: map[string]string{"atime": "on"},
+	})
+	if err != nil {
+		t.Fatalf("CreateDataset: %v", err)
+	}
+
+	want := []string{"create", "-p", "-V", "10485760", "-o", "atime=on", "-o", "compression=zstd", "-o", "volblocksize=1M", "tank/vol"}
+	cmds := mutationCommands(t, exec)
+	if len(cmds) != 1 || !slices.Equal(cmds[0].Args, want) {
+		t.Errorf("commands = %v, want zfs %v", cmds, want)
+	}
+
+	// The stored template is not modified by request overrides.
+	if got := m.templates.(staticTemplates)["backup"]["atime"]; got != "off" {
+		t.Errorf("template atime = %q after create, want off", got)
+	}
+}
+
+func TestTemplateProperties(t *testing.T) {
+	m := &Manager{exec: sysexec.NewMock(), templates: staticTemplates{
+		"backup": {"compression": "zstd"},
+	}}
+
+	got, err := m.TemplateProperties(UseCaseDatabase)
+	if err != nil || got["recordsize"] != "16K" {
+		t.Errorf("TemplateProperties(database) = %v, %v, want built-in", got, err)
+	}
+	got, err = m.TemplateProperties("backup")
+	if err != nil || got["compression"] != "zstd" {
+		t.Errorf("TemplateProperties(backup) = %v, %v, want custom", got, err)
+	}
+	if _, err := m.TemplateProperties("missing"); !errors.Is(err, ErrUnknownTemplate) {
+		t.Errorf("TemplateProperties(missing) error = %v, want ErrUnknownTemplate", err)
+	}
+}
+
+func TestValidateTemplate(t *testing.T) {
+	tests := []struct {
+		name    string
+		tmpl    string
+		props   map[string]string
+		wantErr bool
+	}{
+		{"valid", "backup", map[string]string{"compression": "zstd", "com.example:owner": "ops"}, false},
+		{"builtin name", "media", map[string]string{"atime": "off"}, true},
+		{"bad name", "Backup Set", map[string]string{"atime": "off"}, true},
+		{"no properties", "backup", nil, true},
+		{"bad property", "backup", map[string]string{"-o": "x"}, true},
+		{"empty value", "backup", map[string]string{"atime": ""}, true},
+		{"whitespace value", "backup", map[string]string{"atime": "off on"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateTemplate(tt.tmpl, tt.props); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateTemplate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package zfs
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrTrimUnsupported is returned when none of a pool's devices can be
+// trimmed, as is the case for pools on spinning disks.
+var ErrTrimUnsupported = errors.New("trim is not supported by the pool's devices")
+
+// Trim starts a manual TRIM of all free space in a pool. It returns once
+// the trim has started; use TrimStatus to follow it.
+func (m *Manager) Trim(ctx context.Context, poolName string) error {
+	if err := validateName(poolName); err != nil {
+		return err
+	}
+	if out, err := m.exec.CombinedOutput(ctx, "zpool", "trim", poolName); err != nil {
+		if bytes.Contains(out, []byte("not supported")) {
+			return fmt.Errorf("trim pool %s: %w", poolName, ErrTrimUnsupported)
+		}
+		return fmt.Errorf("trim pool %s: %s: %w", poolName, bytes.TrimSpace(out), err)
+	}
+	return nil
+}
+
+// TrimStatus is the state of the last or current manual TRIM of a pool.
+type TrimStatus struct {
+	InProgress  bool         `json:"in_progress"`
+	PercentDone float64      `json:"percent_done"` // average over the devices that support trim
+	Devices     []DeviceTrim `json:"devices"`
+}
+
+// DeviceTrim is the TRIM state of one leaf device.
+type DeviceTrim struct {
+	Name        string  `json:"name"`
+	State       string  `json:"state"` // trimming, suspended, completed, untrimmed or unsupported
+	PercentDone float64 `json:"percent_done"`
+	Time        int64   `json:"time,omitempty"` // Unix time the trim started, or completed
+}
+
+// TrimStatus returns the per-device TRIM progress of a pool, parsed from
+// zpool status -t.
+func (m *Manager) TrimStatus(ctx context.Context, poolName string) (*TrimStatus, error) {
+	if err := validateName(poolName); err != nil {
+		return nil, err
+	}
+	out, err := m.exec.Output(ctx, "zpool", "status", "-t", poolName)
+	if err != nil {
+		return nil, fmt.Errorf("zpool status: %w", err)
+	}
+	return parseTrimStatus(string(out)), nil
+}
+
+var (
+	// A config line with a trim note, e.g.
+	// "sda  ONLINE  0  0  0  (45% trimmed, started at Mon Jan  6 02:00:01 2025)"
+	trimLineRe     = regexp.MustCompile(`^\s+(\S+)\s+\S+\s+\S+\s+\S+\s+\S+\s+\((.+)\)\s*$`)
+	trimProgressRe = regexp.MustCompile(`^(\d+)% trimmed(, suspended)?, (started|completed) at (.+)$`)
+)
+
+// parseTrimStatus extracts the device trim notes from zpool status -t
+// output. Devices without a note, such as vdevs, are skipped.
+func parseTrimStatus(out string) *TrimStatus {
+	status := &TrimStatus{Devices: []DeviceTrim{}}
+	var total float64
+	var trimmable int
+	for line := range strings.Lines(out) {
+		m := trimLineRe.FindStringSubmatch(strings.TrimRight(line, "\n"))
+		if m == nil {
+			continue
+		}
+		d := DeviceTrim{Name: m[1]}
+		note := m[2]
+		switch {
+		case note == "untrimmed":
+			d.State = "untrimmed"
+		case note == "trim unsupported":
+			d.State = "unsupported"
+		default:
+			p := trimProgressRe.FindStringSubmatch(note)
+			if p == nil {
+				continue
+			}
+			pct, _ := strconv.Atoi(p[1])
+			d.PercentDone = float64(pct)
+			switch {
+			case p[2] != "":
+				d.State = "suspended"
+			case p[3] == "completed":
+				d.State = "completed"
+			default:
+				d.State = "trimming"
+				status.InProgress = true
+			}
+			if t, err := time.ParseInLocation(zpoolTimeLayout, p[4], time.Local); err == nil {
+				d.Time = t.Unix()
+			}
+		}
+		if d.State != "unsupported" {
+			total += d.PercentDone
+			trimmable++
+		}
+		status.Devices = append(status.Devices, d)
+	}
+	if trimmable > 0 {
+		status.PercentDone = total / float64(trimmable)
+	}
+	return status
+}
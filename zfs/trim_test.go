@@ -0,0 +1,94 @@
+package zfs
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"go.aimuz.me/mynt/sysexec"
+)
+
+const trimStatusOutput = `  pool: tank
+ state: ONLINE
+config:
+
+	NAME        STATE     READ WRITE CKSUM
+	tank        ONLINE       0     0     0
+	  mirror-0  ONLINE       0     0     0
+	    sda     ONLINE       0     0     0  (40% trimmed, started at Mon Jan  6 02:00:01 2025)
+	    sdb     ONLINE       0     0     0  (100% trimmed, completed at Mon Jan  6 02:10:41 2025)
+	  mirror-1  ONLINE       0     0     0
+	    sdc     ONLINE       0     0     0  (60% trimmed, suspended, started at Mon Jan  6 02:00:01 2025)
+	    sdd     ONLINE       0     0     0  (untrimmed)
+	  sde       ONLINE       0     0     0  (trim unsupported)
+
+errors: No known data errors
+`
+
+func TestParseTrimStatus(t *testing.T) {
+	status := parseTrimStatus(trimStatusOutput)
+
+	if !status.InProgress {
+		t.Error("InProgress = false, want true while sda is trimming")
+	}
+	// sde does not support trim and is left out of the average
+	if want := (40.0 + 100 + 60 + 0) / 4; status.PercentDone != want {
+		t.Errorf("PercentDone = %v, want %v", status.PercentDone, want)
+	}
+
+	var names, states []string
+	for _, d := range status.Devices {
+		names = append(names, d.Name)
+		states = append(states, d.State)
+	}
+	if want := []string{"sda", "sdb", "sdc", "sdd", "sde"}; !slices.Equal(names, want) {
+		t.Errorf("devices = %v, want %v", names, want)
+	}
+	if want := []string{"trimming", "completed", "suspended", "untrimmed", "unsupported"}; !slices.Equal(states, want) {
+		t.Errorf("states = %v, want %v", states, want)
+	}
+	if status.Devices[1].Time == 0 {
+		t.Error("completed device has no time")
+	}
+}
+
+func TestParseTrimStatus_NoTrimNotes(t *testing.T) {
+	status := parseTrimStatus("  pool: tank\n\ttank  ONLINE  0  0  0\n\t  sda  ONLINE  0  0  0\n")
+	if status.InProgress || len(status.Devices) != 0 || status.PercentDone != 0 {
+		t.Errorf("status = %+v, want empty", status)
+	}
+}
+
+func TestTrim(t *testing.T) {
+	exec := sysexec.NewMock()
+	m := &Manager{exec: exec}
+
+	if err := m.Trim(context.Background(), "tank"); err != nil {
+		t.Fatalf("Trim: %v", err)
+	}
+	cmds := exec.Commands()
+	if want := []string{"trim", "tank"}; len(cmds) != 1 || !slices.Equal(cmds[0].Args, want) {
+		t.Errorf("commands = %v, want zpool %v", cmds, want)
+	}
+}
+
+// trimExec fails zpool trim with the given output.
+type trimExec struct {
+	*sysexec.MockExecutor
+	out string
+}
+
+func (e trimExec) CombinedOutput(ctx context.Context, name string, args ...string) ([]byte, error) {
+	e.MockExecutor.Output(ctx, name, args...) // record the command
+	return []byte(e.out), errors.New("exit status 255")
+}
+
+func TestTrim_Unsupported(t *testing.T) {
+	exec := trimExec{sysexec.NewMock(), "cannot trim '/dev/sda': trim operations are not supported by this device\n"}
+	m := &Manager{exec: exec}
+
+	if err := m.Trim(context.Background(), "tank"); !errors.Is(err, ErrTrimUnsupported) {
+		t.Errorf("error = %v, want ErrTrimUnsupported", err)
+	}
+}
@@ -25,6 +25,9 @@ type Pool struct {
 	Redundancy     int             `json:"redundancy"` // How many more disks can fail
 	ScrubStatus    *ScrubStatus    `json:"scrub_status,omitempty"`
 	ResilverStatus *ResilverStatus `json:"resilver_status,omitempty"`
+	ReadOnly       bool            `json:"readonly"` // Imported read-only; mutations are refused
+	Autotrim       bool            `json:"autotrim"` // Freed space is trimmed on the devices
+	Spares         []DiskDetail    `json:"spares,omitempty"`
 }
 
 // DatasetType represents the type of a dataset.
@@ -38,18 +41,34 @@ const (
 
 // Dataset represents a ZFS dataset.
 type Dataset struct {
-	Name          string      `json:"name"`
-	Type          DatasetType `json:"type"`
-	Pool          string      `json:"pool"` // Pool name extracted from dataset name
-	Used          uint64      `json:"used"`
-	Available     uint64      `json:"available"`
-	Referenced    uint64      `json:"referenced"`
-	Mountpoint    string      `json:"mountpoint"`
-	Compression   string      `json:"compression"`
-	Encryption    string      `json:"encryption"`
-	Deduplication string      `json:"deduplication"`
-	Quota         uint64      `json:"quota,omitempty"`
-	Reservation   uint64      `json:"reservation,omitempty"`
+	Name           string      `json:"name"`
+	Type           DatasetType `json:"type"`
+	Pool           string      `json:"pool"` // Pool name extracted from dataset name
+	Used           uint64      `json:"used"`
+	Available      uint64      `json:"available"`
+	Referenced     uint64      `json:"referenced"`
+	Mountpoint     string      `json:"mountpoint"`
+	Compression    string      `json:"compression"`
+	Encryption     string      `json:"encryption"`
+	Deduplication  string      `json:"deduplication"`
+	Quota          uint64      `json:"quota,omitempty"`
+	RefQuota       uint64      `json:"refquota,omitempty"`
+	Reservation    uint64      `json:"reservation,omitempty"`
+	RefReservation uint64      `json:"refreservation,omitempty"`
+	CompressRatio  float64     `json:"compressratio,omitempty"` // e.g. 1.45 for "1.45x"
+	LogicalUsed    uint64      `json:"logicalused,omitempty"`   // space used before compression
+	Written        uint64      `json:"written,omitempty"`       // written since the latest snapshot
+	Origin         string      `json:"origin,omitempty"`        // snapshot a clone was created from
+	Note           string      `json:"note,omitempty"`          // mynt:note user property
+
+	// Volumes only: how the zvol is exposed and whether its pool trims
+	// freed space, which decides if discards from a VM reach the SSDs.
+	VolMode      string `json:"volmode,omitempty"`
+	PoolAutotrim *bool  `json:"pool_autotrim,omitempty"`
+
+	// Filled in by the API from share paths when listing; zfs leaves them empty.
+	Shared bool    `json:"shared"`           // exported by at least one share
+	Shares []int64 `json:"shares,omitempty"` // IDs of the shares exporting the dataset
 }
 
 // UseCaseTemplate represents predefined dataset configurations.
@@ -85,6 +104,7 @@ const (
 // ScrubStatus represents the status of a scrub operation.
 type ScrubStatus struct {
 	InProgress  bool    `json:"in_progress"`
+	StartTime   int64   `json:"start_time,omitempty"` // Unix timestamp of the current pass
 	EndTime     *string `json:"end_time,omitempty"`
 	Errors      int     `json:"errors"`
 	DataScanned uint64  `json:"data_scanned"`
@@ -102,14 +122,16 @@ type VDevDetail struct {
 
 // DiskDetail represents a disk within a vdev.
 type DiskDetail struct {
-	Name      string `json:"name"`      // e.g., "sda"
-	Path      string `json:"path"`      // e.g., "/dev/sda"
-	Status    string `json:"status"`    // ONLINE, DEGRADED, FAULTED, OFFLINE
-	Slot      string `json:"slot"`      // physical slot number if available
-	Read      uint64 `json:"read"`      // read errors
-	Write     uint64 `json:"write"`     // write errors
-	Checksum  uint64 `json:"checksum"`  // checksum errors
-	Replacing bool   `json:"replacing"` // is being replaced
+	Name      string `json:"name"`             // e.g., "sda"
+	Path      string `json:"path"`             // e.g., "/dev/sda"
+	Status    string `json:"status"`           // ONLINE, DEGRADED, FAULTED, OFFLINE
+	Slot      string `json:"slot"`             // physical slot number if available
+	Read      uint64 `json:"read"`             // read errors
+	Write     uint64 `json:"write"`            // write errors
+	Checksum  uint64 `json:"checksum"`         // checksum errors
+	Replacing bool   `json:"replacing"`        // is being replaced
+	Spare     bool   `json:"spare,omitempty"`  // hot spare
+	InUse     bool   `json:"in_use,omitempty"` // hot spare standing in for a failed disk
 }
 
 // ResilverStatus represents the status of a resilver (rebuild) operation.
@@ -137,10 +159,44 @@ type CreatePoolRequest struct {
 	Name    string   `json:"name"`
 	Devices []string `json:"devices"` // List of disk paths (e.g., /dev/sda)
 	Type    string   `json:"type"`    // mirror, raidz, raidz2, or empty for stripe
+	Ashift  int      `json:"ashift"`  // log2 of the sector size; 0 uses DefaultAshift
 }
 
 // CreateSnapshotRequest represents a request to create a snapshot.
 type CreateSnapshotRequest struct {
-	Dataset string `json:"dataset"` // pool/dataset name
-	Name    string `json:"name"`    // snapshot name (without @)
+	Dataset         string `json:"dataset"`           // pool/dataset name
+	Name            string `json:"name"`              // snapshot name (without @)
+	SkipIfUnchanged bool   `json:"skip_if_unchanged"` // skip if nothing was written since the last snapshot
+}
+
+// DestroyImpact summarizes what recursively destroying a dataset or pool removes.
+type DestroyImpact struct {
+	Datasets  int `json:"datasets"`  // filesystems and volumes, including the target
+	Snapshots int `json:"snapshots"` // snapshots of the target and its descendants
+}
+
+// ImportablePool is a pool visible to `zpool import` but not yet imported.
+type ImportablePool struct {
+	Name           string          `json:"name"`
+	GUID           string          `json:"guid"`
+	State          PoolStatus      `json:"state"`
+	Status         string          `json:"status,omitempty"` // explanation from zpool, if any
+	Action         string          `json:"action,omitempty"` // suggested action from zpool
+	MissingDevices []MissingDevice `json:"missing_devices,omitempty"`
+	NeedsForce     bool            `json:"needs_force,omitempty"` // last used by another system, import with Force
+}
+
+// MissingDevice is a device of an importable pool that is unavailable.
+type MissingDevice struct {
+	Name  string `json:"name"`            // last known path, or GUID if unknown
+	State string `json:"state"`           // UNAVAIL, FAULTED, REMOVED
+	Class string `json:"class,omitempty"` // "logs", "cache", "spares", "special", "dedup"; empty for data vdevs
+}
+
+// ImportOptions controls how a pool is imported.
+type ImportOptions struct {
+	Force      bool `json:"force"`       // -f: import a pool last used by another system
+	MissingLog bool `json:"missing_log"` // -m: import despite a missing log device
+	Degraded   bool `json:"degraded"`    // allow importing a pool with missing or faulted devices
+	ReadOnly   bool `json:"readonly"`    // -o readonly=on: import without writing, for recovery
 }
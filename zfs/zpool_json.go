@@ -23,6 +23,7 @@ type PoolJSON struct {
 	ZPLVersion string           `json:"zpl_version"`
 	ScanStats  *ScanStatsJSON   `json:"scan_stats,omitempty"`
 	VDevs      map[string]*Vdev `json:"vdevs"`
+	Spares     map[string]*Vdev `json:"spares,omitempty"`
 	ErrorCount string           `json:"error_count"`
 }
 